@@ -9,9 +9,11 @@ package ai
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/minand-mohan/execute-my-will/internal/config"
@@ -23,6 +25,7 @@ type AnthropicProvider struct {
 	model       string
 	maxTokens   int
 	temperature float32
+	apiVersion  string // sent as the anthropic-version header
 }
 
 type AnthropicRequest struct {
@@ -30,6 +33,7 @@ type AnthropicRequest struct {
 	MaxTokens   int                `json:"max_tokens"`
 	Temperature float32            `json:"temperature"`
 	Messages    []AnthropicMessage `json:"messages"`
+	Stream      bool               `json:"stream,omitempty"`
 }
 
 type AnthropicMessage struct {
@@ -47,6 +51,16 @@ type AnthropicContent struct {
 	Text string `json:"text"`
 }
 
+// AnthropicStreamEvent is one event of a streamed message; only text deltas and errors matter here
+type AnthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *AnthropicError `json:"error,omitempty"`
+}
+
 type AnthropicError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
@@ -68,23 +82,40 @@ func NewAnthropicProvider(cfg *config.Config) (*AnthropicProvider, error) {
 		model:       cfg.Model,
 		maxTokens:   cfg.MaxTokens,
 		temperature: cfg.Temperature,
+		apiVersion:  cfg.APIVersion("anthropic"),
 	}, nil
 }
 
 func (a *AnthropicProvider) GenerateResponse(prompt string) (string, error) {
-	url := "https://api.anthropic.com/v1/messages"
+	return a.Converse(nil, prompt, nil)
+}
 
+// StreamResponse is GenerateResponse with the answer handed to onChunk as it is generated
+func (a *AnthropicProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	return a.Converse(nil, prompt, onChunk)
+}
+
+// Converse sends the history as messages ahead of the prompt, streaming the answer to onChunk
+// when it is not nil
+func (a *AnthropicProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
 	request := AnthropicRequest{
 		Model:       a.model,
 		MaxTokens:   a.maxTokens,
 		Temperature: a.temperature,
-		Messages: []AnthropicMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
 	}
+	for _, message := range chatMessages(history, prompt) {
+		request.Messages = append(request.Messages, AnthropicMessage{Role: message.Role, Content: message.Content})
+	}
+
+	if onChunk == nil {
+		return a.post(request)
+	}
+	return a.stream(request, onChunk)
+}
+
+// post sends a request and returns the text of the answer
+func (a *AnthropicProvider) post(request AnthropicRequest) (string, error) {
+	url := "https://api.anthropic.com/v1/messages"
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
@@ -98,55 +129,92 @@ func (a *AnthropicProvider) GenerateResponse(prompt string) (string, error) {
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", a.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("anthropic-version", a.apiVersion)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make API request: %w", err)
+		return "", providerError(0, fmt.Errorf("failed to make API request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", providerError(0, fmt.Errorf("failed to read response body: %w", err))
 	}
 
 	var response AnthropicResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		if resp.StatusCode != http.StatusOK {
+			return "", providerError(resp.StatusCode, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body)))
+		}
+		return "", parseFailure(fmt.Errorf("failed to unmarshal response: %w", err))
 	}
 
 	// Check for API errors
 	if response.Error != nil {
-		return "", fmt.Errorf("anthropic API error: %s", response.Error.Message)
+		return "", providerError(resp.StatusCode, fmt.Errorf("anthropic API error: %s", response.Error.Message))
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", providerError(resp.StatusCode, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body)))
 	}
 
 	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no response generated")
+		return "", parseFailure(fmt.Errorf("no response generated"))
 	}
 
 	responseText := response.Content[0].Text
 
-	// Handle failure cases as defined in the prompt
-	if responseText == "FAILURE: Intent too complex for a single shell command." {
-		return "", fmt.Errorf("intent too complex for a single shell command, might need merlin")
-	}
+	return responseText, nil
+}
 
-	if responseText == "FAILURE: Directory reference too vague." {
-		return "", fmt.Errorf("directory reference too vague - please specify exact paths. the map instructions are not clear")
+// stream is post with the answer handed to onChunk as it is generated
+func (a *AnthropicProvider) stream(request AnthropicRequest, onChunk func(chunk string)) (string, error) {
+	request.Stream = true
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Check for any other FAILURE responses
-	if len(responseText) >= 8 && responseText[:8] == "FAILURE:" {
-		return "", fmt.Errorf("command generation failed: %s", responseText[9:])
+	req, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", a.apiKey)
+	req.Header.Set("anthropic-version", a.apiVersion)
 
-	return responseText, nil
+	var text strings.Builder
+	err = postStream(req, func(data string) error {
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return parseFailure(fmt.Errorf("failed to unmarshal stream event: %w", err))
+		}
+		switch {
+		case event.Error != nil:
+			return providerError(http.StatusOK, fmt.Errorf("anthropic API error: %s", event.Error.Message))
+		case event.Type == "content_block_delta" && event.Delta.Type == "text_delta":
+			text.WriteString(event.Delta.Text)
+			onChunk(event.Delta.Text)
+		}
+		return nil
+	})
+
+	var statusErr *streamStatusError
+	if errors.As(err, &statusErr) {
+		var response AnthropicResponse
+		if json.Unmarshal(statusErr.body, &response) == nil && response.Error != nil {
+			return "", providerError(statusErr.status, fmt.Errorf("anthropic API error: %s", response.Error.Message))
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if text.Len() == 0 {
+		return "", parseFailure(fmt.Errorf("no response generated"))
+	}
+	return text.String(), nil
 }
 
 // List Models
@@ -169,7 +237,7 @@ func (a *AnthropicProvider) ListModels() ([]string, error) {
 			continue
 		}
 		req.Header.Add("x-api-key", a.apiKey)             // IMPORTANT: Use the provider's API key
-		req.Header.Add("anthropic-version", "2023-06-01") // Specify the API version
+		req.Header.Add("anthropic-version", a.apiVersion) // Specify the API version
 
 		resp, httpErr := client.Do(req)
 		if httpErr != nil {
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/azure.go
+package ai
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+// AzureOpenAIProvider talks to a model deployed in an Azure OpenAI resource. Requests go to the
+// resource's own endpoint and name the deployment rather than the model.
+type AzureOpenAIProvider struct {
+	apiKey      string
+	endpoint    string // the resource endpoint, e.g. https://my-resource.openai.azure.com
+	deployment  string
+	maxTokens   int
+	temperature float32
+	apiVersion  string // the api-version query parameter, e.g. 2024-10-21
+}
+
+func NewAzureOpenAIProvider(cfg *config.Config) (*AzureOpenAIProvider, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("Azure OpenAI API key is required")
+	}
+	if cfg.Azure.Endpoint == "" {
+		return nil, fmt.Errorf("Azure OpenAI needs the endpoint of your resource. Run 'execute-my-will configure --azure-endpoint https://<resource>.openai.azure.com'")
+	}
+
+	return &AzureOpenAIProvider{
+		apiKey:      cfg.APIKey,
+		endpoint:    strings.TrimRight(cfg.Azure.Endpoint, "/"),
+		deployment:  cfg.Azure.DeploymentName(cfg.Model),
+		maxTokens:   cfg.MaxTokens,
+		temperature: cfg.Temperature,
+		apiVersion:  cfg.APIVersion("azure-openai"),
+	}, nil
+}
+
+// ChatCompletionsURL is where chat completions of the deployment are requested
+func (a *AzureOpenAIProvider) ChatCompletionsURL() string {
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		a.endpoint, url.PathEscape(a.deployment), url.QueryEscape(a.apiVersion))
+}
+
+func (a *AzureOpenAIProvider) GenerateResponse(prompt string) (string, error) {
+	return a.Converse(nil, prompt, nil)
+}
+
+// StreamResponse is GenerateResponse with the answer handed to onChunk as it is generated
+func (a *AzureOpenAIProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	return a.Converse(nil, prompt, onChunk)
+}
+
+// Converse sends the history as chat messages ahead of the prompt, streaming the answer to
+// onChunk when it is not nil
+func (a *AzureOpenAIProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	// The deployment decides the model, so none is sent
+	request := OpenAIRequest{
+		Messages:       openAIMessages(history, prompt),
+		MaxTokens:      a.maxTokens,
+		Temperature:    a.temperature,
+		ResponseFormat: jsonObjectFormat(prompt),
+	}
+
+	header := http.Header{}
+	header.Set("api-key", a.apiKey)
+	if onChunk == nil {
+		return postChatCompletion(a.ChatCompletionsURL(), header, request, "Azure OpenAI")
+	}
+	return streamChatCompletion(a.ChatCompletionsURL(), header, request, "Azure OpenAI", onChunk)
+}
+
+// ListModels returns the configured deployment. Listing a resource's deployments needs the Azure
+// management API and other credentials, so deployments are named in the configuration instead.
+func (a *AzureOpenAIProvider) ListModels() ([]string, error) {
+	return []string{a.deployment}, nil
+}
@@ -18,55 +18,237 @@ import (
 
 type Client interface {
 	GenerateResponse(intent string, sysInfo *system.Info) (*AIResponse, error)
+	// StreamResponse is GenerateResponse with the answer shown to handler while it is generated
+	StreamResponse(intent string, sysInfo *system.Info, handler StreamHandler) (*AIResponse, error)
 	ExplainCommand(command string, sysInfo *system.Info) (string, error)
+	// AnswerQuestion answers an intent phrased as a question, or returns a non-answer response
+	// when the question is about this machine and needs a command to find out
+	AnswerQuestion(question string, sysInfo *system.Info) (*AIResponse, error)
+	AddChecksumVerification(content string, sysInfo *system.Info) (*AIResponse, error)
+	FixCommand(intent string, attempt Attempt, sysInfo *system.Info) (*AIResponse, error)
+	// ListCleanupCandidates asks for a read-only command listing the files a cleanup intent would delete
+	ListCleanupCandidates(intent string, sysInfo *system.Info) (*AIResponse, error)
+	// SummarizeOutput condenses a long execution output, given as a sample of its lines, into a short result
+	SummarizeOutput(intent, content, output string, lines int) (string, error)
+	// DiagnoseIncident explains, without blame, why a quest failed or did damage and what could prevent it
+	DiagnoseIncident(incident Incident) (string, error)
+	// GenerateRollback asks for a command or script that reverses a quest that ran, or a failure
+	// when its changes cannot be undone
+	GenerateRollback(intent string, attempt Attempt, sysInfo *system.Info) (*AIResponse, error)
+	// GenerateCommitMessage writes a commit message for staged changes, unlike the rejected ones
+	GenerateCommitMessage(changes *system.StagedChanges, rejected []string) (string, error)
+	// PlanQuest breaks an intent too large for one script into ordered sub-quests, each an intent of its own
+	PlanQuest(intent string, sysInfo *system.Info) ([]string, error)
 	ListModels() ([]string, error)
+	// Exchanges returns every prompt sent so far with its raw answer
+	Exchanges() []Exchange
+	// Continue sends history, the earlier quests of a session, ahead of every later command,
+	// question, and fix prompt, so that an intent can refer to them
+	Continue(history []Message)
 }
 
 type clientImpl struct {
-	provider AIProvider
+	provider  AIProvider
+	recorder  *recordingProvider
+	privacy   config.PrivacyConfig
+	statsPath string // empty disables cross-run statistics
+	statsKey  string
+	// conversation holds the earlier quests of the session being continued, if any
+	conversation []Message
 }
 
+// notDisclosed replaces context the user has chosen to withhold from the provider
+const notDisclosed = "not disclosed"
+
+// notScanned replaces context a quick (--no-system-scan) quest did not gather
+const notScanned = "not scanned (assume the usual tools for this OS)"
+
 func NewClient(cfg *config.Config) (Client, error) {
-	var provider AIProvider
-	var err error
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit, ok := cfg.RateLimits[cfg.AIProvider]; ok && limit.Enabled() {
+		limiter := NewRateLimiter(limit, config.StatePath(fmt.Sprintf("ratelimit-%s.yaml", cfg.AIProvider)))
+		limiter.OnWait = OnRateLimitWait
+		provider = NewRateLimitedProvider(provider, limiter, cfg.MaxTokens)
+	}
+
+	provider = &usageRecordingProvider{
+		AIProvider: provider,
+		path:       config.StatePath(UsageFile),
+		key:        StatsKey(cfg.AIProvider, cfg.Model),
+	}
+	recorder := &recordingProvider{AIProvider: provider}
+
+	return &clientImpl{
+		provider:  recorder,
+		recorder:  recorder,
+		privacy:   cfg.Privacy,
+		statsPath: config.StatePath(ParseStatsFile),
+		statsKey:  StatsKey(cfg.AIProvider, cfg.Model),
+	}, nil
+}
 
+// newProvider creates the configured provider without rate limiting or usage recording
+func newProvider(cfg *config.Config) (AIProvider, error) {
 	switch cfg.AIProvider {
 	case "gemini":
-		provider, err = NewGeminiProvider(cfg)
+		return NewGeminiProvider(cfg)
 	case "openai":
-		provider, err = NewOpenAIProvider(cfg)
+		return NewOpenAIProvider(cfg)
 	case "anthropic":
-		provider, err = NewAnthropicProvider(cfg)
+		return NewAnthropicProvider(cfg)
+	case "azure-openai":
+		return NewAzureOpenAIProvider(cfg)
+	case "openrouter":
+		return NewOpenRouterProvider(cfg)
+	case "mock":
+		return NewMockProvider(cfg)
 	default:
 		return nil, fmt.Errorf("unsupported AI provider: %s", cfg.AIProvider)
 	}
+}
 
+func (c *clientImpl) GenerateResponse(intent string, sysInfo *system.Info) (*AIResponse, error) {
+	return c.StreamResponse(intent, sysInfo, nil)
+}
+
+func (c *clientImpl) StreamResponse(intent string, sysInfo *system.Info, handler StreamHandler) (*AIResponse, error) {
+	prompt := buildCommandPrompt(intent, sysInfo, c.privacy)
+	response, err := c.generateComplete(prompt, c.conversation, 5, handler)
 	if err != nil {
 		return nil, err
 	}
+	c.recordStats(func(s *ParseStats) { s.RecordResponse(c.statsKey, IsWellFormedResponse(response)) })
+	return ParseAIResponse(response), nil
+}
 
-	return &clientImpl{provider: provider}, nil
+func (c *clientImpl) Continue(history []Message) {
+	c.conversation = history
 }
 
-func (c *clientImpl) GenerateResponse(intent string, sysInfo *system.Info) (*AIResponse, error) {
-	prompt := buildCommandPrompt(intent, sysInfo)
-	response, err := exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 5, 1*time.Second)
+func (c *clientImpl) ExplainCommand(command string, sysInfo *system.Info) (string, error) {
+	prompt := buildExplanationPrompt(command, sysInfo, c.privacy)
+	return exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 3, 1*time.Second)
+}
+
+func (c *clientImpl) AnswerQuestion(question string, sysInfo *system.Info) (*AIResponse, error) {
+	prompt := buildQuestionPrompt(question, sysInfo)
+	response, err := c.generateComplete(prompt, c.conversation, 3, nil)
 	if err != nil {
 		return nil, err
 	}
-	return parseAIResponse(response), nil
+	return ParseAIResponse(response), nil
 }
 
-func (c *clientImpl) ExplainCommand(command string, sysInfo *system.Info) (string, error) {
-	prompt := buildExplanationPrompt(command, sysInfo)
-	return exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 3, 1*time.Second)
+// SummarizeOutput asks the oracle for a short summary of what a long execution output reports
+func (c *clientImpl) SummarizeOutput(intent, content, output string, lines int) (string, error) {
+	prompt := buildSummaryPrompt(intent, content, output, lines)
+	summary, err := exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 3, 1*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary), nil
+}
+
+// Incident is what is known about a failed or destructive quest. Every field must already be redacted.
+type Incident struct {
+	Intent  string
+	Content string
+	Outcome string // e.g. "failed" or "succeeded"
+	Risk    string // the risk level of the content, e.g. "destructive"
+	Error   string
+	Output  string // the error output or a sample of the saved log
+}
+
+// DiagnoseIncident asks the oracle for a blameless diagnosis of a quest for an incident note
+func (c *clientImpl) DiagnoseIncident(incident Incident) (string, error) {
+	prompt := buildIncidentPrompt(incident)
+	diagnosis, err := exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 3, 1*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(diagnosis), nil
+}
+
+// PlanQuest asks the oracle for a numbered plan of sub-quests. A FAILURE answer, or one without
+// numbered steps, is returned as an error.
+func (c *clientImpl) PlanQuest(intent string, sysInfo *system.Info) ([]string, error) {
+	prompt := buildPlanPrompt(intent, sysInfo, c.privacy)
+	response, err := exponentialRetryForAiResponse(c.provider.GenerateResponse, prompt, 3, 1*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlan(response)
+}
+
+// AddChecksumVerification asks the oracle to rewrite a command or script so that every download is
+// verified against its official checksum or signature before use. A FAILURE response means no
+// official checksum is published for at least one download.
+func (c *clientImpl) AddChecksumVerification(content string, sysInfo *system.Info) (*AIResponse, error) {
+	prompt := buildChecksumPrompt(content, sysInfo)
+	response, err := c.generateComplete(prompt, nil, 3, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.recordStats(func(s *ParseStats) { s.RecordResponse(c.statsKey, IsWellFormedResponse(response)) })
+	return ParseAIResponse(response), nil
+}
+
+// FixCommand asks the oracle for a corrected command or script after a failed execution
+func (c *clientImpl) FixCommand(intent string, attempt Attempt, sysInfo *system.Info) (*AIResponse, error) {
+	prompt := buildFixPrompt(intent, attempt, sysInfo, c.privacy)
+	response, err := c.generateComplete(prompt, c.conversation, 5, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.recordStats(func(s *ParseStats) {
+		s.RecordRegeneration(c.statsKey)
+		s.RecordResponse(c.statsKey, IsWellFormedResponse(response))
+	})
+	return ParseAIResponse(response), nil
+}
+
+func (c *clientImpl) ListCleanupCandidates(intent string, sysInfo *system.Info) (*AIResponse, error) {
+	prompt := buildCleanupListingPrompt(intent, sysInfo, c.privacy)
+	response, err := c.generateComplete(prompt, nil, 3, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.recordStats(func(s *ParseStats) { s.RecordResponse(c.statsKey, IsWellFormedResponse(response)) })
+	return ParseAIResponse(response), nil
+}
+
+func (c *clientImpl) GenerateRollback(intent string, attempt Attempt, sysInfo *system.Info) (*AIResponse, error) {
+	prompt := buildRollbackPrompt(intent, attempt, sysInfo, c.privacy)
+	response, err := c.generateComplete(prompt, nil, 5, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.recordStats(func(s *ParseStats) { s.RecordResponse(c.statsKey, IsWellFormedResponse(response)) })
+	return ParseAIResponse(response), nil
+}
+
+func (c *clientImpl) GenerateCommitMessage(changes *system.StagedChanges, rejected []string) (string, error) {
+	prompt := buildCommitMessagePrompt(changes, rejected)
+	response, err := c.generateComplete(prompt, nil, 3, nil)
+	if err != nil {
+		return "", err
+	}
+	message := ParseCommitMessage(response)
+	if message == "" {
+		return "", parseFailure(fmt.Errorf("the oracle wrote an empty commit message"))
+	}
+	return message, nil
 }
 
 func (c *clientImpl) ListModels() ([]string, error) {
 	return c.provider.ListModels()
 }
 
-func buildCommandPrompt(intent string, sysInfo *system.Info) string {
+func buildCommandPrompt(intent string, sysInfo *system.Info, privacy config.PrivacyConfig) string {
 	primaryPackageManager := "the detected package manager"
 	if len(sysInfo.PackageManagers) > 0 {
 		primaryPackageManager = sysInfo.PackageManagers[0]
@@ -74,6 +256,32 @@ func buildCommandPrompt(intent string, sysInfo *system.Info) string {
 
 	// Determine script format based on shell
 	scriptFormat, commentPrefix := getScriptFormat(sysInfo.Shell)
+	responseFormat := describeJSONResponseFormat("For simple single commands", "For complex multi-step tasks", scriptFormat, commentPrefix)
+
+	// Withhold whatever the user has opted out of sharing
+	homeDir := scanned(sysInfo, disclose(privacy.AllowHomeDir(), sysInfo.HomeDir))
+	currentDir := disclose(privacy.AllowCurrentDir(), sysInfo.CurrentDir)
+	installedPackages := scanned(sysInfo, disclose(privacy.AllowInstalledPackages(), joinSlice(sysInfo.InstalledPackages)))
+	availableCommands := joinSlice(sysInfo.AvailableCommands)
+	if sysInfo.CommandsTruncated {
+		availableCommands += " (partial list; others may be installed)"
+	}
+	availableCommands = scanned(sysInfo, disclose(privacy.AllowAvailableCommands(), availableCommands))
+	sshHosts := scanned(sysInfo, disclose(privacy.AllowSSHHosts(), joinSlice(sysInfo.SSHHosts)))
+	processes := disclose(privacy.AllowProcesses(), sysInfo.Processes.String())
+	resources := scanned(sysInfo, sysInfo.Resources.String())
+	projectTypes := scanned(sysInfo, describeProjectTypes(sysInfo.ProjectTypes))
+	packageManagers := scanned(sysInfo, joinSlice(sysInfo.PackageManagers))
+	nix := scanned(sysInfo, sysInfo.Nix.String())
+	direnv := scanned(sysInfo, sysInfo.Direnv.Describe(privacy.AllowEnvrcVariables()))
+	immutable := scanned(sysInfo, sysInfo.Immutable.String())
+	rootAccess := scanned(sysInfo, sysInfo.Privileges.String())
+	if sysInfo.Immutable.Detected() && sysInfo.Immutable.Installer != "" {
+		primaryPackageManager = sysInfo.Immutable.Installer
+	}
+	if sysInfo.Quick {
+		primaryPackageManager = "the usual package manager for this OS"
+	}
 
 	prompt := fmt.Sprintf(`You are a command line expert for %s systems. Generate a single, safe command or a safe script based on the user's intent.
 
@@ -85,60 +293,119 @@ SYSTEM INFORMATION:
 - Current Directory: %s
 - Installed Packages: %s
 - Available Commands: %s
+- Configured SSH Hosts: %s
+- Running Processes: %s
+- CPUs and Memory: %s
+- Project Type (current directory): %s
+- Nix: %s
+- direnv: %s
+- Immutable System: %s
+- Root Access: %s
 
-USER INTENT: %s
-
-RESPONSE FORMAT:
-You must respond with exactly ONE of these three formats:
-
-1. For simple single commands:
-COMMAND: [single shell command with no formatting]
-
-2. For complex multi-step tasks:
-SCRIPT:
-`+"```"+`%s
-%s Brief description of what this command does
-command1
-%s Brief description of what this command does  
-command2
-`+"```"+`
-
-3. For impossible/unsafe tasks:
-FAILURE: [Brief reason why task cannot be completed]
+USER INTENT:
+%s
 
+%s
 REQUIREMENTS:
 1. All commands and scripts must be SAFE and non-destructive.
 2. First, check the "Installed Packages" and "Available Commands" lists to see if required applications are available.
-3. If a required application is NOT available, include installation using the primary package manager '%s' (e.g., 'brew install htop', 'apt install htop', 'winget install htop').
-4. For SCRIPT responses: Each command must have a brief one-line comment above it explaining what it does.
-5. For SCRIPT responses: Use %s syntax for comments and ensure commands work in %s shell.
-6. For SCRIPT responses: Use proper %s syntax and ensure commands can run in sequence in the same shell session.
+3. If a required application is NOT available, include installation using the primary package manager '%s' (e.g., 'brew install htop', 'apt install htop', 'winget install htop'), unless requirement 12, 13, or 17 applies.
+4. For scripts: Each command must have a brief one-line comment above it explaining what it does.
+5. For scripts: Use %s syntax for comments and ensure commands work in %s shell.
+6. For scripts: Use proper %s syntax and ensure commands can run in sequence in the same shell session.
 7. Use safe and non-destructive flags where possible (e.g., 'cp -i' for interactive copy, 'rm -i' for interactive removal).
-8. If any directory reference is vague (e.g., "some folder"), respond with FAILURE: Directory reference too vague.
-9. Choose SCRIPT over COMMAND when the task requires multiple steps, environment setup, or variable usage.
+8. If any directory reference is vague (e.g., "some folder"), respond with a failure of category vague_path.
+9. Choose a script over a command when the task requires multiple steps, environment setup, or variable usage.
+10. If the intent refers to a remote machine (e.g., "the staging box"), use the matching alias from "Configured SSH Hosts" (e.g., 'ssh staging') instead of inventing a hostname.
+11. If the intent refers to building, testing, or running "the project" (e.g., "run the tests"), use the build tool from "Project Type" (e.g., 'cargo test' for cargo, './gradlew test' for gradle).
+12. If "Nix" is detected, never install packages globally ('nix-env -i', 'nix profile install', 'apt install', or any sudo install). Run project commands through the project's dev shell ('nix develop --command cargo test' with a flake, 'nix-shell --run "..."' with shell.nix, 'devenv shell' with devenv.nix) unless already running inside a Nix shell, and use 'nix run nixpkgs#<package>' or 'nix shell nixpkgs#<package> --command ...' for one-off tools. Suggest adding lasting packages to the flake or configuration.nix instead of installing them.
+13. If "Immutable System" is detected (anything other than "not detected" or "(writable)"), the root filesystem cannot be changed by the usual package manager: never use 'dnf install', 'apt install', 'pacman -S', or 'sudo' writes under /usr. Use 'flatpak install flathub <app>' for desktop applications, 'toolbox run' or 'distrobox enter' for command line and development tools, and the listed installer only for system packages ('rpm-ostree install <package>' or 'transactional-update pkg install <package>', and say that a reboot is needed). In a read-only container, write only to writable places such as /tmp, the home directory, or mounted volumes. Inside a writable toolbox or distrobox, the container's own package manager is fine.
+14. To wait for something to become ready, use the provided helper instead of sleep loops or retry loops: 'emw-wait --port 5432', 'emw-wait --url http://localhost:8080/health', or 'emw-wait --process nginx', with '--timeout 60s' (the default) and '--gone' to wait until it has stopped. It exits non-zero when the timeout passes, so chain the next step with '&&' (e.g. 'sudo systemctl restart nginx && emw-wait --url http://localhost/ --timeout 30s').
+15. If "Running Processes" lists processes matching the intent, target them by the listed PID or exact name (e.g. 'kill 4242', 'pkill -x node') instead of guessing. If none match by name, never stop or kill any of the listed processes; inspect them instead (e.g. 'ps -o pid,rss,args -p 4242').
+16. Size parallel jobs and memory by "CPUs and Memory", not by the host: when it reports a cgroup quota, use its CPU count literally (e.g. 'make -j2', 'cargo build -j 2') instead of '$(nproc)', which reports the host's CPUs inside a container, and keep heaps and caches (e.g. '-Xmx', 'NODE_OPTIONS=--max-old-space-size') well within its memory limit.
+17. If "Root Access" starts with "none", the user cannot become root: never use 'sudo', 'su', 'doas', or 'pkexec', never install with the system package manager, and never write under /usr, /etc, or /opt. Install into the home directory instead: 'pip install --user <package>' (or 'pipx install <package>'), 'npm install -g <package>' after 'npm config set prefix ~/.local', 'cargo install <package>', 'go install <module>@latest', or a release binary downloaded into ~/.local/bin (created with 'mkdir -p ~/.local/bin'). Call such a binary by its full path when ~/.local/bin is not in PATH.
+18. If "direnv" reports an .envrc that is "not loaded", the project's commands expect its variables: run them through 'direnv exec <directory of the .envrc> <command>' when direnv is installed, and otherwise say which exported variables must be set first. If it is "loaded", the variables are already set, so use them (e.g. "$DATABASE_URL") instead of hardcoding values. Never print, echo, or log the values of .envrc variables.
+19. %s
 
-RESPONSE:`,
-		sysInfo.OS,                           // systems
-		sysInfo.OS,                           // OS
-		sysInfo.Shell,                        // Shell
-		joinSlice(sysInfo.PackageManagers),   // Available Package Managers
-		sysInfo.HomeDir,                      // Home Directory
-		sysInfo.CurrentDir,                   // Current Directory
-		joinSlice(sysInfo.InstalledPackages), // Installed Packages
-		joinSlice(sysInfo.AvailableCommands), // Available Commands
-		intent,                               // USER INTENT
-		scriptFormat,                         // script format (```bash)
-		commentPrefix,                        // comment prefix (first comment)
-		commentPrefix,                        // comment prefix (second comment)
-		primaryPackageManager,                // primary package manager
-		commentPrefix,                        // comment syntax
-		sysInfo.Shell,                        // shell name
-		scriptFormat,                         // script format (proper bash syntax)
+`+jsonResponseMarker,
+		sysInfo.OS,                            // systems
+		sysInfo.OS,                            // OS
+		sysInfo.Shell,                         // Shell
+		packageManagers,                       // Available Package Managers
+		homeDir,                               // Home Directory
+		currentDir,                            // Current Directory
+		installedPackages,                     // Installed Packages
+		availableCommands,                     // Available Commands
+		sshHosts,                              // Configured SSH Hosts
+		processes,                             // Running Processes
+		resources,                             // CPUs and Memory
+		projectTypes,                          // Project Type
+		nix,                                   // Nix
+		direnv,                                // direnv
+		immutable,                             // Immutable System
+		rootAccess,                            // Root Access
+		QuoteUntrusted("USER INTENT", intent), // USER INTENT
+		responseFormat,                        // RESPONSE FORMAT
+		primaryPackageManager,                 // primary package manager
+		commentPrefix,                         // comment syntax
+		sysInfo.Shell,                         // shell name
+		scriptFormat,                          // script format (proper bash syntax)
+		untrustedDataRule,                     // untrusted sections
 	)
 
 	return prompt
 }
 
+// earlierOutputLines bounds the output kept for each earlier failed attempt in a fix prompt; the
+// latest attempt keeps its whole captured tail
+const earlierOutputLines = 10
+
+// buildFixPrompt extends the command prompt with the failed attempt so the oracle can correct it
+func buildFixPrompt(intent string, attempt Attempt, sysInfo *system.Info, privacy config.PrivacyConfig) string {
+	base := strings.TrimSuffix(buildCommandPrompt(intent, sysInfo, privacy), jsonResponseMarker)
+
+	if len(attempt.Earlier) > 0 {
+		base += "EARLIER ATTEMPTS:\nThese were executed for this intent before the previous attempt and FAILED too. Do not propose any of them again.\n"
+		for i, earlier := range attempt.Earlier {
+			base += fmt.Sprintf("Attempt %d (%s):\n%s\nLast output lines:\n%s\n", i+1, describeExit(earlier), QuoteUntrusted("FAILED ATTEMPT", earlier.Content), QuoteUntrusted("OUTPUT", lastLines(earlier.Output, earlierOutputLines)))
+		}
+		base += "\n"
+	}
+
+	return base + fmt.Sprintf(`PREVIOUS ATTEMPT:
+The following was executed for this intent and FAILED (%s):
+%s
+Error:
+%s
+Last output lines:
+%s
+
+Diagnose the failure from the exit status, error and output above and respond with a corrected command or script using the same RESPONSE FORMAT. Do not repeat the failed attempt unchanged. If the failure cannot be fixed safely, respond with a failure and the reason.
+
+%s`, describeExit(attempt), QuoteUntrusted("FAILED ATTEMPT", attempt.Content), QuoteUntrusted("ERROR", attempt.Error), QuoteUntrusted("OUTPUT", lastLines(attempt.Output, 0)), jsonResponseMarker)
+}
+
+// describeExit tells how a failed attempt ended, for a fix prompt
+func describeExit(attempt Attempt) string {
+	if attempt.ExitCode == nil {
+		return "no exit code was reported"
+	}
+	return fmt.Sprintf("exit code %d", *attempt.ExitCode)
+}
+
+// lastLines returns the last n lines of captured output, or all of it when n is 0, standing in
+// for output that is empty
+func lastLines(output string, n int) string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "(no output captured)"
+	}
+	if lines := strings.Split(output, "\n"); n > 0 && len(lines) > n {
+		output = strings.Join(lines[len(lines)-n:], "\n")
+	}
+	return output
+}
+
 func getScriptFormat(shell string) (scriptFormat, commentPrefix string) {
 	switch shell {
 	case "powershell", "pwsh":
@@ -153,7 +420,7 @@ func getScriptFormat(shell string) (scriptFormat, commentPrefix string) {
 	}
 }
 
-func buildExplanationPrompt(command string, sysInfo *system.Info) string {
+func buildExplanationPrompt(command string, sysInfo *system.Info, privacy config.PrivacyConfig) string {
 	prompt := fmt.Sprintf(`You are an expert explaining command-line instructions to someone new to the terminal.
 
 SYSTEM INFO:
@@ -162,22 +429,401 @@ SYSTEM INFO:
 - Current Dir: %s
 - Home Dir: %s
 
-COMMAND: %s
+COMMAND:
+%s
 
 INSTRUCTIONS:
 Explain what this command does in one clear, simple paragraph. Break down the parts in plain English, avoiding technical jargon where possible. Focus on what the command does, what each part means, and why someone might use it. Be friendly, helpful, and avoid assuming any prior knowledge of the shell.
+%s
 
 EXPLANATION:`,
 		sysInfo.OS,
 		sysInfo.Shell,
-		sysInfo.CurrentDir,
-		sysInfo.HomeDir,
-		command,
+		disclose(privacy.AllowCurrentDir(), sysInfo.CurrentDir),
+		scanned(sysInfo, disclose(privacy.AllowHomeDir(), sysInfo.HomeDir)),
+		QuoteUntrusted("COMMAND", command),
+		untrustedDataRule,
 	)
 
 	return prompt
 }
 
+// commandNeeded is the oracle's reply when a question can only be answered by running a command
+const commandNeeded = "COMMAND NEEDED"
+
+func buildQuestionPrompt(question string, sysInfo *system.Info) string {
+	return fmt.Sprintf(`You are a command line expert for %s systems using the %s shell. The user asked a question instead of giving a task.
+
+%s
+
+RESPONSE FORMAT:
+If the question asks what something means, how it works, or which of several options to choose (e.g. "what does chmod 755 mean?", "what is the difference between apt and snap?"), respond with:
+ANSWER: [a short plain-text answer of at most a few sentences, with an example command when it helps]
+
+If answering needs facts about this machine, such as its files, processes, ports, or installed software (e.g. "what is using port 8080?"), or the question really asks for something to be done (e.g. "can you clean up my downloads?"), respond with exactly:
+%s
+
+REQUIREMENTS:
+1. Do not use markdown; the answer is shown in a plain text box.
+2. Answer for this OS and shell when it matters.
+3. %s
+
+RESPONSE:`,
+		sysInfo.OS,
+		sysInfo.Shell,
+		QuoteUntrusted("USER INTENT", question),
+		commandNeeded,
+		untrustedDataRule,
+	)
+}
+
+func buildSummaryPrompt(intent, content, output string, lines int) string {
+	return fmt.Sprintf(`You summarize the output of command-line tasks for the person who ran them.
+
+%s
+
+%s
+
+OUTPUT (%d lines; long stretches are omitted and marked):
+%s
+
+INSTRUCTIONS:
+Summarize the outcome in at most five short lines of plain text, without markdown. Lead with what was done, with counts where the output gives them (for example "327 packages upgraded"). Then list warnings, errors, and anything the user should act on. Do not invent numbers the output does not show, and say so if the omitted parts may hide details.
+%s
+
+SUMMARY:`,
+		QuoteUntrusted("INTENT", intent),
+		QuoteUntrusted("COMMAND", content),
+		lines,
+		QuoteUntrusted("OUTPUT", output),
+		untrustedDataRule,
+	)
+}
+
+func buildIncidentPrompt(incident Incident) string {
+	output := strings.TrimSpace(incident.Output)
+	if output == "" {
+		output = "(no output captured)"
+	}
+	errorText := strings.TrimSpace(incident.Error)
+	if errorText == "" {
+		errorText = "(none)"
+	}
+
+	return fmt.Sprintf(`You help a team write blameless incident notes about command-line tasks that failed or changed more than intended.
+
+%s
+
+%s
+
+OUTCOME: %s (risk: %s)
+
+%s
+
+%s
+
+INSTRUCTIONS:
+Write three short markdown sections, without a top-level heading:
+### What happened
+Two or three sentences on what ran and what it did to the system.
+### Likely cause
+The most probable technical cause, based only on the error and output above. Say so if they are not enough to tell.
+### Follow-ups
+Two to four "- " bullet points that would prevent a repeat or reduce the impact (checks, safer flags, dry runs, backups).
+
+Keep it blameless: describe what the command and the system did and why, never who was careless or at fault.
+%s
+
+NOTE:`,
+		QuoteUntrusted("INTENT", incident.Intent),
+		QuoteUntrusted("COMMAND", incident.Content),
+		incident.Outcome,
+		incident.Risk,
+		QuoteUntrusted("ERROR", errorText),
+		QuoteUntrusted("OUTPUT", output),
+		untrustedDataRule,
+	)
+}
+
+// MaxPlanSteps caps the sub-quests of a plan; a longer plan should be split into several quests
+const MaxPlanSteps = 12
+
+func buildPlanPrompt(intent string, sysInfo *system.Info, privacy config.PrivacyConfig) string {
+	return fmt.Sprintf(`You are a planner for command-line tasks on %s systems. The user's intent is too large for a single command or script. Break it into a short, ordered plan of sub-quests.
+
+SYSTEM INFORMATION:
+- OS: %s
+- Shell: %s
+- Available Package Managers: %s
+- Current Directory: %s
+- Project Type (current directory): %s
+- Nix: %s
+- Immutable System: %s
+- Root Access: %s
+
+%s
+
+RESPONSE FORMAT:
+PLAN:
+1. [first sub-quest]
+2. [second sub-quest]
+
+or, when the intent is unsafe or impossible:
+FAILURE: [Brief reason]
+
+REQUIREMENTS:
+1. Each sub-quest is a plain-language intent, not a command, that a single command or a short script can carry out (e.g. "install Apache, MariaDB, and PHP with the system package manager").
+2. Each sub-quest must make sense on its own: name the packages, paths, and services it is about instead of saying "it" or "the above".
+3. Order the sub-quests so that each one only depends on earlier ones. Put checks of the result (e.g. "check that Apache answers on port 80") where they help catch a failure early.
+4. Use at most %d sub-quests and leave out anything the intent did not ask for.
+5. If the intent is small enough for one script, respond with a one-step plan.
+6. %s
+
+RESPONSE:`,
+		sysInfo.OS,
+		sysInfo.OS,
+		sysInfo.Shell,
+		scanned(sysInfo, joinSlice(sysInfo.PackageManagers)),
+		disclose(privacy.AllowCurrentDir(), sysInfo.CurrentDir),
+		scanned(sysInfo, describeProjectTypes(sysInfo.ProjectTypes)),
+		scanned(sysInfo, sysInfo.Nix.String()),
+		scanned(sysInfo, sysInfo.Immutable.String()),
+		scanned(sysInfo, sysInfo.Privileges.String()),
+		QuoteUntrusted("USER INTENT", intent),
+		MaxPlanSteps,
+		untrustedDataRule,
+	)
+}
+
+// planStep matches one numbered line of a plan, e.g. "2. Install PHP" or "2) Install PHP"
+var planStep = regexp.MustCompile(`^(\d+)[.)]\s+(.+)$`)
+
+// ParsePlan reads the numbered sub-quests of a PLAN: answer. Lines that are not numbered steps,
+// such as a closing remark, are ignored.
+func ParsePlan(response string) ([]string, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "FAILURE:") {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(strings.TrimPrefix(response, "FAILURE:")))
+	}
+	response = strings.TrimSpace(strings.TrimPrefix(response, "PLAN:"))
+
+	var steps []string
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "*"))
+		if m := planStep.FindStringSubmatch(line); m != nil {
+			steps = append(steps, strings.TrimSpace(m[2]))
+		}
+	}
+
+	switch {
+	case len(steps) == 0:
+		return nil, parseFailure(fmt.Errorf("the oracle did not answer with a numbered plan"))
+	case len(steps) > MaxPlanSteps:
+		return nil, fmt.Errorf("the plan has %d steps, more than the %d allowed; split the quest into smaller ones", len(steps), MaxPlanSteps)
+	}
+	return steps, nil
+}
+
+// disclose returns the value only when the user allows it to be shared
+func disclose(allowed bool, value string) string {
+	if !allowed {
+		return notDisclosed
+	}
+	return value
+}
+
+// scanned returns the value unless the quest skipped the system scan; a quick quest shares
+// only the OS, shell, and current directory
+func scanned(sysInfo *system.Info, value string) string {
+	if sysInfo.Quick {
+		return notScanned
+	}
+	return value
+}
+
+func buildChecksumPrompt(content string, sysInfo *system.Info) string {
+	scriptFormat, commentPrefix := getScriptFormat(sysInfo.Shell)
+
+	return fmt.Sprintf(`You are a security-minded command line expert for %s systems using the %s shell.
+
+The following command or script downloads software and runs or installs it without verifying it:
+
+`+"```"+`%s
+%s
+`+"```"+`
+
+INSTRUCTIONS:
+1. Rewrite it as a script that downloads each file to disk first, fetches the OFFICIAL checksum or signature published by the same project (for example a SHA256SUMS file or .asc signature on the official release page), verifies it, and aborts if verification fails.
+2. Never pipe downloaded content directly into a shell.
+3. Only use checksum or signature locations that the project officially publishes. Do not invent URLs or hashes.
+4. Each command must have a brief one-line comment above it using %s.
+5. If no official checksum or signature exists for any download, respond with a failure whose reason names the download that cannot be verified.
+
+%s
+`+jsonResponseMarker,
+		sysInfo.OS,
+		sysInfo.Shell,
+		scriptFormat,
+		content,
+		commentPrefix,
+		describeJSONResponseFormat("", "For the rewritten script", scriptFormat, commentPrefix),
+	)
+}
+
+// buildCleanupListingPrompt asks for a command that only lists what a cleanup intent would delete,
+// so the user can pick the files before anything is removed
+func buildCleanupListingPrompt(intent string, sysInfo *system.Info, privacy config.PrivacyConfig) string {
+	homeDir := scanned(sysInfo, disclose(privacy.AllowHomeDir(), sysInfo.HomeDir))
+	currentDir := disclose(privacy.AllowCurrentDir(), sysInfo.CurrentDir)
+
+	return fmt.Sprintf(`You are a command line expert for %s systems using the %s shell.
+
+The user wants to delete files:
+%s
+
+Do NOT delete anything. Instead, write ONE read-only command that lists the files or directories this intent refers to.
+
+SYSTEM INFORMATION:
+- Home Directory: %s
+- Current Directory: %s (the command runs here)
+
+REQUIREMENTS:
+1. Print one path per line and nothing else: no headers, sizes, dates, or colors.
+2. Use only read-only tools such as find, fd, ls, or Get-ChildItem with -Name/-FullName. Never use -delete, -exec, rm, or any command that changes files.
+3. Do not use command substitution, redirections to files, or sudo.
+4. Be conservative: match only what the intent clearly describes.
+5. If the files cannot be identified safely, respond with a failure and the reason.
+6. %s
+
+%s
+`+jsonResponseMarker,
+		sysInfo.OS,
+		sysInfo.Shell,
+		QuoteUntrusted("USER INTENT", intent),
+		homeDir,
+		currentDir,
+		untrustedDataRule,
+		describeJSONResponseFormat("For the listing command", "", "", ""),
+	)
+}
+
+func buildRollbackPrompt(intent string, attempt Attempt, sysInfo *system.Info, privacy config.PrivacyConfig) string {
+	scriptFormat, commentPrefix := getScriptFormat(sysInfo.Shell)
+	homeDir := scanned(sysInfo, disclose(privacy.AllowHomeDir(), sysInfo.HomeDir))
+	currentDir := disclose(privacy.AllowCurrentDir(), sysInfo.CurrentDir)
+
+	kind := "command"
+	if strings.Contains(strings.TrimSpace(attempt.Content), "\n") {
+		kind = "script"
+	}
+	outcome := "It completed successfully."
+	if attempt.Error != "" {
+		outcome = fmt.Sprintf("It FAILED partway (%s), so only some of its changes may have been made.", attempt.Error)
+	}
+
+	return fmt.Sprintf(`You are a command line expert for %s systems using the %s shell.
+
+The user ran the following quest and now wants to undo it:
+%s
+
+It ran this %s:
+%s
+
+%s
+
+Write a command or script that reverses exactly the changes it made, and nothing more.
+
+SYSTEM INFORMATION:
+- Available Package Managers: %s
+- Home Directory: %s
+- Current Directory: %s (the reversal runs here)
+- Root Access: %s
+
+REQUIREMENTS:
+1. Reverse only what the quest changed: remove what it created, restore what it renamed or moved, uninstall what it installed with the same package manager, stop or disable what it started or enabled, and revert settings it changed to their previous values when those are known.
+2. Never delete, overwrite, or change anything the quest did not create or change. Remove directories it created only when they are empty (e.g. 'rmdir'), unless the quest clearly created everything inside them.
+3. If a step cannot be reversed, because it deleted or overwrote data without a backup, sent data elsewhere, or its previous state is unknown, respond with a failure of category impossible that names what cannot be restored. Do not guess previous values.
+4. If the quest only read or listed things, respond with a failure of category impossible saying there is nothing to undo.
+5. If the quest failed partway, make each step safe to run when its change was never made (e.g. 'rm -f', '|| true', 'pip uninstall -y').
+6. Use a script with one comment per step when more than one change must be reversed. Reverse the changes in the opposite order to the one they were made in.
+7. %s
+
+%s
+`+jsonResponseMarker,
+		sysInfo.OS,
+		sysInfo.Shell,
+		QuoteUntrusted("ORIGINAL INTENT", intent),
+		kind,
+		QuoteUntrusted("EXECUTED", attempt.Content),
+		outcome,
+		scanned(sysInfo, joinSlice(sysInfo.PackageManagers)),
+		homeDir,
+		currentDir,
+		scanned(sysInfo, sysInfo.Privileges.String()),
+		untrustedDataRule,
+		describeJSONResponseFormat("For a reversal that is a single command", "For a reversal of several steps", scriptFormat, commentPrefix),
+	)
+}
+
+func buildCommitMessagePrompt(changes *system.StagedChanges, rejected []string) string {
+	diff := changes.Diff
+	if changes.Truncated {
+		diff += "\n[the diff was cut short here; the summary above covers every file]"
+	}
+	recent := "none (this is the first commit)"
+	if len(changes.RecentSubjects) > 0 {
+		recent = "\n  " + strings.Join(changes.RecentSubjects, "\n  ")
+	}
+	branch := changes.Branch
+	if branch == "" {
+		branch = "unknown"
+	}
+
+	prompt := fmt.Sprintf(`You are an experienced software engineer writing the git commit message for the staged changes below.
+
+BRANCH: %s
+RECENT COMMIT SUBJECTS (newest first):%s
+
+%s
+
+%s
+
+REQUIREMENTS:
+1. Start with a subject line of at most 72 characters in the imperative mood (e.g. "Fix", "Add", "Remove"), without a trailing period.
+2. Follow the style of the recent commit subjects: if they use a convention such as "feat(scope): ...", "[TICKET-123] ...", or "area: ...", use it too, taking the scope or ticket from the branch name or the changed files.
+3. When the change is not obvious from the subject, add a blank line and a short body wrapped at 72 characters explaining what changed and why. Leave out the body for small, self-explanatory changes.
+4. Describe only what the diff shows. Do not invent motivations, issue numbers, or tests that are not there.
+5. %s
+`,
+		branch,
+		recent,
+		QuoteUntrusted("STAGED FILES", changes.Stat),
+		QuoteUntrusted("STAGED DIFF", diff),
+		untrustedDataRule,
+	)
+	if len(rejected) > 0 {
+		prompt += fmt.Sprintf("6. The user rejected these messages; write a different one:\n%s\n", QuoteUntrusted("REJECTED MESSAGES", strings.Join(rejected, "\n---\n")))
+	}
+	return prompt + "\nRespond with the commit message only: no markdown, no code fences, and no text before or after it.\n"
+}
+
+// ParseCommitMessage takes the commit message from the oracle's answer, unwrapping the code fence
+// some models put around it and removing any "Commit message:" label before it
+func ParseCommitMessage(response string) string {
+	message := strings.TrimSpace(response)
+	if fenced, ok := strings.CutPrefix(message, "```"); ok {
+		_, body, _ := strings.Cut(fenced, "\n")
+		body, _, _ = strings.Cut(body, "```")
+		message = strings.TrimSpace(body)
+	}
+	for _, label := range []string{"commit message:", "message:"} {
+		if len(message) >= len(label) && strings.EqualFold(message[:len(label)], label) {
+			message = strings.TrimSpace(message[len(label):])
+		}
+	}
+	return message
+}
+
 func joinSlice(slice []string) string {
 	if len(slice) == 0 {
 		return "none"
@@ -190,8 +836,59 @@ func joinSlice(slice []string) string {
 	return strings.Join(slice, ", ")
 }
 
-func parseAIResponse(response string) *AIResponse {
+func (c *clientImpl) Exchanges() []Exchange {
+	return append([]Exchange(nil), c.recorder.exchanges...)
+}
+
+// describeProjectTypes lists the detected build tools for the prompt
+func describeProjectTypes(types []system.ProjectType) string {
+	if len(types) == 0 {
+		return "none detected"
+	}
+	descriptions := make([]string, 0, len(types))
+	for _, t := range types {
+		descriptions = append(descriptions, t.String())
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// recordStats updates the cross-run statistics file. Statistics are best effort and never fail a quest.
+func (c *clientImpl) recordStats(update func(*ParseStats)) {
+	if c.statsPath == "" {
+		return
+	}
+	stats, err := LoadParseStats(c.statsPath)
+	if err != nil {
+		return
+	}
+	update(stats)
+	_ = stats.Save(c.statsPath)
+}
+
+// IsWellFormedResponse reports whether a raw response is a valid JSON response object or starts
+// with one of the expected markers
+func IsWellFormedResponse(response string) bool {
 	response = strings.TrimSpace(response)
+	if isWellFormedStructuredResponse(response) {
+		return true
+	}
+	for _, marker := range responseMarkers {
+		if strings.HasPrefix(response, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAIResponse turns a raw provider answer into a command, script, failure, or answer. Generation
+// prompts ask for a JSON object; the COMMAND:, SCRIPT:, FAILURE:, and ANSWER: markers are still
+// understood, as question prompts and mock responses use them. Anything else becomes a malformed
+// failure and is never run. Every change here must keep test/testdata/responses.yaml passing.
+func ParseAIResponse(response string) *AIResponse {
+	response = strings.TrimSpace(response)
+	if structured, ok := parseStructuredResponse(response); ok {
+		return structured
+	}
 
 	if strings.HasPrefix(response, "COMMAND:") {
 		content := strings.TrimSpace(strings.TrimPrefix(response, "COMMAND:"))
@@ -218,16 +915,90 @@ func parseAIResponse(response string) *AIResponse {
 
 	if strings.HasPrefix(response, "FAILURE:") {
 		errorMsg := strings.TrimSpace(strings.TrimPrefix(response, "FAILURE:"))
+		category, errorMsg := parseFailureCategory(errorMsg)
 		return &AIResponse{
-			Type:  ResponseTypeFailure,
-			Error: errorMsg,
+			Type:     ResponseTypeFailure,
+			Error:    errorMsg,
+			Category: category,
+		}
+	}
+
+	if strings.HasPrefix(response, "ANSWER:") {
+		return &AIResponse{
+			Type:    ResponseTypeAnswer,
+			Content: strings.TrimSpace(strings.TrimPrefix(response, "ANSWER:")),
+		}
+	}
+
+	// Prose may be a refusal or an explanation, and is never proposed as a command
+	if response == "" {
+		return malformedResponse("it is empty")
+	}
+	return malformedResponse("it is neither a JSON object nor starts with a marker")
+}
+
+// failureCategoryPattern matches the "[category]" tag that starts a categorized failure
+var failureCategoryPattern = regexp.MustCompile(`^\[\s*([A-Za-z_ -]+?)\s*\]\s*`)
+
+// parseFailureCategory splits a known category tag from a failure reason. Unknown tags are left
+// in the reason, since they may be part of it.
+func parseFailureCategory(reason string) (FailureCategory, string) {
+	match := failureCategoryPattern.FindStringSubmatch(reason)
+	if match == nil {
+		return FailureUncategorized, reason
+	}
+	if category, ok := lookupFailureCategory(match[1]); ok {
+		return category, strings.TrimSpace(reason[len(match[0]):])
+	}
+	return FailureUncategorized, reason
+}
+
+// lookupFailureCategory returns the category the oracle named, tolerating case, spaces, and dashes
+func lookupFailureCategory(name string) (FailureCategory, bool) {
+	name = strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(name)))
+	for _, category := range FailureCategories {
+		if name == string(category) {
+			return category, true
 		}
 	}
+	return FailureUncategorized, false
+}
 
-	// Default fallback - treat as command for backward compatibility
-	return &AIResponse{
-		Type:    ResponseTypeCommand,
-		Content: response,
+// generateComplete asks the provider for a JSON or ANSWER: response, retrying
+// failed requests up to maxRetries times. A response that was cut off before it ended is asked
+// for again with the same prompt, and discarded when that keeps happening, so that half a
+// command is never proposed. A non-nil handler is shown every attempt as it is generated, and
+// a non-empty history is sent as the earlier turns of the conversation.
+func (c *clientImpl) generateComplete(prompt string, history []Message, maxRetries int, handler StreamHandler) (string, error) {
+	if len(history) > 0 {
+		prompt = sessionRule + "\n\n" + prompt
+	}
+	generate := func(prompt string) (string, error) {
+		return converse(c.provider, history, prompt, nil)
+	}
+	if handler != nil {
+		generate = func(prompt string) (string, error) {
+			handler.Begin()
+			defer handler.End()
+			return converse(c.provider, history, prompt, handler.Chunk)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := exponentialRetryForAiResponse(generate, prompt, maxRetries, 1*time.Second)
+		if err != nil {
+			return "", err
+		}
+		if !IsTruncatedResponse(response) {
+			return response, nil
+		}
+		c.recordStats(func(s *ParseStats) { s.RecordTruncation(c.statsKey) })
+		if attempt == maxTruncatedRetries {
+			return "", parseFailure(fmt.Errorf("the answer was cut off %d times in a row and was discarded rather than run incomplete", attempt+1))
+		}
+		if OnTruncatedRetry != nil {
+			OnTruncatedRetry()
+		}
 	}
 }
 
@@ -240,7 +1011,7 @@ func exponentialRetryForAiResponse(fn func(string) (string, error), prompt strin
 		if err == nil {
 			return resp, nil
 		}
-		fmt.Println("🌀" + " " + "The oracles have rejected us, sire. I will try again...")
+		fmt.Println("🌀 The oracles have rejected us, sire. I will try again...")
 		time.Sleep(delay)
 		delay *= 2
 		if delay > 10*time.Second {
@@ -249,6 +1020,6 @@ func exponentialRetryForAiResponse(fn func(string) (string, error), prompt strin
 
 	}
 
-	return "", fmt.Errorf("failed to get response after %d attempts: %v", maxRetries, err)
+	return "", fmt.Errorf("failed to get response after %d attempts: %w", maxRetries, err)
 
 }
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/conversation.go
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The roles of the messages of a conversation
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one earlier turn of a conversation with the oracle
+type Message struct {
+	Role    string // RoleUser or RoleAssistant
+	Content string
+}
+
+// ConversationProvider is a provider that can send the earlier turns of a conversation as
+// messages of their own, ahead of the prompt. A nil onChunk asks for the whole answer at once.
+type ConversationProvider interface {
+	AIProvider
+	Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error)
+}
+
+// sessionRule tells the oracle what the messages before a prompt are
+const sessionRule = "SESSION: the messages before this one are the earlier quests of this session, each with the answer you gave and what happened when it ran. Where the intent below says \"that\", \"it\", \"those files\", or \"now\", it refers to them. Answer only the intent below, in the response format below."
+
+// converse asks provider for an answer to prompt after the turns of history, handing it to
+// onChunk as it is generated when onChunk is not nil. A provider that cannot hold a
+// conversation is sent the history written out at the top of the prompt instead.
+func converse(provider AIProvider, history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	if len(history) > 0 {
+		if conversing, ok := provider.(ConversationProvider); ok {
+			return conversing.Converse(history, prompt, onChunk)
+		}
+		prompt = flattenConversation(history, prompt)
+	}
+	if onChunk == nil {
+		return provider.GenerateResponse(prompt)
+	}
+	return streamResponse(provider, prompt, onChunk)
+}
+
+// chatMessages returns the history followed by the prompt as a user message. Neighbouring
+// messages of the same role are joined, since the APIs expect the roles to alternate.
+func chatMessages(history []Message, prompt string) []Message {
+	var messages []Message
+	for _, message := range append(append([]Message(nil), history...), Message{Role: RoleUser, Content: prompt}) {
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == message.Role {
+			messages[last].Content += "\n\n" + message.Content
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// flattenConversation writes the history out ahead of the prompt, for providers that only
+// take a single prompt
+func flattenConversation(history []Message, prompt string) string {
+	var text strings.Builder
+	text.WriteString("EARLIER IN THIS SESSION:\n")
+	for _, message := range history {
+		fmt.Fprintf(&text, "\n[%s]\n%s\n", strings.ToUpper(message.Role), message.Content)
+	}
+	text.WriteString("\n[END OF THE EARLIER QUESTS]\n\n")
+	text.WriteString(prompt)
+	return text.String()
+}
+
+// ProposalMessage is the oracle's side of an earlier turn that proposed content, written as the
+// StructuredResponse it would have answered with
+func ProposalMessage(content string, isScript bool) Message {
+	proposal := StructuredResponse{Type: "command", Command: content}
+	if isScript {
+		proposal = StructuredResponse{Type: "script", Script: content}
+	}
+	data, _ := json.Marshal(proposal)
+	return Message{Role: RoleAssistant, Content: string(data)}
+}
+
+// RefusalMessage is the oracle's side of an earlier turn it refused, written as the
+// StructuredResponse it would have answered with
+func RefusalMessage(reason string) Message {
+	data, _ := json.Marshal(StructuredResponse{Type: "failure", Reason: reason})
+	return Message{Role: RoleAssistant, Content: string(data)}
+}
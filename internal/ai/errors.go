@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/errors.go
+package ai
+
+import (
+	"errors"
+	"net/http"
+)
+
+// The kinds of errors the oracle's client returns. Callers tell them apart with errors.Is; the
+// messages of the errors are unchanged by their kind.
+var (
+	// ErrProviderUnavailable is a provider that could not be reached, was overloaded, or failed on
+	// its side. Asking again later may succeed. A provider that refused the request, as for a wrong
+	// API key, is not unavailable; its ProviderError carries the status.
+	ErrProviderUnavailable = errors.New("the AI provider is unavailable")
+	// ErrParseFailure is an answer that arrived but could not be read: malformed JSON, an empty or
+	// cut-off answer, or a plan without numbered steps
+	ErrParseFailure = errors.New("the AI response could not be parsed")
+)
+
+// ProviderError is a request the AI provider did not answer, or answered with an error
+type ProviderError struct {
+	StatusCode int // the HTTP status, 0 when no answer arrived
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the provider unavailable when no answer arrived, the request timed out, the rate
+// limit was hit, or the server failed
+func (e *ProviderError) Is(target error) bool {
+	if target != ErrProviderUnavailable {
+		return false
+	}
+	return e.StatusCode == 0 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// providerError wraps an error of a request to the provider, with the HTTP status of the answer
+// or 0 when none arrived
+func providerError(status int, err error) error {
+	return &ProviderError{StatusCode: status, Err: err}
+}
+
+// kindError gives an error one of the kinds above while keeping its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// parseFailure marks err as an answer that could not be read
+func parseFailure(err error) error {
+	return &kindError{kind: ErrParseFailure, err: err}
+}
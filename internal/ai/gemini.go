@@ -24,6 +24,7 @@ type GeminiProvider struct {
 	model       string
 	maxTokens   int
 	temperature float32
+	apiVersion  string // the API path segment, e.g. v1beta or v1
 }
 
 type GeminiRequest struct {
@@ -32,6 +33,7 @@ type GeminiRequest struct {
 }
 
 type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // user or model; a single prompt goes without
 	Parts []GeminiPart `json:"parts"`
 }
 
@@ -40,8 +42,9 @@ type GeminiPart struct {
 }
 
 type GeminiGenerationConfig struct {
-	MaxOutputTokens int     `json:"maxOutputTokens"`
-	Temperature     float32 `json:"temperature"`
+	MaxOutputTokens  int     `json:"maxOutputTokens"`
+	Temperature      float32 `json:"temperature"`
+	ResponseMimeType string  `json:"responseMimeType,omitempty"` // application/json for prompts that expect JSON
 }
 
 type GeminiResponse struct {
@@ -64,25 +67,58 @@ func NewGeminiProvider(cfg *config.Config) (*GeminiProvider, error) {
 		model:       cfg.Model,
 		maxTokens:   cfg.MaxTokens,
 		temperature: cfg.Temperature,
+		apiVersion:  cfg.APIVersion("gemini"),
 	}, nil
 }
 
+// responseMimeType asks for JSON when the prompt expects it, and for plain text otherwise
+func (g *GeminiProvider) responseMimeType(prompt string) string {
+	if !expectsJSON(prompt) {
+		return ""
+	}
+	return "application/json"
+}
+
 func (g *GeminiProvider) GenerateResponse(prompt string) (string, error) {
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", g.model, g.apiKey)
+	return g.Converse(nil, prompt, nil)
+}
 
+// StreamResponse is GenerateResponse with the answer handed to onChunk as it is generated
+func (g *GeminiProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	return g.Converse(nil, prompt, onChunk)
+}
+
+// Converse sends the history as contents of their own ahead of the prompt, streaming the answer
+// to onChunk when it is not nil
+func (g *GeminiProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
 	request := GeminiRequest{
-		Contents: []GeminiContent{
-			{
-				Parts: []GeminiPart{
-					{Text: prompt},
-				},
-			},
-		},
+		Contents: []GeminiContent{{Parts: []GeminiPart{{Text: prompt}}}},
 		GenerationConfig: GeminiGenerationConfig{
-			MaxOutputTokens: g.maxTokens,
-			Temperature:     g.temperature,
+			MaxOutputTokens:  g.maxTokens,
+			Temperature:      g.temperature,
+			ResponseMimeType: g.responseMimeType(prompt),
 		},
 	}
+	if len(history) > 0 {
+		request.Contents = nil
+		for _, message := range chatMessages(history, prompt) {
+			role := "user"
+			if message.Role == RoleAssistant {
+				role = "model"
+			}
+			request.Contents = append(request.Contents, GeminiContent{Role: role, Parts: []GeminiPart{{Text: message.Content}}})
+		}
+	}
+
+	if onChunk == nil {
+		return g.post(request)
+	}
+	return g.stream(request, onChunk)
+}
+
+// post sends a request with generateContent and returns the text of the answer
+func (g *GeminiProvider) post(request GeminiRequest) (string, error) {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/%s/models/%s:generateContent?key=%s", g.apiVersion, g.model, g.apiKey)
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
@@ -91,45 +127,70 @@ func (g *GeminiProvider) GenerateResponse(prompt string) (string, error) {
 
 	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", err
+		return "", providerError(0, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", providerError(0, err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", providerError(resp.StatusCode, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body)))
 	}
 
 	var response GeminiResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", err
+		return "", parseFailure(err)
 	}
 
 	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response generated")
+		return "", parseFailure(fmt.Errorf("no response generated"))
 	}
 
 	responseText := response.Candidates[0].Content.Parts[0].Text
 
-	// Handle failure cases as defined in the prompt
-	if responseText == "FAILURE: Intent too complex for a single shell command." {
-		return "", fmt.Errorf("intent too complex for a single shell command, might need merlin")
-	}
+	return responseText, nil
+}
+
+// stream is post with the answer handed to onChunk as it is generated. It uses
+// streamGenerateContent, whose events are each a GeminiResponse with the next piece of text.
+func (g *GeminiProvider) stream(request GeminiRequest, onChunk func(chunk string)) (string, error) {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/%s/models/%s:streamGenerateContent?alt=sse&key=%s", g.apiVersion, g.model, g.apiKey)
 
-	if responseText == "FAILURE: Directory reference too vague." {
-		return "", fmt.Errorf("directory reference too vague - please specify exact paths. the map instructions are not clear")
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
 	}
 
-	// Check for any other FAILURE responses
-	if len(responseText) >= 8 && responseText[:8] == "FAILURE:" {
-		return "", fmt.Errorf("command generation failed: %s", responseText[9:])
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return response.Candidates[0].Content.Parts[0].Text, nil
+	var text strings.Builder
+	err = postStream(req, func(data string) error {
+		var response GeminiResponse
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			return parseFailure(err)
+		}
+		if len(response.Candidates) > 0 {
+			for _, part := range response.Candidates[0].Content.Parts {
+				text.WriteString(part.Text)
+				onChunk(part.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if text.Len() == 0 {
+		return "", parseFailure(fmt.Errorf("no response generated"))
+	}
+	return text.String(), nil
 }
 
 func (g *GeminiProvider) ListModels() ([]string, error) {
@@ -141,7 +202,7 @@ func (g *GeminiProvider) ListModels() ([]string, error) {
 	var err error
 
 	for i := 0; i < maxRetries; i++ {
-		url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models?key=%s", g.apiKey)
+		url := fmt.Sprintf("https://generativelanguage.googleapis.com/%s/models?key=%s", g.apiVersion, g.apiKey)
 		resp, httpErr := http.Get(url)
 		if httpErr != nil {
 			err = fmt.Errorf("failed to make HTTP request to Gemini: %w", httpErr)
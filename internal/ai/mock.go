@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/mock.go
+package ai
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// mockNoMatch is answered when no canned response matches and the file sets no default
+const mockNoMatch = "FAILURE: The mock provider has no response for this intent."
+
+// MockProvider answers from canned responses in a local YAML file instead of an AI service. It is
+// selected with "provider: mock" and is meant for demos, offline development, and tests of hooks
+// and policies that need a predictable oracle.
+type MockProvider struct {
+	model     string
+	responses []mockResponse
+	fallback  string
+	models    []string
+}
+
+// MockResponses is the layout of the mock responses file
+type MockResponses struct {
+	Responses []MockResponse `yaml:"responses"`
+	Default   string         `yaml:"default,omitempty"` // answered when nothing matches
+	Models    []string       `yaml:"models,omitempty"`  // listed by ListModels
+}
+
+// MockResponse is one canned answer. Both patterns are case-insensitive regular expressions; an
+// entry matches when every pattern it sets matches, so an entry without patterns matches anything.
+type MockResponse struct {
+	Intent   string `yaml:"intent,omitempty"` // matched against the user's intent quoted in the prompt
+	Prompt   string `yaml:"prompt,omitempty"` // matched against the whole prompt
+	Response string `yaml:"response"`         // returned as the raw provider answer, e.g. {"type": "command", "command": "ls -la"}
+}
+
+type mockResponse struct {
+	intent   *regexp.Regexp
+	prompt   *regexp.Regexp
+	response string
+}
+
+func NewMockProvider(cfg *config.Config) (*MockProvider, error) {
+	path := cfg.Mock.ResponsesPath()
+	if path == "" {
+		return nil, fmt.Errorf("the mock provider needs a responses file. Set 'mock.responses' in the configuration")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock responses: %w", err)
+	}
+	var file MockResponses
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse mock responses %s: %w", path, err)
+	}
+
+	provider := &MockProvider{model: cfg.Model, fallback: file.Default, models: file.Models}
+	if provider.fallback == "" {
+		provider.fallback = mockNoMatch
+	}
+	for i, entry := range file.Responses {
+		response := mockResponse{response: entry.Response}
+		if response.intent, err = compileMockPattern(entry.Intent); err != nil {
+			return nil, fmt.Errorf("invalid intent pattern in mock response %d: %w", i+1, err)
+		}
+		if response.prompt, err = compileMockPattern(entry.Prompt); err != nil {
+			return nil, fmt.Errorf("invalid prompt pattern in mock response %d: %w", i+1, err)
+		}
+		provider.responses = append(provider.responses, response)
+	}
+	return provider, nil
+}
+
+func compileMockPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// GenerateResponse returns the first canned response that matches the prompt
+func (m *MockProvider) GenerateResponse(prompt string) (string, error) {
+	intent := promptIntent(prompt)
+	for _, r := range m.responses {
+		if r.intent != nil && !r.intent.MatchString(intent) {
+			continue
+		}
+		if r.prompt != nil && !r.prompt.MatchString(prompt) {
+			continue
+		}
+		return r.response, nil
+	}
+	return m.fallback, nil
+}
+
+func (m *MockProvider) ListModels() ([]string, error) {
+	if len(m.models) > 0 {
+		return m.models, nil
+	}
+	return []string{m.model}, nil
+}
+
+// promptIntent returns the user's intent quoted in a prompt, or "" when the prompt quotes none
+func promptIntent(prompt string) string {
+	for _, label := range []string{"USER INTENT", "INTENT"} {
+		begin := fmt.Sprintf("<<<BEGIN UNTRUSTED %s>>>\n", label)
+		end := fmt.Sprintf("\n<<<END UNTRUSTED %s>>>", label)
+		if _, rest, ok := strings.Cut(prompt, begin); ok {
+			if intent, _, ok := strings.Cut(rest, end); ok {
+				return intent
+			}
+		}
+	}
+	return ""
+}
@@ -9,9 +9,11 @@ package ai
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/minand-mohan/execute-my-will/internal/config"
@@ -23,13 +25,22 @@ type OpenAIProvider struct {
 	model       string
 	maxTokens   int
 	temperature float32
+	baseURL     string // the API root, e.g. https://api.openai.com/v1 or http://localhost:1234/v1
+	jsonMode    bool   // whether the API accepts response_format, which OpenAI-compatible servers vary on
 }
 
 type OpenAIRequest struct {
-	Model       string          `json:"model"`
+	Model       string          `json:"model,omitempty"`
 	Messages    []OpenAIMessage `json:"messages"`
 	MaxTokens   int             `json:"max_tokens"`
 	Temperature float32         `json:"temperature"`
+	Stream      bool            `json:"stream,omitempty"`
+	// ResponseFormat constrains the answer to a JSON object for prompts that expect one
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type OpenAIResponseFormat struct {
+	Type string `json:"type"` // e.g. json_object
 }
 
 type OpenAIMessage struct {
@@ -46,10 +57,18 @@ type OpenAIChoice struct {
 	Message OpenAIMessage `json:"message"`
 }
 
+// OpenAIStreamChunk is one event of a streamed chat completion
+type OpenAIStreamChunk struct {
+	Choices []struct {
+		Delta OpenAIMessage `json:"delta"`
+	} `json:"choices"`
+	Error *OpenAIError `json:"error,omitempty"`
+}
+
 type OpenAIError struct {
-	Message string `json:"message"`
-	Type    string `json:"type"`
-	Code    string `json:"code"`
+	Message string          `json:"message"`
+	Type    string          `json:"type"`
+	Code    json.RawMessage `json:"code"` // a string at OpenAI and Azure, a number at OpenRouter
 }
 
 type OpenAIModelsResponse struct {
@@ -59,33 +78,85 @@ type OpenAIModelsResponse struct {
 }
 
 func NewOpenAIProvider(cfg *config.Config) (*OpenAIProvider, error) {
-	if cfg.APIKey == "" {
+	if cfg.APIKey == "" && !cfg.CustomEndpoint() {
 		return nil, fmt.Errorf("OpenAI API key is required")
 	}
 
+	// The version only applies to OpenAI's own API; a custom base URL names its own
+	baseURL := fmt.Sprintf("https://api.openai.com/%s", cfg.APIVersion("openai"))
+	if cfg.CustomEndpoint() {
+		baseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	}
+
 	return &OpenAIProvider{
 		apiKey:      cfg.APIKey,
 		model:       cfg.Model,
 		maxTokens:   cfg.MaxTokens,
 		temperature: cfg.Temperature,
+		baseURL:     baseURL,
+		jsonMode:    !cfg.CustomEndpoint(),
 	}, nil
 }
 
+// header holds the bearer token, which self-hosted servers without a key go without
+func (o *OpenAIProvider) header() http.Header {
+	header := http.Header{}
+	if o.apiKey != "" {
+		header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
+	}
+	return header
+}
+
 func (o *OpenAIProvider) GenerateResponse(prompt string) (string, error) {
-	url := "https://api.openai.com/v1/chat/completions"
+	return o.Converse(nil, prompt, nil)
+}
+
+// StreamResponse is GenerateResponse with the answer handed to onChunk as it is generated
+func (o *OpenAIProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	return o.Converse(nil, prompt, onChunk)
+}
+
+// Converse sends the history as chat messages ahead of the prompt, streaming the answer to
+// onChunk when it is not nil
+func (o *OpenAIProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	url := o.baseURL + "/chat/completions"
 
 	request := OpenAIRequest{
-		Model: o.model,
-		Messages: []OpenAIMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:       o.model,
+		Messages:    openAIMessages(history, prompt),
 		MaxTokens:   o.maxTokens,
 		Temperature: o.temperature,
 	}
+	if o.jsonMode {
+		request.ResponseFormat = jsonObjectFormat(prompt)
+	}
+
+	if onChunk == nil {
+		return postChatCompletion(url, o.header(), request, "OpenAI")
+	}
+	return streamChatCompletion(url, o.header(), request, "OpenAI", onChunk)
+}
+
+// openAIMessages turns the history and the prompt into chat messages
+func openAIMessages(history []Message, prompt string) []OpenAIMessage {
+	var messages []OpenAIMessage
+	for _, message := range chatMessages(history, prompt) {
+		messages = append(messages, OpenAIMessage{Role: message.Role, Content: message.Content})
+	}
+	return messages
+}
+
+// jsonObjectFormat asks for a JSON object when the prompt expects one, and for plain text otherwise
+func jsonObjectFormat(prompt string) *OpenAIResponseFormat {
+	if !expectsJSON(prompt) {
+		return nil
+	}
+	return &OpenAIResponseFormat{Type: "json_object"}
+}
 
+// postChatCompletion sends a chat completion request to an OpenAI-compatible endpoint and
+// returns the text of the first choice. label names the API in errors.
+func postChatCompletion(url string, header http.Header, request OpenAIRequest, label string) (string, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
@@ -96,56 +167,93 @@ func (o *OpenAIProvider) GenerateResponse(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
+	req.Header = header.Clone()
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make API request: %w", err)
+		return "", providerError(0, fmt.Errorf("failed to make API request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", providerError(0, fmt.Errorf("failed to read response body: %w", err))
 	}
 
 	var response OpenAIResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		if resp.StatusCode != http.StatusOK {
+			return "", providerError(resp.StatusCode, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body)))
+		}
+		return "", parseFailure(fmt.Errorf("failed to unmarshal response: %w", err))
 	}
 
 	// Check for API errors
 	if response.Error != nil {
-		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+		return "", providerError(resp.StatusCode, fmt.Errorf("%s API error: %s", label, response.Error.Message))
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", providerError(resp.StatusCode, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body)))
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response generated")
+		return "", parseFailure(fmt.Errorf("no response generated"))
 	}
 
 	responseText := response.Choices[0].Message.Content
 
-	// Handle failure cases as defined in the prompt
-	if responseText == "FAILURE: Intent too complex for a single shell command." {
-		return "", fmt.Errorf("intent too complex for a single shell command, might need merlin")
-	}
+	return responseText, nil
+}
 
-	if responseText == "FAILURE: Directory reference too vague." {
-		return "", fmt.Errorf("directory reference too vague - please specify exact paths. the map instructions are not clear")
+// streamChatCompletion is postChatCompletion with "stream": true, handing every piece of the
+// first choice to onChunk as it arrives and returning the whole text once the stream ends
+func streamChatCompletion(url string, header http.Header, request OpenAIRequest, label string, onChunk func(chunk string)) (string, error) {
+	request.Stream = true
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Check for any other FAILURE responses
-	if len(responseText) >= 8 && responseText[:8] == "FAILURE:" {
-		return "", fmt.Errorf("command generation failed: %s", responseText[9:])
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header = header.Clone()
+	req.Header.Set("Content-Type", "application/json")
 
-	return responseText, nil
+	var text strings.Builder
+	err = postStream(req, func(data string) error {
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return parseFailure(fmt.Errorf("failed to unmarshal stream event: %w", err))
+		}
+		if chunk.Error != nil {
+			return providerError(http.StatusOK, fmt.Errorf("%s API error: %s", label, chunk.Error.Message))
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			text.WriteString(chunk.Choices[0].Delta.Content)
+			onChunk(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+
+	var statusErr *streamStatusError
+	if errors.As(err, &statusErr) {
+		var response OpenAIResponse
+		if json.Unmarshal(statusErr.body, &response) == nil && response.Error != nil {
+			return "", providerError(statusErr.status, fmt.Errorf("%s API error: %s", label, response.Error.Message))
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if text.Len() == 0 {
+		return "", parseFailure(fmt.Errorf("no response generated"))
+	}
+	return text.String(), nil
 }
 
 func (o *OpenAIProvider) ListModels() ([]string, error) {
@@ -158,7 +266,7 @@ func (o *OpenAIProvider) ListModels() ([]string, error) {
 
 	for i := 0; i < maxRetries; i++ {
 		client := &http.Client{}
-		req, httpErr := http.NewRequest("GET", "https://api.openai.com/v1/models", nil)
+		req, httpErr := http.NewRequest("GET", o.baseURL+"/models", nil)
 		if httpErr != nil {
 			err = fmt.Errorf("failed to create OpenAI request: %w", httpErr)
 			fmt.Printf("Attempt %d failed: %v. Retrying in %v...\n", i+1, err, initialDelay)
@@ -166,7 +274,7 @@ func (o *OpenAIProvider) ListModels() ([]string, error) {
 			initialDelay *= 2 // Exponential backoff
 			continue
 		}
-		req.Header.Add("Authorization", "Bearer "+o.apiKey) // IMPORTANT: Use the provider's API key
+		req.Header = o.header()
 
 		resp, httpErr := client.Do(req)
 		if httpErr != nil {
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/openrouter.go
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+const (
+	// openRouterReferer and openRouterTitle identify the app to OpenRouter unless configured
+	openRouterReferer = "https://github.com/minand-mohan/execute-my-will"
+	openRouterTitle   = "execute-my-will"
+)
+
+// OpenRouterProvider reaches many vendors' models through OpenRouter's OpenAI-compatible API,
+// with a single API key
+type OpenRouterProvider struct {
+	apiKey      string
+	model       string // "vendor/model", e.g. anthropic/claude-3.5-sonnet
+	maxTokens   int
+	temperature float32
+	baseURL     string
+	referer     string
+	title       string
+}
+
+func NewOpenRouterProvider(cfg *config.Config) (*OpenRouterProvider, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("OpenRouter API key is required")
+	}
+
+	provider := &OpenRouterProvider{
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		maxTokens:   cfg.MaxTokens,
+		temperature: cfg.Temperature,
+		baseURL:     cfg.OpenRouter.URL(),
+		referer:     cfg.OpenRouter.Referer,
+		title:       cfg.OpenRouter.Title,
+	}
+	if provider.referer == "" {
+		provider.referer = openRouterReferer
+	}
+	if provider.title == "" {
+		provider.title = openRouterTitle
+	}
+	return provider, nil
+}
+
+// header holds the key and the attribution headers OpenRouter asks apps to send
+func (o *OpenRouterProvider) header() http.Header {
+	header := http.Header{}
+	header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
+	header.Set("HTTP-Referer", o.referer)
+	header.Set("X-Title", o.title)
+	return header
+}
+
+func (o *OpenRouterProvider) GenerateResponse(prompt string) (string, error) {
+	return o.Converse(nil, prompt, nil)
+}
+
+// StreamResponse is GenerateResponse with the answer handed to onChunk as it is generated
+func (o *OpenRouterProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	return o.Converse(nil, prompt, onChunk)
+}
+
+// Converse sends the history as chat messages ahead of the prompt, streaming the answer to
+// onChunk when it is not nil
+func (o *OpenRouterProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	request := OpenAIRequest{
+		Model:          o.model,
+		Messages:       openAIMessages(history, prompt),
+		MaxTokens:      o.maxTokens,
+		Temperature:    o.temperature,
+		ResponseFormat: jsonObjectFormat(prompt),
+	}
+
+	if onChunk == nil {
+		return postChatCompletion(o.baseURL+"/chat/completions", o.header(), request, "OpenRouter")
+	}
+	return streamChatCompletion(o.baseURL+"/chat/completions", o.header(), request, "OpenRouter", onChunk)
+}
+
+// ListModels returns the IDs in OpenRouter's model catalog, in alphabetical order
+func (o *OpenRouterProvider) ListModels() ([]string, error) {
+	req, err := http.NewRequest("GET", o.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenRouter request: %w", err)
+	}
+	req.Header = o.header()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the OpenRouter model catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OpenRouter model catalog: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenRouter returned status %d for the model catalog: %s", resp.StatusCode, string(body))
+	}
+
+	var catalog OpenAIModelsResponse
+	if err := json.Unmarshal(body, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenRouter model catalog: %w", err)
+	}
+
+	var models []string
+	for _, model := range catalog.Data {
+		models = append(models, model.ID)
+	}
+	sort.Strings(models)
+	return models, nil
+}
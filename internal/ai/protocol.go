@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/protocol.go
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonResponseMarker ends every prompt that asks for a StructuredResponse. Providers with a JSON
+// mode switch it on for such prompts, so the answer cannot be anything but a JSON object.
+const jsonResponseMarker = "JSON RESPONSE:"
+
+// expectsJSON reports whether a prompt asks for a StructuredResponse
+func expectsJSON(prompt string) bool {
+	return strings.HasSuffix(prompt, jsonResponseMarker)
+}
+
+// StructuredResponse is the JSON object the oracle answers generation prompts with
+type StructuredResponse struct {
+	Type        string `json:"type"` // command, script, or failure
+	Command     string `json:"command,omitempty"`
+	Script      string `json:"script,omitempty"`
+	Explanation string `json:"explanation,omitempty"` // what the command or script does, in a sentence
+	Risk        string `json:"risk,omitempty"`        // read-only, modifies, elevated, or destructive
+	Category    string `json:"category,omitempty"`    // why a failure was refused, one of FailureCategories
+	Reason      string `json:"reason,omitempty"`      // the reason of a failure
+}
+
+// RiskLevels are the risk levels the oracle rates its proposals with, from least to most risky
+var RiskLevels = []string{"read-only", "modifies", "elevated", "destructive"}
+
+// describeJSONResponseFormat explains the StructuredResponse to the oracle. commands says what
+// a command is for and scripts what a script is for; either may be empty to leave that type out.
+func describeJSONResponseFormat(commands, scripts, scriptFormat, commentPrefix string) string {
+	var format strings.Builder
+	format.WriteString("RESPONSE FORMAT:\nRespond with exactly ONE JSON object and nothing else: no markdown, no code fences, and no text before or after it.\n")
+	if commands != "" {
+		fmt.Fprintf(&format, `
+%s:
+{"type": "command", "command": "single shell command", "explanation": "one sentence on what it does", "risk": "risk level"}
+`, commands)
+	}
+	if scripts != "" {
+		fmt.Fprintf(&format, `
+%s, a %s script with lines separated by \n:
+{"type": "script", "script": "%s Brief description of what this command does\ncommand1\n%s Brief description of what this command does\ncommand2", "explanation": "one sentence on what it does", "risk": "risk level"}
+`, scripts, scriptFormat, commentPrefix, commentPrefix)
+	}
+	format.WriteString(`
+For impossible or unsafe tasks:
+{"type": "failure", "category": "category", "reason": "brief reason why the task cannot be completed"}
+where category is exactly one of:
+- vague_path: a file or directory reference cannot be resolved (e.g., "some folder")
+- unsafe: the task would be destructive or harmful
+- impossible: the task cannot be done with shell commands on this system
+- needs_more_context: details only the user knows are missing (e.g., which service, version, or host)
+`)
+	if commands != "" || scripts != "" {
+		format.WriteString(`
+and risk level is the riskiest step, exactly one of:
+- read-only: only reads or lists, and changes nothing
+- modifies: creates or changes files, packages, or settings
+- elevated: needs sudo or Administrator rights
+- destructive: deletes data or cannot be undone
+`)
+	}
+	return format.String()
+}
+
+// structuredObject returns the JSON object of a response, unwrapping the code fence some models
+// put around it even when told not to. It reports whether the response is meant as JSON at all.
+func structuredObject(response string) (string, bool) {
+	if fenced, ok := strings.CutPrefix(response, "```"); ok {
+		language, body, _ := strings.Cut(fenced, "\n")
+		if language = strings.TrimSpace(language); language != "" && language != "json" {
+			return "", false
+		}
+		body, _, _ = strings.Cut(body, "```")
+		response = strings.TrimSpace(body)
+	}
+	return response, strings.HasPrefix(response, "{")
+}
+
+// parseStructuredResponse parses a StructuredResponse. It reports false when the response is not
+// meant as JSON; a JSON answer that breaks the format is returned as a malformed failure.
+func parseStructuredResponse(response string) (*AIResponse, bool) {
+	object, ok := structuredObject(response)
+	if !ok {
+		return nil, false
+	}
+
+	var structured StructuredResponse
+	decoder := json.NewDecoder(strings.NewReader(object))
+	if err := decoder.Decode(&structured); err != nil {
+		return malformedResponse(fmt.Sprintf("its JSON is invalid: %v", err)), true
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return malformedResponse("it has text after the JSON object"), true
+	}
+
+	risk := normalizeRisk(structured.Risk)
+	switch strings.ToLower(strings.TrimSpace(structured.Type)) {
+	case "command":
+		command := strings.TrimSpace(structured.Command)
+		if command == "" {
+			return malformedResponse(`its "command" is empty`), true
+		}
+		return &AIResponse{Type: ResponseTypeCommand, Content: command, Explanation: strings.TrimSpace(structured.Explanation), Risk: risk}, true
+	case "script":
+		script := strings.TrimSpace(structured.Script)
+		if script == "" {
+			return malformedResponse(`its "script" is empty`), true
+		}
+		return &AIResponse{Type: ResponseTypeScript, Content: script, Explanation: strings.TrimSpace(structured.Explanation), Risk: risk}, true
+	case "failure":
+		category, _ := lookupFailureCategory(structured.Category)
+		return &AIResponse{Type: ResponseTypeFailure, Error: strings.TrimSpace(structured.Reason), Category: category}, true
+	}
+	return malformedResponse(fmt.Sprintf("its type %q is none of command, script, or failure", structured.Type)), true
+}
+
+// isTruncatedStructuredResponse reports whether a response meant as JSON was cut off: a code
+// fence or object that never closes, or a command that ends mid-way
+func isTruncatedStructuredResponse(response string) (truncated, ok bool) {
+	if strings.HasPrefix(response, "```") && strings.Count(response, "```")%2 == 1 {
+		return true, true
+	}
+	object, ok := structuredObject(response)
+	if !ok {
+		return false, false
+	}
+
+	var structured StructuredResponse
+	err := json.NewDecoder(strings.NewReader(object)).Decode(&structured)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, true
+	}
+	if err == nil && strings.EqualFold(strings.TrimSpace(structured.Type), "command") {
+		return endsMidCommand(strings.TrimSpace(structured.Command)), true
+	}
+	return false, true
+}
+
+// isWellFormedStructuredResponse reports whether a response is a StructuredResponse that parses
+// without any fence around it
+func isWellFormedStructuredResponse(response string) bool {
+	if !strings.HasPrefix(response, "{") || !json.Valid([]byte(response)) {
+		return false
+	}
+	parsed, _ := parseStructuredResponse(response)
+	return parsed.Category != FailureMalformed
+}
+
+// malformedResponse is a failure for an answer that does not follow the response format. It is
+// never proposed as a command, since it may be a refusal or an explanation in prose.
+func malformedResponse(problem string) *AIResponse {
+	return &AIResponse{
+		Type:     ResponseTypeFailure,
+		Error:    "the oracle's answer did not follow the response format: " + problem,
+		Category: FailureMalformed,
+	}
+}
+
+// normalizeRisk returns the risk level named by risk, tolerating case, spaces, and underscores,
+// or "" when it names none
+func normalizeRisk(risk string) string {
+	risk = strings.ToLower(strings.TrimSpace(risk))
+	risk = strings.NewReplacer("_", "-", " ", "-").Replace(risk)
+	for _, level := range RiskLevels {
+		if risk == level {
+			return level
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/ratelimit.go
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+// rateWindow is the sliding window the configured limits apply to
+const rateWindow = time.Minute
+
+type rateEvent struct {
+	At     time.Time `yaml:"at"`
+	Tokens int       `yaml:"tokens"`
+}
+
+// RateLimiter queues AI requests so that a provider's requests/minute and tokens/minute
+// limits are never exceeded. When a state path is given the window is shared across runs.
+type RateLimiter struct {
+	limit  config.RateLimit
+	path   string
+	events []rateEvent
+
+	// Clock and Sleep can be replaced in tests
+	Clock func() time.Time
+	Sleep func(time.Duration)
+	// OnWait is told how long a request waits for the limits before it sleeps; nil waits silently
+	OnWait func(time.Duration)
+}
+
+// OnRateLimitWait is given to the limiter of every client NewClient creates, so that the user
+// hears why a request is held back. The cli layer sets it to print through the UI.
+var OnRateLimitWait func(wait time.Duration)
+
+// NewRateLimiter creates a limiter for the given limits. An empty path keeps the window in memory.
+func NewRateLimiter(limit config.RateLimit, path string) *RateLimiter {
+	return &RateLimiter{
+		limit: limit,
+		path:  path,
+		Clock: time.Now,
+		Sleep: time.Sleep,
+	}
+}
+
+// Wait blocks until a request of the given estimated size fits within the limits, then records it
+func (r *RateLimiter) Wait(tokens int) {
+	for {
+		r.load()
+		now := r.Clock()
+		r.prune(now)
+
+		wait := r.delay(now, tokens)
+		if wait <= 0 {
+			r.events = append(r.events, rateEvent{At: now, Tokens: tokens})
+			r.save()
+			return
+		}
+
+		if r.OnWait != nil {
+			r.OnWait(wait)
+		}
+		r.Sleep(wait)
+	}
+}
+
+// delay returns how long to wait before the request fits, or zero when it fits now
+func (r *RateLimiter) delay(now time.Time, tokens int) time.Duration {
+	var wait time.Duration
+
+	if r.limit.RequestsPerMinute > 0 && len(r.events) >= r.limit.RequestsPerMinute {
+		// Wait until enough of the oldest requests leave the window
+		oldest := r.events[len(r.events)-r.limit.RequestsPerMinute]
+		wait = maxDuration(wait, oldest.At.Add(rateWindow).Sub(now))
+	}
+
+	if r.limit.TokensPerMinute > 0 && len(r.events) > 0 {
+		used := 0
+		for _, event := range r.events {
+			used += event.Tokens
+		}
+		// A single request larger than the limit is let through once the window is empty
+		for i := 0; used+tokens > r.limit.TokensPerMinute && i < len(r.events); i++ {
+			used -= r.events[i].Tokens
+			wait = maxDuration(wait, r.events[i].At.Add(rateWindow).Sub(now))
+		}
+	}
+
+	return wait
+}
+
+func (r *RateLimiter) prune(now time.Time) {
+	kept := r.events[:0]
+	for _, event := range r.events {
+		if now.Sub(event.At) < rateWindow {
+			kept = append(kept, event)
+		}
+	}
+	r.events = kept
+}
+
+// load refreshes the window from the state file; a missing or unreadable file keeps the in-memory window
+func (r *RateLimiter) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return
+	}
+	var events []rateEvent
+	if err := yaml.Unmarshal(data, &events); err == nil {
+		r.events = events
+	}
+}
+
+func (r *RateLimiter) save() {
+	if r.path == "" {
+		return
+	}
+	data, err := yaml.Marshal(r.events)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(r.path, data, 0600)
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// rateLimitedProvider waits for the limiter before every request to the wrapped provider
+type rateLimitedProvider struct {
+	AIProvider
+	limiter   *RateLimiter
+	maxTokens int
+}
+
+// NewRateLimitedProvider wraps a provider so every request respects the limiter.
+// Each request is estimated as its prompt (about four characters per token) plus maxTokens of response.
+func NewRateLimitedProvider(provider AIProvider, limiter *RateLimiter, maxTokens int) AIProvider {
+	return &rateLimitedProvider{AIProvider: provider, limiter: limiter, maxTokens: maxTokens}
+}
+
+func (p *rateLimitedProvider) GenerateResponse(prompt string) (string, error) {
+	p.limiter.Wait(len(prompt)/4 + p.maxTokens)
+	return p.AIProvider.GenerateResponse(prompt)
+}
+
+func (p *rateLimitedProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	p.limiter.Wait(len(prompt)/4 + p.maxTokens)
+	return streamResponse(p.AIProvider, prompt, onChunk)
+}
+
+func (p *rateLimitedProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	p.limiter.Wait(len(flattenConversation(history, prompt))/4 + p.maxTokens)
+	return converse(p.AIProvider, history, prompt, onChunk)
+}
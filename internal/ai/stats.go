@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/stats.go
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseStatsFile is the name of the state file holding cross-run response statistics
+const ParseStatsFile = "parse-stats.yaml"
+
+const (
+	// minStatsSamples is the number of responses needed before a model's failure rate is trusted
+	minStatsSamples = 20
+	// hintFailureRate is the parse failure rate above which a hint is shown
+	hintFailureRate = 0.05
+)
+
+// ModelStats counts how well a single provider/model follows the response format
+type ModelStats struct {
+	Responses     int `yaml:"responses"`
+	ParseFailures int `yaml:"parse_failures"`
+	Regenerations int `yaml:"regenerations"`
+	Truncations   int `yaml:"truncations,omitempty"` // responses cut off before they ended, then asked for again
+}
+
+// FailureRate returns the fraction of responses that did not follow the response format
+func (m ModelStats) FailureRate() float64 {
+	if m.Responses == 0 {
+		return 0
+	}
+	return float64(m.ParseFailures) / float64(m.Responses)
+}
+
+// ParseStats holds response statistics keyed by "provider/model"
+type ParseStats struct {
+	Models map[string]*ModelStats `yaml:"models"`
+}
+
+// StatsKey builds the key under which a provider/model pair is recorded
+func StatsKey(provider, model string) string {
+	return provider + "/" + model
+}
+
+// LoadParseStats reads the statistics file, returning empty statistics when it does not exist yet
+func LoadParseStats(path string) (*ParseStats, error) {
+	stats := &ParseStats{Models: map[string]*ModelStats{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parse statistics: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse statistics file: %w", err)
+	}
+	if stats.Models == nil {
+		stats.Models = map[string]*ModelStats{}
+	}
+	return stats, nil
+}
+
+// Save writes the statistics file, creating its directory if needed
+func (s *ParseStats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create statistics directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parse statistics: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// RecordResponse counts a response and whether it followed the response format
+func (s *ParseStats) RecordResponse(key string, parsed bool) {
+	m := s.model(key)
+	m.Responses++
+	if !parsed {
+		m.ParseFailures++
+	}
+}
+
+// RecordTruncation counts a response that was cut off before it ended
+func (s *ParseStats) RecordTruncation(key string) {
+	s.model(key).Truncations++
+}
+
+// RecordRegeneration counts a response that had to be generated again
+func (s *ParseStats) RecordRegeneration(key string) {
+	s.model(key).Regenerations++
+}
+
+func (s *ParseStats) model(key string) *ModelStats {
+	m, ok := s.Models[key]
+	if !ok {
+		m = &ModelStats{}
+		s.Models[key] = m
+	}
+	return m
+}
+
+// Keys returns the recorded provider/model keys in sorted order
+func (s *ParseStats) Keys() []string {
+	keys := make([]string, 0, len(s.Models))
+	for key := range s.Models {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Hint suggests a more reliable model when the given one often fails to follow the response format.
+// It returns an empty string when there is not enough data or nothing worth mentioning.
+func (s *ParseStats) Hint(key string) string {
+	current, ok := s.Models[key]
+	if !ok || current.Responses < minStatsSamples || current.FailureRate() < hintFailureRate {
+		return ""
+	}
+
+	hint := fmt.Sprintf("%s fails parsing %.0f%% of the time", key, current.FailureRate()*100)
+
+	best := ""
+	bestRate := current.FailureRate()
+	for _, other := range s.Keys() {
+		m := s.Models[other]
+		if other == key || m.Responses < minStatsSamples {
+			continue
+		}
+		if m.FailureRate() < bestRate {
+			best, bestRate = other, m.FailureRate()
+		}
+	}
+	if best != "" {
+		hint += fmt.Sprintf(", consider %s (%.0f%%)", best, bestRate*100)
+	}
+	return hint
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/stream.go
+package ai
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamingProvider is a provider that can hand over its answer piece by piece while it is
+// generated. StreamResponse returns the whole answer once it has ended, like GenerateResponse.
+type StreamingProvider interface {
+	AIProvider
+	StreamResponse(prompt string, onChunk func(chunk string)) (string, error)
+}
+
+// StreamHandler is shown an answer while it is being generated
+type StreamHandler interface {
+	// Begin starts a new answer; an earlier one that failed or was cut off is void
+	Begin()
+	// Chunk adds the next piece of the answer
+	Chunk(text string)
+	// End finishes the answer, whether it arrived whole or not
+	End()
+}
+
+// streamResponse asks provider for an answer, handing it to onChunk as it is generated when the
+// provider can stream, and all at once when it cannot
+func streamResponse(provider AIProvider, prompt string, onChunk func(chunk string)) (string, error) {
+	if streaming, ok := provider.(StreamingProvider); ok {
+		return streaming.StreamResponse(prompt, onChunk)
+	}
+	response, err := provider.GenerateResponse(prompt)
+	if err == nil {
+		onChunk(response)
+	}
+	return response, err
+}
+
+// readServerSentEvents calls onData with the data of every event in an SSE stream, until the
+// stream ends, onData fails, or an OpenAI-style [DONE] event arrives. Events of several data
+// lines are joined with newlines.
+func readServerSentEvents(body io.Reader, onData func(data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		event := strings.Join(data, "\n")
+		data = data[:0]
+		if event == "[DONE]" {
+			return io.EOF
+		}
+		return onData(event)
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return ignoreEOF(err)
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+		// event:, id:, retry:, and comment lines carry nothing the providers need
+	}
+	if err := scanner.Err(); err != nil {
+		return providerError(0, fmt.Errorf("failed to read the response stream: %w", err))
+	}
+	return ignoreEOF(dispatch())
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// postStream sends a streaming request and hands every event of the answer to onData. A response
+// that is not an event stream, such as a JSON error, is returned as its status and body.
+func postStream(req *http.Request, onData func(data string) error) error {
+	req.Header.Set("Accept", "text/event-stream")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return providerError(0, fmt.Errorf("failed to make API request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return providerError(resp.StatusCode, &streamStatusError{status: resp.StatusCode, body: body})
+	}
+	return readServerSentEvents(resp.Body, onData)
+}
+
+// streamStatusError is a streaming request the API refused before any answer began
+type streamStatusError struct {
+	status int
+	body   []byte
+}
+
+func (e *streamStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.status, string(e.body))
+}
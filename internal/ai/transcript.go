@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/transcript.go
+package ai
+
+// Exchange is one prompt sent to the provider together with its raw answer or error
+type Exchange struct {
+	Prompt   string `yaml:"prompt"`
+	Response string `yaml:"response,omitempty"`
+	Error    string `yaml:"error,omitempty"`
+}
+
+// recordingProvider keeps every exchange with the wrapped provider so that a run can be
+// reported afterwards, retries included
+type recordingProvider struct {
+	AIProvider
+	exchanges []Exchange
+}
+
+func (p *recordingProvider) GenerateResponse(prompt string) (string, error) {
+	response, err := p.AIProvider.GenerateResponse(prompt)
+	p.record(prompt, response, err)
+	return response, err
+}
+
+func (p *recordingProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	response, err := streamResponse(p.AIProvider, prompt, onChunk)
+	p.record(prompt, response, err)
+	return response, err
+}
+
+// Converse records the history written out ahead of the prompt, as it would be sent to a
+// provider that takes a single prompt
+func (p *recordingProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	response, err := converse(p.AIProvider, history, prompt, onChunk)
+	p.record(flattenConversation(history, prompt), response, err)
+	return response, err
+}
+
+func (p *recordingProvider) record(prompt, response string, err error) {
+	exchange := Exchange{Prompt: prompt, Response: response}
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	p.exchanges = append(p.exchanges, exchange)
+}
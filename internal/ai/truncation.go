@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/truncation.go
+package ai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTruncatedRetries caps how often a response that was cut off is asked for again
+const maxTruncatedRetries = 2
+
+// OnTruncatedRetry is called before a response that was cut off is asked for again, so that the
+// user hears why the answer takes longer. The cli layer sets it to print through the UI.
+var OnTruncatedRetry func()
+
+// responseMarkers start every well-formed response that is not JSON
+var responseMarkers = []string{"COMMAND:", "SCRIPT:", "FAILURE:", "ANSWER:"}
+
+// continuationSuffixes end a command that goes on in text that never arrived: a pipe, a chain
+// operator, or a line continuation in POSIX shells, cmd (^), and PowerShell (`). The last two
+// count only after a space, so a command wrapped in backticks is not taken for a continuation.
+var continuationSuffixes = []string{"|", "&&", "||", "\\", " ^", " `"}
+
+// IsTruncatedResponse reports whether a raw response was cut off before it ended, as when the
+// provider's connection drops mid-generation: nothing at all, a JSON object that never closes, a
+// marker that stops partway ("COMM"), a marker without content, a script whose code fence never
+// closes, or a command that ends inside a quote or after a pipe, "&&", or a line continuation. A
+// FAILURE without a reason is complete; the reason is optional.
+func IsTruncatedResponse(response string) bool {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return true
+	}
+	if truncated, ok := isTruncatedStructuredResponse(response); ok {
+		return truncated
+	}
+	for _, marker := range responseMarkers {
+		if len(response) < len(marker) && strings.HasPrefix(marker, response) {
+			return true
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(response, "COMMAND:"):
+		command := strings.TrimSpace(strings.TrimPrefix(response, "COMMAND:"))
+		return command == "" || endsMidCommand(command)
+	case strings.HasPrefix(response, "SCRIPT:"):
+		script := strings.TrimSpace(strings.TrimPrefix(response, "SCRIPT:"))
+		return script == "" || strings.Count(script, "```")%2 == 1
+	case strings.HasPrefix(response, "ANSWER:"):
+		return strings.TrimSpace(strings.TrimPrefix(response, "ANSWER:")) == ""
+	}
+	return false
+}
+
+// endsMidCommand reports whether a command stops inside a quoted string or right after an
+// operator that needs more to follow. An apostrophe between two letters, as in "don't", is
+// taken as part of a word rather than a quote.
+func endsMidCommand(command string) bool {
+	for _, suffix := range continuationSuffixes {
+		if strings.HasSuffix(command, suffix) {
+			return true
+		}
+	}
+
+	runes := []rune(command)
+	var quote rune
+	escaped := false
+	for i, r := range runes {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"':
+			quote = r
+		case r == '\'':
+			if i > 0 && i < len(runes)-1 && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]) {
+				continue
+			}
+			quote = r
+		}
+	}
+	return quote != 0
+}
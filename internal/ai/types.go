@@ -17,10 +17,42 @@ const (
 	ResponseTypeCommand ResponseType = iota
 	ResponseTypeScript
 	ResponseTypeFailure
+	ResponseTypeAnswer // a plain-text answer to a question that needs no command
 )
 
+// FailureCategory says why the oracle refused a quest, so the refusal can come with advice
+type FailureCategory string
+
+const (
+	FailureUncategorized    FailureCategory = ""
+	FailureVaguePath        FailureCategory = "vague_path"
+	FailureUnsafe           FailureCategory = "unsafe"
+	FailureImpossible       FailureCategory = "impossible"
+	FailureNeedsMoreContext FailureCategory = "needs_more_context"
+	// FailureMalformed is set by the parser, never by the oracle, for an answer in no known format
+	FailureMalformed FailureCategory = "malformed"
+)
+
+// FailureCategories lists the categories the oracle is asked to choose from
+var FailureCategories = []FailureCategory{FailureVaguePath, FailureUnsafe, FailureImpossible, FailureNeedsMoreContext}
+
 type AIResponse struct {
-	Type    ResponseType
-	Content string
-	Error   string
+	Type        ResponseType
+	Content     string
+	Error       string
+	Category    FailureCategory // set for failures the oracle categorized
+	Explanation string          // the oracle's one-sentence summary of a command or script, if it gave one
+	Risk        string          // the oracle's own rating of a command or script, one of RiskLevels, if it gave one
+}
+
+// Attempt describes a previously executed command or script that did not succeed
+type Attempt struct {
+	Content  string // the command or script that was run
+	Error    string // the error reported by the executor
+	ExitCode *int   // the exit status it ended with, nil when the process reported none
+	Output   string // trailing output captured during execution
+
+	// Earlier are the attempts at the same intent that failed before this one in the same run,
+	// oldest first, so that a fix does not go back to one of them
+	Earlier []Attempt
 }
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/usage.go
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// UsageFile is the name of the state file holding the monthly usage ledger
+const UsageFile = "usage.yaml"
+
+// monthLayout names the calendar month a request is counted in
+const monthLayout = "2006-01"
+
+// ModelUsage counts the requests and estimated tokens spent on a single provider/model
+type ModelUsage struct {
+	Requests int `yaml:"requests"`
+	Tokens   int `yaml:"tokens"`
+}
+
+// UsageLedger holds estimated usage by month ("2006-01"), then by "provider/model"
+type UsageLedger struct {
+	Months map[string]map[string]*ModelUsage `yaml:"months"`
+}
+
+// LoadUsageLedger reads the ledger, returning an empty ledger when it does not exist yet
+func LoadUsageLedger(path string) (*UsageLedger, error) {
+	ledger := &UsageLedger{Months: map[string]map[string]*ModelUsage{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse usage ledger: %w", err)
+	}
+	if ledger.Months == nil {
+		ledger.Months = map[string]map[string]*ModelUsage{}
+	}
+	return ledger, nil
+}
+
+// Save writes the ledger, creating its directory if needed
+func (l *UsageLedger) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage ledger: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Record adds one request of the given estimated size to the month it was made in
+func (l *UsageLedger) Record(key string, tokens int, at time.Time) {
+	month := at.Format(monthLayout)
+	if l.Months[month] == nil {
+		l.Months[month] = map[string]*ModelUsage{}
+	}
+	usage, ok := l.Months[month][key]
+	if !ok {
+		usage = &ModelUsage{}
+		l.Months[month][key] = usage
+	}
+	usage.Requests++
+	usage.Tokens += tokens
+}
+
+// MonthTokens returns the estimated tokens spent on every model in the month of at
+func (l *UsageLedger) MonthTokens(at time.Time) int {
+	total := 0
+	for _, usage := range l.Months[at.Format(monthLayout)] {
+		total += usage.Tokens
+	}
+	return total
+}
+
+// MonthKeys returns the provider/model keys used in the month of at, sorted
+func (l *UsageLedger) MonthKeys(at time.Time) []string {
+	keys := make([]string, 0, len(l.Months[at.Format(monthLayout)]))
+	for key := range l.Months[at.Format(monthLayout)] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EstimateTokens estimates the size of an exchange at about four characters per token
+func EstimateTokens(prompt, response string) int {
+	return (len(prompt) + len(response)) / 4
+}
+
+// DegradeForBudget switches the configured model to the budget's fallback once this month's
+// usage reaches the switch threshold. It returns a notice for the knight, or "" when the
+// budget is not close to being spent.
+func DegradeForBudget(cfg *config.Config, ledger *UsageLedger, now time.Time) string {
+	if !cfg.Budget.Enabled() {
+		return ""
+	}
+	used := ledger.MonthTokens(now)
+	if used < cfg.Budget.SwitchThreshold() {
+		return ""
+	}
+
+	spent := fmt.Sprintf("About %d of your %d monthly tokens (%.0f%%) are spent, my lord.", used, cfg.Budget.MonthlyTokens, float64(used)*100/float64(cfg.Budget.MonthlyTokens))
+	switch fallback := cfg.Budget.FallbackModel; {
+	case fallback == "":
+		return spent + "\n\nNo fallback_model is configured under budget, so I continue with " + cfg.Model + "."
+	case fallback == cfg.Model:
+		return spent + "\n\nAlready using the fallback model " + fallback + "."
+	default:
+		notice := fmt.Sprintf("%s\n\nSwitching from %s to the cheaper %s until the month is over.", spent, cfg.Model, fallback)
+		cfg.Model = fallback
+		return notice
+	}
+}
+
+// usageRecordingProvider adds every answered request to the usage ledger
+type usageRecordingProvider struct {
+	AIProvider
+	path string
+	key  string
+}
+
+func (p *usageRecordingProvider) GenerateResponse(prompt string) (string, error) {
+	response, err := p.AIProvider.GenerateResponse(prompt)
+	if err != nil {
+		return response, err
+	}
+	p.record(prompt, response)
+	return response, nil
+}
+
+func (p *usageRecordingProvider) StreamResponse(prompt string, onChunk func(chunk string)) (string, error) {
+	response, err := streamResponse(p.AIProvider, prompt, onChunk)
+	if err != nil {
+		return response, err
+	}
+	p.record(prompt, response)
+	return response, nil
+}
+
+func (p *usageRecordingProvider) Converse(history []Message, prompt string, onChunk func(chunk string)) (string, error) {
+	response, err := converse(p.AIProvider, history, prompt, onChunk)
+	if err != nil {
+		return response, err
+	}
+	p.record(flattenConversation(history, prompt), response)
+	return response, nil
+}
+
+// record adds a request to the ledger. The ledger is best-effort; failing to update it never fails the quest.
+func (p *usageRecordingProvider) record(prompt, response string) {
+	if ledger, loadErr := LoadUsageLedger(p.path); loadErr == nil {
+		ledger.Record(p.key, EstimateTokens(prompt, response), time.Now())
+		_ = ledger.Save(p.path)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ai/warmup.go
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// LatencyFile is the name of the state file holding the baseline latency of each provider/model
+const LatencyFile = "latency.yaml"
+
+// WarmUpTimeout bounds how long the warm-up request may take before it counts as failed
+const WarmUpTimeout = 30 * time.Second
+
+// warmUpPrompt is as small as a request can be, so that the answer takes about as long as the
+// round trip and the model's time to first token
+const warmUpPrompt = "This is a connection check. Reply with the single word: READY"
+
+// LatencyBaseline is how long the warm-up request of a provider/model took
+type LatencyBaseline struct {
+	Latency    time.Duration `yaml:"latency"`
+	MeasuredAt time.Time     `yaml:"measured_at"`
+}
+
+// LatencyBaselines holds the baseline latencies keyed by "provider/model"
+type LatencyBaselines struct {
+	Models map[string]*LatencyBaseline `yaml:"models"`
+}
+
+// LoadLatencyBaselines reads the latency file, returning no baselines when it does not exist yet
+func LoadLatencyBaselines(path string) (*LatencyBaselines, error) {
+	baselines := &LatencyBaselines{Models: map[string]*LatencyBaseline{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latency baselines: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, baselines); err != nil {
+		return nil, fmt.Errorf("failed to parse latency baselines: %w", err)
+	}
+	if baselines.Models == nil {
+		baselines.Models = map[string]*LatencyBaseline{}
+	}
+	return baselines, nil
+}
+
+// Save writes the latency file, creating its directory if needed
+func (b *LatencyBaselines) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create latency directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshal latency baselines: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// WarmUp sends a tiny request to the configured provider and model and returns how long the
+// answer took. It fails when the provider rejects the key, endpoint, or model, or does not answer
+// within timeout, so that such problems show up while configuring rather than on the first quest.
+// The request skips rate limiting and the usage ledger.
+func WarmUp(cfg *config.Config, timeout time.Duration) (time.Duration, error) {
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	type result struct {
+		latency time.Duration
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		start := time.Now()
+		_, err := provider.GenerateResponse(warmUpPrompt)
+		done <- result{latency: time.Since(start), err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.latency, r.err
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("no answer within %s", timeout)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/calc/calc.go
+package calc
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Answer is a quest worked out locally, without a command or the oracle
+type Answer struct {
+	Result string // e.g. "3.2 GB = 3200 MB"
+	Note   string // optional detail, such as the convention a conversion used
+}
+
+// questionPrefix is dropped before an intent is matched, e.g. "what is 2+2"
+var questionPrefix = regexp.MustCompile(`(?i)^(?:please\s+)?(?:what\s+is|what's|whats|calculate|calc|compute|evaluate|solve)\s+`)
+
+// arithmeticExpression holds only numbers, operators, and parentheses
+var arithmeticExpression = regexp.MustCompile(`^[0-9.,\s+\-*/%^()x×÷]+$`)
+
+// percentOf matches "15% of 80" and "15 percent of 80"
+var percentOf = regexp.MustCompile(`(?i)^(-?[\d,]*\.?\d+)\s*(?:%|percent)\s+of\s+(-?[\d,]*\.?\d+)$`)
+
+// Solve answers arithmetic, unit conversion, and time zone intents such as "how many MB is
+// 3.2GB" or "convert 14:30 UTC to my timezone". It reports false for every other intent, which
+// then goes to the oracle as usual. now supplies the date for time conversions and the user's
+// own time zone.
+func Solve(intent string, now time.Time) (Answer, bool) {
+	text := strings.Join(strings.Fields(intent), " ")
+	text = strings.TrimRight(text, "?.! ")
+	if text == "" {
+		return Answer{}, false
+	}
+
+	if answer, ok := convertTime(text, now); ok {
+		return answer, true
+	}
+	if answer, ok := convertUnits(text); ok {
+		return answer, true
+	}
+
+	text = questionPrefix.ReplaceAllString(text, "")
+	if m := percentOf.FindStringSubmatch(text); m != nil {
+		percent, base := parseNumber(m[1]), parseNumber(m[2])
+		return Answer{Result: fmt.Sprintf("%s%% of %s = %s", FormatNumber(percent), FormatNumber(base), FormatNumber(percent*base/100))}, true
+	}
+	return evaluateArithmetic(text)
+}
+
+func evaluateArithmetic(text string) (Answer, bool) {
+	if !arithmeticExpression.MatchString(text) {
+		return Answer{}, false
+	}
+	expression := strings.NewReplacer("x", "*", "×", "*", "÷", "/", ",", "").Replace(text)
+
+	p := &parser{input: strings.ReplaceAll(expression, " ", "")}
+	p.next()
+	value, err := p.expression()
+	if err == nil && p.token != "" {
+		err = fmt.Errorf("unexpected '%s'", p.token)
+	}
+	// A lone number is not a calculation
+	if err == nil && p.operators == 0 {
+		return Answer{}, false
+	}
+	if err != nil {
+		if err == errDivisionByZero {
+			return Answer{Result: fmt.Sprintf("%s cannot be worked out: it divides by zero", strings.TrimSpace(text))}, true
+		}
+		return Answer{}, false
+	}
+	return Answer{Result: fmt.Sprintf("%s = %s", strings.TrimSpace(text), FormatNumber(value))}, true
+}
+
+var errDivisionByZero = fmt.Errorf("division by zero")
+
+// parser evaluates + - * / % ^ and parentheses with the usual precedence; ^ binds right
+type parser struct {
+	input     string
+	pos       int
+	token     string
+	operators int
+}
+
+func (p *parser) next() {
+	if p.pos >= len(p.input) {
+		p.token = ""
+		return
+	}
+	start := p.pos
+	if c := p.input[p.pos]; (c >= '0' && c <= '9') || c == '.' {
+		for p.pos < len(p.input) && ((p.input[p.pos] >= '0' && p.input[p.pos] <= '9') || p.input[p.pos] == '.') {
+			p.pos++
+		}
+	} else {
+		p.pos++
+	}
+	p.token = p.input[start:p.pos]
+}
+
+func (p *parser) expression() (float64, error) {
+	value, err := p.term()
+	for err == nil && (p.token == "+" || p.token == "-") {
+		operator := p.token
+		p.operators++
+		p.next()
+		var right float64
+		if right, err = p.term(); operator == "+" {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return value, err
+}
+
+func (p *parser) term() (float64, error) {
+	value, err := p.power()
+	for err == nil && (p.token == "*" || p.token == "/" || p.token == "%") {
+		operator := p.token
+		p.operators++
+		p.next()
+		var right float64
+		if right, err = p.power(); err != nil {
+			break
+		}
+		switch {
+		case operator == "*":
+			value *= right
+		case right == 0:
+			err = errDivisionByZero
+		case operator == "/":
+			value /= right
+		default:
+			value = math.Mod(value, right)
+		}
+	}
+	return value, err
+}
+
+func (p *parser) power() (float64, error) {
+	base, err := p.unary()
+	if err != nil || p.token != "^" {
+		return base, err
+	}
+	p.operators++
+	p.next()
+	exponent, err := p.power()
+	return math.Pow(base, exponent), err
+}
+
+func (p *parser) unary() (float64, error) {
+	if p.token == "-" || p.token == "+" {
+		negative := p.token == "-"
+		p.next()
+		value, err := p.unary()
+		if negative {
+			value = -value
+		}
+		return value, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (float64, error) {
+	if p.token == "(" {
+		p.next()
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if p.token != ")" {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		p.next()
+		return value, nil
+	}
+
+	value, err := strconv.ParseFloat(p.token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number, found '%s'", p.token)
+	}
+	p.next()
+	return value, nil
+}
+
+// parseNumber reads a number that matched one of the patterns, ignoring thousands separators
+func parseNumber(text string) float64 {
+	value, _ := strconv.ParseFloat(strings.ReplaceAll(text, ",", ""), 64)
+	return value
+}
+
+// FormatNumber prints a result with at most four decimals, or in scientific notation when it is
+// too large or too small for that
+func FormatNumber(value float64) string {
+	magnitude := math.Abs(value)
+	if magnitude >= 1e15 || (magnitude != 0 && magnitude < 1e-4) {
+		return strconv.FormatFloat(value, 'g', 6, 64)
+	}
+	rounded := math.Round(value*1e4) / 1e4
+	if rounded == 0 {
+		rounded = 0 // no "-0"
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
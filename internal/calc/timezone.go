@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/calc/timezone.go
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Windows and minimal containers ship no zone database of their own
+	_ "time/tzdata"
+)
+
+// timeConversion matches "14:30 UTC to my timezone", "convert 9am PST to Europe/Berlin", and
+// "what is 18:00 CET in local time"
+var timeConversion = regexp.MustCompile(`(?i)^(?:convert\s+|what\s+is\s+|what's\s+|what\s+time\s+is\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s+([a-z_/+\-:0-9]+)\s+(?:to|in|into)\s+(.+?)(?:\s+time)?$`)
+
+// localZone matches the ways of naming the user's own time zone
+var localZone = regexp.MustCompile(`(?i)^(?:my(?:\s+(?:time\s*zone|timezone|tz|time))?|local(?:\s+time)?|here|mine)$`)
+
+// utcOffset matches "UTC+2", "GMT-05:00", and "UTC+0530"
+var utcOffset = regexp.MustCompile(`(?i)^(?:utc|gmt)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// zoneAbbreviations maps common abbreviations to their fixed offset in hours
+var zoneAbbreviations = map[string]float64{
+	"utc": 0, "gmt": 0, "z": 0, "wet": 0,
+	"bst": 1, "cet": 1, "west": 1, "cest": 2, "eet": 2, "eest": 3, "msk": 3,
+	"ist": 5.5, "sgt": 8, "hkt": 8, "awst": 8, "jst": 9, "kst": 9, "aest": 10, "aedt": 11, "nzst": 12, "nzdt": 13,
+	"ast": -4, "edt": -4, "est": -5, "cdt": -5, "cst": -6, "mdt": -6, "mst": -7, "pdt": -7, "pst": -8, "akst": -9, "hst": -10,
+}
+
+// zoneLocations maps the generic US names, which follow daylight saving time, to a location
+var zoneLocations = map[string]string{
+	"et": "America/New_York", "ct": "America/Chicago", "mt": "America/Denver", "pt": "America/Los_Angeles",
+}
+
+func convertTime(text string, now time.Time) (Answer, bool) {
+	m := timeConversion.FindStringSubmatch(text)
+	if m == nil {
+		return Answer{}, false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch strings.ToLower(m[3]) {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return Answer{}, false
+		}
+		hour %= 12
+		if strings.EqualFold(m[3], "pm") {
+			hour += 12
+		}
+	default:
+		// "5 GB to MB" is not a time; a bare hour needs am/pm or minutes
+		if m[2] == "" {
+			return Answer{}, false
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return Answer{}, false
+	}
+
+	source, ok := lookupZone(m[4], now)
+	if !ok {
+		return Answer{}, false
+	}
+	target, ok := lookupZone(m[5], now)
+	if !ok {
+		return Answer{}, false
+	}
+
+	// The time is taken as today in the source zone
+	day := now.In(source)
+	at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, source)
+	converted := at.In(target)
+
+	result := fmt.Sprintf("%s %s = %s %s", at.Format("15:04"), zoneName(at), converted.Format("15:04"), zoneName(converted))
+	if localZone.MatchString(strings.TrimSpace(m[5])) {
+		result += " (your time zone)"
+	}
+	answer := Answer{Result: result}
+
+	fromDate, toDate := at.Format("2006-01-02"), converted.Format("2006-01-02")
+	if fromDate != toDate {
+		answer.Note = fmt.Sprintf("That is %s there, while it is %s in %s.", converted.Format("Mon 2 Jan"), at.Format("Mon 2 Jan"), zoneName(at))
+	}
+	return answer, true
+}
+
+// lookupZone resolves the user's own zone, an abbreviation, a UTC offset, or a zone name such
+// as Europe/Berlin
+func lookupZone(name string, now time.Time) (*time.Location, bool) {
+	name = strings.TrimSpace(name)
+	lower := strings.ToLower(name)
+
+	if localZone.MatchString(name) {
+		return now.Location(), true
+	}
+	if hours, ok := zoneAbbreviations[lower]; ok {
+		return time.FixedZone(strings.ToUpper(name), int(hours*3600)), true
+	}
+	if location, ok := zoneLocations[lower]; ok {
+		name = location
+	}
+	if m := utcOffset.FindStringSubmatch(name); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, false
+		}
+		return time.FixedZone(strings.ToUpper(name), offset), true
+	}
+
+	// Only names such as "Asia/Tokyo", so ordinary words are never looked up as zones
+	if !strings.Contains(name, "/") {
+		return nil, false
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
+
+// zoneName names the zone of t, with its offset when the name is not an abbreviation
+func zoneName(t time.Time) string {
+	name, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	utc := fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)
+	if name == "" || strings.HasPrefix(name, "+") || strings.HasPrefix(name, "-") {
+		return utc
+	}
+	if name == "UTC" || strings.HasPrefix(strings.ToUpper(name), "UTC") || strings.HasPrefix(strings.ToUpper(name), "GMT") {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, utc)
+}
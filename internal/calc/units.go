@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/calc/units.go
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// unit converts to the base unit of its dimension as value*factor + offset
+type unit struct {
+	dimension string
+	symbol    string
+	factor    float64
+	offset    float64
+}
+
+// units is keyed by every accepted spelling, in lower case
+var units = map[string]unit{}
+
+func addUnit(dimension, symbol string, factor, offset float64, aliases ...string) {
+	u := unit{dimension: dimension, symbol: symbol, factor: factor, offset: offset}
+	units[strings.ToLower(symbol)] = u
+	for _, alias := range aliases {
+		units[alias] = u
+	}
+}
+
+func init() {
+	addUnit("data", "B", 1, 0, "byte", "bytes")
+	addUnit("data", "KB", 1e3, 0, "kilobyte", "kilobytes")
+	addUnit("data", "MB", 1e6, 0, "megabyte", "megabytes")
+	addUnit("data", "GB", 1e9, 0, "gigabyte", "gigabytes", "gig", "gigs")
+	addUnit("data", "TB", 1e12, 0, "terabyte", "terabytes")
+	addUnit("data", "PB", 1e15, 0, "petabyte", "petabytes")
+	addUnit("data", "KiB", 1<<10, 0, "kibibyte", "kibibytes")
+	addUnit("data", "MiB", 1<<20, 0, "mebibyte", "mebibytes")
+	addUnit("data", "GiB", 1<<30, 0, "gibibyte", "gibibytes")
+	addUnit("data", "TiB", 1<<40, 0, "tebibyte", "tebibytes")
+	addUnit("data", "PiB", 1<<50, 0, "pebibyte", "pebibytes")
+
+	addUnit("length", "mm", 0.001, 0, "millimeter", "millimeters", "millimetre", "millimetres")
+	addUnit("length", "cm", 0.01, 0, "centimeter", "centimeters", "centimetre", "centimetres")
+	addUnit("length", "m", 1, 0, "meter", "meters", "metre", "metres")
+	addUnit("length", "km", 1000, 0, "kilometer", "kilometers", "kilometre", "kilometres")
+	addUnit("length", "in", 0.0254, 0, "inch", "inches")
+	addUnit("length", "ft", 0.3048, 0, "foot", "feet")
+	addUnit("length", "yd", 0.9144, 0, "yard", "yards")
+	addUnit("length", "mi", 1609.344, 0, "mile", "miles")
+
+	addUnit("mass", "g", 0.001, 0, "gram", "grams")
+	addUnit("mass", "kg", 1, 0, "kilogram", "kilograms", "kilo", "kilos")
+	addUnit("mass", "lb", 0.45359237, 0, "lbs", "pound", "pounds")
+	addUnit("mass", "oz", 0.028349523125, 0, "ounce", "ounces")
+
+	addUnit("time", "ms", 0.001, 0, "millisecond", "milliseconds")
+	addUnit("time", "s", 1, 0, "sec", "secs", "second", "seconds")
+	addUnit("time", "min", 60, 0, "mins", "minute", "minutes")
+	addUnit("time", "h", 3600, 0, "hr", "hrs", "hour", "hours")
+	addUnit("time", "days", 86400, 0, "day", "d")
+	addUnit("time", "weeks", 604800, 0, "week", "wk")
+
+	// Temperatures convert through kelvin
+	addUnit("temperature", "°C", 1, 273.15, "c", "celsius", "degc", "degrees celsius")
+	addUnit("temperature", "°F", 5.0/9, 459.67*5/9, "f", "fahrenheit", "degf", "degrees fahrenheit")
+	addUnit("temperature", "K", 1, 0, "kelvin")
+}
+
+const numberPattern = `(-?[\d,]*\.?\d+)`
+const unitPattern = `((?:degrees\s+)?[a-z°]+)`
+
+// convertForward and convertAsked match "3.2GB in MB", "convert 5 miles to km", and "how many MB is 3.2GB"
+var (
+	convertForward = regexp.MustCompile(`(?i)^(?:convert\s+|what\s+is\s+|what's\s+)?` + numberPattern + `\s*` + unitPattern + `\s+(?:to|in|into|as)\s+` + unitPattern + `$`)
+	convertAsked   = regexp.MustCompile(`(?i)^how\s+(?:many|much)\s+` + unitPattern + `\s+(?:is|are|in|makes?)\s+` + numberPattern + `\s*` + unitPattern + `$`)
+)
+
+func convertUnits(text string) (Answer, bool) {
+	var value, from, to string
+	if m := convertForward.FindStringSubmatch(text); m != nil {
+		value, from, to = m[1], m[2], m[3]
+	} else if m := convertAsked.FindStringSubmatch(text); m != nil {
+		value, from, to = m[2], m[3], m[1]
+	} else {
+		return Answer{}, false
+	}
+
+	source, ok := lookupUnit(from)
+	if !ok {
+		return Answer{}, false
+	}
+	target, ok := lookupUnit(to)
+	if !ok || target.dimension != source.dimension {
+		return Answer{}, false
+	}
+
+	amount := parseNumber(value)
+	result := convert(amount, source, target)
+	answer := Answer{Result: fmt.Sprintf("%s %s = %s %s", FormatNumber(amount), source.symbol, FormatNumber(result), target.symbol)}
+
+	// Disk makers count in powers of 1000 and most tools in powers of 1024, so show both
+	if source.dimension == "data" && isDecimalData(source) && isDecimalData(target) && source.symbol != "B" && target.symbol != "B" {
+		binarySource, binaryTarget := units[strings.ToLower(binarySymbol(source))], units[strings.ToLower(binarySymbol(target))]
+		answer.Note = fmt.Sprintf("Decimal units, counted in powers of 1000. In binary units, %s %s = %s %s.",
+			FormatNumber(amount), binarySource.symbol, FormatNumber(convert(amount, binarySource, binaryTarget)), binaryTarget.symbol)
+	}
+	return answer, true
+}
+
+func lookupUnit(name string) (unit, bool) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	u, ok := units[name]
+	return u, ok
+}
+
+func convert(amount float64, from, to unit) float64 {
+	return (amount*from.factor + from.offset - to.offset) / to.factor
+}
+
+func isDecimalData(u unit) bool {
+	return !strings.Contains(u.symbol, "i")
+}
+
+// binarySymbol turns "GB" into "GiB"
+func binarySymbol(u unit) string {
+	return strings.TrimSuffix(u.symbol, "B") + "iB"
+}
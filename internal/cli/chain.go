@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/chain.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// The sample of a quest's output sent with the follow-up intent that builds on it
+const (
+	chainHeadLines    = 60
+	chainTailLines    = 60
+	chainNotableLines = 40
+)
+
+// PriorStep is an executed quest whose output a follow-up intent builds on
+type PriorStep struct {
+	Intent  string
+	Content string
+	Output  string // a redacted sample of what the quest printed
+	Lines   int
+}
+
+// Describe tells the oracle what the previous quest ran and printed, so that the follow-up can
+// act on what the output shows
+func (p *PriorStep) Describe() string {
+	output := p.Output
+	if strings.TrimSpace(output) == "" {
+		output = "(nothing)"
+	}
+	return fmt.Sprintf("PREVIOUS STEP (this intent follows the quest \"%s\"; act on what its output shows, using the exact names it printed):\nIt ran:\n%s\nIt printed %d lines:\n%s", p.Intent, p.Content, p.Lines, output)
+}
+
+// ChainRun carries out a quest and then follow-up intents that build on its output, as in
+// "list failed systemd units" --then "restart the ones related to docker". Every quest is
+// confirmed on its own, and the chain stops at the first one that does not succeed.
+type ChainRun struct {
+	Deps PipelineDeps
+}
+
+// Run carries out the base quest through the full pipeline, then each follow-up in turn with the
+// output of the quest before it, printing the progress of the chain at the end
+func (c *ChainRun) Run(base *Quest, followUps []string) ([]*StepResult, error) {
+	results := []*StepResult{{Intent: base.Intent, Quest: base, Status: StepPending}}
+	for _, intent := range followUps {
+		results = append(results, &StepResult{Intent: intent, Status: StepPending})
+	}
+
+	err := NewPipeline(c.Deps).Run(base)
+	results[0].Status, results[0].Note = stepOutcome(base, err)
+	if err != nil {
+		return results, err
+	}
+
+	previous := results[0]
+	for i, result := range results[1:] {
+		prior, reason := priorStep(previous)
+		if prior == nil {
+			ui.PrintStatusBox("🔗 CHAIN STOPPED", fmt.Sprintf("I will not go on to \"%s\", my lord: %s.", result.Intent, reason), "info")
+			break
+		}
+
+		ui.PrintPhaseHeader("🔗", fmt.Sprintf("Then %d of %d: %s", i+1, len(followUps), result.Intent))
+		q := &Quest{
+			Intent:       result.Intent,
+			Config:       base.Config,
+			SysInfo:      base.SysInfo,
+			AsUser:       base.AsUser,
+			AutoFixLimit: base.AutoFixLimit,
+			AirGapped:    base.AirGapped,
+			ContextName:  base.ContextName,
+			Context:      base.Context,
+			Prior:        prior,
+		}
+		result.Quest = q
+
+		stages := append([]Stage{&validateStage{newValidator: c.Deps.NewIntentValidator, prompter: c.Deps.Prompter}}, questStages(c.Deps)...)
+		_, err := runStages(q, stages...)
+		result.Status, result.Note = stepOutcome(q, err)
+		if err != nil {
+			return results, err
+		}
+		previous = result
+	}
+
+	printSteps("🔗 QUEST CHAIN", results)
+	return results, nil
+}
+
+// priorStep reads the output of a finished quest for the follow-up after it, or explains why
+// there is nothing to build on
+func priorStep(result *StepResult) (*PriorStep, string) {
+	q := result.Quest
+	switch {
+	case result.Status != StepSucceeded:
+		return nil, fmt.Sprintf("the quest before it did not succeed (%s)", result.Status)
+	case q.Detached != nil:
+		return nil, "the quest before it runs in a detached session, so its output is not here yet"
+	case q.OutputLog == nil:
+		return nil, "the output of the quest before it was not captured"
+	}
+
+	sample, err := system.SampleOutputLog(q.OutputLog.Path, chainHeadLines, chainTailLines, chainNotableLines)
+	if err != nil {
+		return nil, err.Error()
+	}
+	// Tools print credentials; they must never reach the provider
+	return &PriorStep{
+		Intent:  q.Intent,
+		Content: q.Content,
+		Output:  outputRedactor(q).Redact(sample),
+		Lines:   q.OutputLog.Lines,
+	}, ""
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/clipboard.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// readClipboardContext reads the clipboard for --from-clipboard and prepares it for the prompt:
+// cut to a sensible length, with secrets and, when withheld, the home directory redacted
+func readClipboardContext(cfg *config.Config) (string, error) {
+	text, err := system.ReadClipboard()
+	if err != nil {
+		return "", fmt.Errorf("%w, my lord", err)
+	}
+	return ClipboardContext(text, cfg)
+}
+
+// ClipboardContext prepares copied text to be sent with an intent, telling the user how much of
+// it is sent. Empty text is an error, since the intent likely refers to it.
+func ClipboardContext(text string, cfg *config.Config) (string, error) {
+	text, cut := system.TrimClipboard(text)
+	if text == "" {
+		return "", fmt.Errorf("the clipboard is empty, my lord; copy the text for the quest first")
+	}
+
+	var homeDir string
+	if !cfg.Privacy.AllowHomeDir() {
+		homeDir, _ = os.UserHomeDir()
+	}
+	text = system.NewRedactor(homeDir, cfg.APIKey).Redact(text)
+
+	lines := strings.Count(text, "\n") + 1
+	message := fmt.Sprintf("The %d line(s) on the clipboard will be sent with your quest as context.", lines)
+	if cut {
+		message = fmt.Sprintf("The clipboard holds more than %d characters; only its beginning will be sent with your quest.", system.MaxClipboardChars)
+	}
+	ui.PrintInfoMessage(message)
+	return text, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/commit.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxCommitMessageAttempts caps how many messages the oracle is asked for in one run
+const maxCommitMessageAttempts = 5
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Have the oracle write the commit message for your staged changes",
+	Long: `Read the changes staged in the git repository of the current directory, have the oracle write a commit message for them in the style of the repository's recent commits, and commit once you approve it.
+
+You can commit with the message as written, edit it first in git's editor, or ask for another. Only the staged changes are sent to the oracle, with credentials redacted, along with the branch name and the subjects of the last few commits.`,
+	Args: cobra.NoArgs,
+	RunE: runCommit,
+}
+
+func init() {
+	rootCmd.AddCommand(commitCmd)
+}
+
+func runCommit(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	client, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+	cwd, _ := os.Getwd()
+	committer := &Committer{
+		Client:   client,
+		Repo:     system.NewGitRepository(cwd),
+		Prompter: newStdinConsole(os.Stdin),
+		APIKey:   cfg.APIKey,
+	}
+	_, err = committer.Run()
+	return err
+}
+
+// Committer commits the staged changes of a repository with a message the oracle writes
+type Committer struct {
+	Client   ai.Client
+	Repo     system.GitRepository
+	Prompter Prompter
+	APIKey   string // redacted from the changes with other credentials before they are sent
+}
+
+// Run has the oracle write a commit message for the staged changes and commits with it, or with
+// the user's edit of it, once approved. It returns the message committed with, or "" when nothing
+// was committed. An edited message is the one git records; the oracle's is returned.
+func (c *Committer) Run() (string, error) {
+	changes, err := c.Repo.StagedChanges()
+	if err != nil {
+		return "", fmt.Errorf("cannot read the staged changes, my lord: %w", err)
+	}
+	if len(changes.Files) == 0 {
+		ui.PrintStatusBox("📝 NOTHING STAGED", "No changes are staged for a commit, my lord. Stage them first, e.g. with 'git add -p'.", "info")
+		return "", nil
+	}
+	ui.PrintStatusBox(fmt.Sprintf("📝 STAGED CHANGES (%d files)", len(changes.Files)), changes.Stat, "info")
+
+	redactor := system.NewRedactor("", c.APIKey)
+	sent := *changes
+	sent.Diff = redactor.Redact(changes.Diff)
+	sent.Stat = redactor.Redact(changes.Stat)
+	if sent.Diff != changes.Diff {
+		ui.PrintInfoMessage("The changes hold what looks like a credential, my lord. It was redacted before being shown to the oracle; consider whether it belongs in the commit at all.")
+	}
+
+	var rejected []string
+	for len(rejected) < maxCommitMessageAttempts {
+		message, err := c.Client.GenerateCommitMessage(&sent, rejected)
+		if err != nil {
+			return "", oracleFailure(err)
+		}
+		ui.PrintStatusBox("📝 COMMIT MESSAGE", message, "info")
+
+		choice, err := c.Prompter.Choose("Shall I commit with this message, my lord?", []string{
+			"Commit with this message",
+			"Edit it before committing",
+			"Ask the oracle for another",
+			"Leave the changes uncommitted",
+		})
+		if err != nil {
+			return "", err
+		}
+		switch choice {
+		case 0, 1:
+			if err := c.Repo.Commit(message, choice == 1); err != nil {
+				return "", fmt.Errorf("git did not commit the changes, my lord: %w", err)
+			}
+			summary := fmt.Sprintf("\"%s\" is now part of the history, my lord.", subjectLine(message))
+			if choice == 1 {
+				summary = "The changes are committed with your edit of the message, my lord."
+			}
+			ui.PrintStatusBox("🏆 CHANGES COMMITTED", summary, "success")
+			return message, nil
+		case 2:
+			rejected = append(rejected, message)
+		default:
+			ui.PrintInfoMessage("The changes remain staged and uncommitted, my lord.")
+			return "", nil
+		}
+	}
+
+	ui.PrintStatusBox("📝 NO MESSAGE CHOSEN", fmt.Sprintf("The oracle wrote %d messages and none pleased you, my lord. The changes remain staged; commit them with your own words.", maxCommitMessageAttempts), "warning")
+	return "", nil
+}
+
+// subjectLine returns the first line of a commit message
+func subjectLine(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return subject
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/minand-mohan/execute-my-will/internal/ai"
 	"github.com/minand-mohan/execute-my-will/internal/config"
@@ -25,14 +26,52 @@ var configureCmd = &cobra.Command{
 	RunE:  runConfigure,
 }
 
+var configureShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the current settings, with the API key masked",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfigForFacts()
+		if cfg == nil {
+			return err
+		}
+		printConfiguration(cfg)
+		ui.PrintInfoMessage("Change them with: execute-my-will configure")
+		return nil
+	},
+}
+
 func init() {
+	configureCmd.AddCommand(configureShowCmd)
+
 	// Add flags for non-interactive configuration
-	configureCmd.Flags().String("provider", "", "AI provider (gemini, openai, anthropic)")
+	configureCmd.Flags().String("provider", "", "AI provider (gemini, openai, anthropic, azure-openai, openrouter, or mock for canned responses)")
 	configureCmd.Flags().String("api-key", "", "API key for the AI provider")
 	configureCmd.Flags().String("model", "", "Model to use (uses provider defaults if not specified)")
 	configureCmd.Flags().Int("max-tokens", 0, "Maximum tokens for AI response")
 	configureCmd.Flags().Float32("temperature", -1, "Temperature for AI response (0.0-1.0)")
+	configureCmd.Flags().String("base-url", "", "Root of an OpenAI-compatible server for the openai provider, e.g. http://localhost:1234/v1 (empty for api.openai.com)")
+	configureCmd.Flags().String("mock-responses", "", "YAML file of canned responses for the mock provider")
+	configureCmd.Flags().String("azure-endpoint", "", "Endpoint of your Azure OpenAI resource, e.g. https://my-resource.openai.azure.com")
+	configureCmd.Flags().String("azure-deployment", "", "Azure OpenAI deployment name (defaults to the model name)")
+	configureCmd.Flags().String("openrouter-base-url", "", "Root of the OpenRouter API (defaults to "+config.DefaultOpenRouterBaseURL+")")
 	configureCmd.Flags().String("mode", "", "Execution mode: monarch or royal-heir")
+	configureCmd.Flags().String("verbosity", "", "UI verbosity: minimal, normal, or festive")
+	configureCmd.Flags().String("persona", "", "UI persona: knight, pirate, starship, plain, or the path to a persona file")
+	configureCmd.Flags().String("locale", "", "Locale for dates, durations, and sizes, e.g. de_DE or en_US (empty follows LC_ALL and LANG)")
+	configureCmd.Flags().String("confirm", "", "How quests are confirmed: type (y, or the word for yes in your language) or select (the arrow keys and Enter)")
+	configureCmd.Flags().Bool("stream", true, "Show the oracle's answer while it is generated (false waits for the whole answer)")
+	configureCmd.Flags().Bool("system-scan", true, "Enumerate installed packages and commands before each quest (false sends only the OS, shell, and current directory)")
+	configureCmd.Flags().Bool("read-only-default-yes", false, "In monarch mode, let Enter approve quests that only read; riskier quests still default to no")
+	configureCmd.Flags().Bool("reauthenticate", false, "Before a destructive quest runs, ask the system to confirm it is you (sudo password, Touch ID, or Windows Hello)")
+	configureCmd.Flags().Int("repeat-warning-minutes", 0, "Warn before a destructive command or script that ran this many minutes ago runs again (0 for the default of 15, negative to turn off)")
+	configureCmd.Flags().Int("max-line-size", 0, "Bytes of a single output line to print before skipping the rest of it (0 for the default of 1 MiB)")
+	configureCmd.Flags().Bool("commands-only", false, "Always propose a command, even for intents phrased as questions, instead of answering them")
+	configureCmd.Flags().StringSlice("withhold", nil, "Context to keep from the AI: installed-packages, available-commands, current-dir, home-dir, ssh-hosts, processes")
+	configureCmd.Flags().StringSlice("disclose", nil, "Context to share with the AI again (same names as --withhold), or envrc-variables to share the names of the variables an .envrc exports")
+	configureCmd.Flags().StringArray("define", nil, "Add a glossary term as 'term=meaning', e.g. 'my site=/var/www/blog' (repeatable)")
+	configureCmd.Flags().StringSlice("undefine", nil, "Remove glossary terms")
+	configureCmd.Flags().Bool("warm-up", true, "After saving, send a tiny request to check the key and measure the model's baseline latency")
 }
 
 func runConfigure(cmd *cobra.Command, args []string) error {
@@ -45,7 +84,27 @@ func runConfigure(cmd *cobra.Command, args []string) error {
 		cmd.Flags().Changed("model") ||
 		cmd.Flags().Changed("max-tokens") ||
 		cmd.Flags().Changed("temperature") ||
-		cmd.Flags().Changed("mode")
+		cmd.Flags().Changed("base-url") ||
+		cmd.Flags().Changed("mock-responses") ||
+		cmd.Flags().Changed("azure-endpoint") ||
+		cmd.Flags().Changed("azure-deployment") ||
+		cmd.Flags().Changed("openrouter-base-url") ||
+		cmd.Flags().Changed("mode") ||
+		cmd.Flags().Changed("verbosity") ||
+		cmd.Flags().Changed("persona") ||
+		cmd.Flags().Changed("locale") ||
+		cmd.Flags().Changed("system-scan") ||
+		cmd.Flags().Changed("stream") ||
+		cmd.Flags().Changed("confirm") ||
+		cmd.Flags().Changed("commands-only") ||
+		cmd.Flags().Changed("read-only-default-yes") ||
+		cmd.Flags().Changed("reauthenticate") ||
+		cmd.Flags().Changed("repeat-warning-minutes") ||
+		cmd.Flags().Changed("max-line-size") ||
+		cmd.Flags().Changed("withhold") ||
+		cmd.Flags().Changed("disclose") ||
+		cmd.Flags().Changed("define") ||
+		cmd.Flags().Changed("undefine")
 
 	// Load existing config or create new one
 	cfg, err := config.Load()
@@ -83,11 +142,136 @@ func runConfigure(cmd *cobra.Command, args []string) error {
 			cfg.Temperature = temperature
 		}
 
+		if cmd.Flags().Changed("base-url") {
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			cfg.BaseURL = baseURL
+		}
+
+		if cmd.Flags().Changed("mock-responses") {
+			responses, _ := cmd.Flags().GetString("mock-responses")
+			cfg.Mock.Responses = responses
+		}
+
+		if cmd.Flags().Changed("azure-endpoint") {
+			endpoint, _ := cmd.Flags().GetString("azure-endpoint")
+			cfg.Azure.Endpoint = endpoint
+		}
+
+		if cmd.Flags().Changed("azure-deployment") {
+			deployment, _ := cmd.Flags().GetString("azure-deployment")
+			cfg.Azure.Deployment = deployment
+		}
+
+		if cmd.Flags().Changed("openrouter-base-url") {
+			baseURL, _ := cmd.Flags().GetString("openrouter-base-url")
+			cfg.OpenRouter.BaseURL = baseURL
+		}
+
 		if cmd.Flags().Changed("mode") {
 			mode, _ := cmd.Flags().GetString("mode")
 			cfg.Mode = mode
 		}
 
+		if cmd.Flags().Changed("verbosity") {
+			verbosity, _ := cmd.Flags().GetString("verbosity")
+			cfg.UI.Verbosity = verbosity
+		}
+
+		if cmd.Flags().Changed("confirm") {
+			confirm, _ := cmd.Flags().GetString("confirm")
+			cfg.UI.Confirm = confirm
+		}
+
+		if cmd.Flags().Changed("persona") {
+			persona, _ := cmd.Flags().GetString("persona")
+			if _, err := ui.LoadPersona(persona); err != nil {
+				return err
+			}
+			cfg.UI.Persona = persona
+		}
+
+		if cmd.Flags().Changed("locale") {
+			locale, _ := cmd.Flags().GetString("locale")
+			if locale != "" {
+				if _, err := ui.ParseLocale(locale); err != nil {
+					return err
+				}
+			}
+			cfg.UI.Locale = locale
+		}
+
+		if cmd.Flags().Changed("stream") {
+			stream, _ := cmd.Flags().GetBool("stream")
+			cfg.UI.Stream = &stream
+		}
+
+		if cmd.Flags().Changed("system-scan") {
+			scan, _ := cmd.Flags().GetBool("system-scan")
+			cfg.Analysis.SystemScan = &scan
+		}
+
+		if cmd.Flags().Changed("commands-only") {
+			commandsOnly, _ := cmd.Flags().GetBool("commands-only")
+			cfg.Execution.CommandsOnly = commandsOnly
+		}
+
+		if cmd.Flags().Changed("read-only-default-yes") {
+			defaultYes, _ := cmd.Flags().GetBool("read-only-default-yes")
+			cfg.Execution.ReadOnlyDefaultYes = defaultYes
+		}
+
+		if cmd.Flags().Changed("reauthenticate") {
+			reauthenticate, _ := cmd.Flags().GetBool("reauthenticate")
+			cfg.Execution.Reauthenticate = reauthenticate
+		}
+
+		if cmd.Flags().Changed("repeat-warning-minutes") {
+			minutes, _ := cmd.Flags().GetInt("repeat-warning-minutes")
+			cfg.Execution.RepeatWarningMinutes = minutes
+		}
+
+		if cmd.Flags().Changed("max-line-size") {
+			size, _ := cmd.Flags().GetInt("max-line-size")
+			cfg.Execution.MaxLineSize = size
+		}
+
+		if cmd.Flags().Changed("withhold") {
+			fields, _ := cmd.Flags().GetStringSlice("withhold")
+			for _, field := range fields {
+				if err := cfg.Privacy.SetField(field, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		if cmd.Flags().Changed("disclose") {
+			fields, _ := cmd.Flags().GetStringSlice("disclose")
+			for _, field := range fields {
+				if err := cfg.Privacy.SetField(field, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		if cmd.Flags().Changed("undefine") {
+			terms, _ := cmd.Flags().GetStringSlice("undefine")
+			for _, term := range terms {
+				if !cfg.Undefine(term) {
+					ui.PrintWarningMessage(fmt.Sprintf("'%s' is not in your glossary", term))
+				}
+			}
+		}
+
+		if cmd.Flags().Changed("define") {
+			entries, _ := cmd.Flags().GetStringArray("define")
+			for _, entry := range entries {
+				term, meaning, _ := strings.Cut(entry, "=")
+				if err := cfg.Define(term, meaning); err != nil {
+					return err
+				}
+			}
+		}
+
 		ui.PrintInfoMessage("Updating configuration with provided values...")
 	} else {
 		// Interactive mode
@@ -113,12 +297,35 @@ func runConfigure(cmd *cobra.Command, args []string) error {
 	// Display final configuration
 	fmt.Println()
 	ui.PrintSuccessMessage("Configuration saved successfully!")
+	if warmUp, _ := cmd.Flags().GetBool("warm-up"); warmUp && !cfg.OfflineProvider() {
+		warmUpOracle(cfg)
+	}
 	fmt.Println()
 	displayConfiguration(cfg)
 
 	return nil
 }
 
+// warmUpOracle checks a new configuration with a tiny request, so that a wrong key, model, or
+// region shows up now rather than on the first quest, and keeps the latency as the baseline the
+// doctor reports
+func warmUpOracle(cfg *config.Config) {
+	ui.PrintInfoMessage(fmt.Sprintf("Sending a tiny request to %s to check the key and measure its latency...", cfg.Model))
+	latency, err := ai.WarmUp(cfg, ai.WarmUpTimeout)
+	if err != nil {
+		ui.PrintStatusBox("⚠️  WARM-UP FAILED", fmt.Sprintf("The oracle did not answer the warm-up request, my lord: %v\n\nThe configuration was saved, but quests will fail until this is fixed. Check the API key, the model name, and the endpoint or region of your provider.", err), "warning")
+		return
+	}
+
+	// The baseline is only informative, so failing to keep it never fails configure
+	path := config.StatePath(ai.LatencyFile)
+	if baselines, err := ai.LoadLatencyBaselines(path); err == nil {
+		baselines.Models[ai.StatsKey(cfg.AIProvider, cfg.Model)] = &ai.LatencyBaseline{Latency: latency, MeasuredAt: time.Now()}
+		_ = baselines.Save(path)
+	}
+	ui.PrintSuccessMessage(fmt.Sprintf("The oracle answered in %s.", latency.Round(time.Millisecond)))
+}
+
 func runInteractiveConfiguration(cfg *config.Config) error {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -126,6 +333,8 @@ func runInteractiveConfiguration(cfg *config.Config) error {
 		"1": "gemini",
 		"2": "openai",
 		"3": "anthropic",
+		"4": "azure-openai",
+		"5": "openrouter",
 	}
 
 	// List AI  Providers
@@ -133,30 +342,64 @@ func runInteractiveConfiguration(cfg *config.Config) error {
 	fmt.Println(ui.Cyan.Sprint("1. Gemini"))
 	fmt.Println(ui.Cyan.Sprint("2. OpenAI"))
 	fmt.Println(ui.Cyan.Sprint("3. Anthropic"))
+	fmt.Println(ui.Cyan.Sprint("4. Azure OpenAI"))
+	fmt.Println(ui.Cyan.Sprint("5. OpenRouter (many models, one key)"))
 	fmt.Print(ui.Gold.Sprint("Enter the number of the provider you want to use: "))
 
 	if input := readInput(reader); input != "" {
 		cfg.AIProvider = providers[input]
 	}
 
-	// Update model default based on provider
-	if cfg.Model == "" || !isValidModelForProvider(cfg.Model, cfg.AIProvider) {
+	// The OpenAI provider also serves self-hosted OpenAI-compatible servers
+	if cfg.AIProvider == "openai" {
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "api.openai.com"
+		}
+		fmt.Printf("%s Base URL of an OpenAI-compatible server, or '-' for api.openai.com [%s]: ", ui.Gold.Sprint("🌐"), ui.Gray.Sprint(baseURL))
+		if input := readInput(reader); input == "-" {
+			cfg.BaseURL = ""
+		} else if input != "" {
+			cfg.BaseURL = input
+		}
+	}
+
+	// Update model default based on provider; self-hosted servers name their models freely
+	if cfg.Model == "" || (!cfg.CustomEndpoint() && !isValidModelForProvider(cfg.Model, cfg.AIProvider)) {
 		cfg.Model = config.GetDefaultModel(cfg.AIProvider)
 	}
 
-	// Configure API Key (mandatory)
+	// Configure API Key (mandatory, except for self-hosted servers)
 	for {
 		fmt.Printf("%s API Key [%s]: ", ui.Gold.Sprint("🔑"), ui.Gray.Sprint(maskAPIKey(cfg.APIKey)))
 		if input := readInput(reader); input != "" {
 			cfg.APIKey = input
 			break
-		} else if cfg.APIKey != "" {
+		} else if cfg.APIKey != "" || cfg.CustomEndpoint() {
 			// Keep existing API key
 			break
 		}
 		ui.PrintErrorMessage("API Key is required. Please provide a valid API key.")
 	}
 
+	// Azure OpenAI is reached through the user's own resource and deployment
+	if cfg.AIProvider == "azure-openai" {
+		for {
+			fmt.Printf("%s Azure OpenAI Endpoint [%s]: ", ui.Gold.Sprint("🌐"), ui.Gray.Sprint(cfg.Azure.Endpoint))
+			if input := readInput(reader); input != "" {
+				cfg.Azure.Endpoint = input
+				break
+			} else if cfg.Azure.Endpoint != "" {
+				break
+			}
+			ui.PrintErrorMessage("The endpoint is required, e.g. https://my-resource.openai.azure.com")
+		}
+		fmt.Printf("%s Deployment Name [%s]: ", ui.Gold.Sprint("🚀"), ui.Gray.Sprint(cfg.Azure.DeploymentName(cfg.Model)))
+		if input := readInput(reader); input != "" {
+			cfg.Azure.Deployment = input
+		}
+	}
+
 	// Get Models for provider
 	aiClient, err := ai.NewClient(cfg)
 	if err != nil {
@@ -269,27 +512,36 @@ func isValidModelForProvider(model, provider string) bool {
 	switch provider {
 	case "gemini":
 		return strings.HasPrefix(model, "gemini")
-	case "openai":
+	case "openai", "azure-openai":
 		return strings.HasPrefix(model, "gpt") || strings.HasPrefix(model, "text-")
 	case "anthropic":
 		return strings.HasPrefix(model, "claude")
+	case "openrouter":
+		// OpenRouter names models "vendor/model"
+		return strings.Contains(model, "/")
 	default:
 		return true
 	}
 }
 
-func displayConfiguration(cfg *config.Config) {
-	// Create config map for structured display
-	configs := map[string]string{
-		"Provider":    ui.Cyan.Sprint(cfg.AIProvider),
-		"API Key":     ui.Gray.Sprint(maskAPIKey(cfg.APIKey)),
-		"Model":       ui.Cyan.Sprint(cfg.Model),
-		"Max Tokens":  ui.Blue.Sprint(fmt.Sprintf("%d", cfg.MaxTokens)),
-		"Temperature": ui.Blue.Sprint(fmt.Sprintf("%.1f", cfg.Temperature)),
-		"Mode":        ui.Purple.Sprint(cfg.Mode),
+// personaName shows the configured persona, which is the knight unless set
+func personaName(persona string) string {
+	if persona == "" {
+		return ui.DefaultPersona
 	}
+	return persona
+}
 
-	ui.PrintConfigBox(configs)
+// localeName shows the configured locale, or the one found in the environment when none is set
+func localeName(locale string) string {
+	if locale == "" {
+		return ui.DetectLocale(os.Getenv).Name + " (from the environment)"
+	}
+	return locale
+}
+
+func displayConfiguration(cfg *config.Config) {
+	printConfiguration(cfg)
 
 	// Mode-specific message
 	var modeMsg string
@@ -305,3 +557,61 @@ func displayConfiguration(cfg *config.Config) {
 	finalMsg := "Your knight is now ready to serve!\n\n💡 Try: " + ui.CommandText("execute-my-will \"list my files\"")
 	ui.PrintStatusBox("READY TO SERVE", finalMsg, "info")
 }
+
+// printConfiguration shows the settings in a box, with the API key masked
+func printConfiguration(cfg *config.Config) {
+	// Create config map for structured display
+	configs := map[string]string{
+		"Provider":    ui.Cyan.Sprint(cfg.AIProvider),
+		"API Key":     ui.Gray.Sprint(maskAPIKey(cfg.APIKey)),
+		"Model":       ui.Cyan.Sprint(cfg.Model),
+		"Max Tokens":  ui.Blue.Sprint(fmt.Sprintf("%d", cfg.MaxTokens)),
+		"Temperature": ui.Blue.Sprint(fmt.Sprintf("%.1f", cfg.Temperature)),
+		"Mode":        ui.Purple.Sprint(cfg.Mode),
+		"Verbosity":   ui.Purple.Sprint(cfg.UI.Verbosity),
+		"Persona":     ui.Purple.Sprint(personaName(cfg.UI.Persona)),
+		"Locale":      ui.Purple.Sprint(localeName(cfg.UI.Locale)),
+		"System Scan": ui.Gray.Sprint(scanSummary(cfg.Analysis)),
+		"Withheld":    ui.Gray.Sprint(withheldSummary(cfg.Privacy)),
+		"Glossary":    ui.Gray.Sprint(fmt.Sprintf("%d term(s)", len(cfg.Glossary))),
+		"Facts":       ui.Gray.Sprint(fmt.Sprintf("%d remembered", len(cfg.Facts))),
+	}
+	if !cfg.UI.StreamAnswers() {
+		configs["Streaming"] = ui.Gray.Sprint("off (the whole answer is awaited)")
+	}
+	if cfg.UI.SelectConfirmation() {
+		configs["Confirmation"] = ui.Gray.Sprint("chosen with the arrow keys")
+	}
+	if cfg.AIProvider == "mock" {
+		configs["Mock Responses"] = ui.Gray.Sprint(cfg.Mock.ResponsesPath())
+	}
+	if cfg.AIProvider == "azure-openai" {
+		configs["Azure Endpoint"] = ui.Gray.Sprint(cfg.Azure.Endpoint)
+		configs["Deployment"] = ui.Cyan.Sprint(cfg.Azure.DeploymentName(cfg.Model))
+	}
+	if cfg.CustomEndpoint() {
+		configs["Base URL"] = ui.Gray.Sprint(cfg.BaseURL)
+	}
+	if cfg.AIProvider == "openrouter" {
+		configs["OpenRouter URL"] = ui.Gray.Sprint(cfg.OpenRouter.URL())
+	}
+
+	ui.PrintConfigBox(configs)
+}
+
+// scanSummary describes how much of the system is examined before each quest
+func scanSummary(analysis config.AnalysisConfig) string {
+	if analysis.ScanSystem() {
+		return "full"
+	}
+	return "OS, shell, and current directory only"
+}
+
+// withheldSummary lists the context fields kept from the AI provider
+func withheldSummary(privacy config.PrivacyConfig) string {
+	withheld := privacy.Withheld()
+	if len(withheld) == 0 {
+		return "nothing"
+	}
+	return strings.Join(withheld, ", ")
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/distill.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxDistillChoices caps the recurring scripts offered at once
+const maxDistillChoices = 10
+
+var distillCmd = &cobra.Command{
+	Use:   "distill [name]",
+	Short: "Turn a script you run often into a shell function",
+	Long: `Offer the scripts from your quest history that you have run successfully several times, and turn the one you choose into a named shell function.
+
+Once you approve it, the function is appended to ~/.config/execute-my-will/functions.sh. Load that file from your shell's rc file to call the function directly, without asking the oracle again. The name is asked for unless given as an argument.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDistill,
+}
+
+func init() {
+	distillCmd.Flags().Int("min-runs", 3, "Only offer scripts run at least this many times")
+	rootCmd.AddCommand(distillCmd)
+}
+
+func runDistill(cmd *cobra.Command, args []string) error {
+	info, _ := system.NewQuickAnalyzer().AnalyzeSystem()
+	if info == nil || system.ShellFamily(info.Shell) != system.ShellFamilyPOSIX {
+		ui.PrintStatusBox("🐚 SHELL NOT SUPPORTED", "The function library can only be loaded by sh, bash, zsh, and their kin, my lord.", "warning")
+		return nil
+	}
+
+	if cfg, err := config.Load(); err == nil {
+		_ = applyLocale(cfg)
+	}
+	store, err := history.Load(config.StatePath(history.HistoryFile))
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	minRuns, _ := cmd.Flags().GetInt("min-runs")
+	distiller := &Distiller{
+		History:  store,
+		Prompter: newStdinConsole(os.Stdin),
+		Path:     config.StatePath(system.FunctionsFile),
+		MinRuns:  minRuns,
+		Now:      time.Now,
+	}
+	_, err = distiller.Run(name)
+	return err
+}
+
+// Distiller turns a script from the quest history into a function of the shell function library
+type Distiller struct {
+	History  *history.Store
+	Prompter Prompter
+	Path     string // the function library
+	MinRuns  int
+	Now      func() time.Time
+}
+
+// Run offers the recurring scripts, turns the chosen one into a function called name (asked for
+// when empty), and appends it to the library once approved. It returns the function added, or
+// "" when the library was left alone.
+func (d *Distiller) Run(name string) (string, error) {
+	recurring := d.History.Recurring(d.MinRuns)
+	if len(recurring) == 0 {
+		ui.PrintStatusBox("🧪 NOTHING TO DISTILL", fmt.Sprintf("No script has succeeded %d times yet, my lord. Scripts you run often will be offered here.", d.MinRuns), "info")
+		return "", nil
+	}
+	if len(recurring) > maxDistillChoices {
+		recurring = recurring[:maxDistillChoices]
+	}
+
+	options := make([]string, 0, len(recurring))
+	for _, entry := range recurring {
+		options = append(options, fmt.Sprintf("%s (%d runs, last on %s)", entry.Intent, entry.Runs, ui.FormatDate(entry.LastRun)))
+	}
+	index, err := d.Prompter.Choose("Which script shall become a shell function, my lord?", options)
+	if err != nil {
+		return "", err
+	}
+	entry := recurring[index]
+
+	library, err := os.ReadFile(d.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read the function library: %w", err)
+	}
+	if name == "" {
+		if name, err = d.Prompter.Ask("Name of the function", system.SuggestFunctionName(entry.Intent)); err != nil {
+			return "", err
+		}
+	}
+	if err := system.ValidateFunctionName(name); err != nil {
+		return "", err
+	}
+	if slices.Contains(system.DefinedFunctions(string(library)), name) {
+		return "", fmt.Errorf("'%s' is already defined in %s, my lord; choose another name", name, d.Path)
+	}
+
+	function := system.ShellFunction(name, entry.Intent, entry.Content, entry.Runs, d.Now())
+	ui.PrintStatusBox("🧪 DISTILLED FUNCTION", function, "info")
+	choice, err := d.Prompter.Choose(fmt.Sprintf("Append %s to %s?", name, d.Path), []string{"Append it", "Leave the library as it is"})
+	if err != nil {
+		return "", err
+	}
+	if choice != 0 {
+		ui.PrintInfoMessage("The function library is unchanged, my lord.")
+		return "", nil
+	}
+
+	if err := system.AppendFunction(d.Path, function); err != nil {
+		return "", err
+	}
+	ui.PrintStatusBox("📚 FUNCTION ADDED", fmt.Sprintf("'%s' now lives in %s.\n\nLoad the library from your shell's rc file (e.g. ~/.bashrc or ~/.zshrc) with:\n    . %s\n\nThen run it as: %s", name, d.Path, d.Path, name), "success")
+	return function, nil
+}
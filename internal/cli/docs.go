@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/docs.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxDocsWorkers caps the man pages read at once while building the index
+const maxDocsWorkers = 8
+
+var docsCmd = &cobra.Command{
+	Use:   "docs [tool...]",
+	Short: "Build a local index of your tools' documentation to ground generated commands",
+	Long: `Index the man pages of the commands on your PATH, so that each quest is sent the documentation of the tools it is about and the oracle uses flags that really exist.
+
+With tool names, only those tools are (re)indexed, and a tool without a man page is indexed from its --help output instead. --help is never run for the whole PATH, since not every program honours it.
+
+The index stays on this machine, in the configuration directory. Without it quests work as before.`,
+	RunE: runDocs,
+}
+
+func init() {
+	docsCmd.Flags().String("search", "", "Show the documentation that would be sent for this intent")
+	docsCmd.Flags().Bool("remove", false, "Delete the index; quests are no longer sent tool documentation")
+	rootCmd.AddCommand(docsCmd)
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	path := config.StatePath(system.DocsIndexFile)
+
+	if remove, _ := cmd.Flags().GetBool("remove"); remove {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		ui.PrintSuccessMessage("The documentation index is gone, my lord.")
+		return nil
+	}
+
+	if query, _ := cmd.Flags().GetString("search"); query != "" {
+		index, err := system.LoadDocsIndex(path)
+		if err != nil {
+			return err
+		}
+		if index == nil {
+			ui.PrintInfoMessage("There is no documentation index yet. Build one with 'execute-my-will docs'.")
+			return nil
+		}
+		grounding := index.Grounding(query)
+		if grounding == "" {
+			ui.PrintInfoMessage("No documentation matches that intent.")
+			return nil
+		}
+		ui.PrintPlain(grounding)
+		return nil
+	}
+
+	tools := args
+	if len(tools) == 0 {
+		if runtime.GOOS == "windows" {
+			ui.PrintInfoMessage("Windows has no man pages. Name the tools to index from their --help output, e.g. 'execute-my-will docs ffmpeg jq'.")
+			return nil
+		}
+		sysInfo, err := system.NewAnalyzer().AnalyzeSystem()
+		if err != nil && sysInfo == nil {
+			return err
+		}
+		tools = sysInfo.AvailableCommands
+		sort.Strings(tools)
+	}
+
+	ui.PrintPhaseHeader("📚", fmt.Sprintf("Reading the documentation of %d tools...", len(tools)))
+	chunks, documented := collectDocs(tools, len(args) > 0)
+
+	index := system.NewDocsIndex(chunks, time.Now())
+	if len(args) > 0 {
+		// Named tools are added to the existing index
+		existing, err := system.LoadDocsIndex(path)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			index = existing.Replace(documented, chunks, time.Now())
+		}
+	}
+	if err := index.Save(path); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Indexed %d of %d tools; the index now covers %d.", len(documented), len(tools), len(index.Tools()))
+	if missing := len(tools) - len(documented); missing > 0 && len(args) > 0 {
+		message += fmt.Sprintf(" %d had neither a man page nor --help output.", missing)
+	}
+	ui.PrintStatusBox("📚 DOCUMENTATION INDEXED", message+"\n\nQuests are now sent the documentation of the tools they are about.", "success")
+	return nil
+}
+
+// collectDocs reads the documentation of each tool, several at a time, and returns its chunks and
+// the tools that had any
+func collectDocs(tools []string, allowHelp bool) ([]system.DocChunk, []string) {
+	type result struct {
+		tool   string
+		chunks []system.DocChunk
+	}
+	jobs := make(chan string)
+	results := make(chan result)
+	for range min(runtime.NumCPU(), maxDocsWorkers, max(len(tools), 1)) {
+		go func() {
+			for tool := range jobs {
+				source, text := system.CollectToolDoc(tool, allowHelp)
+				var chunks []system.DocChunk
+				if text != "" {
+					chunks = system.ChunkToolDoc(tool, source, text)
+				}
+				results <- result{tool, chunks}
+			}
+		}()
+	}
+	go func() {
+		for _, tool := range tools {
+			jobs <- tool
+		}
+		close(jobs)
+	}()
+
+	bar := ui.NewProgressBar("Reading")
+	var chunks []system.DocChunk
+	var documented []string
+	for done := 1; done <= len(tools); done++ {
+		r := <-results
+		if len(r.chunks) > 0 {
+			chunks = append(chunks, r.chunks...)
+			documented = append(documented, r.tool)
+		}
+		bar.Update(done, len(tools))
+	}
+	bar.Finish()
+
+	// Workers finish in any order; keep the index stable between builds
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].Tool < chunks[j].Tool })
+	sort.Strings(documented)
+	return chunks, documented
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/doctor.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that your knight is ready for quests",
+	Long:  "Check the configuration and the system analysis, and report how reliably each AI provider/model has followed the expected response format across previous runs.",
+	Args:  cobra.NoArgs,
+	RunE:  runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ui.PrintPhaseHeader("🩺", "Examining your knight...")
+
+	template := ui.DefaultTemplate()
+	var problems int
+
+	// Configuration
+	var statsKey string
+	cfg, err := config.Load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		problems++
+		template.PrintBox("🔧 CONFIGURATION", []string{"", doctorLine(false, "Configuration", err.Error()), ""})
+	} else {
+		statsKey = ai.StatsKey(cfg.AIProvider, cfg.Model)
+		apiVersion := doctorLine(true, "API version", cfg.APIVersion(cfg.AIProvider))
+		if warning := cfg.APIVersionWarning(); warning != "" {
+			problems++
+			apiVersion = doctorLine(false, "API version", warning)
+		}
+		lines := []string{
+			"",
+			doctorLine(true, "Configuration", "loaded and valid"),
+			doctorLine(true, "Oracle", statsKey),
+			doctorLine(true, "Mode", cfg.Mode),
+			apiVersion,
+			latencyLine(statsKey),
+		}
+		if cfg.Budget.Enabled() {
+			if ledger, err := ai.LoadUsageLedger(config.StatePath(ai.UsageFile)); err == nil {
+				used := ledger.MonthTokens(time.Now())
+				lines = append(lines, doctorLine(used < cfg.Budget.SwitchThreshold(), "Monthly budget", fmt.Sprintf("about %d of %d tokens spent", used, cfg.Budget.MonthlyTokens)))
+			}
+		}
+		template.PrintBox("🔧 CONFIGURATION", append(lines, ""))
+	}
+
+	// System analysis
+	sysInfo, err := system.NewAnalyzer().AnalyzeSystem()
+	if err != nil && sysInfo == nil {
+		problems++
+		template.PrintBox("🏰 THE REALM", []string{"", doctorLine(false, "System analysis", err.Error()), ""})
+	} else {
+		lines := []string{"", doctorLine(true, "Shell", sysInfo.Shell)}
+		if len(sysInfo.PackageManagers) == 0 {
+			problems++
+			lines = append(lines, doctorLine(false, "Package Managers", "none detected"))
+		} else {
+			lines = append(lines, doctorLine(true, "Package Managers", strings.Join(sysInfo.PackageManagers, ", ")))
+		}
+		if err != nil {
+			lines = append(lines, doctorLine(false, "System analysis", err.Error()))
+		}
+		advice := SlowPhaseAdvice(sysInfo.Timings)
+		if len(advice) > 0 {
+			problems++
+		}
+		lines = append(lines, doctorLine(len(advice) == 0, "Analysis time", analysisTimeSummary(sysInfo.Timings)))
+		for _, text := range advice {
+			lines = append(lines, "  💡 "+text)
+		}
+		template.PrintBox("🏰 THE REALM", append(lines, ""))
+	}
+
+	// Response reliability across previous runs
+	stats, err := ai.LoadParseStats(config.StatePath(ai.ParseStatsFile))
+	if err != nil {
+		ui.PrintWarningMessage(err.Error())
+	} else {
+		printParseStats(stats, statsKey)
+	}
+
+	if problems > 0 {
+		ui.PrintStatusBox("⚠️  AILMENTS FOUND", fmt.Sprintf("Your knight has %d ailment(s) to tend to, my lord.", problems), "warning")
+	} else {
+		ui.PrintStatusBox("🏆 IN FINE HEALTH", "Your knight is ready for any quest, sire!", "success")
+	}
+	return nil
+}
+
+// printParseStats shows how often each provider/model returned a malformed response
+func printParseStats(stats *ai.ParseStats, currentKey string) {
+	lines := []string{""}
+	if len(stats.Models) == 0 {
+		lines = append(lines, ui.Gray.Sprint("No quests recorded yet."))
+	}
+	for _, key := range stats.Keys() {
+		m := stats.Models[key]
+		line := fmt.Sprintf("%-32s %4d responses  %3.0f%% unparsed  %d regenerated", key, m.Responses, m.FailureRate()*100, m.Regenerations)
+		if m.Truncations > 0 {
+			line += fmt.Sprintf("  %d cut off", m.Truncations)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+	ui.DefaultTemplate().PrintBox("📊 ORACLE RELIABILITY", lines)
+
+	if hint := stats.Hint(currentKey); hint != "" {
+		ui.PrintStatusBox("💡 ORACLE HINT", hint, "info")
+	}
+}
+
+// latencyLine shows the baseline latency measured when the model was configured
+func latencyLine(key string) string {
+	baselines, err := ai.LoadLatencyBaselines(config.StatePath(ai.LatencyFile))
+	if err != nil || baselines.Models[key] == nil {
+		return doctorLine(true, "Baseline latency", "not measured; run 'execute-my-will configure' to measure it")
+	}
+	baseline := baselines.Models[key]
+	return doctorLine(true, "Baseline latency", fmt.Sprintf("%s (measured %s)", baseline.Latency.Round(time.Millisecond), baseline.MeasuredAt.Format("2006-01-02")))
+}
+
+// analysisTimeSummary gives the total time of the system analysis and its slowest phase
+func analysisTimeSummary(timings []system.PhaseTiming) string {
+	var total time.Duration
+	var slowest *system.PhaseTiming
+	for i, timing := range timings {
+		if timing.Phase == system.PhaseTotal {
+			total = timing.Duration
+		} else if slowest == nil {
+			slowest = &timings[i] // the timings are sorted, the slowest first
+		}
+	}
+	if slowest == nil {
+		return total.Round(time.Millisecond).String()
+	}
+	return fmt.Sprintf("%s; slowest: %s (%s)", total.Round(time.Millisecond), slowest.Phase, slowest.Duration.Round(time.Millisecond))
+}
+
+func doctorLine(ok bool, label, value string) string {
+	mark := ui.Green.Sprint("✔")
+	if !ok {
+		mark = ui.Red.Sprint("✘")
+	}
+	return fmt.Sprintf("%s %-17s : %s", mark, label, value)
+}
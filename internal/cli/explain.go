@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/explain.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <command>",
+	Short: "Explain a command you found elsewhere, without running it",
+	Long: `Have the oracle explain a command you bring, such as one from a tutorial or a colleague's message, the way royal-heir mode explains its own proposals. The explanation takes this system into account: its operating system, shell, and installed tools.
+
+The command is never run. Quote it, or put it after --, so its flags are not taken for the knight's own. Credentials in the command are redacted before it is sent to the oracle.`,
+	Example: `  execute-my-will explain "tar -xzvf foo.tgz"
+  execute-my-will explain -- find . -name '*.log' -mtime +7 -delete`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	client, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+	explanation := &CommandExplanation{Client: client, Analyzer: newAnalyzer(cfg), APIKey: cfg.APIKey}
+	_, err = explanation.Run(strings.Join(args, " "))
+	return err
+}
+
+// CommandExplanation explains a command the user brings instead of one the oracle proposed
+type CommandExplanation struct {
+	Client   ai.Client
+	Analyzer system.SystemAnalyzer
+	APIKey   string // redacted from the command with other credentials before it is sent
+}
+
+// Run shows the command, with the steps of a chain and their risk, and the oracle's explanation
+// of it on this system. It returns the explanation. The command is never run.
+func (e *CommandExplanation) Run(command string) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", fmt.Errorf("there is no command to explain, my lord")
+	}
+
+	sysInfo, err := e.Analyzer.AnalyzeSystem()
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze the realm's systems, my lord: %w", err)
+	}
+
+	if parts := system.SplitCommandChain(command); len(parts) > 1 {
+		printProposedChain(parts)
+	} else {
+		ui.PrintCommandBox(command)
+	}
+
+	redacted := system.NewRedactor("", e.APIKey).Redact(command)
+	if redacted != command {
+		ui.PrintInfoMessage("The command holds what looks like a credential, my lord. It was redacted before being shown to the oracle.")
+	}
+
+	explanation, err := e.Client.ExplainCommand(redacted, sysInfo)
+	if err != nil {
+		return "", oracleFailure(err)
+	}
+	ui.PrintStatusBox("📚 COMMAND EXPLANATION", explanation, "info")
+	return explanation, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/integrate.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var integrateCmd = &cobra.Command{
+	Use:   "integrate",
+	Short: "Install a key binding that puts the proposed command on your prompt line",
+	Long: fmt.Sprintf(`Install a widget in your shell: type an intent on the prompt line and press %s, and once the quest is checked the proposed command replaces what you typed. Nothing is run until you press Enter, and then your own shell runs it, so quests that change directory or set variables work as they would if you had typed them.
+
+The widget is written to ~/.config/execute-my-will and loaded from your shell's startup file, which is shown as a diff and backed up before it is changed. With --print the widget is only printed.`, system.WidgetKey),
+	Example: `  execute-my-will integrate
+  execute-my-will integrate --shell fish
+  execute-my-will integrate --shell zsh --print > ~/.zsh/execute-my-will.zsh`,
+	Args: cobra.NoArgs,
+	RunE: runIntegrate,
+}
+
+func init() {
+	integrateCmd.Flags().String("shell", "", fmt.Sprintf("The shell to integrate with: %s (default: your current shell)", strings.Join(system.WidgetShells, ", ")))
+	integrateCmd.Flags().Bool("print", false, "Print the widget instead of installing it")
+	rootCmd.AddCommand(integrateCmd)
+}
+
+func runIntegrate(cmd *cobra.Command, args []string) error {
+	shellFlag, _ := cmd.Flags().GetString("shell")
+	printOnly, _ := cmd.Flags().GetBool("print")
+
+	info, _ := system.NewQuickAnalyzer().AnalyzeSystem()
+	shell := system.WidgetShell(shellFlag)
+	if shellFlag == "" && info != nil {
+		shell = system.WidgetShell(info.Shell)
+	}
+	if shell == "" {
+		return fmt.Errorf("choose the shell to integrate with using --shell, my lord: one of %s", strings.Join(system.WidgetShells, ", "))
+	}
+
+	binary := selfBinary()
+	if binary == "" {
+		binary = "execute-my-will"
+	}
+	if printOnly {
+		widget, err := system.ShellWidget(shell, binary)
+		if err != nil {
+			return err
+		}
+		fmt.Print(widget)
+		return nil
+	}
+
+	integration := &Integration{
+		Prompter: newStdinConsole(os.Stdin),
+		Binary:   binary,
+		Dir:      config.StatePath(""),
+		GOOS:     runtime.GOOS,
+		Now:      time.Now,
+	}
+	if info != nil {
+		integration.HomeDir = info.HomeDir
+	}
+	_, err := integration.Run(shell)
+	return err
+}
+
+// Integration installs the prompt-line widget in a shell
+type Integration struct {
+	Prompter Prompter
+	Binary   string // the execute-my-will binary the widget runs
+	Dir      string // where the widget script is written
+	HomeDir  string
+	GOOS     string
+	Now      func() time.Time
+}
+
+// Run writes the widget script for the shell and offers to load it from the shell's startup
+// file. It returns the startup file that was changed, or "" when it was left alone.
+func (i *Integration) Run(shell string) (string, error) {
+	widget, err := system.ShellWidget(shell, i.Binary)
+	if err != nil {
+		return "", fmt.Errorf("%w, my lord", err)
+	}
+	if err := os.MkdirAll(i.Dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", i.Dir, err)
+	}
+	path := filepath.Join(i.Dir, system.WidgetFileName(shell))
+	if err := os.WriteFile(path, []byte(widget), 0644); err != nil {
+		return "", fmt.Errorf("failed to write the widget: %w", err)
+	}
+	source := system.WidgetSourceLine(shell, path)
+	usage := fmt.Sprintf("Type an intent on the prompt line and press %s. Once checked, the proposed command replaces it; nothing runs until you press Enter.", system.WidgetKey)
+
+	profile := system.WidgetProfile(shell, i.HomeDir, i.GOOS)
+	if profile == "" {
+		ui.PrintStatusBox("🪄 WIDGET WRITTEN", fmt.Sprintf("The widget is in %s, my lord, but I cannot tell which startup file your %s reads. Load it from there with:\n\n    %s\n\n%s", path, shell, source, usage), "info")
+		return "", nil
+	}
+	shown := tildePath(profile, i.HomeDir)
+
+	edit, err := system.PlanStartupFileEdit(profile, []string{source}, i.Now())
+	if err != nil {
+		return "", err
+	}
+	if len(edit.Lines) == 0 {
+		ui.PrintStatusBox("🪄 WIDGET UPDATED", fmt.Sprintf("%s already loads the widget, my lord, and it is now up to date. Open a new terminal to use it.\n\n%s", shown, usage), "success")
+		return "", nil
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("The widget is in %s. I can load it from %s, which your %s reads in every new terminal:", path, shown, shell))
+	printDiff(strings.Split(strings.TrimSuffix(edit.Diff, "\n"), "\n"))
+	choice, err := i.Prompter.Choose(fmt.Sprintf("Shall I add it to %s, my lord?", shown), []string{
+		"Add it (the current file is backed up first)",
+		"Leave it to me",
+	})
+	if err != nil {
+		return "", err
+	}
+	if choice != 0 {
+		ui.PrintInfoMessage(fmt.Sprintf("%s is unchanged, my lord. Load the widget yourself with: %s", shown, source))
+		return "", nil
+	}
+
+	backup, err := edit.Apply(i.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", shown, err)
+	}
+	message := fmt.Sprintf("%s now loads the widget, my lord. Open a new terminal to use it.\n\n%s Because your own shell runs the command, quests that change directory or set variables work too.", shown, usage)
+	if backup != "" {
+		message += fmt.Sprintf("\n\nThe previous version was kept at %s", tildePath(backup, i.HomeDir))
+	}
+	ui.PrintStatusBox("🪄 WIDGET INSTALLED", message, "success")
+	return profile, nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/isolated.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// maxShownDiffLines bounds the diffs printed before asking for an approval
+const maxShownDiffLines = 400
+
+// IsolatedRun carries out a quest in a scratch copy of a directory, shows what it changed, and
+// applies the changes to the directory only with the user's approval
+type IsolatedRun struct {
+	Deps PipelineDeps
+	Dir  string
+}
+
+// IsolationOutcome is what became of an isolated quest's changes
+type IsolationOutcome string
+
+const (
+	IsolationUnchanged IsolationOutcome = "unchanged"
+	IsolationApplied   IsolationOutcome = "applied"
+	IsolationDiscarded IsolationOutcome = "discarded"
+	IsolationKept      IsolationOutcome = "kept" // left in the scratch copy for inspection
+)
+
+// Run copies the directory and runs the quest with collaborators pointed at the copy: the
+// oracle is told the copy is the current directory, and commands run inside it
+func (r *IsolatedRun) Run(quest func(deps PipelineDeps) error) (IsolationOutcome, error) {
+	ui.PrintInfoMessage(fmt.Sprintf("Copying %s to a scratch workspace for an isolated quest...", r.Dir))
+	scratch, err := system.NewScratchCopy(r.Dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare an isolated workspace, my lord: %w", err)
+	}
+
+	deps := r.Deps
+	deps.Analyzer = &isolatedAnalyzer{analyzer: deps.Analyzer, dir: scratch.Dir}
+	deps.Executor = system.WithWorkingDir(deps.Executor, scratch.Dir)
+	// The copy is compared as soon as the quest returns, so the quest must not outlive it
+	deps.FindMultiplexer = nil
+	questErr := quest(deps)
+
+	outcome, err := r.offerChanges(scratch)
+	if outcome != IsolationKept {
+		scratch.Remove()
+	}
+	return outcome, errors.Join(questErr, err)
+}
+
+// offerChanges shows the differences between the copy and the directory and asks what to do with them
+func (r *IsolatedRun) offerChanges(scratch *system.ScratchCopy) (IsolationOutcome, error) {
+	changes, err := scratch.Changes()
+	if err != nil {
+		return "", fmt.Errorf("failed to compare the isolated workspace with %s: %w", scratch.Source, err)
+	}
+	if len(changes) == 0 {
+		ui.PrintInfoMessage(fmt.Sprintf("The isolated quest left %s unchanged; there is nothing to apply.", scratch.Source))
+		return IsolationUnchanged, nil
+	}
+
+	printIsolatedChanges(scratch, changes)
+	choice, err := r.Deps.Prompter.Choose(fmt.Sprintf("Apply %s to %s?", countChanges(changes), scratch.Source), []string{
+		"Apply the changes",
+		"Discard the changes",
+		"Keep the scratch workspace to inspect, and apply nothing",
+	})
+	if err != nil {
+		return IsolationDiscarded, err
+	}
+
+	switch choice {
+	case 0:
+		if err := scratch.Apply(changes); err != nil {
+			return IsolationKept, fmt.Errorf("failed to apply the changes, my lord; the scratch workspace is kept at %s: %w", scratch.Dir, err)
+		}
+		ui.PrintSuccessMessage(fmt.Sprintf("Applied %s to %s.", countChanges(changes), scratch.Source))
+		return IsolationApplied, nil
+	case 2:
+		ui.PrintInfoMessage(fmt.Sprintf("The scratch workspace is kept at %s. Nothing was applied.", scratch.Dir))
+		return IsolationKept, nil
+	default:
+		ui.PrintInfoMessage(fmt.Sprintf("The changes were discarded; %s is untouched.", scratch.Source))
+		return IsolationDiscarded, nil
+	}
+}
+
+// countChanges returns "1 change" or "N changes"
+func countChanges(changes []system.FileChange) string {
+	if len(changes) == 1 {
+		return "1 change"
+	}
+	return fmt.Sprintf("%d changes", len(changes))
+}
+
+// printIsolatedChanges lists the changed paths, then their diff
+func printIsolatedChanges(scratch *system.ScratchCopy, changes []system.FileChange) {
+	lines := []string{""}
+	var diff []string
+	for _, change := range changes {
+		switch change.Kind {
+		case system.ChangeAdded:
+			lines = append(lines, ui.Green.Sprint("+ ")+change.Path)
+		case system.ChangeDeleted:
+			lines = append(lines, ui.Red.Sprint("- ")+change.Path)
+		default:
+			lines = append(lines, ui.Yellow.Sprint("~ ")+change.Path)
+		}
+		diff = append(diff, strings.Split(strings.TrimSuffix(scratch.Diff(change), "\n"), "\n")...)
+	}
+	lines = append(lines, "")
+	ui.DefaultTemplate().PrintBox(fmt.Sprintf("🧪 ISOLATED CHANGES (%d)", len(changes)), lines)
+
+	printDiff(diff)
+}
+
+// isolatedAnalyzer reports the scratch copy as the current directory
+type isolatedAnalyzer struct {
+	analyzer system.SystemAnalyzer
+	dir      string
+}
+
+func (a *isolatedAnalyzer) AnalyzeSystem() (*system.Info, error) {
+	info, err := a.analyzer.AnalyzeSystem()
+	if err != nil {
+		return nil, err
+	}
+	info.CurrentDir = a.dir
+	return info, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/journal.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// defaultHistoryLimit is how many quests 'history' lists unless told otherwise
+const defaultHistoryLimit = 20
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the quests your knight has carried out",
+	Long: `List earlier quests, newest first: when each was asked, the command or script proposed, whether you approved it, and how it ended.
+
+Every quest is recorded in ~/.config/execute-my-will/journal.yaml, including the ones you declined and the ones that never ran. Credentials in commands are redacted before they are written down.`,
+	Example: `  execute-my-will history
+  execute-my-will history --search docker
+  execute-my-will history --failed --limit 50`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringP("search", "s", "", "Only list quests whose intent or command contains this text")
+	historyCmd.Flags().Bool("failed", false, "Only list quests that ran and failed")
+	historyCmd.Flags().IntP("limit", "n", defaultHistoryLimit, "List at most this many quests (0 for all)")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	term, _ := cmd.Flags().GetString("search")
+	failed, _ := cmd.Flags().GetBool("failed")
+	limit, _ := cmd.Flags().GetInt("limit")
+	// The journal can be browsed before the knight is configured
+	if cfg, err := config.Load(); err == nil {
+		_ = applyLocale(cfg)
+	}
+
+	journal, err := history.LoadJournal(config.StatePath(history.JournalFile))
+	if err != nil {
+		return err
+	}
+	printJournal(journal.Search(term, failed), len(journal.Records), limit)
+	return nil
+}
+
+// printJournal lists the quests found, newest first, at most limit of them when limit is positive
+func printJournal(records []*history.Record, total, limit int) {
+	if len(records) == 0 {
+		if total == 0 {
+			ui.PrintInfoMessage("No quest has been recorded yet, my lord. Every quest you ask for will be listed here.")
+		} else {
+			ui.PrintInfoMessage(fmt.Sprintf("None of the %d recorded quests match, my lord.", total))
+		}
+		return
+	}
+
+	shown := records
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	lines := []string{""}
+	for _, record := range shown {
+		lines = append(lines, fmt.Sprintf("%s %s %s %s", ui.Cyan.Sprintf("#%d", record.ID), ui.Gray.Sprint(ui.FormatTimestamp(record.At)), journalOutcome(record), record.Intent))
+		if record.Context != "" {
+			lines[len(lines)-1] += ui.Gray.Sprintf(" [%s]", record.Context)
+		}
+		if record.Undoes != 0 {
+			lines[len(lines)-1] += ui.Gray.Sprintf(" (undoes #%d)", record.Undoes)
+		}
+		if summary := journalContent(record); summary != "" {
+			lines = append(lines, "    "+summary)
+		}
+	}
+	lines = append(lines, "")
+	if len(shown) < len(records) {
+		lines = append(lines, ui.Gray.Sprintf("%d more; show them with --limit %d", len(records)-len(shown), len(records)), "")
+	}
+	lines = append(lines, ui.Gray.Sprint("Run one again with: execute-my-will redo <number>"), ui.Gray.Sprint("Reverse one with: execute-my-will undo <number>"), "")
+	ui.DefaultTemplate().PrintBox(fmt.Sprintf("📜 QUEST HISTORY (%d)", len(records)), lines)
+}
+
+// journalOutcome is a short tag for how a recorded quest ended
+func journalOutcome(record *history.Record) string {
+	switch {
+	case record.Failed() && record.ExitCode != nil:
+		return ui.Red.Sprintf("✗ exit %d", *record.ExitCode)
+	case record.Failed():
+		return ui.Red.Sprint("✗ failed")
+	case record.Executed:
+		return ui.Green.Sprint("✓ ran")
+	case record.Decision == history.DecisionDeclined:
+		return ui.Gold.Sprint("– declined")
+	case record.Refusal != "":
+		return ui.Gold.Sprint("– refused")
+	default:
+		return ui.Gray.Sprint("– not run")
+	}
+}
+
+// journalContent shows the proposed command, or the first line of a script with its length
+func journalContent(record *history.Record) string {
+	switch {
+	case record.Content == "" && record.Refusal != "":
+		return ui.Gray.Sprint(record.Refusal)
+	case record.Content == "":
+		return ""
+	case record.IsScript:
+		lines := strings.Split(strings.TrimSpace(record.Content), "\n")
+		first := lines[0]
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(strings.ToUpper(line), "REM ") {
+				first = line
+				break
+			}
+		}
+		return ui.CommandText(fmt.Sprintf("→ %s (script of %d lines)", first, len(lines)))
+	default:
+		return ui.CommandText("→ " + strings.ReplaceAll(record.Content, "\n", " "))
+	}
+}
+
+// recordInJournal writes a quest to the journal, whether or not it ran
+func recordInJournal(journal *history.Journal, q *Quest) {
+	if journal == nil {
+		return
+	}
+	if record := JournalRecord(q, time.Now()); record != nil {
+		journal.Add(record)
+		_ = journal.Save()
+	}
+}
+
+// JournalRecord describes a quest for the journal, with credentials redacted. It returns nil
+// for a quest without an intent.
+func JournalRecord(q *Quest, at time.Time) *history.Record {
+	if strings.TrimSpace(q.Intent) == "" {
+		return nil
+	}
+	var apiKey string
+	if q.Config != nil {
+		apiKey = q.Config.APIKey
+	}
+	redactor := system.NewRedactor("", apiKey)
+
+	record := &history.Record{
+		At:       at,
+		Intent:   redactor.Redact(q.Intent),
+		Context:  q.ContextName,
+		Content:  redactor.Redact(q.Content),
+		Redacted: redactor.Redact(q.Content) != q.Content,
+		IsScript: q.IsScript,
+		Executed: q.Executed,
+		Decision: history.DecisionNotAsked,
+	}
+	if q.SysInfo != nil {
+		record.Dir = q.SysInfo.CurrentDir
+	}
+	if q.Undo != nil {
+		record.Undoes = q.Undo.ID
+	}
+	if q.Content == "" && q.Response != nil && q.Response.Type == ai.ResponseTypeFailure {
+		record.Refusal = q.Response.Error
+	}
+
+	switch {
+	case q.Approved:
+		record.Decision = history.DecisionApproved
+	case q.Declined:
+		record.Decision = history.DecisionDeclined
+	}
+
+	if q.Executed {
+		code := 0
+		if q.ExecErr != nil {
+			record.Error = redactor.Redact(q.ExecErr.Error())
+			code = -1
+			var execErr *system.ExecutionError
+			if errors.As(q.ExecErr, &execErr) {
+				code = execErr.ExitCode
+			}
+		}
+		if code >= 0 {
+			record.ExitCode = &code
+		}
+	}
+	return record
+}
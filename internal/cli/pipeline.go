@@ -0,0 +1,549 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/pipeline.go
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/tui"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// Quest carries the state of a single request as it moves through the pipeline
+type Quest struct {
+	Intent   string
+	Config   *config.Config
+	SysInfo  *system.Info
+	Response *ai.AIResponse
+	Content  string
+	IsScript bool
+	Approved bool
+	Declined bool   // the user turned the proposal down at the confirmation
+	Elevate  bool   // run through the Windows UAC prompt
+	AsUser   string // run as this less-privileged user instead of the knight's own
+	Executed bool
+	ExecErr  error
+
+	// DefaultApproval makes an empty answer at the confirmation approve the quest
+	DefaultApproval bool
+
+	// OutputLog is the saved output of the last execution, when the executor keeps one
+	OutputLog *system.OutputLog
+	// Artifacts are the files and directories the last execution created
+	Artifacts []system.Artifact
+
+	// AutoFixLimit is the number of corrected attempts the oracle may propose after a failure
+	AutoFixLimit int
+	FixAttempts  int
+
+	// ExplainOnly explains the proposed quest in royal-heir style without ever offering to run it
+	ExplainOnly bool
+	// DryRun generates and checks the quest like any other, then prints it instead of running it
+	DryRun bool
+	// ToPrompt is the file the checked command is written to instead of being run, for the shell
+	// widget to put on the user's prompt line, where the user's own shell runs it
+	ToPrompt string
+	// Eval receives the approved command for the user's shell to evaluate (--eval), instead of it
+	// being run here
+	Eval io.Writer
+	// PersistentShell is set when the quest runs in the long-lived shell of 'execute-my-will
+	// shell', where a cd or an export lasts for the quests after it
+	PersistentShell bool
+
+	// ContextName and Context are set when the intent starts with a configured context prefix
+	ContextName string
+	Context     *config.IntentContext
+	// Clipboard is copied text sent with the intent as context (--from-clipboard), already redacted
+	Clipboard string
+	// Piped is text piped to stdin and sent with the intent as context, already redacted
+	Piped string
+
+	// Step places the quest within a plan when it is one sub-quest of a larger one
+	Step *PlanStep
+	// Prior is the quest whose output this one builds on, when it follows another with --then
+	Prior *PriorStep
+	// AirGapped refuses every proposal that would reach the network
+	AirGapped bool
+	// Debug prints how long each phase of the system analysis took
+	Debug bool
+
+	// Recalled is set when the content was reused from the history instead of generated
+	Recalled bool
+	// Replay is the journal record whose command or script 'redo' runs again
+	Replay *history.Record
+	// Undo is the journal record of the quest 'undo' reverses
+	Undo *history.Record
+	// Recipe names the vetted recipe the content was built from, if any
+	Recipe string
+	// Docs holds excerpts of local tool documentation sent with the intent
+	Docs string
+	// Duplicates holds the duplicate files found for a quest that does something with them
+	Duplicates *system.DuplicateReport
+	// Detached is set when the quest runs in a tmux or screen session instead of this terminal
+	Detached *system.DetachedSession
+	// Trust compares a generated script with the one approved for the same intent before
+	Trust *ScriptTrust
+	// RecentRun is the history entry of the same destructive command or script run moments ago
+	RecentRun *history.Entry
+	// CriticalDir is set when the proposal uses relative paths or globs in a directory such as /
+	// or C:\Windows, and must then be approved by typing that directory
+	CriticalDir *system.CriticalDirectoryUse
+	// Script is set by the 'script' subcommand, which always asks for a script and saves it
+	Script *ScriptRequest
+	// Covers are the project quests approved at once by the approval of a workspace quest
+	Covers []*Quest
+}
+
+// PromptIntent returns the intent as sent to the oracle, including any configured context,
+// the meaning of glossary terms the intent mentions, and the facts the user asked to remember
+func (q *Quest) PromptIntent() string {
+	prompt := q.Intent
+	if q.Context != nil && q.Context.Context != "" {
+		prompt = fmt.Sprintf("%s\n\nADDITIONAL CONTEXT (%s): %s", prompt, q.ContextName, q.Context.Context)
+	}
+
+	if q.Clipboard != "" {
+		prompt = fmt.Sprintf("%s\n\nCLIPBOARD (text the user copied, such as an error message or a log excerpt; where the intent says \"this\" or \"it\", it means this text):\n%s", prompt, q.Clipboard)
+	}
+
+	if q.Piped != "" {
+		prompt = fmt.Sprintf("%s\n\nPIPED INPUT (text piped to the knight, such as a log or a command's output, possibly only its end; where the intent says \"this\" or \"it\", it means this text):\n%s", prompt, q.Piped)
+	}
+
+	if q.Step != nil {
+		prompt = fmt.Sprintf("%s\n\n%s", prompt, q.Step.Describe())
+	}
+
+	if q.Prior != nil {
+		prompt = fmt.Sprintf("%s\n\n%s", prompt, q.Prior.Describe())
+	}
+
+	if q.AsUser != "" {
+		prompt = fmt.Sprintf("%s\n\nEXECUTION USER: the command will run as the user '%s', not as the user described below. Do not use sudo, and only use paths and tools that user can reach.", prompt, q.AsUser)
+	}
+
+	if q.AirGapped {
+		prompt = fmt.Sprintf("%s\n\nAIR-GAPPED: this machine has no network access. Use only local files, installed tools, and offline caches; never download, fetch, clone, update package lists, or contact another host. If the quest cannot be done offline, answer with a failure.", prompt)
+	}
+
+	if q.Docs != "" {
+		prompt = fmt.Sprintf("%s\n\n%s", prompt, q.Docs)
+	}
+
+	if q.Script != nil {
+		prompt = fmt.Sprintf("%s\n\nSCRIPT REQUESTED: answer with a SCRIPT even if a single command would do. It will be saved to a file and run again later, so make it self-contained, comment each step, and stop at the first error.", prompt)
+	}
+
+	if q.Duplicates != nil {
+		prompt = fmt.Sprintf("%s\n\nDUPLICATE FILES (found by comparing sizes and SHA-256 hashes under %s; each line is one group of identical files. Act on these exact paths instead of searching again, and keep the first path of each group unless the intent says otherwise):\n%s", prompt, q.Duplicates.Root, q.Duplicates.String())
+	}
+
+	if q.Config == nil {
+		return prompt
+	}
+	if terms := q.Config.GlossaryFor(q.Intent); len(terms) > 0 {
+		lines := []string{"GLOSSARY (the user's own names for things in their system; use these meanings):"}
+		for _, term := range terms {
+			lines = append(lines, fmt.Sprintf("- \"%s\" means %s", term.Term, term.Meaning))
+		}
+		prompt = fmt.Sprintf("%s\n\n%s", prompt, strings.Join(lines, "\n"))
+	}
+	if len(q.Config.Facts) > 0 {
+		lines := []string{"REMEMBERED FACTS (the user's standing preferences about their system; follow them unless the intent says otherwise):"}
+		for _, fact := range q.Config.Facts {
+			lines = append(lines, "- "+fact)
+		}
+		prompt = fmt.Sprintf("%s\n\n%s", prompt, strings.Join(lines, "\n"))
+	}
+	return prompt
+}
+
+// Explains reports whether the proposal should be explained, as in royal-heir mode
+func (q *Quest) Explains() bool {
+	return q.ExplainOnly || q.Config.Mode == "royal-heir"
+}
+
+// ProposalOnly reports whether the quest is only proposed and never run, so nothing should be
+// asked of the user on its way
+func (q *Quest) ProposalOnly() bool {
+	return q.ExplainOnly || q.DryRun
+}
+
+// ConfirmationToken returns the word the user must type to approve the quest,
+// or an empty string when a simple y/N answer is enough
+func (q *Quest) ConfirmationToken() string {
+	if q.CriticalDir != nil {
+		return q.CriticalDir.Dir
+	}
+	if q.Context == nil || !q.Context.TypedConfirmation {
+		return ""
+	}
+	return q.ContextName
+}
+
+// Stage is a single step of the quest pipeline.
+// Run returns false to stop the pipeline gracefully (the user has already been informed),
+// or an error when the quest cannot continue for unexpected reasons.
+type Stage interface {
+	Name() string
+	Run(q *Quest) (bool, error)
+}
+
+// Confirmer asks the user to approve a proposed quest before it is executed
+type Confirmer interface {
+	Confirm(q *Quest) (bool, error)
+}
+
+// Prompter asks the user to pick from options or type a value while a quest is being prepared
+type Prompter interface {
+	// Choose returns the index of the chosen option
+	Choose(question string, options []string) (int, error)
+	// ChooseMany returns the indexes of the chosen options in ascending order; none is a valid answer
+	ChooseMany(question string, options []string) ([]int, error)
+	// Ask returns the typed answer, or defaultValue when the answer is empty
+	Ask(question, defaultValue string) (string, error)
+}
+
+// PipelineDeps holds the collaborators used by the default pipeline stages
+type PipelineDeps struct {
+	Analyzer           system.SystemAnalyzer
+	NewIntentValidator func(sysInfo *system.Info) system.IntentValidator
+	NewEnvValidator    func(sysInfo *system.Info) system.EnvironmentValidatorInterface
+	AIClient           ai.Client
+	Executor           system.CommandExecutor
+	Confirmer          Confirmer
+	Prompter           Prompter
+	IsElevated         func() bool
+	NewProjectExecutor func(dir, label string, background bool) system.CommandExecutor
+	History            *history.Store                            // nil disables suggestions from earlier quests and script trust
+	Journal            *history.Journal                          // nil keeps no record of the quest for the 'history' subcommand
+	ListProcesses      func() ([]system.ProcessInfo, error)      // nil keeps running processes out of the prompt
+	FindMultiplexer    func() string                             // nil never offers to detach long quests
+	LoadDocsIndex      func() (*system.DocsIndex, error)         // nil never sends local tool documentation
+	CheckFlags         func(content string) []system.UnknownFlag // nil never checks flags against --help
+	Reauthenticate     func(reason string) error                 // nil never asks the system to confirm who is at the terminal
+}
+
+// DefaultPipelineDeps returns the production collaborators for the given AI client
+func DefaultPipelineDeps(aiClient ai.Client) PipelineDeps {
+	console := newStdinConsole(os.Stdin)
+
+	// An unreadable history only means there is nothing to suggest
+	quests, err := history.Load(config.StatePath(history.HistoryFile))
+	if err != nil {
+		quests = nil
+	}
+	// An unreadable journal is left alone rather than started afresh over the quests it holds
+	journal, err := history.LoadJournal(config.StatePath(history.JournalFile))
+	if err != nil {
+		journal = nil
+	}
+
+	return PipelineDeps{
+		Analyzer:           system.NewAnalyzer(),
+		NewIntentValidator: system.NewValidator,
+		NewEnvValidator:    system.NewEnvironmentValidator,
+		AIClient:           aiClient,
+		Executor:           system.NewExecutor(),
+		Confirmer:          console,
+		Prompter:           console,
+		IsElevated:         system.IsElevated,
+		NewProjectExecutor: system.NewProjectExecutor,
+		History:            quests,
+		Journal:            journal,
+		ListProcesses:      system.ListProcesses,
+		FindMultiplexer:    system.FindMultiplexer,
+		LoadDocsIndex:      sync.OnceValues(func() (*system.DocsIndex, error) { return system.LoadDocsIndex(config.StatePath(system.DocsIndexFile)) }),
+		CheckFlags:         newFlagChecker(),
+		Reauthenticate:     system.Reauthenticate,
+	}
+}
+
+// newFlagChecker checks flags against the help cache in the state directory, loaded on first use
+func newFlagChecker() func(content string) []system.UnknownFlag {
+	cache := sync.OnceValue(func() *system.HelpCache {
+		homeDir, _ := os.UserHomeDir()
+		currentDir, _ := os.Getwd()
+		return system.LoadHelpCache(config.StatePath(system.HelpCacheFile), homeDir, currentDir)
+	})
+	return func(content string) []system.UnknownFlag {
+		unknown := system.CheckFlags(content, cache().Help)
+		// The cache only saves running --help again
+		_ = cache().Save()
+		return unknown
+	}
+}
+
+// Pipeline runs the quest stages in order:
+// calculate → analyze → question → validate → processes → duplicates → transfer → cleanup → recipe → recall → docs → generate → airgap → flags → verify → review → trust → confirm → reauth → elevate → detach → execute → report → summarize → autofix
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline builds the standard quest pipeline from its collaborators
+func NewPipeline(deps PipelineDeps) *Pipeline {
+	stages := []Stage{
+		&calculateStage{now: time.Now},
+		&analyzeStage{analyzer: deps.Analyzer},
+		&questionStage{client: deps.AIClient},
+		&validateStage{newValidator: deps.NewIntentValidator, prompter: deps.Prompter},
+		&processesStage{list: deps.ListProcesses},
+		&duplicatesStage{prompter: deps.Prompter},
+		&transferStage{prompter: deps.Prompter},
+		&cleanupStage{client: deps.AIClient, prompter: deps.Prompter},
+		&recipeStage{prompter: deps.Prompter},
+		&recallStage{history: deps.History, prompter: deps.Prompter},
+	}
+	return &Pipeline{stages: append(stages, questStages(deps)...)}
+}
+
+// questStages generate, confirm, and carry out a quest on an analyzed system:
+// docs → generate → airgap → flags → verify → review → trust → repeat → confirm → reauth → eval → elevate → detach → execute → report → summarize → autofix
+func questStages(deps PipelineDeps) []Stage {
+	return []Stage{
+		&docsStage{load: deps.LoadDocsIndex},
+		&generateStage{client: deps.AIClient},
+		&airGapStage{},
+		&flagsStage{client: deps.AIClient, check: deps.CheckFlags},
+		&verifyDownloadsStage{client: deps.AIClient},
+		&reviewStage{client: deps.AIClient, newEnvValidator: deps.NewEnvValidator, prompter: deps.Prompter},
+		&trustStage{history: deps.History},
+		&repeatStage{history: deps.History, now: time.Now},
+		&confirmStage{confirmer: deps.Confirmer},
+		&reauthStage{reauthenticate: deps.Reauthenticate},
+		&evalStage{},
+		&elevateStage{prompter: deps.Prompter, isElevated: deps.IsElevated},
+		&detachStage{prompter: deps.Prompter, findMultiplexer: deps.FindMultiplexer, now: time.Now},
+		&executeStage{executor: deps.Executor},
+		&reportStage{},
+		&summarizeStage{client: deps.AIClient, prompter: deps.Prompter},
+		&autoFixStage{
+			client: deps.AIClient,
+			retry: []Stage{
+				&airGapStage{},
+				&flagsStage{client: deps.AIClient, check: deps.CheckFlags},
+				&verifyDownloadsStage{client: deps.AIClient},
+				&reviewStage{client: deps.AIClient, newEnvValidator: deps.NewEnvValidator, prompter: deps.Prompter},
+				&repeatStage{history: deps.History, now: time.Now},
+				&confirmStage{confirmer: deps.Confirmer},
+				&reauthStage{reauthenticate: deps.Reauthenticate},
+				&elevateStage{prompter: deps.Prompter, isElevated: deps.IsElevated},
+				&detachStage{prompter: deps.Prompter, findMultiplexer: deps.FindMultiplexer, now: time.Now},
+				&executeStage{executor: deps.Executor},
+				&reportStage{},
+				&summarizeStage{client: deps.AIClient, prompter: deps.Prompter},
+			},
+		},
+	}
+}
+
+// NewPipelineWithStages builds a pipeline from an explicit list of stages
+func NewPipelineWithStages(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Stages returns the stage names in execution order
+func (p *Pipeline) Stages() []string {
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.Name())
+	}
+	return names
+}
+
+// Run executes each stage in order until one stops the quest or fails
+func (p *Pipeline) Run(q *Quest) error {
+	_, err := runStages(q, p.stages...)
+	return err
+}
+
+// runStages runs the stages in order and reports whether every one of them let the quest proceed
+func runStages(q *Quest, stages ...Stage) (bool, error) {
+	for _, stage := range stages {
+		proceed, err := stage.Run(q)
+		if err != nil || !proceed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// haltedByKillSwitch tells the user when an administrator's kill-switch file forbids quests
+func haltedByKillSwitch(cfg *config.Config) bool {
+	var configured string
+	if cfg != nil {
+		configured = cfg.Execution.KillSwitchPath()
+	}
+	killSwitch := system.CheckKillSwitch(configured)
+	if killSwitch == nil {
+		return false
+	}
+
+	message := "An administrator has disabled the quests of every knight on this machine, my lord. No command will be generated or executed."
+	if killSwitch.Message != "" {
+		message += "\n\n" + killSwitch.Message
+	}
+	message += fmt.Sprintf("\n\nKill switch: %s", killSwitch.Path)
+	ui.PrintStatusBox("🛑 QUESTS DISABLED", message, "error")
+	return true
+}
+
+// stdinConsole reads the royal decree and wizard answers from a reader, one line at a time.
+// A single console serves both roles so that buffered input is never split between two readers.
+type stdinConsole struct {
+	reader   *bufio.Reader
+	terminal bool // the reader is a terminal, where a confirmation can be chosen with the arrow keys
+}
+
+func newStdinConsole(r io.Reader) *stdinConsole {
+	console := &stdinConsole{reader: bufio.NewReader(r)}
+	if f, ok := r.(*os.File); ok {
+		console.terminal = isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return console
+}
+
+// NewStdinConfirmer creates a Confirmer that reads y/N answers from the given reader
+func NewStdinConfirmer(r io.Reader) Confirmer {
+	return newStdinConsole(r)
+}
+
+// NewStdinPrompter creates a Prompter that reads answers from the given reader
+func NewStdinPrompter(r io.Reader) Prompter {
+	return newStdinConsole(r)
+}
+
+func (c *stdinConsole) Choose(question string, options []string) (int, error) {
+	ui.PrintLine("❓", question)
+	for i, option := range options {
+		ui.PrintPlain(fmt.Sprintf("   %d. %s", i+1, option))
+	}
+	ui.PrintPrompt("👉", fmt.Sprintf("Your choice (1-%d):", len(options)))
+
+	answer, err := c.reader.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("failed to read your royal decree: %w", err)
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(options) {
+		return 0, fmt.Errorf("'%s' is not one of the choices, my lord", strings.TrimSpace(answer))
+	}
+	return choice - 1, nil
+}
+
+func (c *stdinConsole) ChooseMany(question string, options []string) ([]int, error) {
+	ui.PrintLine("❓", question)
+	for i, option := range options {
+		ui.PrintPlain(fmt.Sprintf("   %d. %s", i+1, option))
+	}
+	ui.PrintPrompt("👉", "Your choices (e.g. 1,3,5-7 or 'all'; empty for none):")
+
+	answer, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read your royal decree: %w", err)
+	}
+	return parseSelection(answer, len(options))
+}
+
+// parseSelection reads a list of 1-based choices and ranges such as "1,3 5-7" or "all"
+func parseSelection(answer string, count int) ([]int, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" || answer == "none" {
+		return nil, nil
+	}
+
+	chosen := make([]bool, count)
+	if answer == "all" {
+		for i := range chosen {
+			chosen[i] = true
+		}
+	}
+	for _, item := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item == "all" {
+			continue
+		}
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		from, errFrom := strconv.Atoi(first)
+		to, errTo := strconv.Atoi(last)
+		if errFrom != nil || errTo != nil || from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("'%s' is not one of the choices, my lord", item)
+		}
+		for i := from; i <= to; i++ {
+			chosen[i-1] = true
+		}
+	}
+
+	var indexes []int
+	for i, ok := range chosen {
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+func (c *stdinConsole) Ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		question = fmt.Sprintf("%s [%s]", question, defaultValue)
+	}
+	ui.PrintPrompt("✍️ ", question+":")
+
+	answer, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read your royal decree: %w", err)
+	}
+
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+func (c *stdinConsole) Confirm(q *Quest) (bool, error) {
+	if q.ConfirmationToken() == "" && q.Config != nil && q.Config.UI.SelectConfirmation() && c.terminal {
+		initial := 1
+		if q.DefaultApproval {
+			initial = 0
+		}
+		ui.PrintBlankLine()
+		choice, err := tui.Select([]string{"Proceed", "Do not proceed"}, initial)
+		if err != nil {
+			return false, fmt.Errorf("failed to read your royal decree: %w", err)
+		}
+		return choice == 0, nil
+	}
+
+	userResponse, err := c.reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read your royal decree: %w", err)
+	}
+
+	if token := q.ConfirmationToken(); token != "" {
+		return strings.TrimSpace(userResponse) == token, nil
+	}
+
+	if strings.TrimSpace(userResponse) == "" {
+		return q.DefaultApproval, nil
+	}
+	return ui.IsAffirmative(userResponse), nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/plan.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// PlanStep places a sub-quest within the plan made for a larger quest
+type PlanStep struct {
+	Goal   string   // the intent the plan was made for
+	Number int      // 1-based
+	Steps  []string // every sub-quest of the plan, in order
+}
+
+// Describe tells the oracle where the sub-quest stands in the plan, so that it neither repeats
+// earlier steps nor does the work of later ones
+func (s *PlanStep) Describe() string {
+	lines := []string{fmt.Sprintf("PLAN (this intent is step %d of %d of the larger quest \"%s\"; carry out only this step):", s.Number, len(s.Steps), s.Goal)}
+	for i, step := range s.Steps {
+		var mark string
+		switch {
+		case i+1 < s.Number:
+			mark = " (done before this step)"
+		case i+1 == s.Number:
+			mark = " (this step)"
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s%s", i+1, step, mark))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// StepStatus is how one sub-quest of a plan ended
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "not started"
+	StepSkipped   StepStatus = "skipped"
+	StepDeclined  StepStatus = "declined"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+)
+
+// StepResult is the outcome of one sub-quest of a plan
+type StepResult struct {
+	Intent string
+	Quest  *Quest // nil while the step has not been started
+	Status StepStatus
+	Note   string
+}
+
+// PlanRun carries out an intent too large for one script: the oracle breaks it into a numbered
+// plan of sub-quests, and each sub-quest is generated, confirmed, and executed in turn
+type PlanRun struct {
+	Deps PipelineDeps
+}
+
+// Run plans the quest, then works through the plan with the user's approval, printing the
+// progress after every step
+func (p *PlanRun) Run(base *Quest) ([]*StepResult, error) {
+	proceed, err := runStages(base,
+		&analyzeStage{analyzer: p.Deps.Analyzer},
+		&validateStage{newValidator: p.Deps.NewIntentValidator, prompter: p.Deps.Prompter},
+	)
+	if err != nil || !proceed {
+		return nil, err
+	}
+
+	ui.PrintPhaseHeader("🗺️ ", "Drawing up a plan for this grand quest...")
+	steps, err := p.Deps.AIClient.PlanQuest(base.PromptIntent(), base.SysInfo)
+	if err != nil {
+		ui.PrintStatusBox("❌ NO PLAN", fmt.Sprintf("Alas, the oracles could not plan this quest: %v", err), "error")
+		return nil, nil
+	}
+
+	results := make([]*StepResult, 0, len(steps))
+	for _, step := range steps {
+		results = append(results, &StepResult{Intent: step, Status: StepPending})
+	}
+	printSteps("🗺️  QUEST PLAN", results)
+
+	choice, err := p.Deps.Prompter.Choose("Shall we follow this plan?", []string{"Follow the plan, confirming each step", "Abandon the quest"})
+	if err != nil {
+		return results, err
+	}
+	if choice != 0 {
+		ui.PrintStatusBox("🙏 PLAN ABANDONED", "As you wish, my lord. Nothing was carried out.", "info")
+		return results, nil
+	}
+
+	for i, result := range results {
+		ui.PrintPhaseHeader("🧭", fmt.Sprintf("Step %d of %d: %s", i+1, len(results), result.Intent))
+
+		q := &Quest{
+			Intent:       result.Intent,
+			Config:       base.Config,
+			SysInfo:      base.SysInfo,
+			AsUser:       base.AsUser,
+			AutoFixLimit: base.AutoFixLimit,
+			AirGapped:    base.AirGapped,
+			ContextName:  base.ContextName,
+			Context:      base.Context,
+			Clipboard:    base.Clipboard,
+			Piped:        base.Piped,
+			Step:         &PlanStep{Goal: base.Intent, Number: i + 1, Steps: steps},
+		}
+		result.Quest = q
+
+		_, err := runStages(q, questStages(p.Deps)...)
+		result.Status, result.Note = stepOutcome(q, err)
+		ui.PrintInfoMessage(fmt.Sprintf("Plan progress: %d of %d steps done.", countSucceeded(results), len(results)))
+
+		if result.Status == StepSucceeded || i == len(results)-1 {
+			continue
+		}
+		choice, err := p.Deps.Prompter.Choose(fmt.Sprintf("Step %d %s. How shall we proceed?", i+1, result.Status), []string{
+			fmt.Sprintf("Continue with step %d", i+2),
+			"Stop the plan here",
+		})
+		if err != nil {
+			return results, err
+		}
+		if choice != 0 {
+			break
+		}
+	}
+
+	printSteps("🗺️  QUEST PLAN", results)
+	return results, nil
+}
+
+// stepOutcome works out how a sub-quest ended from the state the stages left it in
+func stepOutcome(q *Quest, err error) (StepStatus, string) {
+	switch {
+	case err != nil:
+		return StepFailed, err.Error()
+	case q.Executed && q.ExecErr != nil:
+		return StepFailed, q.ExecErr.Error()
+	case q.Executed:
+		return StepSucceeded, ""
+	case q.Content == "":
+		return StepSkipped, "no runnable quest was proposed"
+	case !q.Approved:
+		return StepDeclined, ""
+	default:
+		return StepSkipped, "stopped before execution"
+	}
+}
+
+func countSucceeded(results []*StepResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == StepSucceeded {
+			count++
+		}
+	}
+	return count
+}
+
+// printSteps shows a plan or chain of quests with the status of every step
+func printSteps(title string, results []*StepResult) {
+	lines := []string{""}
+	for i, result := range results {
+		var mark string
+		switch result.Status {
+		case StepSucceeded:
+			mark = ui.Green.Sprint("✔")
+		case StepFailed:
+			mark = ui.Red.Sprint("✘")
+		case StepPending:
+			mark = ui.Gray.Sprint("○")
+		default:
+			mark = ui.Gray.Sprint("–")
+		}
+
+		line := fmt.Sprintf("%s %d. %s", mark, i+1, result.Intent)
+		if result.Status != StepPending {
+			line += ui.Gray.Sprint(" — " + string(result.Status))
+		}
+		if result.Note != "" {
+			line += ui.Gray.Sprint(": " + result.Note)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+
+	ui.DefaultTemplate().PrintBox(fmt.Sprintf("%s (%d of %d done)", title, countSucceeded(results), len(results)), lines)
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/postmortem.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// postmortemHeadLines, postmortemTailLines, and postmortemNotableLines bound the saved output
+	// quoted in a note; the tail usually holds the error
+	postmortemHeadLines    = 10
+	postmortemTailLines    = 40
+	postmortemNotableLines = 20
+)
+
+var postmortemCmd = &cobra.Command{
+	Use:   "postmortem",
+	Short: "Write a blameless incident note about the last failed or destructive quest",
+	Long: `Compile the last quest's history entry, its error output, and a diagnosis from the oracle into a short markdown incident note.
+
+Notes are written to the 'postmortem.dir' directory of the configuration (by default ~/.config/execute-my-will/postmortems), or to --dir. They only describe what ran and why it went wrong, never who is at fault. Secrets and your home directory are redacted.`,
+	Args: cobra.NoArgs,
+	RunE: runPostmortem,
+}
+
+func init() {
+	postmortemCmd.Flags().String("dir", "", "Write the note to this directory instead of the configured one")
+	postmortemCmd.Flags().Bool("no-ai", false, "Leave out the oracle's diagnosis; nothing is sent to the AI provider")
+	postmortemCmd.Flags().Bool("force", false, "Write a note even when the last quest succeeded without destructive commands")
+	rootCmd.AddCommand(postmortemCmd)
+}
+
+func runPostmortem(cmd *cobra.Command, args []string) error {
+	transcript, err := LoadTranscript(config.StatePath(TranscriptFile))
+	if err != nil {
+		return err
+	}
+	if transcript == nil {
+		ui.PrintStatusBox("📜 NO QUEST RECORDED", "There is no quest to write about yet, my lord.", "info")
+		return nil
+	}
+
+	risk := contentRisk(transcript.Content, transcript.IsScript)
+	force, _ := cmd.Flags().GetBool("force")
+	if !force && !NeedsPostmortem(transcript, risk) {
+		ui.PrintStatusBox("🕊️  NOTHING TO REPORT", fmt.Sprintf("Your last quest (\"%s\") %s without destructive commands, my lord.\n\nUse --force to write a note anyway.", transcript.Intent, transcript.Outcome()), "info")
+		return nil
+	}
+
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	home, _ := os.UserHomeDir()
+	redactor := system.NewRedactor(home, cfg.APIKey)
+
+	// An unreadable history only means the note has no run counts
+	var entry *history.Entry
+	if store, err := history.Load(config.StatePath(history.HistoryFile)); err == nil {
+		entry = store.Find(transcript.Intent, transcript.Context, transcript.Content)
+	}
+
+	output := transcript.ErrorOutput
+	if entry != nil && entry.LogFile != "" {
+		if sample, err := system.SampleOutputLog(entry.LogFile, postmortemHeadLines, postmortemTailLines, postmortemNotableLines); err == nil {
+			output = redactor.Redact(sample)
+		}
+	}
+
+	var diagnosis string
+	if noAI, _ := cmd.Flags().GetBool("no-ai"); !noAI {
+		diagnosis = diagnoseIncident(cfg, transcript, risk, output)
+	}
+
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		dir = cfg.Postmortem.NotesDir()
+	}
+	note := NewIncidentNote(transcript, entry, risk, output, diagnosis)
+	path, err := note.Save(dir)
+	if err != nil {
+		return fmt.Errorf("failed to write the incident note, my lord: %w", err)
+	}
+
+	ui.PrintStatusBox("📝 INCIDENT NOTE WRITTEN", fmt.Sprintf("The note was written to %s\n\nSecrets and your home directory were redacted, but please read it before sharing it.", path), "success")
+	return nil
+}
+
+// diagnoseIncident asks the oracle for a diagnosis. A note without one is still worth writing.
+func diagnoseIncident(cfg *config.Config, t *Transcript, risk system.RiskLevel, output string) string {
+	client, err := ai.NewClient(cfg)
+	if err == nil {
+		ui.PrintInfoMessage("Consulting the oracle about what went wrong...")
+		var diagnosis string
+		diagnosis, err = client.DiagnoseIncident(ai.Incident{
+			Intent:  t.Intent,
+			Content: t.Content,
+			Outcome: t.Outcome(),
+			Risk:    risk.String(),
+			Error:   t.Error,
+			Output:  output,
+		})
+		if err == nil {
+			return diagnosis
+		}
+	}
+	ui.PrintWarningMessage(fmt.Sprintf("The oracle could not diagnose the quest: %v. The note is written without a diagnosis.", err))
+	return ""
+}
+
+// NeedsPostmortem reports whether a quest is worth an incident note: it failed, or it ran
+// destructive commands
+func NeedsPostmortem(t *Transcript, risk system.RiskLevel) bool {
+	switch t.Outcome() {
+	case "failed", "stopped by an error":
+		return true
+	}
+	return t.Executed && risk == system.RiskDestructive
+}
+
+// contentRisk is the highest risk of any step of a command chain or script
+func contentRisk(content string, isScript bool) system.RiskLevel {
+	lines := []string{content}
+	if isScript {
+		lines = strings.Split(content, "\n")
+	}
+
+	risk := system.RiskReadOnly
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToUpper(line), "REM ") {
+			continue
+		}
+		for _, part := range system.SplitCommandChain(line) {
+			if r := system.AssessCommandRisk(part.Command); r > risk {
+				risk = r
+			}
+		}
+	}
+	return risk
+}
+
+// IncidentNote is a blameless markdown note about one quest
+type IncidentNote struct {
+	Transcript *Transcript
+	Entry      *history.Entry // nil when the quest is not in the history
+	Risk       system.RiskLevel
+	Output     string // the error output or a sample of the saved log, redacted
+	Diagnosis  string // the oracle's diagnosis, empty when none was asked for
+}
+
+// NewIncidentNote gathers what is known about a quest into a note
+func NewIncidentNote(t *Transcript, entry *history.Entry, risk system.RiskLevel, output, diagnosis string) *IncidentNote {
+	return &IncidentNote{Transcript: t, Entry: entry, Risk: risk, Output: output, Diagnosis: diagnosis}
+}
+
+// nonSlugText is replaced by dashes in note file names
+var nonSlugText = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FileName names the note after the quest's date and intent, e.g. 2025-10-17-093005-clean-old-logs.md
+func (n *IncidentNote) FileName() string {
+	slug := strings.Trim(nonSlugText.ReplaceAllString(strings.ToLower(n.Transcript.Intent), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if slug == "" {
+		slug = "quest"
+	}
+	return n.Transcript.Time.Format("2006-01-02-150405") + "-" + slug + ".md"
+}
+
+// Markdown renders the note
+func (n *IncidentNote) Markdown() string {
+	t := n.Transcript
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Incident note: %s\n\n", strings.ReplaceAll(t.Intent, "\n", " "))
+	b.WriteString("_A blameless record of what ran, what happened, and what could prevent a repeat._\n\n")
+
+	b.WriteString("| | |\n|---|---|\n")
+	rows := [][2]string{
+		{"Date", t.Time.Format(time.RFC3339)},
+		{"Outcome", t.Outcome()},
+		{"Risk", n.Risk.String()},
+		{"System", strings.TrimSpace(t.System.OS + " / " + t.System.Shell)},
+		{"Tool", fmt.Sprintf("execute-my-will %s (%s, %s, %s mode)", t.AppVersion, t.Provider, t.Model, t.Mode)},
+	}
+	if t.Context != "" {
+		rows = append(rows, [2]string{"Context", t.Context})
+	}
+	if t.Executed {
+		rows = append(rows, [2]string{"Exit code", fmt.Sprintf("%d", t.ExitCode)})
+	}
+	if t.FixAttempts > 0 {
+		rows = append(rows, [2]string{"Auto-fix attempts", fmt.Sprintf("%d", t.FixAttempts)})
+	}
+	if e := n.Entry; e != nil {
+		rows = append(rows,
+			[2]string{"Runs of this command", fmt.Sprintf("%d (%d failed)", e.Runs, e.Failures)},
+			[2]string{"First run", e.FirstRun.Format(time.RFC3339)},
+		)
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], strings.ReplaceAll(row[1], "|", `\|`))
+	}
+
+	if t.IsScript {
+		writeSection(&b, "What ran (script)", t.Content)
+	} else {
+		writeSection(&b, "What ran", t.Content)
+	}
+	writeSection(&b, "Error", firstNonEmpty(t.Error, t.PipelineError))
+	writeSection(&b, "Output", n.Output)
+
+	if diagnosis := strings.TrimSpace(n.Diagnosis); diagnosis != "" {
+		fmt.Fprintf(&b, "\n## Diagnosis\n\n%s\n", diagnosis)
+		b.WriteString("\n_The diagnosis was suggested by the AI provider; check it before acting on it._\n")
+	} else {
+		b.WriteString("\n## Follow-ups\n\n- [ ] \n")
+	}
+	return b.String()
+}
+
+// Save writes the note to dir, creating it if needed, and returns the note's path
+func (n *IncidentNote) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, n.FileName())
+	return path, os.WriteFile(path, []byte(n.Markdown()), 0644)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/realm.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// promptListLimit mirrors the number of packages/commands included in AI prompts
+const promptListLimit = 100
+
+var realmCmd = &cobra.Command{
+	Use:   "realm",
+	Short: "Show everything your knight knows about this system",
+	Long:  "Print a report of what the system analyzer detected (OS, shell, package managers, packages, commands, PATH) so you can see exactly what context is sent to the AI.",
+	Args:  cobra.NoArgs,
+	RunE:  runRealm,
+}
+
+func init() {
+	realmCmd.Flags().Bool("full", false, "List every detected package and command instead of counts")
+	rootCmd.AddCommand(realmCmd)
+}
+
+func runRealm(cmd *cobra.Command, args []string) error {
+	full, _ := cmd.Flags().GetBool("full")
+
+	ui.PrintPhaseHeader("🗺️", "Surveying the realm...")
+
+	// Reflect the user's scan and privacy choices when a configuration exists
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = nil
+	}
+	var privacy config.PrivacyConfig
+	if cfg != nil {
+		privacy = cfg.Privacy
+	}
+
+	sysInfo, err := RealmAnalyzer(cfg).AnalyzeSystem()
+	if err != nil {
+		if sysInfo == nil {
+			return fmt.Errorf("failed to analyze the realm's systems, my lord: %w", err)
+		}
+		ui.PrintWarningMessage(err.Error())
+	}
+
+	printRealmReport(sysInfo, privacy, full)
+	return nil
+}
+
+// RealmAnalyzer is the analyzer behind the realm report: the one quests use under the
+// configuration, so that the report shows what is actually sent, or the full scan without one
+func RealmAnalyzer(cfg *config.Config) system.SystemAnalyzer {
+	if cfg == nil {
+		return system.NewAnalyzer()
+	}
+	return newAnalyzer(cfg)
+}
+
+// printRealmReport renders the analyzer findings as a set of boxes
+func printRealmReport(sysInfo *system.Info, privacy config.PrivacyConfig, full bool) {
+	template := ui.DefaultTemplate()
+
+	packages := CountSummary(len(sysInfo.InstalledPackages))
+	commands := CountSummary(len(sysInfo.AvailableCommands))
+	if sysInfo.CommandsTruncated {
+		commands += ", PATH scan cut short"
+	}
+	if sysInfo.Quick {
+		packages, commands = notScanned, notScanned
+	}
+	template.PrintBox("🏰 THE REALM", []string{
+		"",
+		realmLine("Operating System", ui.Cyan.Sprint(sysInfo.OS)),
+		realmLine("Shell", ui.Cyan.Sprint(sysInfo.Shell)),
+		realmLine("Package Managers", ui.Cyan.Sprint(strings.Join(sysInfo.PackageManagers, ", "))),
+		realmLine("Home Directory", withheldMark(privacy.AllowHomeDir(), sysInfo.HomeDir)),
+		realmLine("Current Directory", withheldMark(privacy.AllowCurrentDir(), sysInfo.CurrentDir)),
+		realmLine("Installed Packages", withheldMark(privacy.AllowInstalledPackages(), packages)),
+		realmLine("Available Commands", withheldMark(privacy.AllowAvailableCommands(), commands)),
+		realmLine("SSH Hosts", withheldMark(privacy.AllowSSHHosts(), fmt.Sprintf("%d", len(sysInfo.SSHHosts)))),
+		realmLine("Project Type", ui.Cyan.Sprint(projectSummary(sysInfo.ProjectTypes))),
+		realmLine("Nix", ui.Cyan.Sprint(sysInfo.Nix.String())),
+		realmLine("direnv", ui.Cyan.Sprint(sysInfo.Direnv.Describe(privacy.AllowEnvrcVariables()))),
+		realmLine("Immutable System", ui.Cyan.Sprint(sysInfo.Immutable.String())),
+		realmLine("CPUs and Memory", ui.Cyan.Sprint(sysInfo.Resources.String())),
+		realmLine("Root Access", ui.Cyan.Sprint(sysInfo.Privileges.String())),
+		realmLine("PATH Entries", fmt.Sprintf("%d", len(sysInfo.PathDirectories))),
+		"",
+	})
+
+	pathLines := []string{""}
+	for i, dir := range sysInfo.PathDirectories {
+		pathLines = append(pathLines, fmt.Sprintf("%2d. %s", i+1, dir))
+	}
+	pathLines = append(pathLines, "")
+	template.PrintBox("🛤️  PATH", pathLines)
+
+	if full {
+		template.PrintBox("📦 INSTALLED PACKAGES", ListLines(sysInfo.InstalledPackages))
+		template.PrintBox("⚔️  AVAILABLE COMMANDS", ListLines(sysInfo.AvailableCommands))
+		template.PrintBox("🔑 SSH HOSTS", ListLines(sysInfo.SSHHosts))
+	}
+
+	message := fmt.Sprintf("The OS, shell, package managers, home and current directory, SSH host aliases (names only), the project type, and the CPUs and memory available (with any container limits) are sent with every quest, along with up to %d installed packages and %d available commands. Quests about running programs also share the matching processes (name, PID, CPU, and memory). The .envrc that direnv applies here is named, but the variables it exports only once you allow it with 'execute-my-will configure --disclose envrc-variables', and never their values.\n\nRun 'execute-my-will realm --full' to list them all.", promptListLimit, promptListLimit)
+	if sysInfo.Quick {
+		message = "Only the OS, shell, and current directory are sent with every quest, since analysis.system_scan is off: installed packages, available commands, SSH hosts, and project files are not looked at. Quests about running programs still share the matching processes (name, PID, CPU, and memory).\n\nSet analysis.system_scan to true, or run 'execute-my-will configure --system-scan', for a better informed oracle."
+	}
+	if withheld := privacy.Withheld(); len(withheld) > 0 {
+		message += fmt.Sprintf("\n\n🔒 Withheld by your privacy settings: %s", strings.Join(withheld, ", "))
+	}
+	ui.PrintStatusBox("🧙 WHAT THE ORACLE SEES", message, "info")
+}
+
+// notScanned stands for the lists the quick analysis leaves out
+const notScanned = "not scanned (analysis.system_scan is off)"
+
+// withheldMark annotates values that are kept from the oracle
+func withheldMark(allowed bool, value string) string {
+	if allowed {
+		return value
+	}
+	return value + ui.Gray.Sprint(" (withheld)")
+}
+
+func realmLine(label, value string) string {
+	return fmt.Sprintf("%-19s : %s", label, value)
+}
+
+// CountSummary reports a count and how much of it reaches the prompt
+func CountSummary(count int) string {
+	if count > promptListLimit {
+		return fmt.Sprintf("%d (first %d shared)", count, promptListLimit)
+	}
+	return fmt.Sprintf("%d", count)
+}
+
+// projectSummary names the build tools detected in the current directory
+func projectSummary(types []system.ProjectType) string {
+	if len(types) == 0 {
+		return "none detected"
+	}
+	tools := make([]string, 0, len(types))
+	for _, t := range types {
+		tools = append(tools, fmt.Sprintf("%s (%s)", t.Tool, t.Marker))
+	}
+	return strings.Join(tools, ", ")
+}
+
+// ListLines lists items in the order the analyzer found them, which is the order the prompt
+// takes its first promptListLimit from; the items past those are marked as not shared
+func ListLines(items []string) []string {
+	lines := []string{""}
+	if len(items) == 0 {
+		lines = append(lines, ui.Gray.Sprint("none"))
+	}
+	for i, item := range items {
+		line := "• " + item
+		if i >= promptListLimit {
+			line += ui.Gray.Sprint(" (not shared)")
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, "")
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/recipes.go
+package cli
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var recipesCmd = &cobra.Command{
+	Use:   "recipes",
+	Short: "List the vetted recipes your knight uses instead of the oracle",
+	Long:  "List the built-in recipes: reviewed, parameterized commands for everyday tasks. When an intent clearly asks for one of them, the recipe's command is proposed instead of a generated one, so the result is the same every time.",
+	Args:  cobra.NoArgs,
+	RunE:  runRecipes,
+}
+
+func init() {
+	rootCmd.AddCommand(recipesCmd)
+}
+
+func runRecipes(cmd *cobra.Command, args []string) error {
+	lines := []string{""}
+	for _, recipe := range system.Recipes {
+		lines = append(lines,
+			ui.Cyan.Sprint(recipe.Name),
+			"  "+recipe.Description,
+			ui.Gray.Sprintf("  e.g. \"%s\"", recipe.Example),
+			ui.Gray.Sprintf("  shells: %s", strings.Join(recipeShells(recipe), ", ")),
+			"",
+		)
+	}
+	ui.DefaultTemplate().PrintBox("📜 VETTED RECIPES", lines)
+	ui.PrintInfoMessage("Intents that ask for more than a recipe does are still sent to the oracle.")
+	return nil
+}
+
+// recipeShells lists the shell families a recipe has a command for
+func recipeShells(recipe system.Recipe) []string {
+	var shells []string
+	for _, variant := range recipe.Variants {
+		if !slices.Contains(shells, variant.Family) {
+			shells = append(shells, variant.Family)
+		}
+	}
+	return shells
+}
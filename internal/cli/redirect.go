@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/redirect.go
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// subcommandRedirects map intents a built-in subcommand serves to that subcommand. Each pattern
+// matches the whole intent, so "show my nginx configuration" still goes to the oracle.
+var subcommandRedirects = []struct {
+	pattern *regexp.Regexp
+	args    []string
+}{
+	{
+		pattern: regexp.MustCompile(`^((show|display|print|view|see|open)( me)? (my|your|the knight'?s|execute-my-will'?s?) (current )?(config|configuration|settings)|what (is|are) (my|your) (current )?(config|configuration|settings))$`),
+		args:    []string{"configure", "show"},
+	},
+	{
+		pattern: regexp.MustCompile(`^((show|display|list|view|see|open)( me)? (my|your) (quest |command |recent |past )*(history|journal)|(show|list)( me)? (my|your) (recent |past |previous |last )*quests|what (quests|commands) (have|did) you (run|carry out|carried out|execute|executed)( recently)?)$`),
+		args:    []string{"history"},
+	},
+	{
+		pattern: regexp.MustCompile(`^(what do you remember( about me)?|(show|list)( me)? (my|your|the) (remembered )?facts)$`),
+		args:    []string{"remember", "list"},
+	},
+	{
+		pattern: regexp.MustCompile(`^(what do you know about (this|my) (system|machine|computer|realm)|(show|describe)( me)? (this|my|the) realm)$`),
+		args:    []string{"realm"},
+	},
+	{
+		pattern: regexp.MustCompile(`^((are you|is the knight) (ready|working|healthy|configured|ok|okay)|check (your|the knight'?s) health|(run )?(a )?health check)$`),
+		args:    []string{"doctor"},
+	},
+	{
+		pattern: regexp.MustCompile(`^(show|list)( me)? (your|the|my) (vetted )?recipes$`),
+		args:    []string{"recipes"},
+	},
+	{
+		pattern: regexp.MustCompile(`^(show|list)( me)? (my|your|the) (scheduled spells|scheduled quests|schedules)$`),
+		args:    []string{"schedule", "list"},
+	},
+	{
+		pattern: regexp.MustCompile(`^undo (that|it|the last quest|your last quest|what you (just )?did)$`),
+		args:    []string{"undo"},
+	},
+	{
+		pattern: regexp.MustCompile(`^(do|run) (that|it|the last quest) again$`),
+		args:    []string{"redo", "last"},
+	},
+}
+
+// politeWrappers are dropped from an intent before it is matched against the subcommands
+var politeWrappers = []string{"please ", "can you ", "could you ", "would you ", "kindly "}
+
+// MatchSubcommand returns the arguments of the built-in subcommand whose purpose the intent
+// names, such as "configure show" for "show my configuration", or nil when the oracle should
+// answer it
+func MatchSubcommand(intent string) []string {
+	text := strings.Join(strings.Fields(strings.ToLower(intent)), " ")
+	text = strings.TrimRight(text, "?.! ")
+	for _, wrapper := range politeWrappers {
+		text = strings.TrimPrefix(text, wrapper)
+	}
+	text = strings.TrimSuffix(strings.TrimSuffix(text, " please"), ",")
+
+	for _, redirect := range subcommandRedirects {
+		if redirect.pattern.MatchString(text) {
+			return append([]string(nil), redirect.args...)
+		}
+	}
+	return nil
+}
+
+// runSubcommand runs the built-in subcommand of root an intent was redirected to, as if it had
+// been typed
+func runSubcommand(root *cobra.Command, args []string) error {
+	target, rest, err := root.Find(args)
+	if err != nil {
+		return err
+	}
+	if err := target.ParseFlags(rest); err != nil {
+		return err
+	}
+	rest = target.Flags().Args()
+	if err := target.ValidateArgs(rest); err != nil {
+		return err
+	}
+	ui.PrintInfoMessage(fmt.Sprintf("No need to trouble the oracle, my lord: this is what 'execute-my-will %s' is for.", strings.Join(args, " ")))
+	return target.RunE(target, rest)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/redo.go
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var redoCmd = &cobra.Command{
+	Use:     "redo <number|last>",
+	Aliases: []string{"replay"},
+	Short:   "Run the command or script of an earlier quest again, without asking the oracle",
+	Long: `Show the command or script an earlier quest proposed and, once you approve it, run it again. The oracle is not consulted, so the quest costs no tokens and runs exactly as before.
+
+Quests are numbered in 'execute-my-will history'; 'last' is the most recent quest that proposed something. The usual review still applies: the environment check, the critical directory guard, and the confirmation. Commands that held a credential were redacted in the journal and cannot be run again.`,
+	Example: `  execute-my-will redo last
+  execute-my-will redo 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRedo,
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+}
+
+func runRedo(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	journal, err := history.LoadJournal(config.StatePath(history.JournalFile))
+	if err != nil {
+		return err
+	}
+	deps := DefaultPipelineDeps(nil)
+	deps.Journal = journal
+	deps.Executor = questExecutor(deps.Executor, cfg)
+
+	quest, runErr := (&Redo{Journal: journal, Deps: deps}).Run(args[0], cfg)
+	if quest == nil {
+		return runErr
+	}
+	_ = NewTranscript(quest, nil, runErr, appVersion).Save(config.StatePath(TranscriptFile))
+	rememberQuest(deps.History, quest)
+	recordInJournal(deps.Journal, quest)
+	return runErr
+}
+
+// Redo runs the command or script of a quest from the journal again
+type Redo struct {
+	Journal *history.Journal
+	Deps    PipelineDeps
+}
+
+// Run finds the quest named by ref, a number from the journal or "last", and runs its proposal
+// through review and confirmation again. It returns the new quest, or nil when there is nothing
+// to run.
+func (r *Redo) Run(ref string, cfg *config.Config) (*Quest, error) {
+	record, err := r.find(ref)
+	if err != nil {
+		return nil, err
+	}
+	if record.Redacted {
+		return nil, fmt.Errorf("quest #%d held a credential that was redacted from the journal, my lord; ask for it anew", record.ID)
+	}
+
+	q := &Quest{Intent: record.Intent, Config: cfg, ContextName: record.Context, Replay: record}
+	if intentContext, ok := cfg.Contexts[record.Context]; ok && record.Context != "" {
+		q.Context = &intentContext
+	}
+
+	ui.PrintStatusBox(fmt.Sprintf("🔁 QUEST #%d ONCE MORE", record.ID), fmt.Sprintf("\"%s\", first asked on %s, my lord. Its %s is shown again as it was; the oracle is not consulted.", record.Intent, ui.FormatTimestamp(record.At), proposalKind(record)), "info")
+	return q, NewRedoPipeline(r.Deps).Run(q)
+}
+
+// find resolves a reference to a quest of the journal
+func (r *Redo) find(ref string) (*history.Record, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "#")
+	if strings.EqualFold(ref, "last") {
+		if record := r.Journal.LastProposal(); record != nil {
+			return record, nil
+		}
+		return nil, fmt.Errorf("no earlier quest proposed anything to run, my lord")
+	}
+
+	id, err := strconv.Atoi(ref)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("'%s' is neither a quest number nor 'last', my lord; 'execute-my-will history' lists the numbers", ref)
+	}
+	record := r.Journal.Find(id)
+	if record == nil {
+		return nil, fmt.Errorf("quest #%d is not in the journal, my lord; 'execute-my-will history' lists the quests kept", id)
+	}
+	if record.Content == "" {
+		return nil, fmt.Errorf("quest #%d proposed nothing to run, my lord", id)
+	}
+	return record, nil
+}
+
+// proposalKind names what a quest proposed, for messages
+func proposalKind(record *history.Record) string {
+	if record.IsScript {
+		return "script"
+	}
+	return "command"
+}
+
+// NewRedoPipeline builds the pipeline of the 'redo' subcommand:
+// analyze → replay → review → repeat → confirm → reauth → elevate → detach → execute → report
+// Nothing asks the oracle: the proposal comes from the journal, and it is not explained again.
+func NewRedoPipeline(deps PipelineDeps) *Pipeline {
+	return &Pipeline{stages: []Stage{
+		&analyzeStage{analyzer: deps.Analyzer},
+		&replayStage{},
+		&reviewStage{newEnvValidator: deps.NewEnvValidator, prompter: deps.Prompter},
+		&repeatStage{history: deps.History, now: time.Now},
+		&confirmStage{confirmer: deps.Confirmer},
+		&reauthStage{reauthenticate: deps.Reauthenticate},
+		&elevateStage{prompter: deps.Prompter, isElevated: deps.IsElevated},
+		&detachStage{prompter: deps.Prompter, findMultiplexer: deps.FindMultiplexer, now: time.Now},
+		&executeStage{executor: deps.Executor},
+		&reportStage{},
+	}}
+}
+
+// replayStage takes the proposal of the quest being run again from the journal, and warns when
+// it was first run in another directory, where its relative paths pointed elsewhere
+type replayStage struct{}
+
+func (s *replayStage) Name() string { return "replay" }
+
+func (s *replayStage) Run(q *Quest) (bool, error) {
+	record := q.Replay
+	responseType := ai.ResponseTypeCommand
+	if record.IsScript {
+		responseType = ai.ResponseTypeScript
+	}
+	q.Content = record.Content
+	q.IsScript = record.IsScript
+	q.Recalled = true
+	q.Response = &ai.AIResponse{Type: responseType, Content: record.Content}
+
+	if record.Dir != "" && q.SysInfo != nil && q.SysInfo.CurrentDir != "" && record.Dir != q.SysInfo.CurrentDir {
+		ui.PrintStatusBox("📁 ANOTHER DIRECTORY", fmt.Sprintf("Quest #%d was run in %s, my lord, and you are now in %s. Its relative paths point elsewhere here.", record.ID, record.Dir, q.SysInfo.CurrentDir), "warning")
+	}
+	return true, nil
+}
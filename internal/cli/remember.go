@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/remember.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var rememberCmd = &cobra.Command{
+	Use:   "remember [fact]",
+	Short: "Remember a fact about your realm for every quest",
+	Long: `Keep a fact about your system or preferences, e.g. "I use podman instead of docker", and send it with every quest, so you no longer repeat it in each intent.
+
+Facts are stored in the configuration file under "facts". Without a fact, the remembered ones are listed.`,
+	Example: `  execute-my-will remember "I use podman instead of docker"
+  execute-my-will remember list
+  execute-my-will remember forget 2`,
+	RunE: runRemember,
+}
+
+var rememberListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the remembered facts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRemember(cmd, nil)
+	},
+}
+
+var rememberForgetCmd = &cobra.Command{
+	Use:   "forget <number|fact>",
+	Short: "Forget a remembered fact, by its number in the list or its text",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runForget,
+}
+
+func init() {
+	rememberCmd.AddCommand(rememberListCmd, rememberForgetCmd)
+	rootCmd.AddCommand(rememberCmd)
+}
+
+func runRemember(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfigForFacts()
+	if cfg == nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		printFacts(cfg.Facts)
+		return nil
+	}
+
+	fact := strings.Join(args, " ")
+	added, err := cfg.Remember(fact)
+	if err != nil {
+		return err
+	}
+	if !added {
+		ui.PrintInfoMessage("I already remember that, my lord.")
+		return nil
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	ui.PrintSuccessMessage(fmt.Sprintf("I shall remember it, my lord. %d fact(s) are now sent with every quest.", len(cfg.Facts)))
+	return nil
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfigForFacts()
+	if cfg == nil {
+		return err
+	}
+
+	fact, err := cfg.Forget(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	ui.PrintSuccessMessage(fmt.Sprintf("Forgotten: %s", fact))
+	return nil
+}
+
+// loadConfigForFacts loads the configuration the facts are kept in. It returns a nil config
+// without an error when the knight has not been configured yet, after telling the user so.
+func loadConfigForFacts() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		if config.IsConfigNotFound(err) {
+			printConfigurationRequired()
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// printFacts lists the remembered facts, numbered for 'remember forget'
+func printFacts(facts []string) {
+	if len(facts) == 0 {
+		ui.PrintInfoMessage("I remember nothing yet, my lord. Teach me with: execute-my-will remember \"I use podman instead of docker\"")
+		return
+	}
+	lines := []string{""}
+	for i, fact := range facts {
+		lines = append(lines, fmt.Sprintf("%s %s", ui.Cyan.Sprintf("%d.", i+1), fact))
+	}
+	lines = append(lines, "", ui.Gray.Sprint("Forget one with: execute-my-will remember forget <number>"), "")
+	ui.DefaultTemplate().PrintBox("🧠 REMEMBERED FACTS", lines)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/report.go
+package cli
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Bundle the last quest into a report for an issue",
+	Long: `Bundle the last quest's prompt, the oracle's response, a summary of the system analysis, and any error output into a single markdown file (or a zip with --zip) ready to attach to a GitHub issue.
+
+The API key, your home directory, and anything that looks like a credential are redacted. Review the report before sharing it.`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringP("output", "o", "", "Where to write the report (default: execute-my-will-report-<time>.md or .zip in the current directory)")
+	reportCmd.Flags().Bool("zip", false, "Write a zip containing the markdown report and the raw transcript")
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	transcript, err := LoadTranscript(config.StatePath(TranscriptFile))
+	if err != nil {
+		return err
+	}
+	if transcript == nil {
+		ui.PrintStatusBox("📜 NO QUEST RECORDED", "There is no quest to report yet, my lord.\n\nRun a quest first, then 'execute-my-will report' bundles it for an issue.", "info")
+		return nil
+	}
+
+	asZip, _ := cmd.Flags().GetBool("zip")
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		extension := ".md"
+		if asZip {
+			extension = ".zip"
+		}
+		output = "execute-my-will-report-" + transcript.Time.Format("20060102-150405") + extension
+	}
+
+	if asZip {
+		err = writeReportZip(output, transcript)
+	} else {
+		err = os.WriteFile(output, []byte(transcript.Markdown()), 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the report, my lord: %w", err)
+	}
+
+	ui.PrintStatusBox("📜 REPORT READY", fmt.Sprintf("The chronicle of your last quest was written to %s\n\nSecrets and your home directory were redacted, but please read it before attaching it to an issue.", output), "success")
+	return nil
+}
+
+// writeReportZip writes the markdown report together with the transcript it was rendered from
+func writeReportZip(path string, transcript *Transcript) error {
+	raw, err := yaml.Marshal(transcript)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	name := filepath.Base(path[:len(path)-len(filepath.Ext(path))])
+	for entry, content := range map[string][]byte{
+		name + "/report.md":       []byte(transcript.Markdown()),
+		name + "/transcript.yaml": raw,
+	} {
+		w, err := archive.Create(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
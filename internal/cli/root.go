@@ -7,13 +7,16 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/minand-mohan/execute-my-will/internal/ai"
 	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
 	"github.com/minand-mohan/execute-my-will/internal/system"
 	"github.com/minand-mohan/execute-my-will/internal/ui"
 	"github.com/spf13/cobra"
@@ -30,7 +33,7 @@ var (
 var rootCmd = &cobra.Command{
 	Use:   "execute-my-will [intent]",
 	Short: "Your faithful digital knight, ready to execute your commands",
-	Long:  "A CLI application that interprets your natural language intent and executes the appropriate system commands with your permission, my lord.",
+	Long:  "A CLI application that interprets your natural language intent and executes the appropriate system commands with your permission, my lord.\n\nText piped to stdin, such as a log, is sent with the intent as context. Give '-' as the intent to read the intent itself from stdin.",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE:  executeWill,
 	CompletionOptions: cobra.CompletionOptions{
@@ -46,10 +49,23 @@ func SetBuildInfo(version, commit, buildTime string) {
 }
 
 func Execute() error {
+	// A Windows console in a legacy code page garbles emoji and the output of children
+	restore := system.PrepareConsole()
+	defer restore()
+
 	return rootCmd.Execute()
 }
 
 func init() {
+	// A request held back by a configured rate limit says so through the UI
+	ai.OnRateLimitWait = func(wait time.Duration) {
+		ui.PrintLine("⏳", fmt.Sprintf("Waiting for the oracle's favor (rate limit reached, %s)...", ui.FormatDuration(wait.Round(time.Second))))
+	}
+	// So does an answer that was cut off and is asked for again
+	ai.OnTruncatedRetry = func() {
+		ui.PrintLine("🌀", "The oracle's answer was cut off before it ended, sire. I will ask again...")
+	}
+
 	// Add version flag
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "Display application version")
 
@@ -58,69 +74,169 @@ func init() {
 
 	// Add mode flag
 	rootCmd.Flags().String("mode", "", "Execution mode: monarch (no explanations) or royal-heir (detailed explanations)")
+
+	// Add explain-only flag
+	rootCmd.Flags().Bool("explain-only", false, "Generate and explain the command without offering to run it")
+
+	// Add dry-run flag
+	rootCmd.Flags().Bool("dry-run", false, "Generate, explain, and check the quest as usual, then print the command or script instead of running it")
+
+	// Add to-prompt flag
+	rootCmd.Flags().String("to-prompt", "", "Check the quest as usual, then write the command to this file instead of running it, for the shell widget of 'execute-my-will integrate' to put on your prompt line")
+
+	// Add eval flag
+	rootCmd.Flags().Bool("eval", false, "Print only the approved command on stdout, for your shell to evaluate with eval \"$(execute-my-will --eval ...)\", so that cd and export last; everything else goes to stderr")
+
+	// Add as-user flag
+	rootCmd.Flags().String("as-user", "", "Run the quest as this less-privileged user via sudo -u, or directly when running as root (Unix only)")
+
+	// Add parallel flag
+	rootCmd.Flags().Bool("parallel", false, "Run 'all:' intents in every workspace project at the same time")
+
+	// Add plan flag
+	rootCmd.Flags().Bool("plan", false, "Break a quest too large for one script into a numbered plan of sub-quests, each confirmed and run in turn")
+
+	// Add isolated flag
+	rootCmd.Flags().Bool("isolated", false, "Run the quest in a scratch copy of the current directory and apply its changes only after you approve the diff")
+
+	// Add continue flag
+	rootCmd.Flags().Bool("continue", false, "Carry on from the previous quest, sending it and what it printed with this intent (e.g. 'now compress that folder')")
+
+	// Add then flag
+	rootCmd.Flags().StringArray("then", nil, "After the quest succeeds, carry out this follow-up intent with the quest's output as context (repeatable; each is confirmed on its own)")
+
+	// Add auto-fix flag
+	rootCmd.Flags().Int("auto-fix", 0, "After a failure, ask the AI for a corrected command up to N times (each still needs confirmation)")
+
+	// Add air-gapped flag
+	rootCmd.Flags().Bool("air-gapped", false, "Refuse every proposal that would reach the network, and only use a provider that works offline")
+
+	// Add from-clipboard flag
+	rootCmd.Flags().Bool("from-clipboard", false, "Send the text on the clipboard, such as a copied error message, with the intent as context (e.g. 'fix this error')")
+
+	// Add debug flag
+	rootCmd.Flags().Bool("debug", false, "Print how long each phase of the system analysis took, with advice on slow ones")
+
+	// Add no-system-scan flag
+	rootCmd.Flags().Bool("no-system-scan", false, "Skip listing installed packages and commands for a faster start; only the OS, shell, and current directory are sent")
 }
 
 func executeWill(cmd *cobra.Command, args []string) error {
 	if versionFlag {
-		fmt.Print("execute-my-will\n")
-		fmt.Printf("Version: %s\n", appVersion)
+		ui.PrintPlain("execute-my-will")
+		ui.PrintPlain(fmt.Sprintf("Version: %s", appVersion))
 		if appCommit != "" && appCommit != "unknown" {
-			fmt.Printf("Commit: %s\n", appCommit)
+			ui.PrintPlain(fmt.Sprintf("Commit: %s", appCommit))
 		}
 		if appBuildTime != "" && appBuildTime != "unknown" {
-			fmt.Printf("Build Time: %s\n", appBuildTime)
+			ui.PrintPlain(fmt.Sprintf("Build Time: %s", appBuildTime))
 		}
 		return nil
 	}
 
+	// With --eval only the approved command reaches stdout, for the shell to evaluate
+	var evalOut io.Writer
+	if evalMode, _ := cmd.Flags().GetBool("eval"); evalMode {
+		evalOut = ui.UseStderr()
+	}
+
 	// Check if there are any arguments
 	if len(args) == 0 {
 		ui.PrintStatusBox("QUEST REQUIRED", "Please provide an intent, my lord!\n\nExample:\n  execute-my-will 'create a new file named my-file.txt in the current directory'", "info")
 		return nil
 	}
 
-	// Check if config file exists, if not prompt user to configure
-	cfg, err := config.Load()
-	if err != nil {
-		if config.IsConfigNotFound(err) {
-			ui.PrintStatusBox("🔧 CONFIGURATION REQUIRED", "Configuration file not found, my lord!\n\n📋 Please run 'execute-my-will configure' to set up your configuration first.\n\nExample:\n  execute-my-will configure\n  # or set specific values:\n  execute-my-will configure --api-key your-key --provider gemini --mode monarch", "warning")
-			return nil
+	// "-" reads the intent itself from stdin
+	if len(args) == 1 && args[0] == "-" {
+		text, err := system.ReadStdin()
+		if err != nil {
+			return fmt.Errorf("%w, my lord", err)
 		}
-		return fmt.Errorf("failed to load configuration: %w", err)
+		intent, err := StdinIntent(text)
+		if err != nil {
+			return err
+		}
+		args = []string{intent}
 	}
 
-	// Override mode from flag if provided
-	if cmd.Flags().Changed("mode") {
-		mode, _ := cmd.Flags().GetString("mode")
-		cfg.Mode = mode
+	// An intent a built-in subcommand serves goes to that subcommand, sparing the oracle and
+	// keeping the knight's own files out of generated commands
+	if cmd.Flags().NFlag() == 0 {
+		if subcommand := MatchSubcommand(strings.Join(args, " ")); subcommand != nil {
+			return runSubcommand(cmd.Root(), subcommand)
+		}
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("configuration error, sire: %w", err)
+	cfg, err := loadValidatedConfig(func(cfg *config.Config) {
+		// Override mode from flag if provided
+		if cmd.Flags().Changed("mode") {
+			mode, _ := cmd.Flags().GetString("mode")
+			cfg.Mode = mode
+		}
+		if noScan, _ := cmd.Flags().GetBool("no-system-scan"); noScan {
+			scan := false
+			cfg.Analysis.SystemScan = &scan
+		}
+	})
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	autoFix, _ := cmd.Flags().GetInt("auto-fix")
+	if autoFix < 0 {
+		return fmt.Errorf("--auto-fix must be zero or a positive number of attempts, my lord")
 	}
 
 	// Join all arguments as the user's intent
 	intent := strings.Join(args, " ")
 
-	ui.PrintKnightMessage(fmt.Sprintf("Your faithful knight has received your command: \"%s\"", intent))
+	// "all: run the tests" runs in every project of the workspace
+	intent, allProjects := config.MatchWorkspaceIntent(intent)
+
+	// Apply any configured context named by the intent's prefix (e.g. "prod: restart nginx")
+	contextName, intentContext, intent := cfg.MatchContext(intent)
+	if intent == "" {
+		ui.PrintStatusBox("QUEST REQUIRED", fmt.Sprintf("Please tell me what to do in the '%s' context, my lord!", contextName), "info")
+		return nil
+	}
+
+	ui.PrintFlourish("📯", "Hear ye, hear ye! A new quest has been decreed!")
+	ui.PrintKnightMessage(ui.Message("quest.received", "intent", intent))
+	if intentContext != nil {
+		ui.PrintInfoMessage(fmt.Sprintf("The '%s' context applies to this quest.", contextName))
+	}
 	ui.PrintInfoMessage("Analyzing your noble request...")
+	if !cfg.Analysis.ScanSystem() {
+		ui.PrintInfoMessage("Skipping the survey of packages and commands; the oracle will know only the OS, shell, and current directory.")
+	}
 
-	ui.PrintPhaseHeader("🧙", "Consulting with the ancient oracles...")
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
 
-	// Initialize system analyzer
-	analyzer := system.NewAnalyzer()
+	var copied string
+	if fromClipboard, _ := cmd.Flags().GetBool("from-clipboard"); fromClipboard {
+		if copied, err = readClipboardContext(cfg); err != nil {
+			return err
+		}
+	}
 
-	// Perform system analysis
-	sysInfo, err := analyzer.AnalyzeSystem()
-	if err != nil {
-		return fmt.Errorf("failed to analyze the realm's systems, my lord: %w", err)
+	// Text piped to stdin, such as a log or a command's output, goes with the intent as context
+	var piped string
+	if system.StdinPiped() {
+		text, err := system.ReadStdin()
+		if err != nil {
+			return fmt.Errorf("%w, my lord", err)
+		}
+		piped = PipedContext(text, cfg)
 	}
 
-	// Validate the intent
-	validator := system.NewValidator(sysInfo)
-	if err := validator.ValidateIntent(intent); err != nil {
-		ui.PrintStatusBox("⚠️  REQUEST CLARIFICATION NEEDED", fmt.Sprintf("Forgive me sire, but your request needs clarification: %s", err.Error()), "warning")
-		return nil
+	airGapped, _ := cmd.Flags().GetBool("air-gapped")
+	if airGapped {
+		if !cfg.OfflineProvider() {
+			return fmt.Errorf("--air-gapped needs a provider that works offline, but '%s' is reached over the network, my lord; point 'base_url' at an OpenAI-compatible server on this machine, or set 'provider: mock' with canned responses", cfg.AIProvider)
+		}
+		ui.PrintInfoMessage("This quest is air-gapped: nothing that reaches the network will be carried out.")
 	}
 
 	// Initialize AI client
@@ -129,140 +245,277 @@ func executeWill(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
 	}
 
-	// Generate response (command or script)
-	response, err := aiClient.GenerateResponse(intent, sysInfo)
-	if err != nil {
-		return fmt.Errorf("the oracles have failed us, sire: %w", err)
+	explainOnly, _ := cmd.Flags().GetBool("explain-only")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun && explainOnly {
+		return fmt.Errorf("choose --dry-run or --explain-only, my lord; --explain-only skips the checks a dry run makes")
+	}
+	asUser, _ := cmd.Flags().GetString("as-user")
+	asUser = strings.TrimSpace(asUser)
+	plan, _ := cmd.Flags().GetBool("plan")
+	toPrompt, _ := cmd.Flags().GetString("to-prompt")
+	if toPrompt != "" && (allProjects || explainOnly || dryRun || plan || asUser != "") {
+		return fmt.Errorf("--to-prompt is not available for 'all:', --explain-only, --dry-run, --plan, or --as-user quests, my lord")
+	}
+	if evalOut != nil && (allProjects || explainOnly || dryRun || plan || asUser != "" || toPrompt != "") {
+		return fmt.Errorf("--eval is not available for 'all:', --explain-only, --dry-run, --plan, --as-user, or --to-prompt quests, my lord")
+	}
+	if plan && (allProjects || explainOnly || dryRun) {
+		return fmt.Errorf("--plan is not available for 'all:', --explain-only, or --dry-run quests yet, my lord")
+	}
+	isolated, _ := cmd.Flags().GetBool("isolated")
+	if isolated && (allProjects || explainOnly || dryRun || asUser != "" || toPrompt != "" || evalOut != nil) {
+		return fmt.Errorf("--isolated is not available for 'all:', --explain-only, --dry-run, --as-user, --to-prompt, or --eval quests yet, my lord")
+	}
+	followUps, _ := cmd.Flags().GetStringArray("then")
+	if len(followUps) > 0 && (allProjects || explainOnly || dryRun || plan || toPrompt != "" || evalOut != nil) {
+		return fmt.Errorf("--then is not available for 'all:', --explain-only, --dry-run, --plan, --to-prompt, or --eval quests, my lord")
 	}
 
-	var taskContent string
-	var isScript bool
+	continued, _ := cmd.Flags().GetBool("continue")
 
-	// Handle different response types
-	switch response.Type {
-	case ai.ResponseTypeFailure:
-		ui.PrintStatusBox("❌ QUEST CANNOT BE COMPLETED", fmt.Sprintf("Alas, I cannot fulfill this quest: %s", response.Error), "error")
-		return nil
+	if allProjects {
+		if asUser != "" {
+			return fmt.Errorf("--as-user is not available for 'all:' quests yet, my lord")
+		}
+		if continued {
+			return fmt.Errorf("--continue is not available for 'all:' quests, my lord")
+		}
+		if autoFix > 0 {
+			return fmt.Errorf("--auto-fix is not available for 'all:' quests yet, my lord; each failed project is listed with its error")
+		}
+		if toPrompt != "" || evalOut != nil {
+			return fmt.Errorf("--to-prompt and --eval hand a single command to your shell, so they are not available for 'all:' quests, my lord")
+		}
 
-	case ai.ResponseTypeCommand:
-		// Display the command for confirmation
-		ui.PrintCommandBox(response.Content)
-		taskContent = response.Content
-		isScript = false
-
-		// If in royal-heir mode, provide detailed explanation for commands only
-		if cfg.Mode == "royal-heir" {
-			explanation, err := aiClient.ExplainCommand(response.Content, sysInfo)
-			if err != nil {
-				ui.PrintStatusBox("⚠️  EXPLANATION DIFFICULTY", fmt.Sprintf("I encountered difficulty explaining the command, but it should still work, my lord: %v", err), "warning")
-			} else {
-				ui.PrintStatusBox("📚 COMMAND EXPLANATION", fmt.Sprintf("As you are still learning the ways of the realm, allow me to explain:\n\n%s", explanation), "info")
-			}
+		cwd, _ := os.Getwd()
+		workspace, err := config.FindWorkspace(cwd)
+		if err != nil {
+			return err
+		}
+		if workspace == nil {
+			ui.PrintStatusBox("📁 NO WORKSPACE", fmt.Sprintf("'all:' quests need a workspace, my lord.\n\nList your projects in %s (here or in a parent directory):\n\nprojects:\n  - ~/code/api\n  - ~/code/web\nparallel: false", config.WorkspaceFile), "info")
+			return nil
 		}
 
-		// Validate if the command affects the environment
-		envValidator := system.NewEnvironmentValidator(sysInfo)
-		if err := envValidator.ValidateEnvironmentCommand(response.Content); err != nil {
-			if envErr, ok := err.(*system.EnvironmentCommandError); ok {
-				fmt.Println()
-				fmt.Println(envErr.GetKnightlyMessage())
-				return nil
+		parallel, _ := cmd.Flags().GetBool("parallel")
+		ui.PrintInfoMessage(fmt.Sprintf("This quest applies to the %d projects of %s.", len(workspace.Projects), workspace.Path))
+		deps := DefaultPipelineDeps(aiClient)
+		deps.Analyzer = newAnalyzer(cfg)
+		newProjectExecutor := deps.NewProjectExecutor
+		deps.NewProjectExecutor = func(dir, label string, background bool) system.CommandExecutor {
+			executor := system.WithEnvironment(newProjectExecutor(dir, label, background), cfg.Execution.Env)
+			executor = system.WithMaxLineSize(executor, cfg.Execution.MaxLineSize)
+			return system.WithWaitHelper(executor, selfBinary())
+		}
+		run := &WorkspaceRun{Workspace: workspace, Parallel: parallel || workspace.Parallel, Deps: deps}
+		quest := &Quest{Intent: intent, Config: cfg, ExplainOnly: explainOnly, DryRun: dryRun, AirGapped: airGapped, ContextName: contextName, Context: intentContext, Clipboard: copied, Piped: piped}
+		results, err := run.Run(quest)
+		// Remembered so that running a destructive workspace quest again warns like any other
+		for _, result := range results {
+			if result.Quest != nil {
+				rememberQuest(deps.History, result.Quest)
 			}
-			return fmt.Errorf("environment validation failed: %w", err)
 		}
+		return err
+	}
 
-	case ai.ResponseTypeScript:
-		// Display the script for confirmation
-		showComments := cfg.Mode == "royal-heir"
-		scriptLines := strings.Split(response.Content, "\n")
-
-		// Filter and format script lines based on mode
-		var displayLines []string
-		displayLines = append(displayLines, "") // Empty line at start
-
-		for _, line := range scriptLines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
+	debug, _ := cmd.Flags().GetBool("debug")
+	quest := &Quest{Intent: intent, Config: cfg, AsUser: asUser, AutoFixLimit: autoFix, ExplainOnly: explainOnly, DryRun: dryRun, ToPrompt: toPrompt, Eval: evalOut, AirGapped: airGapped, ContextName: contextName, Context: intentContext, Clipboard: copied, Piped: piped, Debug: debug}
+	deps := DefaultPipelineDeps(aiClient)
+	deps.Analyzer = newAnalyzer(cfg)
+	if asUser != "" {
+		deps.Executor, err = system.NewUserExecutor(asUser)
+		if err != nil {
+			return fmt.Errorf("failed to prepare the quest for '%s', my lord: %w", asUser, err)
+		}
+		ui.PrintInfoMessage(fmt.Sprintf("The quest will be carried out as '%s'.", asUser))
+	}
+	deps.Executor = questExecutor(deps.Executor, cfg)
 
-			// Check if line is a comment
-			isComment := strings.HasPrefix(line, "#") || strings.HasPrefix(line, "REM")
+	// Every quest starts a new session unless it continues the current one
+	session := startSession(config.StatePath(history.SessionFile), continued, time.Now())
+	aiClient.Continue(SessionMessages(session))
 
-			if isComment && showComments {
-				// Display comment with proper formatting
-				comment := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "#"), "REM"))
-				displayLines = append(displayLines, ui.CommentText("• "+comment))
-			} else if !isComment {
-				// Display command with arrow prefix
-				displayLines = append(displayLines, ui.CommandText("→ "+line))
-			}
+	run := func(deps PipelineDeps) error {
+		if plan {
+			return runPlan(deps, quest, aiClient, session)
+		}
+		if len(followUps) > 0 {
+			return runChain(deps, quest, followUps, aiClient, session)
 		}
-		displayLines = append(displayLines, "") // Empty line at end
+		runErr := NewPipeline(deps).Run(quest)
+
+		// Keep a redacted transcript for 'execute-my-will report'; it never fails the quest
+		_ = NewTranscript(quest, aiClient.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+		rememberQuest(deps.History, quest)
+		recordInJournal(deps.Journal, quest)
+		rememberInSession(session, quest)
+		return runErr
+	}
 
-		template := ui.DefaultTemplate()
-		template.PrintBox("📜 PROPOSED SCRIPT", displayLines)
-		taskContent = response.Content
-		isScript = true
+	if isolated {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to find the current directory, my lord: %w", err)
+		}
+		_, err = (&IsolatedRun{Deps: deps, Dir: cwd}).Run(run)
+		return err
+	}
+	return run(deps)
+}
 
-		if cfg.Mode == "royal-heir" {
-			ui.PrintStatusBox("📚 SCRIPT INFORMATION", "This script will execute each command in sequence, maintaining context between steps.", "info")
+// runPlan works through a planned quest. Every executed step is remembered, every started one
+// joins the session, and the transcript covers the last step that was started.
+func runPlan(deps PipelineDeps, quest *Quest, aiClient ai.Client, session *history.Session) error {
+	results, runErr := (&PlanRun{Deps: deps}).Run(quest)
+
+	last := quest
+	for _, result := range results {
+		if result.Quest != nil {
+			rememberQuest(deps.History, result.Quest)
+			recordInJournal(deps.Journal, result.Quest)
+			rememberInSession(session, result.Quest)
+			last = result.Quest
 		}
 	}
+	_ = NewTranscript(last, aiClient.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+	return runErr
+}
 
-	// Ask for confirmation
-	if cfg.Mode == "monarch" {
-		fmt.Print("🤴 Do you wish me to proceed with this quest? (y/N): ")
-	} else {
-		fmt.Print("👑 Do you wish me to proceed with this quest, young heir? (y/N): ")
+// runChain carries out a quest and its follow-ups. Every executed quest is remembered, every
+// started one joins the session, and the transcript covers the last one that was started.
+func runChain(deps PipelineDeps, quest *Quest, followUps []string, aiClient ai.Client, session *history.Session) error {
+	results, runErr := (&ChainRun{Deps: deps}).Run(quest, followUps)
+
+	last := quest
+	for _, result := range results {
+		if result.Quest != nil {
+			rememberQuest(deps.History, result.Quest)
+			recordInJournal(deps.Journal, result.Quest)
+			rememberInSession(session, result.Quest)
+			last = result.Quest
+		}
 	}
+	_ = NewTranscript(last, aiClient.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+	return runErr
+}
 
-	reader := bufio.NewReader(os.Stdin)
-	userResponse, err := reader.ReadString('\n')
+// questExecutor wraps an executor with the configured environment and line size, the saved output
+// log, and the emw-wait helper
+func questExecutor(executor system.CommandExecutor, cfg *config.Config) system.CommandExecutor {
+	executor = system.WithEnvironment(executor, cfg.Execution.Env)
+	executor = system.WithMaxLineSize(executor, cfg.Execution.MaxLineSize)
+	executor = system.WithOutputLog(executor, config.StatePath(system.OutputLogDir))
+	return system.WithWaitHelper(executor, selfBinary())
+}
+
+// selfBinary is the knight's own executable, whose 'wait' subcommand backs the emw-wait helper
+func selfBinary() string {
+	binary, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to read your royal decree: %w", err)
+		return ""
+	}
+	return binary
+}
+
+// newAnalyzer returns the quick analyzer when the system scan is turned off
+func newAnalyzer(cfg *config.Config) system.SystemAnalyzer {
+	if !cfg.Analysis.ScanSystem() {
+		return system.NewQuickAnalyzer()
 	}
+	return system.NewAnalyzer()
+}
 
-	userResponse = strings.TrimSpace(strings.ToLower(userResponse))
-	if userResponse != "y" && userResponse != "yes" {
-		ui.PrintStatusBox("🙏 QUEST DECLINED", "I understand, sire. Please try again when you're ready.", "info")
+// rememberQuest adds an executed quest to the history used for suggestions. Commands holding
+// anything shaped like a credential are never written down.
+func rememberQuest(store *history.Store, q *Quest) {
+	if store == nil || !q.Executed || system.RedactSecrets(q.Content) != q.Content {
+		return
+	}
+	entry := store.Record(q.Intent, q.ContextName, q.Content, q.IsScript, q.ExecErr, time.Now())
+	if q.OutputLog != nil {
+		entry.LogFile = q.OutputLog.Path
+	}
+	for _, artifact := range q.Artifacts {
+		entry.Artifacts = append(entry.Artifacts, artifact.Path)
+	}
+	_ = store.Save()
+}
+
+// printConfigurationRequired tells the user to run 'configure' before anything else
+func printConfigurationRequired() {
+	ui.PrintStatusBox("🔧 CONFIGURATION REQUIRED", "Configuration file not found, my lord!\n\n📋 Please run 'execute-my-will configure' to set up your configuration first.\n\nExample:\n  execute-my-will configure\n  # or set specific values:\n  execute-my-will configure --api-key your-key --provider gemini --mode monarch", "warning")
+}
+
+// applyLocale shows dates, durations, and sizes in the configured locale. Without one, the
+// locale of the environment stays in use.
+func applyLocale(cfg *config.Config) error {
+	if cfg.UI.Locale == "" {
 		return nil
 	}
+	locale, err := ui.ParseLocale(cfg.UI.Locale)
+	if err != nil {
+		return err
+	}
+	ui.SetLocale(locale)
+	return nil
+}
+
+// loadValidatedConfig loads the configuration, applies any overrides, validates it, and
+// activates the configured UI verbosity. It returns a nil config without an error when the
+// knight has not been configured yet, after telling the user how to do so.
+func loadValidatedConfig(overrides ...func(*config.Config)) (*config.Config, error) {
+	// Check if config file exists, if not prompt user to configure
+	cfg, err := config.Load()
+	if err != nil {
+		if config.IsConfigNotFound(err) {
+			printConfigurationRequired()
+			return nil, nil
+		}
+		if errors.Is(err, config.ErrParseFailure) {
+			return nil, fmt.Errorf("failed to load configuration, sire; mend the file, or write it anew with 'execute-my-will configure': %w", err)
+		}
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	for _, override := range overrides {
+		override(cfg)
+	}
 
-	// Execute the task with enhanced interactive support
-	fmt.Println("🛡️  Executing your quest with honor...")
-	fmt.Println()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration error, sire: %w", err)
+	}
+
+	verbosity, err := ui.ParseVerbosity(cfg.UI.Verbosity)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error, sire: %w", err)
+	}
+	ui.SetVerbosity(verbosity)
 
-	executor := system.NewExecutor()
-	var execErr error
+	persona, err := ui.LoadPersona(cfg.UI.Persona)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error, sire: %w", err)
+	}
+	ui.SetPersona(persona)
 
-	if isScript {
-		showComments := cfg.Mode == "royal-heir"
-		execErr = executor.ExecuteScript(taskContent, sysInfo.Shell, showComments)
-	} else {
-		execErr = executor.Execute(taskContent, sysInfo.Shell)
+	if err := applyLocale(cfg); err != nil {
+		return nil, fmt.Errorf("configuration error, sire: %w", err)
 	}
 
-	if execErr != nil {
-		var suggestionMsg string
+	if warning := cfg.APIVersionWarning(); warning != "" {
+		ui.PrintStatusBox("⏳ API VERSION SUNSET", warning, "warning")
+	}
 
-		// Check if it's a common issue and provide helpful suggestions
-		if strings.Contains(execErr.Error(), "permission denied") {
-			suggestionMsg = "\n\n💡 This might require elevated privileges. Consider adding 'sudo' to your request if appropriate."
-		} else if strings.Contains(execErr.Error(), "command not found") {
-			suggestionMsg = "\n\n💡 The command appears to be missing. The system may need to install required packages first."
-		} else if strings.Contains(execErr.Error(), "no such file or directory") {
-			suggestionMsg = "\n\n💡 Please ensure all file paths in your request are correct and accessible."
+	if ledger, err := ai.LoadUsageLedger(config.StatePath(ai.UsageFile)); err == nil {
+		if notice := ai.DegradeForBudget(cfg, ledger, time.Now()); notice != "" {
+			ui.PrintStatusBox("💰 BUDGET NEARLY SPENT", notice, "warning")
 		}
-
-		ui.PrintStatusBox("⚔️  QUEST DIFFICULTIES", fmt.Sprintf("Alas! The quest has encountered difficulties, my lord: %v%s", execErr, suggestionMsg), "error")
-		return nil // Don't return the error to avoid double error messages
 	}
 
-	if isScript {
-		ui.PrintStatusBox("🏆 QUEST COMPLETED", "Your script has been executed successfully, sire!", "success")
-	} else {
-		ui.PrintStatusBox("🏆 QUEST COMPLETED", "Your command has been executed successfully, sire!", "success")
+	if m := cfg.Migration; m != nil {
+		ui.PrintStatusBox("📜 CONFIGURATION UPGRADED", fmt.Sprintf("Your configuration was upgraded from version %d to %d, my lord.\n\nThe original was kept at %s", m.FromVersion, m.ToVersion, m.BackupPath), "info")
 	}
-	return nil
+
+	return cfg, nil
 }
@@ -0,0 +1,370 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/schedule.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// scheduledDir holds the scripts of scheduled spells and the logs of their runs
+const scheduledDir = "scheduled"
+
+var scheduleCmd = &cobra.Command{
+	Use:   `schedule "<when>" <spell>`,
+	Short: "Run a saved spell unattended on a schedule",
+	Long: `Turn a saved spell into a crontab entry, a systemd user timer, or a Windows scheduled task. A spell is a function of the library built by 'execute-my-will distill', or the number of a quest from 'execute-my-will history' that ran successfully after you approved it.
+
+The schedule can be described in words, such as "every day at 2am", "every 15 minutes", "weekdays at 18:00", or "every monday and friday at 9:30", or given as a cron expression. The spell's script is kept as you approved it and runs without confirmation, review, or elevation prompts, so you are shown it and asked once more before it is scheduled. Every run honors the kill switch, and its output is logged next to the script.`,
+	Example: `  execute-my-will schedule "every day at 2am" back_up_dotfiles
+  execute-my-will schedule "weekdays at 18:00" 42 --name tidy_downloads
+  execute-my-will schedule list
+  execute-my-will schedule remove back_up_dotfiles`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSchedule,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the scheduled spells",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedules, err := history.LoadSchedules(config.StatePath(history.SchedulesFile))
+		if err != nil {
+			return err
+		}
+		printSchedules(schedules)
+		return nil
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Stop running a scheduled spell",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scheduling, err := newScheduling("")
+		if err != nil {
+			return err
+		}
+		return scheduling.Remove(args[0])
+	},
+}
+
+// scheduleRunCmd is what the system scheduler runs; it is not meant to be typed
+var scheduleRunCmd = &cobra.Command{
+	Use:    "run <name>",
+	Short:  "Run a scheduled spell now, without confirmation",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE:   runScheduledSpell,
+}
+
+func init() {
+	scheduleCmd.Flags().String("with", "", fmt.Sprintf("The system scheduler to use: %s (default: the platform's own)", strings.Join(system.Schedulers, ", ")))
+	scheduleCmd.Flags().String("name", "", "Name of the scheduled spell (default: the function's name, or one made from the quest's intent)")
+	scheduleCmd.AddCommand(scheduleListCmd, scheduleRemoveCmd, scheduleRunCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+func runSchedule(cmd *cobra.Command, args []string) error {
+	with, _ := cmd.Flags().GetString("with")
+	name, _ := cmd.Flags().GetString("name")
+	if with != "" && !slices.Contains(system.Schedulers, with) {
+		return fmt.Errorf("--with must be one of %s, my lord", strings.Join(system.Schedulers, ", "))
+	}
+
+	cfg, _ := config.Load()
+	if cfg != nil {
+		_ = applyLocale(cfg)
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	scheduling, err := newScheduling(with)
+	if err != nil {
+		return err
+	}
+	_, err = scheduling.Run(args[0], args[1], name)
+	return err
+}
+
+// newScheduling gathers what scheduling a spell needs from the machine
+func newScheduling(kind string) (*Scheduling, error) {
+	schedules, err := history.LoadSchedules(config.StatePath(history.SchedulesFile))
+	if err != nil {
+		return nil, err
+	}
+	journal, err := history.LoadJournal(config.StatePath(history.JournalFile))
+	if err != nil {
+		return nil, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find the execute-my-will binary for the scheduler to run: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	if kind == "" {
+		kind = system.DefaultScheduler()
+	}
+
+	var shell string
+	if info, _ := system.NewQuickAnalyzer().AnalyzeSystem(); info != nil {
+		shell = info.Shell
+	}
+	return &Scheduling{
+		Journal:       journal,
+		Library:       config.StatePath(system.FunctionsFile),
+		TeamLibrary:   config.StatePath(system.TeamFunctionsFile),
+		Schedules:     schedules,
+		NewScheduler:  system.NewScheduler,
+		SchedulerKind: kind,
+		Prompter:      newStdinConsole(os.Stdin),
+		Dir:           config.StatePath(scheduledDir),
+		Shell:         shell,
+		Executable:    executable,
+		Now:           time.Now,
+	}, nil
+}
+
+// Scheduling installs saved spells with a system scheduler, and removes them again
+type Scheduling struct {
+	Journal       *history.Journal
+	Library       string // the shell function library
+	TeamLibrary   string // the library of shared spells reviewed on this machine
+	Schedules     *history.Schedules
+	NewScheduler  func(kind string) (system.Scheduler, error)
+	SchedulerKind string // the scheduler new spells are installed with
+	Prompter      Prompter
+	Dir           string // where the scripts of scheduled spells and their logs are kept
+	Shell         string
+	Executable    string // the execute-my-will binary the scheduler runs
+	Now           func() time.Time
+}
+
+// Run schedules the spell named by ref, a function of the library or a quest number, to run
+// unattended on the schedule described by when. The script is shown with a warning that no one
+// will confirm its runs, and is only scheduled once approved. It returns the scheduled spell, or
+// nil when it was left unscheduled.
+func (s *Scheduling) Run(when, ref, name string) (*history.ScheduledSpell, error) {
+	schedule, err := system.ParseSchedule(when)
+	if err != nil {
+		return nil, fmt.Errorf("%w, my lord", err)
+	}
+	spell, script, err := s.spell(ref)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		spell.Name = name
+	}
+	if err := system.ValidateFunctionName(spell.Name); err != nil {
+		return nil, fmt.Errorf("%w, my lord; choose another with --name", err)
+	}
+	if existing := s.Schedules.Find(spell.Name); existing != nil && existing.Scheduler != s.SchedulerKind {
+		return nil, fmt.Errorf("'%s' is already scheduled with %s, my lord; remove it first with 'execute-my-will schedule remove %s'", spell.Name, existing.Scheduler, spell.Name)
+	}
+	scheduler, err := s.NewScheduler(s.SchedulerKind)
+	if err != nil {
+		return nil, err
+	}
+
+	spell.When, spell.Cron, spell.Scheduler = schedule.Text, schedule.Cron(), s.SchedulerKind
+	spell.Script = filepath.Join(s.Dir, spell.Name+system.ScriptExtension(spell.Shell))
+	spell.Log = filepath.Join(s.Dir, spell.Name+".log")
+	spell.At = s.Now()
+
+	ui.PrintScriptBox(fmt.Sprintf("📜 SPELL '%s' (from %s)", spell.Name, spell.Source), strings.Split(strings.TrimRight(script, "\n"), "\n"))
+	ui.PrintStatusBox("⏰ NO ONE WILL CONFIRM IT", fmt.Sprintf("Once scheduled, '%s' runs %s through %s without asking you first, my lord: no confirmation, no review, and no elevation prompt. It runs exactly as shown above, even when the realm has changed since you approved it.\n\nIts output is kept in %s. Stop it with 'execute-my-will schedule remove %s', or halt every quest with the kill switch.", spell.Name, schedule, s.SchedulerKind, spell.Log, spell.Name), "warning")
+	switch contentRisk(script, true) {
+	case system.RiskDestructive:
+		ui.PrintStatusBox("💀 DESTRUCTIVE AND UNATTENDED", "This spell destroys data, my lord, and no one will be there to stop it. Schedule it only if you would approve every one of its runs in advance.", "error")
+	case system.RiskElevated:
+		ui.PrintStatusBox("🔐 NEEDS ELEVATED RIGHTS", "This spell asks for elevated rights, my lord. With no terminal to type a password into, sudo will fail unless the scheduler itself runs as root.", "warning")
+	}
+	if existing := s.Schedules.Find(spell.Name); existing != nil {
+		ui.PrintInfoMessage(fmt.Sprintf("It replaces the spell already scheduled as '%s' to run %s, my lord.", existing.Name, existing.When))
+	}
+
+	choice, err := s.Prompter.Choose(fmt.Sprintf("Schedule '%s' to run %s, unattended?", spell.Name, schedule.Text), []string{"Schedule it to run unattended", "Leave it unscheduled"})
+	if err != nil {
+		return nil, err
+	}
+	if choice != 0 {
+		ui.PrintInfoMessage("Nothing was scheduled, my lord.")
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", s.Dir, err)
+	}
+	if err := os.WriteFile(spell.Script, []byte(script), 0700); err != nil {
+		return nil, fmt.Errorf("failed to write the spell's script: %w", err)
+	}
+	if err := scheduler.Install(spell.Name, schedule, []string{s.Executable, "schedule", "run", spell.Name}); err != nil {
+		os.Remove(spell.Script)
+		return nil, fmt.Errorf("%s did not take the spell, my lord: %w", s.SchedulerKind, err)
+	}
+	s.Schedules.Put(spell)
+	if err := s.Schedules.Save(); err != nil {
+		return nil, err
+	}
+
+	ui.PrintStatusBox("🗓️  SPELL SCHEDULED", fmt.Sprintf("'%s' will run %s through %s, my lord.\n\nScript: %s\nLog: %s", spell.Name, schedule, s.SchedulerKind, spell.Script, spell.Log), "success")
+	return spell, nil
+}
+
+// spell finds the saved spell named by ref, returning it with the script it will run. A number
+// names a quest of the journal, which must have run successfully after being approved; anything
+// else names a function of the library, or of the team library of reviewed shared spells.
+func (s *Scheduling) spell(ref string) (*history.ScheduledSpell, string, error) {
+	if id, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		record := s.Journal.Find(id)
+		switch {
+		case record == nil:
+			return nil, "", fmt.Errorf("quest #%d is not in the journal, my lord; 'execute-my-will history' lists the quests kept", id)
+		case record.Decision != history.DecisionApproved || !record.Executed || record.Failed() || record.Content == "":
+			return nil, "", fmt.Errorf("quest #%d did not run successfully after you approved it, my lord; only such quests can be scheduled", id)
+		case record.Redacted:
+			return nil, "", fmt.Errorf("quest #%d held a credential that was redacted from the journal, my lord, so it cannot be run unattended", id)
+		}
+		spell := &history.ScheduledSpell{Name: system.SuggestFunctionName(record.Intent), Source: fmt.Sprintf("quest #%d", id), Intent: record.Intent, Shell: s.Shell}
+		return spell, system.ApplyShebang(strings.TrimRight(record.Content, "\n")+"\n", s.Shell, "path"), nil
+	}
+
+	definition, source := "", "the function library"
+	for _, path := range []string{s.Library, s.TeamLibrary} {
+		if path == "" {
+			continue
+		}
+		library, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("failed to read the function library: %w", err)
+		}
+		if definition = system.FunctionDefinition(string(library), ref); definition != "" {
+			break
+		}
+		source = "the team spellbook"
+	}
+	if definition == "" {
+		return nil, "", fmt.Errorf("there is no spell called '%s' in %s, my lord; save one with 'execute-my-will distill', review your team's with 'execute-my-will spellbook sync', or give the number of a quest from 'execute-my-will history'", ref, s.Library)
+	}
+	// The library's functions are POSIX shell
+	shell := s.Shell
+	if system.ShellFamily(shell) != system.ShellFamilyPOSIX {
+		shell = "/bin/sh"
+	}
+	spell := &history.ScheduledSpell{Name: ref, Source: source, Shell: shell}
+	return spell, system.ApplyShebang(definition+"\n"+ref+"\n", shell, "path"), nil
+}
+
+// Remove stops running the named spell: its job is removed from the scheduler it was installed
+// with, and its script deleted. Its log is kept.
+func (s *Scheduling) Remove(name string) error {
+	spell := s.Schedules.Find(name)
+	if spell == nil {
+		return fmt.Errorf("no spell called '%s' is scheduled, my lord; 'execute-my-will schedule list' shows them", name)
+	}
+	scheduler, err := s.NewScheduler(spell.Scheduler)
+	if err != nil {
+		return err
+	}
+	if err := scheduler.Remove(name); err != nil {
+		return fmt.Errorf("%s did not release the spell, my lord: %w", spell.Scheduler, err)
+	}
+	if err := os.Remove(spell.Script); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the spell's script: %w", err)
+	}
+	s.Schedules.Remove(name)
+	if err := s.Schedules.Save(); err != nil {
+		return err
+	}
+	ui.PrintStatusBox("🗓️  SPELL UNSCHEDULED", fmt.Sprintf("'%s' will no longer run %s, my lord. Its log remains in %s.", name, spell.When, spell.Log), "success")
+	return nil
+}
+
+// printSchedules lists the scheduled spells
+func printSchedules(schedules *history.Schedules) {
+	if len(schedules.Spells) == 0 {
+		ui.PrintStatusBox("🗓️  NO SCHEDULED SPELLS", "No spell runs on a schedule, my lord. Schedule one with: execute-my-will schedule \"every day at 2am\" <spell>", "info")
+		return
+	}
+	var lines []string
+	for _, spell := range schedules.Spells {
+		lines = append(lines,
+			fmt.Sprintf("%s  %s (%s) via %s", ui.Gold.Sprint(spell.Name), spell.When, spell.Cron, spell.Scheduler),
+			ui.Gray.Sprintf("   from %s, scheduled on %s; log: %s", spell.Source, ui.FormatDate(spell.At), spell.Log))
+	}
+	lines = append(lines, "", ui.Gray.Sprint("Scheduled spells run without confirmation. Stop one with: execute-my-will schedule remove <name>"), "")
+	ui.DefaultTemplate().PrintBox(fmt.Sprintf("🗓️  SCHEDULED SPELLS (%d)", len(schedules.Spells)), lines)
+}
+
+// runScheduledSpell runs a scheduled spell for the system scheduler, appending its output to the
+// spell's log. The kill switch still halts it.
+func runScheduledSpell(cmd *cobra.Command, args []string) error {
+	schedules, err := history.LoadSchedules(config.StatePath(history.SchedulesFile))
+	if err != nil {
+		return err
+	}
+	spell := schedules.Find(args[0])
+	if spell == nil {
+		return fmt.Errorf("no spell called '%s' is scheduled", args[0])
+	}
+
+	logFile, err := os.OpenFile(spell.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the spell's log: %w", err)
+	}
+	defer logFile.Close()
+	fmt.Fprintf(logFile, "=== %s: %s (%s)\n", time.Now().Format(time.RFC3339), spell.Name, spell.When)
+
+	cfg, _ := config.Load()
+	if haltedByKillSwitch(cfg) {
+		fmt.Fprintln(logFile, "=== halted by the kill switch; nothing was run")
+		return nil
+	}
+
+	run := scheduledSpellCommand(spell.Shell, spell.Script)
+	run.Stdout, run.Stderr = logFile, logFile
+	runErr := run.Run()
+	if runErr != nil {
+		fmt.Fprintf(logFile, "=== failed: %v\n", runErr)
+	} else {
+		fmt.Fprintln(logFile, "=== done")
+	}
+	return runErr
+}
+
+// scheduledSpellCommand runs the script of a scheduled spell with its shell
+func scheduledSpellCommand(shell, script string) *exec.Cmd {
+	switch system.ShellFamily(shell) {
+	case system.ShellFamilyPowerShell:
+		return exec.Command(shell, "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", script)
+	case system.ShellFamilyCmd:
+		return exec.Command("cmd", "/C", script)
+	}
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell, script)
+}
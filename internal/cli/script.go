@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/script.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var scriptCmd = &cobra.Command{
+	Use:   "script [intent]",
+	Short: "Ask for a script and save it to a file",
+	Long: `Ask the oracle for a script, even for quests a single command could do, and save it to a file you can keep, review, and run again.
+
+The script is checked and shown like any other proposal, then saved to --output, or to a file named after the intent in the current directory. With --run it is also carried out once you approve it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runScript,
+}
+
+func init() {
+	scriptCmd.Flags().StringP("output", "o", "", "File to save the script to (defaults to a name taken from the intent, e.g. back_up_dotfiles.sh)")
+	scriptCmd.Flags().String("shebang", "env", "Interpreter line of POSIX and fish scripts: env (#!/usr/bin/env bash), path (#!/bin/bash), or none")
+	scriptCmd.Flags().Bool("run", false, "Offer to run the script after saving it")
+	rootCmd.AddCommand(scriptCmd)
+}
+
+// ScriptRequest says how the script of the 'script' subcommand is saved and whether it is run
+type ScriptRequest struct {
+	Output  string // the file to save to; a name taken from the intent when empty
+	Shebang string // one of system.ShebangStyles
+	Run     bool   // go on to confirm and run the script once it is saved
+}
+
+// NewScriptPipeline builds the pipeline of the 'script' subcommand:
+// analyze → validate → docs → generate → airgap → flags → verify → script → review → save → trust → repeat → confirm → reauth → eval → elevate → detach → execute → report → summarize → autofix
+// Commands the knight would resolve without the oracle, such as recipes and remembered quests,
+// are skipped, since a script is asked for. The script is made once the flag and download checks,
+// which may replace the proposal, are done.
+func NewScriptPipeline(deps PipelineDeps) *Pipeline {
+	stages := []Stage{
+		&analyzeStage{analyzer: deps.Analyzer},
+		&validateStage{newValidator: deps.NewIntentValidator, prompter: deps.Prompter},
+	}
+	for _, stage := range questStages(deps) {
+		stages = append(stages, stage)
+		switch stage.Name() {
+		case "verify":
+			stages = append(stages, &scriptStage{})
+		case "review":
+			stages = append(stages, &saveScriptStage{prompter: deps.Prompter})
+		}
+	}
+	return &Pipeline{stages: stages}
+}
+
+// scriptStage turns the oracle's answer into a script: a single command becomes a one-line
+// script, and the interpreter line is set in the requested style
+type scriptStage struct{}
+
+func (s *scriptStage) Name() string { return "script" }
+
+func (s *scriptStage) Run(q *Quest) (bool, error) {
+	if q.Script == nil {
+		return true, nil
+	}
+	q.Content = system.ApplyShebang(q.Content, q.SysInfo.Shell, q.Script.Shebang)
+	q.IsScript = true
+	q.Response = &ai.AIResponse{Type: ai.ResponseTypeScript, Content: q.Content}
+	return true, nil
+}
+
+// saveScriptStage writes the reviewed script to its file, asking before one is replaced. The quest
+// ends there unless the script is to be run as well.
+type saveScriptStage struct {
+	prompter Prompter
+}
+
+func (s *saveScriptStage) Name() string { return "save" }
+
+func (s *saveScriptStage) Run(q *Quest) (bool, error) {
+	if q.Script == nil {
+		return true, nil
+	}
+
+	path := ScriptPath(q.Script.Output, q.Intent, q.SysInfo)
+	if _, err := os.Stat(path); err == nil {
+		if s.prompter == nil {
+			return false, fmt.Errorf("%s already exists, my lord; choose another file with --output", path)
+		}
+		choice, err := s.prompter.Choose(fmt.Sprintf("%s already exists. Replace it?", path), []string{"Replace it", "Keep the existing file"})
+		if err != nil {
+			return false, err
+		}
+		if choice != 0 {
+			ui.PrintInfoMessage("The existing file was left alone, my lord. Choose another with --output.")
+			return false, nil
+		}
+	}
+
+	content := system.NormalizeScript(q.Content, q.SysInfo.Shell)
+	content = strings.ReplaceAll(strings.TrimRight(content, "\n")+"\n", "\n", system.ScriptLineEnding(q.SysInfo.Shell))
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		return false, fmt.Errorf("failed to save the script, my lord: %w", err)
+	}
+
+	message := fmt.Sprintf("The script was saved to %s.\n\nRun it with: %s", path, scriptRunCommand(path, q.SysInfo.Shell))
+	ui.PrintStatusBox("📜 SCRIPT SAVED", message, "success")
+	return q.Script.Run, nil
+}
+
+// ScriptPath is where a script is saved: the output file when given, otherwise a file in the
+// current directory named after the intent, e.g. back_up_dotfiles.sh
+func ScriptPath(output, intent string, sysInfo *system.Info) string {
+	if output != "" {
+		if strings.HasPrefix(output, "~/") && sysInfo.HomeDir != "" {
+			return filepath.Join(sysInfo.HomeDir, output[2:])
+		}
+		return output
+	}
+	return filepath.Join(sysInfo.CurrentDir, system.SuggestFunctionName(intent)+system.ScriptExtension(sysInfo.Shell))
+}
+
+// scriptRunCommand shows how a saved script is run in the shell
+func scriptRunCommand(path, shell string) string {
+	switch system.ShellFamily(shell) {
+	case system.ShellFamilyCmd:
+		return path
+	case system.ShellFamilyPowerShell:
+		return fmt.Sprintf("powershell -File %s", path)
+	}
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, ".") {
+		path = "./" + path
+	}
+	return path
+}
+
+func runScript(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	shebang, _ := cmd.Flags().GetString("shebang")
+	run, _ := cmd.Flags().GetBool("run")
+	if !slices.Contains(system.ShebangStyles, shebang) {
+		return fmt.Errorf("--shebang must be one of %s, my lord", strings.Join(system.ShebangStyles, ", "))
+	}
+
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	contextName, intentContext, intent := cfg.MatchContext(strings.TrimSpace(args[0]))
+	if intent == "" {
+		ui.PrintStatusBox("QUEST REQUIRED", "Please describe the script you need, my lord!\n\nExample:\n  execute-my-will script 'back up my dotfiles to ~/backups with a dated folder'", "info")
+		return nil
+	}
+
+	ui.PrintKnightMessage(ui.Message("quest.received", "intent", intent))
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	aiClient, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+
+	deps := DefaultPipelineDeps(aiClient)
+	deps.Analyzer = newAnalyzer(cfg)
+	deps.Executor = questExecutor(deps.Executor, cfg)
+
+	quest := &Quest{
+		Intent:      intent,
+		Config:      cfg,
+		ContextName: contextName,
+		Context:     intentContext,
+		Script:      &ScriptRequest{Output: output, Shebang: shebang, Run: run},
+	}
+	runErr := NewScriptPipeline(deps).Run(quest)
+
+	_ = NewTranscript(quest, aiClient.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+	rememberQuest(deps.History, quest)
+	recordInJournal(deps.Journal, quest)
+	return runErr
+}
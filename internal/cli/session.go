@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/session.go
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// The sample of a quest's output kept in the session. It is smaller than the one sent with a
+// --then follow-up, since every later quest of the session carries it.
+const (
+	sessionHeadLines    = 15
+	sessionTailLines    = 25
+	sessionNotableLines = 10
+)
+
+// startSession loads the current session when the quest continues it, and starts a new one
+// otherwise. A session that cannot be read, or has lain untouched too long, is started afresh.
+func startSession(path string, continued bool, now time.Time) *history.Session {
+	session, err := history.LoadSession(path)
+	if err != nil {
+		session = history.NewSession(path)
+		if continued {
+			ui.PrintInfoMessage(fmt.Sprintf("The previous session could not be read (%v), my lord; this quest starts a new one.", err))
+		}
+		return session
+	}
+
+	switch {
+	case !continued:
+		session.Reset()
+	case session.Last() == nil:
+		ui.PrintInfoMessage("There is no earlier quest to continue, my lord; this quest starts a new session.")
+	case session.Expired(now):
+		ui.PrintInfoMessage(fmt.Sprintf("The last quest of the session was %s ago, my lord; this quest starts a new session.", ui.FormatDuration(now.Sub(session.Last().At))))
+		session.Reset()
+	default:
+		ui.PrintInfoMessage(fmt.Sprintf("Continuing the session: this quest follows on from %d earlier quest(s), the last being \"%s\".", len(session.Turns), session.Last().Intent))
+	}
+	return session
+}
+
+// rememberInSession adds a quest to the session, so that the next quest can continue from it,
+// and saves the session even when the quest never got a proposal or a refusal to add
+func rememberInSession(session *history.Session, q *Quest) {
+	if session == nil {
+		return
+	}
+	if turn := SessionTurn(q); turn != nil {
+		session.Add(turn)
+	}
+	_ = session.Save()
+}
+
+// SessionTurn describes a quest for the session, with credentials and the output redacted
+func SessionTurn(q *Quest) *history.Turn {
+	turn := &history.Turn{Intent: q.Intent, At: time.Now()}
+	switch {
+	case q.Content != "":
+		turn.Content = outputRedactor(q).Redact(q.Content)
+		turn.IsScript = q.IsScript
+	case q.Response != nil && q.Response.Type == ai.ResponseTypeFailure:
+		turn.Refusal = q.Response.Error
+		return turn
+	default:
+		return nil
+	}
+
+	switch {
+	case !q.Executed:
+		turn.Outcome = "it was not run"
+		return turn
+	case q.Detached != nil:
+		turn.Outcome = "it was started in a detached session and may still be running"
+		return turn
+	case q.ExecErr != nil:
+		attempt := failedAttempt(q, outputRedactor(q))
+		if attempt.ExitCode != nil {
+			turn.Outcome = fmt.Sprintf("it ran and failed with exit code %d (%s)", *attempt.ExitCode, attempt.Error)
+		} else {
+			turn.Outcome = fmt.Sprintf("it ran and failed (%s)", attempt.Error)
+		}
+		// Without a saved log, the output tail kept with the failure is what the oracle learns from
+		turn.Output = attempt.Output
+	default:
+		turn.Outcome = "it ran and succeeded"
+	}
+
+	if q.OutputLog != nil {
+		if sample, err := system.SampleOutputLog(q.OutputLog.Path, sessionHeadLines, sessionTailLines, sessionNotableLines); err == nil {
+			turn.Output = outputRedactor(q).Redact(sample)
+			turn.Lines = q.OutputLog.Lines
+		}
+	}
+	return turn
+}
+
+// SessionMessages writes the quests of a session as the conversation they were: the intent, the
+// oracle's proposal or refusal, and what happened when it ran
+func SessionMessages(session *history.Session) []ai.Message {
+	var messages []ai.Message
+	for _, turn := range session.Turns {
+		messages = append(messages, ai.Message{Role: ai.RoleUser, Content: ai.QuoteUntrusted("EARLIER INTENT", turn.Intent)})
+		if turn.Content == "" {
+			messages = append(messages, ai.RefusalMessage(turn.Refusal))
+			continue
+		}
+		messages = append(messages, ai.ProposalMessage(turn.Content, turn.IsScript))
+
+		result := fmt.Sprintf("RESULT: %s.", turn.Outcome)
+		switch {
+		case turn.Output != "" && turn.Lines > 0:
+			result += fmt.Sprintf(" It printed %d lines:\n%s", turn.Lines, ai.QuoteUntrusted("EARLIER OUTPUT", turn.Output))
+		case turn.Output != "":
+			result += fmt.Sprintf(" Its last output lines were:\n%s", ai.QuoteUntrusted("EARLIER OUTPUT", turn.Output))
+		}
+		messages = append(messages, ai.Message{Role: ai.RoleUser, Content: result})
+	}
+	return messages
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/shell.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Carry out quest after quest in one long-lived shell, where cds and exports last",
+	Long: `Start a session with the knight: type an intent at the prompt and it is carried out like any quest, but every command runs in the same shell. A cd, an export, or an activated virtualenv lasts for the quests after it, and each quest follows on from the ones before it, as with --continue.
+
+Type 'exit' or press Ctrl+D to end the session. Scripts run as a child of the session's shell: they start where it is with its exported variables, but their own changes end with them. Sessions need a POSIX shell such as bash or zsh.`,
+	Example: `  execute-my-will shell
+  execute-my-will shell --shell /bin/bash`,
+	Args: cobra.NoArgs,
+	RunE: runShell,
+}
+
+func init() {
+	shellCmd.Flags().String("shell", "", "The shell to keep the session in (default: your current shell)")
+	rootCmd.AddCommand(shellCmd)
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	shell, _ := cmd.Flags().GetString("shell")
+	if shell == "" {
+		if info, _ := system.NewQuickAnalyzer().AnalyzeSystem(); info != nil {
+			shell = info.Shell
+		}
+	}
+	if system.ShellFamily(shell) != system.ShellFamilyPOSIX {
+		return fmt.Errorf("a session needs a POSIX shell such as bash or zsh, my lord; choose one with --shell")
+	}
+
+	aiClient, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+	deps := DefaultPipelineDeps(aiClient)
+	deps.Analyzer = newAnalyzer(cfg)
+
+	run := &ShellRun{
+		Deps:   deps,
+		Config: cfg,
+		Start: func() (*system.ShellSession, error) {
+			return system.StartShellSession(shell, cfg.Execution.Env)
+		},
+		NewExecutor: func(session *system.ShellSession) system.CommandExecutor {
+			return questExecutor(system.NewSessionExecutor(session), cfg)
+		},
+		Conversation: startSession(config.StatePath(history.SessionFile), false, time.Now()),
+		Finish: func(q *Quest, runErr error) {
+			_ = NewTranscript(q, aiClient.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+		},
+	}
+	_, err = run.Run()
+	return err
+}
+
+// ShellRun carries out the quests typed at its prompt one after another in a long-lived shell
+type ShellRun struct {
+	Deps   PipelineDeps
+	Config *config.Config
+	// Start starts the session's shell, and starts it again when a command ends it
+	Start func() (*system.ShellSession, error)
+	// NewExecutor returns the executor of the quests run in a session shell; by default it is
+	// the plain session executor
+	NewExecutor func(*system.ShellSession) system.CommandExecutor
+	// Conversation holds the earlier quests the oracle hears about; nil keeps none
+	Conversation *history.Session
+	// Finish, when set, is called after every quest
+	Finish func(q *Quest, err error)
+}
+
+// shellExits end a session when typed at its prompt
+var shellExits = map[string]bool{"exit": true, "quit": true, "logout": true}
+
+// Run reads intents until the session is ended and returns how many quests were carried out.
+// The knight follows the session shell from directory to directory, so that each quest is
+// surveyed where the last one left off.
+func (r *ShellRun) Run() (int, error) {
+	session, err := r.Start()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start the session, my lord: %w", err)
+	}
+	defer func() { session.Close() }()
+	ui.PrintStatusBox("🐚 SHELL SESSION", fmt.Sprintf("Every quest of this session runs in %s, so a cd or an export lasts for the quests after it.\n\nType 'exit' or press Ctrl+D to end the session.", session.Shell()), "info")
+
+	executed := 0
+	for {
+		intent, err := r.Deps.Prompter.Ask(fmt.Sprintf("⚔️  %s", session.Dir()), "")
+		if errors.Is(err, io.EOF) {
+			ui.PrintBlankLine()
+			break
+		}
+		if err != nil {
+			return executed, err
+		}
+		intent = strings.TrimSpace(intent)
+		if intent == "" {
+			continue
+		}
+		if shellExits[strings.ToLower(intent)] {
+			break
+		}
+
+		quest := r.quest(intent)
+		if quest == nil {
+			continue
+		}
+		deps := r.Deps
+		if r.NewExecutor != nil {
+			deps.Executor = r.NewExecutor(session)
+		} else {
+			deps.Executor = system.NewSessionExecutor(session)
+		}
+		if r.Conversation != nil && deps.AIClient != nil {
+			deps.AIClient.Continue(SessionMessages(r.Conversation))
+		}
+
+		runErr := NewPipeline(deps).Run(quest)
+		if r.Finish != nil {
+			r.Finish(quest, runErr)
+		}
+		rememberQuest(deps.History, quest)
+		recordInJournal(deps.Journal, quest)
+		rememberInSession(r.Conversation, quest)
+		if quest.Executed {
+			executed++
+		}
+		if runErr != nil {
+			ui.PrintErrorMessage(fmt.Sprintf("The quest has failed, my lord: %v", runErr))
+		}
+
+		// The next quest is surveyed, and the next session shell started, where this one left off
+		if err := os.Chdir(session.Dir()); err != nil {
+			ui.PrintWarningMessage(fmt.Sprintf("Could not follow the session into %s, my lord: %v", session.Dir(), err))
+		}
+		if !session.Alive() {
+			ui.PrintInfoMessage("The session's shell has ended, my lord; a new one takes its place where the last one stopped.")
+			session.Close()
+			if session, err = r.Start(); err != nil {
+				return executed, fmt.Errorf("failed to start the session, my lord: %w", err)
+			}
+		}
+	}
+	ui.PrintKnightMessage("The session is ended, my lord.")
+	return executed, nil
+}
+
+// quest prepares the quest of an intent typed at the session prompt, or returns nil when there is
+// nothing to do
+func (r *ShellRun) quest(intent string) *Quest {
+	quest := &Quest{Intent: intent, Config: r.Config, PersistentShell: true}
+	contextName, intentContext, intent := r.Config.MatchContext(intent)
+	if intent == "" {
+		ui.PrintStatusBox("QUEST REQUIRED", fmt.Sprintf("Please tell me what to do in the '%s' context, my lord!", contextName), "info")
+		return nil
+	}
+	quest.Intent, quest.ContextName, quest.Context = intent, contextName, intentContext
+	return quest
+}
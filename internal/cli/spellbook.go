@@ -0,0 +1,337 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/spellbook.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// spellbookCacheDir holds the clone of a spellbook repository
+const spellbookCacheDir = "spellbook"
+
+var spellbookCmd = &cobra.Command{
+	Use:   "spellbook",
+	Short: "Share vetted spells with your team through a git repository or an HTTP endpoint",
+	Long: `Sync the spells your team shares in a spellbook.yaml, kept in a git repository or served over HTTP. Each spell names its owner and may be signed with an Ed25519 key; spells whose signature does not match are dropped, and with 'require_signatures' so are unsigned ones.
+
+Every shared spell is shown for your review before its first use on this machine, and again whenever it changes. Reviewed spells become shell functions in ~/.config/execute-my-will/team-functions.sh, which you load from your shell's rc file, and can be scheduled like your own.
+
+Configure the spellbook in the config file:
+
+  spellbook:
+    source: git@github.com:your-team/spells.git
+    keys:
+      alice: <base64 public key from 'execute-my-will spellbook keygen'>
+    require_signatures: true`,
+}
+
+var spellbookSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch the team's spellbook and review its new and changed spells",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sync, err := newSpellbookSync()
+		if err != nil {
+			return err
+		}
+		return sync.Sync()
+	},
+}
+
+var spellbookReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review the shared spells left unreviewed at the last sync",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sync, err := newSpellbookSync()
+		if err != nil {
+			return err
+		}
+		_, err = sync.Review()
+		return err
+	},
+}
+
+var spellbookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the shared spells of the last sync",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := system.LoadSpellbookState(config.StatePath(system.SpellbookStateFile))
+		if err != nil {
+			return err
+		}
+		printSharedSpells(state)
+		return nil
+	},
+}
+
+var spellbookSignCmd = &cobra.Command{
+	Use:   "sign <spellbook.yaml>",
+	Short: "Sign every spell of a spellbook with your private key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSpellbookSign,
+}
+
+var spellbookKeygenCmd = &cobra.Command{
+	Use:   "keygen <private-key-file>",
+	Short: "Create a key pair for signing spells",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSpellbookKeygen,
+}
+
+func init() {
+	spellbookSignCmd.Flags().String("key", "", "File holding your private key, from 'spellbook keygen'")
+	spellbookSignCmd.Flags().String("signer", "", "Your name as listed under the spellbook's keys")
+	_ = spellbookSignCmd.MarkFlagRequired("key")
+	_ = spellbookSignCmd.MarkFlagRequired("signer")
+	spellbookCmd.AddCommand(spellbookSyncCmd, spellbookReviewCmd, spellbookListCmd, spellbookSignCmd, spellbookKeygenCmd)
+	rootCmd.AddCommand(spellbookCmd)
+}
+
+// newSpellbookSync gathers what syncing the configured spellbook needs
+func newSpellbookSync() (*SpellbookSync, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	state, err := system.LoadSpellbookState(config.StatePath(system.SpellbookStateFile))
+	if err != nil {
+		return nil, err
+	}
+	return &SpellbookSync{
+		Config: cfg.Spellbook,
+		Fetch: func(source string) ([]byte, error) {
+			return system.FetchSpellbook(source, config.StatePath(spellbookCacheDir))
+		},
+		State:    state,
+		Prompter: newStdinConsole(os.Stdin),
+		Library:  config.StatePath(system.TeamFunctionsFile),
+		Now:      time.Now,
+	}, nil
+}
+
+// SpellbookSync brings the team's shared spells to this machine, each reviewed before first use
+type SpellbookSync struct {
+	Config   config.SpellbookConfig
+	Fetch    func(source string) ([]byte, error)
+	State    *system.SpellbookState
+	Prompter Prompter
+	Library  string // the team library the reviewed spells are written to
+	Now      func() time.Time
+}
+
+// Sync fetches the spellbook, keeps the spells whose signatures hold up, and offers the new and
+// changed ones for review
+func (s *SpellbookSync) Sync() error {
+	if !s.Config.Enabled() {
+		ui.PrintStatusBox("📖 NO SPELLBOOK", "No shared spellbook is configured, my lord. Point at your team's in the config file:\n\nspellbook:\n  source: git@github.com:your-team/spells.git\n  keys:\n    alice: <base64 public key>\n  require_signatures: true", "info")
+		return nil
+	}
+	keys, err := system.ParsePublicKeys(s.Config.Keys)
+	if err != nil {
+		return fmt.Errorf("%w, my lord; check 'spellbook.keys' in the config file", err)
+	}
+	source := s.Config.SourcePath()
+	data, err := s.Fetch(source)
+	if err != nil {
+		return fmt.Errorf("the spellbook could not be fetched, my lord: %w", err)
+	}
+	book, err := system.ParseSpellbook(data)
+	if err != nil {
+		return fmt.Errorf("%w, my lord", err)
+	}
+
+	previous := make(map[string]string)
+	for _, spell := range s.State.Spells {
+		previous[spell.Name] = spell.Hash()
+	}
+	var kept []system.SharedSpellState
+	var dropped []string
+	added, changed := 0, 0
+	for _, spell := range book.Spells {
+		trust := spell.Verify(keys)
+		switch {
+		case trust == system.SpellBadSignature:
+			dropped = append(dropped, fmt.Sprintf("%s: its signature by '%s' does not match it", spell.Name, spell.Signer))
+			continue
+		case trust != system.SpellSigned && s.Config.RequireSignatures:
+			dropped = append(dropped, fmt.Sprintf("%s: %s, and signatures are required", spell.Name, trust))
+			continue
+		}
+		kept = append(kept, system.SharedSpellState{SharedSpell: spell, Trust: trust})
+		if hash, ok := previous[spell.Name]; !ok {
+			added++
+		} else if hash != spell.Hash() {
+			changed++
+		}
+		delete(previous, spell.Name)
+	}
+	for name := range previous {
+		delete(s.State.Reviewed, name)
+	}
+
+	s.State.Source, s.State.SyncedAt, s.State.Spells = source, s.Now(), kept
+	if err := s.State.Save(); err != nil {
+		return err
+	}
+	message := fmt.Sprintf("%d spells from %s, my lord: %d new, %d changed, %d removed.", len(kept), source, added, changed, len(previous))
+	if len(dropped) > 0 {
+		message += "\n\nSet aside, and never offered:\n- " + strings.Join(dropped, "\n- ")
+	}
+	status := "success"
+	if len(dropped) > 0 {
+		status = "warning"
+	}
+	ui.PrintStatusBox("📖 SPELLBOOK SYNCED", message, status)
+
+	_, err = s.Review()
+	return err
+}
+
+// Review shows each shared spell not yet reviewed as it is now and asks whether to trust it, then
+// writes the team library of the trusted ones. It returns how many spells were trusted.
+func (s *SpellbookSync) Review() (int, error) {
+	trusted := 0
+	for _, spell := range s.State.Pending() {
+		lines := []string{fmt.Sprintf("Owner: %s", spell.Owner), spellTrustLine(spell)}
+		if spell.Description != "" {
+			lines = append([]string{spell.Description, ""}, lines...)
+		}
+		if _, reviewed := s.State.Reviewed[spell.Name]; reviewed {
+			lines = append(lines, "", "It changed since you last approved it, and is left out of the team library until you approve it again.")
+		}
+		ui.PrintStatusBox(fmt.Sprintf("📜 SHARED SPELL '%s'", spell.Name), strings.Join(lines, "\n"), "info")
+		ui.PrintScriptBox(spell.Name, strings.Split(strings.TrimRight(spell.Script, "\n"), "\n"))
+		if contentRisk(spell.Script, true) == system.RiskDestructive {
+			ui.PrintStatusBox("💀 DESTRUCTIVE SPELL", "This spell destroys data, my lord. Read every line before you trust it.", "warning")
+		}
+
+		choice, err := s.Prompter.Choose(fmt.Sprintf("Trust '%s' on this machine, my lord?", spell.Name), []string{
+			"Trust it and add it to the team library",
+			"Not now; ask again on the next review",
+			"Stop reviewing",
+		})
+		if err != nil {
+			return trusted, err
+		}
+		if choice == 2 {
+			break
+		}
+		if choice == 0 {
+			s.State.MarkReviewed(&spell.SharedSpell)
+			trusted++
+		}
+	}
+
+	if err := s.State.Save(); err != nil {
+		return trusted, err
+	}
+	if err := os.WriteFile(s.Library, []byte(s.State.TeamLibrary(s.Library)), 0644); err != nil {
+		return trusted, fmt.Errorf("failed to write the team library: %w", err)
+	}
+	pending := len(s.State.Pending())
+	message := fmt.Sprintf("%d shared spells are in %s, my lord.", len(s.State.Spells)-pending, s.Library)
+	if pending > 0 {
+		message += fmt.Sprintf(" %d await your review; run 'execute-my-will spellbook review' when you are ready.", pending)
+	}
+	message += fmt.Sprintf("\n\nLoad the library from your shell's rc file (e.g. ~/.bashrc or ~/.zshrc) with:\n    . %s", s.Library)
+	ui.PrintStatusBox("📚 TEAM LIBRARY", message, "info")
+	return trusted, nil
+}
+
+// spellTrustLine says who vouches for a shared spell
+func spellTrustLine(spell *system.SharedSpellState) string {
+	switch spell.Trust {
+	case system.SpellSigned:
+		return fmt.Sprintf("✅ Signed by %s", spell.Signer)
+	case system.SpellUnknownKey:
+		return fmt.Sprintf("⚠️  Signed by '%s', whose key is not listed in your config; the signature proves nothing", spell.Signer)
+	default:
+		return "⚠️  Unsigned; only the spellbook vouches for it"
+	}
+}
+
+// printSharedSpells lists the shared spells of the last sync
+func printSharedSpells(state *system.SpellbookState) {
+	if len(state.Spells) == 0 {
+		ui.PrintStatusBox("📖 NO SHARED SPELLS", "No shared spells have been synced, my lord. Configure a spellbook and run 'execute-my-will spellbook sync'.", "info")
+		return
+	}
+	var lines []string
+	for i := range state.Spells {
+		spell := &state.Spells[i]
+		status := ui.Green.Sprint("✓ reviewed")
+		if !state.IsReviewed(&spell.SharedSpell) {
+			status = ui.Gold.Sprint("– awaits review")
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s  %s", ui.Gold.Sprint(spell.Name), status, ui.Gray.Sprintf("(%s; %s)", spell.Owner, spell.Trust)))
+		if spell.Description != "" {
+			lines = append(lines, ui.Gray.Sprint("   "+spell.Description))
+		}
+	}
+	lines = append(lines, "", ui.Gray.Sprintf("Synced from %s on %s", state.Source, ui.FormatTimestamp(state.SyncedAt)), "")
+	ui.DefaultTemplate().PrintBox(fmt.Sprintf("📖 SHARED SPELLS (%d)", len(state.Spells)), lines)
+}
+
+func runSpellbookSign(cmd *cobra.Command, args []string) error {
+	keyFile, _ := cmd.Flags().GetString("key")
+	signer, _ := cmd.Flags().GetString("signer")
+	encoded, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the private key: %w", err)
+	}
+	key, err := system.ParsePrivateKey(string(encoded))
+	if err != nil {
+		return fmt.Errorf("%s is %w, my lord", keyFile, err)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read the spellbook: %w", err)
+	}
+	book, err := system.ParseSpellbook(data)
+	if err != nil {
+		return fmt.Errorf("%w, my lord", err)
+	}
+	for i := range book.Spells {
+		book.Spells[i].Sign(signer, key)
+	}
+	signed, err := yaml.Marshal(book)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the spellbook: %w", err)
+	}
+	if err := os.WriteFile(args[0], signed, 0644); err != nil {
+		return fmt.Errorf("failed to write the spellbook: %w", err)
+	}
+	ui.PrintStatusBox("🔏 SPELLBOOK SIGNED", fmt.Sprintf("%d spells in %s now carry the signature of %s, my lord.", len(book.Spells), args[0], signer), "success")
+	return nil
+}
+
+func runSpellbookKeygen(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(args[0]); err == nil {
+		return fmt.Errorf("%s already exists, my lord; I will not overwrite a key", args[0])
+	}
+	public, private, err := system.GenerateSigningKey()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(args[0], []byte(private+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write the private key: %w", err)
+	}
+	ui.PrintStatusBox("🔑 SIGNING KEY CREATED", fmt.Sprintf("Your private key is in %s, my lord; keep it to yourself.\n\nShare the public key with your team, to list in their config files:\n\nspellbook:\n  keys:\n    <your name>: %s", args[0], public), "success")
+	return nil
+}
@@ -0,0 +1,1619 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/stages.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/calc"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// calculateStage answers arithmetic, unit, and time zone questions on the spot, without
+// surveying the system or consulting the oracle
+type calculateStage struct {
+	now func() time.Time
+}
+
+func (s *calculateStage) Name() string { return "calculate" }
+
+func (s *calculateStage) Run(q *Quest) (bool, error) {
+	answer, ok := calc.Solve(q.Intent, s.now())
+	if !ok {
+		return true, nil
+	}
+
+	message := answer.Result
+	if answer.Note != "" {
+		message += "\n\n" + answer.Note
+	}
+	ui.PrintStatusBox("🧮 THE KNIGHT'S RECKONING", message+"\n\nNo command was needed for this one, my lord.", "info")
+	return false, nil
+}
+
+// analyzeStage inspects the realm's systems
+type analyzeStage struct {
+	analyzer system.SystemAnalyzer
+}
+
+func (s *analyzeStage) Name() string { return "analyze" }
+
+func (s *analyzeStage) Run(q *Quest) (bool, error) {
+	ui.PrintPhaseHeader("🧙", "Consulting with the ancient oracles...")
+
+	sysInfo, err := s.analyzer.AnalyzeSystem()
+	if err != nil {
+		return false, fmt.Errorf("failed to analyze the realm's systems, my lord: %w", err)
+	}
+	q.SysInfo = sysInfo
+	if q.Debug {
+		printAnalysisTimings(sysInfo.Timings)
+	}
+	return true, nil
+}
+
+// oracleFailure describes an error of the oracle's client, with advice for the kinds of error
+// that have some
+func oracleFailure(err error) error {
+	switch {
+	case errors.Is(err, ai.ErrProviderUnavailable):
+		return fmt.Errorf("the oracles cannot be reached, sire; try again in a while, or check the network connection: %w", err)
+	case errors.Is(err, ai.ErrParseFailure):
+		return fmt.Errorf("the oracles answered in a tongue I cannot read, sire; asking again may help: %w", err)
+	default:
+		return fmt.Errorf("the oracles have failed us, sire: %w", err)
+	}
+}
+
+// questionStage answers intents phrased as questions, such as "what does chmod 755 mean?", in
+// an info box instead of forcing a command. Questions about this machine go on to generation.
+type questionStage struct {
+	client ai.Client
+}
+
+func (s *questionStage) Name() string { return "question" }
+
+func (s *questionStage) Run(q *Quest) (bool, error) {
+	if (q.Config != nil && q.Config.Execution.CommandsOnly) || !system.IsQuestionIntent(q.Intent) {
+		return true, nil
+	}
+	if haltedByKillSwitch(q.Config) {
+		return false, nil
+	}
+
+	response, err := s.client.AnswerQuestion(q.PromptIntent(), q.SysInfo)
+	if err != nil {
+		return false, oracleFailure(err)
+	}
+	if response.Type != ai.ResponseTypeAnswer {
+		return true, nil
+	}
+	q.Response = response
+	ui.PrintStatusBox("📜 THE KNIGHT'S ANSWER", response.Content+"\n\nNo command was needed for this one, my lord.", "info")
+	return false, nil
+}
+
+// maxPathSuggestions caps the existing paths suggested for one that does not exist
+const maxPathSuggestions = 3
+
+// maxPathAmendments bounds how many missing paths one intent may have replaced before giving up
+const maxPathAmendments = 5
+
+// validateStage checks the intent before any oracle is consulted. A path that does not exist may be
+// replaced by a similarly named one the user picks.
+type validateStage struct {
+	newValidator func(*system.Info) system.IntentValidator
+	prompter     Prompter
+}
+
+func (s *validateStage) Name() string { return "validate" }
+
+func (s *validateStage) Run(q *Quest) (bool, error) {
+	validator := s.newValidator(q.SysInfo)
+	for amendments := 0; ; amendments++ {
+		err := validator.ValidateIntent(q.Intent)
+		if err == nil {
+			break
+		}
+		amended, err := s.amendPath(q, err, amendments < maxPathAmendments)
+		if err != nil || !amended {
+			return false, err
+		}
+	}
+
+	// The quest is still sent, as clearly marked data, and every proposal still needs approval
+	if findings := ai.DetectPromptInjection(q.PromptIntent()); len(findings) > 0 {
+		ui.PrintStatusBox("🕵️  SUSPICIOUS INSTRUCTIONS", fmt.Sprintf("Part of this quest reads like orders to the oracle rather than a task (%s).\n\nIt will be passed along only as a description of your quest. Review the proposal with care, my lord.", strings.Join(findings, ", ")), "warning")
+	}
+	return true, nil
+}
+
+// amendPath asks for clarification of an intent that failed validation. For a path that does not
+// exist it suggests similar ones and, when one is chosen, puts it in the intent instead.
+func (s *validateStage) amendPath(q *Quest, validationErr error, canAmend bool) (bool, error) {
+	message := fmt.Sprintf("Forgive me sire, but your request needs clarification: %s", validationErr.Error())
+	var missing *system.MissingPathError
+	var suggestions []string
+	if errors.As(validationErr, &missing) && q.SysInfo != nil {
+		suggestions = system.SimilarPaths(missing.Path, q.SysInfo.CurrentDir, q.SysInfo.HomeDir, maxPathSuggestions)
+	}
+	if len(suggestions) == 1 {
+		message += fmt.Sprintf("\n\n💡 Did you mean %s?", suggestions[0])
+	} else if len(suggestions) > 1 {
+		message += "\n\n💡 Did you mean one of these?\n  • " + strings.Join(suggestions, "\n  • ")
+	}
+	ui.PrintStatusBox("⚠️  REQUEST CLARIFICATION NEEDED", message, "warning")
+	if len(suggestions) == 0 || s.prompter == nil || !canAmend {
+		return false, nil
+	}
+
+	options := append(slices.Clone(suggestions), "None of these, abandon the quest")
+	choice, err := s.prompter.Choose(fmt.Sprintf("Use another path instead of %s?", missing.Path), options)
+	if err != nil {
+		return false, err
+	}
+	if choice >= len(suggestions) {
+		return false, nil
+	}
+	q.Intent = strings.Replace(q.Intent, missing.Path, suggestions[choice], 1)
+	ui.PrintInfoMessage(fmt.Sprintf("Your quest now reads: %s", q.Intent))
+	return true, nil
+}
+
+// processesStage lists the running processes an intent is about, such as "kill the stuck node
+// process", so that the oracle can target the right PID or name instead of guessing
+type processesStage struct {
+	list func() ([]system.ProcessInfo, error)
+}
+
+func (s *processesStage) Name() string { return "processes" }
+
+func (s *processesStage) Run(q *Quest) (bool, error) {
+	if s.list == nil || q.SysInfo == nil || q.SysInfo.Quick || !system.IsProcessIntent(q.Intent) {
+		return true, nil
+	}
+	if q.Config != nil && !q.Config.Privacy.AllowProcesses() {
+		return true, nil
+	}
+
+	processes, err := s.list()
+	if err != nil {
+		// The oracle can still answer without the list
+		ui.PrintWarningMessage(fmt.Sprintf("Could not survey the running processes: %v", err))
+		return true, nil
+	}
+	report := system.NewProcessReport(processes, q.Intent)
+	if report.Matched {
+		ui.PrintInfoMessage(fmt.Sprintf("Found %d running processes that match your quest.", report.Total))
+	}
+	q.SysInfo.Processes = report
+	return true, nil
+}
+
+// transferStage resolves file transfer intents with a short wizard so that the transfer
+// command uses real local paths and configured SSH hosts instead of guessed ones
+type transferStage struct {
+	prompter Prompter
+}
+
+func (s *transferStage) Name() string { return "transfer" }
+
+func (s *transferStage) Run(q *Quest) (bool, error) {
+	// The wizard asks questions, which a quest that is only proposed never does
+	if s.prompter == nil || q.ProposalOnly() {
+		return true, nil
+	}
+
+	hosts := q.SysInfo.SSHHosts
+	if len(hosts) == 0 || !system.IsTransferIntent(q.Intent, hosts) {
+		return true, nil
+	}
+
+	ui.PrintPhaseHeader("📦", "This looks like a transfer quest. Let us settle the details...")
+
+	content, err := s.resolve(q, hosts)
+	if err != nil {
+		ui.PrintStatusBox("⚠️  TRANSFER NOT RESOLVED", fmt.Sprintf("%v\n\nI shall ask the oracles instead.", err), "warning")
+		return true, nil
+	}
+	if content != "" {
+		q.Content = content
+		q.IsScript = false
+		q.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: content}
+	}
+	return true, nil
+}
+
+// resolve runs the wizard and returns the transfer command, or an empty string when the user
+// would rather let the oracle work it out
+func (s *transferStage) resolve(q *Quest, hosts []string) (string, error) {
+	host := system.MentionedHost(q.Intent, hosts)
+	if host != "" {
+		ui.PrintInfoMessage(fmt.Sprintf("Using the host '%s' from your SSH config.", host))
+	} else {
+		options := append([]string{"Let the oracle work it out"}, hosts...)
+		choice, err := s.prompter.Choose("Which host from your SSH config?", options)
+		if err != nil {
+			return "", err
+		}
+		if choice == 0 {
+			return "", nil
+		}
+		host = hosts[choice-1]
+	}
+
+	guessed := system.GuessTransferDirection(q.Intent)
+	directions := []string{"Send local files to " + host, "Fetch files from " + host}
+	if guessed == system.TransferDownload {
+		directions[0], directions[1] = directions[1], directions[0]
+	}
+	choice, err := s.prompter.Choose("Which way shall the goods travel?", directions)
+	if err != nil {
+		return "", err
+	}
+	direction := guessed
+	if choice == 1 {
+		if guessed == system.TransferDownload {
+			direction = system.TransferUpload
+		} else {
+			direction = system.TransferDownload
+		}
+	}
+
+	localPath, err := s.chooseLocalPath(q, direction)
+	if err != nil {
+		return "", err
+	}
+	if localPath == "" {
+		return "", fmt.Errorf("no local path was given, my lord")
+	}
+
+	remotePath, err := s.prompter.Ask(fmt.Sprintf("Path on %s", host), "~/")
+	if err != nil {
+		return "", err
+	}
+
+	info, statErr := os.Stat(localPath)
+	isDir := statErr == nil && info.IsDir()
+	useRsync := containsCommand(q.SysInfo.AvailableCommands, "rsync")
+
+	return system.BuildTransferCommand(useRsync, direction, localPath, host, remotePath, isDir), nil
+}
+
+func (s *transferStage) chooseLocalPath(q *Quest, direction system.TransferDirection) (string, error) {
+	if direction == system.TransferDownload {
+		return s.prompter.Ask("Local destination", q.SysInfo.CurrentDir)
+	}
+
+	candidates := system.FindTransferCandidates(q.Intent, q.SysInfo.CurrentDir, q.SysInfo.HomeDir)
+	if len(candidates) == 0 {
+		return s.prompter.Ask("Local path to send", "")
+	}
+
+	options := append(append([]string{}, candidates...), "Another path...")
+	choice, err := s.prompter.Choose("Which local files shall be sent?", options)
+	if err != nil {
+		return "", err
+	}
+	if choice < len(candidates) {
+		return candidates[choice], nil
+	}
+	return s.prompter.Ask("Local path to send", "")
+}
+
+func containsCommand(commands []string, name string) bool {
+	for _, command := range commands {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicatesStage finds duplicate files with a native scan of sizes and hashes instead of a
+// generated find and md5sum pipeline, which breaks on unusual file names and differs per platform.
+// Finding them answers the quest; deleting them becomes a checklist of the extra copies, and other
+// quests go to the oracle with the exact groups.
+type duplicatesStage struct {
+	prompter Prompter
+}
+
+func (s *duplicatesStage) Name() string { return "duplicates" }
+
+func (s *duplicatesStage) Run(q *Quest) (bool, error) {
+	if q.Content != "" || q.SysInfo == nil || !system.IsDuplicateIntent(q.Intent) {
+		return true, nil
+	}
+
+	root := system.DuplicateRoot(q.Intent, q.SysInfo.CurrentDir, q.SysInfo.HomeDir)
+	ui.PrintPhaseHeader("🔍", fmt.Sprintf("Searching %s for duplicates by size and content...", root))
+	bar := ui.NewProgressBar("Comparing")
+	report, err := system.FindDuplicates(root, system.DuplicateExtensions(q.Intent), bar.Update)
+	bar.Finish()
+	if err != nil {
+		// The oracle can still answer without the scan
+		ui.PrintWarningMessage(fmt.Sprintf("Could not scan for duplicates: %v", err))
+		return true, nil
+	}
+	if len(report.Groups) == 0 {
+		ui.PrintStatusBox("🗂️  NO DUPLICATES", report.Summary()+"\n\nNo command was needed for this one, my lord.", "info")
+		return false, nil
+	}
+	ui.DefaultTemplate().PrintBox("🗂️  DUPLICATE FILES", append([]string{report.Summary(), ""}, report.Lines()...))
+
+	switch {
+	case system.IsDuplicateDeletionIntent(q.Intent) && s.prompter != nil && !q.ProposalOnly():
+		extras := report.Extras()
+		if len(extras) > system.MaxCleanupCandidates {
+			extras = extras[:system.MaxCleanupCandidates]
+			ui.PrintInfoMessage(fmt.Sprintf("Only the first %d copies are shown, my lord.", system.MaxCleanupCandidates))
+		}
+		return chooseDeletions(s.prompter, q, extras, "Which copies shall be deleted? The first file of each group is kept.")
+	case system.IsDuplicateActionIntent(q.Intent):
+		q.Duplicates = report
+		return true, nil
+	}
+	ui.PrintInfoMessage("No command was needed for this one, my lord.")
+	return false, nil
+}
+
+// cleanupStage lists the files a deletion quest refers to with a read-only command, lets the user
+// pick which ones go, and proposes a command that deletes exactly those
+type cleanupStage struct {
+	client   ai.Client
+	prompter Prompter
+}
+
+func (s *cleanupStage) Name() string { return "cleanup" }
+
+func (s *cleanupStage) Run(q *Quest) (bool, error) {
+	if s.prompter == nil || q.Content != "" || q.ProposalOnly() || !system.IsCleanupIntent(q.Intent) {
+		return true, nil
+	}
+
+	ui.PrintPhaseHeader("🧹", "This looks like a cleanup quest. Let us see what would be swept away...")
+
+	paths, err := s.findCandidates(q)
+	if err != nil {
+		ui.PrintStatusBox("⚠️  CANDIDATES NOT LISTED", fmt.Sprintf("%v\n\nI shall ask the oracles for the whole quest instead.", err), "warning")
+		return true, nil
+	}
+	if len(paths) == 0 {
+		ui.PrintStatusBox("🧹 NOTHING TO CLEAN", "Nothing in the realm matches your quest, my lord. Nothing was deleted.", "info")
+		return false, nil
+	}
+	if len(paths) == system.MaxCleanupCandidates {
+		ui.PrintInfoMessage(fmt.Sprintf("Only the first %d candidates are shown, my lord.", system.MaxCleanupCandidates))
+	}
+
+	return chooseDeletions(s.prompter, q, paths, "Which of these shall be deleted?")
+}
+
+// chooseDeletions offers the paths as a checklist and makes the quest a command that deletes
+// only the ones ticked
+func chooseDeletions(prompter Prompter, q *Quest, paths []string, question string) (bool, error) {
+	labels := make([]string, 0, len(paths))
+	for _, path := range paths {
+		labels = append(labels, candidateLabel(path, q.SysInfo.CurrentDir))
+	}
+	chosen, err := prompter.ChooseMany(question, labels)
+	if err != nil {
+		return false, err
+	}
+	if len(chosen) == 0 {
+		ui.PrintStatusBox("🛡️  NOTHING CHOSEN", "Nothing was chosen, so nothing shall be deleted, my lord.", "info")
+		return false, nil
+	}
+
+	selected := make([]string, 0, len(chosen))
+	for _, i := range chosen {
+		selected = append(selected, paths[i])
+	}
+	q.Content = system.BuildDeleteCommand(q.SysInfo.Shell, selected)
+	q.IsScript = false
+	q.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: q.Content}
+	return true, nil
+}
+
+// findCandidates asks the oracle for a listing command and runs it once it is known to be read-only
+func (s *cleanupStage) findCandidates(q *Quest) ([]string, error) {
+	response, err := s.client.ListCleanupCandidates(q.PromptIntent(), q.SysInfo)
+	if err != nil {
+		return nil, err
+	}
+	if response.Type != ai.ResponseTypeCommand {
+		return nil, fmt.Errorf("the oracles could not list the files: %s", response.Error)
+	}
+	if !system.IsSafeListing(response.Content) {
+		return nil, fmt.Errorf("the proposed listing is not read-only, so it was not run: %s", response.Content)
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("Listing the candidates with: %s", response.Content))
+	return system.ListCleanupCandidates(q.SysInfo.Shell, response.Content, q.SysInfo.CurrentDir)
+}
+
+// candidateLabel shows a candidate relative to the current directory when it lies within it
+func candidateLabel(path, currentDir string) string {
+	label := path
+	if rel, err := filepath.Rel(currentDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		label = rel
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		label += string(filepath.Separator)
+	}
+	return label
+}
+
+// recipeStage answers everyday quests with a vetted recipe when the intent clearly asks for one,
+// so they get the same reviewed command every time instead of a generated one
+type recipeStage struct {
+	prompter Prompter
+}
+
+func (s *recipeStage) Name() string { return "recipe" }
+
+func (s *recipeStage) Run(q *Quest) (bool, error) {
+	if q.Content != "" || q.SysInfo == nil {
+		return true, nil
+	}
+	match := system.MatchRecipe(q.Intent, q.SysInfo)
+	if match == nil {
+		return true, nil
+	}
+
+	values := make(map[string]string, len(match.Recipe.Params))
+	for _, param := range match.Recipe.Params {
+		value := system.FindRecipeParam(param, q.Intent, q.SysInfo.CurrentDir, q.SysInfo.HomeDir)
+		if value == "" && param.Default != "" {
+			value = system.ExpandRecipeDefault(param.Default, values)
+		}
+		if value == "" {
+			// A quest that is only proposed asks nothing, so it runs unattended, e.g. in CI
+			if s.prompter == nil || q.ProposalOnly() {
+				return true, nil
+			}
+			answer, err := s.prompter.Ask(fmt.Sprintf("%s (leave empty to ask the oracle instead)", param.Prompt), "")
+			if err != nil {
+				return false, err
+			}
+			if answer == "" {
+				return true, nil
+			}
+			value = answer
+		}
+		values[param.Name] = value
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("Using the vetted recipe '%s': %s.", match.Recipe.Name, strings.ToLower(match.Recipe.Description)))
+	q.Content = system.BuildRecipeCommand(match.Variant, values)
+	q.IsScript = false
+	q.Recipe = match.Recipe.Name
+	q.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: q.Content}
+	return true, nil
+}
+
+// recallStage offers a command that was run for a similar quest before, saving a trip to the oracle
+type recallStage struct {
+	history  *history.Store
+	prompter Prompter
+}
+
+func (s *recallStage) Name() string { return "recall" }
+
+func (s *recallStage) Run(q *Quest) (bool, error) {
+	// The files a cleanup quest deletes change from one run to the next
+	if s.history == nil || s.prompter == nil || q.Content != "" || q.ProposalOnly() || system.IsCleanupIntent(q.Intent) {
+		return true, nil
+	}
+
+	entry := s.history.FindSimilar(q.Intent, q.ContextName)
+	if entry == nil {
+		return true, nil
+	}
+
+	times := "once"
+	if entry.Runs > 1 {
+		times = fmt.Sprintf("%d times", entry.Runs)
+	}
+	what := fmt.Sprintf("'%s'", entry.Content)
+	if entry.IsScript {
+		what = fmt.Sprintf("a %d-line script", len(strings.Split(strings.TrimSpace(entry.Content), "\n")))
+	}
+	ui.PrintInfoMessage(fmt.Sprintf("Your earlier quest \"%s\" was carried out %s.", entry.Intent, times))
+
+	question := fmt.Sprintf("You previously ran %s for a similar quest — reuse it? (saves tokens)", what)
+	choice, err := s.prompter.Choose(question, []string{"Reuse it", "Ask the oracle anew"})
+	if err != nil {
+		return false, err
+	}
+	if choice != 0 {
+		return true, nil
+	}
+
+	responseType := ai.ResponseTypeCommand
+	if entry.IsScript {
+		responseType = ai.ResponseTypeScript
+	}
+	q.Content = entry.Content
+	q.IsScript = entry.IsScript
+	q.Recalled = true
+	q.Response = &ai.AIResponse{Type: responseType, Content: entry.Content}
+	return true, nil
+}
+
+// docsStage grounds the oracle in the installed tools' own documentation, from the optional local
+// index built with 'execute-my-will docs', so that it uses flags that really exist
+type docsStage struct {
+	load func() (*system.DocsIndex, error)
+}
+
+func (s *docsStage) Name() string { return "docs" }
+
+func (s *docsStage) Run(q *Quest) (bool, error) {
+	if s.load == nil || q.Content != "" || q.Docs != "" {
+		return true, nil
+	}
+	index, err := s.load()
+	if err != nil {
+		// The oracle can still answer from memory
+		ui.PrintWarningMessage(fmt.Sprintf("Could not read the documentation index: %v", err))
+		return true, nil
+	}
+	if index == nil {
+		return true, nil
+	}
+	if q.Docs = index.Grounding(q.Intent); q.Docs != "" {
+		ui.PrintInfoMessage(fmt.Sprintf("Consulting the scrolls of %s.", strings.Join(index.GroundedTools(q.Intent), ", ")))
+	}
+	return true, nil
+}
+
+// generateStage asks the oracle for a command or script
+type generateStage struct {
+	client ai.Client
+}
+
+func (s *generateStage) Name() string { return "generate" }
+
+func (s *generateStage) Run(q *Quest) (bool, error) {
+	// An earlier stage may already have resolved the quest without the oracle
+	if q.Content != "" {
+		return true, nil
+	}
+	if haltedByKillSwitch(q.Config) {
+		return false, nil
+	}
+
+	var response *ai.AIResponse
+	var err error
+	if display := ui.NewStreamDisplay("The oracle is writing..."); display.Enabled() && (q.Config == nil || q.Config.UI.StreamAnswers()) {
+		response, err = s.client.StreamResponse(q.PromptIntent(), q.SysInfo, display)
+	} else {
+		response, err = s.client.GenerateResponse(q.PromptIntent(), q.SysInfo)
+	}
+	if err != nil {
+		return false, oracleFailure(err)
+	}
+	q.Response = response
+
+	switch response.Type {
+	case ai.ResponseTypeFailure:
+		message := fmt.Sprintf("Alas, I cannot fulfill this quest: %s", response.Error)
+		if guidance := failureGuidance(q, response); guidance != "" {
+			message += "\n\n" + guidance
+		}
+		if q.Step == nil && strings.Contains(strings.ToLower(response.Error), "too complex") {
+			message += "\n\n💡 Try again with --plan to break it into smaller quests."
+		}
+		ui.PrintStatusBox("❌ QUEST CANNOT BE COMPLETED", message, "error")
+		return false, nil
+	case ai.ResponseTypeAnswer:
+		// An answer is never run as a command
+		ui.PrintStatusBox("📜 THE KNIGHT'S ANSWER", response.Content, "info")
+		return false, nil
+	case ai.ResponseTypeScript:
+		q.IsScript = true
+	default:
+		q.IsScript = false
+	}
+	q.Content = response.Content
+	return true, nil
+}
+
+// maxNearbyDirectories caps the directories suggested when the oracle finds a path too vague
+const maxNearbyDirectories = 8
+
+// failureGuidance turns the category of a refusal into advice on rephrasing the intent
+func failureGuidance(q *Quest, response *ai.AIResponse) string {
+	switch response.Category {
+	case ai.FailureVaguePath:
+		guidance := "💡 Name the exact path in your intent, sire."
+		if q.SysInfo == nil {
+			return guidance
+		}
+		nearby := system.NearbyDirectories(q.Intent, q.SysInfo.CurrentDir, maxNearbyDirectories)
+		if len(nearby) == 0 {
+			return guidance
+		}
+		lines := []string{"Directories near " + q.SysInfo.CurrentDir + ":"}
+		for _, dir := range nearby {
+			lines = append(lines, "  • "+dir+string(filepath.Separator))
+		}
+		return strings.Join(lines, "\n") + "\n\n" + guidance
+	case ai.FailureUnsafe:
+		return "💡 The oracle judged this quest unsafe. If it is truly your will, name the exact targets and what should become of them."
+	case ai.FailureImpossible:
+		return "💡 This cannot be done with commands on this realm. Consider another tool or approach."
+	case ai.FailureNeedsMoreContext:
+		return "💡 Add the missing details to your intent, such as which file, service, version, or host."
+	case ai.FailureMalformed:
+		return "💡 Nothing was run. Try again, or choose a model that follows the response format more reliably; 'execute-my-will doctor' shows how each has fared."
+	}
+	return ""
+}
+
+// airGapStage refuses proposals that would reach the network when the quest is air-gapped
+type airGapStage struct{}
+
+func (s *airGapStage) Name() string { return "airgap" }
+
+func (s *airGapStage) Run(q *Quest) (bool, error) {
+	if !q.AirGapped || q.Content == "" {
+		return true, nil
+	}
+	operations := system.DetectNetworkOperations(q.Content)
+	if len(operations) == 0 {
+		return true, nil
+	}
+
+	lines := []string{"This realm is air-gapped, yet the proposed quest would reach the network:", ""}
+	for _, operation := range operations {
+		lines = append(lines, fmt.Sprintf("  • %s\n    (%s)", operation.Line, operation.Reason))
+	}
+	lines = append(lines, "", "I will not carry it out, my lord. Rephrase the quest to use only what is on this machine.")
+	ui.PrintStatusBox("🔌 NETWORK REFUSED", strings.Join(lines, "\n"), "error")
+	return false, nil
+}
+
+// flagsStage checks the flags of a generated command against its tools' own --help output and asks
+// the oracle once to correct flags that do not exist
+type flagsStage struct {
+	client ai.Client
+	check  func(content string) []system.UnknownFlag
+}
+
+func (s *flagsStage) Name() string { return "flags" }
+
+func (s *flagsStage) Run(q *Quest) (bool, error) {
+	// Recipes and recalled quests were not invented by the oracle just now
+	if s.check == nil || q.Content == "" || q.Recipe != "" || q.Recalled {
+		return true, nil
+	}
+	if q.SysInfo == nil || system.ShellFamily(q.SysInfo.Shell) != system.ShellFamilyPOSIX {
+		return true, nil
+	}
+	unknown := s.check(q.Content)
+	if len(unknown) == 0 {
+		return true, nil
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("The help of the tools does not mention %s. Asking the oracle to correct them...", describeUnknownFlags(unknown)))
+	prompt := fmt.Sprintf("%s\n\nFLAG CHECK: a previous answer was:\n%s\nThese flags are not in the --help output of the installed tools: %s. Answer again using only flags the installed tools support.", q.PromptIntent(), q.Content, describeUnknownFlags(unknown))
+	response, err := s.client.GenerateResponse(prompt, q.SysInfo)
+	if err == nil && response.Type != ai.ResponseTypeFailure && response.Content != "" {
+		if remaining := s.check(response.Content); len(remaining) < len(unknown) {
+			q.Response = response
+			q.Content = response.Content
+			q.IsScript = response.Type == ai.ResponseTypeScript
+			if len(remaining) == 0 {
+				ui.PrintStatusBox("🔎 FLAGS CORRECTED", "The oracle replaced flags the installed tools do not support.", "success")
+				return true, nil
+			}
+			unknown = remaining
+		}
+	}
+
+	ui.PrintStatusBox("⚠️  UNKNOWN FLAGS", fmt.Sprintf("The help of the installed tools does not mention %s.\n\nThe oracle may have invented them, or they may be valid yet undocumented. Review the quest closely before you approve it, my lord.", describeUnknownFlags(unknown)), "warning")
+	return true, nil
+}
+
+// describeUnknownFlags lists unknown flags as "'tar --frobnicate', 'ls -Q'"
+func describeUnknownFlags(unknown []system.UnknownFlag) string {
+	names := make([]string, 0, len(unknown))
+	for _, flag := range unknown {
+		names = append(names, "'"+flag.String()+"'")
+	}
+	return strings.Join(names, ", ")
+}
+
+// verifyDownloadsStage adds checksum verification to downloads that would otherwise be trusted blindly
+type verifyDownloadsStage struct {
+	client ai.Client
+}
+
+func (s *verifyDownloadsStage) Name() string { return "verify" }
+
+func (s *verifyDownloadsStage) Run(q *Quest) (bool, error) {
+	risks := system.DetectUnverifiedDownloads(q.Content)
+	if len(risks) == 0 {
+		return true, nil
+	}
+
+	ui.PrintInfoMessage("This quest downloads and runs software. Seeking the official seals to verify it...")
+
+	verified, err := s.client.AddChecksumVerification(q.Content, q.SysInfo)
+	if err == nil && verified.Type != ai.ResponseTypeFailure && len(system.DetectUnverifiedDownloads(verified.Content)) == 0 {
+		q.Content = verified.Content
+		q.IsScript = verified.Type == ai.ResponseTypeScript
+		ui.PrintStatusBox("🔐 VERIFICATION ADDED", "I have added steps that check the downloads against their official checksums or signatures before they are used.", "success")
+		return true, nil
+	}
+
+	var reason string
+	switch {
+	case err != nil:
+		reason = fmt.Sprintf("the oracles could not add verification: %v", err)
+	case verified.Type == ai.ResponseTypeFailure:
+		reason = verified.Error
+	default:
+		reason = "the rewritten quest still runs unverified downloads"
+	}
+
+	var lines []string
+	for _, risk := range risks {
+		lines = append(lines, fmt.Sprintf("• %s\n  (%s)", risk.Line, risk.Reason))
+	}
+	ui.PrintStatusBox("🚨 UNVERIFIED DOWNLOAD", fmt.Sprintf("BEWARE, my lord! This quest runs software that cannot be verified against an official checksum: %s\n\n%s\n\nOnly proceed if you trust the source completely.", reason, strings.Join(lines, "\n")), "error")
+	return true, nil
+}
+
+// reviewStage presents the proposal, explains it when needed, and checks environment safety
+type reviewStage struct {
+	client          ai.Client // nil never asks the oracle to explain a command
+	newEnvValidator func(*system.Info) system.EnvironmentValidatorInterface
+	prompter        Prompter // nil never offers to add a blocked environment command to a startup file
+}
+
+func (s *reviewStage) Name() string { return "review" }
+
+func (s *reviewStage) Run(q *Quest) (bool, error) {
+	q.CriticalDir = nil
+	if q.IsScript {
+		printProposedScript(q.Content, q.Explains())
+		printOracleNotes(q)
+		if q.Explains() {
+			ui.PrintStatusBox("📚 SCRIPT INFORMATION", "This script will execute each command in sequence, maintaining context between steps.", "info")
+		}
+		warnImmutableInstall(q)
+		if !q.ExplainOnly && refuseRootCommand(q) {
+			return false, nil
+		}
+		// The shell evaluates a single command, not a script
+		if q.Eval != nil {
+			ui.PrintStatusBox("📜 A SCRIPT, NOT A COMMAND", "This quest needs a script, my lord, and --eval hands your shell a single command. Run it without --eval to carry it out here.", "warning")
+			return false, nil
+		}
+		guardCriticalDirectory(q)
+		return true, nil
+	}
+
+	// Display the command for confirmation, broken into steps when it is a chain
+	if parts := system.SplitCommandChain(q.Content); len(parts) > 1 {
+		printProposedChain(parts)
+	} else {
+		ui.PrintCommandBox(q.Content)
+	}
+	printOracleNotes(q)
+
+	// If in royal-heir mode, provide detailed explanation for commands only
+	if q.Explains() && s.client != nil {
+		explanation, err := s.client.ExplainCommand(q.Content, q.SysInfo)
+		if err != nil {
+			ui.PrintStatusBox("⚠️  EXPLANATION DIFFICULTY", fmt.Sprintf("I encountered difficulty explaining the command, but it should still work, my lord: %v", err), "warning")
+		} else {
+			ui.PrintStatusBox("📚 COMMAND EXPLANATION", fmt.Sprintf("As you are still learning the ways of the realm, allow me to explain:\n\n%s", explanation), "info")
+		}
+	}
+	warnImmutableInstall(q)
+
+	// The command will be run by hand elsewhere, where environment changes do persist
+	if q.ExplainOnly {
+		return true, nil
+	}
+
+	if refuseRootCommand(q) {
+		return false, nil
+	}
+
+	// Validate if the command affects the environment. On the prompt line or under eval the
+	// user's own shell runs it, and in a shell session the session's shell does, so its cd or
+	// export lasts there.
+	if q.ToPrompt == "" && q.Eval == nil && !q.PersistentShell {
+		envValidator := s.newEnvValidator(q.SysInfo)
+		if err := envValidator.ValidateEnvironmentCommand(q.Content); err != nil {
+			var envErr *system.EnvironmentCommandError
+			if errors.As(err, &envErr) {
+				ui.PrintBlankLine()
+				ui.PrintPlain(envErr.GetKnightlyMessage())
+				printEvalHint(q)
+				offerStartupFileEdit(q, s.prompter)
+				return false, nil
+			}
+			return false, fmt.Errorf("environment validation failed: %w", err)
+		}
+	}
+	guardCriticalDirectory(q)
+	return true, nil
+}
+
+// printOracleNotes shows the oracle's one-sentence summary of the proposal, and warns when the
+// oracle rates it riskier than its commands read
+func printOracleNotes(q *Quest) {
+	if q.Response == nil {
+		return
+	}
+	if q.Response.Explanation != "" {
+		ui.PrintLine("📜", q.Response.Explanation)
+	}
+	if declared, ok := understatedRisk(q); ok {
+		ui.PrintStatusBox("⚠️  THE ORACLE'S OWN WARNING", fmt.Sprintf("The oracle rates this quest %s, riskier than its commands read to me (%s). Review it with care, my lord.", declared, contentRisk(q.Content, q.IsScript)), "warning")
+	}
+}
+
+// understatedRisk returns the oracle's own rating of the proposal when it is riskier than the
+// commands read, as when a harmless-looking tool deletes data
+func understatedRisk(q *Quest) (system.RiskLevel, bool) {
+	if q.Response == nil {
+		return system.RiskReadOnly, false
+	}
+	declared, ok := system.ParseRiskLevel(q.Response.Risk)
+	if !ok {
+		return system.RiskReadOnly, false
+	}
+	return declared, declared > contentRisk(q.Content, q.IsScript)
+}
+
+// questRisk is how risky the proposal is: what its commands read as, or the oracle's own rating
+// when that is higher
+func questRisk(q *Quest) system.RiskLevel {
+	if declared, understated := understatedRisk(q); understated {
+		return declared
+	}
+	return contentRisk(q.Content, q.IsScript)
+}
+
+// ScriptTrust relates a generated script to the version last approved for the same intent,
+// so that a regeneration cannot slip in new commands unnoticed
+type ScriptTrust struct {
+	Approved    *history.Entry // the version run before
+	Fingerprint string         // of the generated script
+	Identical   bool
+	Added       int // lines new in the generated script
+	Removed     int // lines of the approved version it no longer has
+}
+
+// trustStage fingerprints generated scripts on first use. When the same intent produces a
+// script again, an identical one is trusted as already approved, and a changed one is shown as
+// a diff against the approved version so that only the changes need a fresh look.
+type trustStage struct {
+	history *history.Store
+}
+
+func (s *trustStage) Name() string { return "trust" }
+
+func (s *trustStage) Run(q *Quest) (bool, error) {
+	q.Trust = nil
+	if s.history == nil || !q.IsScript || q.Recalled || q.ProposalOnly() {
+		return true, nil
+	}
+	entry := s.history.Latest(q.Intent, q.ContextName)
+	if entry == nil || !entry.IsScript {
+		return true, nil
+	}
+
+	trust := &ScriptTrust{Approved: entry, Fingerprint: history.Fingerprint(q.Content)}
+	q.Trust = trust
+	approvedOn := ui.FormatTimestamp(entry.LastRun)
+	if trust.Fingerprint == history.Fingerprint(entry.Content) {
+		trust.Identical = true
+		ui.PrintStatusBox("🔐 TRUSTED SCRIPT", fmt.Sprintf("This script is identical to the one you approved for this quest on %s (fingerprint %s).", approvedOn, trust.Fingerprint), "success")
+		return true, nil
+	}
+
+	diff := system.UnifiedDiff("approved on "+approvedOn, "regenerated", history.NormalizeContent(entry.Content), history.NormalizeContent(q.Content))
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for _, line := range lines[2:] {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			trust.Added++
+		case strings.HasPrefix(line, "-"):
+			trust.Removed++
+		}
+	}
+
+	ui.PrintStatusBox("⚠️  SCRIPT CHANGED SINCE YOUR APPROVAL", fmt.Sprintf("The oracle wrote a different script for this quest than the one you approved on %s: %d new or changed lines, %d dropped. Only these changes are new; review them before approving.", approvedOn, trust.Added, trust.Removed), "warning")
+	printDiff(lines)
+	return true, nil
+}
+
+// repeatStage warns before confirmation when the same destructive command or script was run
+// moments ago, so that a one-time operation such as dropping a table is not carried out twice
+// by running the same quest again
+type repeatStage struct {
+	history *history.Store
+	now     func() time.Time
+}
+
+func (s *repeatStage) Name() string { return "repeat" }
+
+func (s *repeatStage) Run(q *Quest) (bool, error) {
+	q.RecentRun = nil
+	if s.history == nil || q.Content == "" || q.ProposalOnly() {
+		return true, nil
+	}
+	var execution config.ExecutionConfig
+	if q.Config != nil {
+		execution = q.Config.Execution
+	}
+	window := execution.RepeatWindow()
+	if window == 0 {
+		return true, nil
+	}
+	if questRisk(q) < system.RiskDestructive {
+		return true, nil
+	}
+
+	now := s.now()
+	entry := s.history.RecentRun(q.Content, now.Add(-window))
+	if entry == nil {
+		return true, nil
+	}
+	q.RecentRun = entry
+
+	kind := "command"
+	if q.IsScript {
+		kind = "script"
+	}
+	message := fmt.Sprintf("You ran this exact %s %s ago, my lord, for \"%s\".", kind, ui.FormatDuration(now.Sub(entry.LastRun)), entry.Intent)
+	if entry.Succeeded() {
+		message += " It succeeded then."
+	} else {
+		message += fmt.Sprintf(" It failed then: %s", entry.LastError)
+	}
+	message += "\n\nIt is destructive: a one-time operation such as deleting an account or dropping a table must not be done twice. Approve it only if you mean to run it again."
+	ui.PrintStatusBox("⚠️  RAN MOMENTS AGO", message, "warning")
+	return true, nil
+}
+
+// printDiff shows a unified diff with added lines in green and removed ones in red, up to
+// maxShownDiffLines lines
+func printDiff(lines []string) {
+	for i, line := range lines {
+		if i == maxShownDiffLines {
+			ui.PrintPlain(ui.Gray.Sprintf("… %d more lines", len(lines)-i))
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			ui.PrintPlain(ui.Gray.Sprint(line))
+		case strings.HasPrefix(line, "@@"):
+			ui.PrintPlain(ui.Cyan.Sprint(line))
+		case strings.HasPrefix(line, "+"):
+			ui.PrintPlain(ui.Green.Sprint(line))
+		case strings.HasPrefix(line, "-"):
+			ui.PrintPlain(ui.Red.Sprint(line))
+		default:
+			ui.PrintPlain(line)
+		}
+	}
+	ui.PrintBlankLine()
+}
+
+// warnImmutableInstall points out an install that an immutable root filesystem will reject. The
+// oracle is told about such systems, but a plain install can still slip through.
+func warnImmutableInstall(q *Quest) {
+	if q.SysInfo == nil {
+		return
+	}
+	install := q.SysInfo.Immutable.ConflictingInstall(q.Content)
+	if install == "" {
+		return
+	}
+
+	var hint string
+	switch q.SysInfo.Immutable.Installer {
+	case "":
+		hint = "Run the tool in a container instead."
+	case "flatpak":
+		hint = "Use a Flatpak, or a toolbox or distrobox for command line tools."
+	default:
+		hint = fmt.Sprintf("Use a Flatpak or a toolbox, or layer the package with %s (which needs a reboot).", q.SysInfo.Immutable.Installer)
+	}
+	ui.PrintStatusBox("🧊 IMMUTABLE SYSTEM", fmt.Sprintf("'%s' will likely fail here, my lord: this realm (%s) does not allow changes to its root filesystem.\n\n%s", install, q.SysInfo.Immutable.String(), hint), "warning")
+}
+
+// guardCriticalDirectory warns about a proposal that uses relative paths or globs in a directory
+// the system depends on, where a command meant for another folder, such as "rm -rf *", breaks the
+// machine. The quest must then be approved by typing the directory.
+func guardCriticalDirectory(q *Quest) {
+	if q.ExplainOnly || q.SysInfo == nil {
+		return
+	}
+	use := system.DetectCriticalDirectoryUse(q.Content, q.SysInfo.CurrentDir, q.SysInfo.OS)
+	if use == nil {
+		return
+	}
+	q.CriticalDir = use
+	ui.PrintStatusBox("🛑 CRITICAL DIRECTORY", fmt.Sprintf("Beware, my lord: '%s' uses '%s', which is relative to %s, a directory your system depends on.\n\nIf this quest was meant for another folder, it would act on the system's own files instead. Decline it, or type the directory to show you meant it.", use.Line, use.Path, use.Dir), "error")
+}
+
+// refuseRootCommand rejects a proposal that needs root when the user has no sudo, since it would
+// only stop at a password prompt that cannot succeed
+func refuseRootCommand(q *Quest) bool {
+	if q.SysInfo == nil {
+		return false
+	}
+	command := q.SysInfo.Privileges.RootCommand(q.Content)
+	if command == "" {
+		return false
+	}
+	ui.PrintStatusBox("🔒 NO ROOT ACCESS", fmt.Sprintf("I cannot carry out this quest, my lord: '%s' needs root, and you have no sudo in this realm.\n\nAsk again for an install into your home directory, such as 'pip install --user', 'cargo install', or a download into ~/.local/bin.", command), "error")
+	return true
+}
+
+// printProposedScript renders a script with comments shown only when requested
+func printProposedScript(script string, showComments bool) {
+	var displayLines []string
+	displayLines = append(displayLines, "") // Empty line at start
+
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Check if line is a comment
+		isComment := strings.HasPrefix(line, "#") || strings.HasPrefix(line, "REM")
+
+		if isComment && showComments {
+			// Display comment with proper formatting
+			comment := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "#"), "REM"))
+			displayLines = append(displayLines, ui.CommentText("• "+comment))
+		} else if !isComment {
+			// Display command with arrow prefix
+			displayLines = append(displayLines, ui.CommandText("→ "+line))
+		}
+	}
+	displayLines = append(displayLines, "") // Empty line at end
+
+	template := ui.DefaultTemplate()
+	template.PrintBox("📜 PROPOSED SCRIPT", displayLines)
+}
+
+// printProposedChain renders a chained command as numbered steps with a risk tag for each
+func printProposedChain(parts []system.ChainPart) {
+	displayLines := []string{""}
+
+	for i, part := range parts {
+		if i > 0 {
+			displayLines = append(displayLines, ui.Gray.Sprint("   "+chainOperatorText(part.Operator, i)))
+		}
+		displayLines = append(displayLines, fmt.Sprintf("%d. %s %s", i+1, ui.CommandText(part.Command), riskTag(system.AssessCommandRisk(part.Command))))
+	}
+	displayLines = append(displayLines, "")
+
+	template := ui.DefaultTemplate()
+	template.PrintBox("⚔️  PROPOSED COMMAND CHAIN", displayLines)
+}
+
+// chainOperatorText explains when the step after an operator runs
+func chainOperatorText(operator string, previousStep int) string {
+	switch operator {
+	case "&&":
+		return fmt.Sprintf("&& only if step %d succeeds", previousStep)
+	case "||":
+		return fmt.Sprintf("|| only if step %d fails", previousStep)
+	default:
+		return fmt.Sprintf("; after step %d, whatever its outcome", previousStep)
+	}
+}
+
+func riskTag(risk system.RiskLevel) string {
+	tag := "[" + risk.String() + "]"
+	switch risk {
+	case system.RiskReadOnly:
+		return ui.Green.Sprint(tag)
+	case system.RiskModifies:
+		return ui.Yellow.Sprint(tag)
+	case system.RiskElevated:
+		return ui.Gold.Sprint(tag)
+	default:
+		return ui.Red.Sprint(tag)
+	}
+}
+
+// confirmStage asks for the royal decree
+type confirmStage struct {
+	confirmer Confirmer
+}
+
+func (s *confirmStage) Name() string { return "confirm" }
+
+func (s *confirmStage) Run(q *Quest) (bool, error) {
+	if q.ExplainOnly {
+		ui.PrintStatusBox("📖 EXPLANATION ONLY", "As you wished, this quest will not be carried out here, my lord. Take it to whichever realm needs it.", "info")
+		return false, nil
+	}
+	if q.DryRun {
+		kind := "command"
+		if q.IsScript {
+			kind = "script"
+		}
+		ui.PrintStatusBox("🧪 DRY RUN", fmt.Sprintf("The %s passed my checks and was not carried out, my lord. Here it is as it would have run:", kind), "info")
+		ui.PrintPlain(q.Content)
+		return false, nil
+	}
+	if q.ToPrompt != "" {
+		if err := os.WriteFile(q.ToPrompt, []byte(strings.TrimRight(q.Content, "\n")), 0600); err != nil {
+			return false, fmt.Errorf("failed to hand the command to your shell, my lord: %w", err)
+		}
+		if q.CriticalDir != nil {
+			ui.PrintStatusBox("🛑 CRITICAL DIRECTORY", fmt.Sprintf("This quest acts on files in %s, my lord. Check its paths before you press Enter.", q.CriticalDir.Dir), "warning")
+		}
+		ui.PrintInfoMessage("The quest awaits on your prompt line, my lord. Review it, change it if you will, and press Enter to carry it out yourself.")
+		return false, nil
+	}
+
+	// A script identical to one approved before needs no second approval, unless the context
+	// asks for a typed confirmation every time
+	if q.Trust != nil && q.Trust.Identical && q.ConfirmationToken() == "" && q.RecentRun == nil {
+		ui.PrintInfoMessage("Proceeding with the script you approved before, my lord.")
+		q.Approved = true
+		return true, nil
+	}
+	if q.Trust != nil && !q.Trust.Identical {
+		ui.PrintLine("🔎", "Your approval covers the highlighted changes; the rest of the script is as you approved it before.")
+	}
+
+	q.DefaultApproval = approvedByDefault(q)
+	if q.CriticalDir != nil {
+		ui.PrintPrompt("🛑", fmt.Sprintf("This quest acts on files in %s. Type '%s' to proceed:", q.CriticalDir.Dir, q.CriticalDir.Dir))
+	} else if token := q.ConfirmationToken(); token != "" {
+		ui.PrintPrompt("🔏", fmt.Sprintf("This quest runs in the '%s' context. Type '%s' to proceed:", token, token))
+	} else {
+		icon, key := "👑", "confirm.heir"
+		if q.DefaultApproval {
+			icon, key = "🤴", "confirm.read_only"
+		} else if q.Config.Mode == "monarch" {
+			icon, key = "🤴", "confirm.monarch"
+		}
+		message := ui.Message(key)
+		if q.Config.UI.SelectConfirmation() {
+			// The answer is chosen below the question, not typed after it
+			message = ui.WithoutAnswerHint(message)
+		}
+		ui.PrintPrompt(icon, message)
+	}
+
+	approved, err := s.confirmer.Confirm(q)
+	if err != nil {
+		return false, err
+	}
+	q.Approved = approved
+	q.Declined = !approved
+
+	if !approved {
+		ui.PrintStatusBox("🙏 QUEST DECLINED", ui.Message("confirm.declined"), "info")
+		return false, nil
+	}
+	return true, nil
+}
+
+// approvedByDefault reports whether Enter approves the quest: only in monarch mode when configured,
+// and only when every step only reads and the oracle does not rate it riskier. Anything riskier,
+// and every quest that asks for a typed confirmation, defaults to no. A workspace quest defaults
+// to yes only when every project quest it covers would.
+func approvedByDefault(q *Quest) bool {
+	if q.Config == nil || q.Config.Mode != "monarch" || !q.Config.Execution.ReadOnlyDefaultYes {
+		return false
+	}
+	if len(q.Covers) > 0 {
+		for _, covered := range q.Covers {
+			if !approvedByDefault(covered) {
+				return false
+			}
+		}
+		return q.ConfirmationToken() == ""
+	}
+	_, understated := understatedRisk(q)
+	return q.ConfirmationToken() == "" && contentRisk(q.Content, q.IsScript) == system.RiskReadOnly && !understated
+}
+
+// reauthStage asks the operating system to confirm who is at the terminal before a destructive
+// quest runs, when the configuration asks for it, so that an approved terminal left unattended
+// cannot be used to run one
+type reauthStage struct {
+	reauthenticate func(reason string) error
+}
+
+func (s *reauthStage) Name() string { return "reauth" }
+
+func (s *reauthStage) Run(q *Quest) (bool, error) {
+	if s.reauthenticate == nil || q.Config == nil || !q.Config.Execution.Reauthenticate {
+		return true, nil
+	}
+	if questRisk(q) < system.RiskDestructive {
+		return true, nil
+	}
+
+	ui.PrintLine("🔐", fmt.Sprintf("This quest is destructive, my lord. Confirm it is you with %s.", system.ReauthenticationMethod()))
+	if err := s.reauthenticate("execute-my-will: confirm the destructive quest."); err != nil {
+		ui.PrintStatusBox("🔐 NOT CONFIRMED", fmt.Sprintf("%v\n\nThe quest was not carried out, my lord.", err), "error")
+		return false, nil
+	}
+	return true, nil
+}
+
+// evalStage hands the approved command to the user's shell to evaluate (--eval) instead of
+// running it here, so that a cd or export lasts in the shell it was asked from
+type evalStage struct{}
+
+func (s *evalStage) Name() string { return "eval" }
+
+func (s *evalStage) Run(q *Quest) (bool, error) {
+	if q.Eval == nil {
+		return true, nil
+	}
+	if haltedByKillSwitch(q.Config) {
+		return false, nil
+	}
+	if _, err := fmt.Fprintln(q.Eval, strings.TrimRight(q.Content, "\n")); err != nil {
+		return false, fmt.Errorf("failed to hand the command to your shell, my lord: %w", err)
+	}
+	ui.PrintInfoMessage("The quest is handed to your shell, my lord, which carries it out as soon as I am done.")
+	return false, nil
+}
+
+// printEvalHint shows how to ask for the quest again with --eval, so that the user's own shell
+// carries out the environment change once approved
+func printEvalHint(q *Quest) {
+	if q.SysInfo == nil || q.AsUser != "" || q.Step != nil || q.Prior != nil {
+		return
+	}
+	intent := q.Intent
+	if q.ContextName != "" {
+		intent = q.ContextName + ": " + intent
+	}
+	if line := system.EvalLine(q.SysInfo.Shell, intent); line != "" {
+		ui.PrintInfoMessage(fmt.Sprintf("Or let your own shell carry out the quest once you approve it:\n    %s", line))
+	}
+}
+
+// elevateStage offers the Windows UAC prompt for commands that need Administrator rights,
+// instead of letting them fail with "access denied"
+type elevateStage struct {
+	prompter   Prompter
+	isElevated func() bool
+}
+
+func (s *elevateStage) Name() string { return "elevate" }
+
+func (s *elevateStage) Run(q *Quest) (bool, error) {
+	q.Elevate = false
+	if q.SysInfo.OS != "windows" || q.IsScript || !system.RequiresAdministrator(q.Content) {
+		return true, nil
+	}
+	if s.prompter == nil || (s.isElevated != nil && s.isElevated()) {
+		return true, nil
+	}
+
+	choice, err := s.prompter.Choose("This quest needs Administrator rights. How shall I proceed?", []string{
+		"Run it elevated (Windows will ask for approval)",
+		"Run it as I am",
+	})
+	if err != nil {
+		return false, err
+	}
+	q.Elevate = choice == 0
+	return true, nil
+}
+
+// detachStage offers to run a long quest in a detached tmux or screen session, so that closing
+// the terminal by accident does not kill it
+type detachStage struct {
+	prompter        Prompter
+	findMultiplexer func() string
+	now             func() time.Time
+}
+
+func (s *detachStage) Name() string { return "detach" }
+
+func (s *detachStage) Run(q *Quest) (bool, error) {
+	q.Detached = nil
+	// Scripts run from a temporary file that is gone once the executor returns, and the steps of
+	// a plan must finish before the next one starts
+	if s.prompter == nil || s.findMultiplexer == nil || q.IsScript || q.Elevate || q.Step != nil {
+		return true, nil
+	}
+	if system.ShellFamily(q.SysInfo.Shell) != system.ShellFamilyPOSIX || !system.IsLongRunning(q.Content) {
+		return true, nil
+	}
+	tool := s.findMultiplexer()
+	if tool == "" {
+		return true, nil
+	}
+
+	choice, err := s.prompter.Choose("This quest may run for a long while. Where shall it run?", []string{
+		"Here, in this terminal",
+		fmt.Sprintf("In a detached %s session that survives closing the terminal", tool),
+	})
+	if err != nil {
+		return false, err
+	}
+	if choice == 1 {
+		q.Detached = system.NewDetachedSession(tool, s.now())
+	}
+	return true, nil
+}
+
+// executeStage carries out the approved quest
+type executeStage struct {
+	executor system.CommandExecutor
+}
+
+func (s *executeStage) Name() string { return "execute" }
+
+func (s *executeStage) Run(q *Quest) (bool, error) {
+	// The kill switch may have been engaged while the quest awaited approval
+	if haltedByKillSwitch(q.Config) {
+		return false, nil
+	}
+
+	ui.PrintLine("🛡️ ", "Executing your quest with honor...")
+	ui.PrintBlankLine()
+
+	// A detached quest creates its files after this stage has returned
+	var watch *system.ArtifactWatch
+	if q.Detached == nil {
+		watch = system.WatchArtifacts(q.Content, q.SysInfo.CurrentDir, q.SysInfo.HomeDir)
+	}
+
+	if q.IsScript {
+		showComments := q.Config.Mode == "royal-heir"
+		q.ExecErr = s.executor.ExecuteScript(q.Content, q.SysInfo.Shell, showComments)
+	} else if q.Elevate {
+		q.ExecErr = s.executor.ExecuteElevated(q.Content, q.SysInfo.Shell)
+	} else if q.Detached != nil {
+		q.ExecErr = s.executor.Execute(q.Detached.Command(q.Content, q.SysInfo.Shell), q.SysInfo.Shell)
+	} else {
+		q.ExecErr = s.executor.Execute(q.Content, q.SysInfo.Shell)
+	}
+	q.Executed = true
+
+	q.OutputLog = nil
+	if logger, ok := s.executor.(system.OutputLogger); ok {
+		q.OutputLog = logger.LastOutput()
+	}
+
+	q.Artifacts = nil
+	for _, artifact := range watch.Created() {
+		if q.OutputLog == nil || artifact.Path != q.OutputLog.Path {
+			q.Artifacts = append(q.Artifacts, artifact)
+		}
+	}
+	return true, nil
+}
+
+// maxShownArtifacts caps the created files listed in the report
+const maxShownArtifacts = 10
+
+// describeArtifacts lists the files a quest created, with their permissions and sizes
+func describeArtifacts(artifacts []system.Artifact) string {
+	if len(artifacts) == 0 {
+		return ""
+	}
+	lines := []string{"📦 Created:"}
+	for i, artifact := range artifacts {
+		if i == maxShownArtifacts {
+			lines = append(lines, fmt.Sprintf("  ...and %d more", len(artifacts)-maxShownArtifacts))
+			break
+		}
+		lines = append(lines, "  "+artifactLine(artifact))
+	}
+	return "\n\n" + strings.Join(lines, "\n")
+}
+
+// artifactLine shows a created file with its mode and its size in the user's locale
+func artifactLine(artifact system.Artifact) string {
+	if artifact.Mode.IsDir() {
+		return fmt.Sprintf("%s %10s %s%c", artifact.Mode, "", artifact.Path, filepath.Separator)
+	}
+	return fmt.Sprintf("%s %10s %s", artifact.Mode, ui.FormatBytes(artifact.Size), artifact.Path)
+}
+
+// reportStage tells the monarch how the quest went
+type reportStage struct{}
+
+func (s *reportStage) Name() string { return "report" }
+
+func (s *reportStage) Run(q *Quest) (bool, error) {
+	if q.ExecErr != nil {
+		var suggestionMsg string
+
+		// Check if it's a common issue and provide helpful suggestions
+		switch {
+		case errors.Is(q.ExecErr, system.ErrPermissionDenied):
+			suggestionMsg = "\n\n💡 This might require elevated privileges. Consider adding 'sudo' to your request if appropriate."
+		case errors.Is(q.ExecErr, system.ErrCommandNotFound):
+			suggestionMsg = "\n\n💡 The command appears to be missing. The system may need to install required packages first."
+		case errors.Is(q.ExecErr, system.ErrPathNotFound):
+			suggestionMsg = "\n\n💡 Please ensure all file paths in your request are correct and accessible."
+		}
+
+		// Don't return the error to avoid double error messages
+		ui.PrintStatusBox("⚔️  QUEST DIFFICULTIES", ui.Message("quest.difficulties", "error", q.ExecErr.Error())+suggestionMsg+describeArtifacts(q.Artifacts), "error")
+		return true, nil
+	}
+
+	if q.Detached != nil {
+		ui.PrintStatusBox("🖥️  QUEST DETACHED", fmt.Sprintf("The quest runs in the %s session '%s', my lord. Closing this terminal will not stop it.\n\nRe-attach to follow it with:\n  %s", q.Detached.Tool, q.Detached.Name, q.Detached.AttachCommand()), "info")
+		return true, nil
+	}
+
+	if q.IsScript {
+		ui.PrintStatusBox("🏆 QUEST COMPLETED", ui.Message("quest.completed.script")+describeArtifacts(q.Artifacts), "success")
+	} else {
+		ui.PrintStatusBox("🏆 QUEST COMPLETED", ui.Message("quest.completed.command")+describeArtifacts(q.Artifacts), "success")
+	}
+	ui.PrintFlourish("🎉", ui.Message("quest.celebration"))
+	if q.Undo == nil && q.Step == nil && contentRisk(q.Content, q.IsScript) > system.RiskReadOnly {
+		ui.PrintLine("↩️", "Changed your mind? 'execute-my-will undo' asks the oracle how to reverse this quest.")
+	}
+	return true, nil
+}
+
+// summaryLineThreshold is the output length from which a summary by the oracle is offered
+const summaryLineThreshold = 1000
+
+const (
+	// summaryHeadLines and summaryTailLines are the lines always sent from a long output
+	summaryHeadLines = 40
+	summaryTailLines = 120
+	// summaryNotableLines caps the lines mentioning errors, warnings, or totals sent from the middle
+	summaryNotableLines = 80
+)
+
+// summarizeStage offers to have the oracle condense a long output into a few lines. Only a
+// redacted sample of the saved log is sent; the full log stays on disk.
+type summarizeStage struct {
+	client   ai.Client
+	prompter Prompter
+}
+
+func (s *summarizeStage) Name() string { return "summarize" }
+
+func (s *summarizeStage) Run(q *Quest) (bool, error) {
+	if s.prompter == nil || q.OutputLog == nil || q.OutputLog.Lines < summaryLineThreshold {
+		return true, nil
+	}
+
+	question := fmt.Sprintf("The quest printed %d lines. Shall the oracle summarize them?", q.OutputLog.Lines)
+	choice, err := s.prompter.Choose(question, []string{"Summarize the output", "No need"})
+	if err != nil {
+		return false, err
+	}
+	if choice != 0 {
+		ui.PrintInfoMessage(fmt.Sprintf("The full output is kept in %s", q.OutputLog.Path))
+		return true, nil
+	}
+
+	sample, err := system.SampleOutputLog(q.OutputLog.Path, summaryHeadLines, summaryTailLines, summaryNotableLines)
+	if err == nil {
+		ui.PrintInfoMessage("Consulting the oracle about the output...")
+		var summary string
+		summary, err = s.client.SummarizeOutput(q.Intent, q.Content, outputRedactor(q).Redact(sample), q.OutputLog.Lines)
+		if err == nil {
+			ui.PrintStatusBox("📜 QUEST SUMMARY", fmt.Sprintf("%s\n\nFull log: %s", summary, q.OutputLog.Path), "info")
+			return true, nil
+		}
+	}
+	ui.PrintWarningMessage(fmt.Sprintf("The oracle could not summarize the output: %v. The full log is kept in %s", err, q.OutputLog.Path))
+	return true, nil
+}
+
+// autoFixStage feeds a failed execution back to the oracle and re-proposes a corrected quest.
+// Every corrected attempt goes through the retry stages again, so it still needs the royal decree.
+type autoFixStage struct {
+	client ai.Client
+	retry  []Stage
+}
+
+func (s *autoFixStage) Name() string { return "autofix" }
+
+func (s *autoFixStage) Run(q *Quest) (bool, error) {
+	if q.ExecErr != nil && q.AutoFixLimit > 0 && q.Context != nil && q.Context.DisableAutoFix {
+		ui.PrintInfoMessage(fmt.Sprintf("Auto-fix is disabled in the '%s' context, my lord.", q.ContextName))
+		return true, nil
+	}
+
+	// Tools print credentials (docker login, aws sts get-session-token); they must never reach the provider
+	redactor := outputRedactor(q)
+
+	// The attempts already fixed go along with each fix, so the oracle learns from all of them
+	var earlier []ai.Attempt
+	for q.ExecErr != nil && q.FixAttempts < q.AutoFixLimit {
+		q.FixAttempts++
+		ui.PrintPhaseHeader("🔧", fmt.Sprintf("Seeking a remedy from the oracles (attempt %d of %d)...", q.FixAttempts, q.AutoFixLimit))
+
+		attempt := failedAttempt(q, redactor)
+		attempt.Earlier = earlier
+		response, err := s.client.FixCommand(q.PromptIntent(), attempt, q.SysInfo)
+		if err != nil {
+			ui.PrintStatusBox("⚠️  NO REMEDY FOUND", fmt.Sprintf("The oracles could not suggest a remedy, my lord: %v", err), "warning")
+			return true, nil
+		}
+		if response.Type == ai.ResponseTypeFailure {
+			ui.PrintStatusBox("❌ NO REMEDY FOUND", fmt.Sprintf("Alas, the oracles see no safe remedy: %s", response.Error), "error")
+			return true, nil
+		}
+
+		attempt.Earlier = nil
+		earlier = append(earlier, attempt)
+		q.Response = response
+		q.Content = response.Content
+		q.IsScript = response.Type == ai.ResponseTypeScript
+		q.Approved = false
+		q.Executed = false
+		q.ExecErr = nil
+
+		for _, stage := range s.retry {
+			proceed, err := stage.Run(q)
+			if err != nil {
+				return false, err
+			}
+			if !proceed {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// failedAttempt describes the quest's failed execution for the oracle: what ran, the exit code it
+// ended with, and the last lines it printed, with secrets redacted
+func failedAttempt(q *Quest, redactor *system.Redactor) ai.Attempt {
+	attempt := ai.Attempt{
+		Content: q.Content,
+		Error:   redactor.Redact(q.ExecErr.Error()),
+		Output:  redactor.Redact(system.ExecutionOutput(q.ExecErr)),
+	}
+	var execErr *system.ExecutionError
+	if errors.As(q.ExecErr, &execErr) && execErr.ExitCode >= 0 {
+		attempt.ExitCode = &execErr.ExitCode
+	}
+	return attempt
+}
+
+// outputRedactor removes secrets and the API key from execution output sent back to the oracle.
+// The home directory is hidden too when the user withholds it.
+func outputRedactor(q *Quest) *system.Redactor {
+	var homeDir string
+	if q.SysInfo != nil && !q.Config.Privacy.AllowHomeDir() {
+		homeDir = q.SysInfo.HomeDir
+	}
+	return system.NewRedactor(homeDir, q.Config.APIKey)
+}
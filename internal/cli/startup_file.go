@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/startup_file.go
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// offerStartupFileEdit offers to make a blocked environment command last by appending it to the
+// shell's startup file, after showing the change. An existing file is backed up first. Nothing
+// is offered for a command with steps a startup file cannot keep, or for another user's quest.
+func offerStartupFileEdit(q *Quest, prompter Prompter) {
+	if prompter == nil || q.SysInfo == nil || q.AsUser != "" || q.DryRun {
+		return
+	}
+	lines, target := system.PersistentEnvironmentLines(q.Content, q.SysInfo.Shell)
+	if len(lines) == 0 {
+		return
+	}
+	path := startupFilePath(target, q.SysInfo)
+	if path == "" {
+		return
+	}
+	shown := tildePath(path, q.SysInfo.HomeDir)
+
+	edit, err := system.PlanStartupFileEdit(path, lines, time.Now())
+	if err != nil {
+		ui.PrintStatusBox("⚠️  STARTUP FILE UNREADABLE", fmt.Sprintf("I could not offer to add this to your startup file, my lord: %v", err), "warning")
+		return
+	}
+	if len(edit.Lines) == 0 {
+		ui.PrintStatusBox("📜 ALREADY IN "+strings.ToUpper(filepath.Base(path)), fmt.Sprintf("%s already has these lines, my lord. They apply to every new terminal; run 'source %s' to use them in this one.", shown, shown), "info")
+		return
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("I can make this last by adding it to %s, which your shell reads in every new terminal:", shown))
+	printDiff(strings.Split(strings.TrimSuffix(edit.Diff, "\n"), "\n"))
+	choice, err := prompter.Choose(fmt.Sprintf("Shall I add it to %s, my lord?", shown), []string{
+		"Add it (the current file is backed up first)",
+		"Leave it to me",
+	})
+	if err != nil || choice != 0 {
+		return
+	}
+
+	backup, err := edit.Apply(time.Now())
+	if err != nil {
+		ui.PrintStatusBox("❌ STARTUP FILE NOT CHANGED", fmt.Sprintf("I could not add it to %s, my lord: %v", shown, err), "error")
+		return
+	}
+	message := fmt.Sprintf("Added to %s, my lord. It applies to every new terminal; run 'source %s' to use it in this one.", shown, shown)
+	if backup != "" {
+		message += fmt.Sprintf("\n\nThe previous version was kept at %s", tildePath(backup, q.SysInfo.HomeDir))
+	}
+	ui.PrintStatusBox("📜 STARTUP FILE UPDATED", message, "success")
+}
+
+// startupFilePath is the startup file the command itself names, when it echoes into one, and
+// otherwise the one the user's shell reads
+func startupFilePath(target string, sysInfo *system.Info) string {
+	if target == "" {
+		return system.StartupFile(sysInfo.Shell, sysInfo.HomeDir, runtime.GOOS)
+	}
+	for _, prefix := range []string{"~/", "$HOME/", "${HOME}/"} {
+		if rest, ok := strings.CutPrefix(target, prefix); ok {
+			if sysInfo.HomeDir == "" {
+				return ""
+			}
+			return filepath.Join(sysInfo.HomeDir, rest)
+		}
+	}
+	if !filepath.IsAbs(target) {
+		return ""
+	}
+	return target
+}
+
+// tildePath shortens a path in the home directory to ~/...
+func tildePath(path, homeDir string) string {
+	if rest, ok := strings.CutPrefix(path, homeDir+string(filepath.Separator)); ok && homeDir != "" {
+		return "~/" + filepath.ToSlash(rest)
+	}
+	return path
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/stdin.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// StdinIntent turns the text read for the intent "-" into an intent, with its lines and spacing
+// joined. Empty text is an error.
+func StdinIntent(text string) (string, error) {
+	intent := strings.Join(strings.Fields(text), " ")
+	if intent == "" {
+		return "", fmt.Errorf("nothing was piped to stdin, my lord; with '-' the intent is read from there, as in: echo 'list my files' | execute-my-will -")
+	}
+	return intent, nil
+}
+
+// PipedContext prepares text piped to stdin to be sent with an intent, telling the user how much
+// of it is sent: its end, with secrets and, when withheld, the home directory redacted. Empty
+// text, as from a job whose stdin is closed, is no context at all.
+func PipedContext(text string, cfg *config.Config) string {
+	text, cut := system.TrimPiped(text)
+	if text == "" {
+		return ""
+	}
+
+	var homeDir string
+	if !cfg.Privacy.AllowHomeDir() {
+		homeDir, _ = os.UserHomeDir()
+	}
+	text = system.NewRedactor(homeDir, cfg.APIKey).Redact(text)
+
+	lines := strings.Count(text, "\n") + 1
+	message := fmt.Sprintf("The %d line(s) piped to me will be sent with your quest as context.", lines)
+	if cut {
+		message = fmt.Sprintf("More than %d characters were piped to me; only their end will be sent with your quest.", system.MaxClipboardChars)
+	}
+	ui.PrintInfoMessage(message)
+	return text
+}
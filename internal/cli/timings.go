@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/timings.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// SlowPhase is how long a phase of the system analysis may take before advice is given on it
+const SlowPhase = 2 * time.Second
+
+// SlowPhaseAdvice suggests how to speed up each phase of the analysis that took SlowPhase or
+// longer. Phases without a known remedy, and the total, get none.
+func SlowPhaseAdvice(timings []system.PhaseTiming) []string {
+	var advice []string
+	seen := map[string]bool{}
+	add := func(text string) {
+		if !seen[text] {
+			seen[text] = true
+			advice = append(advice, text)
+		}
+	}
+
+	for _, timing := range timings {
+		if timing.Duration < SlowPhase {
+			continue
+		}
+		switch {
+		case timing.Phase == system.PhasePackages || strings.HasPrefix(timing.Phase, "packages ("):
+			add("Listing installed packages is slow. Skip it with --no-system-scan, or for every quest with 'execute-my-will configure --system-scan=false'.")
+		case timing.Phase == system.PhaseCommands:
+			add("Walking the PATH directories is slow, often because one is on a network drive. Remove slow directories from PATH, or skip the walk with --no-system-scan.")
+		case timing.Phase == "privileges":
+			add("Checking for passwordless sudo is slow, often because the hostname does not resolve. Add the hostname to /etc/hosts.")
+		case timing.Phase == "project types" || timing.Phase == "Nix":
+			add("Looking at the current directory is slow, as on a network share. Start quests from a local directory.")
+		}
+	}
+	return advice
+}
+
+// analysisTimingLines lists the phases of the analysis, the slowest first, one per line
+func analysisTimingLines(timings []system.PhaseTiming) []string {
+	lines := make([]string, 0, len(timings))
+	for _, timing := range timings {
+		duration := fmt.Sprintf("%8s", timing.Duration.Round(time.Millisecond))
+		if timing.Duration >= SlowPhase && timing.Phase != system.PhaseTotal {
+			duration = ui.Yellow.Sprint(duration)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", duration, timing.Phase))
+	}
+	return lines
+}
+
+// printAnalysisTimings shows how long each phase of the system analysis took, for --debug
+func printAnalysisTimings(timings []system.PhaseTiming) {
+	if len(timings) == 0 {
+		return
+	}
+	lines := append([]string{""}, analysisTimingLines(timings)...)
+	if advice := SlowPhaseAdvice(timings); len(advice) > 0 {
+		lines = append(lines, "")
+		for _, text := range advice {
+			lines = append(lines, "💡 "+text)
+		}
+	}
+	ui.DefaultTemplate().PrintBox("⏱️  SYSTEM ANALYSIS TIMINGS", append(lines, ""))
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/transcript.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// TranscriptFile is the state file holding the transcript of the last quest
+const TranscriptFile = "last-run.yaml"
+
+// Transcript records what happened during the last quest so it can be attached to an issue.
+// Everything in it has already been redacted.
+type Transcript struct {
+	AppVersion string    `yaml:"app_version"`
+	Time       time.Time `yaml:"time"`
+	Provider   string    `yaml:"provider"`
+	Model      string    `yaml:"model"`
+	Mode       string    `yaml:"mode"`
+	Intent     string    `yaml:"intent"`
+	Context    string    `yaml:"context,omitempty"`
+
+	System TranscriptSystem `yaml:"system"`
+
+	Exchanges []ai.Exchange `yaml:"exchanges"`
+
+	Content       string `yaml:"content,omitempty"` // the final command or script
+	IsScript      bool   `yaml:"is_script"`
+	Approved      bool   `yaml:"approved"`
+	Executed      bool   `yaml:"executed"`
+	FixAttempts   int    `yaml:"fix_attempts,omitempty"`
+	ExitCode      int    `yaml:"exit_code"`
+	Error         string `yaml:"error,omitempty"`
+	ErrorOutput   string `yaml:"error_output,omitempty"`
+	PipelineError string `yaml:"pipeline_error,omitempty"`
+}
+
+// TranscriptSystem is the part of the system analysis that helps debugging without identifying the user
+type TranscriptSystem struct {
+	OS                string   `yaml:"os"`
+	Shell             string   `yaml:"shell"`
+	PackageManagers   []string `yaml:"package_managers"`
+	InstalledPackages int      `yaml:"installed_packages"`
+	AvailableCommands int      `yaml:"available_commands"`
+}
+
+// NewTranscript captures a finished quest. The API key, the home directory, and anything
+// shaped like a credential are redacted from every field.
+func NewTranscript(q *Quest, exchanges []ai.Exchange, runErr error, appVersion string) *Transcript {
+	t := &Transcript{
+		AppVersion:  appVersion,
+		Time:        time.Now().UTC().Truncate(time.Second),
+		Intent:      q.Intent,
+		Context:     q.ContextName,
+		Content:     q.Content,
+		IsScript:    q.IsScript,
+		Approved:    q.Approved,
+		Executed:    q.Executed,
+		FixAttempts: q.FixAttempts,
+		ExitCode:    -1,
+		Exchanges:   append([]ai.Exchange(nil), exchanges...),
+	}
+
+	var apiKey, homeDir string
+	if q.Config != nil {
+		t.Provider, t.Model, t.Mode = q.Config.AIProvider, q.Config.Model, q.Config.Mode
+		apiKey = q.Config.APIKey
+	}
+	if q.SysInfo != nil {
+		homeDir = q.SysInfo.HomeDir
+		t.System = TranscriptSystem{
+			OS:                q.SysInfo.OS,
+			Shell:             q.SysInfo.Shell,
+			PackageManagers:   q.SysInfo.PackageManagers,
+			InstalledPackages: len(q.SysInfo.InstalledPackages),
+			AvailableCommands: len(q.SysInfo.AvailableCommands),
+		}
+	}
+	if homeDir == "" {
+		homeDir, _ = os.UserHomeDir()
+	}
+
+	if q.ExecErr != nil {
+		t.Error = q.ExecErr.Error()
+		t.ErrorOutput = system.ExecutionOutput(q.ExecErr)
+		var execErr *system.ExecutionError
+		if errors.As(q.ExecErr, &execErr) {
+			t.ExitCode = execErr.ExitCode
+		}
+	} else if q.Executed {
+		t.ExitCode = 0
+	}
+	if runErr != nil {
+		t.PipelineError = runErr.Error()
+	}
+
+	t.redact(system.NewRedactor(homeDir, apiKey))
+	return t
+}
+
+func (t *Transcript) redact(r *system.Redactor) {
+	for _, field := range []*string{&t.Intent, &t.Content, &t.Error, &t.ErrorOutput, &t.PipelineError} {
+		*field = r.Redact(*field)
+	}
+	for i := range t.Exchanges {
+		t.Exchanges[i].Prompt = r.Redact(t.Exchanges[i].Prompt)
+		t.Exchanges[i].Response = r.Redact(t.Exchanges[i].Response)
+		t.Exchanges[i].Error = r.Redact(t.Exchanges[i].Error)
+	}
+}
+
+// LoadTranscript reads a saved transcript. It returns nil without an error when none exists.
+func LoadTranscript(path string) (*Transcript, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last quest's transcript: %w", err)
+	}
+
+	var t Transcript
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse the last quest's transcript: %w", err)
+	}
+	return &t, nil
+}
+
+// Save writes the transcript, creating its directory if needed
+func (t *Transcript) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Outcome summarises how the quest ended in a few words
+func (t *Transcript) Outcome() string {
+	switch {
+	case t.PipelineError != "":
+		return "stopped by an error"
+	case t.Content == "":
+		return "no command was proposed"
+	case !t.Approved:
+		return "declined"
+	case !t.Executed:
+		return "not executed"
+	case t.Error != "":
+		return "failed"
+	default:
+		return "succeeded"
+	}
+}
+
+// Markdown renders the transcript as an issue-ready markdown document
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# execute-my-will quest report\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	rows := [][2]string{
+		{"Version", t.AppVersion},
+		{"Recorded", t.Time.Format(time.RFC3339)},
+		{"Provider", t.Provider},
+		{"Model", t.Model},
+		{"Mode", t.Mode},
+		{"OS", t.System.OS},
+		{"Shell", t.System.Shell},
+		{"Package managers", strings.Join(t.System.PackageManagers, ", ")},
+		{"Installed packages", fmt.Sprintf("%d detected", t.System.InstalledPackages)},
+		{"Available commands", fmt.Sprintf("%d detected", t.System.AvailableCommands)},
+		{"Outcome", t.Outcome()},
+	}
+	if t.Context != "" {
+		rows = append(rows, [2]string{"Context", t.Context})
+	}
+	if t.Executed {
+		rows = append(rows, [2]string{"Exit code", fmt.Sprintf("%d", t.ExitCode)})
+	}
+	if t.FixAttempts > 0 {
+		rows = append(rows, [2]string{"Auto-fix attempts", fmt.Sprintf("%d", t.FixAttempts)})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], strings.ReplaceAll(row[1], "|", `\|`))
+	}
+
+	writeSection(&b, "Intent", t.Intent)
+	if t.IsScript {
+		writeSection(&b, "Final script", t.Content)
+	} else {
+		writeSection(&b, "Final command", t.Content)
+	}
+	writeSection(&b, "Error", t.Error)
+	writeSection(&b, "Error output", t.ErrorOutput)
+	writeSection(&b, "Pipeline error", t.PipelineError)
+
+	if len(t.Exchanges) > 0 {
+		b.WriteString("\n## Oracle exchanges\n")
+	}
+	for i, exchange := range t.Exchanges {
+		fmt.Fprintf(&b, "\n### Exchange %d\n", i+1)
+		writeDetails(&b, "Prompt", exchange.Prompt)
+		writeDetails(&b, "Response", exchange.Response)
+		writeDetails(&b, "Error", exchange.Error)
+	}
+
+	return b.String()
+}
+
+func writeSection(b *strings.Builder, title, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n%s", title, codeBlock(text))
+}
+
+func writeDetails(b *strings.Builder, summary, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	fmt.Fprintf(b, "\n<details><summary>%s</summary>\n\n%s\n</details>\n", summary, codeBlock(text))
+}
+
+// codeBlock fences text with more backticks than it contains, so embedded fences survive
+func codeBlock(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fmt.Sprintf("%s\n%s\n%s\n", fence, strings.TrimRight(text, "\n"), fence)
+}
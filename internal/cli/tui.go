@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/tui.go
+package cli
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/tui"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the full-screen quest chamber",
+	Long:  "Start a keyboard-driven, full-screen interface with panes for your intent, the proposed command, its explanation, and live output.",
+	Args:  cobra.NoArgs,
+	RunE:  runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	ui.PrintPhaseHeader("🧙", "Surveying the realm before opening the quest chamber...")
+	sysInfo, err := newAnalyzer(cfg).AnalyzeSystem()
+	if err != nil {
+		return fmt.Errorf("failed to analyze the realm's systems, my lord: %w", err)
+	}
+
+	aiClient, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+
+	// Quests run in the TUI are remembered like any other
+	deps := DefaultPipelineDeps(aiClient)
+	hooks := tui.Hooks{
+		Risk: func(p tui.Proposal) system.RiskLevel {
+			return questRisk(&Quest{Response: p.Response, Content: p.Content, IsScript: p.IsScript})
+		},
+		Reauthenticate: system.Reauthenticate,
+		Record: func(p tui.Proposal, runErr error) {
+			quest := &Quest{
+				Intent:   p.Intent,
+				Config:   cfg,
+				SysInfo:  sysInfo,
+				Response: p.Response,
+				Content:  p.Content,
+				IsScript: p.IsScript,
+				Approved: true,
+				Executed: true,
+				ExecErr:  runErr,
+			}
+			rememberQuest(deps.History, quest)
+			recordInJournal(deps.Journal, quest)
+		},
+		History: deps.History,
+	}
+	program := tea.NewProgram(tui.NewModel(cfg, sysInfo, aiClient, hooks), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("the quest chamber collapsed, sire: %w", err)
+	}
+	return nil
+}
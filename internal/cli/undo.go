@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/undo.go
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:     "undo [number|last]",
+	Aliases: []string{"rollback"},
+	Short:   "Ask the oracle how to reverse an earlier quest, and run it once you approve",
+	Long: `Have the oracle write a command or script that reverses what an earlier quest changed: removing what it created, uninstalling what it installed, or restoring what it moved. When its changes cannot be undone, such as files deleted without a backup, the oracle says so and nothing is run.
+
+Without an argument the most recent quest that changed something is undone. Quests are numbered in 'execute-my-will history'. The reversal is reviewed and confirmed like any other quest, and you are warned when it is more destructive than the quest it reverses.`,
+	Example: `  execute-my-will undo
+  execute-my-will undo 42`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndo,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if haltedByKillSwitch(cfg) {
+		return nil
+	}
+
+	journal, err := history.LoadJournal(config.StatePath(history.JournalFile))
+	if err != nil {
+		return err
+	}
+	client, err := ai.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summon the oracle, my lord: %w", err)
+	}
+	deps := DefaultPipelineDeps(client)
+	deps.Analyzer = newAnalyzer(cfg)
+	deps.Journal = journal
+	deps.Executor = questExecutor(deps.Executor, cfg)
+
+	ref := "last"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	quest, runErr := (&Undo{Journal: journal, Deps: deps}).Run(ref, cfg)
+	if quest == nil {
+		return runErr
+	}
+	_ = NewTranscript(quest, client.Exchanges(), runErr, appVersion).Save(config.StatePath(TranscriptFile))
+	recordInJournal(deps.Journal, quest)
+	return runErr
+}
+
+// Undo reverses a quest from the journal with a command or script the oracle writes for it
+type Undo struct {
+	Journal *history.Journal
+	Deps    PipelineDeps
+}
+
+// Run finds the quest named by ref, a number from the journal or "last", and has the oracle
+// propose its reversal, which is reviewed and confirmed like any other quest. It returns the new
+// quest, or nil when there is nothing to undo.
+func (u *Undo) Run(ref string, cfg *config.Config) (*Quest, error) {
+	record, err := u.find(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Quest{Intent: fmt.Sprintf("undo quest #%d: %s", record.ID, record.Intent), Config: cfg, ContextName: record.Context, Undo: record}
+	if intentContext, ok := cfg.Contexts[record.Context]; ok && record.Context != "" {
+		q.Context = &intentContext
+	}
+
+	message := fmt.Sprintf("\"%s\", carried out on %s, my lord. I shall ask the oracle how its %s can be reversed.", record.Intent, ui.FormatTimestamp(record.At), proposalKind(record))
+	if record.Failed() {
+		message += " It failed partway, so only some of its changes may need undoing."
+	}
+	ui.PrintStatusBox(fmt.Sprintf("↩️  UNDOING QUEST #%d", record.ID), message, "info")
+	return q, NewUndoPipeline(u.Deps).Run(q)
+}
+
+// find resolves a reference to a quest of the journal that can be undone
+func (u *Undo) find(ref string) (*history.Record, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "#")
+	if strings.EqualFold(ref, "last") {
+		for i := len(u.Journal.Records) - 1; i >= 0; i-- {
+			if record := u.Journal.Records[i]; u.undoable(record) == nil {
+				return record, nil
+			}
+		}
+		return nil, fmt.Errorf("no earlier quest changed anything that could be undone, my lord")
+	}
+
+	id, err := strconv.Atoi(ref)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("'%s' is neither a quest number nor 'last', my lord; 'execute-my-will history' lists the numbers", ref)
+	}
+	record := u.Journal.Find(id)
+	if record == nil {
+		return nil, fmt.Errorf("quest #%d is not in the journal, my lord; 'execute-my-will history' lists the quests kept", id)
+	}
+	if err := u.undoable(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// undoable says why a recorded quest cannot be undone, or returns nil when it can
+func (u *Undo) undoable(record *history.Record) error {
+	switch {
+	case !record.Executed || record.Content == "":
+		return fmt.Errorf("quest #%d never ran, my lord; there is nothing to undo", record.ID)
+	case record.Undoes != 0:
+		return fmt.Errorf("quest #%d was itself the undoing of quest #%d, my lord; run 'execute-my-will redo %d' to carry that quest out again", record.ID, record.Undoes, record.Undoes)
+	case contentRisk(record.Content, record.IsScript) == system.RiskReadOnly:
+		return fmt.Errorf("quest #%d only read and changed nothing, my lord; there is nothing to undo", record.ID)
+	}
+	if undoneBy := u.Journal.UndoneBy(record.ID); undoneBy != nil {
+		return fmt.Errorf("quest #%d was already undone by quest #%d, my lord", record.ID, undoneBy.ID)
+	}
+	return nil
+}
+
+// NewUndoPipeline builds the pipeline of the 'undo' subcommand:
+// analyze → rollback → airgap → flags → verify → review → confirm → reauth → elevate → execute → report
+// The reversal is the oracle's proposal like any other, so it passes the same checks.
+func NewUndoPipeline(deps PipelineDeps) *Pipeline {
+	return &Pipeline{stages: []Stage{
+		&analyzeStage{analyzer: deps.Analyzer},
+		&rollbackStage{client: deps.AIClient},
+		&airGapStage{},
+		&flagsStage{client: deps.AIClient, check: deps.CheckFlags},
+		&verifyDownloadsStage{client: deps.AIClient},
+		&reviewStage{client: deps.AIClient, newEnvValidator: deps.NewEnvValidator, prompter: deps.Prompter},
+		&confirmStage{confirmer: deps.Confirmer},
+		&reauthStage{reauthenticate: deps.Reauthenticate},
+		&elevateStage{prompter: deps.Prompter, isElevated: deps.IsElevated},
+		&executeStage{executor: deps.Executor},
+		&reportStage{},
+	}}
+}
+
+// rollbackStage asks the oracle to reverse the quest being undone. It warns when the reversal
+// is more destructive than the quest was, as when undoing a mkdir proposes an rm -rf.
+type rollbackStage struct {
+	client ai.Client
+}
+
+func (s *rollbackStage) Name() string { return "rollback" }
+
+func (s *rollbackStage) Run(q *Quest) (bool, error) {
+	if haltedByKillSwitch(q.Config) {
+		return false, nil
+	}
+	record := q.Undo
+
+	response, err := s.client.GenerateRollback(record.Intent, ai.Attempt{Content: record.Content, Error: record.Error}, q.SysInfo)
+	if err != nil {
+		return false, oracleFailure(err)
+	}
+	q.Response = response
+
+	switch response.Type {
+	case ai.ResponseTypeFailure:
+		ui.PrintStatusBox("↩️  CANNOT BE UNDONE", fmt.Sprintf("The oracle found no safe way to undo quest #%d, my lord: %s", record.ID, response.Error), "error")
+		return false, nil
+	case ai.ResponseTypeAnswer:
+		ui.PrintStatusBox("↩️  CANNOT BE UNDONE", fmt.Sprintf("The oracle answered instead of proposing a reversal, my lord:\n\n%s", response.Content), "warning")
+		return false, nil
+	case ai.ResponseTypeScript:
+		q.IsScript = true
+	default:
+		q.IsScript = false
+	}
+	q.Content = response.Content
+
+	undone, reversal := contentRisk(record.Content, record.IsScript), contentRisk(q.Content, q.IsScript)
+	if reversal == system.RiskDestructive && reversal > undone {
+		ui.PrintStatusBox("⚠️  A HEAVIER HAND", fmt.Sprintf("Undoing quest #%d is destructive, while the quest itself only %s, my lord. Check what the reversal removes before you approve it.", record.ID, riskVerb(undone)), "warning")
+	}
+	if record.Dir != "" && q.SysInfo != nil && q.SysInfo.CurrentDir != "" && record.Dir != q.SysInfo.CurrentDir {
+		ui.PrintStatusBox("📁 ANOTHER DIRECTORY", fmt.Sprintf("Quest #%d was run in %s, my lord, and you are now in %s. A reversal with relative paths would miss its changes; undo it from there.", record.ID, record.Dir, q.SysInfo.CurrentDir), "warning")
+	}
+	return true, nil
+}
+
+// riskVerb says what a quest of the given risk did, for messages
+func riskVerb(risk system.RiskLevel) string {
+	switch risk {
+	case system.RiskReadOnly:
+		return "read"
+	case system.RiskElevated:
+		return "ran with elevated rights"
+	case system.RiskDestructive:
+		return "destroyed"
+	default:
+		return "made changes"
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/wait.go
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait until a port, URL, or process is ready (the emw-wait helper of generated scripts)",
+	Long: `Poll a port, a URL, or a process until it is ready, or until it is gone with --gone, and fail when the timeout passes first.
+
+Generated commands and scripts call this as 'emw-wait' instead of writing their own sleep loops, for example:
+
+  sudo systemctl restart nginx && emw-wait --url http://localhost/ --timeout 30s
+  emw-wait --port 5432
+  emw-wait --process apt-get --gone --timeout 5m`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runWait,
+}
+
+func init() {
+	waitCmd.Flags().String("port", "", "Wait until this port accepts TCP connections ('8080' on localhost, or 'host:port')")
+	waitCmd.Flags().String("url", "", "Wait until this URL answers with a 2xx or 3xx status")
+	waitCmd.Flags().String("process", "", "Wait until a process with this name is running")
+	waitCmd.Flags().Bool("gone", false, "Wait until the port, URL, or process is gone instead")
+	waitCmd.Flags().Duration("timeout", 60*time.Second, "Give up after this long")
+	waitCmd.Flags().Duration("interval", time.Second, "Time between checks")
+	rootCmd.AddCommand(waitCmd)
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	var condition system.WaitCondition
+	condition.Port, _ = cmd.Flags().GetString("port")
+	condition.URL, _ = cmd.Flags().GetString("url")
+	condition.Process, _ = cmd.Flags().GetString("process")
+	condition.Gone, _ = cmd.Flags().GetBool("gone")
+	condition.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	condition.Interval, _ = cmd.Flags().GetDuration("interval")
+
+	if err := condition.Validate(); err != nil {
+		ui.PrintPlain(fmt.Sprintf("✘ %s: %v", system.WaitHelper, err))
+		return err
+	}
+
+	ui.PrintPlain(fmt.Sprintf("⏳ Waiting up to %s for %s...", condition.Timeout, condition))
+	start := time.Now()
+	if err := system.WaitFor(condition); err != nil {
+		// The calling script sees the failure through the exit status
+		ui.PrintPlain(fmt.Sprintf("✘ %s: %v", system.WaitHelper, err))
+		return err
+	}
+	ui.PrintPlain(fmt.Sprintf("✔ Ready after %s", ui.FormatDuration(time.Since(start))))
+	return nil
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/cli/workspace.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// ProjectStatus is how a workspace quest ended in one project
+type ProjectStatus string
+
+const (
+	ProjectSkipped   ProjectStatus = "skipped"
+	ProjectDeclined  ProjectStatus = "declined"
+	ProjectSucceeded ProjectStatus = "succeeded"
+	ProjectFailed    ProjectStatus = "failed"
+)
+
+// ProjectResult is the outcome of a workspace quest in one project
+type ProjectResult struct {
+	Dir      string
+	Label    string
+	Quest    *Quest
+	Status   ProjectStatus
+	Note     string
+	Duration time.Duration
+}
+
+// WorkspaceRun runs one intent in every project of a workspace: a quest is proposed for each
+// project, a single approval covers them all, and the approved quests run one after another
+// or in parallel
+type WorkspaceRun struct {
+	Workspace *config.Workspace
+	Parallel  bool
+	Deps      PipelineDeps
+}
+
+// Run proposes, confirms, and executes the quest in every project, then prints a result table
+func (w *WorkspaceRun) Run(base *Quest) ([]*ProjectResult, error) {
+	proceed, err := runStages(base,
+		&analyzeStage{analyzer: w.Deps.Analyzer},
+		&validateStage{newValidator: w.Deps.NewIntentValidator, prompter: w.Deps.Prompter},
+	)
+	if err != nil || !proceed {
+		return nil, err
+	}
+
+	results := w.propose(base)
+
+	var ready []*ProjectResult
+	for _, result := range results {
+		if result.Status == "" {
+			ready = append(ready, result)
+		}
+	}
+	if len(ready) == 0 {
+		printWorkspaceResults(results)
+		return results, nil
+	}
+
+	ui.PrintInfoMessage(fmt.Sprintf("The quest is ready in %d of %d projects.", len(ready), len(results)))
+	if critical := criticalProjects(ready); len(critical) > 0 {
+		ui.PrintStatusBox("🛑 CRITICAL DIRECTORY", fmt.Sprintf("In %s the quest uses relative paths or globs in a directory your system depends on, my lord. Each of them must be approved on its own by typing that directory.", strings.Join(critical, ", ")), "warning")
+	}
+	for _, result := range ready {
+		base.Covers = append(base.Covers, result.Quest)
+	}
+	approved, err := runStages(base, &confirmStage{confirmer: w.Deps.Confirmer})
+	if err != nil {
+		return results, err
+	}
+	if approved {
+		ready, err = w.confirmCriticalDirectories(ready)
+		if err != nil {
+			return results, err
+		}
+		approved = len(ready) > 0
+	}
+	if approved {
+		approved, err = w.authorize(ready)
+		if err != nil {
+			return results, err
+		}
+	}
+	if !approved {
+		for _, result := range ready {
+			result.Status = ProjectDeclined
+		}
+		return results, nil
+	}
+
+	w.execute(ready)
+	printWorkspaceResults(results)
+	return results, nil
+}
+
+// propose generates and reviews a quest for every project. Results left without a status are ready to run.
+func (w *WorkspaceRun) propose(base *Quest) []*ProjectResult {
+	var results []*ProjectResult
+	for _, dir := range w.Workspace.Projects {
+		result := &ProjectResult{Dir: dir, Label: filepath.Base(dir)}
+		results = append(results, result)
+
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			result.Status, result.Note = ProjectSkipped, "directory not found"
+			continue
+		}
+
+		ui.PrintPhaseHeader("📁", fmt.Sprintf("Project %s (%s)", result.Label, dir))
+
+		sysInfo := *base.SysInfo
+		sysInfo.CurrentDir = dir
+		sysInfo.ProjectTypes = system.DetectProjectTypes(dir)
+		sysInfo.Nix = system.DetectNixEnvironment(dir, os.Getenv)
+		sysInfo.Nix.NixOS = base.SysInfo.Nix.NixOS
+		sysInfo.Direnv = system.DetectDirenv(dir, os.Getenv)
+		sysInfo.Direnv.Installed = base.SysInfo.Direnv.Installed
+		q := &Quest{
+			Intent:      base.Intent,
+			Config:      base.Config,
+			SysInfo:     &sysInfo,
+			ExplainOnly: base.ExplainOnly,
+			DryRun:      base.DryRun,
+			AirGapped:   base.AirGapped,
+			ContextName: base.ContextName,
+			Context:     base.Context,
+			Clipboard:   base.Clipboard,
+			Piped:       base.Piped,
+		}
+		result.Quest = q
+
+		proceed, err := runStages(q,
+			&generateStage{client: w.Deps.AIClient},
+			&airGapStage{},
+			&flagsStage{client: w.Deps.AIClient, check: w.Deps.CheckFlags},
+			&verifyDownloadsStage{client: w.Deps.AIClient},
+			&reviewStage{client: w.Deps.AIClient, newEnvValidator: w.Deps.NewEnvValidator},
+			&repeatStage{history: w.Deps.History, now: time.Now},
+		)
+		switch {
+		case err != nil:
+			result.Status, result.Note = ProjectSkipped, err.Error()
+		case !proceed:
+			result.Status, result.Note = ProjectSkipped, "no runnable quest was proposed"
+		}
+	}
+	return results
+}
+
+// criticalProjects returns the labels of the projects whose quest acts on a critical directory
+func criticalProjects(ready []*ProjectResult) []string {
+	var labels []string
+	for _, result := range ready {
+		if result.Quest.CriticalDir != nil {
+			labels = append(labels, result.Label)
+		}
+	}
+	return labels
+}
+
+// confirmCriticalDirectories asks again in every project whose quest acts on a critical directory,
+// with the directory typed as for a single quest. A project declined there is left out, and the
+// projects still to run are returned.
+func (w *WorkspaceRun) confirmCriticalDirectories(ready []*ProjectResult) ([]*ProjectResult, error) {
+	var confirmed []*ProjectResult
+	for _, result := range ready {
+		if result.Quest.CriticalDir != nil {
+			ui.PrintPhaseHeader("📁", fmt.Sprintf("Project %s (%s)", result.Label, result.Dir))
+			approved, err := runStages(result.Quest, &confirmStage{confirmer: w.Deps.Confirmer})
+			if err != nil {
+				return nil, err
+			}
+			if !approved {
+				result.Status = ProjectDeclined
+				continue
+			}
+		}
+		confirmed = append(confirmed, result)
+	}
+	return confirmed, nil
+}
+
+// authorize runs the gates between the approval and the execution of the workspace quest. One
+// re-authentication covers every project, so it is asked for the riskiest of them, and each
+// project that needs Administrator rights may be elevated.
+func (w *WorkspaceRun) authorize(ready []*ProjectResult) (bool, error) {
+	riskiest := ready[0].Quest
+	for _, result := range ready[1:] {
+		if questRisk(result.Quest) > questRisk(riskiest) {
+			riskiest = result.Quest
+		}
+	}
+	proceed, err := runStages(riskiest, &reauthStage{reauthenticate: w.Deps.Reauthenticate})
+	if err != nil || !proceed {
+		return false, err
+	}
+
+	for _, result := range ready {
+		if _, err := runStages(result.Quest, &elevateStage{prompter: w.Deps.Prompter, isElevated: w.Deps.IsElevated}); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// execute runs the approved quests, one after another or all at once
+func (w *WorkspaceRun) execute(ready []*ProjectResult) {
+	ui.PrintLine("🛡️ ", "Executing your quest across the workspace with honor...")
+	ui.PrintBlankLine()
+
+	run := func(result *ProjectResult) {
+		q := result.Quest
+		executor := w.Deps.NewProjectExecutor(result.Dir, result.Label, w.Parallel)
+
+		start := time.Now()
+		if q.IsScript {
+			q.ExecErr = executor.ExecuteScript(q.Content, q.SysInfo.Shell, q.Config.Mode == "royal-heir")
+		} else if q.Elevate {
+			q.ExecErr = executor.ExecuteElevated(q.Content, q.SysInfo.Shell)
+		} else {
+			q.ExecErr = executor.Execute(q.Content, q.SysInfo.Shell)
+		}
+		q.Approved, q.Executed = true, true
+		result.Duration = time.Since(start)
+
+		if q.ExecErr != nil {
+			result.Status, result.Note = ProjectFailed, q.ExecErr.Error()
+		} else {
+			result.Status = ProjectSucceeded
+		}
+	}
+
+	// The kill switch may be engaged while the workspace awaits approval or between projects;
+	// the projects not yet started are then left alone
+	halted := func(pending []*ProjectResult) bool {
+		if !haltedByKillSwitch(pending[0].Quest.Config) {
+			return false
+		}
+		for _, result := range pending {
+			result.Status, result.Note = ProjectSkipped, "halted by the kill switch"
+		}
+		return true
+	}
+
+	if !w.Parallel {
+		for i, result := range ready {
+			if halted(ready[i:]) {
+				return
+			}
+			run(result)
+		}
+		return
+	}
+
+	if halted(ready) {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, result := range ready {
+		wg.Add(1)
+		go func(result *ProjectResult) {
+			defer wg.Done()
+			run(result)
+		}(result)
+	}
+	wg.Wait()
+}
+
+// printWorkspaceResults shows one line per project
+func printWorkspaceResults(results []*ProjectResult) {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.Label))
+	}
+
+	lines := []string{""}
+	for _, result := range results {
+		var mark string
+		switch result.Status {
+		case ProjectSucceeded:
+			mark = ui.Green.Sprint("✔")
+		case ProjectFailed:
+			mark = ui.Red.Sprint("✘")
+		default:
+			mark = ui.Gray.Sprint("–")
+		}
+
+		line := fmt.Sprintf("%s %-*s  %s", mark, width, result.Label, result.Status)
+		if result.Duration > 0 {
+			line += " in " + ui.FormatDuration(result.Duration)
+		}
+		if result.Note != "" {
+			line += ui.Gray.Sprint(" — " + result.Note)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+
+	ui.DefaultTemplate().PrintBox("📋 WORKSPACE RESULTS", lines)
+}
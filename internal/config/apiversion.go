@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/config/apiversion.go
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultAPIVersions are the provider API versions used when none is pinned in the
+// "api_versions" section
+var DefaultAPIVersions = map[string]string{
+	"anthropic":    "2023-06-01", // the anthropic-version header
+	"azure-openai": "2024-10-21", // the api-version query parameter of Azure OpenAI
+	"gemini":       "v1beta",     // the path segment of the Generative Language API
+	"openai":       "v1",         // the path segment of the OpenAI API
+}
+
+// apiVersionFormats describe what a version looks like for each provider
+var apiVersionFormats = map[string]*regexp.Regexp{
+	"anthropic":    regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+	"azure-openai": regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:-preview)?$`),
+	"gemini":       regexp.MustCompile(`^v\d+(?:(?:alpha|beta)\d*)?$`),
+	"openai":       regexp.MustCompile(`^v\d+$`),
+}
+
+// sunsetAPIVersions are versions a provider has retired or replaced, with the reason
+var sunsetAPIVersions = map[string]map[string]string{
+	"anthropic": {
+		"2023-01-01": "it was replaced by 2023-06-01, which changed the response and error formats",
+	},
+	"gemini": {
+		"v1beta2": "it belongs to the retired PaLM API",
+		"v1beta3": "it belongs to the retired PaLM API",
+	},
+}
+
+// APIVersion returns the API version pinned for the provider, or the provider's default
+func (c *Config) APIVersion(provider string) string {
+	if version := c.APIVersions[provider]; version != "" {
+		return version
+	}
+	return DefaultAPIVersions[provider]
+}
+
+// APIVersionWarning explains why the configured provider's pinned API version should be
+// changed, or returns an empty string when it is fine
+func (c *Config) APIVersionWarning() string {
+	version := c.APIVersion(c.AIProvider)
+	reason, sunset := sunsetAPIVersions[c.AIProvider][version]
+	if !sunset {
+		return ""
+	}
+	return fmt.Sprintf("The %s API version '%s' is sunset: %s. Requests may fail; change api_versions.%s to %s or remove it to use the default.",
+		c.AIProvider, version, reason, c.AIProvider, DefaultAPIVersions[c.AIProvider])
+}
+
+func validateAPIVersions(versions map[string]string) error {
+	for provider, version := range versions {
+		format, known := apiVersionFormats[provider]
+		if known && !format.MatchString(version) {
+			return fmt.Errorf("invalid API version '%s' for '%s'. Expected a version like '%s'", version, provider, DefaultAPIVersions[provider])
+		}
+	}
+	return nil
+}
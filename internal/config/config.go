@@ -7,8 +7,16 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,11 +27,443 @@ type Config struct {
 	Model       string  `yaml:"model"`
 	MaxTokens   int     `yaml:"max_tokens"`
 	Temperature float32 `yaml:"temperature"`
-	Mode        string  `yaml:"mode"` // field for monarch/royal-heir modes
+	Mode        string  `yaml:"mode"`               // field for monarch/royal-heir modes
+	BaseURL     string  `yaml:"base_url,omitempty"` // root of an OpenAI-compatible server for the openai provider, e.g. http://localhost:1234/v1
+
+	UI          UIConfig                 `yaml:"-"` // stored under the top-level "ui" section
+	Privacy     PrivacyConfig            `yaml:"-"` // stored under the top-level "privacy" section
+	Contexts    map[string]IntentContext `yaml:"-"` // stored under the top-level "contexts" section
+	RateLimits  map[string]RateLimit     `yaml:"-"` // stored under the top-level "rate_limits" section, keyed by provider
+	Glossary    map[string]string        `yaml:"-"` // stored under the top-level "glossary" section, term to meaning
+	Facts       []string                 `yaml:"-"` // stored under the top-level "facts" section, sent with every quest
+	APIVersions map[string]string        `yaml:"-"` // stored under the top-level "api_versions" section, keyed by provider
+	Budget      Budget                   `yaml:"-"` // stored under the top-level "budget" section
+	Execution   ExecutionConfig          `yaml:"-"` // stored under the top-level "execution" section
+	Analysis    AnalysisConfig           `yaml:"-"` // stored under the top-level "analysis" section
+	Postmortem  PostmortemConfig         `yaml:"-"` // stored under the top-level "postmortem" section
+	Spellbook   SpellbookConfig          `yaml:"-"` // stored under the top-level "spellbook" section
+	Mock        MockConfig               `yaml:"-"` // stored under the top-level "mock" section
+	Azure       AzureConfig              `yaml:"-"` // stored under the top-level "azure" section
+	OpenRouter  OpenRouterConfig         `yaml:"-"` // stored under the top-level "openrouter" section
+
+	Migration *MigrationResult `yaml:"-"` // set when Load upgraded an older config file
+}
+
+// IntentContext is applied to intents starting with its name followed by a colon, e.g. "prod: restart nginx"
+type IntentContext struct {
+	Context           string `yaml:"context"`            // extra context given to the AI
+	TypedConfirmation bool   `yaml:"typed_confirmation"` // require typing the context name instead of y/N
+	DisableAutoFix    bool   `yaml:"disable_auto_fix"`   // never retry failures automatically
+}
+
+// RateLimit caps how quickly requests are sent to a provider. Zero means unlimited.
+type RateLimit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute"`
+}
+
+// Enabled reports whether any limit is set
+func (r RateLimit) Enabled() bool {
+	return r.RequestsPerMinute > 0 || r.TokensPerMinute > 0
+}
+
+// defaultBudgetSwitchAt is the share of the monthly budget at which the fallback model takes over
+const defaultBudgetSwitchAt = 0.9
+
+// Budget caps the estimated tokens spent each calendar month. Zero means no budget.
+type Budget struct {
+	MonthlyTokens int     `yaml:"monthly_tokens"`
+	FallbackModel string  `yaml:"fallback_model,omitempty"` // a cheaper model of the configured provider
+	SwitchAt      float64 `yaml:"switch_at,omitempty"`      // share of the budget at which to switch, 0.9 when unset
+}
+
+// Enabled reports whether a monthly budget is set
+func (b Budget) Enabled() bool {
+	return b.MonthlyTokens > 0
+}
+
+// SwitchThreshold returns the number of tokens after which the fallback model is used
+func (b Budget) SwitchThreshold() int {
+	share := b.SwitchAt
+	if share == 0 {
+		share = defaultBudgetSwitchAt
+	}
+	return int(float64(b.MonthlyTokens) * share)
+}
+
+// ExecutionConfig shapes the processes that run commands and scripts
+type ExecutionConfig struct {
+	Env          map[string]string `yaml:"env,omitempty"`           // set for every command, e.g. DEBIAN_FRONTEND: noninteractive
+	KillSwitch   string            `yaml:"kill_switch,omitempty"`   // a file whose presence disables quests, besides the machine-wide one
+	CommandsOnly bool              `yaml:"commands_only,omitempty"` // propose a command even for questions instead of answering them
+	// ReadOnlyDefaultYes makes Enter approve read-only quests in monarch mode; anything riskier still defaults to no
+	ReadOnlyDefaultYes bool `yaml:"read_only_default_yes,omitempty"`
+	// Reauthenticate asks the operating system to confirm who is at the terminal, with a password,
+	// Touch ID, or Windows Hello, before a destructive quest runs
+	Reauthenticate bool `yaml:"reauthenticate,omitempty"`
+	// RepeatWarningMinutes is how long after a destructive command or script ran that running it
+	// again is called out before confirmation; 15 when unset, and negative turns the warning off
+	RepeatWarningMinutes int `yaml:"repeat_warning_minutes,omitempty"`
+	// MaxLineSize is how many bytes of a single output line are printed before the rest of it is
+	// skipped; 1 MiB when unset
+	MaxLineSize int `yaml:"max_line_size,omitempty"`
+}
+
+// defaultRepeatWarningMinutes is how long a destructive run is called out when run again, unless configured
+const defaultRepeatWarningMinutes = 15
+
+// RepeatWindow returns how long after a destructive run the same run is called out, or zero when
+// the warning is turned off
+func (e ExecutionConfig) RepeatWindow() time.Duration {
+	switch {
+	case e.RepeatWarningMinutes < 0:
+		return 0
+	case e.RepeatWarningMinutes == 0:
+		return defaultRepeatWarningMinutes * time.Minute
+	}
+	return time.Duration(e.RepeatWarningMinutes) * time.Minute
+}
+
+// KillSwitchPath returns the configured kill-switch file with "~" expanded, or "" when none is set
+func (e ExecutionConfig) KillSwitchPath() string {
+	path := strings.TrimSpace(e.KillSwitch)
+	if path == "" {
+		return ""
+	}
+	return expandHome(path)
+}
+
+// AnalysisConfig controls how much of the system is examined before each quest
+type AnalysisConfig struct {
+	SystemScan *bool `yaml:"system_scan,omitempty"` // enumerate packages and commands; defaults to true
+}
+
+// ScanSystem reports whether installed packages and available commands are enumerated. Without
+// the scan only the OS, shell, and current directory are gathered.
+func (a AnalysisConfig) ScanSystem() bool { return isAllowed(a.SystemScan) }
+
+// PostmortemConfig controls where incident notes about failed quests are written
+type PostmortemConfig struct {
+	Dir string `yaml:"dir,omitempty"` // e.g. a directory in the team's runbook repository
+}
+
+// NotesDir returns the directory for incident notes, with "~" expanded. Without a configured
+// directory the notes are kept with the other local state.
+func (p PostmortemConfig) NotesDir() string {
+	dir := strings.TrimSpace(p.Dir)
+	if dir == "" {
+		return StatePath("postmortems")
+	}
+	return expandHome(dir)
+}
+
+// SpellbookConfig points at the spellbook a team shares its vetted spells through
+type SpellbookConfig struct {
+	Source            string            `yaml:"source,omitempty"`             // a git repository holding spellbook.yaml, or an http(s) URL of the file
+	Keys              map[string]string `yaml:"keys,omitempty"`               // signer name to base64 Ed25519 public key
+	RequireSignatures bool              `yaml:"require_signatures,omitempty"` // drop spells no listed key has signed
+}
+
+// Enabled reports whether a shared spellbook is configured
+func (s SpellbookConfig) Enabled() bool {
+	return strings.TrimSpace(s.Source) != ""
+}
+
+// SourcePath returns the source with "~" expanded, for spellbooks kept in a local repository
+func (s SpellbookConfig) SourcePath() string {
+	return expandHome(strings.TrimSpace(s.Source))
+}
+
+// MockConfig points the mock provider at its canned responses. The provider is only used when
+// "provider" is set to "mock", and it needs no API key.
+type MockConfig struct {
+	Responses string `yaml:"responses,omitempty"` // a YAML file of canned responses
+}
+
+// AzureConfig locates the deployment used when "provider" is set to "azure-openai"
+type AzureConfig struct {
+	Endpoint   string `yaml:"endpoint,omitempty"`   // the resource endpoint, e.g. https://my-resource.openai.azure.com
+	Deployment string `yaml:"deployment,omitempty"` // the deployment name; the model name when unset
+}
+
+// DeploymentName returns the configured deployment, or the model for deployments named after it
+func (a AzureConfig) DeploymentName(model string) string {
+	if deployment := strings.TrimSpace(a.Deployment); deployment != "" {
+		return deployment
+	}
+	return model
+}
+
+// validate checks the endpoint and deployment of the Azure OpenAI provider
+func (a AzureConfig) validate(model string) error {
+	endpoint, err := url.Parse(strings.TrimSpace(a.Endpoint))
+	if a.Endpoint == "" || err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return fmt.Errorf("invalid Azure OpenAI endpoint '%s'. Set 'azure.endpoint' to your resource's endpoint, e.g. https://my-resource.openai.azure.com", a.Endpoint)
+	}
+	if a.DeploymentName(model) == "" {
+		return fmt.Errorf("the Azure OpenAI provider needs a deployment. Set 'azure.deployment' or run 'execute-my-will configure --azure-deployment <name>'")
+	}
+	return nil
+}
+
+// DefaultOpenRouterBaseURL is the root of OpenRouter's OpenAI-compatible API
+const DefaultOpenRouterBaseURL = "https://openrouter.ai/api/v1"
+
+// OpenRouterConfig tunes the "openrouter" provider, which reaches many vendors' models with one
+// API key. Models are named "vendor/model", e.g. anthropic/claude-3.5-sonnet.
+type OpenRouterConfig struct {
+	BaseURL string `yaml:"base_url,omitempty"` // the API root; DefaultOpenRouterBaseURL when unset
+	Referer string `yaml:"referer,omitempty"`  // sent as HTTP-Referer, the site OpenRouter attributes requests to
+	Title   string `yaml:"title,omitempty"`    // sent as X-Title, the app name shown in OpenRouter's rankings
+}
+
+// URL returns the API root without a trailing slash
+func (o OpenRouterConfig) URL() string {
+	if base := strings.TrimSpace(o.BaseURL); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return DefaultOpenRouterBaseURL
+}
+
+// validate checks the base URL of the OpenRouter provider
+func (o OpenRouterConfig) validate() error {
+	base, err := url.Parse(o.URL())
+	if err != nil || (base.Scheme != "https" && base.Scheme != "http") || base.Host == "" {
+		return fmt.Errorf("invalid OpenRouter base URL '%s'. Set 'openrouter.base_url' to an http(s) URL or remove it to use %s", o.BaseURL, DefaultOpenRouterBaseURL)
+	}
+	return nil
+}
+
+// ResponsesPath returns the responses file with "~" expanded, or "" when none is set
+func (m MockConfig) ResponsesPath() string {
+	path := strings.TrimSpace(m.Responses)
+	if path == "" {
+		return ""
+	}
+	return expandHome(path)
+}
+
+// expandHome replaces a leading "~" with the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Clean(path)
+}
+
+// envVarName matches names every shell accepts as an environment variable
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// MatchContext finds the configured context named by the intent's prefix.
+// It returns the context name and the intent without its prefix when one matches.
+func (c *Config) MatchContext(intent string) (string, *IntentContext, string) {
+	prefix, rest, found := strings.Cut(intent, ":")
+	if !found {
+		return "", nil, intent
+	}
+
+	name := strings.ToLower(strings.TrimSpace(prefix))
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return "", nil, intent
+	}
+	return name, &ctx, strings.TrimSpace(rest)
+}
+
+// GlossaryTerm is the user's own name for something in their realm, e.g. "my site" = /var/www/blog
+type GlossaryTerm struct {
+	Term    string
+	Meaning string
+}
+
+// Define adds or replaces a glossary term. Terms are matched case-insensitively and stored in lowercase.
+func (c *Config) Define(term, meaning string) error {
+	term = strings.ToLower(strings.TrimSpace(term))
+	meaning = strings.TrimSpace(meaning)
+	if term == "" || meaning == "" {
+		return fmt.Errorf("a glossary entry needs both a term and a meaning, e.g. \"the blue server=host 10.0.0.12\"")
+	}
+	if c.Glossary == nil {
+		c.Glossary = map[string]string{}
+	}
+	c.Glossary[term] = meaning
+	return nil
+}
+
+// Undefine removes a glossary term, reporting whether it existed
+func (c *Config) Undefine(term string) bool {
+	term = strings.ToLower(strings.TrimSpace(term))
+	_, ok := c.Glossary[term]
+	delete(c.Glossary, term)
+	return ok
+}
+
+// GlossaryFor returns the glossary terms mentioned in the intent as whole words, sorted by term
+func (c *Config) GlossaryFor(intent string) []GlossaryTerm {
+	var terms []GlossaryTerm
+	for term, meaning := range c.Glossary {
+		pattern := regexp.MustCompile(`(?i)(^|[^\pL\pN_])` + regexp.QuoteMeta(term) + `($|[^\pL\pN_])`)
+		if pattern.MatchString(intent) {
+			terms = append(terms, GlossaryTerm{Term: term, Meaning: meaning})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Term < terms[j].Term })
+	return terms
+}
+
+// Remember adds a fact about the user's realm or preferences, e.g. "I use podman instead of
+// docker", to be sent with every quest. It reports false when the fact is already remembered.
+func (c *Config) Remember(fact string) (bool, error) {
+	fact = strings.Join(strings.Fields(fact), " ")
+	if fact == "" {
+		return false, fmt.Errorf("there is nothing to remember; give the fact to keep, e.g. \"I use podman instead of docker\"")
+	}
+	for _, known := range c.Facts {
+		if strings.EqualFold(known, fact) {
+			return false, nil
+		}
+	}
+	c.Facts = append(c.Facts, fact)
+	return true, nil
+}
+
+// Forget removes a remembered fact, given by its number in the list (starting at 1) or by its
+// text, ignoring case. It returns the fact removed.
+func (c *Config) Forget(selector string) (string, error) {
+	selector = strings.Join(strings.Fields(selector), " ")
+	var index int
+	if number, err := strconv.Atoi(selector); err == nil {
+		if number < 1 || number > len(c.Facts) {
+			return "", fmt.Errorf("there is no fact %d; %d fact(s) are remembered", number, len(c.Facts))
+		}
+		index = number - 1
+	} else {
+		index = slices.IndexFunc(c.Facts, func(fact string) bool { return strings.EqualFold(fact, selector) })
+		if index < 0 {
+			return "", fmt.Errorf("'%s' is not a remembered fact", selector)
+		}
+	}
+	fact := c.Facts[index]
+	c.Facts = slices.Delete(c.Facts, index, index+1)
+	return fact, nil
+}
+
+// UIConfig holds presentation preferences
+type UIConfig struct {
+	Verbosity string `yaml:"verbosity"`         // minimal, normal, or festive
+	Persona   string `yaml:"persona,omitempty"` // knight, pirate, starship, plain, or the path to a persona file
+	Locale    string `yaml:"locale,omitempty"`  // e.g. "de_DE" for dates, sizes, and numbers; empty follows LC_ALL and LANG
+	Stream    *bool  `yaml:"stream,omitempty"`  // show the oracle's answer while it is generated; defaults to true
+	Confirm   string `yaml:"confirm,omitempty"` // "type" to type y or a word for yes (the default), or "select" to choose with the arrow keys
+}
+
+// SelectConfirmation reports whether quests are confirmed by choosing with the arrow keys rather
+// than by typing an answer
+func (u UIConfig) SelectConfirmation() bool { return u.Confirm == "select" }
+
+// StreamAnswers reports whether the oracle's answer is shown while it is generated
+func (u UIConfig) StreamAnswers() bool { return isAllowed(u.Stream) }
+
+// PrivacyConfig controls which parts of the system context are shared with the AI provider.
+// Every field defaults to true when omitted so existing installs keep their behaviour, except
+// SendEnvrcVariables, which is only shared once the user consents.
+type PrivacyConfig struct {
+	SendInstalledPackages *bool `yaml:"send_installed_packages,omitempty"`
+	SendAvailableCommands *bool `yaml:"send_available_commands,omitempty"`
+	SendCurrentDir        *bool `yaml:"send_current_dir,omitempty"`
+	SendHomeDir           *bool `yaml:"send_home_dir,omitempty"`
+	SendSSHHosts          *bool `yaml:"send_ssh_hosts,omitempty"`
+	SendProcesses         *bool `yaml:"send_processes,omitempty"`
+	SendEnvrcVariables    *bool `yaml:"send_envrc_variables,omitempty"`
+}
+
+// PrivacyFields lists the names accepted by SetField, in display order
+var PrivacyFields = []string{"installed-packages", "available-commands", "current-dir", "home-dir", "ssh-hosts", "processes", "envrc-variables"}
+
+// AllowInstalledPackages reports whether installed packages may be sent to the AI
+func (p PrivacyConfig) AllowInstalledPackages() bool { return isAllowed(p.SendInstalledPackages) }
+
+// AllowAvailableCommands reports whether available commands may be sent to the AI
+func (p PrivacyConfig) AllowAvailableCommands() bool { return isAllowed(p.SendAvailableCommands) }
+
+// AllowCurrentDir reports whether the current directory path may be sent to the AI
+func (p PrivacyConfig) AllowCurrentDir() bool { return isAllowed(p.SendCurrentDir) }
+
+// AllowHomeDir reports whether the home directory path may be sent to the AI
+func (p PrivacyConfig) AllowHomeDir() bool { return isAllowed(p.SendHomeDir) }
+
+// AllowSSHHosts reports whether host aliases from ~/.ssh/config may be sent to the AI
+func (p PrivacyConfig) AllowSSHHosts() bool { return isAllowed(p.SendSSHHosts) }
+
+// AllowProcesses reports whether the running processes relevant to an intent may be sent to the AI
+func (p PrivacyConfig) AllowProcesses() bool { return isAllowed(p.SendProcesses) }
+
+// AllowEnvrcVariables reports whether the names of the variables an .envrc exports may be sent
+// to the AI. Unlike the other fields it is off until the user allows it.
+func (p PrivacyConfig) AllowEnvrcVariables() bool {
+	return p.SendEnvrcVariables != nil && *p.SendEnvrcVariables
+}
+
+// SetField enables or disables sharing of a named context field
+func (p *PrivacyConfig) SetField(field string, allowed bool) error {
+	value := allowed
+	switch field {
+	case "installed-packages":
+		p.SendInstalledPackages = &value
+	case "available-commands":
+		p.SendAvailableCommands = &value
+	case "current-dir":
+		p.SendCurrentDir = &value
+	case "home-dir":
+		p.SendHomeDir = &value
+	case "ssh-hosts":
+		p.SendSSHHosts = &value
+	case "processes":
+		p.SendProcesses = &value
+	case "envrc-variables":
+		p.SendEnvrcVariables = &value
+	default:
+		return fmt.Errorf("unknown privacy field '%s'. Choose from: %s", field, strings.Join(PrivacyFields, ", "))
+	}
+	return nil
+}
+
+// Withheld returns the names of the context fields shared by default that are not shared.
+// The opt-in envrc-variables field is left out, so a fresh install withholds nothing.
+func (p PrivacyConfig) Withheld() []string {
+	allowed := []bool{p.AllowInstalledPackages(), p.AllowAvailableCommands(), p.AllowCurrentDir(), p.AllowHomeDir(), p.AllowSSHHosts(), p.AllowProcesses()}
+	var withheld []string
+	for i, ok := range allowed {
+		if !ok {
+			withheld = append(withheld, PrivacyFields[i])
+		}
+	}
+	return withheld
+}
+
+func isAllowed(flag *bool) bool {
+	return flag == nil || *flag
 }
 
 type ConfigFile struct {
-	AI Config `yaml:"ai"`
+	Version     int                      `yaml:"config_version"`
+	AI          Config                   `yaml:"ai"`
+	UI          UIConfig                 `yaml:"ui,omitempty"`
+	Privacy     PrivacyConfig            `yaml:"privacy,omitempty"`
+	Contexts    map[string]IntentContext `yaml:"contexts,omitempty"`
+	RateLimits  map[string]RateLimit     `yaml:"rate_limits,omitempty"`
+	Glossary    map[string]string        `yaml:"glossary,omitempty"`
+	Facts       []string                 `yaml:"facts,omitempty"`
+	APIVersions map[string]string        `yaml:"api_versions,omitempty"`
+	Budget      Budget                   `yaml:"budget,omitempty"`
+	Execution   ExecutionConfig          `yaml:"execution,omitempty"`
+	Analysis    AnalysisConfig           `yaml:"analysis,omitempty"`
+	Postmortem  PostmortemConfig         `yaml:"postmortem,omitempty"`
+	Spellbook   SpellbookConfig          `yaml:"spellbook,omitempty"`
+	Mock        MockConfig               `yaml:"mock,omitempty"`
+	Azure       AzureConfig              `yaml:"azure,omitempty"`
+	OpenRouter  OpenRouterConfig         `yaml:"openrouter,omitempty"`
 }
 
 // New creates a new config with default values
@@ -35,6 +475,9 @@ func New() *Config {
 		MaxTokens:   1000,
 		Temperature: 0.1,
 		Mode:        "", // Empty by default, requires configuration
+		UI: UIConfig{
+			Verbosity: "normal",
+		},
 	}
 }
 
@@ -51,18 +494,50 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Upgrade older layouts before parsing them
+	migrated, fromVersion, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
 	var configFile ConfigFile
-	if err := yaml.Unmarshal(data, &configFile); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := yaml.Unmarshal(migrated, &configFile); err != nil {
+		return nil, parseFailure(fmt.Errorf("failed to parse config file: %w", err))
 	}
 
 	cfg := configFile.AI
+	cfg.UI = configFile.UI
+	cfg.Privacy = configFile.Privacy
+	cfg.Contexts = configFile.Contexts
+	cfg.RateLimits = configFile.RateLimits
+	cfg.Glossary = configFile.Glossary
+	cfg.Facts = configFile.Facts
+	cfg.APIVersions = configFile.APIVersions
+	cfg.Budget = configFile.Budget
+	cfg.Execution = configFile.Execution
+	cfg.Analysis = configFile.Analysis
+	cfg.Postmortem = configFile.Postmortem
+	cfg.Spellbook = configFile.Spellbook
+	cfg.Mock = configFile.Mock
+	cfg.Azure = configFile.Azure
+	cfg.OpenRouter = configFile.OpenRouter
 
 	// Set default model if not provided
 	if cfg.Model == "" {
 		cfg.Model = GetDefaultModel(cfg.AIProvider)
 	}
 
+	if fromVersion != CurrentConfigVersion {
+		backupPath, err := backupConfig(configPath, data, fromVersion)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(configPath, migrated, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write migrated config: %w", err)
+		}
+		cfg.Migration = &MigrationResult{FromVersion: fromVersion, ToVersion: CurrentConfigVersion, BackupPath: backupPath}
+	}
+
 	return &cfg, nil
 }
 
@@ -75,7 +550,7 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configFile := ConfigFile{AI: *cfg}
+	configFile := ConfigFile{Version: CurrentConfigVersion, AI: *cfg, UI: cfg.UI, Privacy: cfg.Privacy, Contexts: cfg.Contexts, RateLimits: cfg.RateLimits, Glossary: cfg.Glossary, Facts: cfg.Facts, APIVersions: cfg.APIVersions, Budget: cfg.Budget, Execution: cfg.Execution, Analysis: cfg.Analysis, Postmortem: cfg.Postmortem, Spellbook: cfg.Spellbook, Mock: cfg.Mock, Azure: cfg.Azure, OpenRouter: cfg.OpenRouter}
 
 	data, err := yaml.Marshal(&configFile)
 	if err != nil {
@@ -89,9 +564,16 @@ func Save(cfg *Config) error {
 	return nil
 }
 
-// Validate checks if the configuration is valid
+// Validate checks if the configuration is valid. Its errors are of the kind ErrInvalidConfig.
 func (c *Config) Validate() error {
-	if c.APIKey == "" {
+	if err := c.validate(); err != nil {
+		return &kindError{kind: ErrInvalidConfig, err: err}
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.APIKey == "" && c.AIProvider != "mock" && !c.CustomEndpoint() {
 		return fmt.Errorf("API key is required. Run 'execute-my-will configure' to set it up")
 	}
 
@@ -107,6 +589,90 @@ func (c *Config) Validate() error {
 		c.AIProvider = "gemini"
 	}
 
+	if c.AIProvider == "azure-openai" {
+		if err := c.Azure.validate(c.Model); err != nil {
+			return err
+		}
+	}
+
+	if c.CustomEndpoint() {
+		base, err := url.Parse(strings.TrimSpace(c.BaseURL))
+		if err != nil || (base.Scheme != "https" && base.Scheme != "http") || base.Host == "" {
+			return fmt.Errorf("invalid base URL '%s'. Set 'base_url' to the root of an OpenAI-compatible server, e.g. http://localhost:1234/v1", c.BaseURL)
+		}
+	}
+
+	if c.AIProvider == "openrouter" {
+		if err := c.OpenRouter.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.AIProvider == "mock" && c.Mock.ResponsesPath() == "" {
+		return fmt.Errorf("the mock provider needs a responses file. Set 'mock.responses' or run 'execute-my-will configure --mock-responses <file>'")
+	}
+
+	// Verbosity is read case-insensitively, as ui.ParseVerbosity does
+	c.UI.Verbosity = strings.ToLower(strings.TrimSpace(c.UI.Verbosity))
+	switch c.UI.Verbosity {
+	case "":
+		c.UI.Verbosity = "normal"
+	case "minimal", "normal", "festive":
+	default:
+		return fmt.Errorf("invalid verbosity '%s'. Choose minimal, normal, or festive", c.UI.Verbosity)
+	}
+
+	switch c.UI.Confirm {
+	case "", "type", "select":
+	default:
+		return fmt.Errorf("invalid confirmation style '%s'. Choose type or select", c.UI.Confirm)
+	}
+
+	for name := range c.Contexts {
+		if name == "" || name != strings.ToLower(name) || strings.ContainsAny(name, ": \t") {
+			return fmt.Errorf("invalid context name '%s'. Context names must be lowercase words without spaces or colons", name)
+		}
+	}
+
+	for provider, limit := range c.RateLimits {
+		if limit.RequestsPerMinute < 0 || limit.TokensPerMinute < 0 {
+			return fmt.Errorf("invalid rate limit for '%s'. Limits must be zero (unlimited) or positive", provider)
+		}
+	}
+
+	for term, meaning := range c.Glossary {
+		if strings.TrimSpace(term) == "" || strings.TrimSpace(meaning) == "" {
+			return fmt.Errorf("invalid glossary entry '%s'. Every term needs a meaning", term)
+		}
+	}
+
+	for i, fact := range c.Facts {
+		if strings.TrimSpace(fact) == "" {
+			return fmt.Errorf("invalid fact %d. Remembered facts cannot be empty", i+1)
+		}
+	}
+
+	if err := validateAPIVersions(c.APIVersions); err != nil {
+		return err
+	}
+
+	if c.Budget.MonthlyTokens < 0 {
+		return fmt.Errorf("invalid monthly token budget %d. The budget must be zero (none) or positive", c.Budget.MonthlyTokens)
+	}
+	if c.Budget.SwitchAt < 0 || c.Budget.SwitchAt > 1 {
+		return fmt.Errorf("invalid budget switch_at %g. Expected a share of the budget between 0 and 1, e.g. 0.9", c.Budget.SwitchAt)
+	}
+
+	if c.Execution.MaxLineSize < 0 {
+		return fmt.Errorf("invalid max_line_size %d. Expected a number of bytes, or zero for the default of 1 MiB", c.Execution.MaxLineSize)
+	}
+
+	for name := range c.Execution.Env {
+		if !envVarName.MatchString(name) {
+			return fmt.Errorf("invalid execution environment variable '%s'. Names may only contain letters, digits, and underscores", name)
+		}
+	}
+
 	if c.MaxTokens <= 0 {
 		c.MaxTokens = 1000
 	}
@@ -123,6 +689,36 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// CustomEndpoint reports whether the openai provider is pointed at another OpenAI-compatible
+// server, such as LM Studio, vLLM, a llama.cpp server, or Groq. Self-hosted servers often need no
+// API key.
+func (c *Config) CustomEndpoint() bool {
+	return c.AIProvider == "openai" && strings.TrimSpace(c.BaseURL) != ""
+}
+
+// OfflineProvider reports whether the configured provider answers without the network: the
+// mock provider, which reads canned responses from a local file, and an OpenAI-compatible server
+// on this machine, such as Ollama or llama.cpp, reached at localhost or a loopback address
+func (c *Config) OfflineProvider() bool {
+	if c.AIProvider == "mock" {
+		return true
+	}
+	if !c.CustomEndpoint() {
+		return false
+	}
+	base, err := url.Parse(strings.TrimSpace(c.BaseURL))
+	if err != nil {
+		return false
+	}
+	// Names under .localhost always resolve to this machine (RFC 6761)
+	host := strings.ToLower(base.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // GetDefaultModel returns the default model for a provider
 func GetDefaultModel(provider string) string {
 	switch provider {
@@ -132,6 +728,12 @@ func GetDefaultModel(provider string) string {
 		return "gpt-3.5-turbo"
 	case "anthropic":
 		return "claude-3-sonnet-20240229"
+	case "azure-openai":
+		return "gpt-4o"
+	case "openrouter":
+		return "openai/gpt-4o-mini"
+	case "mock":
+		return "mock"
 	default:
 		return "gemini-pro"
 	}
@@ -146,6 +748,11 @@ func getConfigPath() string {
 	return filepath.Join(home, ".config/execute-my-will/config.yaml")
 }
 
+// StatePath returns the path of a local state file kept next to the configuration file
+func StatePath(name string) string {
+	return filepath.Join(filepath.Dir(getConfigPath()), name)
+}
+
 // ConfigNotFoundError represents a missing config file error
 type ConfigNotFoundError struct {
 	Path string
@@ -169,6 +776,12 @@ func GetModels(provider string) ([]string, error) {
 		return []string{"gpt-3.5-turbo", "gpt-4"}, nil
 	case "anthropic":
 		return []string{"claude-3-sonnet-20240229"}, nil
+	case "azure-openai":
+		return []string{"gpt-4o", "gpt-4o-mini"}, nil
+	case "openrouter":
+		return []string{"openai/gpt-4o-mini", "anthropic/claude-3.5-sonnet", "google/gemini-2.0-flash-001", "meta-llama/llama-3.3-70b-instruct"}, nil
+	case "mock":
+		return []string{"mock"}, nil
 	default:
 		return nil, fmt.Errorf("unsupported AI provider: %s", provider)
 	}
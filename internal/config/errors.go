@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/config/errors.go
+package config
+
+import "errors"
+
+// The kinds of errors loading and checking the configuration returns, told apart with errors.Is.
+// A missing configuration file is a ConfigNotFoundError instead.
+var (
+	// ErrParseFailure is a configuration or workspace file that is not valid YAML, or whose
+	// config_version cannot be read
+	ErrParseFailure = errors.New("the configuration could not be parsed")
+	// ErrInvalidConfig is a configuration that parsed but that Validate rejects
+	ErrInvalidConfig = errors.New("the configuration is invalid")
+)
+
+// kindError gives an error one of the kinds above while keeping its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// parseFailure marks err as a file that could not be parsed
+func parseFailure(err error) error {
+	return &kindError{kind: ErrParseFailure, err: err}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migrations[i] upgrades a raw configuration layout from version i to version i+1.
+// Append a function here (never edit an existing one) whenever the schema changes.
+var migrations = []func(raw map[string]interface{}) error{
+	migrateFlatLayout,
+}
+
+// CurrentConfigVersion is the schema version written by Save
+var CurrentConfigVersion = len(migrations)
+
+// MigrationResult describes an automatic upgrade performed while loading the configuration
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	BackupPath  string
+}
+
+// Migrate upgrades raw configuration YAML to the current schema.
+// It returns the upgraded YAML and the version the data was written with.
+func Migrate(data []byte) ([]byte, int, error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, 0, parseFailure(fmt.Errorf("failed to parse config file: %w", err))
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	version := 0
+	if value, ok := raw["config_version"]; ok {
+		v, ok := value.(int)
+		if !ok || v < 0 {
+			return nil, 0, parseFailure(fmt.Errorf("invalid config_version '%v'", value))
+		}
+		version = v
+	}
+
+	if version > CurrentConfigVersion {
+		return nil, version, fmt.Errorf("config_version %d was written by a newer execute-my-will (this one understands up to %d). Please upgrade", version, CurrentConfigVersion)
+	}
+	if version == CurrentConfigVersion {
+		return data, version, nil
+	}
+
+	for v := version; v < CurrentConfigVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate config from version %d to %d: %w", v, v+1, err)
+		}
+	}
+	raw["config_version"] = CurrentConfigVersion
+
+	migrated, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return migrated, version, nil
+}
+
+// backupConfig keeps a copy of the original configuration before it is rewritten by a migration
+func backupConfig(configPath string, data []byte, version int) (string, error) {
+	backupPath := fmt.Sprintf("%s.v%d.bak", configPath, version)
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up config before migration: %w", err)
+	}
+	return backupPath, nil
+}
+
+// migrateFlatLayout (0 → 1) moves AI settings written at the top level into the "ai" section
+func migrateFlatLayout(raw map[string]interface{}) error {
+	ai, _ := raw["ai"].(map[string]interface{})
+	if ai == nil {
+		if _, exists := raw["ai"]; exists {
+			return fmt.Errorf("the 'ai' section must be a mapping")
+		}
+		ai = map[string]interface{}{}
+	}
+
+	for _, key := range []string{"provider", "api_key", "model", "max_tokens", "temperature", "mode"} {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		if _, exists := ai[key]; !exists {
+			ai[key] = value
+		}
+		delete(raw, key)
+	}
+
+	raw["ai"] = ai
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/config/workspace.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile is the name of the workspace file looked up in the current directory and its parents
+const WorkspaceFile = ".execute-my-will-workspace.yaml"
+
+// workspacePrefix starts intents that apply to every project of the workspace
+const workspacePrefix = "all"
+
+// Workspace lists project directories that "all:" intents run in
+type Workspace struct {
+	Projects []string `yaml:"projects"`
+	Parallel bool     `yaml:"parallel"` // run projects at the same time instead of one after another
+
+	Path string `yaml:"-"` // the file the workspace was loaded from
+}
+
+// MatchWorkspaceIntent reports whether the intent starts with "all:" and returns it without the prefix
+func MatchWorkspaceIntent(intent string) (string, bool) {
+	prefix, rest, found := strings.Cut(intent, ":")
+	if !found || strings.ToLower(strings.TrimSpace(prefix)) != workspacePrefix {
+		return intent, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// FindWorkspace looks for a workspace file in startDir and its parents, then next to the
+// configuration file. It returns nil without an error when there is none.
+func FindWorkspace(startDir string) (*Workspace, error) {
+	for dir := startDir; dir != ""; {
+		path := filepath.Join(dir, WorkspaceFile)
+		if _, err := os.Stat(path); err == nil {
+			return LoadWorkspace(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	path := StatePath("workspace.yaml")
+	if _, err := os.Stat(path); err == nil {
+		return LoadWorkspace(path)
+	}
+	return nil, nil
+}
+
+// LoadWorkspace reads a workspace file. Relative project paths are resolved against the
+// file's directory and ~ is expanded to the home directory.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, parseFailure(fmt.Errorf("failed to parse workspace file %s: %w", path, err))
+	}
+	if len(ws.Projects) == 0 {
+		return nil, fmt.Errorf("the workspace file %s lists no projects", path)
+	}
+
+	home, _ := os.UserHomeDir()
+	for i, project := range ws.Projects {
+		switch {
+		case project == "~" || strings.HasPrefix(project, "~/"):
+			project = filepath.Join(home, project[1:])
+		case !filepath.IsAbs(project):
+			project = filepath.Join(filepath.Dir(path), project)
+		}
+		ws.Projects[i] = filepath.Clean(project)
+	}
+	ws.Path = path
+	return &ws, nil
+}
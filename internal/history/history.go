@@ -0,0 +1,254 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/history/history.go
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HistoryFile is the state file holding previous quests
+const HistoryFile = "history.yaml"
+
+// maxEntries bounds the history file; the least recently used entries are dropped first
+const maxEntries = 500
+
+// similarityThreshold is the minimum word overlap for an earlier intent to count as similar
+const similarityThreshold = 0.8
+
+// Entry is a command or script that was run for an intent. Runs of the same intent and
+// content are folded into one entry.
+type Entry struct {
+	Intent    string    `yaml:"intent"`
+	Context   string    `yaml:"context,omitempty"` // the context prefix the intent was given with
+	Content   string    `yaml:"content"`
+	IsScript  bool      `yaml:"is_script,omitempty"`
+	Runs      int       `yaml:"runs"`
+	Failures  int       `yaml:"failures,omitempty"`
+	FirstRun  time.Time `yaml:"first_run"`
+	LastRun   time.Time `yaml:"last_run"`
+	LastError string    `yaml:"last_error,omitempty"`
+	LogFile   string    `yaml:"log_file,omitempty"`  // the saved output of the last run, when it was kept
+	Artifacts []string  `yaml:"artifacts,omitempty"` // files and directories the last run created, for undoing it
+}
+
+// Succeeded reports whether the most recent run of the entry succeeded
+func (e *Entry) Succeeded() bool {
+	return e.LastError == ""
+}
+
+// Store is the history of previous quests kept in a local file
+type Store struct {
+	Entries []*Entry `yaml:"entries"`
+
+	path string
+}
+
+// Load reads the history file, returning an empty store when it does not exist yet
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quest history: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse quest history: %w", err)
+	}
+	return store, nil
+}
+
+// Save writes the history file, creating its directory if needed
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quest history: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Record adds a run, folding it into an existing entry with the same intent, context, and
+// content, and returns that entry
+func (s *Store) Record(intent, context, content string, isScript bool, runErr error, at time.Time) *Entry {
+	entry := s.Find(intent, context, content)
+	if entry == nil {
+		entry = &Entry{Intent: intent, Context: context, Content: content, IsScript: isScript, FirstRun: at}
+		s.Entries = append(s.Entries, entry)
+	}
+
+	entry.Runs++
+	entry.LastRun = at
+	entry.LastError = ""
+	entry.LogFile = ""
+	entry.Artifacts = nil
+	if runErr != nil {
+		entry.Failures++
+		entry.LastError = runErr.Error()
+	}
+
+	if len(s.Entries) > maxEntries {
+		sort.SliceStable(s.Entries, func(i, j int) bool { return s.Entries[i].LastRun.After(s.Entries[j].LastRun) })
+		s.Entries = s.Entries[:maxEntries]
+	}
+	return entry
+}
+
+// Find returns the entry for exactly this intent, context, and content, or nil
+func (s *Store) Find(intent, context, content string) *Entry {
+	for _, e := range s.Entries {
+		if normalize(e.Intent) == normalize(intent) && e.Context == context && e.Content == content {
+			return e
+		}
+	}
+	return nil
+}
+
+// Latest returns the most recently run entry for exactly this intent and context, whatever its
+// content, or nil
+func (s *Store) Latest(intent, context string) *Entry {
+	var latest *Entry
+	for _, e := range s.Entries {
+		if normalize(e.Intent) != normalize(intent) || e.Context != context {
+			continue
+		}
+		if latest == nil || e.LastRun.After(latest.LastRun) {
+			latest = e
+		}
+	}
+	return latest
+}
+
+// RecentRun returns the entry, for any intent, whose content is this command or script and that
+// was run most recently at or after since, or nil
+func (s *Store) RecentRun(content string, since time.Time) *Entry {
+	fingerprint := Fingerprint(content)
+	var recent *Entry
+	for _, e := range s.Entries {
+		if e.LastRun.Before(since) || Fingerprint(e.Content) != fingerprint {
+			continue
+		}
+		if recent == nil || e.LastRun.After(recent.LastRun) {
+			recent = e
+		}
+	}
+	return recent
+}
+
+// Fingerprint identifies a command or script by its content. Line endings and trailing
+// whitespace do not change the fingerprint.
+func Fingerprint(content string) string {
+	sum := sha256.Sum256([]byte(NormalizeContent(content)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NormalizeContent drops carriage returns, trailing whitespace, and surrounding blank lines
+func NormalizeContent(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// FindSimilar returns the successful entry in the same context whose intent is most similar to
+// the given one, or nil. Ties are broken by how often the entry was run, then by how recently.
+func (s *Store) FindSimilar(intent, context string) *Entry {
+	words := wordSet(intent)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var best *Entry
+	bestScore := 0.0
+	for _, e := range s.Entries {
+		if !e.Succeeded() || e.Context != context {
+			continue
+		}
+		score := similarity(words, wordSet(e.Intent))
+		if score < similarityThreshold {
+			continue
+		}
+		if best == nil || score > bestScore ||
+			(score == bestScore && (e.Runs > best.Runs || (e.Runs == best.Runs && e.LastRun.After(best.LastRun)))) {
+			best, bestScore = e, score
+		}
+	}
+	return best
+}
+
+// Recurring returns the scripts that were run at least minRuns times and succeeded the last
+// time, most often run first. Ties are broken by how recently the script was run.
+func (s *Store) Recurring(minRuns int) []*Entry {
+	var recurring []*Entry
+	for _, e := range s.Entries {
+		if e.IsScript && e.Succeeded() && e.Runs >= minRuns {
+			recurring = append(recurring, e)
+		}
+	}
+	sort.SliceStable(recurring, func(i, j int) bool {
+		if recurring[i].Runs != recurring[j].Runs {
+			return recurring[i].Runs > recurring[j].Runs
+		}
+		return recurring[i].LastRun.After(recurring[j].LastRun)
+	})
+	return recurring
+}
+
+// stopWords carry no meaning for matching intents
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "in": true, "on": true, "of": true, "for": true,
+	"my": true, "me": true, "all": true, "and": true, "please": true, "with": true, "from": true, "into": true,
+}
+
+func normalize(intent string) string {
+	return strings.Join(strings.Fields(strings.ToLower(intent)), " ")
+}
+
+// wordSet splits an intent into its meaningful lowercase words
+func wordSet(intent string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(intent), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '/' && r != '-' && r != '_'
+	}) {
+		word = strings.Trim(word, ".-")
+		if word != "" && !stopWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// similarity is the share of words two intents have in common (Jaccard index)
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/history/journal.go
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JournalFile is the state file recording every quest, for the 'history' subcommand
+const JournalFile = "journal.yaml"
+
+// maxJournalRecords bounds the journal; the oldest quests are dropped first
+const maxJournalRecords = 2000
+
+// The decisions a quest's proposal can end with
+const (
+	DecisionApproved = "approved"
+	DecisionDeclined = "declined"
+	DecisionNotAsked = "not asked" // the quest stopped before its proposal was offered
+)
+
+// Record is one quest as it happened. Unlike the entries of the history store, runs of the same
+// intent are never folded together, and quests that were declined or never ran are kept too.
+type Record struct {
+	ID       int       `yaml:"id"` // numbers the quests in the order they were asked, from 1
+	At       time.Time `yaml:"at"`
+	Intent   string    `yaml:"intent"`
+	Context  string    `yaml:"context,omitempty"`
+	Dir      string    `yaml:"dir,omitempty"`     // where the quest was started
+	Content  string    `yaml:"content,omitempty"` // the proposed command or script, with credentials redacted
+	IsScript bool      `yaml:"is_script,omitempty"`
+	Redacted bool      `yaml:"redacted,omitempty"` // the content held a credential, so it cannot be run again
+	Refusal  string    `yaml:"refusal,omitempty"`  // why the oracle proposed nothing
+	Decision string    `yaml:"decision"`
+	Executed bool      `yaml:"executed,omitempty"`
+	ExitCode *int      `yaml:"exit_code,omitempty"` // nil when the quest did not run or reported no code
+	Error    string    `yaml:"error,omitempty"`
+	Undoes   int       `yaml:"undoes,omitempty"` // the quest this one reversed, for quests run by 'undo'
+}
+
+// Failed reports whether the quest ran and failed
+func (r *Record) Failed() bool {
+	return r.Executed && r.Error != ""
+}
+
+// Journal is the record of every quest, newest last
+type Journal struct {
+	Records []*Record `yaml:"records"`
+
+	path string
+}
+
+// NewJournal returns an empty journal kept in the file at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// LoadJournal reads the journal file, returning an empty journal when it does not exist yet
+func LoadJournal(path string) (*Journal, error) {
+	journal := NewJournal(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the quest journal: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, journal); err != nil {
+		return nil, fmt.Errorf("failed to parse the quest journal: %w", err)
+	}
+	return journal, nil
+}
+
+// Save writes the journal file, creating its directory if needed
+func (j *Journal) Save() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the quest journal: %w", err)
+	}
+	return os.WriteFile(j.path, data, 0600)
+}
+
+// Add numbers a quest and appends it to the journal, dropping the oldest once it holds
+// maxJournalRecords
+func (j *Journal) Add(record *Record) {
+	record.ID = 1
+	if len(j.Records) > 0 {
+		record.ID = j.Records[len(j.Records)-1].ID + 1
+	}
+	j.Records = append(j.Records, record)
+	if len(j.Records) > maxJournalRecords {
+		j.Records = j.Records[len(j.Records)-maxJournalRecords:]
+	}
+}
+
+// Search returns the quests whose intent, context, or content contains term, ignoring case,
+// newest first. An empty term matches every quest; failedOnly keeps only quests that failed.
+func (j *Journal) Search(term string, failedOnly bool) []*Record {
+	term = strings.ToLower(term)
+	var found []*Record
+	for i := len(j.Records) - 1; i >= 0; i-- {
+		record := j.Records[i]
+		if failedOnly && !record.Failed() {
+			continue
+		}
+		if term != "" && !strings.Contains(strings.ToLower(record.Intent+"\n"+record.Context+"\n"+record.Content), term) {
+			continue
+		}
+		found = append(found, record)
+	}
+	return found
+}
+
+// Find returns the quest with the given number, or nil when it is not in the journal
+func (j *Journal) Find(id int) *Record {
+	for _, record := range j.Records {
+		if record.ID == id {
+			return record
+		}
+	}
+	return nil
+}
+
+// UndoneBy returns the quest that ran to reverse the quest with the given number, or nil
+func (j *Journal) UndoneBy(id int) *Record {
+	for _, record := range j.Records {
+		if record.Undoes == id && record.Executed && !record.Failed() {
+			return record
+		}
+	}
+	return nil
+}
+
+// LastProposal returns the most recent quest that proposed a command or script, or nil
+func (j *Journal) LastProposal() *Record {
+	for i := len(j.Records) - 1; i >= 0; i-- {
+		if j.Records[i].Content != "" {
+			return j.Records[i]
+		}
+	}
+	return nil
+}
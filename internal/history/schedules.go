@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/history/schedules.go
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchedulesFile is the name of the file listing the spells scheduled to run unattended
+const SchedulesFile = "schedules.yaml"
+
+// ScheduledSpell is a spell installed with a system scheduler
+type ScheduledSpell struct {
+	Name      string    `yaml:"name"`
+	When      string    `yaml:"when"` // as the user described it
+	Cron      string    `yaml:"cron"`
+	Scheduler string    `yaml:"scheduler"` // cron, systemd, or task
+	Source    string    `yaml:"source"`    // the function or quest it was made from, e.g. "quest #12"
+	Intent    string    `yaml:"intent,omitempty"`
+	Script    string    `yaml:"script"` // the script the scheduler runs, as approved
+	Shell     string    `yaml:"shell"`
+	Log       string    `yaml:"log"`
+	At        time.Time `yaml:"at"`
+}
+
+// Schedules lists the scheduled spells, in the order they were scheduled
+type Schedules struct {
+	Spells []*ScheduledSpell `yaml:"spells"`
+
+	path string
+}
+
+// LoadSchedules reads the schedules file, returning an empty list when it does not exist yet
+func LoadSchedules(path string) (*Schedules, error) {
+	schedules := &Schedules{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return schedules, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the scheduled spells: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse the scheduled spells: %w", err)
+	}
+	return schedules, nil
+}
+
+// Save writes the schedules file, creating its directory if needed
+func (s *Schedules) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create schedules directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the scheduled spells: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Find returns the scheduled spell with the given name, or nil
+func (s *Schedules) Find(name string) *ScheduledSpell {
+	for _, spell := range s.Spells {
+		if spell.Name == name {
+			return spell
+		}
+	}
+	return nil
+}
+
+// Put adds a scheduled spell, replacing one of the same name
+func (s *Schedules) Put(spell *ScheduledSpell) {
+	s.Remove(spell.Name)
+	s.Spells = append(s.Spells, spell)
+}
+
+// Remove drops the scheduled spell with the given name, reporting whether there was one
+func (s *Schedules) Remove(name string) bool {
+	for i, spell := range s.Spells {
+		if spell.Name == name {
+			s.Spells = append(s.Spells[:i], s.Spells[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
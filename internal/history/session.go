@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/history/session.go
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SessionFile is the state file holding the quests of the current session
+const SessionFile = "session.yaml"
+
+// maxSessionTurns bounds the quests a session keeps; the oldest are dropped first
+const maxSessionTurns = 8
+
+// SessionIdle is how long a session may lie untouched before it can no longer be continued
+const SessionIdle = 2 * time.Hour
+
+// Turn is one quest of a session: what was asked, what the oracle proposed, and how running it went
+type Turn struct {
+	Intent   string    `yaml:"intent"`
+	Content  string    `yaml:"content,omitempty"` // the proposed command or script, already redacted
+	IsScript bool      `yaml:"is_script,omitempty"`
+	Refusal  string    `yaml:"refusal,omitempty"` // why the oracle proposed nothing
+	Outcome  string    `yaml:"outcome,omitempty"` // e.g. "it ran and succeeded" or "it was not run"
+	Output   string    `yaml:"output,omitempty"`  // a redacted sample of what it printed
+	Lines    int       `yaml:"lines,omitempty"`   // how many lines it printed in all
+	At       time.Time `yaml:"at"`
+}
+
+// Session is the chain of quests a later one may follow on from, as in "now compress that
+// folder". Every quest starts a new session unless it continues the current one.
+type Session struct {
+	Turns []*Turn `yaml:"turns"`
+
+	path string
+}
+
+// NewSession returns an empty session kept in the file at path
+func NewSession(path string) *Session {
+	return &Session{path: path}
+}
+
+// LoadSession reads the session file, returning an empty session when it does not exist yet
+func LoadSession(path string) (*Session, error) {
+	session := NewSession(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the session: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("failed to parse the session: %w", err)
+	}
+	return session, nil
+}
+
+// Save writes the session file, creating its directory if needed
+func (s *Session) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the session: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Add appends a quest to the session, dropping the oldest once it holds maxSessionTurns
+func (s *Session) Add(turn *Turn) {
+	s.Turns = append(s.Turns, turn)
+	if len(s.Turns) > maxSessionTurns {
+		s.Turns = s.Turns[len(s.Turns)-maxSessionTurns:]
+	}
+}
+
+// Reset forgets every quest of the session
+func (s *Session) Reset() {
+	s.Turns = nil
+}
+
+// Last returns the most recent quest of the session, or nil when it has none
+func (s *Session) Last() *Turn {
+	if len(s.Turns) == 0 {
+		return nil
+	}
+	return s.Turns[len(s.Turns)-1]
+}
+
+// Expired reports whether the session has lain untouched for SessionIdle or longer by now
+func (s *Session) Expired(now time.Time) bool {
+	last := s.Last()
+	return last != nil && now.Sub(last.At) >= SessionIdle
+}
@@ -13,10 +13,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Info struct {
@@ -28,6 +30,17 @@ type Info struct {
 	PathDirectories   []string
 	InstalledPackages []string
 	AvailableCommands []string
+	CommandsTruncated bool              // the PATH scan stopped early, so AvailableCommands may miss some
+	SSHHosts          []string          // host aliases from ~/.ssh/config, names only
+	ProjectTypes      []ProjectType     // build tools detected in the current directory
+	Nix               NixEnvironment    // Nix shells and project files that should replace global installs
+	Direnv            DirenvEnvironment // the .envrc direnv applies to the current directory
+	Immutable         ImmutableSystem   // image-based distros and read-only roots where installs fail
+	Quick             bool              // only the OS, shell, and directories were gathered (--no-system-scan)
+	Processes         *ProcessReport    // running processes relevant to the intent; nil unless it is about running programs
+	Resources         ResourceLimits    // CPUs and memory available, with any container (cgroup) limits
+	Privileges        Privileges        // whether the user can become root; without it installs stay in the home directory
+	Timings           []PhaseTiming     // how long each phase of the analysis took, the slowest first
 }
 
 type Analyzer struct{}
@@ -45,6 +58,8 @@ func (a *Analyzer) AnalyzeSystem() (*Info, error) {
 
 	var wg sync.WaitGroup
 	errors := make(chan error, 5)
+	clock := &phaseClock{}
+	start := time.Now()
 
 	info.OS = runtime.GOOS
 	currentDir, _ := os.Getwd()
@@ -52,39 +67,48 @@ func (a *Analyzer) AnalyzeSystem() (*Info, error) {
 	info.CurrentDir = currentDir
 	info.HomeDir = homeDir
 
-	initial_tasks := []func(*Info) error{
-		func(*Info) error { return a.detectShell(info) },
-		func(*Info) error { return a.detectPackageManagers(info) },
-		func(*Info) error { return a.getPathDirectories(info) },
+	initial_tasks := []analysisTask{
+		{"shell", func() error { return a.detectShell(info) }},
+		{"package managers", func() error { return a.detectPackageManagers(info) }},
+		{"PATH", func() error { return a.getPathDirectories(info) }},
+		{"SSH hosts", func() error { return a.detectSSHHosts(info) }},
+		{"project types", func() error { return a.detectProjectTypes(info) }},
+		{"direnv", func() error { return a.detectDirenv(info) }},
+		{"Nix", func() error { return a.detectNix(info) }},
+		{"immutable system", func() error { return a.detectImmutable(info) }},
+		{"resources", func() error { return a.detectResources(info) }},
+		{"privileges", func() error { return a.detectPrivileges(info) }},
 	}
 
 	wg.Add(len(initial_tasks))
 	for _, task := range initial_tasks {
-		go func(t func(*Info) error) {
+		go func(t analysisTask) {
 			defer wg.Done()
-			if err := t(info); err != nil {
+			if err := clock.time(t.phase, t.run); err != nil {
 				errors <- err
 			}
 		}(task)
 	}
 	wg.Wait()
 
-	secondary_tasks := []func(*Info) error{
-		func(*Info) error { return a.getInstalledPackages(info) },
-		func(*Info) error { return a.getAvailableCommands(info) },
+	secondary_tasks := []analysisTask{
+		{PhasePackages, func() error { return a.getInstalledPackages(info, clock) }},
+		{PhaseCommands, func() error { return a.getAvailableCommands(info) }},
 	}
 
 	wg.Add(len(secondary_tasks))
 	for _, task := range secondary_tasks {
-		go func(t func(*Info) error) {
+		go func(t analysisTask) {
 			defer wg.Done()
-			if err := t(info); err != nil {
+			if err := clock.time(t.phase, t.run); err != nil {
 				errors <- err
 			}
 		}(task)
 	}
 
 	wg.Wait()
+	clock.record(PhaseTotal, time.Since(start))
+	info.Timings = clock.slowestFirst()
 
 	close(errors)
 	if len(errors) > 0 {
@@ -107,7 +131,7 @@ func (a *Analyzer) detectShell(info *Info) error {
 }
 
 func (a *Analyzer) detectPackageManagers(info *Info) error {
-	managers := []string{"apt", "yum", "dnf", "pacman", "brew", "zypper"}
+	managers := []string{"apt", "yum", "dnf", "pacman", "brew", "zypper", "nix"}
 	for _, manager := range managers {
 		if _, err := exec.LookPath(manager); err == nil {
 			info.PackageManagers = append(info.PackageManagers, manager)
@@ -127,7 +151,7 @@ func (a *Analyzer) getPathDirectories(info *Info) error {
 	return nil
 }
 
-func (a *Analyzer) getInstalledPackages(info *Info) error {
+func (a *Analyzer) getInstalledPackages(info *Info, clock *phaseClock) error {
 	var wg sync.WaitGroup
 
 	packageChan := make(chan string, 50)
@@ -136,6 +160,7 @@ func (a *Analyzer) getInstalledPackages(info *Info) error {
 		wg.Add(1)
 		go func(m string) {
 			defer wg.Done()
+			defer func(start time.Time) { clock.record(PackagePhase(m), time.Since(start)) }(time.Now())
 			var cmd *exec.Cmd
 			switch m {
 			case "apt":
@@ -182,27 +207,39 @@ func (a *Analyzer) getInstalledPackages(info *Info) error {
 }
 
 func (a *Analyzer) getAvailableCommands(info *Info) error {
-	commandSet := make(map[string]bool)
-
-	// Get commands from PATH directories
-	for _, dir := range info.PathDirectories {
-		entries, err := os.ReadDir(dir)
-		if err != nil {
-			continue // Skip directories we can't read
+	info.AvailableCommands, info.CommandsTruncated = ScanPathCommands(info.PathDirectories, func(entry os.DirEntry) []string {
+		// On Unix, any file that is not a directory could be an executable script
+		if entry.IsDir() {
+			return nil
 		}
+		return []string{entry.Name()}
+	})
+	return nil
+}
+
+func (a *Analyzer) detectNix(info *Info) error {
+	info.Nix = DetectNixEnvironment(info.CurrentDir, os.Getenv)
+	info.Nix.NixOS = fileExists("/etc/NIXOS")
+	return nil
+}
 
-		for _, entry := range entries {
-			// On Unix, any file that is not a directory could be an executable script
-			if !entry.IsDir() {
-				commandSet[entry.Name()] = true
+func (a *Analyzer) detectImmutable(info *Info) error {
+	info.Immutable = DetectImmutableSystem("/", os.Getenv)
+	return nil
+}
+
+func (a *Analyzer) detectPrivileges(info *Info) error {
+	var groups []string
+	if current, err := user.Current(); err == nil {
+		ids, _ := current.GroupIds()
+		for _, id := range ids {
+			if group, err := user.LookupGroupId(id); err == nil {
+				groups = append(groups, group.Name)
 			}
 		}
 	}
-
-	// Convert map to slice
-	for cmd := range commandSet {
-		info.AvailableCommands = append(info.AvailableCommands, cmd)
-	}
-
+	// "sudo -n" fails at once instead of prompting when a password would be needed
+	sudoWithoutPassword := func() bool { return exec.Command("sudo", "-n", "true").Run() == nil }
+	info.Privileges = DetectPrivileges(os.Geteuid(), groups, exec.LookPath, sudoWithoutPassword)
 	return nil
 }
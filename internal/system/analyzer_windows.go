@@ -17,6 +17,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Info struct {
@@ -28,6 +29,17 @@ type Info struct {
 	PathDirectories   []string
 	InstalledPackages []string
 	AvailableCommands []string
+	CommandsTruncated bool              // the PATH scan stopped early, so AvailableCommands may miss some
+	SSHHosts          []string          // host aliases from ~/.ssh/config, names only
+	ProjectTypes      []ProjectType     // build tools detected in the current directory
+	Nix               NixEnvironment    // Nix shells and project files that should replace global installs
+	Direnv            DirenvEnvironment // the .envrc direnv applies to the current directory
+	Immutable         ImmutableSystem   // image-based distros and read-only roots where installs fail
+	Quick             bool              // only the OS, shell, and directories were gathered (--no-system-scan)
+	Processes         *ProcessReport    // running processes relevant to the intent; nil unless it is about running programs
+	Resources         ResourceLimits    // CPUs and memory available, with any container (cgroup) limits
+	Privileges        Privileges        // whether the user can become root; without it installs stay in the home directory
+	Timings           []PhaseTiming     // how long each phase of the analysis took, the slowest first
 }
 
 type Analyzer struct{}
@@ -45,6 +57,8 @@ func (a *Analyzer) AnalyzeSystem() (*Info, error) {
 
 	var wg sync.WaitGroup
 	errors := make(chan error, 5)
+	clock := &phaseClock{}
+	start := time.Now()
 
 	info.OS = runtime.GOOS
 	currentDir, _ := os.Getwd()
@@ -52,39 +66,45 @@ func (a *Analyzer) AnalyzeSystem() (*Info, error) {
 	info.CurrentDir = currentDir
 	info.HomeDir = homeDir
 
-	initial_tasks := []func(*Info) error{
-		func(*Info) error { return a.detectShell(info) },
-		func(*Info) error { return a.detectPackageManagers(info) },
-		func(*Info) error { return a.getPathDirectories(info) },
+	initial_tasks := []analysisTask{
+		{"shell", func() error { return a.detectShell(info) }},
+		{"package managers", func() error { return a.detectPackageManagers(info) }},
+		{"PATH", func() error { return a.getPathDirectories(info) }},
+		{"SSH hosts", func() error { return a.detectSSHHosts(info) }},
+		{"project types", func() error { return a.detectProjectTypes(info) }},
+		{"direnv", func() error { return a.detectDirenv(info) }},
+		{"resources", func() error { return a.detectResources(info) }},
 	}
 
 	wg.Add(len(initial_tasks))
 	for _, task := range initial_tasks {
-		go func(t func(*Info) error) {
+		go func(t analysisTask) {
 			defer wg.Done()
-			if err := t(info); err != nil {
+			if err := clock.time(t.phase, t.run); err != nil {
 				errors <- err
 			}
 		}(task)
 	}
 	wg.Wait()
 
-	secondary_tasks := []func(*Info) error{
-		func(*Info) error { return a.getInstalledPackages(info) },
-		func(*Info) error { return a.getAvailableCommands(info) },
+	secondary_tasks := []analysisTask{
+		{PhasePackages, func() error { return a.getInstalledPackages(info, clock) }},
+		{PhaseCommands, func() error { return a.getAvailableCommands(info) }},
 	}
 
 	wg.Add(len(secondary_tasks))
 	for _, task := range secondary_tasks {
-		go func(t func(*Info) error) {
+		go func(t analysisTask) {
 			defer wg.Done()
-			if err := t(info); err != nil {
+			if err := clock.time(t.phase, t.run); err != nil {
 				errors <- err
 			}
 		}(task)
 	}
 
 	wg.Wait()
+	clock.record(PhaseTotal, time.Since(start))
+	info.Timings = clock.slowestFirst()
 
 	close(errors)
 	if len(errors) > 0 {
@@ -141,7 +161,7 @@ func (a *Analyzer) getPathDirectories(info *Info) error {
 	return nil
 }
 
-func (a *Analyzer) getInstalledPackages(info *Info) error {
+func (a *Analyzer) getInstalledPackages(info *Info, clock *phaseClock) error {
 	var wg sync.WaitGroup
 	packageChan := make(chan string, 100)
 
@@ -149,6 +169,7 @@ func (a *Analyzer) getInstalledPackages(info *Info) error {
 		wg.Add(1)
 		go func(m string) {
 			defer wg.Done()
+			defer func(start time.Time) { clock.record(PackagePhase(m), time.Since(start)) }(time.Now())
 			var cmd *exec.Cmd
 			var parser func(string) []string
 
@@ -238,41 +259,34 @@ func parseScoopOutput(output string) []string {
 }
 
 func (a *Analyzer) getAvailableCommands(info *Info) error {
-	commandSet := make(map[string]bool)
 	execExtensions := []string{".exe", ".bat", ".cmd", ".com", ".ps1"}
 
-	// Get commands from PATH directories
-	for _, dir := range info.PathDirectories {
-		entries, err := os.ReadDir(dir)
-		if err != nil {
-			continue // Skip unreadable directories
+	// Built-in commands of the detected shell come first; they are always there
+	commands := a.getBuiltinCommands(info.Shell)
+	pathCommands, truncated := ScanPathCommands(info.PathDirectories, func(entry os.DirEntry) []string {
+		if entry.IsDir() {
+			return nil
 		}
-
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				name := entry.Name()
-				lowerName := strings.ToLower(name)
-				for _, ext := range execExtensions {
-					if strings.HasSuffix(lowerName, ext) {
-						commandSet[name] = true                               // e.g., git.exe
-						commandSet[strings.TrimSuffix(lowerName, ext)] = true // e.g., git
-						break
-					}
-				}
+		name := entry.Name()
+		lowerName := strings.ToLower(name)
+		for _, ext := range execExtensions {
+			if strings.HasSuffix(lowerName, ext) {
+				return []string{name, strings.TrimSuffix(lowerName, ext)} // e.g., git.exe and git
 			}
 		}
+		return nil
+	})
+	seen := make(map[string]bool)
+	for _, command := range commands {
+		seen[command] = true
 	}
-
-	// Add built-in commands for the detected shell
-	for _, cmd := range a.getBuiltinCommands(info.Shell) {
-		commandSet[cmd] = true
-	}
-
-	// Convert map to slice
-	for cmd := range commandSet {
-		info.AvailableCommands = append(info.AvailableCommands, cmd)
+	for _, command := range pathCommands {
+		if !seen[command] {
+			seen[command] = true
+			commands = append(commands, command)
+		}
 	}
-
+	info.AvailableCommands, info.CommandsTruncated = commands, truncated
 	return nil
 }
 
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/artifacts.go
+package system
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxWatchedDirs caps the directories watched for files a quest creates
+const maxWatchedDirs = 20
+
+// maxArtifacts caps the created paths listed for one quest
+const maxArtifacts = 100
+
+// unwatchedDirs hold device and kernel files, which come and go on their own
+var unwatchedDirs = []string{"/dev", "/proc", "/sys", "/run"}
+
+// pathTokenSeparators split a command into the words that may be paths, e.g. the target of
+// "> out.txt" or "-out=server.crt"
+const pathTokenSeparators = " \t\n\"'`=<>;|&()"
+
+// Artifact is a file or directory a quest created
+type Artifact struct {
+	Path string
+	Mode fs.FileMode
+	Size int64
+}
+
+// String shows the artifact's permissions, size, and path, e.g. "-rw------- 1.7 KiB /home/me/certs/key.pem"
+func (a Artifact) String() string {
+	if a.Mode.IsDir() {
+		return fmt.Sprintf("%s %8s %s%c", a.Mode, "", a.Path, filepath.Separator)
+	}
+	return fmt.Sprintf("%s %8s %s", a.Mode, formatFileSize(a.Size), a.Path)
+}
+
+// ArtifactWatch remembers the entries of the directories a quest may create files in, so that
+// the files it created can be listed afterwards
+type ArtifactWatch struct {
+	before map[string]map[string]bool // directory to the names it held
+}
+
+// WatchArtifacts records the entries of the current directory and of the directories the paths
+// in content point into, before content is run. A path that does not exist yet is watched
+// through its nearest existing parent, so a directory the quest creates is noticed as well.
+// Paths with variables other than $HOME, or with wildcards, are skipped.
+func WatchArtifacts(content, currentDir, homeDir string) *ArtifactWatch {
+	watch := &ArtifactWatch{before: map[string]map[string]bool{}}
+	dirs := []string{currentDir}
+	for _, token := range strings.FieldsFunc(content, func(r rune) bool { return strings.ContainsRune(pathTokenSeparators, r) }) {
+		if path := resolvePathToken(token, currentDir, homeDir); path != "" {
+			dirs = append(dirs, watchedDir(path))
+		}
+	}
+
+	for _, dir := range dirs {
+		if len(watch.before) == maxWatchedDirs {
+			break
+		}
+		if dir == "" || watch.before[dir] != nil || isUnwatched(dir) {
+			continue
+		}
+		if names, err := dirNames(dir); err == nil {
+			watch.before[dir] = names
+		}
+	}
+	return watch
+}
+
+// Created lists the files and directories that appeared in the watched directories since
+// WatchArtifacts, in path order. Inside a created directory every path is listed.
+func (w *ArtifactWatch) Created() []Artifact {
+	if w == nil {
+		return nil
+	}
+	var artifacts []Artifact
+	seen := map[string]bool{}
+	add := func(path string, info fs.FileInfo) {
+		if seen[path] || len(artifacts) == maxArtifacts {
+			return
+		}
+		seen[path] = true
+		artifacts = append(artifacts, Artifact{Path: path, Mode: info.Mode(), Size: info.Size()})
+	}
+
+	for dir, before := range w.before {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if before[entry.Name()] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Lstat(path)
+			if err != nil || !isArtifactMode(info.Mode()) {
+				continue
+			}
+			add(path, info)
+			if info.IsDir() {
+				_ = filepath.WalkDir(path, func(inner string, d fs.DirEntry, err error) error {
+					if err != nil || inner == path {
+						return nil
+					}
+					if info, err := d.Info(); err == nil && isArtifactMode(info.Mode()) {
+						add(inner, info)
+					}
+					return nil
+				})
+			}
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	return artifacts
+}
+
+// resolvePathToken turns a word of a command into an absolute path when it looks like one:
+// containing a separator or starting with "." or "~"
+func resolvePathToken(token, currentDir, homeDir string) string {
+	for _, prefix := range []string{"$HOME", "${HOME}", "~"} {
+		if token == prefix || strings.HasPrefix(token, prefix+"/") || strings.HasPrefix(token, prefix+`\`) {
+			if homeDir == "" {
+				return ""
+			}
+			token = homeDir + token[len(prefix):]
+			break
+		}
+	}
+	if strings.ContainsAny(token, "$*?[]{}%") || strings.Contains(token, "://") {
+		return ""
+	}
+	if !strings.ContainsAny(token, `/\`) && !strings.HasPrefix(token, ".") {
+		return ""
+	}
+	if !filepath.IsAbs(token) {
+		token = filepath.Join(currentDir, token)
+	}
+	return filepath.Clean(token)
+}
+
+// watchedDir is the directory to watch for path: itself when it is a directory, otherwise its
+// nearest existing parent
+func watchedDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
+
+func isUnwatched(dir string) bool {
+	for _, unwatched := range unwatchedDirs {
+		if dir == unwatched || strings.HasPrefix(dir, unwatched+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isArtifactMode reports whether a created path is worth listing: files, directories, and
+// symlinks, but not sockets, pipes, or devices
+func isArtifactMode(mode fs.FileMode) bool {
+	return mode.IsRegular() || mode.IsDir() || mode&fs.ModeSymlink != 0
+}
+
+func dirNames(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	return names, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/cleanup.go
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxCleanupCandidates caps how many files are offered for selection in a cleanup quest
+const MaxCleanupCandidates = 200
+
+// cleanupListingTimeout bounds how long the listing command may run
+const cleanupListingTimeout = 30 * time.Second
+
+var (
+	cleanupVerbPattern   = regexp.MustCompile(`(?i)\b(delete|remove|clean\s*up|cleanup|clear\s+out|purge|prune|get\s+rid\s+of|tidy\s+up|wipe)\b`)
+	cleanupObjectPattern = regexp.MustCompile(`(?i)\b(files?|logs?|folders?|dirs?|directories|downloads|backups?|dumps?|caches?|temp|tmp|archives?|screenshots?|duplicates?|\*?\.[a-z0-9]{1,5})\b`)
+
+	// Command substitutions could hide a write inside an otherwise read-only listing
+	substitutionPattern = regexp.MustCompile("\\$\\(|`")
+)
+
+// IsCleanupIntent reports whether the intent asks to delete files, so candidates can be listed first
+func IsCleanupIntent(intent string) bool {
+	return cleanupVerbPattern.MatchString(intent) && cleanupObjectPattern.MatchString(intent)
+}
+
+// IsSafeListing reports whether every part of the command only reads, so it can run before approval
+func IsSafeListing(command string) bool {
+	if strings.TrimSpace(command) == "" || strings.Contains(command, "\n") || substitutionPattern.MatchString(command) {
+		return false
+	}
+	for _, part := range SplitCommandChain(command) {
+		if AssessCommandRisk(part.Command) != RiskReadOnly {
+			return false
+		}
+	}
+	return true
+}
+
+// ListCleanupCandidates runs a safe listing command in dir and returns the existing paths it
+// printed, one per line, resolved against dir and without duplicates
+func ListCleanupCandidates(shell, command, dir string) ([]string, error) {
+	if !IsSafeListing(command) {
+		return nil, fmt.Errorf("the listing command is not read-only: %s", command)
+	}
+
+	cmd := NewShellCommand(shell, command)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to list the candidates: %w", err)
+	}
+	timer := time.AfterFunc(cleanupListingTimeout, func() { _ = cmd.Process.Kill() })
+	err := cmd.Wait()
+	timer.Stop()
+
+	// find exits non-zero for unreadable directories while still listing the rest
+	if err != nil && stdout.Len() == 0 {
+		return nil, fmt.Errorf("failed to list the candidates: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParseCleanupCandidates(stdout.String(), dir), nil
+}
+
+// ParseCleanupCandidates turns listing output into absolute paths of existing files, keeping at
+// most MaxCleanupCandidates
+func ParseCleanupCandidates(output, dir string) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, line := range strings.Split(output, "\n") {
+		path := strings.TrimSpace(line)
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+		if seen[path] || !fileExists(path) {
+			continue
+		}
+		seen[path] = true
+		candidates = append(candidates, path)
+		if len(candidates) == MaxCleanupCandidates {
+			break
+		}
+	}
+	return candidates
+}
+
+// BuildDeleteCommand builds a command that deletes exactly the given paths in the given shell.
+// Directories are removed with their contents.
+func BuildDeleteCommand(shell string, paths []string) string {
+	var files, dirs []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, path)
+		} else {
+			files = append(files, path)
+		}
+	}
+
+	switch ShellFamily(shell) {
+	case ShellFamilyPowerShell:
+		quoted := make([]string, 0, len(paths))
+		for _, path := range paths {
+			quoted = append(quoted, "'"+strings.ReplaceAll(path, "'", "''")+"'")
+		}
+		command := "Remove-Item -LiteralPath " + strings.Join(quoted, ", ")
+		if len(dirs) > 0 {
+			command += " -Recurse"
+		}
+		return command
+	case ShellFamilyCmd:
+		var steps []string
+		if len(files) > 0 {
+			steps = append(steps, "del /Q "+cmdQuoteAll(files))
+		}
+		if len(dirs) > 0 {
+			steps = append(steps, "rmdir /S /Q "+cmdQuoteAll(dirs))
+		}
+		return strings.Join(steps, " && ")
+	default:
+		quoted := make([]string, 0, len(paths))
+		for _, path := range paths {
+			quoted = append(quoted, shellQuote(path))
+		}
+		if len(dirs) > 0 {
+			return "rm -r -- " + strings.Join(quoted, " ")
+		}
+		return "rm -- " + strings.Join(quoted, " ")
+	}
+}
+
+func cmdQuoteAll(paths []string) string {
+	quoted := make([]string, 0, len(paths))
+	for _, path := range paths {
+		quoted = append(quoted, `"`+path+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
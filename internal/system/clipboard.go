@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/clipboard.go
+package system
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// MaxClipboardChars caps the copied text sent with an intent. An error message or a stack trace
+// fits easily; anything longer is cut, keeping its beginning, where errors usually start.
+const MaxClipboardChars = 8000
+
+// ReadClipboard returns the text on the system clipboard. It uses pbpaste on macOS, the Windows
+// clipboard API, and xclip, xsel, or wl-paste on Linux, one of which must be installed.
+func ReadClipboard() (string, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the clipboard: %w", err)
+	}
+	return text, nil
+}
+
+// TrimClipboard normalizes copied text for a prompt: line endings become \n, surrounding blank
+// lines are dropped, and text beyond MaxClipboardChars is cut. It reports whether text was cut.
+func TrimClipboard(text string) (string, bool) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Trim(text, "\n\r\t ")
+	runes := []rune(text)
+	if len(runes) <= MaxClipboardChars {
+		return text, false
+	}
+	return string(runes[:MaxClipboardChars]), true
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/command_chain.go
+package system
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ChainPart is one command of a chain joined with &&, || or ;
+type ChainPart struct {
+	Command  string
+	Operator string // operator joining this part to the previous one; empty for the first part
+}
+
+// SplitCommandChain splits a single command line on &&, || and ; while respecting quotes,
+// escapes, and $(...) substitutions. Pipes and background jobs stay within their part.
+func SplitCommandChain(command string) []ChainPart {
+	var parts []ChainPart
+	var current strings.Builder
+	operator := ""
+
+	var quote rune
+	depth := 0
+	escaped := false
+	runes := []rune(command)
+
+	flush := func(nextOperator string) {
+		if part := strings.TrimSpace(current.String()); part != "" {
+			parts = append(parts, ChainPart{Command: part, Operator: operator})
+		}
+		current.Reset()
+		operator = nextOperator
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '(':
+			depth++
+		case r == '(' && depth > 0:
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && r == ';':
+			flush(";")
+			continue
+		case depth == 0 && i+1 < len(runes) && (r == '&' || r == '|') && runes[i+1] == r:
+			flush(string([]rune{r, r}))
+			i++
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+	flush("")
+
+	return parts
+}
+
+// RiskLevel classifies what a command can do to the system
+type RiskLevel int
+
+const (
+	RiskReadOnly RiskLevel = iota
+	RiskModifies
+	RiskElevated
+	RiskDestructive
+)
+
+func (r RiskLevel) String() string {
+	switch r {
+	case RiskReadOnly:
+		return "read-only"
+	case RiskModifies:
+		return "modifies"
+	case RiskElevated:
+		return "elevated"
+	default:
+		return "destructive"
+	}
+}
+
+// ParseRiskLevel returns the risk level with the given name, as String spells it
+func ParseRiskLevel(name string) (RiskLevel, bool) {
+	for _, risk := range []RiskLevel{RiskReadOnly, RiskModifies, RiskElevated, RiskDestructive} {
+		if name == risk.String() {
+			return risk, true
+		}
+	}
+	return RiskReadOnly, false
+}
+
+var (
+	destructivePatterns = regexp.MustCompile(`(?i)\brm\s+(-\w*[rf]\w*\s+)+|\b(dd|mkfs(\.\w+)?|fdisk|parted|wipefs|shred|truncate)\b|\bchmod\s+(-\w+\s+)*777\b|\bchown\s+-\w*r|\b(shutdown|reboot|halt|poweroff)\b|\bkill(all)?\s+-(9|kill)\b|\bgit\s+(push\b.*(--force|\s-f\b)|reset\s+--hard|clean\s+-\w*f)|\bdrop\s+(database|table)\b|\bfind\b.*\s-delete\b|\bremove-item\b.*-recurse|\bformat\s+[a-z]:|\b(rd|rmdir)\s+/s\b|\bdel\s+/[sq]\b`)
+
+	elevationPattern = regexp.MustCompile(`(?i)^(sudo|doas|pkexec|runas|gsudo)\b`)
+
+	// Redirections that write files; redirections to /dev/null or other descriptors are harmless
+	writeRedirectPattern = regexp.MustCompile(`(^|[^0-9&<])>{1,2}\s*[^&\s]`)
+	nullRedirectPattern  = regexp.MustCompile(`\d?>{1,2}\s*(/dev/null|\$null|nul)\b`)
+
+	readOnlyCommands = map[string]bool{
+		"ls": true, "ll": true, "cat": true, "less": true, "more": true, "head": true, "tail": true,
+		"grep": true, "egrep": true, "fgrep": true, "rg": true, "ag": true, "find": true, "fd": true,
+		"du": true, "df": true, "ps": true, "top": true, "htop": true, "pwd": true, "whoami": true,
+		"id": true, "echo": true, "printf": true, "wc": true, "sort": true, "uniq": true, "cut": true,
+		"which": true, "type": true, "file": true, "stat": true, "uname": true, "date": true,
+		"env": true, "printenv": true, "hostname": true, "uptime": true, "free": true, "lsblk": true,
+		"lsof": true, "netstat": true, "ss": true, "ping": true, "dig": true, "nslookup": true,
+		"host": true, "tree": true, "diff": true, "cmp": true, "md5sum": true, "sha256sum": true,
+		"man": true, "journalctl": true, "jq": true, "awk": true, "test": true, "true": true,
+		"dir": true, "get-childitem": true, "get-content": true, "get-process": true, "get-item": true,
+		"select-string": true, "where": true, "where-object": true, "write-output": true, "write-host": true,
+		WaitHelper: true,
+	}
+
+	// Subcommands that only read state for tools that can also modify it
+	readOnlySubcommands = map[string]map[string]bool{
+		"git":       {"status": true, "log": true, "diff": true, "show": true, "blame": true, "remote": true, "rev-parse": true},
+		"docker":    {"ps": true, "images": true, "logs": true, "inspect": true, "version": true, "info": true},
+		"kubectl":   {"get": true, "describe": true, "logs": true, "version": true, "explain": true, "top": true},
+		"systemctl": {"status": true, "list-units": true, "is-active": true, "is-enabled": true, "cat": true},
+	}
+)
+
+// AssessCommandRisk classifies a single command (not a chain) by what it can do
+func AssessCommandRisk(command string) RiskLevel {
+	command = strings.TrimSpace(command)
+	if destructivePatterns.MatchString(command) {
+		return RiskDestructive
+	}
+	if elevationPattern.MatchString(command) {
+		return RiskElevated
+	}
+	if writeRedirectPattern.MatchString(nullRedirectPattern.ReplaceAllString(command, "")) {
+		return RiskModifies
+	}
+
+	// Every command of a pipeline must be read-only for the whole part to be read-only
+	for _, stage := range strings.Split(command, "|") {
+		if !isReadOnlyCommand(stage) {
+			return RiskModifies
+		}
+	}
+	return RiskReadOnly
+}
+
+func isReadOnlyCommand(command string) bool {
+	fields := strings.Fields(strings.ToLower(command))
+
+	// Skip environment assignments and harmless wrappers
+	for len(fields) > 0 && (strings.Contains(fields[0], "=") || fields[0] == "time" || fields[0] == "nohup") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return true
+	}
+
+	name := fields[0]
+	if subcommands, ok := readOnlySubcommands[name]; ok {
+		return len(fields) > 1 && subcommands[fields[1]]
+	}
+	if name == "find" && strings.Contains(command, "-exec") {
+		return false
+	}
+	if name == "sed" {
+		return !strings.Contains(command, " -i")
+	}
+	return readOnlyCommands[name]
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/critical_dir.go
+package system
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// CriticalDirectoryUse describes a command that acts on relative paths or globs inside a
+// directory the system depends on, as in "rm -rf *" run from / instead of a build folder
+type CriticalDirectoryUse struct {
+	Dir  string // the critical directory the paths are relative to
+	Line string // the command that uses them
+	Path string // the first relative path or glob it uses
+}
+
+var (
+	// criticalDirs are critical themselves, but hold folders that are not, such as /usr/local/src
+	criticalDirs = map[string][]string{
+		"unix":    {"/", "/usr", "/usr/local", "/usr/share", "/var", "/var/lib", "/opt", "/home", "/Users"},
+		"darwin":  {"/private", "/private/var", "/Applications"},
+		"windows": {"/", "/users", "/program files", "/program files (x86)", "/programdata"},
+	}
+	// criticalTrees are critical along with everything below them
+	criticalTrees = map[string][]string{
+		"unix":    {"/etc", "/boot", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/usr/bin", "/usr/sbin", "/usr/lib", "/usr/lib64", "/proc", "/sys", "/dev"},
+		"darwin":  {"/System", "/Library", "/private/etc"},
+		"windows": {"/windows"},
+	}
+
+	// windowsDrive is the drive of an absolute Windows path, as in C:\Windows
+	windowsDrive = regexp.MustCompile(`^[A-Za-z]:`)
+)
+
+// pathCommands take file and directory names as their arguments, so even a bare name is a path
+// relative to the current directory
+var pathCommands = map[string]bool{
+	"rm": true, "rmdir": true, "mv": true, "cp": true, "ln": true, "touch": true, "mkdir": true,
+	"truncate": true, "shred": true, "tar": true, "zip": true, "unzip": true, "rsync": true,
+	"chmod": true, "chown": true, "chgrp": true, "chattr": true,
+	"del": true, "erase": true, "rd": true, "md": true, "move": true, "copy": true, "ren": true,
+	"remove-item": true, "ri": true, "move-item": true, "copy-item": true, "rename-item": true,
+}
+
+// modeCommands take a mode or an owner before their paths
+var modeCommands = map[string]bool{"chmod": true, "chown": true, "chgrp": true, "chattr": true}
+
+// IsCriticalDirectory reports whether dir is a directory the system depends on, where a command
+// with relative paths or globs can break the machine: /, /etc, C:\Windows, and the like
+func IsCriticalDirectory(dir, goos string) bool {
+	if dir == "" {
+		return false
+	}
+	dir = comparablePath(dir, goos)
+	families := []string{"unix", goos}
+	if goos == "windows" {
+		families = []string{"windows"}
+	}
+
+	for _, family := range families {
+		for _, critical := range criticalDirs[family] {
+			if dir == critical {
+				return true
+			}
+		}
+		for _, tree := range criticalTrees[family] {
+			if dir == tree || strings.HasPrefix(dir, tree+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectCriticalDirectoryUse finds the first command of a command or script that uses relative
+// paths or globs while the directory it runs in is critical. dir is where the content starts
+// running; a cd within it moves the later commands, so "cd / && rm -rf *" is caught from anywhere.
+func DetectCriticalDirectoryUse(content, dir, goos string) *CriticalDirectoryUse {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToUpper(line), "REM ") {
+			continue
+		}
+		for _, part := range SplitCommandChain(line) {
+			words := unwrapCommand(shellWords(part.Command))
+			if len(words) > 0 && isChangeDirectory(words[0]) {
+				dir = changeDirectory(dir, words[1:], goos)
+				continue
+			}
+			if !IsCriticalDirectory(dir, goos) {
+				continue
+			}
+			if relative := relativePathIn(part.Command, words, goos); relative != "" {
+				return &CriticalDirectoryUse{Dir: dir, Line: part.Command, Path: relative}
+			}
+		}
+	}
+	return nil
+}
+
+// relativePathIn returns the first glob or relative path a simple command uses, or "" when all
+// of its paths are absolute
+func relativePathIn(command string, words []string, goos string) string {
+	if glob := unquotedGlob(command, goos); glob != "" {
+		return glob
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	name := strings.ToLower(words[0])
+	args := words[1:]
+	skippedMode := !modeCommands[name]
+	for _, arg := range args {
+		if isOption(arg, goos) || strings.Contains(arg, "=") {
+			continue
+		}
+		if !skippedMode {
+			skippedMode = true
+			continue
+		}
+		if isAbsoluteArgument(arg, goos) {
+			continue
+		}
+		if arg == "." || arg == ".." || strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") ||
+			strings.Contains(arg, "/") || (goos == "windows" && strings.Contains(arg, `\`)) || pathCommands[name] {
+			return arg
+		}
+	}
+	return ""
+}
+
+// unquotedGlob returns the first word of a command with a wildcard the shell expands, leaving
+// out patterns in quotes, which a tool such as find matches itself, absolute patterns, $? and
+// the ? of URLs
+func unquotedGlob(command, goos string) string {
+	var quote rune
+	escaped := false
+	for _, word := range strings.Fields(command) {
+		glob := false
+		url := strings.Contains(word, "://")
+		previous := ' '
+		for _, r := range word {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"':
+				quote = r
+			case r == '*' || (r == '?' && previous != '$' && !url):
+				glob = true
+			}
+			previous = r
+		}
+		if glob && !isAbsoluteArgument(strings.Trim(word, `'"`), goos) {
+			return word
+		}
+	}
+	return ""
+}
+
+// isOption reports whether a word is a flag rather than a path; cmd's flags start with a slash
+func isOption(word, goos string) bool {
+	if strings.HasPrefix(word, "-") && len(word) > 1 {
+		return true
+	}
+	return goos == "windows" && strings.HasPrefix(word, "/") && len(word) <= 3
+}
+
+// isAbsoluteArgument reports whether a word names a path that does not depend on the current
+// directory, including paths under the home directory and in variables
+func isAbsoluteArgument(word, goos string) bool {
+	if strings.HasPrefix(word, "/") || strings.HasPrefix(word, "~") || strings.HasPrefix(word, "$") {
+		return true
+	}
+	if goos == "windows" {
+		return windowsDrive.MatchString(word) || strings.HasPrefix(word, `\`) || strings.HasPrefix(word, "%")
+	}
+	return false
+}
+
+func isChangeDirectory(name string) bool {
+	switch strings.ToLower(name) {
+	case "cd", "chdir", "pushd", "set-location", "sl":
+		return true
+	}
+	return false
+}
+
+// changeDirectory returns the directory a cd moves to. A cd whose target cannot be known, such
+// as one to a variable, leaves the directory unknown, and nothing after it is flagged.
+func changeDirectory(dir string, args []string, goos string) string {
+	var target string
+	for _, arg := range args {
+		if !isOption(arg, goos) {
+			target = arg
+			break
+		}
+	}
+	switch {
+	case target == "" || strings.HasPrefix(target, "~"):
+		// The home directory is not critical
+		return ""
+	case strings.HasPrefix(target, "$") || strings.HasPrefix(target, "%") || target == "-":
+		return ""
+	case isAbsoluteArgument(target, goos):
+		return target
+	case dir == "":
+		return ""
+	}
+	if goos == "windows" {
+		return strings.ReplaceAll(path.Join(strings.ReplaceAll(dir, `\`, "/"), strings.ReplaceAll(target, `\`, "/")), "/", `\`)
+	}
+	return path.Join(dir, target)
+}
+
+// comparablePath cleans a path for comparison with the critical directories. Windows paths lose
+// their drive and are lowercased, since C:\Windows and d:\WINDOWS are the same kind of place.
+func comparablePath(dir, goos string) string {
+	if goos != "windows" {
+		return path.Clean(dir)
+	}
+	dir = strings.ToLower(strings.ReplaceAll(dir, `\`, "/"))
+	dir = windowsDrive.ReplaceAllString(dir, "")
+	if dir == "" {
+		return "/"
+	}
+	return path.Clean(dir)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/detach.go
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"time"
+)
+
+// longRunningPattern matches commands that usually run for many minutes: big compiles, backups,
+// copies of whole trees, system upgrades, image builds, and media encoding
+var longRunningPattern = regexp.MustCompile(`(?i)(^|[;&|(]\s*|\bsudo\s+)(` +
+	`make\b|ninja\b|cmake\s+--build|cargo\s+build\s+.*--release|bazel\s+build|gradle\w*\s+(build|assemble)|mvn\w*\s+(package|install|verify)|` +
+	`docker\s+(build|compose\s+build)|podman\s+build|` +
+	`rsync\b|dd\b|borg\s+create|restic\s+backup|duplicity\b|rclone\s+(copy|sync)|pg_dump(all)?\b|mysqldump\b|` +
+	`tar\s+\S*c|zip\s+-r|7z\s+a\b|` +
+	`apt(-get)?\s+(-y\s+)?(upgrade|full-upgrade|dist-upgrade)|dnf\s+(-y\s+)?(upgrade|system-upgrade)|pacman\s+-Syu|do-release-upgrade|` +
+	`ffmpeg\b|HandBrakeCLI\b)`)
+
+// IsLongRunning estimates whether a command runs long enough that closing the terminal by
+// accident would likely cut it short
+func IsLongRunning(command string) bool {
+	return longRunningPattern.MatchString(command)
+}
+
+// FindMultiplexer returns "tmux" or "screen" when one of them is installed, or an empty string.
+// Inside an existing session a closed terminal only detaches, so none is offered there either.
+func FindMultiplexer() string {
+	if runtime.GOOS == "windows" || os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return ""
+	}
+	for _, tool := range []string{"tmux", "screen"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return ""
+}
+
+// DetachedSession is a tmux or screen session a long quest runs in, away from the terminal
+type DetachedSession struct {
+	Tool string // "tmux" or "screen"
+	Name string
+}
+
+// NewDetachedSession names a session for a quest started at the given time
+func NewDetachedSession(tool string, now time.Time) *DetachedSession {
+	return &DetachedSession{Tool: tool, Name: "emw-" + now.Format("0102-150405")}
+}
+
+// Command wraps a command so that it starts in the detached session and returns at once. The
+// session stays open after the command finishes, so its output and exit code can still be read
+// on re-attaching.
+func (d *DetachedSession) Command(command, shell string) string {
+	inner := command + `; status=$?; printf '\n[quest finished with exit code %s; press Enter to close]\n' "$status"; read -r _`
+	if d.Tool == "screen" {
+		return fmt.Sprintf("screen -dmS %s %s -c %s", d.Name, shellQuote(shell), shellQuote(inner))
+	}
+	return fmt.Sprintf("tmux new-session -d -s %s %s", d.Name, shellQuote(shellQuote(shell)+" -c "+shellQuote(inner)))
+}
+
+// AttachCommand is how the user returns to the session
+func (d *DetachedSession) AttachCommand() string {
+	if d.Tool == "screen" {
+		return "screen -r " + d.Name
+	}
+	return "tmux attach -t " + d.Name
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/direnv.go
+package system
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DirenvEnvironment describes the .envrc that direnv applies to the current directory. Project
+// commands expect its variables, so they must run where direnv has loaded it.
+type DirenvEnvironment struct {
+	Envrc     string   // the nearest .envrc in the current directory or its parents
+	Installed bool     // direnv is on the PATH
+	Loaded    bool     // direnv's hook has loaded the .envrc into the knight's environment
+	Uses      []string // direnv layouts and integrations it calls, e.g. "layout python" or "use flake"
+	Variables []string // names of the variables it exports, leaving out any that look secret
+}
+
+// maxEnvrcVariables caps how many variable names are kept from an .envrc
+const maxEnvrcVariables = 30
+
+var (
+	// envrcExport matches "export NAME=value" in an .envrc and "NAME=value" in a dotenv file
+	envrcExport = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=`)
+	// envrcUse matches direnv's "use" and "layout" directives
+	envrcUse = regexp.MustCompile(`^((?:use|layout)\s+[A-Za-z0-9_\-]+)`)
+	// envrcDotenv matches direnv's dotenv directives and the file they load
+	envrcDotenv = regexp.MustCompile(`^dotenv(?:_if_exists)?(?:\s+(\S+))?\s*$`)
+	// secretVariableName matches variable names whose values are likely credentials
+	secretVariableName = regexp.MustCompile(`(?i)pass|secret|token|key|credential|auth|cookie|session|private|salt|signature`)
+)
+
+// Detected reports whether an .envrc applies to the current directory
+func (d DirenvEnvironment) Detected() bool {
+	return d.Envrc != ""
+}
+
+func (d DirenvEnvironment) String() string {
+	return d.Describe(true)
+}
+
+// Describe summarizes the .envrc and whether direnv has loaded it. The names of its variables are
+// only included when withVariables is set.
+func (d DirenvEnvironment) Describe(withVariables bool) string {
+	if !d.Detected() {
+		return "not detected"
+	}
+
+	parts := []string{d.Envrc}
+	switch {
+	case d.Loaded:
+		parts = append(parts, "loaded by direnv")
+	case d.Installed:
+		parts = append(parts, "not loaded (direnv is installed but not hooked into this shell, or the .envrc is not allowed)")
+	default:
+		parts = append(parts, "not loaded (direnv is not installed)")
+	}
+	if len(d.Uses) > 0 {
+		parts = append(parts, "uses: "+strings.Join(d.Uses, ", "))
+	}
+	if withVariables && len(d.Variables) > 0 {
+		parts = append(parts, "exports: "+strings.Join(d.Variables, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DetectDirenv finds the .envrc that applies to dir and reads the names of the variables it
+// exports, but not their values. Whether direnv is installed is detected by the analyzer.
+func DetectDirenv(dir string, getenv func(string) string) DirenvEnvironment {
+	var direnv DirenvEnvironment
+	if dir == "" {
+		return direnv
+	}
+
+	for current := dir; ; {
+		if path := filepath.Join(current, ".envrc"); fileExists(path) {
+			direnv.Envrc = path
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return direnv
+		}
+		current = parent
+	}
+
+	// direnv sets DIRENV_DIR to "-" followed by the directory of the .envrc it loaded
+	direnv.Loaded = strings.TrimPrefix(getenv("DIRENV_DIR"), "-") == filepath.Dir(direnv.Envrc)
+
+	seen := make(map[string]bool)
+	addVariable := func(name string) {
+		if seen[name] || secretVariableName.MatchString(name) || len(direnv.Variables) >= maxEnvrcVariables {
+			return
+		}
+		seen[name] = true
+		direnv.Variables = append(direnv.Variables, name)
+	}
+
+	forEachEnvrcLine(direnv.Envrc, func(line string) {
+		if m := envrcDotenv.FindStringSubmatch(line); m != nil {
+			dotenv := m[1]
+			if dotenv == "" {
+				dotenv = ".env"
+			}
+			if !filepath.IsAbs(dotenv) {
+				dotenv = filepath.Join(filepath.Dir(direnv.Envrc), dotenv)
+			}
+			forEachEnvrcLine(dotenv, func(line string) {
+				if m := envrcExport.FindStringSubmatch(line); m != nil {
+					addVariable(m[1])
+				}
+			})
+			return
+		}
+		if m := envrcUse.FindStringSubmatch(line); m != nil {
+			direnv.Uses = append(direnv.Uses, m[1])
+			return
+		}
+		if m := envrcExport.FindStringSubmatch(line); m != nil && strings.HasPrefix(line, "export") {
+			addVariable(m[1])
+		}
+	})
+	return direnv
+}
+
+// forEachEnvrcLine calls fn with each trimmed line of a file, skipping blanks and comments
+func forEachEnvrcLine(path string, fn func(line string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+}
+
+func (a *Analyzer) detectDirenv(info *Info) error {
+	info.Direnv = DetectDirenv(info.CurrentDir, os.Getenv)
+	_, err := exec.LookPath("direnv")
+	info.Direnv.Installed = err == nil
+	return nil
+}
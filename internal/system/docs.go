@@ -0,0 +1,352 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/docs.go
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DocsIndexFile is the local index of tool documentation, kept in the state directory
+const DocsIndexFile = "docs-index.json"
+
+const (
+	// maxToolDocChars bounds the documentation kept for one tool; the synopsis and options come first
+	maxToolDocChars = 24000
+	// docChunkChars is the size a tool's documentation is split into for retrieval
+	docChunkChars = 1200
+	// maxDocChunks and maxDocsChars bound the documentation sent with one quest
+	maxDocChunks = 3
+	maxDocsChars = 3600
+	// toolHelpTimeout bounds how long a man page or --help output may take
+	toolHelpTimeout = 5 * time.Second
+)
+
+// BM25 parameters for ranking documentation chunks against an intent
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// overstrikePattern matches the backspace sequences man uses for bold and underlined text
+var overstrikePattern = regexp.MustCompile(".\b")
+
+// docStopWords carry no meaning when matching an intent against documentation
+var docStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true, "in": true, "on": true,
+	"for": true, "with": true, "by": true, "from": true, "into": true, "is": true, "are": true, "be": true,
+	"it": true, "this": true, "that": true, "my": true, "me": true, "i": true, "all": true, "use": true,
+	"using": true, "please": true, "can": true, "you": true, "as": true, "at": true, "if": true, "not": true,
+}
+
+// DocChunk is one part of a tool's documentation
+type DocChunk struct {
+	Tool   string `json:"tool"`
+	Source string `json:"source"` // "man" or "--help"
+	Text   string `json:"text"`
+}
+
+// DocsIndex is a local index of the documentation of installed tools. Chunks are ranked against an
+// intent with BM25 over their words, so no model or network is needed to search it.
+type DocsIndex struct {
+	Built  time.Time  `json:"built"`
+	Chunks []DocChunk `json:"chunks"`
+
+	terms   []map[string]int // the words of each chunk and how often they occur
+	docFreq map[string]int   // the number of chunks each word occurs in
+	avgLen  float64
+}
+
+// NewDocsIndex builds an index from documentation chunks
+func NewDocsIndex(chunks []DocChunk, built time.Time) *DocsIndex {
+	index := &DocsIndex{Built: built, Chunks: chunks}
+	index.prepare()
+	return index
+}
+
+// LoadDocsIndex reads the index at path. A missing index is not an error: it returns nil, since
+// the index is optional.
+func LoadDocsIndex(path string) (*DocsIndex, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index DocsIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("the documentation index at %s is damaged; rebuild it with 'execute-my-will docs': %w", path, err)
+	}
+	index.prepare()
+	return &index, nil
+}
+
+// Save writes the index to path
+func (ix *DocsIndex) Save(path string) error {
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Tools lists the tools the index has documentation for, sorted
+func (ix *DocsIndex) Tools() []string {
+	var tools []string
+	for _, chunk := range ix.Chunks {
+		if !slices.Contains(tools, chunk.Tool) {
+			tools = append(tools, chunk.Tool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Replace swaps the documentation of the given tools for new chunks, keeping every other tool's
+func (ix *DocsIndex) Replace(tools []string, chunks []DocChunk, built time.Time) *DocsIndex {
+	kept := make([]DocChunk, 0, len(ix.Chunks)+len(chunks))
+	for _, chunk := range ix.Chunks {
+		if !slices.Contains(tools, chunk.Tool) {
+			kept = append(kept, chunk)
+		}
+	}
+	return NewDocsIndex(append(kept, chunks...), built)
+}
+
+func (ix *DocsIndex) prepare() {
+	ix.terms = make([]map[string]int, len(ix.Chunks))
+	ix.docFreq = make(map[string]int)
+	total := 0
+	for i, chunk := range ix.Chunks {
+		counts := make(map[string]int)
+		for _, word := range docWords(chunk.Text) {
+			counts[word]++
+			total++
+		}
+		for word := range counts {
+			ix.docFreq[word]++
+		}
+		ix.terms[i] = counts
+	}
+	if len(ix.Chunks) > 0 {
+		ix.avgLen = float64(total) / float64(len(ix.Chunks))
+	}
+}
+
+// Search returns up to limit chunks that best match the query, best first. A chunk of a tool the
+// query names scores twice as high, since the intent is then clearly about that tool.
+func (ix *DocsIndex) Search(query string, limit int) []DocChunk {
+	words := docWords(query)
+	if len(words) == 0 || len(ix.Chunks) == 0 {
+		return nil
+	}
+	type scored struct {
+		index int
+		score float64
+	}
+	var results []scored
+	for i, chunk := range ix.Chunks {
+		length := 0
+		for _, count := range ix.terms[i] {
+			length += count
+		}
+		score := 0.0
+		for _, word := range words {
+			frequency := float64(ix.terms[i][word])
+			if frequency == 0 {
+				continue
+			}
+			df := float64(ix.docFreq[word])
+			idf := math.Log(1 + (float64(len(ix.Chunks))-df+0.5)/(df+0.5))
+			score += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*float64(length)/ix.avgLen))
+		}
+		if score == 0 {
+			continue
+		}
+		if slices.Contains(words, strings.ToLower(chunk.Tool)) {
+			score *= 2
+		}
+		results = append(results, scored{i, score})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+
+	chunks := make([]DocChunk, 0, min(limit, len(results)))
+	for _, result := range results[:min(limit, len(results))] {
+		chunks = append(chunks, ix.Chunks[result.index])
+	}
+	return chunks
+}
+
+// Grounding returns the documentation excerpts that best match the intent, ready to be sent with
+// it, or an empty string when nothing matches
+func (ix *DocsIndex) Grounding(intent string) string {
+	chunks := ix.Search(intent, maxDocChunks)
+	if len(chunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("TOOL DOCUMENTATION (excerpts from the installed tools' own documentation; prefer these flags and options over remembered ones):")
+	for _, chunk := range chunks {
+		excerpt := fmt.Sprintf("\n--- %s (%s) ---\n%s", chunk.Tool, chunk.Source, chunk.Text)
+		if b.Len()+len(excerpt) > maxDocsChars {
+			break
+		}
+		b.WriteString(excerpt)
+	}
+	return b.String()
+}
+
+// GroundedTools lists the tools whose documentation Grounding would send for the intent
+func (ix *DocsIndex) GroundedTools(intent string) []string {
+	var tools []string
+	for _, chunk := range ix.Search(intent, maxDocChunks) {
+		if !slices.Contains(tools, chunk.Tool) {
+			tools = append(tools, chunk.Tool)
+		}
+	}
+	return tools
+}
+
+// docWords splits text into lowercase words for matching, keeping flags such as "--preset" as
+// "preset" and leaving out stop words
+func docWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	words := fields[:0]
+	for _, field := range fields {
+		field = strings.Trim(field, "-_")
+		if len(field) > 1 && !docStopWords[field] {
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// ChunkToolDoc splits a tool's documentation into chunks of whole paragraphs
+func ChunkToolDoc(tool, source, text string) []DocChunk {
+	text = CleanManText(text)
+	if len(text) > maxToolDocChars {
+		text = text[:maxToolDocChars]
+	}
+	var chunks []DocChunk
+	var current strings.Builder
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			chunks = append(chunks, DocChunk{Tool: tool, Source: source, Text: strings.TrimSpace(current.String())})
+		}
+		current.Reset()
+	}
+	var paragraphs []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraphs = append(paragraphs, splitLongParagraph(paragraph)...)
+	}
+	for _, paragraph := range paragraphs {
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(paragraph) > docChunkChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	flush()
+	return chunks
+}
+
+// splitLongParagraph splits a paragraph longer than a chunk, such as a long list of options, at
+// line breaks
+func splitLongParagraph(paragraph string) []string {
+	if len(paragraph) <= docChunkChars {
+		return []string{paragraph}
+	}
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.Split(paragraph, "\n") {
+		if current.Len() > 0 && current.Len()+len(line) > docChunkChars {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line[:min(len(line), docChunkChars)])
+	}
+	return append(parts, current.String())
+}
+
+// CleanManText removes man's overstrike formatting and squeezes the indentation and blank lines
+// that only matter on screen
+func CleanManText(text string) string {
+	text = overstrikePattern.ReplaceAllString(text, "")
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	cleaned := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		// Deep indentation only aligns columns; a little keeps continuation lines apart
+		trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+		line = strings.Repeat(" ", min(len(line)-len(trimmed), 4)) + trimmed
+		if trimmed == "" {
+			if !blank && len(cleaned) > 0 {
+				cleaned = append(cleaned, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		cleaned = append(cleaned, line)
+	}
+	return strings.TrimSpace(strings.Join(cleaned, "\n"))
+}
+
+// CollectToolDoc returns a tool's documentation: its man page where there is one, otherwise its
+// --help output when allowHelp is set. Running an arbitrary program with --help is only safe for
+// tools the user named, so it is never done for the whole PATH.
+func CollectToolDoc(tool string, allowHelp bool) (source, text string) {
+	if page := readManPage(tool); page != "" {
+		return "man", page
+	}
+	if !allowHelp {
+		return "", ""
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return "", ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), toolHelpTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, tool, "--help")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Many tools print their help to stderr and exit with a non-zero status
+	_ = cmd.Run()
+	if ctx.Err() != nil {
+		return "", ""
+	}
+	return "--help", strings.TrimSpace(out.String())
+}
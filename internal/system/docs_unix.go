@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+)
+
+// readManPage returns the tool's man page as plain text, or an empty string when it has none
+func readManPage(tool string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), toolHelpTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "man", tool)
+	cmd.Env = append(os.Environ(), "MANPAGER=cat", "PAGER=cat", "MANWIDTH=100", "MAN_KEEP_FORMATTING=")
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build windows
+// +build windows
+
+package system
+
+// readManPage returns an empty string: Windows has no man pages, so tools are documented by
+// their --help output
+func readManPage(tool string) string {
+	return ""
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/download_guard.go
+package system
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DownloadRisk describes a download in a command that is executed or installed without verification
+type DownloadRisk struct {
+	Line   string
+	Reason string
+}
+
+var (
+	// curl/wget output piped straight into an interpreter
+	pipeToShellPattern = regexp.MustCompile(`(?i)\b(curl|wget|iwr|invoke-webrequest|irm|invoke-restmethod)\b[^|]*\|\s*(sudo\s+)?(ba|z|da|fi)?sh\b|\b(iwr|irm|invoke-webrequest|invoke-restmethod)\b[^|]*\|\s*iex\b`)
+
+	// downloads of installable artifacts
+	downloadPattern  = regexp.MustCompile(`(?i)\b(curl|wget|iwr|invoke-webrequest|aria2c)\b`)
+	installerPattern = regexp.MustCompile(`(?i)\S+\.(deb|rpm|pkg|msi|exe|appimage|apk|dmg|run|sh|tar\.gz|tgz|tar\.xz)\b`)
+
+	// commands that verify what was downloaded, and the checksum lists they check
+	verificationPattern = regexp.MustCompile(`(?i)\b(sha256sum|sha512sum|shasum|md5sum|gpg\s+--verify|gpgv|minisign|cosign\s+verify|get-filehash|certutil\s+-hashfile)\b`)
+	checksumListPattern = regexp.MustCompile(`(?:^|\s)(-c|--check)(\s|$)`)
+
+	// the file given to an output option, which a download saves instead of its URL's last part
+	outputFilePattern = regexp.MustCompile(`(?i)(?:^|\s)(?:-[a-z]*o|--output(?:-document)?|-outfile)[\s=]+["']?([^\s"'|;&]+)`)
+)
+
+// DetectUnverifiedDownloads finds downloads that are executed or installed without a checksum or
+// signature verification of the downloaded file. Content may be a single command or a multi-line
+// script. Piping a download straight into a shell is always a risk, since nothing can check what
+// the shell ran; a verification counts only on a line that runs and names the downloaded file, or
+// a checksum list downloaded with it.
+func DetectUnverifiedDownloads(content string) []DownloadRisk {
+	var lines, verifications []string
+	downloaded := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToUpper(line), "REM") {
+			continue
+		}
+		lines = append(lines, line)
+		if verificationPattern.MatchString(line) {
+			verifications = append(verifications, line)
+		}
+		if downloadPattern.MatchString(line) {
+			for _, name := range downloadedFiles(line) {
+				downloaded[name] = true
+			}
+		}
+	}
+
+	var risks []DownloadRisk
+	for _, line := range lines {
+		if pipeToShellPattern.MatchString(line) {
+			risks = append(risks, DownloadRisk{Line: line, Reason: "downloaded content is piped directly into a shell"})
+			continue
+		}
+
+		if downloadPattern.MatchString(line) && installerPattern.MatchString(line) && !verified(downloadedFiles(line), verifications, downloaded) {
+			risks = append(risks, DownloadRisk{Line: line, Reason: "downloaded installer or archive is not verified"})
+		}
+	}
+	return risks
+}
+
+// downloadedFiles returns the names of the files a download line may save: the last part of each
+// URL, and the file given to an output option such as curl -o or wget -O
+func downloadedFiles(line string) []string {
+	var names []string
+	for _, url := range urlPattern.FindAllString(line, -1) {
+		url, _, _ = strings.Cut(url, "?")
+		names = append(names, url)
+	}
+	for _, match := range outputFilePattern.FindAllStringSubmatch(line, -1) {
+		names = append(names, match[1])
+	}
+
+	var files []string
+	for _, name := range names {
+		name = strings.TrimRight(name, "/")
+		name = name[strings.LastIndexAny(name, `/\`)+1:]
+		if name != "" && name != "-" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// verified reports whether one of the verification lines checks one of files, by naming it or by
+// checking a checksum list that was downloaded too
+func verified(files []string, verifications []string, downloaded map[string]bool) bool {
+	for _, line := range verifications {
+		for _, file := range files {
+			if strings.Contains(line, file) {
+				return true
+			}
+		}
+		if checksumListPattern.MatchString(line) {
+			for _, field := range strings.Fields(line) {
+				if downloaded[strings.Trim(field, `"'`)] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/duplicates.go
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxReportedDuplicateGroups bounds the groups of duplicates described to the user and the oracle
+const maxReportedDuplicateGroups = 20
+
+// maxDuplicateWorkers caps the files hashed at once; more rarely helps on a single disk
+const maxDuplicateWorkers = 8
+
+var (
+	duplicatePattern = regexp.MustCompile(`(?i)\b(duplicates?|duplicated|dupes?|dedupe|de-?duplicate|identical\s+(files?|photos?|images?|pictures?|videos?|songs?|documents?))\b`)
+
+	// duplicateActionPattern finds intents that ask for more than a list of the duplicates
+	duplicateActionPattern = regexp.MustCompile(`(?i)\b(delete|remove|rm|move|mv|copy|cp|link|hard\s*link|symlink|replace|archive|trash|clean\s*up|cleanup|purge|prune|get\s+rid\s+of|wipe|dedupe|de-?duplicate|keep|rename|compress)\b`)
+)
+
+// duplicateKinds narrow a scan to the files an intent is about, such as "duplicate photos"
+var duplicateKinds = []struct {
+	words      []string
+	extensions []string
+}{
+	{[]string{"photo", "photos", "image", "images", "picture", "pictures", "pics"}, []string{".jpg", ".jpeg", ".png", ".gif", ".heic", ".heif", ".webp", ".bmp", ".tif", ".tiff", ".raw", ".cr2", ".nef", ".arw", ".dng"}},
+	{[]string{"video", "videos", "movie", "movies", "clips"}, []string{".mp4", ".mov", ".mkv", ".avi", ".wmv", ".webm", ".m4v"}},
+	{[]string{"song", "songs", "music", "audio", "tracks"}, []string{".mp3", ".flac", ".wav", ".m4a", ".aac", ".ogg", ".opus"}},
+	{[]string{"document", "documents", "pdfs"}, []string{".pdf", ".doc", ".docx", ".odt", ".xls", ".xlsx", ".ods", ".ppt", ".pptx", ".txt", ".md"}},
+}
+
+// IsDuplicateIntent reports whether the intent is about duplicate files, so they can be found by
+// a native scan instead of a generated pipeline
+func IsDuplicateIntent(intent string) bool {
+	return duplicatePattern.MatchString(intent)
+}
+
+// IsDuplicateActionIntent reports whether the intent asks to do something with the duplicates
+// rather than only to find them
+func IsDuplicateActionIntent(intent string) bool {
+	return duplicateActionPattern.MatchString(intent)
+}
+
+// IsDuplicateDeletionIntent reports whether the intent asks to delete duplicates, such as
+// "remove the dupes in ~/Downloads"
+func IsDuplicateDeletionIntent(intent string) bool {
+	return IsDuplicateIntent(intent) && cleanupVerbPattern.MatchString(intent)
+}
+
+// DuplicateExtensions returns the file extensions the intent limits a duplicate scan to, or nil
+// to scan every file
+func DuplicateExtensions(intent string) []string {
+	var extensions []string
+	words := intentWords(intent)
+	for _, kind := range duplicateKinds {
+		if slices.ContainsFunc(kind.words, func(word string) bool { return slices.Contains(words, word) }) {
+			extensions = append(extensions, kind.extensions...)
+		}
+	}
+	return extensions
+}
+
+// DuplicateRoot returns the directory the intent asks to scan: one it names, or currentDir
+func DuplicateRoot(intent, currentDir, homeDir string) string {
+	dir := FindRecipeParam(RecipeParam{Name: "folder", Kind: RecipeParamDir}, intent, currentDir, homeDir)
+	if dir == "" {
+		return currentDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(currentDir, dir)
+	}
+	return dir
+}
+
+// DuplicateGroup is a set of files with the same size and content
+type DuplicateGroup struct {
+	Size  int64
+	Paths []string // sorted; the first is the copy kept by default
+}
+
+// DuplicateReport is the result of a duplicate scan
+type DuplicateReport struct {
+	Root    string
+	Scanned int // files considered
+	Hashed  int // files whose size matched another's, so their content was compared
+	Groups  []DuplicateGroup
+}
+
+// Wasted is the space the extra copies take up
+func (r *DuplicateReport) Wasted() int64 {
+	var wasted int64
+	for _, group := range r.Groups {
+		wasted += group.Size * int64(len(group.Paths)-1)
+	}
+	return wasted
+}
+
+// Extras lists every copy but the first of each group
+func (r *DuplicateReport) Extras() []string {
+	var extras []string
+	for _, group := range r.Groups {
+		extras = append(extras, group.Paths[1:]...)
+	}
+	return extras
+}
+
+// Summary describes the scan in one sentence
+func (r *DuplicateReport) Summary() string {
+	if len(r.Groups) == 0 {
+		return fmt.Sprintf("No duplicates among %d files in %s.", r.Scanned, r.Root)
+	}
+	return fmt.Sprintf("%d groups of identical files among %d files in %s; the extra copies take %s.", len(r.Groups), r.Scanned, r.Root, formatFileSize(r.Wasted()))
+}
+
+// Lines lists the largest groups, with paths relative to the root, for display
+func (r *DuplicateReport) Lines() []string {
+	var lines []string
+	for _, group := range r.Groups[:min(len(r.Groups), maxReportedDuplicateGroups)] {
+		lines = append(lines, fmt.Sprintf("%d copies of %s:", len(group.Paths), formatFileSize(group.Size)))
+		for _, path := range group.Paths {
+			lines = append(lines, "  "+r.relative(path))
+		}
+	}
+	if len(r.Groups) > maxReportedDuplicateGroups {
+		lines = append(lines, fmt.Sprintf("and %d more groups", len(r.Groups)-maxReportedDuplicateGroups))
+	}
+	return lines
+}
+
+// String describes the groups with their full paths, for the oracle
+func (r *DuplicateReport) String() string {
+	groups := make([]string, 0, len(r.Groups))
+	for _, group := range r.Groups[:min(len(r.Groups), maxReportedDuplicateGroups)] {
+		groups = append(groups, "- "+strings.Join(group.Paths, " | "))
+	}
+	if len(r.Groups) > maxReportedDuplicateGroups {
+		groups = append(groups, fmt.Sprintf("- and %d more groups", len(r.Groups)-maxReportedDuplicateGroups))
+	}
+	return strings.Join(groups, "\n")
+}
+
+func (r *DuplicateReport) relative(path string) string {
+	if rel, err := filepath.Rel(r.Root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// FindDuplicates scans root for identical files. Files are grouped by size first, so only those
+// that share a size are read, and those are hashed with SHA-256 by several workers at once.
+// Hidden and dependency directories are skipped, as are empty files and files that cannot be read.
+// When extensions is not empty only files with one of them are compared. progress, if not nil, is
+// called from the calling goroutine after each file is hashed.
+func FindDuplicates(root string, extensions []string, progress func(done, total int)) (*DuplicateReport, error) {
+	report := &DuplicateReport{Root: root}
+	bySize := make(map[int64][]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || skippedSearchDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if len(extensions) > 0 && !slices.Contains(extensions, strings.ToLower(filepath.Ext(name))) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() == 0 {
+			return nil
+		}
+		report.Scanned++
+		bySize[info.Size()] = append(bySize[info.Size()], path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, paths := range bySize {
+		if len(paths) > 1 {
+			candidates = append(candidates, paths...)
+		}
+	}
+	report.Hashed = len(candidates)
+	sums := hashFiles(candidates, progress)
+
+	type key struct {
+		size int64
+		sum  string
+	}
+	byContent := make(map[key][]string)
+	for size, paths := range bySize {
+		for _, path := range paths {
+			if sum, ok := sums[path]; ok {
+				byContent[key{size, sum}] = append(byContent[key{size, sum}], path)
+			}
+		}
+	}
+	for k, paths := range byContent {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			report.Groups = append(report.Groups, DuplicateGroup{Size: k.size, Paths: paths})
+		}
+	}
+	// The groups wasting the most space first
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		wastedA, wastedB := a.Size*int64(len(a.Paths)-1), b.Size*int64(len(b.Paths)-1)
+		if wastedA != wastedB {
+			return wastedA > wastedB
+		}
+		return a.Paths[0] < b.Paths[0]
+	})
+	return report, nil
+}
+
+// hashFiles returns the SHA-256 of each readable file, hashing several at once
+func hashFiles(paths []string, progress func(done, total int)) map[string]string {
+	type result struct {
+		path, sum string
+		err       error
+	}
+	jobs := make(chan string)
+	results := make(chan result)
+	workers := min(runtime.NumCPU(), maxDuplicateWorkers, max(len(paths), 1))
+	for range workers {
+		go func() {
+			for path := range jobs {
+				sum, err := hashFile(path)
+				results <- result{path, sum, err}
+			}
+		}()
+	}
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+	}()
+
+	sums := make(map[string]string, len(paths))
+	for done := 1; done <= len(paths); done++ {
+		r := <-results
+		if r.err == nil {
+			sums[r.path] = r.sum
+		}
+		if progress != nil {
+			progress(done, len(paths))
+		}
+	}
+	return sums
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// formatFileSize shows a size in the largest binary unit that keeps it at least one
+func formatFileSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size, exponent := float64(bytes)/unit, 0
+	for size >= unit && exponent < 3 {
+		size /= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", size, "KMGT"[exponent])
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/elevation.go
+package system
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Windows commands that fail with "access denied" unless run as Administrator
+var windowsAdminPattern = regexp.MustCompile(`(?i)` + strings.Join([]string{
+	// services
+	`\bsc(\.exe)?\s+(start|stop|config|create|delete|failure)\b`,
+	`\bnet\s+(start|stop|user|localgroup)\b`,
+	`\b(start|stop|restart|set|new|remove|suspend|resume)-service\b`,
+	// machine-wide registry
+	`\breg(\.exe)?\s+(add|delete|import|restore)\s+"?(hklm|hkey_local_machine)`,
+	`\b(set|new|remove)-item(property)?\b[^|;]*\bhklm:`,
+	// installs and system features
+	`\bchoco(latey)?\s+(install|upgrade|uninstall)\b`,
+	`\bmsiexec(\.exe)?\s+/[ix]\b`,
+	`\b(install|uninstall)-windowsfeature\b`,
+	`\b(enable|disable)-windowsoptionalfeature\b`,
+	`\bdism(\.exe)?\s`,
+	`\bwinget\s+install\b[^|;]*--scope\s+machine`,
+	// system configuration
+	`\bbcdedit\b`,
+	`\bnetsh\s+(advfirewall|interface|winsock)\b`,
+	`\bset-executionpolicy\b(?:[^|;]*-scope\s+localmachine|[^|;-]*$)`,
+	`\b(add|set|remove)-mppreference\b`,
+	`\bnew-netfirewallrule\b`,
+}, "|"))
+
+// RequiresAdministrator reports whether a Windows command needs an elevated (Administrator) process
+func RequiresAdministrator(command string) bool {
+	return windowsAdminPattern.MatchString(command)
+}
+
+// EncodePowerShellCommand encodes a script for powershell -EncodedCommand (base64 of UTF-16LE),
+// which avoids every quoting problem when handing a command to another process
+func EncodePowerShellCommand(script string) string {
+	codes := utf16.Encode([]rune(script))
+	buf := make([]byte, len(codes)*2)
+	for i, code := range codes {
+		binary.LittleEndian.PutUint16(buf[i*2:], code)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// ElevatedPowerShellScript builds the script run inside the elevated window. It runs the command
+// through the user's shell, keeps the window open so the output can be read, and exits with the
+// command's status.
+func ElevatedPowerShellScript(command, shell string) string {
+	run := command
+	if ShellFamily(shell) == ShellFamilyCmd {
+		run = fmt.Sprintf("& cmd.exe /C @'\n%s\n'@", command)
+	}
+
+	return fmt.Sprintf(`%s
+$code = if ($?) { 0 } elseif ($LASTEXITCODE) { $LASTEXITCODE } else { 1 }
+Write-Host ''
+Read-Host 'Quest finished. Press Enter to close this window' | Out-Null
+exit $code`, run)
+}
+
+// ElevationLauncherScript builds the script that asks UAC for an elevated PowerShell running
+// the encoded inner script, waits for it, and passes on its exit code
+func ElevationLauncherScript(command, shell string) string {
+	encoded := EncodePowerShellCommand(ElevatedPowerShellScript(command, shell))
+	return fmt.Sprintf(`$p = Start-Process -FilePath powershell.exe -Verb RunAs -Wait -PassThru -ArgumentList '-NoProfile','-EncodedCommand','%s'; exit $p.ExitCode`, encoded)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/encoding.go
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// CodePageUTF8 is the Windows code page of UTF-8
+const CodePageUTF8 = 65001
+
+// consoleLineSize is the longest line decoded at once; longer lines are decoded in parts
+const consoleLineSize = 8 << 10
+
+// codePageEncodings maps the Windows code pages consoles commonly use to their encodings
+var codePageEncodings = map[uint32]encoding.Encoding{
+	437:   charmap.CodePage437,
+	850:   charmap.CodePage850,
+	852:   charmap.CodePage852,
+	855:   charmap.CodePage855,
+	858:   charmap.CodePage858,
+	860:   charmap.CodePage860,
+	862:   charmap.CodePage862,
+	863:   charmap.CodePage863,
+	865:   charmap.CodePage865,
+	866:   charmap.CodePage866,
+	874:   charmap.Windows874,
+	932:   japanese.ShiftJIS,
+	936:   simplifiedchinese.GBK,
+	949:   korean.EUCKR,
+	950:   traditionalchinese.Big5,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	1253:  charmap.Windows1253,
+	1254:  charmap.Windows1254,
+	1255:  charmap.Windows1255,
+	1256:  charmap.Windows1256,
+	1257:  charmap.Windows1257,
+	1258:  charmap.Windows1258,
+	20866: charmap.KOI8R,
+	21866: charmap.KOI8U,
+	28591: charmap.ISO8859_1,
+	28592: charmap.ISO8859_2,
+	28605: charmap.ISO8859_15,
+}
+
+// CodePageEncoding returns the encoding of a Windows code page, or nil for UTF-8 and code pages
+// that are not known
+func CodePageEncoding(codePage uint32) encoding.Encoding {
+	return codePageEncodings[codePage]
+}
+
+// NewConsoleDecoder transcodes the output of a child process to UTF-8, line by line. Lines that
+// are already valid UTF-8 pass through untouched, since many programs write UTF-8 whatever the
+// console's code page; the others are decoded from enc. A nil enc returns r itself.
+func NewConsoleDecoder(r io.Reader, enc encoding.Encoding) io.Reader {
+	if enc == nil {
+		return r
+	}
+	return &consoleDecoder{reader: bufio.NewReaderSize(r, consoleLineSize), decoder: enc.NewDecoder()}
+}
+
+type consoleDecoder struct {
+	reader  *bufio.Reader
+	decoder *encoding.Decoder
+	pending []byte // decoded output not yet read
+	err     error
+}
+
+func (d *consoleDecoder) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		line, err := d.reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			err = nil
+		}
+		d.pending, d.err = DecodeConsoleLine(line, d.decoder), err
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+// DecodeConsoleLine returns the line as UTF-8, decoding it with the decoder unless it already is
+func DecodeConsoleLine(line []byte, decoder *encoding.Decoder) []byte {
+	if utf8.Valid(line) {
+		return bytes.Clone(line)
+	}
+	decoded, err := decoder.Bytes(line)
+	if err != nil {
+		return bytes.ToValidUTF8(line, []byte("�"))
+	}
+	return decoded
+}
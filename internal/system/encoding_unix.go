@@ -0,0 +1,14 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+// PrepareConsole does nothing on Unix, where terminals take UTF-8 from the locale
+func PrepareConsole() (restore func()) {
+	return func() {}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/text/encoding"
+)
+
+var procGetOEMCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetOEMCP")
+
+// consoleEncoding is the encoding child processes wrote in before the console was switched to
+// UTF-8; programs that ignore the switch keep writing in it
+var consoleEncoding encoding.Encoding
+
+// PrepareConsole switches the console to UTF-8 so that emoji and box drawing render, and returns
+// a function that restores the original code pages. The code pages belong to the console window,
+// which outlives the knight, so the restore must run before exiting.
+func PrepareConsole() (restore func()) {
+	outputCP, err := windows.GetConsoleOutputCP()
+	if err != nil || outputCP == 0 {
+		// No console, e.g. when the output is redirected; children write in the OEM code page
+		oem, _, _ := procGetOEMCP.Call()
+		consoleEncoding = CodePageEncoding(uint32(oem))
+		return func() {}
+	}
+	consoleEncoding = CodePageEncoding(outputCP)
+	if outputCP == CodePageUTF8 {
+		return func() {}
+	}
+
+	inputCP, _ := windows.GetConsoleCP()
+	if err := windows.SetConsoleOutputCP(CodePageUTF8); err != nil {
+		return func() {}
+	}
+	_ = windows.SetConsoleCP(CodePageUTF8)
+	return func() {
+		_ = windows.SetConsoleOutputCP(outputCP)
+		if inputCP != 0 {
+			_ = windows.SetConsoleCP(inputCP)
+		}
+	}
+}
+
+// decodeConsoleOutput transcodes a child's output stream to UTF-8
+func decodeConsoleOutput(r io.Reader) io.Reader {
+	return NewConsoleDecoder(r, consoleEncoding)
+}
@@ -49,11 +49,42 @@ func (ev *EnvironmentValidator) detectEnvironmentCommand(command string) string
 	// Remove leading sudo, && chains, and pipes for core command analysis
 	coreCmd := ev.extractCoreCommand(lowerCmd)
 
+	for _, check := range ev.checksForShell() {
+		if check.detector(coreCmd, command) {
+			return check.name
+		}
+	}
+
+	return ""
+}
+
+// envCheck pairs an environment command type with its detector
+type envCheck struct {
+	name     string
+	detector func(coreCmd, fullCmd string) bool
+}
+
+// checksForShell returns the detectors that apply to the user's shell, so that builtins of one
+// shell are not flagged (or missed) when another shell runs the command
+func (ev *EnvironmentValidator) checksForShell() []envCheck {
+	shell := ""
+	if ev.sysInfo != nil {
+		shell = ev.sysInfo.Shell
+	}
+
+	switch ShellFamily(shell) {
+	case ShellFamilyFish:
+		return ev.fishChecks()
+	case ShellFamilyPowerShell:
+		return ev.powerShellChecks()
+	case ShellFamilyNushell:
+		return ev.nushellChecks()
+	case ShellFamilyCmd:
+		return ev.cmdChecks()
+	}
+
 	// Check for different types of environment-affecting commands
-	checks := []struct {
-		name     string
-		detector func(string, string) bool
-	}{
+	return []envCheck{
 		{"path_modification", ev.detectPathModification}, // Check path_modification before export
 		{"conda_env", ev.detectCondaEnvironment},         // Check conda before virtual_env
 		{"source", ev.detectSourceCommand},
@@ -67,14 +98,6 @@ func (ev *EnvironmentValidator) detectEnvironmentCommand(command string) string
 		{"docker_env", ev.detectDockerEnvironment},
 		{"rbenv_pyenv", ev.detectVersionManagers},
 	}
-
-	for _, check := range checks {
-		if check.detector(coreCmd, command) {
-			return check.name
-		}
-	}
-
-	return ""
 }
 
 // extractCoreCommand removes common prefixes and extracts the main command
@@ -130,6 +153,9 @@ func (ev *EnvironmentValidator) looksLikeEnvironmentCommand(command string) bool
 	envKeywords := []string{
 		"export", "source", ".", "cd", "alias", "unalias", "conda", "activate",
 		"deactivate", "nvm", "rbenv", "pyenv", "set", "unset", "module", "ml",
+		// fish, PowerShell, nushell and cmd builtins
+		"abbr", "funcsave", "function", "set-location", "set-alias", "set-item", "import-module",
+		"let-env", "load-env", "source-env", "def", "def-env", "doskey", "chdir", "pushd", "popd",
 	}
 
 	for _, keyword := range envKeywords {
@@ -138,6 +164,11 @@ func (ev *EnvironmentValidator) looksLikeEnvironmentCommand(command string) bool
 		}
 	}
 
+	// PowerShell ($env:NAME) and nushell ($env.NAME) environment variables
+	if strings.HasPrefix(command, "$env:") || strings.HasPrefix(command, "$env.") {
+		return true
+	}
+
 	// Check for variable assignments
 	if strings.Contains(command, "=") && !strings.Contains(command, "==") {
 		parts := strings.Split(command, "=")
@@ -347,6 +378,7 @@ func (ev *EnvironmentValidator) detectPathModification(coreCmd, fullCmd string)
 		">> ~/.bash_profile",
 		">> $HOME/.bashrc",
 		">> $HOME/.zshrc",
+		">> ~/.config/fish/config.fish",
 	}
 
 	for _, pattern := range pathModPatterns {
@@ -459,7 +491,7 @@ func (ev *EnvironmentValidator) looksLikeSourceableFile(filename string) bool {
 		".bashrc", ".zshrc", ".profile", ".bash_profile",
 		".env", ".envrc",
 		"activate", // virtualenv activation
-		".sh", ".bash", ".zsh",
+		".sh", ".bash", ".zsh", ".fish",
 	}
 
 	filename = strings.ToLower(filename)
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/env_validator_shells.go
+package system
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Detectors for shells outside the POSIX family. Commands reach these already lowercased (coreCmd)
+// and with their original case (fullCmd), exactly like the POSIX detectors in env_validator.go.
+
+var (
+	// fish: set without a query/listing flag changes the session; -U (universal) persists on its own
+	fishSetPattern      = regexp.MustCompile(`^set\s+`)
+	fishSetQueryPattern = regexp.MustCompile(`^set\s+(-\w*[qnsu]\w*|--(query|names|show|universal))\b`)
+
+	psEnvAssignPattern       = regexp.MustCompile(`^\$env:[a-z_][a-z0-9_]*\s*[+]?=`)
+	psEnvItemPattern         = regexp.MustCompile(`^(set-item|new-item|remove-item|ni|si|ri|del)\s+(-path\s+)?env:`)
+	psPreferencePattern      = regexp.MustCompile(`^\$[a-z]*preference\s*=`)
+	psDotSourcePattern       = regexp.MustCompile(`^\.\s+\S+`)
+	psPersistentScopePattern = regexp.MustCompile(`setenvironmentvariable\([^)]*['"]?(user|machine)['"]?\s*\)`)
+
+	nuEnvAssignPattern = regexp.MustCompile(`^\$env\.[a-z_][a-z0-9_]*\s*=`)
+
+	cmdDriveSwitchPattern = regexp.MustCompile(`^[a-z]:$`)
+)
+
+// hasAnyPrefix reports whether the command starts with one of the prefixes, or is exactly one of them
+// without its trailing space (so "cd " matches a bare "cd" but not "cdk")
+func hasAnyPrefix(command string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(command, prefix) || command == strings.TrimSpace(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fish
+
+func (ev *EnvironmentValidator) fishChecks() []envCheck {
+	return []envCheck{
+		{"path_modification", ev.detectPathModification},
+		{"conda_env", ev.detectCondaEnvironment},
+		{"source", ev.detectSourceCommand},
+		{"export", ev.detectFishVariable},
+		{"alias", ev.detectFishAlias},
+		{"cd", ev.detectFishCd},
+		{"virtual_env", ev.detectVirtualEnvCommand},
+		{"shell_function", ev.detectFishFunction},
+		{"shell_options", ev.detectResourceLimits},
+		{"rbenv_pyenv", ev.detectVersionManagers},
+	}
+}
+
+func (ev *EnvironmentValidator) detectFishVariable(coreCmd, fullCmd string) bool {
+	return fishSetPattern.MatchString(coreCmd) && !fishSetQueryPattern.MatchString(coreCmd)
+}
+
+func (ev *EnvironmentValidator) detectFishAlias(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "alias ", "abbr ")
+}
+
+func (ev *EnvironmentValidator) detectFishCd(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "cd ", "pushd ", "popd ", "prevd ", "nextd ")
+}
+
+func (ev *EnvironmentValidator) detectFishFunction(coreCmd, fullCmd string) bool {
+	// funcsave only works on functions defined in the current session
+	return hasAnyPrefix(coreCmd, "function ", "funcsave ", "functions -e", "functions --erase")
+}
+
+// detectResourceLimits covers builtins that only change limits of the current shell process
+func (ev *EnvironmentValidator) detectResourceLimits(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "ulimit ", "umask ")
+}
+
+// PowerShell
+
+func (ev *EnvironmentValidator) powerShellChecks() []envCheck {
+	return []envCheck{
+		{"conda_env", ev.detectCondaEnvironment},
+		{"source", ev.detectPowerShellDotSource},
+		{"export", ev.detectPowerShellEnv},
+		{"alias", ev.detectPowerShellAlias},
+		{"cd", ev.detectPowerShellLocation},
+		{"virtual_env", ev.detectVirtualEnvCommand},
+		{"shell_function", ev.detectPowerShellFunction},
+		{"environment_module", ev.detectPowerShellModule},
+		{"shell_options", ev.detectPowerShellPreferences},
+	}
+}
+
+func (ev *EnvironmentValidator) detectPowerShellEnv(coreCmd, fullCmd string) bool {
+	if psEnvAssignPattern.MatchString(coreCmd) || psEnvItemPattern.MatchString(coreCmd) {
+		return true
+	}
+
+	// Process-scoped variables vanish with the subshell; User and Machine scopes persist
+	return strings.Contains(coreCmd, "[environment]::setenvironmentvariable(") && !psPersistentScopePattern.MatchString(coreCmd)
+}
+
+func (ev *EnvironmentValidator) detectPowerShellDotSource(coreCmd, fullCmd string) bool {
+	return psDotSourcePattern.MatchString(coreCmd)
+}
+
+func (ev *EnvironmentValidator) detectPowerShellAlias(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "set-alias ", "new-alias ", "sal ", "nal ")
+}
+
+func (ev *EnvironmentValidator) detectPowerShellLocation(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "cd ", "chdir ", "set-location ", "sl ", "push-location ", "pushd ", "pop-location ", "popd ")
+}
+
+func (ev *EnvironmentValidator) detectPowerShellFunction(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "function ", "filter ")
+}
+
+func (ev *EnvironmentValidator) detectPowerShellModule(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "import-module ", "ipmo ", "remove-module ", "rmo ")
+}
+
+func (ev *EnvironmentValidator) detectPowerShellPreferences(coreCmd, fullCmd string) bool {
+	return psPreferencePattern.MatchString(coreCmd) || hasAnyPrefix(coreCmd, "set-strictmode ", "set-psdebug ")
+}
+
+// nushell
+
+func (ev *EnvironmentValidator) nushellChecks() []envCheck {
+	return []envCheck{
+		{"conda_env", ev.detectCondaEnvironment},
+		{"source", ev.detectNushellSource},
+		{"export", ev.detectNushellEnv},
+		{"alias", ev.detectNushellAlias},
+		{"cd", ev.detectNushellCd},
+		{"virtual_env", ev.detectVirtualEnvCommand},
+		{"shell_function", ev.detectNushellDef},
+	}
+}
+
+func (ev *EnvironmentValidator) detectNushellEnv(coreCmd, fullCmd string) bool {
+	return nuEnvAssignPattern.MatchString(coreCmd) || hasAnyPrefix(coreCmd, "let-env ", "load-env ", "hide-env ")
+}
+
+func (ev *EnvironmentValidator) detectNushellSource(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "source ", "source-env ", "use ", "overlay use ")
+}
+
+func (ev *EnvironmentValidator) detectNushellAlias(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "alias ")
+}
+
+func (ev *EnvironmentValidator) detectNushellCd(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "cd ", "enter ", "dexit ")
+}
+
+func (ev *EnvironmentValidator) detectNushellDef(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "def ", "def-env ")
+}
+
+// cmd.exe
+
+func (ev *EnvironmentValidator) cmdChecks() []envCheck {
+	return []envCheck{
+		{"conda_env", ev.detectCondaEnvironment},
+		{"export", ev.detectCmdSet},
+		{"alias", ev.detectCmdDoskey},
+		{"cd", ev.detectCmdCd},
+		{"virtual_env", ev.detectVirtualEnvCommand},
+	}
+}
+
+func (ev *EnvironmentValidator) detectCmdSet(coreCmd, fullCmd string) bool {
+	// setx writes the registry and persists, so only set and path are flagged
+	if strings.HasPrefix(coreCmd, "set /?") {
+		return false
+	}
+	return hasAnyPrefix(coreCmd, "set ", "path ", "path=")
+}
+
+func (ev *EnvironmentValidator) detectCmdDoskey(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "doskey ")
+}
+
+func (ev *EnvironmentValidator) detectCmdCd(coreCmd, fullCmd string) bool {
+	return hasAnyPrefix(coreCmd, "cd ", "cd/", "chdir ", "pushd ", "popd ") || cmdDriveSwitchPattern.MatchString(coreCmd)
+}
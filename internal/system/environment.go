@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/environment.go
+package system
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithEnvironment adds the variables to the environment of every command and script the
+// executor runs, replacing any inherited value. Executors other than the system's own, such as
+// test doubles, are returned unchanged.
+func WithEnvironment(executor CommandExecutor, env map[string]string) CommandExecutor {
+	if e, ok := executor.(*Executor); ok && len(env) > 0 {
+		e.env = env
+	}
+	return executor
+}
+
+// MergeEnvironment returns base ("NAME=value" entries) with the extra variables set, in a
+// stable order
+func MergeEnvironment(base []string, extra map[string]string) []string {
+	var env []string
+	for _, variable := range base {
+		name, _, _ := strings.Cut(variable, "=")
+		if _, replaced := extra[name]; !replaced {
+			env = append(env, variable)
+		}
+	}
+	for _, name := range sortedNames(extra) {
+		env = append(env, name+"="+extra[name])
+	}
+	return env
+}
+
+// EnvironmentPrefix builds a statement that sets the variables in the given shell before the
+// command that follows it, for processes that do not inherit the knight's environment
+func EnvironmentPrefix(shell string, env map[string]string) string {
+	if len(env) == 0 {
+		return ""
+	}
+
+	var assignments []string
+	for _, name := range sortedNames(env) {
+		switch ShellFamily(shell) {
+		case ShellFamilyPowerShell:
+			assignments = append(assignments, "$env:"+name+" = '"+strings.ReplaceAll(env[name], "'", "''")+"'")
+		case ShellFamilyCmd:
+			assignments = append(assignments, `set "`+name+"="+env[name]+`"`)
+		default:
+			assignments = append(assignments, "export "+name+"="+shellQuote(env[name]))
+		}
+	}
+
+	if ShellFamily(shell) == ShellFamilyCmd {
+		return strings.Join(assignments, " && ") + " && "
+	}
+	return strings.Join(assignments, "; ") + "; "
+}
+
+func sortedNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/errors.go
+package system
+
+import (
+	"errors"
+	"strings"
+)
+
+// The kinds of errors this package returns, told apart with errors.Is. The typed errors
+// (EnvironmentCommandError, MissingPathError, ExecutionError) carry the details of each.
+var (
+	// ErrUnsafeCommand is a proposal a safety check refuses to run as it stands
+	ErrUnsafeCommand = errors.New("the command is unsafe to run")
+	// ErrPermissionDenied is a command that failed for lack of permission
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrCommandNotFound is a command that failed because a program it runs is not installed
+	ErrCommandNotFound = errors.New("command not found")
+	// ErrPathNotFound is a path that does not exist, named in an intent or by a failed command
+	ErrPathNotFound = errors.New("no such file or directory")
+	// ErrNotAuthenticated is a user the operating system could not confirm when asked to
+	ErrNotAuthenticated = errors.New("not authenticated")
+)
+
+// kindError gives an error one of the kinds above while keeping its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// failureSigns are what shells and programs print for each kind of failure, lowercased, on
+// Unix and Windows
+var failureSigns = map[error][]string{
+	ErrPermissionDenied: {"permission denied", "operation not permitted", "access is denied", "unauthorizedaccess"},
+	ErrCommandNotFound:  {"command not found", "is not recognized as an internal or external command", "is not recognized as the name of a cmdlet", "unknown command"},
+	ErrPathNotFound:     {"no such file or directory", "cannot find the path", "cannot find the file", "cannot find path"},
+}
+
+// failureExitCodes are the exit codes POSIX shells use for a kind of failure
+var failureExitCodes = map[error]int{
+	ErrPermissionDenied: 126,
+	ErrCommandNotFound:  127,
+}
+
+// Is classifies a failed execution by its exit code and by what it printed
+func (e *ExecutionError) Is(target error) bool {
+	signs, ok := failureSigns[target]
+	if !ok {
+		return false
+	}
+	if code, ok := failureExitCodes[target]; ok && e.ExitCode == code {
+		return true
+	}
+	failure := strings.ToLower(e.Err.Error() + "\n" + e.Output)
+	for _, sign := range signs {
+		if strings.Contains(failure, sign) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is makes environment commands unsafe: run in a subshell, they would not do what was asked
+func (e *EnvironmentCommandError) Is(target error) bool {
+	return target == ErrUnsafeCommand
+}
+
+// Is makes a missing path in an intent ErrPathNotFound
+func (e *MissingPathError) Is(target error) bool {
+	return target == ErrPathNotFound
+}
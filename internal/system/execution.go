@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/execution.go
+package system
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// ExecutionError describes a failed command or script together with what it printed
+type ExecutionError struct {
+	Err      error
+	ExitCode int    // -1 when the process did not report an exit code
+	Output   string // the trailing lines of combined stdout/stderr
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// ExecutionOutput returns the captured output tail of a failed execution, if any
+func ExecutionOutput(err error) string {
+	var execErr *ExecutionError
+	if errors.As(err, &execErr) {
+		return execErr.Output
+	}
+	return ""
+}
+
+// wrapExecutionError attaches the exit code and output tail to an execution failure
+func wrapExecutionError(err error, highlighter *ui.OutputHighlighter) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExecutionError{
+		Err:      err,
+		ExitCode: exitCodeOf(err),
+		Output:   strings.Join(highlighter.Tail(), "\n"),
+	}
+}
+
+// WithShellBinary runs every command and script with the binary at path in place of the shell the
+// quest names. The binary receives the same arguments the real shell would, which lets tests run
+// the executor against a fake shell. Executors other than the system's own are returned unchanged.
+func WithShellBinary(executor CommandExecutor, path string) CommandExecutor {
+	if e, ok := executor.(*Executor); ok {
+		e.shellBinary = path
+	}
+	return executor
+}
+
+// WithMaxLineSize prints at most size bytes of every output line of the executor, skipping the
+// rest of a longer one. Zero keeps ui.DefaultMaxLineSize. Executors other than the system's own
+// are returned unchanged.
+func WithMaxLineSize(executor CommandExecutor, size int) CommandExecutor {
+	if e, ok := executor.(*Executor); ok {
+		e.maxLineSize = size
+	}
+	return executor
+}
+
+// newHighlighter creates the highlighter an execution's output streams through
+func (e *Executor) newHighlighter(timestamps bool) *ui.OutputHighlighter {
+	highlighter := ui.NewOutputHighlighter(timestamps, 1)
+	highlighter.SetMaxLineSize(e.maxLineSize)
+	return highlighter
+}
+
+// shellPath returns the binary that runs the named shell
+func (e *Executor) shellPath(shell string) string {
+	if e.shellBinary != "" {
+		return e.shellBinary
+	}
+	return shell
+}
+
+// outputPrefix labels output lines when several executors share the terminal
+func (e *Executor) outputPrefix() string {
+	if e.label == "" {
+		return ""
+	}
+	return ui.HighlightText("["+e.label+"]") + " "
+}
+
+// exitCodeOf returns the process exit code carried by err, or -1 when there is none
+func exitCodeOf(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
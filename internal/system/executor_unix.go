@@ -13,7 +13,10 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
+	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -21,18 +24,84 @@ import (
 	"github.com/minand-mohan/execute-my-will/internal/ui"
 )
 
-type Executor struct{}
+// Executor runs commands in a shell, streaming their highlighted output
+type Executor struct {
+	dir        string // working directory; empty means the current directory
+	label      string // printed before every output line
+	background bool   // no terminal input or foreground process group, so several can run at once
+
+	runAs      *user.User          // run every command as this user instead of the knight's own
+	sudo       bool                // switch users through sudo -u because the knight is not root
+	credential *syscall.Credential // the user's ids when the knight is root and switches directly
+
+	env         map[string]string // added to the environment of every command, see WithEnvironment
+	waitHelper  string            // the binary that emw-wait expands to, see WithWaitHelper
+	shellBinary string            // runs in place of the named shell, see WithShellBinary
+	maxLineSize int               // bytes of a line printed before the rest is skipped, see WithMaxLineSize
+
+	logDir     string     // where the output of every run is saved, see WithOutputLog
+	lastOutput *OutputLog // the saved output of the most recent run
+
+	session *ShellSession // the long-lived shell every command runs in, see NewSessionExecutor
+}
 
 // NewExecutor creates a new executor instance
 func NewExecutor() CommandExecutor {
 	return &Executor{}
 }
 
+// NewProjectExecutor creates an executor that runs in dir and labels every output line.
+// Background executors do not take over the terminal, so several of them can run at once.
+func NewProjectExecutor(dir, label string, background bool) CommandExecutor {
+	return &Executor{dir: dir, label: label, background: background}
+}
+
+// NewUserExecutor creates an executor that runs every command as another, usually less
+// privileged, user. When the knight runs as root it switches to the user directly;
+// otherwise the commands go through sudo -u.
+func NewUserExecutor(name string) (CommandExecutor, error) {
+	account, err := user.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot run as '%s': %w", name, err)
+	}
+	e := &Executor{runAs: account}
+
+	if os.Geteuid() != 0 {
+		if _, err := exec.LookPath("sudo"); err != nil {
+			return nil, fmt.Errorf("running as '%s' needs root or sudo, and sudo was not found", name)
+		}
+		e.sudo = true
+		return e, nil
+	}
+
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot run as '%s': invalid user id %s", name, account.Uid)
+	}
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot run as '%s': invalid group id %s", name, account.Gid)
+	}
+	e.credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+
+	groupIDs, _ := account.GroupIds()
+	for _, id := range groupIDs {
+		if group, err := strconv.ParseUint(id, 10, 32); err == nil {
+			e.credential.Groups = append(e.credential.Groups, uint32(group))
+		}
+	}
+	return e, nil
+}
+
 // Execute runs the command with enhanced real-time output display
 func (e *Executor) Execute(command string, shell string) error {
 	ui.PrintExecutionHeader(fmt.Sprintf("Executing thy will, my lord:\n%s", command))
+	command = ExpandWaitHelper(command, shell, e.waitHelper)
+	if e.session != nil {
+		return e.runInSession(command, false)
+	}
 
-	cmd := exec.Command(shell, "-c", command)
+	cmd := e.shellCommand(shell, "-c", command)
 
 	// Create pipes to capture output for highlighting while still showing real-time
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -45,31 +114,28 @@ func (e *Executor) Execute(command string, shell string) error {
 		return fmt.Errorf("failed to create stderr pipe: %v", err)
 	}
 
-	cmd.Stdin = os.Stdin
+	e.configure(cmd)
 
-	// Ensure the command runs in the foreground
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Foreground: true,
-		Pgid:       0,
-	}
+	// Create output highlighter
+	highlighter := e.newHighlighter(false)
+	logFile := e.startOutputLog(highlighter)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		e.finishOutputLog(logFile, highlighter)
 		return err
 	}
-
-	// Create output highlighter
-	highlighter := ui.NewOutputHighlighter(false, 1)
+	stopForwarding := forwardSignals(cmd)
 
 	// Stream stdout and stderr concurrently
 	done := make(chan error, 2)
 
 	go func() {
-		done <- highlighter.StreamOutput(stdoutPipe, "")
+		done <- highlighter.StreamOutput(stdoutPipe, e.outputPrefix())
 	}()
 
 	go func() {
-		done <- highlighter.StreamOutput(stderrPipe, "")
+		done <- highlighter.StreamOutput(stderrPipe, e.outputPrefix())
 	}()
 
 	// Wait for both streams to complete
@@ -81,50 +147,61 @@ func (e *Executor) Execute(command string, shell string) error {
 
 	// Wait for command to complete
 	err = cmd.Wait()
+	stopForwarding()
+	e.finishOutputLog(logFile, highlighter)
 
 	ui.PrintSeparator()
 
-	if err != nil {
-		return err
-	}
-	return nil
+	return wrapExecutionError(err, highlighter)
 }
 
 // ExecuteScript runs a script with enhanced real-time output and comment display
 func (e *Executor) ExecuteScript(scriptContent string, shell string, showComments bool) error {
-	// Create temp directory
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return fmt.Errorf("failed to get config directory: %v", err)
-	}
+	scriptContent = ExpandWaitHelper(scriptContent, shell, e.waitHelper)
+	scriptContent = NormalizeScript(scriptContent, shell)
 
-	tmpDir := filepath.Join(configDir, "execute-my-will", "tmp")
-	if err := os.MkdirAll(tmpDir, 0755); err != nil {
-		return fmt.Errorf("failed to create tmp directory: %v", err)
+	// Create executable script with enhanced output
+	scriptWithExecutor := e.createExecutableScriptWithOutput(scriptContent, showComments)
+	if e.session != nil {
+		ui.PrintExecutionHeader("Executing thy script, my lord")
+		return e.runScriptInSession(scriptWithExecutor)
 	}
 
-	// Generate script filename with timestamp
-	timestamp := time.Now().Format("20060102_150405")
-	scriptPath := filepath.Join(tmpDir, fmt.Sprintf("script_%s.sh", timestamp))
+	var cmd *exec.Cmd
+	if e.runAs != nil {
+		// The knight's temp directory is private to the knight, so the script is passed inline
+		cmd = e.shellCommand(shell, "-c", scriptWithExecutor)
+	} else {
+		// Create temp directory
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return fmt.Errorf("failed to get config directory: %v", err)
+		}
 
-	// Create executable script with enhanced output
-	scriptWithExecutor := e.createExecutableScriptWithOutput(scriptContent, showComments)
+		tmpDir := filepath.Join(configDir, "execute-my-will", "tmp")
+		if err := os.MkdirAll(tmpDir, 0755); err != nil {
+			return fmt.Errorf("failed to create tmp directory: %v", err)
+		}
 
-	if err := ioutil.WriteFile(scriptPath, []byte(scriptWithExecutor), 0755); err != nil {
-		return fmt.Errorf("failed to write script file: %v", err)
-	}
+		// Generate script filename with timestamp
+		timestamp := time.Now().Format("20060102_150405.000000000")
+		scriptPath := filepath.Join(tmpDir, fmt.Sprintf("script_%s.sh", timestamp))
 
-	// Clean up script file after execution
-	defer func() {
-		os.Remove(scriptPath)
-		// Clean up old script files (older than 1 hour)
-		e.cleanupOldScripts(tmpDir)
-	}()
+		if err := ioutil.WriteFile(scriptPath, []byte(scriptWithExecutor), 0755); err != nil {
+			return fmt.Errorf("failed to write script file: %v", err)
+		}
 
-	ui.PrintExecutionHeader("Executing thy script, my lord")
+		// Clean up script file after execution
+		defer func() {
+			os.Remove(scriptPath)
+			// Clean up old script files (older than 1 hour)
+			e.cleanupOldScripts(tmpDir)
+		}()
+
+		cmd = exec.Command(e.shellPath(shell), scriptPath)
+	}
 
-	// Execute the script with enhanced output capture
-	cmd := exec.Command(shell, scriptPath)
+	ui.PrintExecutionHeader("Executing thy script, my lord")
 
 	// Create pipes for output capture
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -137,30 +214,28 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 		return fmt.Errorf("failed to create stderr pipe: %v", err)
 	}
 
-	cmd.Stdin = os.Stdin
+	e.configure(cmd)
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Foreground: true,
-		Pgid:       0,
-	}
+	// Create output highlighter with timestamps for scripts
+	highlighter := e.newHighlighter(true)
+	logFile := e.startOutputLog(highlighter)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		e.finishOutputLog(logFile, highlighter)
 		return err
 	}
-
-	// Create output highlighter with timestamps for scripts
-	highlighter := ui.NewOutputHighlighter(true, 1)
+	stopForwarding := forwardSignals(cmd)
 
 	// Stream outputs concurrently
 	done := make(chan error, 2)
 
 	go func() {
-		done <- highlighter.StreamOutput(stdoutPipe, "")
+		done <- highlighter.StreamOutput(stdoutPipe, e.outputPrefix())
 	}()
 
 	go func() {
-		done <- highlighter.StreamOutput(stderrPipe, "")
+		done <- highlighter.StreamOutput(stderrPipe, e.outputPrefix())
 	}()
 
 	// Wait for both streams
@@ -172,10 +247,82 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 
 	// Wait for command completion
 	err = cmd.Wait()
+	stopForwarding()
+	e.finishOutputLog(logFile, highlighter)
 
 	ui.PrintSeparator()
 
-	return err
+	return wrapExecutionError(err, highlighter)
+}
+
+// shellCommand builds the command that runs the shell with args, through sudo when switching users needs it
+func (e *Executor) shellCommand(shell string, args ...string) *exec.Cmd {
+	if e.runAs != nil && e.sudo {
+		// sudo resets the environment, so the configured variables are passed through env
+		sudoArgs := []string{"-u", e.runAs.Username, "-H", "--"}
+		if len(e.env) > 0 {
+			sudoArgs = append(append(sudoArgs, "env"), MergeEnvironment(nil, e.env)...)
+		}
+		return exec.Command("sudo", append(append(sudoArgs, e.shellPath(shell)), args...)...)
+	}
+	return exec.Command(e.shellPath(shell), args...)
+}
+
+// configure applies the executor's working directory, user, environment, and terminal settings to a command
+func (e *Executor) configure(cmd *exec.Cmd) {
+	cmd.Dir = e.dir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: e.credential}
+	if e.credential != nil {
+		cmd.Env = userEnvironment(e.runAs)
+	}
+	if len(e.env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = MergeEnvironment(cmd.Env, e.env)
+	}
+	if e.background {
+		// A process group of its own keeps a Ctrl+C at the terminal from reaching it twice; the
+		// knight forwards the signal instead
+		cmd.SysProcAttr.Setpgid = true
+		return
+	}
+
+	cmd.Stdin = os.Stdin
+
+	// Ensure the command runs in the foreground
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Pgid = 0
+}
+
+// forwardSignals passes the interrupts and termination requests the knight receives on to the
+// command's process group while it runs, so that stopping the knight stops the quest with it.
+// The returned function stops forwarding.
+func forwardSignals(cmd *exec.Cmd) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				// The command leads its own process group, which holds everything it started
+				_ = syscall.Kill(-cmd.Process.Pid, sig.(syscall.Signal))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// userEnvironment is the knight's environment with the identity variables of the given user
+func userEnvironment(account *user.User) []string {
+	identity := map[string]string{"HOME": account.HomeDir, "USER": account.Username, "LOGNAME": account.Username}
+	return MergeEnvironment(os.Environ(), identity)
 }
 
 // createExecutableScriptWithOutput creates a bash script with enhanced output and error handling
@@ -229,3 +376,19 @@ func (e *Executor) cleanupOldScripts(tmpDir string) {
 		}
 	}
 }
+
+// NewShellCommand builds a non-interactive command that runs the content through the given shell.
+// Callers own the command's pipes, which makes it suitable for embedding output in other views.
+func NewShellCommand(shell string, content string) *exec.Cmd {
+	return exec.Command(shell, "-c", content)
+}
+
+// ExecuteElevated is only supported on Windows; elsewhere commands carry their own sudo
+func (e *Executor) ExecuteElevated(command string, shell string) error {
+	return fmt.Errorf("elevated execution through UAC is only available on Windows")
+}
+
+// IsElevated reports whether the current process runs as root
+func IsElevated() bool {
+	return os.Geteuid() == 0
+}
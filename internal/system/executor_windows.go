@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
@@ -21,16 +22,41 @@ import (
 	"github.com/minand-mohan/execute-my-will/internal/ui"
 )
 
-type Executor struct{}
+// Executor runs commands in a shell, streaming their highlighted output
+type Executor struct {
+	dir        string // working directory; empty means the current directory
+	label      string // printed before every output line
+	background bool   // no console input, so several can run at once
+
+	env         map[string]string // added to the environment of every command, see WithEnvironment
+	waitHelper  string            // the binary that emw-wait expands to, see WithWaitHelper
+	shellBinary string            // runs in place of the named shell, see WithShellBinary
+	maxLineSize int               // bytes of a line printed before the rest is skipped, see WithMaxLineSize
+
+	logDir     string     // where the output of every run is saved, see WithOutputLog
+	lastOutput *OutputLog // the saved output of the most recent run
+}
 
 func NewExecutor() *Executor {
 	return &Executor{}
 }
 
+// NewProjectExecutor creates an executor that runs in dir and labels every output line.
+// Background executors do not read console input, so several of them can run at once.
+func NewProjectExecutor(dir, label string, background bool) CommandExecutor {
+	return &Executor{dir: dir, label: label, background: background}
+}
+
+// NewUserExecutor is only supported on Unix; Windows has no equivalent of sudo -u
+func NewUserExecutor(name string) (CommandExecutor, error) {
+	return nil, fmt.Errorf("running quests as another user is only available on Unix")
+}
+
 func (e *Executor) Execute(command string, shell string) error {
 	ui.PrintExecutionHeader(fmt.Sprintf("Executing thy will, my lord:\n%s", command))
+	command = ExpandWaitHelper(command, shell, e.waitHelper)
 
-	cmd := exec.Command(shell, "/C", command)
+	cmd := exec.Command(e.shellPath(shell), "/C", command)
 
 	// Create pipes to capture output for highlighting
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -43,31 +69,28 @@ func (e *Executor) Execute(command string, shell string) error {
 		return fmt.Errorf("failed to create stderr pipe: %v", err)
 	}
 
-	cmd.Stdin = os.Stdin
+	e.configure(cmd)
 
-	// Ensure it runs in the same console
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
-		HideWindow:    false,
-	}
+	// Create output highlighter
+	highlighter := e.newHighlighter(false)
+	logFile := e.startOutputLog(highlighter)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		e.finishOutputLog(logFile, highlighter)
 		return err
 	}
-
-	// Create output highlighter
-	highlighter := ui.NewOutputHighlighter(false, 1)
+	stopForwarding := forwardSignals(cmd)
 
 	// Stream outputs concurrently
 	done := make(chan error, 2)
 
 	go func() {
-		done <- highlighter.StreamOutput(stdoutPipe, "")
+		done <- highlighter.StreamOutput(decodeConsoleOutput(stdoutPipe), e.outputPrefix())
 	}()
 
 	go func() {
-		done <- highlighter.StreamOutput(stderrPipe, "")
+		done <- highlighter.StreamOutput(decodeConsoleOutput(stderrPipe), e.outputPrefix())
 	}()
 
 	// Wait for both streams to complete
@@ -79,14 +102,18 @@ func (e *Executor) Execute(command string, shell string) error {
 
 	// Wait for command to complete
 	err = cmd.Wait()
+	stopForwarding()
+	e.finishOutputLog(logFile, highlighter)
 
 	ui.PrintSeparator()
 
-	return err
+	return wrapExecutionError(err, highlighter)
 }
 
 // ExecuteScript runs a script with comments displayed during execution
 func (e *Executor) ExecuteScript(scriptContent string, shell string, showComments bool) error {
+	scriptContent = ExpandWaitHelper(scriptContent, shell, e.waitHelper)
+
 	// Create temp directory
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -99,18 +126,20 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 	}
 
 	// Generate script filename with timestamp and appropriate extension
-	timestamp := time.Now().Format("20060102_150405")
+	timestamp := time.Now().Format("20060102_150405.000000000")
 	var scriptPath string
 	var scriptWithExecutor string
 
 	if shell == "powershell" || shell == "pwsh" {
 		scriptPath = filepath.Join(tmpDir, fmt.Sprintf("script_%s.ps1", timestamp))
-		scriptWithExecutor = e.createPowerShellScript(scriptContent, showComments)
+		scriptWithExecutor = e.createPowerShellScript(NormalizeScript(scriptContent, shell), showComments)
 	} else {
 		// Default to cmd
+		shell = "cmd"
 		scriptPath = filepath.Join(tmpDir, fmt.Sprintf("script_%s.bat", timestamp))
-		scriptWithExecutor = e.createCmdScript(scriptContent, showComments)
+		scriptWithExecutor = e.createCmdScript(NormalizeScript(scriptContent, shell), showComments)
 	}
+	scriptWithExecutor = strings.ReplaceAll(scriptWithExecutor, "\n", ScriptLineEnding(shell))
 
 	if err := ioutil.WriteFile(scriptPath, []byte(scriptWithExecutor), 0755); err != nil {
 		return fmt.Errorf("failed to write script file: %v", err)
@@ -128,9 +157,9 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 	// Execute the script
 	var cmd *exec.Cmd
 	if shell == "powershell" || shell == "pwsh" {
-		cmd = exec.Command(shell, "-File", scriptPath)
+		cmd = exec.Command(e.shellPath(shell), "-File", scriptPath)
 	} else {
-		cmd = exec.Command("cmd", "/C", scriptPath)
+		cmd = exec.Command(e.shellPath("cmd"), "/C", scriptPath)
 	}
 
 	// Create pipes for enhanced output capture
@@ -144,30 +173,28 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 		return fmt.Errorf("failed to create stderr pipe: %v", err)
 	}
 
-	cmd.Stdin = os.Stdin
+	e.configure(cmd)
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
-		HideWindow:    false,
-	}
+	// Create output highlighter with timestamps for scripts
+	highlighter := e.newHighlighter(true)
+	logFile := e.startOutputLog(highlighter)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		e.finishOutputLog(logFile, highlighter)
 		return err
 	}
-
-	// Create output highlighter with timestamps for scripts
-	highlighter := ui.NewOutputHighlighter(true, 1)
+	stopForwarding := forwardSignals(cmd)
 
 	// Stream outputs concurrently
 	done := make(chan error, 2)
 
 	go func() {
-		done <- highlighter.StreamOutput(stdoutPipe, "")
+		done <- highlighter.StreamOutput(decodeConsoleOutput(stdoutPipe), e.outputPrefix())
 	}()
 
 	go func() {
-		done <- highlighter.StreamOutput(stderrPipe, "")
+		done <- highlighter.StreamOutput(decodeConsoleOutput(stderrPipe), e.outputPrefix())
 	}()
 
 	// Wait for both streams
@@ -179,10 +206,58 @@ func (e *Executor) ExecuteScript(scriptContent string, shell string, showComment
 
 	// Wait for command completion
 	err = cmd.Wait()
+	stopForwarding()
+	e.finishOutputLog(logFile, highlighter)
 
 	ui.PrintSeparator()
 
-	return err
+	return wrapExecutionError(err, highlighter)
+}
+
+// configure applies the executor's working directory, environment, and console settings to a command
+func (e *Executor) configure(cmd *exec.Cmd) {
+	cmd.Dir = e.dir
+	if len(e.env) > 0 {
+		cmd.Env = MergeEnvironment(os.Environ(), e.env)
+	}
+	if !e.background {
+		cmd.Stdin = os.Stdin
+	}
+
+	// Ensure it runs in the same console
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    false,
+	}
+}
+
+// generateConsoleCtrlEvent sends Ctrl+C or Ctrl+Break to a console process group
+var generateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+// ctrlBreakEvent is the only console event that reaches a process group other than the caller's
+const ctrlBreakEvent = 1
+
+// forwardSignals passes a Ctrl+C the knight receives on to the command while it runs. The command
+// has a process group of its own, which ignores Ctrl+C, so it is sent Ctrl+Break instead. The
+// returned function stops forwarding.
+func forwardSignals(cmd *exec.Cmd) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				_, _, _ = generateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(cmd.Process.Pid))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
 }
 
 // createPowerShellScript creates a PowerShell script with error handling and comment display
@@ -269,3 +344,52 @@ func (e *Executor) cleanupOldScripts(tmpDir string) {
 		}
 	}
 }
+
+// NewShellCommand builds a non-interactive command that runs the content through the given shell.
+// Callers own the command's pipes, which makes it suitable for embedding output in other views.
+func NewShellCommand(shell string, content string) *exec.Cmd {
+	if shell == "powershell" || shell == "pwsh" {
+		return exec.Command(shell, "-NoProfile", "-Command", content)
+	}
+
+	// cmd cannot take multi-line input via /C, so chain the non-comment lines
+	var steps []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(strings.ToUpper(line), "REM") {
+			continue
+		}
+		steps = append(steps, line)
+	}
+	return exec.Command("cmd", "/C", strings.Join(steps, " && "))
+}
+
+// ExecuteElevated relaunches the command in an elevated PowerShell window through the UAC prompt.
+// Output appears in that window, which stays open until the user dismisses it.
+func (e *Executor) ExecuteElevated(command string, shell string) error {
+	ui.PrintExecutionHeader(fmt.Sprintf("Executing thy will with the crown's authority, my lord:\n%s", command))
+	ui.PrintInfoMessage("Approve the Windows prompt to continue. The output will appear in a new window.")
+	e.lastOutput = nil
+	command = ExpandWaitHelper(command, shell, e.waitHelper)
+
+	// The elevated window does not inherit the knight's environment, so the variables are set inline
+	launcher := ElevationLauncherScript(EnvironmentPrefix(shell, e.env)+command, shell)
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-EncodedCommand", EncodePowerShellCommand(launcher))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	ui.PrintSeparator()
+
+	if err != nil {
+		return &ExecutionError{Err: err, ExitCode: exitCodeOf(err)}
+	}
+	return nil
+}
+
+// IsElevated reports whether the current process already runs as Administrator
+func IsElevated() bool {
+	// "net session" only succeeds for Administrators
+	return exec.Command("net", "session").Run() == nil
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/flags.go
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// HelpCacheFile caches the --help output of tools between quests, in the state directory
+const HelpCacheFile = "help-cache.json"
+
+// minDocumentedFlags is how many flags a help text must mention before it is trusted to list them all
+const minDocumentedFlags = 3
+
+var (
+	// helpFlagPattern finds the flags a help text documents
+	helpFlagPattern = regexp.MustCompile(`(?:^|[^\w-])(--?[A-Za-z0-9][\w-]*)`)
+
+	// subcommandPattern matches a word that may name a subcommand, such as "commit" in "git commit"
+	subcommandPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// unsafeHelpTools are never run, not even with --help, since some versions ignore the flag
+var unsafeHelpTools = map[string]bool{
+	"reboot": true, "shutdown": true, "halt": true, "poweroff": true, "init": true, "telinit": true,
+	"kexec": true, "openrc-shutdown": true,
+}
+
+// commandWrappers run the command that follows them; their own flags are not checked
+var commandWrappers = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "time": true, "nohup": true, "nice": true, "ionice": true,
+	"exec": true, "command": true, "builtin": true, "xargs": true, "timeout": true, "stdbuf": true,
+}
+
+// UnknownFlag is a flag a command uses that its tool's help text does not mention
+type UnknownFlag struct {
+	Tool string // the tool, with its subcommand if any, e.g. "git commit"
+	Flag string
+}
+
+func (f UnknownFlag) String() string {
+	return fmt.Sprintf("%s %s", f.Tool, f.Flag)
+}
+
+// helpEntry is the cached help text of one tool, valid while the executable is unchanged
+type helpEntry struct {
+	Path    string `json:"path"`
+	ModTime int64  `json:"mod_time"`
+	Size    int64  `json:"size"`
+	Text    string `json:"text"`
+}
+
+// HelpCache captures and remembers the --help output of tools. Only tools found on the PATH outside
+// the home and current directories are ever run, so a project's own scripts are never started
+// before the quest is approved.
+type HelpCache struct {
+	path       string
+	homeDir    string
+	currentDir string
+	mu         sync.Mutex
+	entries    map[string]helpEntry
+	changed    bool
+}
+
+// LoadHelpCache reads the cache at path; a missing or damaged cache starts empty
+func LoadHelpCache(path, homeDir, currentDir string) *HelpCache {
+	cache := &HelpCache{path: path, homeDir: homeDir, currentDir: currentDir, entries: make(map[string]helpEntry)}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cache.entries)
+	}
+	return cache
+}
+
+// Save writes the cache back when new help texts were captured
+func (c *HelpCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed || c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	c.changed = false
+	return os.WriteFile(c.path, data, 0o644)
+}
+
+// Help returns the help text of a tool, or of one of its subcommands when args name one. ok is
+// false when the tool is not one that may be run, or its help lists too few flags to judge by.
+func (c *HelpCache) Help(tool string, args ...string) (text string, ok bool) {
+	if strings.ContainsAny(tool, `/\`) || unsafeHelpTools[tool] {
+		return "", false
+	}
+	path, err := exec.LookPath(tool)
+	if err != nil || c.isPrivate(path) {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+
+	key := strings.Join(append([]string{tool}, args...), " ")
+	c.mu.Lock()
+	entry, cached := c.entries[key]
+	c.mu.Unlock()
+	if !cached || entry.Path != path || entry.ModTime != info.ModTime().Unix() || entry.Size != info.Size() {
+		entry = helpEntry{Path: path, ModTime: info.ModTime().Unix(), Size: info.Size(), Text: captureHelp(path, args)}
+		c.mu.Lock()
+		c.entries[key] = entry
+		c.changed = true
+		c.mu.Unlock()
+	}
+	return entry.Text, len(helpFlagPattern.FindAllString(entry.Text, minDocumentedFlags)) >= minDocumentedFlags
+}
+
+// isPrivate reports whether an executable lives in the home or current directory, where it is
+// more likely a personal script than a tool that honours --help
+func (c *HelpCache) isPrivate(path string) bool {
+	for _, dir := range []string{c.homeDir, c.currentDir} {
+		if dir == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// captureHelp runs the tool with --help, falling back to its man page when that prints no flags
+func captureHelp(path string, args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), toolHelpTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, append(append([]string{}, args...), "--help")...)
+	cmd.Env = append(os.Environ(), "MANPAGER=cat", "PAGER=cat", "GIT_PAGER=cat")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Many tools print their help to stderr and exit with a non-zero status
+	_ = cmd.Run()
+	text := out.String()
+	if ctx.Err() != nil {
+		text = ""
+	}
+	if len(args) == 0 && !helpFlagPattern.MatchString(text) {
+		text = readManPage(filepath.Base(path))
+	}
+	return CleanManText(text)
+}
+
+// CheckFlags returns the flags of a POSIX command or script that the help texts of its tools do
+// not mention. help returns a tool's help text and whether it can be judged by; tools it cannot
+// judge are skipped. A short option cluster such as "-xzf" passes when it, or its first letter,
+// is documented, since the letters after it may be a value.
+func CheckFlags(content string, help func(tool string, args ...string) (string, bool)) []UnknownFlag {
+	var unknown []UnknownFlag
+	seen := make(map[UnknownFlag]bool)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, part := range SplitCommandChain(line) {
+			for _, stage := range splitPipeline(part.Command) {
+				for _, flag := range checkStageFlags(shellWords(stage), help) {
+					if !seen[flag] {
+						seen[flag] = true
+						unknown = append(unknown, flag)
+					}
+				}
+			}
+		}
+	}
+	return unknown
+}
+
+func checkStageFlags(words []string, help func(tool string, args ...string) (string, bool)) []UnknownFlag {
+	words = unwrapCommand(words)
+	if len(words) == 0 {
+		return nil
+	}
+
+	tool, args := words[0], words[1:]
+	text, ok := help(tool)
+	if !ok {
+		return nil
+	}
+	name := tool
+	// A subcommand the tool lists documents its own flags, as in "git commit --amend"; without that
+	// help the top-level one cannot judge them
+	if len(args) > 0 && subcommandPattern.MatchString(args[0]) && helpMentions(text, args[0]) {
+		subText, subOK := help(tool, args[0])
+		if !subOK {
+			return nil
+		}
+		text, name, args = subText, tool+" "+args[0], args[1:]
+	}
+
+	var unknown []UnknownFlag
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		flag, _, _ := strings.Cut(arg, "=")
+		if len(flag) < 2 || flag[0] != '-' || !isFlagName(strings.TrimLeft(flag, "-")) {
+			continue
+		}
+		if helpMentions(text, flag) {
+			continue
+		}
+		if !strings.HasPrefix(flag, "--") && helpMentions(text, flag[:2]) {
+			continue
+		}
+		unknown = append(unknown, UnknownFlag{Tool: name, Flag: flag})
+	}
+	return unknown
+}
+
+// unwrapCommand skips environment assignments and wrappers such as sudo, with the wrappers' own
+// flags, so that the words start with the command that really runs
+func unwrapCommand(words []string) []string {
+	for len(words) > 0 && (strings.Contains(words[0], "=") || commandWrappers[words[0]]) {
+		words = words[1:]
+		for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// isFlagName reports whether the text after the dashes names an option rather than a number or
+// a value such as "-" for stdin
+func isFlagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	first := name[0]
+	return (first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z')
+}
+
+// helpMentions reports whether the help text has the word or flag on its own, not inside another
+func helpMentions(text, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before := i == 0 || !isWordByte(text[i-1])
+		after := end == len(text) || !isWordByte(text[end])
+		if before && after {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '-' || b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// splitPipeline splits a command on the pipes outside quotes
+func splitPipeline(command string) []string {
+	var stages []string
+	var current strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '|':
+			stages = append(stages, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(stages, current.String())
+}
+
+// shellWords splits a simple command into words, removing quotes. Words with substitutions or
+// redirections are left out, since their flags cannot be known before they run.
+func shellWords(command string) []string {
+	var words []string
+	var current strings.Builder
+	var quote rune
+	escaped, inWord, dynamic := false, false, false
+	flush := func() {
+		if inWord && !dynamic {
+			words = append(words, current.String())
+		}
+		current.Reset()
+		inWord, dynamic = false, false
+	}
+	for _, r := range command {
+		switch {
+		case escaped:
+			escaped = false
+			current.WriteRune(r)
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			if r == '$' || r == '`' || r == '<' || r == '>' || r == '(' || r == ')' || r == '&' {
+				dynamic = true
+			}
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+	return words
+}
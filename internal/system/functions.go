@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/functions.go
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FunctionsFile is the library of shell functions distilled from recurring scripts
+const FunctionsFile = "functions.sh"
+
+// maxFunctionNameWords caps the words of the intent used for a suggested function name
+const maxFunctionNameWords = 4
+
+var (
+	functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// definedFunctionPattern finds the functions a library defines, as "name() {" or "name() ("
+	definedFunctionPattern = regexp.MustCompile(`(?m)^([A-Za-z_][A-Za-z0-9_]*)\s*\(\)`)
+)
+
+// reservedFunctionNames are shell keywords and builtins a function must not shadow
+var reservedFunctionNames = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true, "case": true, "esac": true, "for": true,
+	"while": true, "until": true, "do": true, "done": true, "function": true, "select": true, "time": true,
+	"cd": true, "echo": true, "exit": true, "export": true, "set": true, "unset": true, "source": true,
+	"alias": true, "read": true, "test": true, "return": true, "eval": true, "exec": true, "type": true,
+}
+
+// functionNameStopWords are left out of suggested function names
+var functionNameStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "my": true, "to": true, "of": true, "for": true, "in": true,
+	"on": true, "all": true, "please": true, "and": true, "with": true, "from": true, "into": true,
+}
+
+// SuggestFunctionName turns an intent into a function name, e.g. "back up my dotfiles to the NAS"
+// becomes "back_up_dotfiles_nas"
+func SuggestFunctionName(intent string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(intent), func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+	}) {
+		if functionNameStopWords[word] {
+			continue
+		}
+		words = append(words, word)
+		if len(words) == maxFunctionNameWords {
+			break
+		}
+	}
+	name := strings.Join(words, "_")
+	if name == "" || !functionNamePattern.MatchString(name) || reservedFunctionNames[name] {
+		name = "quest_" + name
+	}
+	return strings.TrimSuffix(name, "_")
+}
+
+// ValidateFunctionName reports why a name cannot be used for a shell function, or nil
+func ValidateFunctionName(name string) error {
+	if !functionNamePattern.MatchString(name) {
+		return fmt.Errorf("'%s' is not a valid function name; use letters, digits, and underscores, not starting with a digit", name)
+	}
+	if reservedFunctionNames[name] {
+		return fmt.Errorf("'%s' is a shell keyword or builtin", name)
+	}
+	return nil
+}
+
+// ShellFunction wraps a script in a POSIX shell function. The body runs in a subshell, so that
+// the script's "set -e", "cd", and "exit" cannot affect the shell that sources the library. The
+// function's arguments reach the script as "$1", "$2", and so on.
+func ShellFunction(name, intent, script string, runs int, at time.Time) string {
+	return fmt.Sprintf("# %s\n# Distilled on %s from a script run %d times\n%s", strings.ReplaceAll(intent, "\n", " "), at.Format("2006-01-02"), runs, functionBody(name, script))
+}
+
+// functionBody wraps a script in the subshell body of a function, without any comment above it
+func functionBody(name, script string) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(script, "\r\n", "\n")), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
+		lines = lines[1:]
+	}
+	// Indenting a heredoc would move its terminator off the start of the line
+	indent := "  "
+	if strings.Contains(script, "<<") {
+		indent = ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s() (\n", name)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// DefinedFunctions lists the functions a library defines
+func DefinedFunctions(library string) []string {
+	var names []string
+	for _, match := range definedFunctionPattern.FindAllStringSubmatch(library, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// AppendFunction adds a function to the library at path, creating it with a header explaining
+// how to load it when it does not exist yet
+func AppendFunction(path, function string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create the functions directory: %w", err)
+	}
+
+	var header string
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		header = fmt.Sprintf("# Shell functions distilled from recurring quests by execute-my-will.\n# Load them from your shell's rc file (e.g. ~/.bashrc or ~/.zshrc) with:\n#   . %s\n", path)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	_, err = fmt.Fprintf(file, "%s\n%s", header, function)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// FunctionDefinition returns the named function of a library with the comments written above it,
+// or "" when the library does not define it
+func FunctionDefinition(library, name string) string {
+	lines := strings.Split(strings.ReplaceAll(library, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		match := definedFunctionPattern.FindStringSubmatch(line)
+		if match == nil || match[1] != name {
+			continue
+		}
+		start := i
+		for start > 0 && strings.HasPrefix(lines[start-1], "#") {
+			start--
+		}
+		for end := i + 1; end < len(lines); end++ {
+			if closing := strings.TrimRight(lines[end], " \t"); closing == ")" || closing == "}" {
+				return strings.Join(lines[start:end+1], "\n") + "\n"
+			}
+		}
+		return strings.Join(lines[start:], "\n")
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/git.go
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// maxStagedDiff caps the characters of the staged diff kept for a commit message
+	maxStagedDiff = 12000
+	// recentCommitSubjects is how many earlier commit subjects show the repository's style
+	recentCommitSubjects = 10
+)
+
+// StagedChanges are the changes staged for the next commit of a repository
+type StagedChanges struct {
+	Branch         string   // the current branch; empty before the first commit or on a detached HEAD
+	Files          []string // one "status<TAB>path" line per file, as from git diff --name-status
+	Stat           string   // the diffstat of the staged changes
+	Diff           string   // the staged diff, cut at maxStagedDiff characters
+	Truncated      bool     // Diff was cut short
+	RecentSubjects []string // the subjects of the latest commits, newest first
+}
+
+// gitRepository runs git in a directory
+type gitRepository struct {
+	dir string
+}
+
+// NewGitRepository returns the git repository containing dir
+func NewGitRepository(dir string) GitRepository {
+	return &gitRepository{dir: dir}
+}
+
+// StagedChanges reads what is staged for the next commit. It fails when git is not installed or
+// dir is not inside a repository; nothing staged is not an error.
+func (r *gitRepository) StagedChanges() (*StagedChanges, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, &kindError{kind: ErrCommandNotFound, err: errors.New("git is not installed")}
+	}
+	if _, err := r.git("rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository", r.dir)
+	}
+
+	names, err := r.git("diff", "--cached", "--name-status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the staged files: %w", err)
+	}
+	changes := &StagedChanges{}
+	for _, line := range strings.Split(strings.TrimSpace(names), "\n") {
+		if line != "" {
+			changes.Files = append(changes.Files, line)
+		}
+	}
+	if len(changes.Files) == 0 {
+		return changes, nil
+	}
+
+	if changes.Stat, err = r.git("diff", "--cached", "--stat"); err != nil {
+		return nil, fmt.Errorf("failed to read the staged changes: %w", err)
+	}
+	changes.Stat = strings.TrimRight(changes.Stat, "\n")
+	if changes.Diff, err = r.git("diff", "--cached", "--no-color", "--no-ext-diff"); err != nil {
+		return nil, fmt.Errorf("failed to read the staged changes: %w", err)
+	}
+	if len(changes.Diff) > maxStagedDiff {
+		changes.Diff, changes.Truncated = changes.Diff[:maxStagedDiff], true
+	}
+
+	// Both fail harmlessly in a repository without commits
+	if branch, err := r.git("symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		changes.Branch = strings.TrimSpace(branch)
+	}
+	if subjects, err := r.git("log", "-n", fmt.Sprint(recentCommitSubjects), "--format=%s"); err == nil {
+		for _, subject := range strings.Split(strings.TrimSpace(subjects), "\n") {
+			if subject != "" {
+				changes.RecentSubjects = append(changes.RecentSubjects, subject)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// Commit commits the staged changes with message. With edit set, git opens the message in the
+// user's editor first, and an emptied message aborts the commit.
+func (r *gitRepository) Commit(message string, edit bool) error {
+	file, err := os.CreateTemp("", "execute-my-will-commit-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to write the commit message: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(strings.TrimSpace(message) + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the commit message: %w", err)
+	}
+	file.Close()
+
+	args := []string{"-C", r.dir, "commit", "-F", file.Name()}
+	if edit {
+		args = append(args, "--edit")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// git runs a git subcommand in the repository and returns its output, or its error message
+func (r *gitRepository) git(args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", r.dir}, args...)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/immutable.go
+package system
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImmutableSystem describes a root filesystem that the usual package manager cannot change:
+// ostree-based and image-based distros, and containers with a read-only root. Software belongs
+// in a layer, a Flatpak, or a toolbox there instead of a plain install that fails or is lost.
+type ImmutableSystem struct {
+	Distro       string // e.g. "Fedora Linux 40 (Silverblue)" or "SteamOS"
+	Installer    string // the supported way to add system packages, e.g. "rpm-ostree" or "flatpak"
+	OSTree       bool   // booted from an ostree deployment
+	ReadOnlyRoot bool   // "/" is mounted read-only
+	Container    string // the container the knight runs in, e.g. "toolbox" or "docker"
+}
+
+// immutableDistros maps os-release IDs and variant IDs of image-based distros to their installer
+var immutableDistros = map[string]string{
+	"silverblue":          "rpm-ostree",
+	"kinoite":             "rpm-ostree",
+	"sericea":             "rpm-ostree",
+	"onyx":                "rpm-ostree",
+	"coreos":              "rpm-ostree",
+	"iot":                 "rpm-ostree",
+	"fedora-coreos":       "rpm-ostree",
+	"bazzite":             "rpm-ostree",
+	"bluefin":             "rpm-ostree",
+	"aurora":              "rpm-ostree",
+	"steamos":             "flatpak",
+	"endless":             "flatpak",
+	"opensuse-microos":    "transactional-update",
+	"opensuse-aeon":       "transactional-update",
+	"opensuse-kalpa":      "transactional-update",
+	"opensuse-leap-micro": "transactional-update",
+	"vanilla":             "abroot",
+	"flatcar":             "", // no package manager at all; everything runs in containers
+	"talos":               "",
+	"bottlerocket":        "",
+}
+
+// rootInstall matches package manager installs that write to the root filesystem
+var rootInstall = regexp.MustCompile(`\b(?:dnf|yum|apt|apt-get|zypper)\s+(?:-\S+\s+)*(?:install|in)\b|\bpacman\s+(?:-\S+\s+)*-S\w*`)
+
+// Detected reports whether the root filesystem resists ordinary installs. A writable toolbox
+// on an immutable host is not immutable itself.
+func (m ImmutableSystem) Detected() bool {
+	return m.OSTree || m.ReadOnlyRoot || m.Distro != ""
+}
+
+func (m ImmutableSystem) String() string {
+	var parts []string
+	if m.Distro != "" {
+		parts = append(parts, m.Distro)
+	}
+	if m.OSTree {
+		parts = append(parts, "ostree")
+	}
+	if m.ReadOnlyRoot {
+		parts = append(parts, "read-only root")
+	}
+	if m.Installer != "" {
+		parts = append(parts, "install with "+m.Installer)
+	}
+	if m.Container != "" {
+		parts = append(parts, "inside "+m.Container)
+	}
+	if len(parts) == 0 {
+		return "not detected"
+	}
+	if !m.Detected() {
+		// Only the container is known, so installs work as usual
+		return parts[0] + " (writable)"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ConflictingInstall returns the first install in content that the read-only root would reject,
+// or "" when there is none. Installs run inside a toolbox or distrobox are fine.
+func (m ImmutableSystem) ConflictingInstall(content string) string {
+	if !m.Detected() {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, "toolbox ") || strings.Contains(line, "distrobox") {
+			continue
+		}
+		if match := rootInstall.FindString(line); match != "" {
+			return match
+		}
+	}
+	return ""
+}
+
+// DetectImmutableSystem examines the filesystem below root ("/" outside of tests): os-release,
+// the ostree and container markers, and the mount table
+func DetectImmutableSystem(root string, getenv func(string) string) ImmutableSystem {
+	var m ImmutableSystem
+
+	release := readOSRelease(root)
+	for _, id := range []string{release["VARIANT_ID"], release["ID"]} {
+		if installer, ok := immutableDistros[id]; ok && id != "" {
+			m.Distro = release["PRETTY_NAME"]
+			if m.Distro == "" {
+				m.Distro = release["NAME"]
+			}
+			m.Installer = installer
+			break
+		}
+	}
+
+	m.OSTree = fileExists(filepath.Join(root, "run", "ostree-booted"))
+	if m.OSTree && m.Installer == "" {
+		m.Installer = "rpm-ostree"
+	}
+	m.ReadOnlyRoot = rootMountedReadOnly(filepath.Join(root, "proc", "self", "mounts"))
+
+	switch {
+	case fileExists(filepath.Join(root, "run", ".toolboxenv")):
+		m.Container = "toolbox"
+	case getenv("DISTROBOX_ENTER_PATH") != "":
+		m.Container = "distrobox"
+	case fileExists(filepath.Join(root, "run", ".containerenv")):
+		m.Container = "podman"
+	case fileExists(filepath.Join(root, ".dockerenv")):
+		m.Container = "docker"
+	}
+	return m
+}
+
+// readOSRelease parses os-release into its keys, without quotes
+func readOSRelease(root string) map[string]string {
+	release := make(map[string]string)
+	for _, path := range []string{filepath.Join(root, "etc", "os-release"), filepath.Join(root, "usr", "lib", "os-release")} {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+			if ok && !strings.HasPrefix(key, "#") {
+				release[key] = strings.Trim(value, `"'`)
+			}
+		}
+		_ = file.Close()
+		return release
+	}
+	return release
+}
+
+// rootMountedReadOnly reports whether the last mount of "/" in the mount table is read-only
+func rootMountedReadOnly(mountsPath string) bool {
+	data, err := os.ReadFile(mountsPath)
+	if err != nil {
+		return false
+	}
+
+	readOnly := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[1] != "/" {
+			continue
+		}
+		readOnly = false
+		for _, option := range strings.Split(fields[3], ",") {
+			if option == "ro" {
+				readOnly = true
+			}
+		}
+	}
+	return readOnly
+}
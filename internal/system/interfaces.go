@@ -14,6 +14,7 @@ type SystemAnalyzer interface {
 type CommandExecutor interface {
 	Execute(command string, shell string) error
 	ExecuteScript(scriptContent string, shell string, showComments bool) error
+	ExecuteElevated(command string, shell string) error
 }
 
 // EnvironmentValidatorInterface defines the interface for environment validation
@@ -26,4 +27,17 @@ type IntentValidator interface {
 	ValidateIntent(intent string) error
 }
 
+// GitRepository defines the interface for reading and committing the staged changes of a repository
+type GitRepository interface {
+	StagedChanges() (*StagedChanges, error)
+	Commit(message string, edit bool) error
+}
+
+// Scheduler defines the interface for installing and removing the jobs that run scheduled spells.
+// Removing a spell that was never installed is not an error.
+type Scheduler interface {
+	Install(name string, schedule *Schedule, command []string) error
+	Remove(name string) error
+}
+
 // Note: Interface compliance is verified through usage in tests
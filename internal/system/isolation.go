@@ -0,0 +1,350 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/isolation.go
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MaxScratchCopySize is the largest directory, in bytes, that is copied for an isolated quest
+const MaxScratchCopySize = 512 << 20
+
+// ScratchCopy is a copy of a directory that a quest may change freely. Its changes reach the
+// original directory only through Apply.
+type ScratchCopy struct {
+	Source string // the original directory
+	Dir    string // the copy
+}
+
+// ChangeKind is how a path differs between the copy and the original
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeModified ChangeKind = "modified"
+	ChangeDeleted  ChangeKind = "deleted"
+)
+
+// FileChange is one path the quest changed in the copy
+type FileChange struct {
+	Path string // relative to both directories
+	Kind ChangeKind
+}
+
+// NewScratchCopy copies source, with its files, directories, and symlinks, into a new
+// temporary directory
+func NewScratchCopy(source string) (*ScratchCopy, error) {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	err = filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		if size > MaxScratchCopySize {
+			return fmt.Errorf("%s holds more than %d MiB, too much to copy", source, MaxScratchCopySize>>20)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "emw-isolated-*")
+	if err != nil {
+		return nil, err
+	}
+	scratch := &ScratchCopy{Source: source, Dir: dir}
+	if err := copyTree(source, dir); err != nil {
+		scratch.Remove()
+		return nil, err
+	}
+	return scratch, nil
+}
+
+// copyTree copies the contents of src into the existing directory dst. Sockets, pipes, and
+// devices are left out.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		return copyEntry(path, filepath.Join(dst, rel), entry.Type())
+	})
+}
+
+// copyEntry copies one file, directory, or symlink, replacing whatever is at dst
+func copyEntry(src, dst string, mode fs.FileMode) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case mode.IsDir():
+		// Kept writable by its owner so that its contents can be copied in
+		return os.MkdirAll(dst, info.Mode().Perm()|0o700)
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case mode.IsRegular():
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	default:
+		return nil
+	}
+}
+
+// treeEntries lists every path under dir, relative to it, with its type
+func treeEntries(dir string) (map[string]fs.FileMode, error) {
+	entries := make(map[string]fs.FileMode)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." {
+			entries[rel] = entry.Type()
+		}
+		return err
+	})
+	return entries, err
+}
+
+// Changes compares the copy with the original, in path order. Inside an added directory every
+// path is listed; a deleted directory is listed once.
+func (s *ScratchCopy) Changes() ([]FileChange, error) {
+	before, err := treeEntries(s.Source)
+	if err != nil {
+		return nil, err
+	}
+	after, err := treeEntries(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for path, mode := range after {
+		original, existed := before[path]
+		switch {
+		case !existed:
+			changes = append(changes, FileChange{Path: path, Kind: ChangeAdded})
+		case original.Type() != mode.Type():
+			changes = append(changes, FileChange{Path: path, Kind: ChangeModified})
+		case !mode.IsDir():
+			same, err := s.sameEntry(path)
+			if err != nil {
+				return nil, err
+			}
+			if !same {
+				changes = append(changes, FileChange{Path: path, Kind: ChangeModified})
+			}
+		}
+	}
+	for path := range before {
+		if _, exists := after[path]; !exists && !insideDeleted(path, before, after) {
+			changes = append(changes, FileChange{Path: path, Kind: ChangeDeleted})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// insideDeleted reports whether a parent directory of path is gone from the copy as well, or
+// is no longer a directory
+func insideDeleted(path string, before, after map[string]fs.FileMode) bool {
+	for parent := filepath.Dir(path); parent != "."; parent = filepath.Dir(parent) {
+		if mode, exists := after[parent]; !exists || !mode.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// sameEntry compares a file or symlink present in both directories by content and permissions
+func (s *ScratchCopy) sameEntry(path string) (bool, error) {
+	original, copied := filepath.Join(s.Source, path), filepath.Join(s.Dir, path)
+
+	before, err := os.Lstat(original)
+	if err != nil {
+		return false, err
+	}
+	after, err := os.Lstat(copied)
+	if err != nil {
+		return false, err
+	}
+
+	if before.Mode()&fs.ModeSymlink != 0 {
+		oldTarget, err := os.Readlink(original)
+		if err != nil {
+			return false, err
+		}
+		newTarget, err := os.Readlink(copied)
+		return oldTarget == newTarget, err
+	}
+	if before.Size() != after.Size() || before.Mode().Perm() != after.Mode().Perm() {
+		return false, nil
+	}
+
+	oldContent, err := os.ReadFile(original)
+	if err != nil {
+		return false, err
+	}
+	newContent, err := os.ReadFile(copied)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(oldContent, newContent), nil
+}
+
+// Diff shows one change: a unified diff for text files, and a one-line summary for binary
+// files, directories, symlinks, and permission changes
+func (s *ScratchCopy) Diff(change FileChange) string {
+	original, copied := filepath.Join(s.Source, change.Path), filepath.Join(s.Dir, change.Path)
+	name := filepath.ToSlash(change.Path)
+
+	var old, new []byte
+	for _, side := range []struct {
+		path    string
+		present bool
+		content *[]byte
+	}{
+		{original, change.Kind != ChangeAdded, &old},
+		{copied, change.Kind != ChangeDeleted, &new},
+	} {
+		if !side.present {
+			continue
+		}
+		info, err := os.Lstat(side.path)
+		if err != nil {
+			return fmt.Sprintf("%s: %v\n", name, err)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Sprintf("%s: %s %s\n", name, describeMode(info.Mode()), change.Kind)
+		}
+		if *side.content, err = os.ReadFile(side.path); err != nil {
+			return fmt.Sprintf("%s: %v\n", name, err)
+		}
+	}
+
+	if bytes.IndexByte(old, 0) >= 0 || bytes.IndexByte(new, 0) >= 0 {
+		return fmt.Sprintf("%s: binary file %s\n", name, change.Kind)
+	}
+
+	oldName, newName := "a/"+name, "b/"+name
+	switch change.Kind {
+	case ChangeAdded:
+		oldName = "/dev/null"
+	case ChangeDeleted:
+		newName = "/dev/null"
+	}
+	if diff := UnifiedDiff(oldName, newName, string(old), string(new)); diff != "" {
+		return diff
+	}
+	return fmt.Sprintf("%s: permissions changed\n", name)
+}
+
+func describeMode(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "directory"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "special file"
+	}
+}
+
+// Apply makes the changes in the original directory
+func (s *ScratchCopy) Apply(changes []FileChange) error {
+	for _, change := range changes {
+		target := filepath.Join(s.Source, change.Path)
+		if change.Kind == ChangeDeleted {
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			continue
+		}
+
+		info, err := os.Lstat(filepath.Join(s.Dir, change.Path))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if change.Kind == ChangeModified && info.IsDir() {
+			// A file replaced by a directory
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+		}
+		if err := copyEntry(filepath.Join(s.Dir, change.Path), target, info.Mode().Type()); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes the copy
+func (s *ScratchCopy) Remove() error {
+	if s.Dir == "" || !strings.Contains(filepath.Base(s.Dir), "emw-isolated-") {
+		return nil
+	}
+	return os.RemoveAll(s.Dir)
+}
+
+// WithWorkingDir runs every command and script of the executor in dir
+func WithWorkingDir(executor CommandExecutor, dir string) CommandExecutor {
+	if e, ok := executor.(*Executor); ok {
+		e.dir = dir
+	}
+	return executor
+}
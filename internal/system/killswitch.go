@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/killswitch.go
+package system
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxKillSwitchMessage bounds how much of a kill-switch file is shown as the administrator's message
+const maxKillSwitchMessage = 2 << 10
+
+// KillSwitch is an engaged kill-switch file. While it exists, no command is generated or executed.
+type KillSwitch struct {
+	Path    string
+	Message string // the file's contents, left by the administrator; may be empty
+}
+
+// DefaultKillSwitchPath is the machine-wide kill-switch file, which only administrators can create:
+// /etc/execute-my-will/disabled, or %ProgramData%\execute-my-will\disabled on Windows
+func DefaultKillSwitchPath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "execute-my-will", "disabled")
+	}
+	return "/etc/execute-my-will/disabled"
+}
+
+// CheckKillSwitch returns the engaged kill switch, or nil when quests may proceed. The default
+// path is always checked, so a user's configuration can add a kill switch but never remove it.
+func CheckKillSwitch(configured string) *KillSwitch {
+	for _, path := range []string{DefaultKillSwitchPath(), configured} {
+		if path == "" {
+			continue
+		}
+		// The file's existence is what counts, even when it cannot be read
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		return &KillSwitch{Path: path, Message: readKillSwitchMessage(path)}
+	}
+	return nil
+}
+
+func readKillSwitchMessage(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	content, _ := io.ReadAll(io.LimitReader(file, maxKillSwitchMessage))
+	return strings.TrimSpace(strings.ToValidUTF8(string(content), ""))
+}
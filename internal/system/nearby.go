@@ -0,0 +1,241 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/nearby.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// NearbyDirectories lists up to limit subdirectories of dir that a vague intent may have meant.
+// Directories whose names share a word with the intent come first; hidden ones are left out.
+func NearbyDirectories(intent, dir string, limit int) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	words := intentWords(intent)
+	var named, others []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if slices.ContainsFunc(words, func(word string) bool { return len(word) > 2 && strings.Contains(strings.ToLower(name), word) }) {
+			named = append(named, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	directories := append(named, others...)
+	if len(directories) > limit {
+		directories = directories[:limit]
+	}
+	return directories
+}
+
+// maxSimilarPathEntries bounds how many directory entries a search for similar paths visits
+const maxSimilarPathEntries = 5000
+
+// similarPathDepth is how deep under the current and home directories similar names are searched for
+const similarPathDepth = 3
+
+// maxPartMatches caps the entries followed for each part of a path that does not exist
+const maxPartMatches = 3
+
+// skippedSearchDirs are large directories that are never searched for similar names
+var skippedSearchDirs = map[string]bool{"node_modules": true, "vendor": true, "target": true, "__pycache__": true}
+
+// pathMatch is an existing path relative to where it was found, and how far it is from the one asked for
+type pathMatch struct {
+	path     string
+	distance int
+}
+
+// SimilarPaths suggests up to limit existing paths for one that does not exist, such as
+// "./docs/design" for "./doc/desgin". Each part of the path is matched against the entries beside
+// it, and paths named like its last part are searched for under the current and home directories.
+// Suggestions are relative to the current directory, absolute, or under "~", like the path given.
+func SimilarPaths(path, currentDir, homeDir string, limit int) []string {
+	root, prefix, rest := splitPathRoot(path, currentDir, homeDir)
+	parts := strings.FieldsFunc(rest, isPathSeparator)
+	if len(parts) == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		shown, resolved string
+		distance        int
+	}
+	var candidates []candidate
+	for _, match := range matchPathParts(root, parts) {
+		candidates = append(candidates, candidate{prefix + match.path, filepath.Join(root, match.path), match.distance})
+	}
+	budget := maxSimilarPathEntries
+	for _, base := range []struct{ dir, prefix string }{{currentDir, "." + string(filepath.Separator)}, {homeDir, "~" + string(filepath.Separator)}} {
+		if base.dir == "" {
+			continue
+		}
+		for _, match := range searchSimilarNames(base.dir, parts[len(parts)-1], &budget) {
+			candidates = append(candidates, candidate{base.prefix + match.path, filepath.Join(base.dir, match.path), match.distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	var suggestions []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		if !seen[c.resolved] {
+			seen[c.resolved] = true
+			suggestions = append(suggestions, c.shown)
+		}
+	}
+	return suggestions
+}
+
+// splitPathRoot returns the directory a path starts from, the prefix suggestions for it are shown
+// with, and the rest of the path
+func splitPathRoot(path, currentDir, homeDir string) (root, prefix, rest string) {
+	separator := string(filepath.Separator)
+	switch {
+	case strings.HasPrefix(path, "~"):
+		return homeDir, "~" + separator, path[1:]
+	case filepath.IsAbs(path) || isPathSeparator(rune(path[0])):
+		volume := filepath.VolumeName(path)
+		return volume + separator, volume + separator, path[len(volume):]
+	case strings.HasPrefix(path, ".."):
+		return currentDir, "", path
+	case strings.HasPrefix(path, "./") || strings.HasPrefix(path, ".\\"):
+		return currentDir, "." + separator, path[2:]
+	}
+	return currentDir, "." + separator, path
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// matchPathParts follows the parts of a path from dir, keeping parts that exist and trying the
+// closest entries for those that do not
+func matchPathParts(dir string, parts []string) []pathMatch {
+	if len(parts) == 0 {
+		return []pathMatch{{}}
+	}
+	var next []pathMatch
+	if _, err := os.Stat(filepath.Join(dir, parts[0])); err == nil {
+		next = []pathMatch{{path: parts[0]}}
+	} else {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && len(parts) > 1 {
+				continue
+			}
+			if distance := nameDistance(parts[0], entry.Name()); distance >= 0 {
+				next = append(next, pathMatch{entry.Name(), distance})
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].distance < next[j].distance })
+		if len(next) > maxPartMatches {
+			next = next[:maxPartMatches]
+		}
+	}
+
+	var matches []pathMatch
+	for _, part := range next {
+		for _, tail := range matchPathParts(filepath.Join(dir, part.path), parts[1:]) {
+			matches = append(matches, pathMatch{filepath.Join(part.path, tail.path), part.distance + tail.distance})
+		}
+	}
+	return matches
+}
+
+// searchSimilarNames finds entries named like name under root, breadth first, visiting at most
+// budget entries
+func searchSimilarNames(root, name string, budget *int) []pathMatch {
+	type pending struct {
+		dir, rel string
+		depth    int
+	}
+	var matches []pathMatch
+	queue := []pending{{root, "", 1}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		entries, err := os.ReadDir(current.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if *budget <= 0 {
+				return matches
+			}
+			*budget--
+			entryName := entry.Name()
+			if strings.HasPrefix(entryName, ".") || skippedSearchDirs[entryName] {
+				continue
+			}
+			rel := filepath.Join(current.rel, entryName)
+			if distance := nameDistance(name, entryName); distance >= 0 {
+				matches = append(matches, pathMatch{rel, distance})
+			}
+			if entry.IsDir() && current.depth < similarPathDepth {
+				queue = append(queue, pending{filepath.Join(current.dir, entryName), rel, current.depth + 1})
+			}
+		}
+	}
+	return matches
+}
+
+// nameDistance scores how close an existing name is to the one asked for, or returns -1 when it is
+// not close. Case is ignored, about one typo is allowed in three letters, and a name that begins
+// with the other counts as a distant match.
+func nameDistance(want, got string) int {
+	want, got = strings.ToLower(want), strings.ToLower(got)
+	if want == got {
+		return 0
+	}
+	allowed := max(1, utf8.RuneCountInString(want)/3)
+	if distance := editDistance(want, got); distance <= allowed {
+		return distance
+	}
+	if len(want) >= 3 && len(got) >= 3 && (strings.HasPrefix(got, want) || strings.HasPrefix(want, got)) {
+		return allowed + 1
+	}
+	return -1
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
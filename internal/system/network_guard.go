@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/network_guard.go
+package system
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// NetworkOperation describes a line of a command or script that would reach the network
+type NetworkOperation struct {
+	Line   string
+	Reason string
+}
+
+var (
+	// urlPattern finds URLs of the schemes that reach other machines
+	urlPattern = regexp.MustCompile(`(?i)\b(https?|ftps?|sftp|ssh|git|rsync|wss?)://[^\s'"<>|;&)]+`)
+
+	// substitutionNetworkPattern finds network tools inside $(...) and backticks, whose words are not parsed
+	substitutionNetworkPattern = regexp.MustCompile("(?i)(\\$\\(|`)\\s*(sudo\\s+)?(curl|wget|aria2c|ssh|nc|ncat|dig|nslookup|iwr|irm|invoke-webrequest|invoke-restmethod)\\b")
+
+	// remotePathPattern matches the host:path arguments of scp and rsync
+	remotePathPattern = regexp.MustCompile(`^([\w.-]+@)?[\w.-]+::?[^/\\]?`)
+)
+
+// networkTools always reach the network
+var networkTools = map[string]bool{
+	"curl": true, "wget": true, "aria2c": true, "ssh": true, "scp": true, "sftp": true, "ftp": true,
+	"lftp": true, "telnet": true, "nc": true, "ncat": true, "netcat": true, "socat": true, "ping": true,
+	"ping6": true, "dig": true, "nslookup": true, "traceroute": true, "tracepath": true, "mtr": true,
+	"whois": true, "rclone": true, "aws": true, "gcloud": true, "gsutil": true, "az": true,
+	"iwr": true, "irm": true, "invoke-webrequest": true, "invoke-restmethod": true,
+	"test-netconnection": true, "start-bitstransfer": true,
+}
+
+// urlTools only fetch the URLs they are given, so a fetch from this machine itself is allowed
+var urlTools = map[string]bool{
+	"curl": true, "wget": true, "aria2c": true, "iwr": true, "irm": true, "invoke-webrequest": true,
+	"invoke-restmethod": true,
+}
+
+// networkSubcommands are the subcommands of package managers and other tools that download or upload
+var networkSubcommands = map[string][]string{
+	"git":     {"clone", "fetch", "pull", "push", "ls-remote", "submodule"},
+	"apt":     {"update", "upgrade", "install", "dist-upgrade", "full-upgrade", "download", "source"},
+	"apt-get": {"update", "upgrade", "install", "dist-upgrade", "download", "source"},
+	"dnf":     {"install", "update", "upgrade", "makecache", "download", "check-update"},
+	"yum":     {"install", "update", "upgrade", "makecache", "check-update"},
+	"zypper":  {"install", "in", "refresh", "ref", "update", "up", "dist-upgrade", "dup"},
+	"apk":     {"add", "update", "upgrade", "fetch"},
+	"brew":    {"install", "update", "upgrade", "fetch", "tap"},
+	"snap":    {"install", "refresh", "download"},
+	"flatpak": {"install", "update", "remote-add"},
+	"pip":     {"install", "download"},
+	"pip3":    {"install", "download"},
+	"npm":     {"install", "i", "ci", "update", "add", "publish"},
+	"yarn":    {"add", "install", "upgrade", "publish"},
+	"pnpm":    {"add", "install", "i", "update", "publish"},
+	"cargo":   {"install", "fetch", "update", "publish"},
+	"go":      {"get"},
+	"gem":     {"install", "update", "fetch"},
+	"conda":   {"install", "create", "update"},
+	"docker":  {"pull", "push", "login", "search"},
+	"podman":  {"pull", "push", "login", "search"},
+	"helm":    {"repo", "pull"},
+	"ollama":  {"pull", "push"},
+	"winget":  {"install", "upgrade", "search", "source"},
+	"choco":   {"install", "upgrade", "search"},
+	"scoop":   {"install", "update", "bucket"},
+}
+
+// offlineFlags make an otherwise networked subcommand work from local files only
+var offlineFlags = []string{"--offline", "--no-index", "--no-download", "--cacheonly"}
+
+// valueFlags are global flags whose value comes before the subcommand, as in "git -C repo pull"
+var valueFlags = map[string]bool{"-C": true, "-c": true, "--git-dir": true, "--work-tree": true}
+
+// DetectNetworkOperations finds the lines of a command or script that would reach another
+// machine: network tools, package managers that download, and URLs that are not this machine's.
+// Content may be a single command or a multi-line script.
+func DetectNetworkOperations(content string) []NetworkOperation {
+	var operations []NetworkOperation
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToUpper(line), "REM ") {
+			continue
+		}
+		if reason := lineNetworkReason(line); reason != "" {
+			operations = append(operations, NetworkOperation{Line: line, Reason: reason})
+		}
+	}
+	return operations
+}
+
+func lineNetworkReason(line string) string {
+	remote := remoteURLs(line)
+	for _, part := range SplitCommandChain(line) {
+		for _, stage := range splitPipeline(part.Command) {
+			if reason := commandNetworkReason(unwrapCommand(shellWords(stage)), len(remote) > 0); reason != "" {
+				return reason
+			}
+		}
+	}
+	if match := substitutionNetworkPattern.FindStringSubmatch(line); match != nil {
+		return fmt.Sprintf("%s runs inside a substitution and reaches the network", strings.ToLower(match[3]))
+	}
+	if len(remote) > 0 {
+		return fmt.Sprintf("it refers to %s, which is not on this machine", remote[0])
+	}
+	return ""
+}
+
+// commandNetworkReason explains why one simple command reaches the network, or returns an empty
+// string when it does not. remote reports whether its line names a URL of another machine.
+func commandNetworkReason(words []string, remote bool) string {
+	if len(words) == 0 {
+		return ""
+	}
+	tool := strings.TrimSuffix(strings.ToLower(words[0]), ".exe")
+	args := words[1:]
+
+	switch {
+	case urlTools[tool]:
+		// A tool fetching only from this machine, such as a local health check, stays offline
+		if !remote && hasOnlyLoopbackURLs(args) {
+			return ""
+		}
+		return fmt.Sprintf("%s downloads from the network", tool)
+	case networkTools[tool]:
+		return fmt.Sprintf("%s reaches the network", tool)
+	case tool == "rsync":
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") && remotePathPattern.MatchString(arg) {
+				return "rsync copies to or from another machine"
+			}
+		}
+	case tool == "pacman":
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-S") || arg == "--sync" || arg == "--refresh" {
+				return "pacman downloads from package mirrors"
+			}
+		}
+	case tool == "go" && len(args) >= 2 && args[0] == "mod" && args[1] == "download":
+		return "go mod download fetches modules from the network"
+	case tool == "yarn" && len(args) == 0:
+		return "yarn installs packages from the network"
+	}
+
+	subcommands, ok := networkSubcommands[tool]
+	if !ok {
+		return ""
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if valueFlags[arg] {
+				i++
+			}
+			continue
+		}
+		for _, subcommand := range subcommands {
+			if arg == subcommand && !hasOfflineFlag(args) {
+				return fmt.Sprintf("%s %s reaches the network", tool, subcommand)
+			}
+		}
+		// Only the first word after the tool's own flags names the subcommand
+		break
+	}
+	return ""
+}
+
+func hasOfflineFlag(args []string) bool {
+	for _, arg := range args {
+		for _, flag := range offlineFlags {
+			if arg == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasOnlyLoopbackURLs reports whether the arguments name at least one URL or host and all of them
+// are this machine's own
+func hasOnlyLoopbackURLs(args []string) bool {
+	found := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		var host string
+		if parsed, err := url.Parse(arg); err == nil && parsed.Host != "" {
+			host = parsed.Hostname()
+		} else {
+			// A bare "localhost:8080/health" or "127.0.0.1/health"; other words are file names
+			host, _, _ = strings.Cut(strings.SplitN(arg, "/", 2)[0], ":")
+			if host != "localhost" && net.ParseIP(host) == nil {
+				continue
+			}
+		}
+		if !isLoopbackHost(host) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// remoteURLs lists the URLs in a line that point at other machines
+func remoteURLs(line string) []string {
+	var remote []string
+	for _, match := range urlPattern.FindAllString(line, -1) {
+		parsed, err := url.Parse(match)
+		if err != nil || !isLoopbackHost(parsed.Hostname()) {
+			remote = append(remote, match)
+		}
+	}
+	return remote
+}
+
+func isLoopbackHost(host string) bool {
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/nix.go
+package system
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// NixEnvironment describes how Nix manages the system and the current project. Where Nix is in
+// charge, packages belong in a dev shell or a declaration rather than a global install.
+type NixEnvironment struct {
+	NixOS   bool   // the system is NixOS, where imperative installs do not survive a rebuild
+	Shell   string // the Nix shell the knight runs in, e.g. "devenv" or "nix shell (impure)"
+	Flake   string // the nearest flake.nix in the current directory or its parents
+	DevFile string // devenv.nix, shell.nix, or default.nix in the current directory
+}
+
+// nixDevFiles declare a development shell without a flake, most specific first
+var nixDevFiles = []string{"devenv.nix", "shell.nix", "default.nix"}
+
+// Detected reports whether Nix manages anything the quest may touch
+func (n NixEnvironment) Detected() bool {
+	return n.NixOS || n.Shell != "" || n.Flake != "" || n.DevFile != ""
+}
+
+func (n NixEnvironment) String() string {
+	if !n.Detected() {
+		return "not detected"
+	}
+
+	var parts []string
+	if n.NixOS {
+		parts = append(parts, "NixOS")
+	}
+	if n.Shell != "" {
+		parts = append(parts, "running inside "+n.Shell)
+	}
+	if n.Flake != "" {
+		parts = append(parts, "flake: "+n.Flake)
+	}
+	if n.DevFile != "" {
+		parts = append(parts, "dev shell: "+n.DevFile)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DetectNixEnvironment finds Nix shells from the environment and Nix project files from dir.
+// NixOS itself is detected by the analyzer.
+func DetectNixEnvironment(dir string, getenv func(string) string) NixEnvironment {
+	var nix NixEnvironment
+
+	switch {
+	case getenv("DEVENV_ROOT") != "":
+		nix.Shell = "devenv"
+	case getenv("IN_NIX_SHELL") != "":
+		nix.Shell = fmt.Sprintf("nix shell (%s)", getenv("IN_NIX_SHELL"))
+	}
+
+	if dir == "" {
+		return nix
+	}
+	for _, file := range nixDevFiles {
+		if fileExists(filepath.Join(dir, file)) {
+			nix.DevFile = file
+			break
+		}
+	}
+	for current := dir; ; {
+		if path := filepath.Join(current, "flake.nix"); fileExists(path) {
+			nix.Flake = path
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	return nix
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/output_log.go
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// OutputLogDir is the name of the state directory holding saved output logs
+const OutputLogDir = "logs"
+
+// maxOutputLogs is how many output logs are kept; older ones are removed after every run
+const maxOutputLogs = 50
+
+// notableOutputLine matches lines worth keeping when a long output is sampled
+var notableOutputLine = regexp.MustCompile(`(?i)\b(error|errors|warn|warning|warnings|fail|failed|failure|fatal|denied|held|skipped|not found|summary|total)\b`)
+
+// OutputLog is the saved output of one command or script
+type OutputLog struct {
+	Path  string
+	Lines int
+}
+
+// OutputLogger is implemented by executors that save the output of every run to a file
+type OutputLogger interface {
+	// LastOutput returns the log of the most recent run, or nil when it was not saved
+	LastOutput() *OutputLog
+}
+
+// WithOutputLog saves the output of every command and script the executor runs to a file in
+// dir. Executors other than the system's own, such as test doubles, are returned unchanged.
+func WithOutputLog(executor CommandExecutor, dir string) CommandExecutor {
+	if e, ok := executor.(*Executor); ok {
+		e.logDir = dir
+	}
+	return executor
+}
+
+// LastOutput returns the log of the most recent run, or nil when it was not saved
+func (e *Executor) LastOutput() *OutputLog {
+	return e.lastOutput
+}
+
+// startOutputLog opens a new log file and attaches it to the highlighter. It returns nil when
+// logging is off or the file cannot be created; a missing log never fails the quest.
+func (e *Executor) startOutputLog(highlighter *ui.OutputHighlighter) *os.File {
+	e.lastOutput = nil
+	if e.logDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(e.logDir, 0700); err != nil {
+		return nil
+	}
+
+	name := fmt.Sprintf("quest_%s.log", time.Now().Format("20060102_150405.000000000"))
+	file, err := os.OpenFile(filepath.Join(e.logDir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil
+	}
+	highlighter.SetLog(file)
+	return file
+}
+
+// finishOutputLog closes the log file, remembers it as the last output, and prunes old logs
+func (e *Executor) finishOutputLog(file *os.File, highlighter *ui.OutputHighlighter) {
+	if file == nil {
+		return
+	}
+	_ = file.Close()
+	e.lastOutput = &OutputLog{Path: file.Name(), Lines: highlighter.LineCount()}
+	pruneOutputLogs(e.logDir)
+}
+
+// pruneOutputLogs keeps only the most recent logs; their names sort by time
+func pruneOutputLogs(dir string) {
+	logs, err := filepath.Glob(filepath.Join(dir, "quest_*.log"))
+	if err != nil || len(logs) <= maxOutputLogs {
+		return
+	}
+	sort.Strings(logs)
+	for _, path := range logs[:len(logs)-maxOutputLogs] {
+		_ = os.Remove(path)
+	}
+}
+
+// SampleOutputLog reads a saved log and keeps its first head and last tail lines, plus up to
+// notable lines from the middle that mention errors, warnings, or totals. Omitted stretches are
+// marked so the sample is never mistaken for the whole output.
+func SampleOutputLog(path string, head, tail, notable int) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the output log: %w", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) <= head+tail {
+		return strings.Join(lines, "\n"), nil
+	}
+
+	sample := append([]string(nil), lines[:head]...)
+	omitted := 0
+	for _, line := range lines[head : len(lines)-tail] {
+		if notable > 0 && notableOutputLine.MatchString(line) {
+			if omitted > 0 {
+				sample = append(sample, fmt.Sprintf("[… %d lines omitted …]", omitted))
+				omitted = 0
+			}
+			sample = append(sample, line)
+			notable--
+			continue
+		}
+		omitted++
+	}
+	if omitted > 0 {
+		sample = append(sample, fmt.Sprintf("[… %d lines omitted …]", omitted))
+	}
+	return strings.Join(append(sample, lines[len(lines)-tail:]...), "\n"), nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/path_scan.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxPathCommands caps the commands gathered from PATH. Far more than any prompt shows, it keeps
+// a PATH full of huge directories, such as the Windows system directories under WSL, from
+// costing seconds of startup.
+const MaxPathCommands = 5000
+
+// pathScanWorkers bounds how many PATH directories are read at once
+const pathScanWorkers = 8
+
+// pathScanBudget is how long the PATH scan may take before directories still being read, such
+// as ones on a slow network drive, are given up on
+const pathScanBudget = 3 * time.Second
+
+// relevantPathDirectories hold the tools users ask for most, and are read first
+var relevantPathDirectories = []string{
+	"~/.local/bin", "~/bin", "~/go/bin", "~/.cargo/bin", "~/.nix-profile/bin",
+	"/usr/local/bin", "/opt/homebrew/bin", "/usr/bin", "/bin", "/usr/local/sbin", "/usr/sbin", "/sbin",
+}
+
+// remotePathPrefixes start PATH directories that are likely mounted from elsewhere and slow to
+// read, and are read last: other drives under WSL, removable media, and network shares
+var remotePathPrefixes = []string{"/mnt/", "/media/", "/net/", "/afs/", `\\`}
+
+// ScanPathCommands lists the commands in the PATH directories, reading several directories at
+// once. Duplicate directories are read once, and the most relevant ones first, so that the
+// commands come most relevant first. names returns the command names a directory entry offers,
+// or none. The scan stops early at MaxPathCommands commands or after pathScanBudget, and reports
+// whether it did.
+func ScanPathCommands(dirs []string, names func(entry os.DirEntry) []string) ([]string, bool) {
+	return scanPathCommands(PrioritizePathDirectories(UniquePathDirectories(dirs)), names, MaxPathCommands, pathScanBudget)
+}
+
+func scanPathCommands(dirs []string, names func(entry os.DirEntry) []string, limit int, budget time.Duration) ([]string, bool) {
+	type listing struct {
+		index    int
+		commands []string
+	}
+
+	jobs := make(chan int)
+	results := make(chan listing, len(dirs))
+	for range min(pathScanWorkers, len(dirs)) {
+		go func() {
+			for i := range jobs {
+				entries, _ := os.ReadDir(dirs[i]) // unreadable directories offer no commands
+				var commands []string
+				for _, entry := range entries {
+					commands = append(commands, names(entry)...)
+				}
+				results <- listing{index: i, commands: commands}
+			}
+		}()
+	}
+
+	// Directories are handed out in order of relevance, and no more once enough commands are
+	// found or the time is up. A worker stuck on a slow directory is left to finish on its own.
+	listings := make([][]string, len(dirs))
+	deadline := time.After(budget)
+	next, pending, found := 0, 0, 0
+	truncated := false
+	for next < len(dirs) || pending > 0 {
+		send := jobs
+		if next == len(dirs) || found >= limit {
+			send = nil
+		}
+		if send == nil && pending == 0 {
+			break
+		}
+		select {
+		case send <- next:
+			next++
+			pending++
+		case result := <-results:
+			listings[result.index] = result.commands
+			found += len(result.commands)
+			pending--
+		case <-deadline:
+			truncated = true
+			pending = 0
+			next = len(dirs)
+		}
+	}
+	close(jobs)
+	truncated = truncated || next < len(dirs)
+
+	seen := make(map[string]bool)
+	var commands []string
+	for _, listing := range listings {
+		for _, command := range listing {
+			if seen[command] {
+				continue
+			}
+			if len(commands) == limit {
+				return commands, true
+			}
+			seen[command] = true
+			commands = append(commands, command)
+		}
+	}
+	return commands, truncated
+}
+
+// UniquePathDirectories drops empty and repeated PATH entries, comparing cleaned paths, and
+// ignoring case on Windows
+func UniquePathDirectories(dirs []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		key := dir
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(dir)
+		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, dir)
+		}
+	}
+	return unique
+}
+
+// PrioritizePathDirectories orders PATH directories for reading: the usual tool directories
+// first, likely remote ones last, and the rest in between, each group in PATH order
+func PrioritizePathDirectories(dirs []string) []string {
+	home, _ := os.UserHomeDir()
+	rank := func(dir string) int {
+		slashed := filepath.ToSlash(dir)
+		for _, relevant := range relevantPathDirectories {
+			if rest, ok := strings.CutPrefix(relevant, "~"); ok {
+				if home == "" {
+					continue
+				}
+				relevant = filepath.ToSlash(home) + rest
+			}
+			if slashed == relevant {
+				return 0
+			}
+		}
+		for _, prefix := range remotePathPrefixes {
+			if strings.HasPrefix(dir, prefix) || strings.HasPrefix(slashed, prefix) {
+				return 2
+			}
+		}
+		return 1
+	}
+
+	ordered := append([]string(nil), dirs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return rank(ordered[i]) < rank(ordered[j]) })
+	return ordered
+}
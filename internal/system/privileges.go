@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/privileges.go
+package system
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Privileges describes whether the user can become root. Without sudo, every install has to go
+// into the home directory: pip install --user, a user npm prefix, cargo install, or a download
+// into ~/.local/bin.
+type Privileges struct {
+	Checked  bool   // the scan looked; false for quick scans and on Windows
+	Root     bool   // the knight already runs as root
+	Elevator string // the tool that grants this user root, "sudo" or "doas"; empty when there is none
+}
+
+// adminGroups are the groups that sudo and doas grant root to on common distros
+var adminGroups = map[string]bool{"sudo": true, "wheel": true, "admin": true}
+
+// rootTools run a command as another user, which without sudo rights only asks for a password
+// that will be refused
+var rootTools = map[string]bool{"sudo": true, "doas": true, "su": true, "pkexec": true, "run0": true}
+
+// systemWriteTools write to the paths they are given
+var systemWriteTools = map[string]bool{
+	"cp": true, "mv": true, "install": true, "ln": true, "tee": true, "mkdir": true, "touch": true,
+	"chmod": true, "chown": true, "rm": true,
+}
+
+// lastArgTools only write to their last argument; the others are sources
+var lastArgTools = map[string]bool{"cp": true, "mv": true, "install": true, "ln": true}
+
+// systemDirs belong to root, so a user without sudo cannot write below them
+var systemDirs = []string{"/usr/", "/etc/", "/opt/", "/bin/", "/sbin/", "/lib/", "/lib64/", "/boot/", "/var/lib/"}
+
+var (
+	// systemRedirect matches output redirected into a system directory
+	systemRedirect = regexp.MustCompile(`>>?\s*/(usr|etc|opt|bin|sbin|lib|lib64|boot|var/lib)/`)
+
+	// systemInstall matches package managers that install for the whole machine
+	systemInstall = regexp.MustCompile(`^(?:snap\s+install|apk\s+add|dpkg\s+(?:-\S+\s+)*-i|rpm\s+(?:-\S+\s+)*-[iU]\w*)\b`)
+)
+
+// RootLess reports whether the user is known to have no way to become root
+func (p Privileges) RootLess() bool {
+	return p.Checked && !p.Root && p.Elevator == ""
+}
+
+func (p Privileges) String() string {
+	switch {
+	case !p.Checked:
+		return "not checked"
+	case p.Root:
+		return "running as root"
+	case p.Elevator != "":
+		return "available through " + p.Elevator
+	}
+	return "none (no sudo); install into the home directory only"
+}
+
+// RootCommand returns the first command in content that needs root, or "" when there is none or
+// the user can become root: sudo and its kin, installs by the system package manager, and writes
+// below system directories such as /usr/local/bin
+func (p Privileges) RootCommand(content string) string {
+	if !p.RootLess() {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, part := range SplitCommandChain(line) {
+			for _, stage := range splitPipeline(part.Command) {
+				if needsRoot(stage) {
+					return strings.TrimSpace(stage)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// needsRoot reports whether one simple command needs root to succeed
+func needsRoot(stage string) bool {
+	words := shellWords(stage)
+	if len(words) == 0 {
+		return false
+	}
+	if rootTools[filepath.Base(words[0])] {
+		return true
+	}
+	if systemRedirect.MatchString(stage) {
+		return true
+	}
+
+	words = unwrapCommand(words)
+	if len(words) == 0 {
+		return false
+	}
+	command := strings.Join(words, " ")
+	if rootInstall.MatchString(command) || systemInstall.MatchString(command) {
+		return true
+	}
+
+	tool := filepath.Base(words[0])
+	if !systemWriteTools[tool] {
+		return false
+	}
+	var paths []string
+	for _, word := range words[1:] {
+		if !strings.HasPrefix(word, "-") {
+			paths = append(paths, word)
+		}
+	}
+	if lastArgTools[tool] && len(paths) > 0 {
+		paths = paths[len(paths)-1:]
+	}
+	for _, path := range paths {
+		if inSystemDir(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func inSystemDir(path string) bool {
+	for _, dir := range systemDirs {
+		if strings.HasPrefix(path, dir) || path+"/" == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectPrivileges works out whether the user can become root from their effective user ID and
+// group names. lookPath finds sudo and doas; sudoWithoutPassword, tried only when no admin group
+// vouches for the user, reports whether sudo grants root without asking (a NOPASSWD rule).
+func DetectPrivileges(euid int, groups []string, lookPath func(string) (string, error), sudoWithoutPassword func() bool) Privileges {
+	p := Privileges{Checked: true}
+	if euid == 0 {
+		p.Root = true
+		return p
+	}
+
+	admin := false
+	for _, group := range groups {
+		if adminGroups[group] {
+			admin = true
+			break
+		}
+	}
+
+	_, sudoErr := lookPath("sudo")
+	_, doasErr := lookPath("doas")
+	switch {
+	case sudoErr == nil && admin:
+		p.Elevator = "sudo"
+	case doasErr == nil && admin:
+		p.Elevator = "doas"
+	case sudoErr == nil && sudoWithoutPassword != nil && sudoWithoutPassword():
+		p.Elevator = "sudo"
+	}
+	return p
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/processes.go
+package system
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxReportedProcesses bounds the processes sent with a quest
+const maxReportedProcesses = 15
+
+// ProcessInfo is one running process
+type ProcessInfo struct {
+	PID      int
+	Name     string
+	CPU      float64 // percent of one core; negative when the platform does not report it
+	MemoryKB int64   // resident memory
+}
+
+func (p ProcessInfo) String() string {
+	usage := fmt.Sprintf("%.0f MB", float64(p.MemoryKB)/1024)
+	if p.CPU >= 0 {
+		usage = fmt.Sprintf("%.1f%% CPU, %s", p.CPU, usage)
+	}
+	return fmt.Sprintf("%s (pid %d, %s)", p.Name, p.PID, usage)
+}
+
+// ProcessReport is the part of the process list that is relevant to an intent
+type ProcessReport struct {
+	Processes []ProcessInfo
+	Matched   bool   // the processes match a name in the intent; otherwise they are the heaviest ones
+	By        string // "memory" or "CPU", the order of the list
+	Total     int    // processes that qualified before the list was cut
+}
+
+func (r *ProcessReport) String() string {
+	if r == nil {
+		return "not gathered"
+	}
+	listed := make([]string, 0, len(r.Processes))
+	for _, p := range r.Processes {
+		listed = append(listed, p.String())
+	}
+	list := strings.Join(listed, "; ")
+	if r.Total > len(r.Processes) {
+		list += fmt.Sprintf("; and %d more", r.Total-len(r.Processes))
+	}
+
+	if r.Matched {
+		return fmt.Sprintf("matching the intent, by %s: %s", r.By, list)
+	}
+	return fmt.Sprintf("none match the intent by name; the top %d by %s: %s", len(r.Processes), r.By, list)
+}
+
+// processIntentWords mark intents about running programs
+var processIntentWords = map[string]bool{
+	"process": true, "processes": true, "pid": true, "pids": true, "kill": true, "killall": true, "pkill": true,
+	"terminate": true, "stuck": true, "hung": true, "hanging": true, "frozen": true, "unresponsive": true,
+	"responding": true, "ram": true, "memory": true, "cpu": true, "eating": true, "hogging": true, "hog": true,
+	"consuming": true, "running": true, "using": true,
+}
+
+// processStopWords are never taken for a process name
+var processStopWords = map[string]bool{
+	"the": true, "my": true, "why": true, "is": true, "are": true, "what": true, "which": true, "and": true,
+	"for": true, "too": true, "much": true, "that": true, "this": true, "with": true, "from": true, "all": true,
+	"find": true, "show": true, "list": true, "app": true, "program": true, "slow": true, "so": true, "it": true,
+}
+
+// processAliases maps names people use to the names their processes run under
+var processAliases = map[string]string{
+	"vscode": "code",
+	"edge":   "msedge",
+	"word":   "winword",
+}
+
+// IsProcessIntent reports whether the intent is about running programs, such as "why is chrome
+// eating my RAM" or "kill the stuck node process"
+func IsProcessIntent(intent string) bool {
+	for _, word := range intentWords(intent) {
+		if processIntentWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func intentWords(intent string) []string {
+	return strings.FieldsFunc(strings.ToLower(intent), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	})
+}
+
+// NewProcessReport picks the processes named in the intent, or the heaviest ones when none is
+// named. The list is ordered by CPU when the intent mentions it, and by memory otherwise.
+func NewProcessReport(processes []ProcessInfo, intent string) *ProcessReport {
+	report := &ProcessReport{By: "memory"}
+	var names []string
+	for _, word := range intentWords(intent) {
+		if word == "cpu" {
+			report.By = "CPU"
+		}
+		if processIntentWords[word] || processStopWords[word] || len(word) < 2 {
+			continue
+		}
+		if alias, ok := processAliases[word]; ok {
+			word = alias
+		}
+		names = append(names, strings.Trim(word, ".-_"))
+	}
+
+	var matched []ProcessInfo
+	for _, p := range processes {
+		if processMatches(p.Name, names) {
+			matched = append(matched, p)
+		}
+	}
+	selected := processes
+	if len(matched) > 0 {
+		selected, report.Matched = matched, true
+	}
+
+	sorted := append([]ProcessInfo(nil), selected...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if report.By == "CPU" && sorted[i].CPU != sorted[j].CPU {
+			return sorted[i].CPU > sorted[j].CPU
+		}
+		return sorted[i].MemoryKB > sorted[j].MemoryKB
+	})
+	report.Total = len(sorted)
+	if !report.Matched {
+		report.Total = 0
+	}
+	report.Processes = sorted[:min(len(sorted), maxReportedProcesses)]
+	return report
+}
+
+// processMatches reports whether a process name matches one of the intent's words: exactly, or
+// by containing it (or being contained in it) when the shorter of the two has at least four letters
+func processMatches(name string, words []string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	for _, word := range words {
+		switch {
+		case word == "":
+		case name == word:
+			return true
+		case len(word) >= 4 && strings.Contains(name, word):
+			return true
+		case len(name) >= 4 && strings.Contains(word, name):
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProcessList reads the output of 'ps -axo pid=,pcpu=,rss=,comm='. Absolute command paths
+// are reduced to their base name, and the knight's own process is left out.
+func ParseProcessList(output string) []ProcessInfo {
+	var processes []ProcessInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, errPID := strconv.Atoi(fields[0])
+		cpu, errCPU := strconv.ParseFloat(fields[1], 64)
+		rss, errRSS := strconv.ParseInt(fields[2], 10, 64)
+		if errPID != nil || errCPU != nil || errRSS != nil || pid == os.Getpid() {
+			continue
+		}
+
+		// The command may contain spaces, as in macOS application paths
+		command := strings.TrimSpace(line)
+		for i := 0; i < 3; i++ {
+			command = strings.TrimSpace(command[len(strings.Fields(command)[0]):])
+		}
+		// Kernel threads such as "kworker/0:1" keep their slash
+		if slash := strings.LastIndex(command, "/"); strings.HasPrefix(command, "/") && slash < len(command)-1 {
+			command = command[slash+1:]
+		}
+		processes = append(processes, ProcessInfo{PID: pid, Name: command, CPU: cpu, MemoryKB: rss})
+	}
+	return processes
+}
+
+// ParseTasklist reads the output of 'tasklist /FO CSV /NH'. Tasklist reports no CPU usage.
+func ParseTasklist(output string) []ProcessInfo {
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.FieldsPerRecord = -1
+	records, _ := reader.ReadAll()
+
+	var processes []ProcessInfo
+	for _, record := range records {
+		if len(record) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(record[1])
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		// "150,236 K", with the thousands separator of the locale
+		digits := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, record[4])
+		memory, _ := strconv.ParseInt(digits, 10, 64)
+		processes = append(processes, ProcessInfo{PID: pid, Name: record[0], CPU: -1, MemoryKB: memory})
+	}
+	return processes
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// ListProcesses returns the running processes with their CPU and memory use
+func ListProcesses() ([]ProcessInfo, error) {
+	output, err := exec.Command("ps", "-axo", "pid=,pcpu=,rss=,comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return ParseProcessList(string(output)), nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/project.go
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectType is a build tool identified by its key file in a project directory
+type ProjectType struct {
+	Tool        string // e.g. "cargo"
+	Marker      string // the key file that identified the tool
+	TestCommand string // how the project's tests are usually run
+}
+
+func (p ProjectType) String() string {
+	return fmt.Sprintf("%s (%s; tests: %s)", p.Tool, p.Marker, p.TestCommand)
+}
+
+// projectMarker maps key files to a build tool. Markers are checked in order and the first
+// existing file of each tool wins.
+type projectMarker struct {
+	tool        string
+	files       []string
+	testCommand func(dir string) string
+}
+
+func fixedCommand(command string) func(string) string {
+	return func(string) string { return command }
+}
+
+var projectMarkers = []projectMarker{
+	{"bazel", []string{"MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE", "BUILD.bazel"}, fixedCommand("bazel test //...")},
+	{"gradle", []string{"build.gradle.kts", "build.gradle", "settings.gradle.kts", "settings.gradle"}, func(dir string) string {
+		if fileExists(filepath.Join(dir, "gradlew")) || fileExists(filepath.Join(dir, "gradlew.bat")) {
+			return "./gradlew test"
+		}
+		return "gradle test"
+	}},
+	{"maven", []string{"pom.xml"}, func(dir string) string {
+		if fileExists(filepath.Join(dir, "mvnw")) || fileExists(filepath.Join(dir, "mvnw.cmd")) {
+			return "./mvnw test"
+		}
+		return "mvn test"
+	}},
+	{"cargo", []string{"Cargo.toml"}, fixedCommand("cargo test")},
+	{"go", []string{"go.work", "go.mod"}, fixedCommand("go test ./...")},
+	{"node", []string{"package.json"}, func(dir string) string {
+		switch {
+		case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+			return "pnpm test"
+		case fileExists(filepath.Join(dir, "yarn.lock")):
+			return "yarn test"
+		case fileExists(filepath.Join(dir, "bun.lockb")) || fileExists(filepath.Join(dir, "bun.lock")):
+			return "bun test"
+		default:
+			return "npm test"
+		}
+	}},
+	{"python", []string{"pyproject.toml", "setup.py", "setup.cfg", "tox.ini"}, func(dir string) string {
+		if fileExists(filepath.Join(dir, "tox.ini")) {
+			return "tox"
+		}
+		return "pytest"
+	}},
+	{"cmake", []string{"CMakeLists.txt"}, fixedCommand("ctest --test-dir build")},
+	{"dotnet", []string{"*.sln", "*.csproj", "*.fsproj"}, fixedCommand("dotnet test")},
+	{"make", []string{"Makefile", "makefile", "GNUmakefile"}, fixedCommand("make test")},
+}
+
+// DetectProjectTypes returns the build tools whose key files are in dir, most specific first
+func DetectProjectTypes(dir string) []ProjectType {
+	if dir == "" {
+		return nil
+	}
+
+	var types []ProjectType
+	for _, marker := range projectMarkers {
+		for _, file := range marker.files {
+			name, ok := findMarker(dir, file)
+			if !ok {
+				continue
+			}
+			types = append(types, ProjectType{Tool: marker.tool, Marker: name, TestCommand: marker.testCommand(dir)})
+			break
+		}
+	}
+	return types
+}
+
+// findMarker looks for a key file, which may be a pattern such as "*.sln", directly in dir
+func findMarker(dir, pattern string) (string, bool) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern, fileExists(filepath.Join(dir, pattern))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if matched, _ := filepath.Match(pattern, entry.Name()); matched && !entry.IsDir() {
+			return entry.Name(), true
+		}
+	}
+	return "", false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (a *Analyzer) detectProjectTypes(info *Info) error {
+	info.ProjectTypes = DetectProjectTypes(info.CurrentDir)
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/question.go
+package system
+
+import "regexp"
+
+var (
+	// politePrefix is the "please" or "can you" before the question itself
+	politePrefix = regexp.MustCompile(`(?i)^\s*(?:please\s+)?(?:(?:can|could|would)\s+you\s+(?:please\s+)?)?`)
+
+	// questionOpener matches intents that open like a question or ask for an explanation
+	questionOpener = regexp.MustCompile(`(?i)^(?:what|what's|whats|why|how|is|are|does|do|did|should|when|which|who|where|explain|define|describe|tell\s+me\s+(?:what|why|how))\b`)
+
+	// howToPattern matches questions that are requests in disguise, such as "how do I free up space"
+	howToPattern = regexp.MustCompile(`(?i)^(?:how\s+(?:do|can|could|would|should|might)\s+(?:i|we)\b|how\s+to\b)`)
+)
+
+// IsQuestionIntent reports whether the intent is phrased as a question, such as "what does
+// chmod 755 mean?", rather than as a task. Questions that ask how to do something ("how do I
+// find large files") and requests ("can you clean up my downloads") are tasks.
+func IsQuestionIntent(intent string) bool {
+	rest := intent[len(politePrefix.FindString(intent)):]
+	return questionOpener.MatchString(rest) && !howToPattern.MatchString(rest)
+}
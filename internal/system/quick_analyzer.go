@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/quick_analyzer.go
+package system
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// QuickAnalyzer gathers only the OS, shell, and directories. It never enumerates packages,
+// commands, SSH hosts, or project files, so it returns at once even on slow network home
+// directories, at the cost of a less informed oracle.
+type QuickAnalyzer struct{}
+
+// NewQuickAnalyzer creates an analyzer for --no-system-scan quests
+func NewQuickAnalyzer() SystemAnalyzer {
+	return &QuickAnalyzer{}
+}
+
+func (q *QuickAnalyzer) AnalyzeSystem() (*Info, error) {
+	start := time.Now()
+	info := &Info{
+		OS:                runtime.GOOS,
+		PackageManagers:   make([]string, 0),
+		InstalledPackages: make([]string, 0),
+		AvailableCommands: make([]string, 0),
+		Quick:             true,
+	}
+	info.CurrentDir, _ = os.Getwd()
+	// The home directory is only used locally, e.g. to expand "~"; it is not sent in a quick quest
+	info.HomeDir, _ = os.UserHomeDir()
+
+	var analyzer Analyzer
+	err := analyzer.detectShell(info)
+	info.Timings = []PhaseTiming{{Phase: PhaseTotal, Duration: time.Since(start)}}
+	return info, err
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/reauth.go
+package system
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Reauthenticate asks the operating system to confirm that the person at the terminal is the
+// signed-in user, as it is set up to: the sudo password on Linux and BSD, the sudo password or
+// Touch ID on macOS (when sudo's PAM configuration allows pam_tid), and Windows Hello on Windows.
+// reason is shown in the prompt. The error is of the kind ErrNotAuthenticated when the user could
+// not be confirmed, including when the system has no way to ask.
+func Reauthenticate(reason string) error {
+	return reauthenticate(reason)
+}
+
+// ReauthenticationMethod names how Reauthenticate asks on this system, for messages
+func ReauthenticationMethod() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows Hello"
+	case "darwin":
+		return "your password or Touch ID"
+	default:
+		return "your sudo password"
+	}
+}
+
+// notAuthenticated describes why the user could not be confirmed
+func notAuthenticated(format string, args ...any) error {
+	return &kindError{kind: ErrNotAuthenticated, err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// reauthenticate has sudo ask for the password afresh, ignoring credentials it cached earlier.
+// On macOS sudo offers Touch ID instead when pam_tid is enabled in /etc/pam.d/sudo_local.
+func reauthenticate(reason string) error {
+	if os.Geteuid() == 0 {
+		return notAuthenticated("the knight runs as root, where sudo asks for no password; re-authentication cannot confirm who is at the terminal")
+	}
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return notAuthenticated("re-authentication asks through sudo, which is not installed")
+	}
+	// A NOPASSWD rule lets sudo through without asking, which would confirm nothing
+	if exec.Command("sudo", "-k", "-n", "true").Run() == nil {
+		return notAuthenticated("sudo is set up to grant root without a password here, so it cannot confirm who is at the terminal")
+	}
+
+	// sudo expands %-escapes in its prompt
+	prompt := "🔐 " + strings.ReplaceAll(reason, "%", "%%") + " Password for %p: "
+	cmd := exec.Command("sudo", "-k", "-v", "-p", prompt)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return notAuthenticated("sudo could not confirm who is at the terminal: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// windowsHelloScript asks Windows Hello (PIN, fingerprint, or face) to verify the user through
+// the UserConsentVerifier API. It prints the verifier's answer and exits 0 only when verified.
+const windowsHelloScript = `
+Add-Type -AssemblyName System.Runtime.WindowsRuntime
+$asTask = [System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object { $_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -eq 'IAsyncOperation` + "`" + `1' } | Select-Object -First 1
+function Await($operation, $type) {
+  $task = $asTask.MakeGenericMethod($type).Invoke($null, @($operation))
+  $task.Wait(-1) | Out-Null
+  $task.Result
+}
+$verifier = [Windows.Security.Credentials.UI.UserConsentVerifier, Windows.Security.Credentials.UI, ContentType = WindowsRuntime]
+$availability = Await ($verifier::CheckAvailabilityAsync()) ([Windows.Security.Credentials.UI.UserConsentVerifierAvailability])
+if ($availability -ne 'Available') { Write-Output "Unavailable:$availability"; exit 1 }
+$result = Await ($verifier::RequestVerificationAsync($env:EMW_REAUTH_REASON)) ([Windows.Security.Credentials.UI.UserConsentVerificationResult])
+Write-Output $result
+if ($result -eq 'Verified') { exit 0 } else { exit 1 }
+`
+
+// reauthenticate asks Windows Hello to verify the user
+func reauthenticate(reason string) error {
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-EncodedCommand", EncodePowerShellCommand(windowsHelloScript))
+	cmd.Env = append(cmd.Environ(), "EMW_REAUTH_REASON="+reason)
+	output, err := cmd.Output()
+	answer := strings.TrimSpace(string(output))
+	switch {
+	case err == nil:
+		return nil
+	case strings.HasPrefix(answer, "Unavailable:"):
+		return notAuthenticated("Windows Hello is not available (%s); set up a PIN, fingerprint, or face in Settings > Accounts > Sign-in options", strings.TrimPrefix(answer, "Unavailable:"))
+	case answer != "":
+		return notAuthenticated("Windows Hello did not verify you (%s)", answer)
+	default:
+		return notAuthenticated("Windows Hello could not be asked: %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/recipes.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MinRecipeConfidence is the confidence at which a recipe is preferred over asking the oracle
+const MinRecipeConfidence = 0.75
+
+// RecipeParamKind tells how a recipe parameter is found in an intent
+type RecipeParamKind string
+
+const (
+	RecipeParamDir    RecipeParamKind = "dir"    // an existing directory named in the intent
+	RecipeParamNumber RecipeParamKind = "number" // a whole number captured by the parameter's pattern
+)
+
+// RecipeParam is a value a recipe's command is filled with
+type RecipeParam struct {
+	Name    string
+	Kind    RecipeParamKind
+	Prompt  string         // asked when the intent does not give the value and there is no default
+	Pattern *regexp.Regexp // numbers: the first non-empty group is the value
+	Default string         // may refer to earlier parameters, e.g. "{{folder}}.tar.gz"
+}
+
+// RecipeVariant is a recipe's command for one shell family, used when the required commands are available
+type RecipeVariant struct {
+	Family   string
+	Requires []string
+	Command  string // {{name}} placeholders are replaced by the quoted parameter values
+}
+
+// Recipe is a vetted, parameterized command for an everyday task. Its result does not depend on
+// an oracle, so the same intent always gets the same command.
+type Recipe struct {
+	Name        string
+	Description string
+	Example     string     // an intent the recipe answers
+	Triggers    [][]string // every group must be matched by a word of the intent
+	Params      []RecipeParam
+	Variants    []RecipeVariant // the first one that fits the shell and commands is used
+}
+
+// recipeBlockers mark intents that ask for more than a recipe does, such as "find the largest
+// directories and delete them"
+var recipeBlockers = map[string]bool{
+	"and": true, "then": true, "but": true, "except": true, "excluding": true, "without": true, "not": true,
+	"delete": true, "remove": true, "upload": true, "send": true, "email": true, "script": true,
+	"every": true, "schedule": true, "cron": true, "remote": true, "server": true, "ssh": true,
+	"open": true, "edit": true, "move": true, "copy": true, "rename": true, "kill": true, "stop": true,
+}
+
+var (
+	countPattern = regexp.MustCompile(`(?i)\btop\s+(\d+)\b|\b(\d+)\s+(?:largest|biggest|heaviest)\b`)
+	daysPattern  = regexp.MustCompile(`(?i)\b(\d+)\s*days?\b`)
+
+	numberPattern = regexp.MustCompile(`^\d+$`)
+)
+
+// Recipes is the library of vetted recipes
+var Recipes = []Recipe{
+	{
+		Name:        "largest-dirs",
+		Description: "List the directories taking the most space",
+		Example:     "find the largest directories in ~/projects",
+		Triggers: [][]string{
+			{"largest", "biggest", "heaviest", "space", "usage"},
+			{"dir", "dirs", "directory", "directories", "folder", "folders", "subfolders"},
+		},
+		Params: []RecipeParam{
+			{Name: "dir", Kind: RecipeParamDir, Default: "."},
+			{Name: "count", Kind: RecipeParamNumber, Pattern: countPattern, Default: "10"},
+		},
+		Variants: []RecipeVariant{
+			{Family: ShellFamilyPOSIX, Command: "du -xhd 1 {{dir}} 2>/dev/null | sort -rh | head -n {{count}}"},
+			{Family: ShellFamilyPowerShell, Command: "Get-ChildItem -LiteralPath {{dir}} -Directory -Force | ForEach-Object { [pscustomobject]@{ SizeMB = [math]::Round((Get-ChildItem -LiteralPath $_.FullName -Recurse -File -Force -ErrorAction SilentlyContinue | Measure-Object -Property Length -Sum).Sum / 1MB, 1); Path = $_.FullName } } | Sort-Object SizeMB -Descending | Select-Object -First {{count}}"},
+		},
+	},
+	{
+		Name:        "largest-files",
+		Description: "List the files taking the most space",
+		Example:     "show the 20 largest files here",
+		Triggers: [][]string{
+			{"largest", "biggest", "heaviest"},
+			{"file", "files"},
+		},
+		Params: []RecipeParam{
+			{Name: "dir", Kind: RecipeParamDir, Default: "."},
+			{Name: "count", Kind: RecipeParamNumber, Pattern: countPattern, Default: "10"},
+		},
+		Variants: []RecipeVariant{
+			{Family: ShellFamilyPOSIX, Command: "find {{dir}} -xdev -type f -exec du -h {} + 2>/dev/null | sort -rh | head -n {{count}}"},
+			{Family: ShellFamilyPowerShell, Command: "Get-ChildItem -LiteralPath {{dir}} -Recurse -File -Force -ErrorAction SilentlyContinue | Sort-Object Length -Descending | Select-Object -First {{count}} FullName, @{Name='SizeMB'; Expression={[math]::Round($_.Length / 1MB, 1)}}"},
+		},
+	},
+	{
+		Name:        "tar-folder",
+		Description: "Pack a folder into a .tar.gz archive, showing progress",
+		Example:     "tar the photos folder with progress",
+		Triggers: [][]string{
+			{"tar", "targz", "tarball", "archive", "compress", "pack"},
+			{"folder", "dir", "directory"},
+		},
+		Params: []RecipeParam{
+			{Name: "folder", Kind: RecipeParamDir, Prompt: "Folder to archive"},
+			{Name: "archive", Default: "{{folder}}.tar.gz"},
+		},
+		Variants: []RecipeVariant{
+			{Family: ShellFamilyPOSIX, Requires: []string{"tar", "pv", "gzip"}, Command: `tar -cf - {{folder}} | pv -s "$(du -sk {{folder}} | cut -f1)K" | gzip > {{archive}}`},
+			{Family: ShellFamilyPOSIX, Requires: []string{"tar"}, Command: "tar -czvf {{archive}} {{folder}}"},
+			{Family: ShellFamilyPowerShell, Requires: []string{"tar"}, Command: "tar -czvf {{archive}} {{folder}}"},
+		},
+	},
+	{
+		Name:        "rotate-logs",
+		Description: "Compress .log files that have not changed for some days",
+		Example:     "rotate the logs in ./logs older than 14 days",
+		Triggers: [][]string{
+			{"rotate", "compress", "gzip", "archive"},
+			{"log", "logs", "logfiles"},
+		},
+		Params: []RecipeParam{
+			{Name: "dir", Kind: RecipeParamDir, Prompt: "Directory holding the logs"},
+			{Name: "days", Kind: RecipeParamNumber, Pattern: daysPattern, Default: "7"},
+		},
+		Variants: []RecipeVariant{
+			{Family: ShellFamilyPOSIX, Requires: []string{"gzip"}, Command: "find {{dir}} -type f -name '*.log' -mtime +{{days}} -exec gzip -- {} +"},
+			{Family: ShellFamilyPowerShell, Command: `Get-ChildItem -LiteralPath {{dir}} -Recurse -File -Filter *.log | Where-Object { $_.LastWriteTime -lt (Get-Date).AddDays(-{{days}}) } | ForEach-Object { Compress-Archive -LiteralPath $_.FullName -DestinationPath "$($_.FullName).zip"; Remove-Item -LiteralPath $_.FullName }`},
+		},
+	},
+	{
+		Name:        "listening-ports",
+		Description: "List the TCP ports that programs are listening on",
+		Example:     "which ports are open",
+		Triggers: [][]string{
+			{"listening", "open", "listen", "bound"},
+			{"ports", "sockets"},
+		},
+		Variants: []RecipeVariant{
+			{Family: ShellFamilyPOSIX, Requires: []string{"ss"}, Command: "ss -tlnp"},
+			{Family: ShellFamilyPOSIX, Requires: []string{"lsof"}, Command: "lsof -nP -iTCP -sTCP:LISTEN"},
+			{Family: ShellFamilyPowerShell, Command: "Get-NetTCPConnection -State Listen | Sort-Object LocalPort | Select-Object LocalAddress, LocalPort, OwningProcess"},
+		},
+	},
+}
+
+// RecipeMatch is a recipe chosen for an intent
+type RecipeMatch struct {
+	Recipe     *Recipe
+	Variant    *RecipeVariant
+	Confidence float64
+}
+
+// MatchRecipe classifies the intent against the library and returns the best recipe that has a
+// variant for the system. It returns nil when no recipe reaches MinRecipeConfidence, or when two
+// recipes fit equally well and the intent is ambiguous.
+func MatchRecipe(intent string, sysInfo *Info) *RecipeMatch {
+	words := intentWords(intent)
+	for i, word := range words {
+		words[i] = strings.Trim(word, ".-_")
+	}
+
+	var best *RecipeMatch
+	ambiguous := false
+	for i := range Recipes {
+		recipe := &Recipes[i]
+		confidence := RecipeConfidence(recipe, words)
+		if confidence < MinRecipeConfidence || (best != nil && confidence < best.Confidence) {
+			continue
+		}
+		variant := recipe.variantFor(sysInfo)
+		if variant == nil {
+			continue
+		}
+		if best != nil && confidence == best.Confidence {
+			ambiguous = true
+			continue
+		}
+		best, ambiguous = &RecipeMatch{Recipe: recipe, Variant: variant, Confidence: confidence}, false
+	}
+	if ambiguous {
+		return nil
+	}
+	return best
+}
+
+// RecipeConfidence is the share of the recipe's trigger groups the words match, halved when the
+// words ask for something more than the recipe does
+func RecipeConfidence(recipe *Recipe, words []string) float64 {
+	if len(recipe.Triggers) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, group := range recipe.Triggers {
+		if slices.ContainsFunc(words, func(word string) bool { return slices.Contains(group, word) }) {
+			matched++
+		}
+	}
+	confidence := float64(matched) / float64(len(recipe.Triggers))
+	if slices.ContainsFunc(words, func(word string) bool { return recipeBlockers[word] && !recipe.triggeredBy(word) }) {
+		confidence /= 2
+	}
+	return confidence
+}
+
+func (r *Recipe) triggeredBy(word string) bool {
+	for _, group := range r.Triggers {
+		if slices.Contains(group, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recipe) variantFor(sysInfo *Info) *RecipeVariant {
+	family := ShellFamily(sysInfo.Shell)
+	for i := range r.Variants {
+		variant := &r.Variants[i]
+		if variant.Family != family {
+			continue
+		}
+		if !slices.ContainsFunc(variant.Requires, func(command string) bool { return !slices.Contains(sysInfo.AvailableCommands, command) }) {
+			return variant
+		}
+	}
+	return nil
+}
+
+// FindRecipeParam returns the value of a parameter as given in the intent, or an empty string.
+// Directories are words of the intent that name an existing directory, relative to currentDir
+// or the home directory, and "home" names the home directory itself.
+func FindRecipeParam(param RecipeParam, intent, currentDir, homeDir string) string {
+	switch param.Kind {
+	case RecipeParamNumber:
+		for _, match := range param.Pattern.FindAllStringSubmatch(intent, -1) {
+			for _, group := range match[1:] {
+				if group != "" {
+					return group
+				}
+			}
+		}
+	case RecipeParamDir:
+		for _, word := range strings.Fields(intent) {
+			word = strings.Trim(word, "\"'`,;:?!()")
+			if word == "" {
+				continue
+			}
+			if dir := resolveRecipeDir(word, currentDir, homeDir); dir != "" {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+func resolveRecipeDir(word, currentDir, homeDir string) string {
+	switch {
+	case strings.EqualFold(word, "home") && homeDir != "":
+		return homeDir
+	case word == "~" && homeDir != "":
+		return homeDir
+	case strings.HasPrefix(word, "~/") && homeDir != "":
+		word = filepath.Join(homeDir, word[2:])
+	case !filepath.IsAbs(word):
+		// A plain word only names a directory that really is in the current one, and stays relative
+		path := filepath.Join(currentDir, word)
+		if info, err := os.Stat(path); err == nil && info.IsDir() && path != filepath.Clean(currentDir) {
+			return word
+		}
+		return ""
+	}
+	if info, err := os.Stat(word); err == nil && info.IsDir() {
+		return word
+	}
+	return ""
+}
+
+// BuildRecipeCommand fills the variant's command with the parameter values, quoted for the shell
+// family. Defaults that refer to other parameters are expanded with their unquoted values.
+func BuildRecipeCommand(variant *RecipeVariant, values map[string]string) string {
+	replacements := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		replacements = append(replacements, "{{"+name+"}}", quoteRecipeValue(variant.Family, value))
+	}
+	return strings.NewReplacer(replacements...).Replace(variant.Command)
+}
+
+// ExpandRecipeDefault fills a default such as "{{folder}}.tar.gz" with the base names of the values
+// resolved so far, so that an archive lands in the current directory
+func ExpandRecipeDefault(defaultValue string, values map[string]string) string {
+	replacements := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		if abs, err := filepath.Abs(value); err == nil {
+			value = abs
+		}
+		replacements = append(replacements, "{{"+name+"}}", filepath.Base(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(defaultValue)
+}
+
+func quoteRecipeValue(family, value string) string {
+	if family == ShellFamilyPowerShell && !numberPattern.MatchString(value) {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return shellQuote(value)
+}
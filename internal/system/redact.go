@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/redact.go
+package system
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces every secret removed from text
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials commonly printed by tools or pasted into intents.
+// The replacement keeps any leading key name so the redacted text still reads naturally.
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), redacted},
+	{regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{16,}`), redacted},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`), redacted},
+	{regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b|\bgithub_pat_[A-Za-z0-9_]{22,}`), redacted},
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`), redacted},
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), redacted},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`), redacted},
+	{regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/\-]{8,}=*`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)(\b[a-z_]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)["']?\s*[:=]\s*["']?)[^\s"',;]+`), "${1}" + redacted},
+	{regexp.MustCompile(`(\b[a-z][a-z0-9+.\-]*://[^\s:/@]+:)[^\s@/]+@`), "${1}" + redacted + "@"},
+}
+
+// Redactor removes secrets, known sensitive values, and the home directory from text
+// before it leaves the machine
+type Redactor struct {
+	homeDir  string
+	literals []string
+}
+
+// NewRedactor creates a redactor that also removes the given literal values (such as the
+// configured API key) and replaces the home directory with ~. Empty values are ignored.
+func NewRedactor(homeDir string, literals ...string) *Redactor {
+	r := &Redactor{homeDir: strings.TrimRight(homeDir, `/\`)}
+	for _, literal := range literals {
+		if strings.TrimSpace(literal) != "" {
+			r.literals = append(r.literals, literal)
+		}
+	}
+	return r
+}
+
+// Redact returns the text with every secret replaced by [REDACTED]
+func (r *Redactor) Redact(text string) string {
+	for _, literal := range r.literals {
+		text = strings.ReplaceAll(text, literal, redacted)
+	}
+	text = RedactSecrets(text)
+	if len(r.homeDir) > 1 {
+		text = strings.ReplaceAll(text, r.homeDir, "~")
+	}
+	return text
+}
+
+// RedactSecrets replaces credentials recognised by their shape with [REDACTED]
+func RedactSecrets(text string) string {
+	for _, secret := range secretPatterns {
+		text = secret.pattern.ReplaceAllString(text, secret.replacement)
+	}
+	return text
+}
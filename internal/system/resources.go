@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/resources.go
+package system
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// cgroupV1Unlimited is the smallest memory limit cgroup v1 reports when there is none
+// (the largest page-aligned 64-bit value, give or take the page size)
+const cgroupV1Unlimited = 1 << 60
+
+// ResourceLimits are the CPUs and memory a quest can actually use. Inside a container the
+// cgroup limits can be far below what the host has, and below what nproc reports.
+type ResourceLimits struct {
+	HostCPUs        int     // logical CPUs the process may run on
+	HostMemoryBytes int64   // total memory of the machine; 0 when unknown
+	CPUQuota        float64 // CPUs' worth of time the cgroup allows; 0 when unlimited
+	MemoryBytes     int64   // the cgroup memory limit; 0 when unlimited
+}
+
+// Limited reports whether a cgroup limits the CPUs or memory below the host's
+func (r ResourceLimits) Limited() bool {
+	return (r.CPUQuota > 0 && r.CPUQuota < float64(r.HostCPUs)) ||
+		(r.MemoryBytes > 0 && (r.HostMemoryBytes == 0 || r.MemoryBytes < r.HostMemoryBytes))
+}
+
+// EffectiveCPUs is the number of CPUs worth using for parallel work, at least one
+func (r ResourceLimits) EffectiveCPUs() int {
+	cpus := r.HostCPUs
+	if r.CPUQuota > 0 && r.CPUQuota < float64(cpus) {
+		cpus = int(math.Ceil(r.CPUQuota))
+	}
+	return max(cpus, 1)
+}
+
+// EffectiveMemoryBytes is the memory available to the process; 0 when unknown
+func (r ResourceLimits) EffectiveMemoryBytes() int64 {
+	if r.MemoryBytes > 0 && (r.HostMemoryBytes == 0 || r.MemoryBytes < r.HostMemoryBytes) {
+		return r.MemoryBytes
+	}
+	return r.HostMemoryBytes
+}
+
+// String describes the limits, e.g. "2 CPUs (cgroup quota of 1.5 CPUs on a 16-CPU host), 512 MiB
+// memory (cgroup limit; the host has 31.2 GiB)"
+func (r ResourceLimits) String() string {
+	if r.HostCPUs == 0 {
+		return "unknown"
+	}
+
+	cpus := fmt.Sprintf("%d CPUs", r.EffectiveCPUs())
+	if r.EffectiveCPUs() == 1 {
+		cpus = "1 CPU"
+	}
+	if r.CPUQuota > 0 && r.CPUQuota < float64(r.HostCPUs) {
+		cpus += fmt.Sprintf(" (cgroup quota of %s CPUs on a %d-CPU host; nproc reports the host's count)", strconv.FormatFloat(r.CPUQuota, 'f', -1, 64), r.HostCPUs)
+	}
+
+	memory := "memory unknown"
+	switch effective := r.EffectiveMemoryBytes(); {
+	case effective == 0:
+	case effective == r.MemoryBytes && r.HostMemoryBytes > 0:
+		memory = fmt.Sprintf("%s memory (cgroup limit; the host has %s)", formatBytes(effective), formatBytes(r.HostMemoryBytes))
+	case effective == r.MemoryBytes:
+		memory = fmt.Sprintf("%s memory (cgroup limit)", formatBytes(effective))
+	default:
+		memory = fmt.Sprintf("%s memory", formatBytes(effective))
+	}
+	return cpus + ", " + memory
+}
+
+func formatBytes(bytes int64) string {
+	const mib = 1 << 20
+	if bytes < 1<<30 {
+		return fmt.Sprintf("%d MiB", bytes/mib)
+	}
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+}
+
+// DetectResourceLimits reads the host's CPUs and memory and, on Linux, the limits of the cgroup
+// the knight runs in
+func DetectResourceLimits() ResourceLimits {
+	limits := ResourceLimits{HostCPUs: runtime.NumCPU()}
+	if runtime.GOOS != "linux" {
+		return limits
+	}
+
+	if meminfo, err := os.ReadFile("/proc/meminfo"); err == nil {
+		limits.HostMemoryBytes = ParseMemTotal(string(meminfo))
+	}
+	if selfCgroup, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		limits.CPUQuota, limits.MemoryBytes = CgroupLimits("/sys/fs/cgroup", string(selfCgroup))
+	}
+	return limits
+}
+
+func (a *Analyzer) detectResources(info *Info) error {
+	info.Resources = DetectResourceLimits()
+	return nil
+}
+
+// CgroupLimits returns the tightest CPU quota and memory limit of the cgroup described by
+// selfCgroup (the contents of /proc/self/cgroup) and its parents, under the cgroup filesystem
+// at root. Both cgroup v2 and v1 are understood; zero means unlimited.
+func CgroupLimits(root, selfCgroup string) (cpuQuota float64, memoryBytes int64) {
+	for _, line := range strings.Split(strings.TrimSpace(selfCgroup), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		controllers, cgroupPath := strings.Split(parts[1], ","), parts[2]
+
+		switch {
+		case parts[1] == "":
+			// cgroup v2: one hierarchy with every controller
+			for _, dir := range cgroupDirs(root, cgroupPath) {
+				cpuQuota = tighterLimit(cpuQuota, ParseCgroupCPUMax(readLimit(dir, "cpu.max")))
+				memoryBytes = tighterLimit(memoryBytes, ParseCgroupMemory(readLimit(dir, "memory.max")))
+			}
+		case slices.Contains(controllers, "cpu"):
+			for _, dir := range cgroupDirs(filepath.Join(root, parts[1]), cgroupPath) {
+				cpuQuota = tighterLimit(cpuQuota, ParseCgroupV1CPU(readLimit(dir, "cpu.cfs_quota_us"), readLimit(dir, "cpu.cfs_period_us")))
+			}
+		case slices.Contains(controllers, "memory"):
+			for _, dir := range cgroupDirs(filepath.Join(root, parts[1]), cgroupPath) {
+				memoryBytes = tighterLimit(memoryBytes, ParseCgroupMemory(readLimit(dir, "memory.limit_in_bytes")))
+			}
+		}
+	}
+	return cpuQuota, memoryBytes
+}
+
+// cgroupDirs returns the existing directories of the cgroup and its parents under root. Inside a
+// container the cgroup's own path is usually not mounted, and root holds the container's limits.
+func cgroupDirs(root, cgroupPath string) []string {
+	var dirs []string
+	for p := path.Clean("/" + cgroupPath); ; p = path.Dir(p) {
+		dir := filepath.Join(root, filepath.FromSlash(p))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+		if p == "/" {
+			return dirs
+		}
+	}
+}
+
+func readLimit(dir, file string) string {
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// tighterLimit returns the smaller of two limits, where zero means unlimited
+func tighterLimit[T int64 | float64](current, candidate T) T {
+	if candidate > 0 && (current == 0 || candidate < current) {
+		return candidate
+	}
+	return current
+}
+
+// ParseCgroupCPUMax reads a cgroup v2 cpu.max ("200000 100000", or "max 100000" when unlimited)
+// as a number of CPUs
+func ParseCgroupCPUMax(content string) float64 {
+	fields := strings.Fields(content)
+	if len(fields) != 2 {
+		return 0
+	}
+	return ParseCgroupV1CPU(fields[0], fields[1])
+}
+
+// ParseCgroupV1CPU reads the cgroup v1 CFS quota and period (quota -1 when unlimited) as a
+// number of CPUs
+func ParseCgroupV1CPU(quota, period string) float64 {
+	q, errQuota := strconv.ParseFloat(quota, 64)
+	p, errPeriod := strconv.ParseFloat(period, 64)
+	if errQuota != nil || errPeriod != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return math.Round(q/p*100) / 100
+}
+
+// ParseCgroupMemory reads a memory limit in bytes: memory.max of cgroup v2 ("max" when
+// unlimited) or memory.limit_in_bytes of cgroup v1 (a huge number when unlimited)
+func ParseCgroupMemory(content string) int64 {
+	limit, err := strconv.ParseInt(content, 10, 64)
+	if err != nil || limit <= 0 || limit >= cgroupV1Unlimited {
+		return 0
+	}
+	return limit
+}
+
+// ParseMemTotal reads the total memory in bytes from /proc/meminfo
+func ParseMemTotal(meminfo string) int64 {
+	for _, line := range strings.Split(meminfo, "\n") {
+		if value, ok := strings.CutPrefix(line, "MemTotal:"); ok {
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				return 0
+			}
+			if kb, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				return kb * 1024
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/schedule.go
+package system
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cronMarker starts the comment line above each crontab entry installed for a scheduled spell
+const cronMarker = "# execute-my-will: "
+
+var (
+	cronFieldPattern = regexp.MustCompile(`^[0-9*/,-]+$`)
+	clockPattern     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	everyNPattern    = regexp.MustCompile(`^every (\d+) (minute|hour)s?$`)
+)
+
+// weekdayNumbers maps the day names understood in a schedule to cron's day-of-week numbers
+var weekdayNumbers = map[string]string{
+	"sunday": "0", "monday": "1", "tuesday": "2", "wednesday": "3", "thursday": "4", "friday": "5", "saturday": "6",
+	"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+}
+
+// weekdayNames are the day names of systemd and the Task Scheduler, by cron number
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// Schedule is when a scheduled spell runs, held as the five fields of a cron expression
+type Schedule struct {
+	Text       string // as the user described it
+	Minute     string
+	Hour       string
+	DayOfMonth string
+	Month      string
+	DayOfWeek  string
+}
+
+// ParseSchedule reads a schedule described in words, such as "every day at 2am", "every 15
+// minutes", "weekdays at 18:00", or "every monday and friday at 9:30", or given as a five-field
+// cron expression
+func ParseSchedule(text string) (*Schedule, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	schedule := &Schedule{Text: text, Minute: "0", Hour: "0", DayOfMonth: "*", Month: "*", DayOfWeek: "*"}
+	unknown := fmt.Errorf("cannot understand the schedule \"%s\"; try \"every day at 2am\", \"every 15 minutes\", \"weekdays at 18:00\", or a cron expression such as \"0 2 * * *\"", text)
+
+	if fields := strings.Fields(lower); len(fields) == 5 && cronFieldPattern.MatchString(strings.Join(fields, "")) {
+		schedule.Minute, schedule.Hour, schedule.DayOfMonth, schedule.Month, schedule.DayOfWeek = fields[0], fields[1], fields[2], fields[3], fields[4]
+		return schedule, nil
+	}
+
+	switch lower {
+	case "every minute":
+		schedule.Minute, schedule.Hour = "*", "*"
+		return schedule, nil
+	case "every hour", "hourly":
+		schedule.Hour = "*"
+		return schedule, nil
+	}
+	if match := everyNPattern.FindStringSubmatch(lower); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		if match[2] == "minute" && n >= 1 && n < 60 {
+			schedule.Minute, schedule.Hour = "*/"+match[1], "*"
+			return schedule, nil
+		}
+		if match[2] == "hour" && n >= 1 && n < 24 {
+			schedule.Hour = "*/" + match[1]
+			return schedule, nil
+		}
+		return nil, unknown
+	}
+
+	days, at, timed := strings.Cut(lower, " at ")
+	if strings.HasPrefix(lower, "at ") {
+		// "at 2am every day"
+		at, days, _ = strings.Cut(strings.TrimPrefix(lower, "at "), " ")
+		timed = true
+	}
+	if timed {
+		hour, minute, ok := parseClock(strings.TrimSpace(at))
+		if !ok {
+			return nil, unknown
+		}
+		schedule.Hour, schedule.Minute = strconv.Itoa(hour), strconv.Itoa(minute)
+	}
+
+	days = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(days), "on "), "every ")
+	switch days {
+	case "day", "daily", "night", "":
+		if !timed && days == "" {
+			return nil, unknown
+		}
+	case "weekday", "weekdays":
+		schedule.DayOfWeek = "1-5"
+	case "weekend", "weekends":
+		schedule.DayOfWeek = "0,6"
+	case "week", "weekly":
+		schedule.DayOfWeek = "1"
+	case "month", "monthly":
+		schedule.DayOfMonth = "1"
+	default:
+		var numbers []string
+		for _, day := range strings.FieldsFunc(days, func(r rune) bool { return r == ' ' || r == ',' }) {
+			if day == "and" {
+				continue
+			}
+			number, ok := weekdayNumbers[strings.TrimSuffix(day, "s")]
+			if !ok {
+				number, ok = weekdayNumbers[day]
+			}
+			if !ok {
+				return nil, unknown
+			}
+			numbers = append(numbers, number)
+		}
+		if len(numbers) == 0 {
+			return nil, unknown
+		}
+		schedule.DayOfWeek = strings.Join(numbers, ",")
+	}
+	return schedule, nil
+}
+
+// parseClock reads a time of day such as "2am", "2:30 pm", "14:00", "noon", or "midnight"
+func parseClock(text string) (hour, minute int, ok bool) {
+	switch text {
+	case "noon", "midday":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+	match := clockPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// Cron returns the schedule as a cron expression
+func (s *Schedule) Cron() string {
+	return strings.Join([]string{s.Minute, s.Hour, s.DayOfMonth, s.Month, s.DayOfWeek}, " ")
+}
+
+// String describes the schedule for messages, as written and as a cron expression
+func (s *Schedule) String() string {
+	if s.Text == s.Cron() {
+		return s.Text
+	}
+	return fmt.Sprintf("%s (%s)", s.Text, s.Cron())
+}
+
+// OnCalendar returns the schedule as a systemd calendar event, e.g. "Mon..Fri *-*-* 18:00:00"
+func (s *Schedule) OnCalendar() string {
+	event := fmt.Sprintf("*-%s-%s %s:%s:00", calendarField(s.Month, "1"), calendarField(s.DayOfMonth, "1"), calendarField(s.Hour, "0"), calendarField(s.Minute, "0"))
+	if s.DayOfWeek == "*" {
+		return event
+	}
+	var days strings.Builder
+	for _, r := range s.DayOfWeek {
+		switch {
+		case r >= '0' && r <= '7':
+			days.WriteString(weekdayNames[(r-'0')%7])
+		case r == '-':
+			days.WriteString("..")
+		default:
+			days.WriteRune(r)
+		}
+	}
+	return days.String() + " " + event
+}
+
+// calendarField turns a cron field into its systemd form: steps start from first, and ranges
+// are written with ".."
+func calendarField(field, first string) string {
+	if rest, ok := strings.CutPrefix(field, "*/"); ok {
+		return first + "/" + rest
+	}
+	return strings.ReplaceAll(field, "-", "..")
+}
+
+// TaskSchedulerArgs returns the schtasks /Create arguments for the schedule. The Task Scheduler
+// only follows the kinds of schedule that can be described in words, not every cron expression.
+func (s *Schedule) TaskSchedulerArgs() ([]string, error) {
+	unsupported := fmt.Errorf("the Task Scheduler cannot follow \"%s\"; describe it in words, e.g. \"every day at 2am\"", s.Text)
+	if s.Month != "*" {
+		return nil, unsupported
+	}
+	at := func() ([]string, bool) {
+		hour, hourErr := strconv.Atoi(s.Hour)
+		minute, minuteErr := strconv.Atoi(s.Minute)
+		return []string{"/ST", fmt.Sprintf("%02d:%02d", hour, minute)}, hourErr == nil && minuteErr == nil
+	}
+
+	switch {
+	case s.DayOfMonth != "*":
+		start, ok := at()
+		if _, err := strconv.Atoi(s.DayOfMonth); err != nil || !ok || s.DayOfWeek != "*" {
+			return nil, unsupported
+		}
+		return append([]string{"/SC", "MONTHLY", "/D", s.DayOfMonth}, start...), nil
+	case s.Hour == "*" && s.Minute == "*":
+		return []string{"/SC", "MINUTE", "/MO", "1"}, nil
+	case s.Hour == "*" && strings.HasPrefix(s.Minute, "*/"):
+		return []string{"/SC", "MINUTE", "/MO", strings.TrimPrefix(s.Minute, "*/")}, nil
+	case s.Hour == "*" || strings.HasPrefix(s.Hour, "*/"):
+		minute, err := strconv.Atoi(s.Minute)
+		if err != nil || s.DayOfWeek != "*" {
+			return nil, unsupported
+		}
+		every := strings.TrimPrefix(strings.TrimPrefix(s.Hour, "*"), "/")
+		if every == "" {
+			every = "1"
+		}
+		return []string{"/SC", "HOURLY", "/MO", every, "/ST", fmt.Sprintf("00:%02d", minute)}, nil
+	}
+
+	start, ok := at()
+	if !ok {
+		return nil, unsupported
+	}
+	if s.DayOfWeek == "*" {
+		return append([]string{"/SC", "DAILY"}, start...), nil
+	}
+	var days []string
+	for _, day := range expandDayOfWeek(s.DayOfWeek) {
+		if day < 0 || day > 6 {
+			return nil, unsupported
+		}
+		days = append(days, strings.ToUpper(weekdayNames[day]))
+	}
+	if len(days) == 0 {
+		return nil, unsupported
+	}
+	return append([]string{"/SC", "WEEKLY", "/D", strings.Join(days, ",")}, start...), nil
+}
+
+// expandDayOfWeek lists the day numbers of a cron day-of-week field made of numbers, lists, and
+// ranges, or nil for anything else
+func expandDayOfWeek(field string) []int {
+	var days []int
+	for _, part := range strings.Split(field, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return nil
+			}
+		}
+		for day := first; day <= last; day++ {
+			days = append(days, day%7)
+		}
+	}
+	return days
+}
+
+// CronEntry returns the crontab lines that run command on the schedule, under a marker naming
+// the spell so that the entry can be found again
+func CronEntry(name string, schedule *Schedule, command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		// cron turns an unescaped % into a newline
+		quoted[i] = strings.ReplaceAll(shellQuote(arg), "%", `\%`)
+	}
+	return fmt.Sprintf("%s%s\n%s %s\n", cronMarker, name, schedule.Cron(), strings.Join(quoted, " "))
+}
+
+// AddCronEntry returns the crontab with the entry for the named spell, replacing any earlier one
+func AddCronEntry(crontab, name, entry string) string {
+	crontab, _ = RemoveCronEntry(crontab, name)
+	if crontab != "" && !strings.HasSuffix(crontab, "\n") {
+		crontab += "\n"
+	}
+	return crontab + entry
+}
+
+// RemoveCronEntry returns the crontab without the entry for the named spell, and whether there
+// was one
+func RemoveCronEntry(crontab, name string) (string, bool) {
+	lines := strings.SplitAfter(crontab, "\n")
+	var kept []string
+	found := false
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") == cronMarker+name {
+			found = true
+			i++ // the entry follows its marker
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return strings.Join(kept, ""), found
+}
+
+// SystemdUnits returns the service and timer units that run command on the schedule
+func SystemdUnits(name string, schedule *Schedule, command []string) (service, timer string) {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = strconv.Quote(arg)
+	}
+	service = fmt.Sprintf("[Unit]\nDescription=execute-my-will spell %s\n\n[Service]\nType=oneshot\nExecStart=%s\n", name, strings.Join(quoted, " "))
+	timer = fmt.Sprintf("[Unit]\nDescription=Run the execute-my-will spell %s %s\n\n[Timer]\nOnCalendar=%s\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n", name, schedule.Text, schedule.OnCalendar())
+	return service, timer
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/scheduler.go
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// The system schedulers a spell can be scheduled with
+const (
+	SchedulerCron    = "cron"
+	SchedulerSystemd = "systemd"
+	SchedulerTask    = "task" // the Windows Task Scheduler
+)
+
+// Schedulers lists the supported system schedulers
+var Schedulers = []string{SchedulerCron, SchedulerSystemd, SchedulerTask}
+
+// scheduledUnitPrefix names the systemd units and scheduled tasks of scheduled spells
+const scheduledUnitPrefix = "execute-my-will-"
+
+// DefaultScheduler picks the scheduler of the platform: the Task Scheduler on Windows, otherwise
+// cron when crontab is installed, and a systemd user timer when only systemctl is
+func DefaultScheduler() string {
+	if runtime.GOOS == "windows" {
+		return SchedulerTask
+	}
+	if _, err := exec.LookPath("crontab"); err != nil {
+		if _, err := exec.LookPath("systemctl"); err == nil {
+			return SchedulerSystemd
+		}
+	}
+	return SchedulerCron
+}
+
+// NewScheduler returns the system scheduler of the given kind
+func NewScheduler(kind string) (Scheduler, error) {
+	program := map[string]string{SchedulerCron: "crontab", SchedulerSystemd: "systemctl", SchedulerTask: "schtasks"}[kind]
+	if program == "" {
+		return nil, fmt.Errorf("unknown scheduler '%s'; choose one of %s", kind, strings.Join(Schedulers, ", "))
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return nil, &kindError{kind: ErrCommandNotFound, err: fmt.Errorf("%s is not installed, so the %s scheduler cannot be used", program, kind)}
+	}
+
+	switch kind {
+	case SchedulerSystemd:
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot find the systemd user unit directory: %w", err)
+		}
+		return &systemdScheduler{unitDir: filepath.Join(configDir, "systemd", "user")}, nil
+	case SchedulerTask:
+		return &taskScheduler{}, nil
+	}
+	return &cronScheduler{}, nil
+}
+
+// cronScheduler keeps scheduled spells in the user's crontab
+type cronScheduler struct{}
+
+func (c *cronScheduler) Install(name string, schedule *Schedule, command []string) error {
+	crontab, err := c.read()
+	if err != nil {
+		return err
+	}
+	return c.write(AddCronEntry(crontab, name, CronEntry(name, schedule, command)))
+}
+
+func (c *cronScheduler) Remove(name string) error {
+	crontab, err := c.read()
+	if err != nil {
+		return err
+	}
+	crontab, found := RemoveCronEntry(crontab, name)
+	if !found {
+		return nil
+	}
+	return c.write(crontab)
+}
+
+// read returns the user's crontab, or "" when they have none yet
+func (c *cronScheduler) read() (string, error) {
+	output, err := exec.Command("crontab", "-l").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(strings.ToLower(string(exitErr.Stderr)), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read the crontab: %w", err)
+	}
+	return string(output), nil
+}
+
+func (c *cronScheduler) write(crontab string) error {
+	cmd := exec.Command("crontab", "-")
+	cmd.Stdin = strings.NewReader(crontab)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write the crontab: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// systemdScheduler runs scheduled spells from systemd user timers
+type systemdScheduler struct {
+	unitDir string
+}
+
+func (s *systemdScheduler) Install(name string, schedule *Schedule, command []string) error {
+	if err := os.MkdirAll(s.unitDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.unitDir, err)
+	}
+	service, timer := SystemdUnits(name, schedule, command)
+	unit := scheduledUnitPrefix + name
+	if err := os.WriteFile(filepath.Join(s.unitDir, unit+".service"), []byte(service), 0644); err != nil {
+		return fmt.Errorf("failed to write the service unit: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.unitDir, unit+".timer"), []byte(timer), 0644); err != nil {
+		return fmt.Errorf("failed to write the timer unit: %w", err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", unit+".timer")
+}
+
+func (s *systemdScheduler) Remove(name string) error {
+	unit := scheduledUnitPrefix + name
+	timer := filepath.Join(s.unitDir, unit+".timer")
+	if _, err := os.Stat(timer); os.IsNotExist(err) {
+		return nil
+	}
+	if err := systemctl("disable", "--now", unit+".timer"); err != nil {
+		return err
+	}
+	for _, path := range []string{timer, filepath.Join(s.unitDir, unit+".service")} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return systemctl("daemon-reload")
+}
+
+// systemctl runs a systemctl command on the user's service manager
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// taskScheduler runs scheduled spells as tasks of the Windows Task Scheduler
+type taskScheduler struct{}
+
+func (t *taskScheduler) Install(name string, schedule *Schedule, command []string) error {
+	when, err := schedule.TaskSchedulerArgs()
+	if err != nil {
+		return err
+	}
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = arg
+		if strings.ContainsAny(arg, " \t") {
+			quoted[i] = `"` + arg + `"`
+		}
+	}
+	args := append([]string{"/Create", "/F", "/TN", scheduledUnitPrefix + name, "/TR", strings.Join(quoted, " ")}, when...)
+	if output, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks failed to create the task: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (t *taskScheduler) Remove(name string) error {
+	task := scheduledUnitPrefix + name
+	if err := exec.Command("schtasks", "/Query", "/TN", task).Run(); err != nil {
+		return nil
+	}
+	if output, err := exec.Command("schtasks", "/Delete", "/F", "/TN", task).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks failed to delete the task: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/script_format.go
+package system
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ShebangStyles are the ways a saved script can name its interpreter: "env" finds the shell on
+// the PATH (#!/usr/bin/env bash), "path" names the shell's absolute path (#!/bin/bash), and
+// "none" leaves the interpreter line out
+var ShebangStyles = []string{"env", "path", "none"}
+
+var (
+	// posixBackslashPath matches a relative or home path written with backslashes, e.g.
+	// .\build\out or $HOME\Documents. The first segment must be two characters or longer, so
+	// regular expressions such as '.\d' are left alone.
+	posixBackslashPath = regexp.MustCompile(`(^|[\s'"=(])((?:\.{1,2}|~|\$HOME|\$\{HOME\}|\$PWD)[/\\][\w.-]{2,}[\w.\-/\\]*)`)
+
+	// cmdSlashPath matches a relative or environment path written with forward slashes, e.g.
+	// ./build.bat or %USERPROFILE%/Documents. Switches such as /s do not start with a path root.
+	cmdSlashPath = regexp.MustCompile(`(^|[\s"=(])((?:\.{1,2}|%[A-Za-z_][A-Za-z0-9_]*%)[/\\][\w.-]{2,}[\w.\-/\\]*)`)
+
+	// cmdHomePath matches "~/", which cmd does not expand
+	cmdHomePath = regexp.MustCompile(`(^|[\s"=(])~[/\\]`)
+)
+
+// NormalizeScript fixes a generated script for the shell that will run it, since a model unsure
+// of the platform sometimes mixes conventions: carriage returns are dropped (bash reports them as
+// "$'\r': command not found"), relative and home paths use "/" for POSIX shells and "\" for
+// cmd, and "~/" becomes "%USERPROFILE%\" for cmd. PowerShell accepts both separators, so its
+// paths are kept. The result ends lines with "\n"; see ScriptLineEnding for the file on disk.
+func NormalizeScript(content, shell string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	switch ShellFamily(shell) {
+	case ShellFamilyPOSIX:
+		return replacePathSeparators(content, posixBackslashPath, `\`, "/")
+	case ShellFamilyCmd:
+		content = cmdHomePath.ReplaceAllString(content, `${1}%USERPROFILE%\`)
+		return replacePathSeparators(content, cmdSlashPath, "/", `\`)
+	}
+	return content
+}
+
+// ScriptLineEnding is the line ending a script file needs for the shell: batch files and
+// Windows PowerShell expect "\r\n", the others "\n"
+func ScriptLineEnding(shell string) string {
+	switch ShellFamily(shell) {
+	case ShellFamilyCmd, ShellFamilyPowerShell:
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// ScriptExtension is the file extension of a saved script for the shell
+func ScriptExtension(shell string) string {
+	switch ShellFamily(shell) {
+	case ShellFamilyCmd:
+		return ".bat"
+	case ShellFamilyPowerShell:
+		return ".ps1"
+	case ShellFamilyFish:
+		return ".fish"
+	case ShellFamilyNushell:
+		return ".nu"
+	}
+	return ".sh"
+}
+
+// ApplyShebang replaces the interpreter line of a script with one in the given style, or
+// removes it for "none". cmd and PowerShell do not read interpreter lines, so their scripts are
+// returned unchanged.
+func ApplyShebang(content, shell, style string) string {
+	family := ShellFamily(shell)
+	if family == ShellFamilyCmd || family == ShellFamilyPowerShell {
+		return content
+	}
+	if strings.HasPrefix(content, "#!") {
+		_, content, _ = strings.Cut(content, "\n")
+	}
+
+	name := strings.TrimSuffix(filepath.Base(shell), ".exe")
+	if shell == "" || name == "." || name == "/" {
+		name = "sh"
+	}
+	switch style {
+	case "env":
+		return "#!/usr/bin/env " + name + "\n" + content
+	case "path":
+		path := shell
+		if !filepath.IsAbs(path) {
+			path = "/bin/" + name
+		}
+		return "#!" + path + "\n" + content
+	}
+	return content
+}
+
+// replacePathSeparators rewrites the separators of the paths the pattern finds, keeping the
+// character before each path
+func replacePathSeparators(content string, pattern *regexp.Regexp, from, to string) string {
+	return pattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := pattern.FindStringSubmatch(match)
+		return parts[1] + strings.ReplaceAll(parts[2], from, to)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/shell.go
+package system
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Shell families share the same builtins and syntax for changing the session environment
+const (
+	ShellFamilyPOSIX      = "posix" // sh, bash, zsh, dash, ksh and friends
+	ShellFamilyFish       = "fish"
+	ShellFamilyPowerShell = "powershell"
+	ShellFamilyNushell    = "nushell"
+	ShellFamilyCmd        = "cmd"
+)
+
+// ShellFamily maps a shell name or path (as detected by the analyzer) to its family
+func ShellFamily(shell string) string {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(shell), ".exe"))
+
+	switch name {
+	case "fish":
+		return ShellFamilyFish
+	case "powershell", "pwsh":
+		return ShellFamilyPowerShell
+	case "nu", "nushell":
+		return ShellFamilyNushell
+	case "cmd":
+		return ShellFamilyCmd
+	default:
+		return ShellFamilyPOSIX
+	}
+}
+
+// EvalLine returns the line that runs execute-my-will --eval for the intent and evaluates the
+// command it prints in the current shell, or "" for shells that cannot evaluate one
+func EvalLine(shell, intent string) string {
+	switch ShellFamily(shell) {
+	case ShellFamilyPOSIX:
+		return fmt.Sprintf(`eval "$(execute-my-will --eval %s)"`, shellQuote(intent))
+	case ShellFamilyFish:
+		return fmt.Sprintf("eval (execute-my-will --eval %s | string collect)", fishQuote(intent))
+	case ShellFamilyPowerShell:
+		return fmt.Sprintf("Invoke-Expression (execute-my-will --eval %s | Out-String)", powerShellQuote(intent))
+	}
+	return ""
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// sessionMarker starts the line the session shell prints on stdout and stderr after each command.
+// The record separator keeps it apart from anything a command prints.
+const sessionMarker = "\x1eexecute-my-will-done:"
+
+// sessionLoop is the program of the session shell. It reads the path of a file to source from
+// fd 3, runs it in the shell itself so that its cd and exports last, and reports the exit status
+// and working directory. The trap keeps an interrupted command from ending the session; children
+// still get the default handler.
+const sessionLoop = `trap : INT
+while IFS= read -r __emw_file <&3; do
+  . "$__emw_file" 3<&-
+  __emw_status=$?
+  printf '\036execute-my-will-done:%s %s\n' "$__emw_status" "$PWD"
+  printf '\036execute-my-will-done:\n' >&2
+done
+`
+
+// ErrSessionEnded is returned when the session shell is no longer running, such as after an exit
+var ErrSessionEnded = errors.New("the session shell has ended")
+
+// ShellSession is a long-lived shell that carries out command after command, so that a cd, an
+// export, or an activated virtualenv lasts from one quest to the next
+type ShellSession struct {
+	shell   string
+	cmd     *exec.Cmd
+	control *os.File // the shell reads the file of each command from here
+	stdout  *os.File
+	stderr  *os.File
+	dir     string // the shell's working directory after the last command
+	tmpDir  string // holds the file of each command
+	runs    int
+	exited  chan struct{}
+}
+
+// StartShellSession starts a session in a POSIX shell, in the current directory, with env added
+// to its environment. Its commands read the terminal like those of any executor.
+func StartShellSession(shell string, env map[string]string) (*ShellSession, error) {
+	if ShellFamily(shell) != ShellFamilyPOSIX {
+		return nil, fmt.Errorf("a session needs a POSIX shell such as bash or zsh, and %s is not one", shell)
+	}
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		return nil, &kindError{kind: ErrCommandNotFound, err: fmt.Errorf("the shell %s was not found", shell)}
+	}
+	tmpDir, err := os.MkdirTemp("", "execute-my-will-session-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the session directory: %w", err)
+	}
+
+	// Pipes of our own, unlike StdoutPipe, stay readable until everything the shell wrote is read
+	var files []*os.File
+	pipe := func() (*os.File, *os.File) {
+		r, w, pipeErr := os.Pipe()
+		if pipeErr != nil {
+			err = pipeErr
+			return nil, nil
+		}
+		files = append(files, r, w)
+		return r, w
+	}
+	controlRead, controlWrite := pipe()
+	stdoutRead, stdoutWrite := pipe()
+	stderrRead, stderrWrite := pipe()
+	if err != nil {
+		for _, f := range files {
+			f.Close()
+		}
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to create the session pipes: %w", err)
+	}
+
+	cmd := exec.Command(path, "-c", sessionLoop)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout, cmd.Stderr = stdoutWrite, stderrWrite
+	cmd.ExtraFiles = []*os.File{controlRead}
+	if len(env) > 0 {
+		cmd.Env = MergeEnvironment(os.Environ(), env)
+	}
+	startErr := cmd.Start()
+	// The shell holds its own copies of these ends
+	controlRead.Close()
+	stdoutWrite.Close()
+	stderrWrite.Close()
+	if startErr != nil {
+		controlWrite.Close()
+		stdoutRead.Close()
+		stderrRead.Close()
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to start the session shell: %w", startErr)
+	}
+
+	dir, _ := os.Getwd()
+	s := &ShellSession{
+		shell:   path,
+		cmd:     cmd,
+		control: controlWrite,
+		stdout:  stdoutRead,
+		stderr:  stderrRead,
+		dir:     dir,
+		tmpDir:  tmpDir,
+		exited:  make(chan struct{}),
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(s.exited)
+	}()
+	return s, nil
+}
+
+// Shell returns the path of the session's shell
+func (s *ShellSession) Shell() string {
+	return s.shell
+}
+
+// Dir returns the session shell's working directory after the last command
+func (s *ShellSession) Dir() string {
+	return s.dir
+}
+
+// Alive reports whether the session shell is still running
+func (s *ShellSession) Alive() bool {
+	select {
+	case <-s.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Run carries out content in the session shell, copying what it prints to stdout and stderr, and
+// returns its exit status. An interrupt at the terminal stops the command, not the knight.
+func (s *ShellSession) Run(content string, stdout, stderr io.Writer) (int, error) {
+	if !s.Alive() {
+		return -1, ErrSessionEnded
+	}
+	s.runs++
+	file := filepath.Join(s.tmpDir, fmt.Sprintf("command_%d.sh", s.runs))
+	if err := os.WriteFile(file, []byte(content+"\n"), 0600); err != nil {
+		return -1, fmt.Errorf("failed to write the command for the session: %w", err)
+	}
+	defer os.Remove(file)
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, syscall.SIGINT)
+	defer signal.Stop(interrupts)
+
+	if _, err := fmt.Fprintln(s.control, file); err != nil {
+		return -1, ErrSessionEnded
+	}
+
+	marker := []byte(sessionMarker)
+	stderrDone := make(chan error, 1)
+	go func() {
+		_, err := streamUntilMarker(s.stderr, stderr, marker)
+		stderrDone <- err
+	}()
+	report, err := streamUntilMarker(s.stdout, stdout, marker)
+	stderrErr := <-stderrDone
+	if err != nil || stderrErr != nil {
+		// The command ended the shell, e.g. with exit; it is given a moment to be reaped so
+		// that Alive reports it
+		select {
+		case <-s.exited:
+		case <-time.After(time.Second):
+		}
+		return -1, ErrSessionEnded
+	}
+
+	status, dir, _ := strings.Cut(report, " ")
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return -1, fmt.Errorf("the session shell reported an unreadable status %q", status)
+	}
+	if dir != "" {
+		s.dir = dir
+	}
+	return code, nil
+}
+
+// Close ends the session shell, killing it when it does not leave within a few seconds
+func (s *ShellSession) Close() error {
+	s.control.Close()
+	select {
+	case <-s.exited:
+	case <-time.After(3 * time.Second):
+		_ = s.cmd.Process.Kill()
+		<-s.exited
+	}
+	s.stdout.Close()
+	s.stderr.Close()
+	return os.RemoveAll(s.tmpDir)
+}
+
+// streamUntilMarker copies r to w as it arrives until the marker line, which may follow output
+// that does not end in a newline. It returns the rest of the marker line.
+func streamUntilMarker(r io.Reader, w io.Writer, marker []byte) (string, error) {
+	var pending []byte
+	atLineStart := true
+	write := func(data []byte) {
+		if len(data) > 0 {
+			w.Write(data)
+			atLineStart = data[len(data)-1] == '\n'
+		}
+	}
+	buf := make([]byte, 32<<10)
+	for {
+		if i := bytes.Index(pending, marker); i >= 0 {
+			write(pending[:i])
+			pending = append(pending[:0], pending[i:]...)
+			if end := bytes.IndexByte(pending, '\n'); end >= 0 {
+				if !atLineStart {
+					w.Write([]byte("\n"))
+				}
+				return string(pending[len(marker):end]), nil
+			}
+		} else if safe := len(pending) - partialMarker(pending, marker); safe > 0 {
+			// Output is passed on at once, except a tail that may be the start of the marker
+			write(pending[:safe])
+			pending = append(pending[:0], pending[safe:]...)
+		}
+
+		n, err := r.Read(buf)
+		pending = append(pending, buf[:n]...)
+		if err != nil && n == 0 {
+			write(pending)
+			return "", err
+		}
+	}
+}
+
+// partialMarker returns the length of the longest end of data that starts the marker
+func partialMarker(data, marker []byte) int {
+	for k := min(len(marker)-1, len(data)); k > 0; k-- {
+		if bytes.HasSuffix(data, marker[:k]) {
+			return k
+		}
+	}
+	return 0
+}
+
+// NewSessionExecutor creates an executor that carries out every command in the session shell,
+// where a cd or an export lasts for the commands after it. Scripts run as a child of the
+// session: they start in its directory with its exported variables, but their own changes end
+// with them.
+func NewSessionExecutor(session *ShellSession) CommandExecutor {
+	return &Executor{session: session}
+}
+
+// runInSession carries out content in the executor's session with the same highlighted output,
+// log, and errors as a command run on its own
+func (e *Executor) runInSession(content string, timestamps bool) error {
+	highlighter := e.newHighlighter(timestamps)
+	logFile := e.startOutputLog(highlighter)
+
+	stdoutRead, stdoutWrite := io.Pipe()
+	stderrRead, stderrWrite := io.Pipe()
+	done := make(chan error, 2)
+	go func() {
+		done <- highlighter.StreamOutput(stdoutRead, e.outputPrefix())
+	}()
+	go func() {
+		done <- highlighter.StreamOutput(stderrRead, e.outputPrefix())
+	}()
+
+	code, err := e.session.Run(content, stdoutWrite, stderrWrite)
+	stdoutWrite.Close()
+	stderrWrite.Close()
+	for i := 0; i < 2; i++ {
+		if streamErr := <-done; streamErr != nil {
+			ui.PrintWarningMessage(fmt.Sprintf("Stream error: %v", streamErr))
+		}
+	}
+	e.finishOutputLog(logFile, highlighter)
+	ui.PrintSeparator()
+
+	if err == nil && code != 0 {
+		err = fmt.Errorf("exit status %d", code)
+	}
+	if err != nil {
+		return &ExecutionError{Err: err, ExitCode: code, Output: strings.Join(highlighter.Tail(), "\n")}
+	}
+	return nil
+}
+
+// runScriptInSession saves the script next to the session's commands and runs it with the
+// session's shell
+func (e *Executor) runScriptInSession(script string) error {
+	file := filepath.Join(e.session.tmpDir, fmt.Sprintf("script_%d.sh", e.session.runs+1))
+	if err := os.WriteFile(file, []byte(script), 0700); err != nil {
+		return fmt.Errorf("failed to write script file: %v", err)
+	}
+	defer os.Remove(file)
+	return e.runInSession(shellQuote(e.session.shell)+" "+shellQuote(file), true)
+}
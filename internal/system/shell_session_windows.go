@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrSessionEnded is returned when the session shell is no longer running, such as after an exit
+var ErrSessionEnded = errors.New("the session shell has ended")
+
+// errSessionUnsupported is returned on Windows, where sessions are not available yet
+var errSessionUnsupported = errors.New("sessions in a long-lived shell are not available on Windows yet")
+
+// ShellSession is a long-lived shell that carries out command after command; Windows has none yet
+type ShellSession struct{}
+
+// StartShellSession is not available on Windows yet
+func StartShellSession(shell string, env map[string]string) (*ShellSession, error) {
+	return nil, errSessionUnsupported
+}
+
+// Shell returns the path of the session's shell
+func (s *ShellSession) Shell() string { return "" }
+
+// Dir returns the session shell's working directory after the last command
+func (s *ShellSession) Dir() string { return "" }
+
+// Alive reports whether the session shell is still running
+func (s *ShellSession) Alive() bool { return false }
+
+// Run is not available on Windows yet
+func (s *ShellSession) Run(content string, stdout, stderr io.Writer) (int, error) {
+	return -1, errSessionUnsupported
+}
+
+// Close does nothing on Windows
+func (s *ShellSession) Close() error { return nil }
+
+// NewSessionExecutor returns the default executor on Windows, where sessions are not available
+func NewSessionExecutor(session *ShellSession) CommandExecutor {
+	return NewExecutor()
+}
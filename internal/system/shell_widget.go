@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/shell_widget.go
+package system
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WidgetShells lists the shells the prompt-line widget can be installed in
+var WidgetShells = []string{"zsh", "bash", "fish", "powershell"}
+
+// WidgetKey describes the key binding of the widget, the same in every shell
+const WidgetKey = "Ctrl-X Ctrl-W"
+
+// widgetHeader starts every widget script
+const widgetHeader = `# execute-my-will shell integration for %s, written by 'execute-my-will integrate'.
+# Type an intent on the prompt line and press Ctrl-X Ctrl-W: once checked, the proposed command
+# replaces it, to be reviewed, edited, and run with Enter like anything you type.
+`
+
+// WidgetShell returns the widget shell named by name or by a shell path, or "" when the widget
+// cannot be installed in it
+func WidgetShell(name string) string {
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(name), ".exe"))
+	switch base {
+	case "zsh", "bash", "fish", "powershell":
+		return base
+	case "pwsh":
+		return "powershell"
+	}
+	return ""
+}
+
+// WidgetFileName is the name of the file the widget script for the shell is kept in
+func WidgetFileName(shell string) string {
+	extension := map[string]string{"zsh": ".zsh", "bash": ".bash", "fish": ".fish", "powershell": ".ps1"}[shell]
+	return "widget" + extension
+}
+
+// ShellWidget returns the script that binds the widget in the shell. The widget runs binary with
+// the prompt line as the intent and --to-prompt, and replaces the line with the command written
+// to that file, leaving it unrun.
+func ShellWidget(shell, binary string) (string, error) {
+	header := fmt.Sprintf(widgetHeader, shell)
+	switch shell {
+	case "zsh":
+		return header + `_execute_my_will_widget() {
+  [[ -z "$BUFFER" ]] && return
+  local file
+  file=$(mktemp "${TMPDIR:-/tmp}/execute-my-will.XXXXXX") || return
+  zle -I
+  ` + shellQuote(binary) + ` --to-prompt "$file" -- "$BUFFER" </dev/tty
+  if [[ -s "$file" ]]; then
+    BUFFER=$(<"$file")
+    CURSOR=${#BUFFER}
+  fi
+  command rm -f -- "$file"
+  zle reset-prompt
+}
+zle -N _execute_my_will_widget
+bindkey '^X^W' _execute_my_will_widget
+`, nil
+	case "bash":
+		return header + `_execute_my_will_widget() {
+  [[ -z "$READLINE_LINE" ]] && return
+  local file
+  file=$(mktemp "${TMPDIR:-/tmp}/execute-my-will.XXXXXX") || return
+  ` + shellQuote(binary) + ` --to-prompt "$file" -- "$READLINE_LINE" </dev/tty
+  if [[ -s "$file" ]]; then
+    READLINE_LINE=$(<"$file")
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+  command rm -f -- "$file"
+}
+bind -x '"\C-x\C-w": _execute_my_will_widget'
+`, nil
+	case "fish":
+		return header + `function _execute_my_will_widget
+    set -l intent (commandline | string collect)
+    test -z "$intent"; and return
+    set -l file (mktemp)
+    or return
+    ` + fishQuote(binary) + ` --to-prompt $file -- "$intent" </dev/tty
+    if test -s $file
+        commandline -r -- (string collect < $file)
+        commandline -f end-of-buffer
+    end
+    command rm -f -- $file
+    commandline -f repaint
+end
+bind \cx\cw _execute_my_will_widget
+`, nil
+	case "powershell":
+		return header + `Set-PSReadLineKeyHandler -Chord 'Ctrl+x,Ctrl+w' -BriefDescription 'execute-my-will' -Description 'Replace the intent on the prompt line with the command execute-my-will proposes' -ScriptBlock {
+    $line = $null
+    $cursor = $null
+    [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+    if ([string]::IsNullOrWhiteSpace($line)) { return }
+    $file = New-TemporaryFile
+    & ` + powerShellQuote(binary) + ` --to-prompt $file.FullName '--' $line
+    $command = Get-Content -Raw -LiteralPath $file.FullName
+    Remove-Item -LiteralPath $file.FullName -ErrorAction SilentlyContinue
+    if ($command) {
+        [Microsoft.PowerShell.PSConsoleReadLine]::Replace(0, $line.Length, $command.TrimEnd())
+    }
+    [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+}
+`, nil
+	}
+	return "", fmt.Errorf("the prompt-line widget cannot be installed in '%s'; choose one of %s", shell, strings.Join(WidgetShells, ", "))
+}
+
+// WidgetProfile returns the startup file of the shell that should load the widget
+func WidgetProfile(shell, homeDir, goos string) string {
+	if shell != "powershell" {
+		return StartupFile(shell, homeDir, goos)
+	}
+	if homeDir == "" {
+		return ""
+	}
+	if goos == "windows" {
+		return filepath.Join(homeDir, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1")
+	}
+	return filepath.Join(homeDir, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
+}
+
+// WidgetSourceLine returns the startup file line that loads the widget script at path, doing
+// nothing once the script is gone
+func WidgetSourceLine(shell, path string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("test -f %s; and source %s", fishQuote(path), fishQuote(path))
+	case "powershell":
+		return fmt.Sprintf("if (Test-Path %s) { . %s }", powerShellQuote(path), powerShellQuote(path))
+	}
+	return fmt.Sprintf("[ -f %s ] && . %s", shellQuote(path), shellQuote(path))
+}
+
+// fishQuote quotes a word for fish, where only \ and ' are special inside single quotes
+func fishQuote(word string) string {
+	if safeShellWord.MatchString(word) {
+		return word
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(word) + "'"
+}
+
+// powerShellQuote quotes a word for PowerShell
+func powerShellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", "''") + "'"
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/spellbook.go
+package system
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SpellbookFile is the file a shared spellbook repository keeps its spells in
+	SpellbookFile = "spellbook.yaml"
+	// SpellbookStateFile keeps the synced shared spells and which of them were reviewed
+	SpellbookStateFile = "spellbook-state.yaml"
+	// TeamFunctionsFile is the library of shared spells reviewed on this machine
+	TeamFunctionsFile = "team-functions.sh"
+
+	// maxSpellbookSize caps the bytes read from a spellbook endpoint
+	maxSpellbookSize = 1 << 20
+)
+
+// The trust a shared spell's signature earns
+const (
+	SpellSigned       = "signed"        // signed by a listed key
+	SpellUnsigned     = "unsigned"      // carries no signature
+	SpellUnknownKey   = "unknown key"   // signed by a signer with no listed key
+	SpellBadSignature = "bad signature" // the signature does not match the spell
+)
+
+// SharedSpell is a spell of a team's spellbook: a POSIX shell script with its owner, optionally
+// signed with an Ed25519 key
+type SharedSpell struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Owner       string `yaml:"owner"`
+	Script      string `yaml:"script"`
+	Signer      string `yaml:"signer,omitempty"`
+	Signature   string `yaml:"signature,omitempty"` // base64 Ed25519 signature of SignedMessage
+}
+
+// Spellbook is the file a team shares its spells in
+type Spellbook struct {
+	Spells []SharedSpell `yaml:"spells"`
+}
+
+// ParseSpellbook reads a spellbook, refusing spells without a valid name, an owner, or a script,
+// and names used twice
+func ParseSpellbook(data []byte) (*Spellbook, error) {
+	var book Spellbook
+	if err := yaml.Unmarshal(data, &book); err != nil {
+		return nil, fmt.Errorf("failed to parse the spellbook: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, spell := range book.Spells {
+		if err := ValidateFunctionName(spell.Name); err != nil {
+			return nil, fmt.Errorf("the spellbook has an invalid spell: %w", err)
+		}
+		if strings.TrimSpace(spell.Owner) == "" || strings.TrimSpace(spell.Script) == "" {
+			return nil, fmt.Errorf("spell '%s' needs both an owner and a script", spell.Name)
+		}
+		if seen[spell.Name] {
+			return nil, fmt.Errorf("spell '%s' appears twice in the spellbook", spell.Name)
+		}
+		seen[spell.Name] = true
+	}
+	return &book, nil
+}
+
+// SignedMessage is what a spell's signature covers: its name, owner, and script
+func (s *SharedSpell) SignedMessage() []byte {
+	return []byte("execute-my-will spell\n" + s.Name + "\n" + s.Owner + "\n" + s.Script)
+}
+
+// Hash identifies the content of the spell, so that a changed spell is reviewed again
+func (s *SharedSpell) Hash() string {
+	sum := sha256.Sum256(s.SignedMessage())
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign signs the spell as signer
+func (s *SharedSpell) Sign(signer string, key ed25519.PrivateKey) {
+	s.Signer = signer
+	s.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, s.SignedMessage()))
+}
+
+// Verify checks the spell's signature against the listed public keys, by signer name
+func (s *SharedSpell) Verify(keys map[string]ed25519.PublicKey) string {
+	if s.Signature == "" {
+		return SpellUnsigned
+	}
+	key, ok := keys[s.Signer]
+	if !ok {
+		return SpellUnknownKey
+	}
+	signature, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil || !ed25519.Verify(key, s.SignedMessage(), signature) {
+		return SpellBadSignature
+	}
+	return SpellSigned
+}
+
+// ParsePublicKeys decodes base64 Ed25519 public keys, by signer name
+func ParsePublicKeys(encoded map[string]string) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(encoded))
+	for signer, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("the key of signer '%s' is not a base64 Ed25519 public key", signer)
+		}
+		keys[signer] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+// ParsePrivateKey decodes a base64 Ed25519 private key, as written by GenerateSigningKey
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("not a base64 Ed25519 private key")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// GenerateSigningKey returns a new key pair for signing spells, both base64 encoded
+func GenerateSigningKey() (public, private string, err error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate a signing key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(publicKey), base64.StdEncoding.EncodeToString(privateKey), nil
+}
+
+// FetchSpellbook reads the spellbook at source: an http(s) URL of the file, or a git repository
+// with spellbook.yaml at its root, which is cloned into cacheDir once and pulled after that
+func FetchSpellbook(source, cacheDir string) ([]byte, error) {
+	if isHTTPSpellbook(source) {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the spellbook: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch the spellbook: %s answered %s", source, resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpellbookSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the spellbook: %w", err)
+		}
+		return data, nil
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, &kindError{kind: ErrCommandNotFound, err: errors.New("git is not installed, so the spellbook repository cannot be fetched")}
+	}
+	clone := filepath.Join(cacheDir, "repository")
+	repo := &gitRepository{dir: clone}
+	// A clone of a source no longer configured is replaced
+	origin, _ := repo.git("remote", "get-url", "origin")
+	if strings.TrimSpace(origin) == source {
+		if _, err := repo.git("pull", "--ff-only", "-q"); err != nil {
+			return nil, fmt.Errorf("failed to pull the spellbook repository: %w", err)
+		}
+	} else {
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", cacheDir, err)
+		}
+		os.RemoveAll(clone)
+		if output, err := exec.Command("git", "clone", "-q", "--depth", "1", source, clone).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to clone the spellbook repository: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(clone, SpellbookFile))
+	if err != nil {
+		return nil, fmt.Errorf("the spellbook repository has no %s at its root", SpellbookFile)
+	}
+	return data, nil
+}
+
+// isHTTPSpellbook reports whether the source is a spellbook file served over HTTP rather than a
+// git repository, which may also be reached over https but ends in .git
+func isHTTPSpellbook(source string) bool {
+	lower := strings.ToLower(source)
+	return (strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")) && !strings.HasSuffix(strings.TrimSuffix(lower, "/"), ".git")
+}
+
+// SharedSpellState is a synced shared spell with the trust its signature earned
+type SharedSpellState struct {
+	SharedSpell `yaml:",inline"`
+	Trust       string `yaml:"trust"`
+}
+
+// SpellbookState is what this machine knows of the shared spellbook: the spells of the last sync
+// and the content of each spell as it was reviewed
+type SpellbookState struct {
+	Source   string             `yaml:"source"`
+	SyncedAt time.Time          `yaml:"synced_at"`
+	Spells   []SharedSpellState `yaml:"spells"`
+	Reviewed map[string]string  `yaml:"reviewed,omitempty"` // spell name to the hash of the content approved
+
+	path string
+}
+
+// LoadSpellbookState reads the spellbook state, returning an empty state when there is none yet
+func LoadSpellbookState(path string) (*SpellbookState, error) {
+	state := &SpellbookState{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the spellbook state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse the spellbook state: %w", err)
+	}
+	return state, nil
+}
+
+// Save writes the spellbook state, creating its directory if needed
+func (s *SpellbookState) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create spellbook directory: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the spellbook state: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Find returns the synced spell with the given name, or nil
+func (s *SpellbookState) Find(name string) *SharedSpellState {
+	for i := range s.Spells {
+		if s.Spells[i].Name == name {
+			return &s.Spells[i]
+		}
+	}
+	return nil
+}
+
+// IsReviewed reports whether the spell was approved on this machine as it is now
+func (s *SpellbookState) IsReviewed(spell *SharedSpell) bool {
+	return s.Reviewed[spell.Name] == spell.Hash()
+}
+
+// MarkReviewed records the spell as approved as it is now
+func (s *SpellbookState) MarkReviewed(spell *SharedSpell) {
+	if s.Reviewed == nil {
+		s.Reviewed = make(map[string]string)
+	}
+	s.Reviewed[spell.Name] = spell.Hash()
+}
+
+// Pending lists the synced spells not yet reviewed as they are now
+func (s *SpellbookState) Pending() []*SharedSpellState {
+	var pending []*SharedSpellState
+	for i := range s.Spells {
+		if !s.IsReviewed(&s.Spells[i].SharedSpell) {
+			pending = append(pending, &s.Spells[i])
+		}
+	}
+	return pending
+}
+
+// TeamLibrary returns the function library of the reviewed spells, with a header explaining how
+// to load it. Each spell becomes a function named after it, run in a subshell like a distilled one.
+func (s *SpellbookState) TeamLibrary(path string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Shared spells reviewed on this machine, written by 'execute-my-will spellbook'.\n# Synced from %s; edits are lost on the next sync.\n# Load them from your shell's rc file (e.g. ~/.bashrc or ~/.zshrc) with:\n#   . %s\n", s.Source, path)
+	for i := range s.Spells {
+		spell := &s.Spells[i]
+		if !s.IsReviewed(&spell.SharedSpell) {
+			continue
+		}
+		intent := spell.Description
+		if intent == "" {
+			intent = spell.Name
+		}
+		signed := "unsigned"
+		if spell.Trust == SpellSigned {
+			signed = "signed by " + spell.Signer
+		}
+		fmt.Fprintf(&b, "\n# %s\n# Shared by %s, %s\n%s", strings.ReplaceAll(intent, "\n", " "), spell.Owner, signed, functionBody(spell.Name, spell.Script))
+	}
+	return b.String()
+}
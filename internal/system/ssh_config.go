@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/ssh_config.go
+package system
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseSSHConfigHosts returns the concrete host aliases declared with "Host" in an ssh config.
+// Wildcard and negated patterns are skipped, and nothing but the alias names is read.
+func ParseSSHConfigHosts(r io.Reader) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Keywords are case-insensitive and may be separated from their value by "="
+		fields := strings.Fields(strings.Replace(line, "=", " ", 1))
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "host") {
+			continue
+		}
+
+		for _, alias := range fields[1:] {
+			alias = strings.Trim(alias, `"`)
+			if alias == "" || strings.ContainsAny(alias, "*?!") || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			hosts = append(hosts, alias)
+		}
+	}
+	return hosts
+}
+
+// LoadSSHHosts reads the host aliases from the user's ~/.ssh/config, if there is one
+func LoadSSHHosts() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	file, err := os.Open(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	return ParseSSHConfigHosts(file)
+}
+
+func (a *Analyzer) detectSSHHosts(info *Info) error {
+	info.SSHHosts = LoadSSHHosts()
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/startup_file.go
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// posixPersistentLine matches the lines of a POSIX shell that keep working in a startup file
+	posixPersistentLine = regexp.MustCompile(`^(export\s+[A-Za-z_][A-Za-z0-9_]*(=.*)?|alias\s+\S+=.+)$`)
+	// posixAssignment is a variable assignment without export, as in PATH="$HOME/bin:$PATH"
+	posixAssignment = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*=\S`)
+	// fishPersistentLine matches the lines of fish that keep working in config.fish
+	fishPersistentLine = regexp.MustCompile(`^(set\s+(-[a-zA-Z]+\s+)*[A-Za-z_][A-Za-z0-9_]*\s+.+|fish_add_path\s+.+|alias\s+\S+.+|abbr\s+.+)$`)
+	// echoIntoFile is a line already headed for a startup file, as in echo 'export X=1' >> ~/.bashrc.
+	// Double quotes only count without expansions, which would change the line on the way.
+	echoIntoFile = regexp.MustCompile("^echo\\s+(?:'([^']*)'|\"([^\"$`\\\\]*)\")\\s*>>\\s*(\\S+)$")
+)
+
+// StartupFile returns the file the shell reads at the start of every interactive session, where
+// exports and aliases last, or "" when the shell has none the knight knows how to edit
+func StartupFile(shell, homeDir, goos string) string {
+	if homeDir == "" {
+		return ""
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(homeDir, ".config")
+	}
+
+	switch ShellFamily(shell) {
+	case ShellFamilyFish:
+		return filepath.Join(configDir, "fish", "config.fish")
+	case ShellFamilyPOSIX:
+	default:
+		return ""
+	}
+
+	switch strings.ToLower(filepath.Base(shell)) {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc")
+		}
+		return filepath.Join(homeDir, ".zshrc")
+	case "bash":
+		// Terminals on macOS start login shells, which read .bash_profile and not .bashrc
+		if goos == "darwin" {
+			return filepath.Join(homeDir, ".bash_profile")
+		}
+		return filepath.Join(homeDir, ".bashrc")
+	case "ksh", "mksh":
+		return filepath.Join(homeDir, ".kshrc")
+	default:
+		return filepath.Join(homeDir, ".profile")
+	}
+}
+
+// PersistentEnvironmentLines returns the lines that make an environment command last when
+// appended to the shell's startup file: its exports, PATH changes, and aliases. A line the
+// command already echoes into a startup file is taken as it is, along with that file. It
+// returns no lines unless every step of the command can be kept this way, since the other
+// steps would be lost.
+func PersistentEnvironmentLines(command, shell string) (lines []string, target string) {
+	family := ShellFamily(shell)
+	for _, part := range SplitCommandChain(command) {
+		line := strings.TrimSpace(part.Command)
+		if match := echoIntoFile.FindStringSubmatch(line); match != nil {
+			if target != "" && target != match[3] {
+				return nil, ""
+			}
+			target = match[3]
+			line = match[1] + match[2]
+		}
+
+		switch {
+		case isSourcingStartupFile(line):
+			// Reloading the startup file only ever mattered to the current session
+			continue
+		case family == ShellFamilyPOSIX && posixPersistentLine.MatchString(line):
+		case family == ShellFamilyPOSIX && posixAssignment.MatchString(line):
+			line = "export " + line
+		case family == ShellFamilyFish && fishPersistentLine.MatchString(line):
+		default:
+			return nil, ""
+		}
+		lines = append(lines, line)
+	}
+	return lines, target
+}
+
+// isSourcingStartupFile reports whether a command reloads a shell startup file
+func isSourcingStartupFile(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) != 2 || (fields[0] != "source" && fields[0] != ".") {
+		return false
+	}
+	switch filepath.Base(fields[1]) {
+	case ".bashrc", ".zshrc", ".profile", ".bash_profile", ".kshrc", "config.fish":
+		return true
+	}
+	return false
+}
+
+// StartupFileEdit appends lines to a shell startup file. It is planned first, so that the diff
+// can be shown and approved before anything is written.
+type StartupFileEdit struct {
+	Path  string   // the startup file
+	Lines []string // the lines to append; those the file already has are left out
+	Diff  string   // the change in the format of 'diff -u'
+
+	original string
+	updated  string
+	exists   bool
+	mode     os.FileMode
+}
+
+// PlanStartupFileEdit works out how appending lines changes the startup file at path, which need
+// not exist yet. The lines are marked with a comment naming the knight and the date.
+func PlanStartupFileEdit(path string, lines []string, at time.Time) (*StartupFileEdit, error) {
+	edit := &StartupFileEdit{Path: path, mode: 0644}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		edit.original, edit.exists = string(data), true
+		if info, statErr := os.Stat(path); statErr == nil {
+			edit.mode = info.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(edit.original, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	for _, line := range lines {
+		if !present[strings.TrimSpace(line)] {
+			present[strings.TrimSpace(line)] = true
+			edit.Lines = append(edit.Lines, line)
+		}
+	}
+	if len(edit.Lines) == 0 {
+		return edit, nil
+	}
+
+	updated := edit.original
+	if updated != "" && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	if updated != "" {
+		updated += "\n"
+	}
+	updated += fmt.Sprintf("# Added by execute-my-will on %s\n%s\n", at.Format("2006-01-02"), strings.Join(edit.Lines, "\n"))
+	edit.updated = updated
+	edit.Diff = UnifiedDiff(path, path, edit.original, updated)
+	return edit, nil
+}
+
+// Apply writes the planned change, first copying an existing file to a timestamped backup next
+// to it. It returns the path of the backup, or "" when the file is new.
+func (e *StartupFileEdit) Apply(at time.Time) (string, error) {
+	if len(e.Lines) == 0 {
+		return "", nil
+	}
+
+	var backup string
+	if e.exists {
+		backup = fmt.Sprintf("%s.emw-backup-%s", e.Path, at.Format("20060102-150405"))
+		if err := os.WriteFile(backup, []byte(e.original), e.mode); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", e.Path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return backup, fmt.Errorf("failed to create %s: %w", filepath.Dir(e.Path), err)
+	}
+	if err := os.WriteFile(e.Path, []byte(e.updated), e.mode); err != nil {
+		return backup, fmt.Errorf("failed to write %s: %w", e.Path, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/stdin.go
+package system
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// maxStdinBytes caps what is read from stdin; a log longer than this is read no further
+const maxStdinBytes = 4 << 20
+
+// StdinPiped reports whether stdin is a pipe or a file rather than a terminal. /dev/null, as
+// given to cron jobs and services, counts as a terminal, so that nothing is read from it.
+func StdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// ReadStdin reads what was piped to stdin and then attaches stdin to the terminal, so that
+// confirmations and the commands run still read from the keyboard. Without a terminal stdin
+// is left empty.
+func ReadStdin() (string, error) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdinBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	// Closing the pipe stops a writer with more to send instead of leaving it blocked
+	os.Stdin.Close()
+	if os.Stdin, err = OpenTerminal(); err != nil {
+		os.Stdin, _ = os.Open(os.DevNull)
+	}
+	return string(data), nil
+}
+
+// OpenTerminal opens the terminal the knight was started from for reading
+func OpenTerminal() (*os.File, error) {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	return os.Open(name)
+}
+
+// TrimPiped normalizes piped text for a prompt like TrimClipboard, but keeps the end of text
+// beyond MaxClipboardChars, since a log or a command's output ends with how it failed. It
+// reports whether text was cut.
+func TrimPiped(text string) (string, bool) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Trim(text, "\n\r\t ")
+	runes := []rune(text)
+	if len(runes) <= MaxClipboardChars {
+		return text, false
+	}
+	return string(runes[len(runes)-MaxClipboardChars:]), true
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/timings.go
+package system
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Analysis phases that take long enough to be worth tuning
+const (
+	PhaseTotal    = "total"
+	PhasePackages = "installed packages"
+	PhaseCommands = "commands (PATH walk)"
+)
+
+// PackagePhase names the phase that lists the packages of one package manager
+func PackagePhase(manager string) string {
+	return "packages (" + manager + ")"
+}
+
+// PhaseTiming is how long one phase of the system analysis took
+type PhaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// analysisTask is one phase of the system analysis
+type analysisTask struct {
+	phase string
+	run   func() error
+}
+
+// phaseClock records the timings of phases that run concurrently
+type phaseClock struct {
+	mu      sync.Mutex
+	timings []PhaseTiming
+}
+
+// time runs task and records how long it took as phase
+func (c *phaseClock) time(phase string, task func() error) error {
+	start := time.Now()
+	err := task()
+	c.record(phase, time.Since(start))
+	return err
+}
+
+func (c *phaseClock) record(phase string, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timings = append(c.timings, PhaseTiming{Phase: phase, Duration: duration})
+}
+
+// slowestFirst returns the recorded timings, the slowest phase first
+func (c *phaseClock) slowestFirst() []PhaseTiming {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timings := append([]PhaseTiming(nil), c.timings...)
+	sort.SliceStable(timings, func(i, j int) bool { return timings[i].Duration > timings[j].Duration })
+	return timings
+}
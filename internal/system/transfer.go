@@ -0,0 +1,169 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/transfer.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	transferVerbPattern   = regexp.MustCompile(`(?i)\b(copy|send|transfer|sync|upload|download|back\s*up|backup|push|pull|move|fetch|mirror)\b`)
+	remoteHintPattern     = regexp.MustCompile(`(?i)\b(nas|server|remote|host|box|machine|vps|pi|raspberry|cloud)\b`)
+	downloadDirectionHint = regexp.MustCompile(`(?i)\b(download|fetch|pull)\b|\bfrom\s+(the\s+|my\s+)?(nas|server|remote|host|box|machine|vps|pi)\b`)
+	wordPattern           = regexp.MustCompile(`[A-Za-z0-9_.-]{3,}`)
+)
+
+// well-known folders that intents usually refer to by a plain word
+var homeFolderKeywords = map[string][]string{
+	"photos":    {"Pictures", "Photos"},
+	"pictures":  {"Pictures"},
+	"images":    {"Pictures"},
+	"documents": {"Documents"},
+	"docs":      {"Documents"},
+	"music":     {"Music"},
+	"songs":     {"Music"},
+	"videos":    {"Videos", "Movies"},
+	"movies":    {"Movies", "Videos"},
+	"downloads": {"Downloads"},
+	"desktop":   {"Desktop"},
+}
+
+// TransferDirection tells which way files travel in a transfer quest
+type TransferDirection int
+
+const (
+	TransferUpload TransferDirection = iota
+	TransferDownload
+)
+
+// IsTransferIntent reports whether the intent asks to move files between this machine and a remote host.
+// Mentioning a known host alias counts as naming a remote.
+func IsTransferIntent(intent string, hosts []string) bool {
+	if !transferVerbPattern.MatchString(intent) {
+		return false
+	}
+	return remoteHintPattern.MatchString(intent) || MentionedHost(intent, hosts) != ""
+}
+
+// GuessTransferDirection guesses whether the intent fetches from or sends to the remote host
+func GuessTransferDirection(intent string) TransferDirection {
+	if downloadDirectionHint.MatchString(intent) {
+		return TransferDownload
+	}
+	return TransferUpload
+}
+
+// MentionedHost returns the host alias named in the intent when exactly one is
+func MentionedHost(intent string, hosts []string) string {
+	words := make(map[string]bool)
+	for _, word := range wordPattern.FindAllString(strings.ToLower(intent), -1) {
+		words[word] = true
+	}
+
+	found := ""
+	for _, host := range hosts {
+		if words[strings.ToLower(host)] {
+			if found != "" {
+				return ""
+			}
+			found = host
+		}
+	}
+	return found
+}
+
+// FindTransferCandidates lists local paths the intent may refer to: entries of the current directory
+// whose names contain a word of the intent, and well-known home folders named by a keyword
+func FindTransferCandidates(intent, currentDir, homeDir string) []string {
+	words := wordPattern.FindAllString(strings.ToLower(intent), -1)
+	seen := make(map[string]bool)
+	var candidates []string
+
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		if _, err := os.Stat(path); err == nil {
+			seen[path] = true
+			candidates = append(candidates, path)
+		}
+	}
+
+	for _, word := range words {
+		for _, folder := range homeFolderKeywords[word] {
+			if homeDir != "" {
+				add(filepath.Join(homeDir, folder))
+			}
+		}
+	}
+
+	if entries, err := os.ReadDir(currentDir); err == nil {
+		var matches []string
+		for _, entry := range entries {
+			name := strings.ToLower(entry.Name())
+			for _, word := range words {
+				if strings.Contains(name, word) {
+					matches = append(matches, filepath.Join(currentDir, entry.Name()))
+					break
+				}
+			}
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	return candidates
+}
+
+// BuildTransferCommand builds an rsync (preferred) or scp command between a local path and host:remotePath
+func BuildTransferCommand(useRsync bool, direction TransferDirection, localPath, host, remotePath string, isDir bool) string {
+	local := shellQuote(localPath)
+	remote := host + ":" + shellQuoteRemote(remotePath)
+
+	src, dst := local, remote
+	if direction == TransferDownload {
+		src, dst = remote, local
+	}
+
+	if useRsync {
+		return "rsync -avh --progress " + src + " " + dst
+	}
+	if isDir || direction == TransferDownload {
+		return "scp -r " + src + " " + dst
+	}
+	return "scp " + src + " " + dst
+}
+
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,-]+$`)
+
+// shellQuote single-quotes a word for POSIX shells when it contains special characters
+func shellQuote(word string) string {
+	if safeShellWord.MatchString(word) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// shellQuoteRemote quotes a remote path while keeping a leading ~/ expandable on the remote side
+func shellQuoteRemote(path string) string {
+	if path == "~" || path == "" {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if rest == "" {
+			return "~/"
+		}
+		return "~/" + shellQuote(rest)
+	}
+	return shellQuote(path)
+}
@@ -13,6 +13,15 @@ import (
 	"strings"
 )
 
+// MissingPathError reports a path named in an intent that does not exist
+type MissingPathError struct {
+	Path string
+}
+
+func (e *MissingPathError) Error() string {
+	return fmt.Sprintf("the directory '%s' does not exist in your realm. Please specify an existing path or use specific directory names", e.Path)
+}
+
 type Validator struct {
 	sysInfo *Info
 }
@@ -57,7 +66,7 @@ func (v *Validator) validateDirectoryReferences(intent string) error {
 		if strings.Contains(word, "/") || strings.Contains(word, "\\") {
 			// Validate that the directory exists
 			if !v.pathExists(word) {
-				return fmt.Errorf("the directory '%s' does not exist in your realm. Please specify an existing path or use specific directory names", word)
+				return &MissingPathError{Path: word}
 			}
 		}
 	}
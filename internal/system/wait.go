@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/system/wait.go
+package system
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// WaitHelper is the command generated scripts call to wait for a condition, instead of a
+// fragile sleep loop. It is expanded to the knight's own 'wait' subcommand before execution.
+const WaitHelper = "emw-wait"
+
+// WaitCondition is what the wait helper polls for until it holds or the timeout passes. Exactly
+// one of Port, URL, and Process is set.
+type WaitCondition struct {
+	Port     string // "8080" on localhost, or "host:port", accepting TCP connections
+	URL      string // answering with a 2xx or 3xx status
+	Process  string // a running process with this name
+	Gone     bool   // wait until the condition no longer holds, e.g. a process has exited
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// Validate checks that the condition names exactly one thing to wait for
+func (c WaitCondition) Validate() error {
+	set := 0
+	for _, value := range []string{c.Port, c.URL, c.Process} {
+		if strings.TrimSpace(value) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("name exactly one of --port, --url, or --process to wait for")
+	}
+	if c.URL != "" && !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("the URL must start with http:// or https://: %s", c.URL)
+	}
+	if c.Timeout <= 0 || c.Interval <= 0 {
+		return fmt.Errorf("the timeout and interval must be positive")
+	}
+	return nil
+}
+
+// String describes the condition, e.g. "port localhost:80 to accept connections"
+func (c WaitCondition) String() string {
+	var what string
+	switch {
+	case c.Port != "":
+		what = fmt.Sprintf("port %s to accept connections", c.address())
+	case c.URL != "":
+		what = fmt.Sprintf("%s to answer", c.URL)
+	default:
+		what = fmt.Sprintf("process '%s' to be running", c.Process)
+	}
+	if c.Gone {
+		what = strings.NewReplacer("to accept connections", "to stop accepting connections", "to answer", "to stop answering", "to be running", "to exit").Replace(what)
+	}
+	return what
+}
+
+// address adds localhost to a bare port
+func (c WaitCondition) address() string {
+	if _, _, err := net.SplitHostPort(c.Port); err == nil {
+		return c.Port
+	}
+	return net.JoinHostPort("localhost", c.Port)
+}
+
+// Holds checks the condition once
+func (c WaitCondition) Holds() (bool, error) {
+	var holds bool
+	switch {
+	case c.Port != "":
+		conn, err := net.DialTimeout("tcp", c.address(), min(c.Interval, 5*time.Second))
+		if err == nil {
+			conn.Close()
+		}
+		holds = err == nil
+	case c.URL != "":
+		client := &http.Client{Timeout: min(c.Interval, 10*time.Second)}
+		resp, err := client.Get(c.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		holds = err == nil && resp.StatusCode < 400
+	default:
+		running, err := processRunning(c.Process)
+		if err != nil {
+			return false, err
+		}
+		holds = running
+	}
+	return holds != c.Gone, nil
+}
+
+// WaitFor polls the condition every interval until it holds. It returns an error naming the
+// condition when the timeout passes first.
+func WaitFor(c WaitCondition) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(c.Timeout)
+	for {
+		holds, err := c.Holds()
+		if err != nil {
+			return err
+		}
+		if holds {
+			return nil
+		}
+		if time.Now().Add(c.Interval).After(deadline) {
+			return fmt.Errorf("gave up waiting for %s after %s", c, c.Timeout)
+		}
+		time.Sleep(c.Interval)
+	}
+}
+
+// waitHelperCall matches the helper where a command starts: at the start of a line or after an
+// operator, pipe, or opening bracket
+var waitHelperCall = regexp.MustCompile(`(^|[\n;&|({])(\s*)` + regexp.QuoteMeta(WaitHelper) + `(\s|$)`)
+
+// ExpandWaitHelper replaces calls to the wait helper with the given binary's 'wait' subcommand,
+// quoted for the shell. Mentions of the helper elsewhere, such as in an echo, are left alone.
+func ExpandWaitHelper(content, shell, binary string) string {
+	if binary == "" || !strings.Contains(content, WaitHelper) {
+		return content
+	}
+
+	var call string
+	switch ShellFamily(shell) {
+	case ShellFamilyPowerShell:
+		call = "& '" + strings.ReplaceAll(binary, "'", "''") + "' wait"
+	case ShellFamilyCmd:
+		call = `"` + binary + `" wait`
+	default:
+		call = shellQuote(binary) + " wait"
+	}
+	return waitHelperCall.ReplaceAllStringFunc(content, func(match string) string {
+		m := waitHelperCall.FindStringSubmatch(match)
+		return m[1] + m[2] + call + m[3]
+	})
+}
+
+// WithWaitHelper makes the wait helper available to the commands and scripts the executor runs,
+// by expanding it to the given binary
+func WithWaitHelper(executor CommandExecutor, binary string) CommandExecutor {
+	if e, ok := executor.(*Executor); ok {
+		e.waitHelper = binary
+	}
+	return executor
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !windows
+// +build !windows
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// processRunning asks pgrep for a process with exactly this name
+func processRunning(name string) (bool, error) {
+	err := exec.Command("pgrep", "-x", name).Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to look for the process with pgrep: %w", err)
+	}
+}
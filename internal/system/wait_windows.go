@@ -0,0 +1,27 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// processRunning asks tasklist for a process with this image name; ".exe" may be left out
+func processRunning(name string) (bool, error) {
+	if !strings.HasSuffix(strings.ToLower(name), ".exe") {
+		name += ".exe"
+	}
+	output, err := exec.Command("tasklist", "/FI", "IMAGENAME eq "+name, "/NH", "/FO", "CSV").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to look for the process with tasklist: %w", err)
+	}
+	return strings.Contains(strings.ToLower(string(output)), `"`+strings.ToLower(name)+`"`), nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/tui/hooks.go
+package tui
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// Proposal is the command or script under review in the TUI
+type Proposal struct {
+	Intent   string
+	Response *ai.AIResponse
+	Content  string
+	IsScript bool
+}
+
+// kind names the proposal in messages
+func (p Proposal) kind() string {
+	if p.IsScript {
+		return "script"
+	}
+	return "command"
+}
+
+// Hooks connect the TUI to the checks of the command line's quests, which the tui package
+// cannot import. Any of them may be left nil.
+type Hooks struct {
+	// Risk rates a proposal as the command line does before confirmation
+	Risk func(p Proposal) system.RiskLevel
+	// Reauthenticate confirms who is at the terminal before a destructive quest runs, when
+	// execution.reauthenticate asks for it
+	Reauthenticate func(reason string) error
+	// Record writes down a proposal once it has run, for the journal and the history
+	Record func(p Proposal, runErr error)
+	// History holds the quests run before, to warn when a destructive one is proposed again
+	// within execution.repeat_warning_minutes
+	History *history.Store
+	// Now is the current time for the repeat warning; time.Now when nil
+	Now func() time.Time
+}
+
+// risk rates a proposal, as destructive when no Risk hook is set
+func (h Hooks) risk(p Proposal) system.RiskLevel {
+	if h.Risk == nil {
+		return system.RiskDestructive
+	}
+	return h.Risk(p)
+}
+
+// recentRun returns the history entry of the same destructive proposal run within the repeat
+// window, if any, and how long ago it ran
+func (h Hooks) recentRun(p Proposal, execution config.ExecutionConfig) (*history.Entry, time.Duration) {
+	window := execution.RepeatWindow()
+	if h.History == nil || p.Content == "" || window == 0 || h.risk(p) < system.RiskDestructive {
+		return nil, 0
+	}
+	now := time.Now()
+	if h.Now != nil {
+		now = h.Now()
+	}
+	entry := h.History.RecentRun(p.Content, now.Add(-window))
+	if entry == nil {
+		return nil, 0
+	}
+	return entry, now.Sub(entry.LastRun)
+}
+
+// reauthCommand runs re-authentication while the TUI has released the terminal, so that sudo or
+// Windows Hello can ask
+type reauthCommand struct {
+	reauthenticate func(reason string) error
+	out            io.Writer
+}
+
+func (c *reauthCommand) Run() error {
+	if c.out != nil {
+		fmt.Fprintf(c.out, "🔐 This quest is destructive, my lord. Confirm it is you with %s.\n", system.ReauthenticationMethod())
+	}
+	return c.reauthenticate("execute-my-will: confirm the destructive quest.")
+}
+
+func (c *reauthCommand) SetStdin(io.Reader)    {}
+func (c *reauthCommand) SetStdout(w io.Writer) { c.out = w }
+func (c *reauthCommand) SetStderr(io.Writer)   {}
@@ -0,0 +1,599 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/tui/model.go
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+// state describes which step of the quest the TUI is in
+type state int
+
+const (
+	stateInput state = iota
+	stateGenerating
+	stateReview
+	stateEditing
+	stateTypingDir
+	stateRunning
+	stateDone
+)
+
+// Messages exchanged with background work
+type (
+	responseMsg struct {
+		response *ai.AIResponse
+		err      error
+	}
+	explanationMsg struct {
+		text string
+		err  error
+	}
+	outputLineMsg    string
+	executionDoneMsg struct {
+		err error
+	}
+	reauthDoneMsg struct {
+		err error
+	}
+)
+
+// Model is the bubbletea model backing the full-screen quest view
+type Model struct {
+	cfg      *config.Config
+	sysInfo  *system.Info
+	aiClient ai.Client
+	hooks    Hooks
+
+	state       state
+	input       textinput.Model
+	output      viewport.Model
+	intent      string
+	response    *ai.AIResponse
+	proposal    string
+	explanation string
+	status      string
+	blocked     bool
+	criticalDir *system.CriticalDirectoryUse
+	warnings    []string
+	lines       []string
+	outputCh    chan string
+	cmd         *exec.Cmd
+	cmdMu       sync.Mutex
+	width       int
+	height      int
+}
+
+// NewModel creates the TUI model for an analyzed system and configured oracle. hooks supply the
+// command line's checks for approved proposals.
+func NewModel(cfg *config.Config, sysInfo *system.Info, aiClient ai.Client, hooks Hooks) *Model {
+	input := textinput.New()
+	input.Placeholder = "What is thy will, my lord?"
+	input.Prompt = "⚔️  "
+	input.Focus()
+
+	return &Model{
+		cfg:      cfg,
+		sysInfo:  sysInfo,
+		aiClient: aiClient,
+		hooks:    hooks,
+		state:    stateInput,
+		input:    input,
+		output:   viewport.New(80, 10),
+		status:   "Enter an intent and press Enter to consult the oracles.",
+		width:    80,
+		height:   24,
+	}
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.output.Width = msg.Width - 4
+		m.output.Height = maxInt(msg.Height-20, 5)
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case responseMsg:
+		return m.handleResponse(msg)
+
+	case explanationMsg:
+		if msg.err != nil {
+			m.explanation = fmt.Sprintf("I encountered difficulty explaining the command: %v", msg.err)
+		} else if !m.blocked {
+			m.explanation = msg.text
+		}
+		return m, nil
+
+	case reauthDoneMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("🔐 Not confirmed, so the quest was not carried out: %v  •  ", msg.err) + reviewHelp
+			return m, nil
+		}
+		return m, m.execute()
+
+	case outputLineMsg:
+		m.lines = append(m.lines, string(msg))
+		m.output.SetContent(strings.Join(m.lines, "\n"))
+		m.output.GotoBottom()
+		return m, waitForOutput(m.outputCh)
+
+	case executionDoneMsg:
+		m.state = stateDone
+		m.cmdMu.Lock()
+		m.cmd = nil
+		m.cmdMu.Unlock()
+		if m.hooks.Record != nil {
+			m.hooks.Record(m.current(), msg.err)
+		}
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Alas! The quest has encountered difficulties: %v  •  r refine  •  n new quest  •  q quit", msg.err)
+		} else {
+			m.status = "🏆 Quest completed, sire!  •  n new quest  •  q quit"
+		}
+		return m, nil
+	}
+
+	if m.typing() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleKey applies the keybindings for the current state
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		m.killRunning()
+		return m, tea.Quit
+	}
+
+	switch m.state {
+	case stateInput:
+		switch msg.String() {
+		case "esc":
+			return m, tea.Quit
+		case "enter":
+			intent := strings.TrimSpace(m.input.Value())
+			if intent == "" {
+				return m, nil
+			}
+			if m.haltedByKillSwitch() {
+				return m, nil
+			}
+			m.intent = intent
+			m.state = stateGenerating
+			m.status = "🧙 Consulting with the ancient oracles..."
+			m.explanation = ""
+			return m, m.generate(intent)
+		}
+
+	case stateEditing:
+		switch msg.String() {
+		case "esc":
+			m.state = stateReview
+			m.input.Blur()
+			m.status = reviewHelp
+			return m, nil
+		case "enter":
+			m.setProposal(strings.TrimSpace(m.input.Value()))
+			m.input.Blur()
+			m.state = stateReview
+			m.status = "Command amended by royal hand.  " + reviewHelp
+			return m, nil
+		}
+
+	case stateTypingDir:
+		switch msg.String() {
+		case "esc":
+			m.leaveTypingDir("Not carried out.  " + reviewHelp)
+			return m, nil
+		case "enter":
+			if strings.TrimSpace(m.input.Value()) != m.criticalDir.Dir {
+				m.leaveTypingDir(fmt.Sprintf("🙏 '%s' was not typed, so the quest was not carried out.  ", m.criticalDir.Dir) + reviewHelp)
+				return m, nil
+			}
+			m.leaveTypingDir(reviewHelp)
+			return m, m.approve()
+		}
+
+	case stateReview:
+		switch msg.String() {
+		case "a", "y":
+			if m.blocked || m.proposal == "" || m.haltedByKillSwitch() {
+				return m, nil
+			}
+			if m.criticalDir != nil {
+				// As on the command line, the directory must be typed to show it was meant
+				m.state = stateTypingDir
+				m.input.SetValue("")
+				m.input.Focus()
+				m.status = fmt.Sprintf("🛑 This quest acts on files in %s. Type '%s' and press Enter to proceed, Esc to cancel.", m.criticalDir.Dir, m.criticalDir.Dir)
+				return m, textinput.Blink
+			}
+			return m, m.approve()
+		case "e":
+			m.state = stateEditing
+			m.input.SetValue(m.proposal)
+			m.input.CursorEnd()
+			m.input.Focus()
+			m.status = "Edit the command, Enter to keep it, Esc to cancel."
+			return m, textinput.Blink
+		case "x":
+			if m.blocked {
+				return m, nil
+			}
+			m.explanation = "📚 Preparing an explanation..."
+			return m, m.explain(m.proposal)
+		case "r":
+			return m, m.refine()
+		case "n", "esc":
+			return m, m.reset()
+		case "q":
+			return m, tea.Quit
+		}
+
+	case stateDone:
+		switch msg.String() {
+		case "r":
+			return m, m.refine()
+		case "n":
+			return m, m.reset()
+		case "q", "esc":
+			return m, tea.Quit
+		}
+	}
+
+	if m.typing() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// typing reports whether keys go to the text input
+func (m *Model) typing() bool {
+	return m.state == stateInput || m.state == stateEditing || m.state == stateTypingDir
+}
+
+// leaveTypingDir returns from typing the critical directory to the review
+func (m *Model) leaveTypingDir(status string) {
+	m.state = stateReview
+	m.input.SetValue(m.intent)
+	m.input.Blur()
+	m.status = status
+}
+
+const reviewHelp = "a approve  •  e edit  •  x explain  •  r refine  •  n new quest  •  q quit"
+
+// handleResponse stores the oracle's answer and asks for an explanation
+func (m *Model) handleResponse(msg responseMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.state = stateInput
+		m.input.Focus()
+		m.status = fmt.Sprintf("The oracles have failed us, sire: %v", msg.err)
+		return m, nil
+	}
+
+	m.response = msg.response
+	if msg.response.Type == ai.ResponseTypeFailure {
+		m.state = stateInput
+		m.input.Focus()
+		m.proposal = ""
+		m.status = fmt.Sprintf("Alas, I cannot fulfill this quest: %s", msg.response.Error)
+		return m, nil
+	}
+
+	m.setProposal(msg.response.Content)
+	m.state = stateReview
+	m.input.Blur()
+	if m.blocked {
+		return m, nil
+	}
+	m.status = reviewHelp
+	if m.cfg.Mode != "royal-heir" {
+		m.explanation = "Press x for an explanation, sire."
+		return m, nil
+	}
+	m.explanation = "📚 Preparing an explanation..."
+	return m, m.explain(m.proposal)
+}
+
+// haltedByKillSwitch shows the administrator's message when a kill-switch file forbids quests
+func (m *Model) haltedByKillSwitch() bool {
+	killSwitch := system.CheckKillSwitch(m.cfg.Execution.KillSwitchPath())
+	if killSwitch == nil {
+		return false
+	}
+	m.status = fmt.Sprintf("🛑 An administrator has disabled quests on this machine (%s).", killSwitch.Path)
+	if killSwitch.Message != "" {
+		m.status += " " + killSwitch.Message
+	}
+	return true
+}
+
+// setProposal records the command or script and checks whether it can affect the environment
+func (m *Model) setProposal(proposal string) {
+	m.proposal = proposal
+	m.blocked = false
+	m.warnings = nil
+	m.criticalDir = system.DetectCriticalDirectoryUse(proposal, m.sysInfo.CurrentDir, m.sysInfo.OS)
+	if use := m.criticalDir; use != nil {
+		m.warnings = append(m.warnings, fmt.Sprintf("🛑 '%s' uses '%s', which is relative to %s, a directory your system depends on. Approving it asks you to type the directory.", use.Line, use.Path, use.Dir))
+	}
+	if entry, ago := m.hooks.recentRun(m.current(), m.cfg.Execution); entry != nil {
+		outcome := "It succeeded then."
+		if !entry.Succeeded() {
+			outcome = fmt.Sprintf("It failed then: %s", entry.LastError)
+		}
+		m.warnings = append(m.warnings, fmt.Sprintf("⚠️  You ran this exact %s %s ago, for \"%s\". %s It is destructive: approve it only if you mean to run it again.", m.current().kind(), ui.FormatDuration(ago), entry.Intent, outcome))
+	}
+
+	if m.response != nil && m.response.Type == ai.ResponseTypeScript {
+		return
+	}
+	envValidator := system.NewEnvironmentValidator(m.sysInfo)
+	if err := envValidator.ValidateEnvironmentCommand(proposal); err != nil {
+		var envErr *system.EnvironmentCommandError
+		if errors.As(err, &envErr) {
+			m.blocked = true
+			m.explanation = envErr.GetKnightlyMessage()
+			m.status = "This command must be run in your own shell.  e edit  •  r refine  •  n new quest  •  q quit"
+		}
+	}
+}
+
+func (m *Model) generate(intent string) tea.Cmd {
+	client, sysInfo := m.aiClient, m.sysInfo
+	return func() tea.Msg {
+		response, err := client.GenerateResponse(intent, sysInfo)
+		return responseMsg{response: response, err: err}
+	}
+}
+
+func (m *Model) explain(command string) tea.Cmd {
+	client, sysInfo := m.aiClient, m.sysInfo
+	return func() tea.Msg {
+		text, err := client.ExplainCommand(command, sysInfo)
+		return explanationMsg{text: text, err: err}
+	}
+}
+
+// refine returns to the intent pane pre-filled with the last intent
+func (m *Model) refine() tea.Cmd {
+	m.state = stateInput
+	m.input.SetValue(m.intent)
+	m.input.CursorEnd()
+	m.input.Focus()
+	m.status = "Refine thy intent and press Enter to consult the oracles again."
+	return textinput.Blink
+}
+
+// reset clears the panes for a new quest
+func (m *Model) reset() tea.Cmd {
+	m.state = stateInput
+	m.intent = ""
+	m.proposal = ""
+	m.response = nil
+	m.explanation = ""
+	m.blocked = false
+	m.criticalDir = nil
+	m.warnings = nil
+	m.lines = nil
+	m.output.SetContent("")
+	m.input.SetValue("")
+	m.input.Focus()
+	m.status = "Enter an intent and press Enter to consult the oracles."
+	return textinput.Blink
+}
+
+// current describes the proposal under review
+func (m *Model) current() Proposal {
+	return Proposal{
+		Intent:   m.intent,
+		Response: m.response,
+		Content:  m.proposal,
+		IsScript: m.response != nil && m.response.Type == ai.ResponseTypeScript,
+	}
+}
+
+// approve carries out the approved proposal once it passes the checks the command line makes
+// after confirmation: a destructive quest needs re-authentication when it is configured
+func (m *Model) approve() tea.Cmd {
+	if !m.cfg.Execution.Reauthenticate || m.hooks.risk(m.current()) < system.RiskDestructive {
+		return m.execute()
+	}
+	if m.hooks.Reauthenticate == nil {
+		m.status = "🔐 Destructive quests need re-authentication, which the quest chamber cannot ask for. Run this one from the command line.  " + reviewHelp
+		return nil
+	}
+	return tea.Exec(&reauthCommand{reauthenticate: m.hooks.Reauthenticate}, func(err error) tea.Msg {
+		return reauthDoneMsg{err: err}
+	})
+}
+
+// execute runs the approved proposal and streams its output into the output pane
+func (m *Model) execute() tea.Cmd {
+	m.state = stateRunning
+	m.status = "🛡️  Executing your quest with honor...  (ctrl+c to abort)"
+	m.lines = nil
+	m.output.SetContent("")
+
+	cmd := system.NewShellCommand(m.sysInfo.Shell, m.proposal)
+	if len(m.cfg.Execution.Env) > 0 {
+		cmd.Env = system.MergeEnvironment(os.Environ(), m.cfg.Execution.Env)
+	}
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	m.outputCh = make(chan string, 64)
+	m.cmdMu.Lock()
+	m.cmd = cmd
+	m.cmdMu.Unlock()
+
+	outputCh, maxLineSize := m.outputCh, m.cfg.Execution.MaxLineSize
+	go func() {
+		// The highlighter prints at most execution.max_line_size bytes of a line; whatever happens,
+		// the pipe is drained so that the command never blocks writing to it
+		lines := &lineWriter{lines: outputCh}
+		highlighter := ui.NewOutputHighlighter(false, 0)
+		highlighter.SetOutput(lines)
+		highlighter.SetMaxLineSize(maxLineSize)
+		_ = highlighter.StreamOutput(reader, "")
+		_, _ = io.Copy(io.Discard, reader)
+		lines.flush()
+		close(outputCh)
+	}()
+
+	run := func() tea.Msg {
+		err := cmd.Start()
+		if err == nil {
+			err = cmd.Wait()
+		}
+		writer.Close()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = &system.ExecutionError{Err: err, ExitCode: exitErr.ExitCode()}
+		}
+		return executionDoneMsg{err: err}
+	}
+
+	return tea.Batch(waitForOutput(outputCh), run)
+}
+
+// lineWriter sends what is written to it to the output pane, one line at a time
+type lineWriter struct {
+	lines   chan<- string
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.lines <- string(w.pending[:i])
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// flush sends a last line that did not end in a newline
+func (w *lineWriter) flush() {
+	if len(w.pending) > 0 {
+		w.lines <- string(w.pending)
+		w.pending = nil
+	}
+}
+
+// waitForOutput delivers the next line of child output to the model
+func waitForOutput(ch chan string) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return outputLineMsg(line)
+	}
+}
+
+func (m *Model) killRunning() {
+	m.cmdMu.Lock()
+	defer m.cmdMu.Unlock()
+	if m.cmd != nil && m.cmd.Process != nil {
+		m.cmd.Process.Kill()
+	}
+}
+
+// Styles
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	paneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("3")).Padding(0, 1)
+	activeStyle  = paneStyle.BorderForeground(lipgloss.Color("11"))
+	cmdStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	statusStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// View implements tea.Model
+func (m *Model) View() string {
+	width := maxInt(m.width-2, 40)
+
+	pane := func(title, body string, active bool) string {
+		style := paneStyle
+		if active {
+			style = activeStyle
+		}
+		return style.Width(width - 2).Render(titleStyle.Render(title) + "\n" + body)
+	}
+
+	proposal := m.proposal
+	if proposal == "" {
+		proposal = statusStyle.Render("(no proposal yet)")
+	} else {
+		proposal = cmdStyle.Render(proposal)
+	}
+	for _, warning := range m.warnings {
+		proposal += "\n" + warningStyle.Render(warning)
+	}
+
+	explanation := m.explanation
+	if explanation == "" {
+		explanation = statusStyle.Render("(explanations appear here)")
+	}
+
+	sections := []string{
+		titleStyle.Render("🏰 execute-my-will — thy faithful knight"),
+		pane("📝 INTENT", m.input.View(), m.typing()),
+		pane("⚔️  PROPOSED COMMAND", proposal, m.state == stateReview),
+		pane("📚 EXPLANATION", explanation, false),
+		pane("📜 OUTPUT", m.output.View(), m.state == stateRunning),
+		statusStyle.Render(m.status),
+	}
+	return strings.Join(sections, "\n")
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
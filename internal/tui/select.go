@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/tui/select.go
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	selectedStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	unselectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+// selectModel is a choice between a few options on one line, made with the arrow keys
+type selectModel struct {
+	options []string
+	cursor  int
+	chosen  int // -1 until an option is chosen
+	done    bool
+}
+
+func (m selectModel) Init() tea.Cmd { return nil }
+
+func (m selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "left", "up", "shift+tab":
+		m.cursor = (m.cursor + len(m.options) - 1) % len(m.options)
+	case "right", "down", "tab":
+		m.cursor = (m.cursor + 1) % len(m.options)
+	case "enter", " ":
+		m.chosen, m.done = m.cursor, true
+		return m, tea.Quit
+	case "esc", "ctrl+c", "ctrl+d":
+		m.done = true
+		return m, tea.Quit
+	default:
+		// Digits are on every keyboard layout
+		if n, err := strconv.Atoi(key.String()); err == nil && n >= 1 && n <= len(m.options) {
+			m.cursor = n - 1
+		}
+	}
+	return m, nil
+}
+
+func (m selectModel) View() string {
+	if m.done {
+		if m.chosen < 0 {
+			return unselectedStyle.Render("  (cancelled)") + "\n"
+		}
+		return selectedStyle.Render("  › "+m.options[m.chosen]) + "\n"
+	}
+	parts := make([]string, len(m.options))
+	for i, option := range m.options {
+		if i == m.cursor {
+			parts[i] = selectedStyle.Render("› " + option)
+		} else {
+			parts[i] = unselectedStyle.Render("  " + option)
+		}
+	}
+	return "  " + strings.Join(parts, "   ") + unselectedStyle.Render("   (←/→ and Enter)") + "\n"
+}
+
+// Select asks for one of a few options with the arrow keys, starting at initial, and returns the
+// index of the option chosen, or -1 when the choice was cancelled with Esc or Ctrl+C
+func Select(options []string, initial int) (int, error) {
+	final, err := tea.NewProgram(selectModel{options: options, cursor: initial, chosen: -1}).Run()
+	if err != nil {
+		return -1, err
+	}
+	return final.(selectModel).chosen, nil
+}
@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"os"
+
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
 // Color definitions for the medieval knight theme
@@ -70,3 +73,14 @@ func HighlightText(text string) string {
 func CommentText(text string) string {
 	return Purple.Add(color.Italic).Sprint(text)
 }
+
+// UseStderr sends everything the UI prints to stderr, keeping stdout for output meant for another
+// program, such as a command for the shell to evaluate. It returns the original stdout.
+func UseStderr() *os.File {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = color.Error
+	color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" ||
+		!(isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()))
+	return stdout
+}
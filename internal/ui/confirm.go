@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ui
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// affirmatives are the words accepted as yes at a confirmation whatever the locale, by language.
+// None of them means no in another language, so a user who types their own word for yes is
+// understood, and anything else still declines.
+var affirmatives = map[string][]string{
+	"en": {"y", "yes"},
+	"de": {"ja"},
+	"nl": {"ja"},
+	"sv": {"ja"},
+	"fr": {"oui"},
+	"es": {"sí", "si"},
+	"it": {"sì", "si"},
+	"pt": {"sim"},
+	"pl": {"tak"},
+	"cs": {"ano"},
+	"hu": {"igen"},
+	"fi": {"kyllä"},
+	"tr": {"evet"},
+	"ro": {"da"},
+	"ru": {"да"},
+	"uk": {"так"},
+	"el": {"ναι"},
+	"he": {"כן"},
+	"ar": {"نعم"},
+	"hi": {"हाँ", "हां", "haan"},
+	"ja": {"はい"},
+	"zh": {"是", "是的", "好", "对", "對"},
+	"ko": {"네", "예"},
+	"id": {"ya"},
+}
+
+// affirmativeLetters are one-letter answers accepted only in the language of the active locale,
+// where they abbreviate its yes. Elsewhere a single letter may start a no, as "н" starts the
+// Russian "нет", so it is never guessed at.
+var affirmativeLetters = map[string][]string{
+	"de": {"j"}, "nl": {"j"}, "sv": {"j"},
+	"fr": {"o"},
+	"es": {"s"}, "it": {"s"}, "pt": {"s"},
+	"pl": {"t"},
+	"ru": {"д"},
+}
+
+// IsAffirmative reports whether an answer typed at a confirmation means yes: "y" or "yes", the
+// word for yes in one of many languages, or the one-letter yes of the active locale's language.
+// Case, surrounding spaces, and a final full stop or exclamation mark do not matter.
+func IsAffirmative(answer string) bool {
+	answer = norm.NFC.String(strings.ToLower(strings.TrimSpace(answer)))
+	answer = strings.TrimRight(answer, ".!。！ ")
+	for _, words := range affirmatives {
+		if slices.Contains(words, answer) {
+			return true
+		}
+	}
+	language, _, _ := strings.Cut(currentLocale.Name, "_")
+	return slices.Contains(affirmativeLetters[language], answer)
+}
+
+// answerHint is the "(y/N):" a confirmation message ends with
+var answerHint = regexp.MustCompile(`\s*\([^()]*\)\s*:?\s*$`)
+
+// WithoutAnswerHint drops the "(y/N):" from the end of a confirmation message, for a prompt
+// answered by choosing instead of typing
+func WithoutAnswerHint(message string) string {
+	return answerHint.ReplaceAllString(message, "")
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale holds the conventions timestamps, durations, sizes, and counts are shown in
+type Locale struct {
+	Name       string
+	DateLayout string // a Go reference layout, e.g. "02.01.2006"
+	TimeLayout string // e.g. "15:04" or "3:04 PM"
+	Decimal    string // the decimal separator
+	Thousands  string // the digit group separator
+}
+
+// DefaultLocale is used when neither the configuration nor the environment names a known locale.
+// Its ISO dates read the same everywhere.
+const DefaultLocale = "iso"
+
+// locales are keyed by language, or by language and territory where the territory changes the
+// conventions, as en_US writes dates month first
+var locales = map[string]Locale{
+	"iso":   {DateLayout: "2006-01-02", TimeLayout: "15:04", Decimal: ".", Thousands: ","},
+	"en":    {DateLayout: "02/01/2006", TimeLayout: "15:04", Decimal: ".", Thousands: ","},
+	"en_US": {DateLayout: "01/02/2006", TimeLayout: "3:04 PM", Decimal: ".", Thousands: ","},
+	"en_CA": {DateLayout: "2006-01-02", TimeLayout: "3:04 PM", Decimal: ".", Thousands: ","},
+	"de":    {DateLayout: "02.01.2006", TimeLayout: "15:04", Decimal: ",", Thousands: "."},
+	"de_CH": {DateLayout: "02.01.2006", TimeLayout: "15:04", Decimal: ".", Thousands: "'"},
+	"fr":    {DateLayout: "02/01/2006", TimeLayout: "15:04", Decimal: ",", Thousands: " "},
+	"es":    {DateLayout: "02/01/2006", TimeLayout: "15:04", Decimal: ",", Thousands: "."},
+	"it":    {DateLayout: "02/01/2006", TimeLayout: "15:04", Decimal: ",", Thousands: "."},
+	"nl":    {DateLayout: "02-01-2006", TimeLayout: "15:04", Decimal: ",", Thousands: "."},
+	"pt":    {DateLayout: "02/01/2006", TimeLayout: "15:04", Decimal: ",", Thousands: "."},
+	"pl":    {DateLayout: "02.01.2006", TimeLayout: "15:04", Decimal: ",", Thousands: " "},
+	"ru":    {DateLayout: "02.01.2006", TimeLayout: "15:04", Decimal: ",", Thousands: " "},
+	"sv":    {DateLayout: "2006-01-02", TimeLayout: "15:04", Decimal: ",", Thousands: " "},
+	"ja":    {DateLayout: "2006/01/02", TimeLayout: "15:04", Decimal: ".", Thousands: ","},
+	"zh":    {DateLayout: "2006/01/02", TimeLayout: "15:04", Decimal: ".", Thousands: ","},
+	"ko":    {DateLayout: "2006. 01. 02.", TimeLayout: "15:04", Decimal: ".", Thousands: ","},
+	"hi":    {DateLayout: "02/01/2006", TimeLayout: "3:04 PM", Decimal: ".", Thousands: ","},
+}
+
+var currentLocale = DetectLocale(os.Getenv)
+
+// Locales returns the names of the known locales, sorted
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLocale resolves a locale name such as "de_DE.UTF-8", "en-US", or "fr". A territory without
+// conventions of its own falls back to its language. "C" and "POSIX" are the ISO locale.
+func ParseLocale(value string) (Locale, error) {
+	name := strings.TrimSpace(value)
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ReplaceAll(name, "-", "_")
+	if name == "" || name == "C" || name == "POSIX" {
+		name = DefaultLocale
+	}
+
+	language, territory, _ := strings.Cut(name, "_")
+	language = strings.ToLower(language)
+	candidates := []string{language}
+	if territory != "" {
+		candidates = []string{language + "_" + strings.ToUpper(territory), language}
+	}
+	for _, candidate := range candidates {
+		if locale, ok := locales[candidate]; ok {
+			locale.Name = candidate
+			return locale, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("unknown locale '%s'. Choose one of %s", value, strings.Join(Locales(), ", "))
+}
+
+// DetectLocale finds the locale of the environment from LC_ALL, LC_TIME, and LANG, in the order
+// the C library reads them, falling back to DefaultLocale
+func DetectLocale(getenv func(string) string) Locale {
+	for _, variable := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if value := getenv(variable); value != "" {
+			if locale, err := ParseLocale(value); err == nil {
+				return locale
+			}
+			break
+		}
+	}
+	locale, _ := ParseLocale(DefaultLocale)
+	return locale
+}
+
+// SetLocale sets the locale used by the formatting helpers in this package
+func SetLocale(locale Locale) {
+	currentLocale = locale
+}
+
+// GetLocale returns the active locale
+func GetLocale() Locale {
+	return currentLocale
+}
+
+// FormatDate shows the date of t in the active locale
+func FormatDate(t time.Time) string {
+	return t.Local().Format(currentLocale.DateLayout)
+}
+
+// FormatTimestamp shows the date and time of t in the active locale
+func FormatTimestamp(t time.Time) string {
+	return t.Local().Format(currentLocale.DateLayout + " " + currentLocale.TimeLayout)
+}
+
+// FormatDuration shows a duration the way people read it: "850 ms", "4.2 s", "3 min 05 s", or
+// "2 h 07 min", with the decimal separator of the active locale
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%d ms", d.Milliseconds())
+	case d < time.Minute:
+		return formatDecimal(d.Seconds(), 1) + " s"
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%d min %02d s", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%d h %02d min", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// FormatBytes shows a size in binary units, as in "1.5 MiB" or "1,5 MiB"
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return FormatNumber(bytes) + " B"
+	}
+	size, exponent := float64(bytes)/unit, 0
+	for size >= unit && exponent < 4 {
+		size /= unit
+		exponent++
+	}
+	return fmt.Sprintf("%s %ciB", formatDecimal(size, 1), "KMGTP"[exponent])
+}
+
+// FormatNumber groups the digits of n with the separator of the active locale
+func FormatNumber(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(currentLocale.Thousands)
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String()
+}
+
+// formatDecimal shows value with the given number of decimals and the locale's separator
+func formatDecimal(value float64, decimals int) string {
+	text := fmt.Sprintf("%.*f", decimals, value)
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return text
+	}
+	return strings.Replace(text, ".", currentLocale.Decimal, 1)
+}
@@ -2,17 +2,42 @@ package ui
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+)
+
+// DefaultTailLines is the number of trailing output lines kept for diagnosis after execution
+const DefaultTailLines = 50
+
+// DefaultMaxLineSize is the number of bytes of a single line that are printed; the rest is skipped
+const DefaultMaxLineSize = 1 << 20
+
+const (
+	// streamChunkSize is how much of a long line is read and printed at a time
+	streamChunkSize = 8 << 10
+	// tailLineSize is how much of each line is kept for diagnosis
+	tailLineSize = 512
 )
 
 // OutputHighlighter handles real-time output streaming with intelligent highlighting
 type OutputHighlighter struct {
 	showTimestamps bool
 	indentLevel    int
+	maxLineSize    int
+	out            io.Writer
+
+	mu        sync.Mutex
+	log       io.Writer // receives every plain output line when set
+	tail      []string
+	tailLimit int
+	lineCount int
 }
 
 // NewOutputHighlighter creates a new output highlighter
@@ -20,11 +45,70 @@ func NewOutputHighlighter(showTimestamps bool, indentLevel int) *OutputHighlight
 	return &OutputHighlighter{
 		showTimestamps: showTimestamps,
 		indentLevel:    indentLevel,
+		maxLineSize:    DefaultMaxLineSize,
+		out:            os.Stdout,
+		tailLimit:      DefaultTailLines,
+	}
+}
+
+// SetMaxLineSize sets how many bytes of a single line are printed before the rest is skipped
+func (oh *OutputHighlighter) SetMaxLineSize(size int) {
+	if size > 0 {
+		oh.maxLineSize = size
+	}
+}
+
+// SetOutput redirects the highlighted output, which goes to stdout by default
+func (oh *OutputHighlighter) SetOutput(w io.Writer) {
+	oh.out = w
+}
+
+// SetLog copies every plain output line to w, e.g. a log file kept after execution.
+// Lines are cut at the same size as the diagnosis tail.
+func (oh *OutputHighlighter) SetLog(w io.Writer) {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	oh.log = w
+}
+
+// record keeps the most recent plain output lines; safe for concurrent streams
+func (oh *OutputHighlighter) record(line string) {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+
+	if len(line) > tailLineSize {
+		line = strings.ToValidUTF8(line[:tailLineSize], "") + "…"
+	}
+
+	oh.lineCount++
+	if oh.log != nil {
+		fmt.Fprintln(oh.log, line)
+	}
+	oh.tail = append(oh.tail, line)
+	if len(oh.tail) > oh.tailLimit {
+		oh.tail = oh.tail[len(oh.tail)-oh.tailLimit:]
 	}
 }
 
+// Tail returns the most recent output lines seen across all streams
+func (oh *OutputHighlighter) Tail() []string {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	return append([]string(nil), oh.tail...)
+}
+
+// LineCount returns the total number of output lines seen across all streams
+func (oh *OutputHighlighter) LineCount() int {
+	oh.mu.Lock()
+	defer oh.mu.Unlock()
+	return oh.lineCount
+}
+
 // Pattern matchers for different types of output
 var (
+	// ansiSequencePattern matches CSI sequences (colors, cursor movement) and OSC sequences (titles, links)
+	ansiSequencePattern = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\))`)
+
 	errorPatterns = regexp.MustCompile(`(?i)(error|failed|fatal|panic|exception|denied|cannot|unable to|not found|invalid|illegal)`)
 
 	warningPatterns = regexp.MustCompile(`(?i)(warning|warn|deprecated|caution|note|notice)`)
@@ -36,41 +120,174 @@ var (
 	progressPatterns = regexp.MustCompile(`(\d+%|\d+/\d+|\[\d+/\d+\]|\d+\.\d+\s*(MB|GB|KB))`)
 )
 
-// StreamOutput processes output line by line with highlighting
+// StreamOutput processes output line by line with highlighting. Long lines are printed in chunks as
+// they arrive and cut off after the maximum line size; once the stream looks binary the rest of it is
+// counted instead of printed, so raw bytes never reach the terminal.
 func (oh *OutputHighlighter) StreamOutput(reader io.Reader, prefix string) error {
-	scanner := bufio.NewScanner(reader)
+	r := bufio.NewReaderSize(reader, streamChunkSize)
+
+	var (
+		inLine      bool   // part of the current line has been printed
+		colored     bool   // the current line carries its own escape codes
+		first       []byte // start of the current line, kept for the diagnosis tail
+		lineSize    int    // bytes of the current line seen so far
+		skipped     int    // bytes of the current line beyond the maximum line size
+		carry       []byte // incomplete UTF-8 sequence at the end of the previous chunk
+		binaryBytes = -1   // bytes hidden since the stream turned out to be binary
+	)
+
+	endLine := func() {
+		if skipped > 0 {
+			fmt.Fprint(oh.out, Gray.Sprintf(" … [%s more not shown]", formatByteSize(skipped)))
+		}
+		fmt.Fprintln(oh.out)
+		oh.record(StripANSISequences(string(first)))
+		inLine, colored, first, lineSize, skipped = false, false, nil, 0, 0
+	}
+
+	for {
+		fragment, isPrefix, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if binaryBytes >= 0 {
+			binaryBytes += len(fragment)
+			continue
+		}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+		chunk := append(carry, fragment...)
+		carry = nil
+		if isPrefix {
+			chunk, carry = splitIncompleteRune(chunk)
+		}
 
-		// Build the formatted line
-		var formattedLine strings.Builder
+		if looksBinary(chunk) {
+			if inLine {
+				endLine()
+			}
+			binaryBytes = len(chunk)
+			continue
+		}
 
-		// Add indent
-		for i := 0; i < oh.indentLevel; i++ {
-			formattedLine.WriteString("  ")
+		if !inLine {
+			inLine = true
+			fmt.Fprint(oh.out, oh.linePrefix(prefix))
+			first = append([]byte(nil), chunk[:min(len(chunk), tailLineSize+1)]...)
 		}
 
-		// Add timestamp if enabled
-		if oh.showTimestamps {
-			timestamp := time.Now().Format("15:04:05")
-			formattedLine.WriteString(TimestampText(fmt.Sprintf("[%s] ", timestamp)))
+		// Print up to the maximum line size without splitting a character
+		printable := min(len(chunk), max(oh.maxLineSize-lineSize, 0))
+		for printable > 0 && printable < len(chunk) && !utf8.RuneStart(chunk[printable]) {
+			printable--
+		}
+		if printable > 0 {
+			text := string(chunk[:printable])
+			// Lines the child process colored itself pass through untouched
+			colored = colored || HasANSI(text)
+			if !colored {
+				text = oh.highlightLine(text)
+			}
+			fmt.Fprint(oh.out, text)
 		}
+		skipped += len(chunk) - printable
+		lineSize += len(chunk)
+
+		if !isPrefix {
+			endLine()
+		}
+	}
+
+	if inLine {
+		endLine()
+	}
+	if binaryBytes >= 0 {
+		note := fmt.Sprintf("[binary output hidden: %s]", formatByteSize(binaryBytes))
+		fmt.Fprintln(oh.out, oh.linePrefix(prefix)+WarningMessage(note))
+		oh.record(note)
+	}
+	return nil
+}
+
+// linePrefix builds the indent, timestamp, and prefix printed before each line
+func (oh *OutputHighlighter) linePrefix(prefix string) string {
+	var formattedLine strings.Builder
+
+	// Add indent
+	for i := 0; i < oh.indentLevel; i++ {
+		formattedLine.WriteString("  ")
+	}
+
+	// Add timestamp if enabled
+	if oh.showTimestamps {
+		timestamp := time.Now().Format("15:04:05")
+		formattedLine.WriteString(TimestampText(fmt.Sprintf("[%s] ", timestamp)))
+	}
+
+	// Add prefix if provided
+	formattedLine.WriteString(prefix)
+	return formattedLine.String()
+}
 
-		// Add prefix if provided
-		if prefix != "" {
-			formattedLine.WriteString(prefix)
+// splitIncompleteRune separates a UTF-8 sequence cut off at the end of a chunk so it can be
+// completed by the next one
+func splitIncompleteRune(chunk []byte) ([]byte, []byte) {
+	for i := len(chunk) - 1; i >= 0 && i >= len(chunk)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(chunk[i]) {
+			if !utf8.FullRune(chunk[i:]) {
+				return chunk[:i], append([]byte(nil), chunk[i:]...)
+			}
+			break
 		}
+	}
+	return chunk, nil
+}
 
-		// Apply highlighting based on content
-		highlightedLine := oh.highlightLine(line)
-		formattedLine.WriteString(highlightedLine)
+// looksBinary reports whether a chunk is binary data rather than text: it contains a NUL byte, or
+// control characters and invalid UTF-8 make up more than 30% of it. Escape sequences, tabs, and
+// carriage returns used by progress bars count as text.
+func looksBinary(chunk []byte) bool {
+	if bytes.IndexByte(chunk, 0) >= 0 {
+		return true
+	}
 
-		// Print the formatted line
-		fmt.Println(formattedLine.String())
+	suspicious, total := 0, 0
+	for len(chunk) > 0 {
+		r, size := utf8.DecodeRune(chunk)
+		chunk = chunk[size:]
+		total++
+		switch {
+		case r == utf8.RuneError && size == 1:
+			suspicious++
+		case r < 0x20 && r != '\t' && r != '\r' && r != '\b' && r != 0x1b, r == 0x7f:
+			suspicious++
+		}
+	}
+	return total > 0 && suspicious*10 > total*3
+}
+
+// formatByteSize renders a byte count for humans
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
 	}
+}
 
-	return scanner.Err()
+// HasANSI reports whether text already contains terminal escape sequences
+func HasANSI(text string) bool {
+	return ansiSequencePattern.MatchString(text)
+}
+
+// StripANSISequences removes terminal escape sequences, leaving the visible text
+func StripANSISequences(text string) string {
+	return ansiSequencePattern.ReplaceAllString(text, "")
 }
 
 // highlightLine applies color highlighting based on line content
@@ -96,34 +313,65 @@ func (oh *OutputHighlighter) highlightLine(line string) string {
 	}
 }
 
-// PrintKnightMessage prints a themed knight message
+// PrintKnightMessage prints a themed knight message (suppressed in minimal verbosity)
 func PrintKnightMessage(message string) {
-	fmt.Println(KnightMessage("🛡️  " + message))
+	if IsMinimal() {
+		return
+	}
+	fmt.Println(KnightMessage("🛡️  " + Voice(message)))
 }
 
 // PrintSuccessMessage prints a themed success message
 func PrintSuccessMessage(message string) {
-	fmt.Println(SuccessMessage("🏆 " + message))
+	fmt.Println(SuccessMessage(decorate("🏆", message)))
 }
 
 // PrintErrorMessage prints a themed error message
 func PrintErrorMessage(message string) {
-	fmt.Println(ErrorMessage("❌ " + message))
+	fmt.Println(ErrorMessage(decorate("❌", message)))
 }
 
 // PrintWarningMessage prints a themed warning message
 func PrintWarningMessage(message string) {
-	fmt.Println(WarningMessage("⚠️  " + message))
+	fmt.Println(WarningMessage(decorate("⚠️ ", message)))
 }
 
 // PrintInfoMessage prints a themed info message
 func PrintInfoMessage(message string) {
-	fmt.Println(InfoMessage("🔍 " + message))
+	fmt.Println(InfoMessage(decorate("🔍", message)))
 }
 
 // PrintAIMessage prints a themed AI consultation message
 func PrintAIMessage(message string) {
-	fmt.Println(AIMessage("🧙 " + message))
+	fmt.Println(AIMessage(decorate("🧙", message)))
+}
+
+// PrintFlourish prints a celebratory message only in festive verbosity
+func PrintFlourish(icon, message string) {
+	if !IsFestive() {
+		return
+	}
+	fmt.Println(KnightMessage(decorate(icon, message)))
+}
+
+// PrintLine prints a plain line prefixed with an icon (icon dropped in minimal verbosity)
+func PrintLine(icon, message string) {
+	fmt.Println(decorate(icon, message))
+}
+
+// PrintBlankLine prints an empty line
+func PrintBlankLine() {
+	fmt.Println()
+}
+
+// PrintPrompt prints a question without a trailing newline so the answer stays on the same line
+func PrintPrompt(icon, question string) {
+	fmt.Print(decorate(icon, question) + " ")
+}
+
+// PrintPlain prints text exactly as given, bypassing all decoration
+func PrintPlain(text string) {
+	fmt.Println(text)
 }
 
 // Default template instance
@@ -134,14 +382,23 @@ func PrintSeparator() {
 	defaultTemplate.PrintStandardSeparator()
 }
 
-// PrintExecutionHeader prints a header for command/script execution
+// PrintExecutionHeader prints a header for command/script execution. Only the first line is
+// reworded by the persona; the command that follows is shown as is.
 func PrintExecutionHeader(title string) {
+	heading, command, found := strings.Cut(title, "\n")
+	title = Voice(heading)
+	if found {
+		title += "\n" + command
+	}
 	defaultTemplate.PrintMainSection(title)
 }
 
-// PrintPhaseHeader prints a phase header
+// PrintPhaseHeader prints a phase header (suppressed in minimal verbosity)
 func PrintPhaseHeader(icon, phase string) {
-	defaultTemplate.PrintPhase(icon, phase)
+	if IsMinimal() {
+		return
+	}
+	defaultTemplate.PrintPhase(icon, Voice(phase))
 }
 
 // PrintCommandBox prints a command in a structured box
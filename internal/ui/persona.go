@@ -0,0 +1,280 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Persona is the voice of the UI. Terms rename the knightly vocabulary wherever it is printed,
+// and Messages replace whole entries of the message catalog.
+type Persona struct {
+	Name     string            `yaml:"name"`
+	Terms    map[string]string `yaml:"terms"`    // phrase to replacement, matched as whole words regardless of case
+	Messages map[string]string `yaml:"messages"` // catalog key to text, with {placeholders} as in the catalog
+
+	pattern *regexp.Regexp
+}
+
+// DefaultPersona is the knight the UI speaks as unless configured otherwise
+const DefaultPersona = "knight"
+
+// messageCatalog holds the messages a persona may replace, keyed by name
+var messageCatalog = map[string]string{
+	"quest.received":          "Your faithful knight has received your command: \"{intent}\"",
+	"confirm.monarch":         "Do you wish me to proceed with this quest? (y/N):",
+	"confirm.heir":            "Do you wish me to proceed with this quest, young heir? (y/N):",
+	"confirm.read_only":       "This quest only looks and changes nothing. Shall I proceed? (Y/n):",
+	"confirm.declined":        "I understand, sire. Please try again when you're ready.",
+	"quest.completed.command": "Your command has been executed successfully, sire!",
+	"quest.completed.script":  "Your script has been executed successfully, sire!",
+	"quest.difficulties":      "Alas! The quest has encountered difficulties, my lord: {error}",
+	"quest.celebration":       "Huzzah! The realm rejoices at another quest fulfilled!",
+}
+
+// builtinPersonas can be chosen by name instead of a persona file
+var builtinPersonas = map[string]Persona{
+	DefaultPersona: {Name: DefaultPersona},
+	"pirate": {
+		Name: "pirate",
+		Terms: map[string]string{
+			"my lord": "captain", "sire": "cap'n", "young heir": "young swab",
+			"knight": "first mate", "knights": "crew", "quest": "voyage", "quests": "voyages",
+			"oracle": "sea witch", "oracles": "sea witches", "the realm": "the seven seas",
+			"royal decree": "captain's orders", "huzzah": "yo ho ho", "hear ye, hear ye": "ahoy, ahoy",
+			"thy": "yer", "thou": "ye",
+		},
+		Messages: map[string]string{
+			"confirm.monarch":   "Shall we set sail on this voyage, captain? (y/N):",
+			"confirm.heir":      "Shall we set sail on this voyage, young swab? (y/N):",
+			"confirm.read_only": "A calm voyage that only scouts the waters. Set sail, captain? (Y/n):",
+			"confirm.declined":  "Aye, we'll stay in port. Hail me when ye be ready, cap'n.",
+		},
+	},
+	"starship": {
+		Name: "starship",
+		Terms: map[string]string{
+			"my lord": "captain", "sire": "captain", "young heir": "ensign",
+			"knight": "ship's computer", "knights": "ship's computers", "quest": "mission", "quests": "missions",
+			"oracle": "science officer", "oracles": "science officers", "the realm": "the ship",
+			"royal decree": "orders", "huzzah": "engage", "hear ye, hear ye": "attention, all decks",
+			"thy": "your", "thou": "you",
+		},
+		Messages: map[string]string{
+			"confirm.monarch":   "Shall I engage, captain? (y/N):",
+			"confirm.heir":      "Shall I engage, ensign? (y/N):",
+			"confirm.read_only": "Sensor sweep only, no changes. Shall I engage, captain? (Y/n):",
+			"confirm.declined":  "Standing by, captain. Awaiting further orders.",
+		},
+	},
+	"plain": {
+		Name: "plain",
+		Terms: map[string]string{
+			"my lord": "", "sire": "", "young heir": "", "hear ye, hear ye!": "",
+			"knight": "assistant", "knights": "assistants", "quest": "task", "quests": "tasks",
+			"oracle": "AI", "oracles": "AI providers", "the realm": "the system",
+			"royal decree": "confirmation", "huzzah!": "", "thy": "your", "thou": "you",
+		},
+		Messages: map[string]string{
+			"quest.received":          "Request: \"{intent}\"",
+			"confirm.monarch":         "Proceed? (y/N):",
+			"confirm.heir":            "Proceed? (y/N):",
+			"confirm.read_only":       "Read-only. Proceed? (Y/n):",
+			"confirm.declined":        "Cancelled.",
+			"quest.completed.command": "The command ran successfully.",
+			"quest.completed.script":  "The script ran successfully.",
+			"quest.difficulties":      "The task failed: {error}",
+			"quest.celebration":       "Done.",
+		},
+	},
+}
+
+var currentPersona = builtinPersonas[DefaultPersona]
+
+// verbatimText matches quoted text and paths, which are never reworded. A single quote only
+// opens a quotation after a non-letter, so apostrophes as in "knight's" are not mistaken for one.
+var verbatimText = regexp.MustCompile("(^|[^\\pL])'[^'\\n]*'|\"[^\"\\n]*\"|`[^`\\n]*`|[^\\s'\"`]*[/\\\\][^\\s'\"`]*")
+
+// BuiltinPersonas returns the names of the personas that need no file, sorted
+func BuiltinPersonas() []string {
+	names := make([]string, 0, len(builtinPersonas))
+	for name := range builtinPersonas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPersona resolves a configured persona: empty for the knight, the name of a built-in
+// persona, or the path to a persona YAML file
+func LoadPersona(value string) (Persona, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return builtinPersonas[DefaultPersona], nil
+	}
+	if persona, ok := builtinPersonas[strings.ToLower(value)]; ok {
+		return persona, nil
+	}
+
+	if strings.HasPrefix(value, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = filepath.Join(home, value[2:])
+		}
+	}
+	data, err := os.ReadFile(value)
+	if os.IsNotExist(err) {
+		return Persona{}, fmt.Errorf("unknown persona '%s'. Choose %s, or the path to a persona file", value, strings.Join(BuiltinPersonas(), ", "))
+	}
+	if err != nil {
+		return Persona{}, fmt.Errorf("failed to read persona file: %w", err)
+	}
+
+	var persona Persona
+	if err := yaml.Unmarshal(data, &persona); err != nil {
+		return Persona{}, fmt.Errorf("failed to parse persona file %s: %w", value, err)
+	}
+	for key := range persona.Messages {
+		if _, ok := messageCatalog[key]; !ok {
+			return Persona{}, fmt.Errorf("persona file %s replaces unknown message '%s'", value, key)
+		}
+	}
+	if persona.Name == "" {
+		persona.Name = strings.TrimSuffix(filepath.Base(value), filepath.Ext(value))
+	}
+	return persona, nil
+}
+
+// SetPersona sets the voice used by all print helpers in this package
+func SetPersona(persona Persona) {
+	persona.pattern = nil
+	if len(persona.Terms) > 0 {
+		lowered := make(map[string]string, len(persona.Terms))
+		alternatives := make([]string, 0, len(persona.Terms))
+		for term, replacement := range persona.Terms {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term == "" {
+				continue
+			}
+			lowered[term] = replacement
+			alternatives = append(alternatives, wholeWords(term))
+		}
+		// Longer phrases first, so "hear ye, hear ye!" wins over "hear ye"
+		sort.Slice(alternatives, func(i, j int) bool { return len(alternatives[i]) > len(alternatives[j]) })
+		persona.pattern = regexp.MustCompile(`(?i)(,[ \t]*|[ \t]+)?(` + strings.Join(alternatives, "|") + `)`)
+		persona.Terms = lowered
+	}
+	currentPersona = persona
+}
+
+// wholeWords matches the term only where it is not part of a longer word
+func wholeWords(term string) string {
+	pattern := regexp.QuoteMeta(term)
+	if first, _ := utf8.DecodeRuneInString(term); isWordRune(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(term); isWordRune(last) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// GetPersona returns the active persona
+func GetPersona() Persona {
+	return currentPersona
+}
+
+// Message returns a catalog message as the active persona phrases it. Values are given as
+// placeholder and value pairs, e.g. Message("quest.received", "intent", intent). The print
+// helpers reword the result like any other text.
+func Message(key string, values ...string) string {
+	text, ok := currentPersona.Messages[key]
+	if !ok {
+		text = messageCatalog[key]
+	}
+
+	for i := 0; i+1 < len(values); i += 2 {
+		text = strings.ReplaceAll(text, "{"+values[i]+"}", values[i+1])
+	}
+	return text
+}
+
+// Voice rewords text in the active persona. Quoted text and paths are left untouched.
+func Voice(text string) string {
+	if currentPersona.pattern == nil || text == "" {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, quoted := range verbatimText.FindAllStringIndex(text, -1) {
+		b.WriteString(currentPersona.reword(text[last:quoted[0]]))
+		b.WriteString(text[quoted[0]:quoted[1]])
+		last = quoted[1]
+	}
+	b.WriteString(currentPersona.reword(text[last:]))
+	return b.String()
+}
+
+func (p Persona) reword(text string) string {
+	dropped := false
+	reworded := p.pattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := p.pattern.FindStringSubmatch(match)
+		separator, term := groups[1], groups[2]
+
+		replacement := p.Terms[strings.ToLower(term)]
+		if replacement == "" {
+			// "successfully, sire!" becomes "successfully!"
+			dropped = true
+			return ""
+		}
+		return separator + matchCase(term, replacement)
+	})
+	if !dropped {
+		return reworded
+	}
+
+	// A dropped term can leave a leading comma, as in "Sire, please wait", or doubled spaces
+	if trimmed := strings.TrimLeft(reworded, ", "); trimmed != reworded && strings.TrimSpace(text) != "" && !unicode.IsSpace(rune(text[0])) {
+		first, _ := utf8.DecodeRuneInString(text)
+		reworded = trimmed
+		if unicode.IsUpper(first) {
+			reworded = capitalize(reworded)
+		}
+	}
+	for strings.Contains(reworded, "  ") {
+		reworded = strings.ReplaceAll(reworded, "  ", " ")
+	}
+	return reworded
+}
+
+// matchCase applies the capitalization of the original term to its replacement
+func matchCase(original, replacement string) string {
+	if strings.ToUpper(original) == original && strings.ToLower(original) != original {
+		return strings.ToUpper(replacement)
+	}
+	if first, _ := utf8.DecodeRuneInString(original); unicode.IsUpper(first) {
+		return capitalize(replacement)
+	}
+	return replacement
+}
+
+func capitalize(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ui/progress.go
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// progressBarWidth is the number of cells in a progress bar
+const progressBarWidth = 30
+
+// ProgressBar redraws one line with how much of a long task is done. It draws nothing when the
+// output is not a terminal or in minimal verbosity, so logs and pipes stay clean.
+type ProgressBar struct {
+	label   string
+	enabled bool
+	percent int // the last percentage drawn, or -1
+}
+
+// NewProgressBar returns a progress bar for the task described by label
+func NewProgressBar(label string) *ProgressBar {
+	terminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	return &ProgressBar{label: label, enabled: terminal && !IsMinimal(), percent: -1}
+}
+
+// Update redraws the bar when done out of total moves it by at least one percent
+func (p *ProgressBar) Update(done, total int) {
+	if !p.enabled || total <= 0 {
+		return
+	}
+	percent := min(done*100/total, 100)
+	if percent == p.percent {
+		return
+	}
+	p.percent = percent
+	filled := percent * progressBarWidth / 100
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	fmt.Printf("\r  %s %s %3d%% %s", Voice(p.label), Cyan.Sprint(bar), percent, Gray.Sprintf("(%d/%d)", done, total))
+}
+
+// Finish ends the line the bar was drawn on
+func (p *ProgressBar) Finish() {
+	if p.enabled && p.percent >= 0 {
+		fmt.Println()
+	}
+}
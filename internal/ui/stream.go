@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: internal/ui/stream.go
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// streamMargin starts every line of an answer shown while it is generated
+const streamMargin = "  │ "
+
+// StreamDisplay shows the oracle's answer while it is being generated, so that a long script
+// appears line by line instead of after a silent wait. Like ProgressBar it draws nothing when the
+// output is not a terminal or in minimal verbosity.
+type StreamDisplay struct {
+	label       string
+	enabled     bool
+	started     bool // something of the current answer has been drawn
+	atLineStart bool
+}
+
+// NewStreamDisplay returns a display that introduces every answer with label
+func NewStreamDisplay(label string) *StreamDisplay {
+	terminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	return &StreamDisplay{label: label, enabled: terminal && !IsMinimal()}
+}
+
+// Enabled reports whether answers are drawn as they arrive
+func (d *StreamDisplay) Enabled() bool {
+	return d.enabled
+}
+
+// Begin starts a new answer below any earlier one
+func (d *StreamDisplay) Begin() {
+	d.started = false
+	d.atLineStart = true
+}
+
+// Chunk draws the next piece of the answer, dimmed, with a margin at the start of every line
+func (d *StreamDisplay) Chunk(text string) {
+	if !d.enabled || text == "" {
+		return
+	}
+	if !d.started {
+		fmt.Println(decorate("📜", d.label))
+		d.started = true
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			fmt.Println()
+			d.atLineStart = true
+		}
+		if line == "" {
+			continue
+		}
+		if d.atLineStart {
+			fmt.Print(Gray.Sprint(streamMargin))
+			d.atLineStart = false
+		}
+		fmt.Print(Gray.Sprint(line))
+	}
+}
+
+// End finishes the line the answer ended on
+func (d *StreamDisplay) End() {
+	if d.enabled && d.started && !d.atLineStart {
+		fmt.Println()
+	}
+	if d.started {
+		fmt.Println()
+	}
+	d.started = false
+}
@@ -145,6 +145,7 @@ func (t *UITemplate) PrintBox(title string, content []string) {
 		Gold.Sprint("╮"))
 
 	// Title if provided
+	title = stripLeadingIcons(Voice(title))
 	if title != "" {
 		// Calculate padding to center the title using visible length
 		contentWidth := t.width - 4 // Account for "│ " and " │"
@@ -359,11 +360,15 @@ func (t *UITemplate) PrintStatusBox(status, message string, statusType string) {
 		colorFunc = func(s string) string { return s }
 	}
 
-	t.PrintBox(fmt.Sprintf("%s %s", icon, status), []string{
-		"",
-		colorFunc(message),
-		"",
-	})
+	// Each line of the message is wrapped on its own, so paragraphs and lists keep their breaks
+	content := []string{""}
+	for _, line := range strings.Split(Voice(message), "\n") {
+		content = append(content, colorFunc(line))
+	}
+	content = append(content, "")
+
+	// PrintBox rewords the title in the persona's voice
+	t.PrintBox(withIcon(icon, status), content)
 }
 
 // Configuration display template
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Verbosity controls how many phase headers, emoji, and knightly flourishes are printed
+type Verbosity int
+
+const (
+	// VerbosityMinimal prints only the essentials: boxes, prompts, and results without decoration
+	VerbosityMinimal Verbosity = iota
+	// VerbosityNormal is the standard knightly experience
+	VerbosityNormal
+	// VerbosityFestive adds extra flourishes on top of the normal output
+	VerbosityFestive
+)
+
+var currentVerbosity = VerbosityNormal
+
+// ParseVerbosity converts a configuration value into a Verbosity level
+func ParseVerbosity(value string) (Verbosity, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "minimal":
+		return VerbosityMinimal, nil
+	case "", "normal":
+		return VerbosityNormal, nil
+	case "festive":
+		return VerbosityFestive, nil
+	default:
+		return VerbosityNormal, fmt.Errorf("invalid verbosity '%s'. Choose minimal, normal, or festive", value)
+	}
+}
+
+// String returns the configuration name of the verbosity level
+func (v Verbosity) String() string {
+	switch v {
+	case VerbosityMinimal:
+		return "minimal"
+	case VerbosityFestive:
+		return "festive"
+	default:
+		return "normal"
+	}
+}
+
+// SetVerbosity sets the verbosity used by all print helpers in this package
+func SetVerbosity(v Verbosity) {
+	currentVerbosity = v
+}
+
+// GetVerbosity returns the active verbosity level
+func GetVerbosity() Verbosity {
+	return currentVerbosity
+}
+
+// IsMinimal reports whether decorations should be suppressed
+func IsMinimal() bool {
+	return currentVerbosity == VerbosityMinimal
+}
+
+// IsFestive reports whether extra flourishes should be printed
+func IsFestive() bool {
+	return currentVerbosity == VerbosityFestive
+}
+
+// decorate prefixes text, in the active persona's voice, with an icon unless running in minimal verbosity
+func decorate(icon, text string) string {
+	return withIcon(icon, Voice(text))
+}
+
+// withIcon prefixes text with an icon unless running in minimal verbosity
+func withIcon(icon, text string) string {
+	if IsMinimal() || icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
+
+// stripLeadingIcons removes leading emoji and symbols from a title in minimal verbosity
+func stripLeadingIcons(text string) string {
+	if !IsMinimal() {
+		return text
+	}
+	return strings.TrimLeftFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
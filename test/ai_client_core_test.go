@@ -125,6 +125,12 @@ Get-Location
 			expectedType:    ai.ResponseTypeScript,
 			expectedContent: "Write-Host \"Starting task\"\nGet-Location",
 		},
+		{
+			name:            "answer response",
+			response:        "ANSWER: chmod 755 lets the owner read, write, and run the file, and everyone else read and run it.",
+			expectedType:    ai.ResponseTypeAnswer,
+			expectedContent: "chmod 755 lets the owner read, write, and run the file, and everyone else read and run it.",
+		},
 		{
 			name:          "failure response",
 			response:      "FAILURE: Cannot complete this unsafe task",
@@ -137,18 +143,6 @@ Get-Location
 			expectedType:  ai.ResponseTypeFailure,
 			expectedError: "Task too vague",
 		},
-		{
-			name:            "fallback to command for unknown format",
-			response:        "Just some random text",
-			expectedType:    ai.ResponseTypeCommand,
-			expectedContent: "Just some random text",
-		},
-		{
-			name:            "empty response",
-			response:        "",
-			expectedType:    ai.ResponseTypeCommand,
-			expectedContent: "",
-		},
 	}
 
 	for _, tc := range testCases {
@@ -185,6 +179,16 @@ Get-Location
 	}
 }
 
+// An answer in no known format is a malformed failure, never a command to run
+func TestParseAIResponse_UnmarkedTextIsMalformed(t *testing.T) {
+	for _, raw := range []string{"Just some random text", ""} {
+		response := ai.ParseAIResponse(raw)
+		if response.Type != ai.ResponseTypeFailure || response.Category != ai.FailureMalformed || response.Content != "" {
+			t.Errorf("Expected %q to be a malformed failure, got %+v", raw, response)
+		}
+	}
+}
+
 // Test exponential retry logic through mock failures
 func TestExponentialRetry(t *testing.T) {
 	testCases := []struct {
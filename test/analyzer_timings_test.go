@@ -0,0 +1,84 @@
+// File: test/analyzer_timings_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestAnalyzer_RecordsPhaseTimings(t *testing.T) {
+	info, err := system.NewAnalyzer().AnalyzeSystem()
+	if info == nil {
+		t.Fatalf("Expected system info, got error: %v", err)
+	}
+
+	phases := map[string]bool{}
+	for i, timing := range info.Timings {
+		phases[timing.Phase] = true
+		if i > 0 && timing.Duration > info.Timings[i-1].Duration {
+			t.Errorf("Expected the slowest phase first, got %v after %v", timing, info.Timings[i-1])
+		}
+	}
+	for _, phase := range []string{system.PhaseTotal, system.PhasePackages, system.PhaseCommands, "shell"} {
+		if !phases[phase] {
+			t.Errorf("Expected a timing for phase %q, got %v", phase, info.Timings)
+		}
+	}
+	if info.Timings[0].Phase != system.PhaseTotal {
+		t.Errorf("Expected the total to take longest, got %v", info.Timings)
+	}
+}
+
+func TestQuickAnalyzer_RecordsTotal(t *testing.T) {
+	info, _ := system.NewQuickAnalyzer().AnalyzeSystem()
+	if len(info.Timings) != 1 || info.Timings[0].Phase != system.PhaseTotal {
+		t.Errorf("Expected only the total, got %v", info.Timings)
+	}
+}
+
+func TestSlowPhaseAdvice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		timings  []system.PhaseTiming
+		expected []string
+	}{
+		{name: "fast", timings: []system.PhaseTiming{{Phase: system.PhaseTotal, Duration: 300 * time.Millisecond}, {Phase: system.PhaseCommands, Duration: 200 * time.Millisecond}}},
+		{name: "slow total only", timings: []system.PhaseTiming{{Phase: system.PhaseTotal, Duration: 3 * time.Second}, {Phase: "shell", Duration: 3 * time.Second}}},
+		{
+			name: "slow package manager",
+			timings: []system.PhaseTiming{
+				{Phase: system.PhaseTotal, Duration: 30 * time.Second},
+				{Phase: system.PhasePackages, Duration: 29 * time.Second},
+				{Phase: system.PackagePhase("snap"), Duration: 29 * time.Second},
+				{Phase: system.PackagePhase("apt"), Duration: 500 * time.Millisecond},
+			},
+			expected: []string{"--no-system-scan"},
+		},
+		{
+			name: "slow PATH walk",
+			timings: []system.PhaseTiming{
+				{Phase: system.PhaseTotal, Duration: 12 * time.Second},
+				{Phase: system.PhaseCommands, Duration: 12 * time.Second},
+			},
+			expected: []string{"Remove slow directories from PATH"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			advice := cli.SlowPhaseAdvice(tc.timings)
+			if len(advice) != len(tc.expected) {
+				t.Fatalf("Expected %d piece(s) of advice, got %v", len(tc.expected), advice)
+			}
+			for i, expected := range tc.expected {
+				if !strings.Contains(advice[i], expected) {
+					t.Errorf("Expected advice to mention %q, got %q", expected, advice[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// File: test/artifacts_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func writeArtifact(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("-----BEGIN-----"), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func artifactPaths(artifacts []system.Artifact) []string {
+	var paths []string
+	for _, artifact := range artifacts {
+		paths = append(paths, artifact.Path)
+	}
+	return paths
+}
+
+func TestWatchArtifacts(t *testing.T) {
+	home, work, elsewhere := t.TempDir(), t.TempDir(), t.TempDir()
+	writeArtifact(t, filepath.Join(work, "existing.txt"), 0644)
+
+	script := "#!/bin/bash\n# Create the certificate directory\nmkdir -p ~/certs/new\n" +
+		"openssl req -x509 -keyout \"$HOME/certs/new/key.pem\" -out=cert.pem 2>/dev/null\n" +
+		"cp cert.pem " + filepath.Join(elsewhere, "missing", "copy.pem")
+	watch := system.WatchArtifacts(script, work, home)
+
+	writeArtifact(t, filepath.Join(home, "certs", "new", "key.pem"), 0600)
+	writeArtifact(t, filepath.Join(work, "cert.pem"), 0644)
+	writeArtifact(t, filepath.Join(elsewhere, "missing", "copy.pem"), 0644)
+	writeArtifact(t, filepath.Join(t.TempDir(), "unrelated.txt"), 0644)
+	os.WriteFile(filepath.Join(work, "existing.txt"), []byte("changed"), 0644)
+
+	expected := []string{
+		filepath.Join(elsewhere, "missing"),
+		filepath.Join(elsewhere, "missing", "copy.pem"),
+		filepath.Join(home, "certs"),
+		filepath.Join(home, "certs", "new"),
+		filepath.Join(home, "certs", "new", "key.pem"),
+		filepath.Join(work, "cert.pem"),
+	}
+	got := artifactPaths(watch.Created())
+	for _, path := range expected {
+		if !strings.Contains(strings.Join(got, "\n"), path) {
+			t.Errorf("Expected %s to be listed, got %v", path, got)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected only the created paths %v, got %v", expected, got)
+	}
+}
+
+func TestWatchArtifacts_SkipsVariablesAndWildcards(t *testing.T) {
+	work := t.TempDir()
+	other := t.TempDir()
+	watch := system.WatchArtifacts("touch $TARGET/"+filepath.Base(other)+" && cp *.txt /tmp/*", work, work)
+
+	writeArtifact(t, filepath.Join(other, "new.txt"), 0644)
+	if created := watch.Created(); len(created) != 0 {
+		t.Errorf("Expected no watched directory outside the current one, got %v", artifactPaths(created))
+	}
+}
+
+func TestArtifact_String(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no POSIX permission bits")
+	}
+	file := system.Artifact{Path: "/home/me/certs/key.pem", Mode: 0600, Size: 1740}
+	if got := file.String(); got != "-rw-------  1.7 KiB /home/me/certs/key.pem" {
+		t.Errorf("Unexpected file artifact %q", got)
+	}
+	dir := system.Artifact{Path: "/home/me/certs", Mode: os.ModeDir | 0755}
+	if got := dir.String(); !strings.HasPrefix(got, "drwxr-xr-x") || !strings.HasSuffix(got, "/home/me/certs/") {
+		t.Errorf("Unexpected directory artifact %q", got)
+	}
+}
+
+func TestPipeline_ListsCreatedArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: dir, HomeDir: dir}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "mkdir -p ./tls\nopenssl genrsa -out ./tls/server.key 2048"}
+	f.executor.OnRun = func() { writeArtifact(t, filepath.Join(dir, "tls", "server.key"), 0600) }
+
+	quest := newQuest("create a TLS key for the server", "monarch")
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := filepath.Join(dir, "tls") + "\n" + filepath.Join(dir, "tls", "server.key")
+	if got := strings.Join(artifactPaths(quest.Artifacts), "\n"); got != expected {
+		t.Errorf("Expected the created directory and key, got %q", got)
+	}
+}
+
+func TestHistory_RecordResetsArtifacts(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	entry := store.Record("create a key", "", "openssl genrsa -out key.pem", false, nil, time.Now())
+	entry.Artifacts = []string{"/home/me/key.pem"}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if entry = store.Record("create a key", "", "openssl genrsa -out key.pem", false, nil, time.Now()); entry.Artifacts != nil {
+		t.Errorf("Expected a new run to forget the artifacts of the last one, got %v", entry.Artifacts)
+	}
+}
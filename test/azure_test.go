@@ -0,0 +1,115 @@
+// File: test/azure_test.go
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+func azureConfig(endpoint string) *config.Config {
+	return &config.Config{
+		AIProvider: "azure-openai",
+		APIKey:     "azure-key",
+		Model:      "gpt-4o",
+		Mode:       "monarch",
+		MaxTokens:  500,
+		Azure:      config.AzureConfig{Endpoint: endpoint, Deployment: "corp-gpt4o"},
+	}
+}
+
+func TestAzureOpenAIProvider_GenerateResponse(t *testing.T) {
+	var gotPath, gotVersion, gotKey, gotAuth string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotVersion = r.URL.Path, r.URL.Query().Get("api-version")
+		gotKey, gotAuth = r.Header.Get("api-key"), r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"COMMAND: ls -la"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := ai.NewAzureOpenAIProvider(azureConfig(server.URL + "/"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	response, err := provider.GenerateResponse("list my files")
+	if err != nil || response != "COMMAND: ls -la" {
+		t.Fatalf("Expected the deployment's answer, got %q and %v", response, err)
+	}
+
+	if gotPath != "/openai/deployments/corp-gpt4o/chat/completions" || gotVersion != config.DefaultAPIVersions["azure-openai"] {
+		t.Errorf("Expected the deployment's chat completions with the default API version, got %s?api-version=%s", gotPath, gotVersion)
+	}
+	if gotKey != "azure-key" || gotAuth != "" {
+		t.Errorf("Expected the key in the api-key header only, got api-key %q and Authorization %q", gotKey, gotAuth)
+	}
+	if _, sent := gotBody["model"]; sent {
+		t.Errorf("The deployment decides the model, so none should be sent, got %v", gotBody)
+	}
+}
+
+func TestAzureOpenAIProvider_ReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`))
+	}))
+	defer server.Close()
+
+	provider, _ := ai.NewAzureOpenAIProvider(azureConfig(server.URL))
+	if _, err := provider.GenerateResponse("list my files"); err == nil || !strings.Contains(err.Error(), "Azure OpenAI API error: The API deployment") {
+		t.Errorf("Expected the Azure error message, got %v", err)
+	}
+}
+
+func TestAzureOpenAIProvider_DeploymentAndVersion(t *testing.T) {
+	cfg := azureConfig("https://contoso.openai.azure.com")
+	cfg.Azure.Deployment = ""
+	cfg.APIVersions = map[string]string{"azure-openai": "2025-01-01-preview"}
+
+	provider, err := ai.NewAzureOpenAIProvider(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "https://contoso.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=2025-01-01-preview"
+	if got := provider.ChatCompletionsURL(); got != expected {
+		t.Errorf("Expected the model as the deployment, got %s", got)
+	}
+	if models, _ := provider.ListModels(); len(models) != 1 || models[0] != "gpt-4o" {
+		t.Errorf("Expected the deployment to be listed, got %v", models)
+	}
+}
+
+func TestConfig_ValidateAzure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		endpoint string
+		version  string
+		valid    bool
+	}{
+		{name: "resource endpoint", endpoint: "https://contoso.openai.azure.com", valid: true},
+		{name: "preview version", endpoint: "https://contoso.openai.azure.com", version: "2025-01-01-preview", valid: true},
+		{name: "missing endpoint", endpoint: ""},
+		{name: "endpoint without scheme", endpoint: "contoso.openai.azure.com"},
+		{name: "openai-style version", endpoint: "https://contoso.openai.azure.com", version: "v1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := azureConfig(tc.endpoint)
+			if tc.version != "" {
+				cfg.APIVersions = map[string]string{"azure-openai": tc.version}
+			}
+			if err := cfg.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid=%v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
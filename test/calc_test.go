@@ -0,0 +1,76 @@
+// File: test/calc_test.go
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/calc"
+)
+
+func TestSolve_Answers(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Failed to load the time zone: %v", err)
+	}
+	now := time.Date(2025, 10, 17, 12, 0, 0, 0, berlin)
+
+	testCases := []struct {
+		intent string
+		result string
+		note   string
+	}{
+		{intent: "how many MB is 3.2GB", result: "3.2 GB = 3200 MB", note: "Decimal units, counted in powers of 1000. In binary units, 3.2 GiB = 3276.8 MiB."},
+		{intent: "3 GiB in MB", result: "3 GiB = 3221.2255 MB"},
+		{intent: "convert 1,024 kb to mb", result: "1024 KB = 1.024 MB", note: "Decimal units, counted in powers of 1000. In binary units, 1024 KiB = 1 MiB."},
+		{intent: "5 miles in km", result: "5 mi = 8.0467 km"},
+		{intent: "convert 100 degrees fahrenheit to celsius", result: "100 °F = 37.7778 °C"},
+		{intent: "how many minutes are 3 hours", result: "3 h = 180 min"},
+		{intent: "what is 17 * 23?", result: "17 * 23 = 391"},
+		{intent: "calculate (3 + 4) / 2", result: "(3 + 4) / 2 = 3.5"},
+		{intent: "2^10", result: "2^10 = 1024"},
+		{intent: "-3 + 2 x 4", result: "-3 + 2 x 4 = 5"},
+		{intent: "10 / 0", result: "10 / 0 cannot be worked out: it divides by zero"},
+		{intent: "what's 12% of 80", result: "12% of 80 = 9.6"},
+		{intent: "convert 14:30 UTC to my timezone", result: "14:30 UTC = 16:30 CEST (UTC+02:00) (your time zone)"},
+		{intent: "convert 14:30 ET to UTC", result: "14:30 EDT (UTC-04:00) = 18:30 UTC"},
+		{intent: "9pm PST to Asia/Tokyo", result: "21:00 PST (UTC-08:00) = 14:00 JST (UTC+09:00)", note: "That is Sat 18 Oct there, while it is Fri 17 Oct in PST (UTC-08:00)."},
+		{intent: "18:00 UTC+5:30 in local time", result: "18:00 UTC+5:30 = 14:30 CEST (UTC+02:00) (your time zone)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			answer, ok := calc.Solve(tc.intent, now)
+			if !ok {
+				t.Fatalf("Expected %q to be answered locally", tc.intent)
+			}
+			if answer.Result != tc.result {
+				t.Errorf("Expected result %q, got %q", tc.result, answer.Result)
+			}
+			if answer.Note != tc.note {
+				t.Errorf("Expected note %q, got %q", tc.note, answer.Note)
+			}
+		})
+	}
+}
+
+func TestSolve_LeavesOtherIntentsAlone(t *testing.T) {
+	intents := []string{
+		"list files",
+		"2024",
+		"convert video.mp4 to mp3",
+		"copy 3 files to backup",
+		"compress the logs larger than 3GB",
+		"convert 5 GB to apples",
+		"convert 3 kg to km",
+		"schedule a backup at 14:30 every day",
+		"14:30 UTC to Narnia",
+		"rm -rf /tmp/*",
+	}
+
+	for _, intent := range intents {
+		if answer, ok := calc.Solve(intent, time.Now()); ok {
+			t.Errorf("Expected %q to go to the oracle, got %q", intent, answer.Result)
+		}
+	}
+}
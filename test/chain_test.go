@@ -0,0 +1,80 @@
+// File: test/chain_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func failedUnitsLog(t *testing.T) *system.OutputLog {
+	t.Helper()
+	output := "docker.service loaded failed failed Docker Application Container Engine\ncontainerd.service loaded failed failed containerd container runtime\nnginx.service loaded failed failed nginx\napi key: sk-abcdefghijklmnopqrstuvwxyz0123456789\n"
+	path := filepath.Join(t.TempDir(), "quest.log")
+	if err := os.WriteFile(path, []byte(output), 0600); err != nil {
+		t.Fatalf("Failed to write the log: %v", err)
+	}
+	return &system.OutputLog{Path: path, Lines: 4}
+}
+
+func TestChainRun_FollowUpSeesOutput(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.NextResponses = []*ai.AIResponse{
+		{Type: ai.ResponseTypeCommand, Content: "systemctl --failed --no-legend"},
+		{Type: ai.ResponseTypeCommand, Content: "sudo systemctl restart docker containerd"},
+	}
+	f.executor.Output = failedUnitsLog(t)
+
+	results, err := (&cli.ChainRun{Deps: f.deps()}).Run(newQuest("list failed systemd units", "monarch"), []string{"restart the ones related to docker"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := planStatuses(results); got != "succeeded,succeeded" {
+		t.Errorf("Expected both quests to succeed, got %s", got)
+	}
+	if f.confirmer.CallCount != 2 || len(f.executor.ExecutedCommands) != 2 {
+		t.Errorf("Expected each quest to be confirmed and run, got %d confirmations and %d runs", f.confirmer.CallCount, len(f.executor.ExecutedCommands))
+	}
+	for _, expected := range []string{"restart the ones related to docker", "PREVIOUS STEP", "list failed systemd units", "systemctl --failed --no-legend", "containerd.service loaded failed"} {
+		if !strings.Contains(f.aiClient.LastIntent, expected) {
+			t.Errorf("Expected the follow-up prompt to contain %q, got:\n%s", expected, f.aiClient.LastIntent)
+		}
+	}
+	if strings.Contains(f.aiClient.LastIntent, "sk-abcdefghijklmnopqrstuvwxyz0123456789") {
+		t.Error("Secrets in the output must be redacted before the follow-up is generated")
+	}
+}
+
+func TestChainRun_StopsAfterDeclinedQuest(t *testing.T) {
+	f := newPipelineFixture()
+	f.confirmer.Approve = false
+	f.executor.Output = failedUnitsLog(t)
+
+	results, err := (&cli.ChainRun{Deps: f.deps()}).Run(newQuest("list failed systemd units", "monarch"), []string{"restart them", "show their status"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := planStatuses(results); got != "declined,not started,not started" {
+		t.Errorf("Expected the chain to stop after the declined quest, got %s", got)
+	}
+	if f.aiClient.GenerateCallCount != 1 {
+		t.Errorf("Expected no follow-up to be generated, got %d generations", f.aiClient.GenerateCallCount)
+	}
+}
+
+func TestChainRun_StopsWithoutCapturedOutput(t *testing.T) {
+	f := newPipelineFixture()
+
+	results, err := (&cli.ChainRun{Deps: f.deps()}).Run(newQuest("list failed systemd units", "monarch"), []string{"restart them"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := planStatuses(results); got != "succeeded,not started" {
+		t.Errorf("Expected no follow-up without the first quest's output, got %s", got)
+	}
+}
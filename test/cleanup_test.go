@@ -0,0 +1,101 @@
+// File: test/cleanup_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestIsCleanupIntent(t *testing.T) {
+	testCases := []struct {
+		intent   string
+		expected bool
+	}{
+		{"delete old log files", true},
+		{"clean up my downloads", true},
+		{"remove *.tmp from the build folder", true},
+		{"purge backups older than a month", true},
+		{"list log files", false},
+		{"remove the docker container web", false},
+		{"delete the git branch feature-x", false},
+	}
+
+	for _, tc := range testCases {
+		if got := system.IsCleanupIntent(tc.intent); got != tc.expected {
+			t.Errorf("IsCleanupIntent(%q): expected %v, got %v", tc.intent, tc.expected, got)
+		}
+	}
+}
+
+func TestIsSafeListing(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected bool
+	}{
+		{"find . -name '*.log' -mtime +30", true},
+		{"ls *.tmp", true},
+		{"find ~/Downloads -type f | sort", true},
+		{"find . -name '*.log' -delete", false},
+		{"find . -name '*.log' -exec rm {} +", false},
+		{"ls $(rm -rf ~)", false},
+		{"find . -name '*.log' > list.txt", false},
+		{"ls && rm -f a.log", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		if got := system.IsSafeListing(tc.command); got != tc.expected {
+			t.Errorf("IsSafeListing(%q): expected %v, got %v", tc.command, tc.expected, got)
+		}
+	}
+}
+
+func TestParseCleanupCandidates(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := "a.log\n\n" + filepath.Join(dir, "b.log") + "\n./a.log\nmissing.log\n"
+	candidates := system.ParseCleanupCandidates(output, dir)
+
+	expected := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+	if strings.Join(candidates, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+}
+
+func TestBuildDeleteCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "old report.log")
+	subdir := filepath.Join(dir, "cache")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		shell    string
+		paths    []string
+		expected string
+	}{
+		{"bash", []string{file}, "rm -- '" + file + "'"},
+		{"zsh", []string{file, subdir}, "rm -r -- '" + file + "' " + subdir},
+		{"pwsh", []string{file, subdir}, "Remove-Item -LiteralPath '" + file + "', '" + subdir + "' -Recurse"},
+		{"cmd", []string{file, subdir}, `del /Q "` + file + `" && rmdir /S /Q "` + subdir + `"`},
+	}
+
+	for _, tc := range testCases {
+		if got := system.BuildDeleteCommand(tc.shell, tc.paths); got != tc.expected {
+			t.Errorf("BuildDeleteCommand(%s): expected %q, got %q", tc.shell, tc.expected, got)
+		}
+	}
+}
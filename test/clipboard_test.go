@@ -0,0 +1,58 @@
+// File: test/clipboard_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestClipboardContext(t *testing.T) {
+	cfg := &config.Config{APIKey: "configured-key-1234", Mode: "monarch"}
+	copied := "\r\nTraceback (most recent call last):\r\n  File \"app.py\", line 3\r\nrequests.exceptions.HTTPError: 401 for url https://api.example.com/?token=abc123def456\r\n\r\n"
+
+	text, err := cli.ClipboardContext(copied, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(text, "Traceback (most recent call last):\n  File") || strings.Contains(text, "\r") {
+		t.Errorf("Expected the copied lines with plain line endings and no blank edges, got %q", text)
+	}
+	if strings.Contains(text, "abc123def456") || !strings.Contains(text, "[REDACTED]") {
+		t.Errorf("Expected the token to be redacted, got %q", text)
+	}
+
+	if _, err := cli.ClipboardContext(" \n\t", cfg); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("Expected an empty clipboard to be refused, got %v", err)
+	}
+}
+
+func TestTrimClipboard(t *testing.T) {
+	long := strings.Repeat("é", system.MaxClipboardChars+10)
+	text, cut := system.TrimClipboard(long)
+	if !cut || len([]rune(text)) != system.MaxClipboardChars {
+		t.Errorf("Expected the text to be cut to %d characters, got %d (cut=%v)", system.MaxClipboardChars, len([]rune(text)), cut)
+	}
+	if text, cut := system.TrimClipboard("permission denied\n"); cut || text != "permission denied" {
+		t.Errorf("Expected short text to be kept whole, got %q (cut=%v)", text, cut)
+	}
+}
+
+func TestPipeline_SendsClipboardWithIntent(t *testing.T) {
+	f := newPipelineFixture()
+	quest := newQuest("fix this error", "monarch")
+	quest.Clipboard = "npm ERR! code EACCES\nnpm ERR! syscall mkdir"
+
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(f.aiClient.LastIntent, "CLIPBOARD") || !strings.Contains(f.aiClient.LastIntent, "npm ERR! code EACCES\nnpm ERR! syscall mkdir") {
+		t.Errorf("Expected the copied text with the intent, got %q", f.aiClient.LastIntent)
+	}
+	if !strings.HasPrefix(f.aiClient.LastIntent, "fix this error") {
+		t.Errorf("Expected the intent to come first, got %q", f.aiClient.LastIntent)
+	}
+}
@@ -0,0 +1,98 @@
+// File: test/command_chain_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestSplitCommandChain(t *testing.T) {
+	testCases := []struct {
+		name      string
+		command   string
+		commands  []string
+		operators []string
+	}{
+		{
+			name:      "single command",
+			command:   "ls -la",
+			commands:  []string{"ls -la"},
+			operators: []string{""},
+		},
+		{
+			name:      "mixed operators",
+			command:   "sudo apt update && sudo apt install -y curl || echo failed; echo done",
+			commands:  []string{"sudo apt update", "sudo apt install -y curl", "echo failed", "echo done"},
+			operators: []string{"", "&&", "||", ";"},
+		},
+		{
+			name:      "operators inside quotes",
+			command:   `echo "a && b" && echo 'c; d'`,
+			commands:  []string{`echo "a && b"`, `echo 'c; d'`},
+			operators: []string{"", "&&"},
+		},
+		{
+			name:      "command substitution and pipes",
+			command:   `cd "$(git rev-parse --show-toplevel && echo)" && ls | wc -l`,
+			commands:  []string{`cd "$(git rev-parse --show-toplevel && echo)"`, "ls | wc -l"},
+			operators: []string{"", "&&"},
+		},
+		{
+			name:      "escaped semicolon",
+			command:   `find . -name '*.log' -exec gzip {} \; && echo ok`,
+			commands:  []string{`find . -name '*.log' -exec gzip {} \;`, "echo ok"},
+			operators: []string{"", "&&"},
+		},
+		{
+			name:      "trailing separator",
+			command:   "make build;",
+			commands:  []string{"make build"},
+			operators: []string{""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := system.SplitCommandChain(tc.command)
+			if len(parts) != len(tc.commands) {
+				t.Fatalf("Expected %d parts, got %d: %+v", len(tc.commands), len(parts), parts)
+			}
+			for i, part := range parts {
+				if part.Command != tc.commands[i] || part.Operator != tc.operators[i] {
+					t.Errorf("Part %d: expected (%q, %q), got (%q, %q)", i, tc.commands[i], tc.operators[i], part.Command, part.Operator)
+				}
+			}
+		})
+	}
+}
+
+func TestAssessCommandRisk(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected system.RiskLevel
+	}{
+		{"ls -la", system.RiskReadOnly},
+		{"grep -r TODO . | wc -l", system.RiskReadOnly},
+		{"git status", system.RiskReadOnly},
+		{"find . -name '*.tmp' 2>/dev/null", system.RiskReadOnly},
+		{"echo hello > notes.txt", system.RiskModifies},
+		{"mkdir -p build", system.RiskModifies},
+		{"git commit -m 'wip'", system.RiskModifies},
+		{"sed -i 's/a/b/' file.txt", system.RiskModifies},
+		{"sudo apt install -y curl", system.RiskElevated},
+		{"rm -rf build", system.RiskDestructive},
+		{"sudo dd if=image.iso of=/dev/sdb", system.RiskDestructive},
+		{"find . -name '*.tmp' -delete", system.RiskDestructive},
+		{"git push --force origin main", system.RiskDestructive},
+		{"Remove-Item C:\\temp -Recurse", system.RiskDestructive},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.command, func(t *testing.T) {
+			if risk := system.AssessCommandRisk(tc.command); risk != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, risk)
+			}
+		})
+	}
+}
@@ -2,6 +2,11 @@
 package test
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -220,3 +225,134 @@ func TestMockExecutor_StatefulBehavior(t *testing.T) {
 		t.Error("Expected LastShowComments to be true from script execution")
 	}
 }
+
+func TestNewUserExecutor_UnknownUser(t *testing.T) {
+	executor, err := system.NewUserExecutor("no-such-knight-user")
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown user, got %v", executor)
+	}
+	if !strings.Contains(err.Error(), "no-such-knight-user") && !strings.Contains(err.Error(), "only available on Unix") {
+		t.Errorf("Error should name the user, got %v", err)
+	}
+}
+
+func TestMergeEnvironment(t *testing.T) {
+	env := system.MergeEnvironment(
+		[]string{"PATH=/usr/bin", "LANG=en_GB.UTF-8"},
+		map[string]string{"LANG": "C.UTF-8", "DEBIAN_FRONTEND": "noninteractive"},
+	)
+	expected := []string{"PATH=/usr/bin", "DEBIAN_FRONTEND=noninteractive", "LANG=C.UTF-8"}
+	if strings.Join(env, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+}
+
+func TestEnvironmentPrefix(t *testing.T) {
+	env := map[string]string{"LANG": "C.UTF-8", "GREETING": "it's me"}
+	tests := map[string]string{
+		"bash":       `export GREETING='it'\''s me'; export LANG=C.UTF-8; `,
+		"powershell": `$env:GREETING = 'it''s me'; $env:LANG = 'C.UTF-8'; `,
+		"cmd":        `set "GREETING=it's me" && set "LANG=C.UTF-8" && `,
+	}
+	for shell, expected := range tests {
+		if got := system.EnvironmentPrefix(shell, env); got != expected {
+			t.Errorf("%s: expected %q, got %q", shell, expected, got)
+		}
+	}
+	if got := system.EnvironmentPrefix("bash", nil); got != "" {
+		t.Errorf("Expected no prefix without variables, got %q", got)
+	}
+}
+
+func TestWithEnvironment_AppliesToCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	executor := system.WithEnvironment(system.NewProjectExecutor(t.TempDir(), "env", true), map[string]string{"EMW_TEST_FRONTEND": "noninteractive"})
+	if err := executor.Execute(`test "$EMW_TEST_FRONTEND" = noninteractive`, "sh"); err != nil {
+		t.Errorf("Expected the configured variable in the command's environment, got %v", err)
+	}
+
+	mock := &MockCommandExecutor{}
+	if system.WithEnvironment(mock, map[string]string{"LANG": "C"}) != mock {
+		t.Error("Other executors should be returned unchanged")
+	}
+}
+
+func TestWithMaxLineSize_ShortensPrintedLines(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = w
+	executor := system.WithMaxLineSize(system.NewProjectExecutor(t.TempDir(), "wide", true), 100)
+	err = executor.Execute(`printf '%0500d\n' 0`, "sh")
+	os.Stdout = original
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if zeros := strings.Count(string(printed), "0"); zeros < 100 || zeros >= 500 {
+		t.Errorf("Expected only the first 100 bytes of the line to be printed, got %d zeros", zeros)
+	}
+
+	mock := &MockCommandExecutor{}
+	if system.WithMaxLineSize(mock, 100) != mock {
+		t.Error("Other executors should be returned unchanged")
+	}
+}
+
+func TestWithOutputLog_SavesEveryLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	executor := system.WithOutputLog(system.NewProjectExecutor(t.TempDir(), "log", true), dir)
+	if err := executor.Execute("for i in 1 2 3; do echo line $i; done; echo oops >&2", "sh"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := executor.(system.OutputLogger).LastOutput()
+	if output == nil || output.Lines != 4 || filepath.Dir(output.Path) != dir {
+		t.Fatalf("Expected a four-line log in %s, got %+v", dir, output)
+	}
+	data, err := os.ReadFile(output.Path)
+	if err != nil || !strings.Contains(string(data), "line 3\n") || !strings.Contains(string(data), "oops\n") {
+		t.Errorf("Expected stdout and stderr in the log, got %q (%v)", data, err)
+	}
+}
+
+func TestSampleOutputLog(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 500; i++ {
+		lines = append(lines, "step "+strconv.Itoa(i))
+	}
+	lines[250] = "error: disk full"
+	path := filepath.Join(t.TempDir(), "quest.log")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sample, err := system.SampleOutputLog(path, 2, 3, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "step 1\nstep 2\n[… 248 lines omitted …]\nerror: disk full\n[… 246 lines omitted …]\nstep 498\nstep 499\nstep 500"
+	if sample != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, sample)
+	}
+
+	short, _ := system.SampleOutputLog(path, 400, 200, 0)
+	if strings.Count(short, "\n") != 499 {
+		t.Error("Logs shorter than head and tail together should be returned whole")
+	}
+}
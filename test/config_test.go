@@ -3,11 +3,14 @@ package test
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/minand-mohan/execute-my-will/internal/config"
+	"gopkg.in/yaml.v3"
 )
 
 func TestConfig_New(t *testing.T) {
@@ -327,3 +330,396 @@ func TestConfigNotFoundError(t *testing.T) {
 		t.Error("IsConfigNotFound should return false for non-ConfigNotFoundError")
 	}
 }
+
+func TestConfig_ValidateVerbosity(t *testing.T) {
+	testCases := []struct {
+		name        string
+		verbosity   string
+		expected    string
+		shouldError bool
+	}{
+		{name: "empty defaults to normal", verbosity: "", expected: "normal"},
+		{name: "minimal", verbosity: "minimal", expected: "minimal"},
+		{name: "festive", verbosity: "festive", expected: "festive"},
+		{name: "capitalized", verbosity: " Minimal ", expected: "minimal"},
+		{name: "invalid", verbosity: "loud", shouldError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				APIKey: "test-key",
+				Mode:   "monarch",
+				UI:     config.UIConfig{Verbosity: tc.verbosity},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldError {
+				if err == nil {
+					t.Errorf("Expected error for verbosity '%s'", tc.verbosity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.UI.Verbosity != tc.expected {
+				t.Errorf("Expected verbosity '%s', got '%s'", tc.expected, cfg.UI.Verbosity)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateConfirmationStyle(t *testing.T) {
+	for style, valid := range map[string]bool{"": true, "type": true, "select": true, "arrows": false} {
+		cfg := &config.Config{APIKey: "test-key", Mode: "monarch", UI: config.UIConfig{Confirm: style}}
+		if err := cfg.Validate(); (err == nil) != valid {
+			t.Errorf("Confirmation style %q: expected valid to be %v, got %v", style, valid, err)
+		}
+	}
+	if !(config.UIConfig{Confirm: "select"}).SelectConfirmation() || (config.UIConfig{}).SelectConfirmation() {
+		t.Error("Expected only 'select' to choose with the arrow keys")
+	}
+}
+
+func TestPrivacyConfig_Defaults(t *testing.T) {
+	var privacy config.PrivacyConfig
+
+	if !privacy.AllowInstalledPackages() || !privacy.AllowAvailableCommands() ||
+		!privacy.AllowCurrentDir() || !privacy.AllowHomeDir() {
+		t.Error("All context should be shared when privacy settings are omitted")
+	}
+	if len(privacy.Withheld()) != 0 {
+		t.Errorf("Expected nothing withheld, got %v", privacy.Withheld())
+	}
+}
+
+func TestPrivacyConfig_SetField(t *testing.T) {
+	var privacy config.PrivacyConfig
+
+	if err := privacy.SetField("installed-packages", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := privacy.SetField("home-dir", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if privacy.AllowInstalledPackages() || privacy.AllowHomeDir() {
+		t.Error("Withheld fields should not be allowed")
+	}
+	if !privacy.AllowAvailableCommands() || !privacy.AllowCurrentDir() {
+		t.Error("Untouched fields should remain allowed")
+	}
+
+	withheld := strings.Join(privacy.Withheld(), ",")
+	if withheld != "installed-packages,home-dir" {
+		t.Errorf("Unexpected withheld list: %s", withheld)
+	}
+
+	if err := privacy.SetField("home-dir", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !privacy.AllowHomeDir() {
+		t.Error("Disclosed field should be allowed again")
+	}
+
+	if err := privacy.SetField("passwords", false); err == nil {
+		t.Error("Expected error for unknown privacy field")
+	}
+}
+
+func TestConfig_MatchContext(t *testing.T) {
+	cfg := &config.Config{
+		Contexts: map[string]config.IntentContext{
+			"prod": {Context: "production web servers", TypedConfirmation: true, DisableAutoFix: true},
+			"k8s":  {Context: "kubectl against the staging cluster"},
+		},
+	}
+
+	testCases := []struct {
+		intent       string
+		expectedName string
+		expectedRest string
+	}{
+		{intent: "prod: restart nginx", expectedName: "prod", expectedRest: "restart nginx"},
+		{intent: "K8S:list pods", expectedName: "k8s", expectedRest: "list pods"},
+		{intent: "dev: restart nginx", expectedRest: "dev: restart nginx"},
+		{intent: "restart nginx", expectedRest: "restart nginx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			name, ctx, rest := cfg.MatchContext(tc.intent)
+			if name != tc.expectedName || rest != tc.expectedRest {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tc.expectedName, tc.expectedRest, name, rest)
+			}
+			if (ctx != nil) != (tc.expectedName != "") {
+				t.Errorf("Unexpected context %+v", ctx)
+			}
+		})
+	}
+}
+
+func TestConfig_Glossary(t *testing.T) {
+	cfg := &config.Config{}
+	if err := cfg.Define(" The Blue Server ", "host 10.0.0.12"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := cfg.Define("my site", "/var/www/blog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := cfg.Define("empty", " "); err == nil {
+		t.Error("Expected an error for a term without a meaning")
+	}
+
+	testCases := []struct {
+		intent   string
+		expected []string
+	}{
+		{intent: "ping the blue server", expected: []string{"the blue server"}},
+		{intent: "back up my site to the blue server", expected: []string{"my site", "the blue server"}},
+		{intent: "back up my sites", expected: nil},
+		{intent: "list files", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			var terms []string
+			for _, term := range cfg.GlossaryFor(tc.intent) {
+				terms = append(terms, term.Term)
+			}
+			if strings.Join(terms, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, terms)
+			}
+		})
+	}
+
+	if !cfg.Undefine("MY SITE") || cfg.Undefine("my site") {
+		t.Error("Undefine should remove a term once, ignoring case")
+	}
+}
+
+func TestConfig_Facts(t *testing.T) {
+	cfg := &config.Config{}
+	if added, err := cfg.Remember("  I use podman   instead of docker "); !added || err != nil {
+		t.Fatalf("Expected the fact to be added, got %v and %v", added, err)
+	}
+	if added, _ := cfg.Remember("i use podman instead of docker"); added {
+		t.Error("Expected a fact to be remembered only once, ignoring case")
+	}
+	if _, err := cfg.Remember(" "); err == nil {
+		t.Error("Expected an error for an empty fact")
+	}
+	cfg.Remember("Prefer fd over find")
+	cfg.Remember("My NAS is mounted at /mnt/nas")
+	if strings.Join(cfg.Facts, "|") != "I use podman instead of docker|Prefer fd over find|My NAS is mounted at /mnt/nas" {
+		t.Fatalf("Unexpected facts %q", cfg.Facts)
+	}
+
+	if fact, err := cfg.Forget("2"); err != nil || fact != "Prefer fd over find" {
+		t.Errorf("Expected the second fact to be forgotten, got %q and %v", fact, err)
+	}
+	if fact, err := cfg.Forget("i USE podman instead of docker"); err != nil || fact != "I use podman instead of docker" {
+		t.Errorf("Expected a fact to be forgotten by its text, got %q and %v", fact, err)
+	}
+	for _, selector := range []string{"0", "2", "prefer fd over find"} {
+		if _, err := cfg.Forget(selector); err == nil {
+			t.Errorf("Expected an error forgetting %q", selector)
+		}
+	}
+	if len(cfg.Facts) != 1 {
+		t.Errorf("Expected one fact left, got %q", cfg.Facts)
+	}
+
+	// Facts survive saving under their own section
+	t.Setenv("HOME", t.TempDir())
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := config.Load()
+	if err != nil || strings.Join(loaded.Facts, "|") != "My NAS is mounted at /mnt/nas" {
+		t.Errorf("Expected the facts to be loaded again, got %v and %v", loaded, err)
+	}
+}
+
+func TestConfig_ValidateContextNames(t *testing.T) {
+	for _, name := range []string{"Prod", "my env", "a:b", ""} {
+		cfg := &config.Config{
+			APIKey:   "test-key",
+			Mode:     "monarch",
+			Contexts: map[string]config.IntentContext{name: {}},
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for context name %q", name)
+		}
+	}
+}
+
+func TestConfig_Migrate(t *testing.T) {
+	legacy := []byte("provider: openai\napi_key: sk-test\nmodel: gpt-4\nmode: monarch\nui:\n  verbosity: minimal\n")
+
+	migrated, fromVersion, err := config.Migrate(legacy)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fromVersion != 0 {
+		t.Errorf("Expected legacy config to be version 0, got %d", fromVersion)
+	}
+
+	var file config.ConfigFile
+	if err := yaml.Unmarshal(migrated, &file); err != nil {
+		t.Fatalf("Migrated config should parse: %v", err)
+	}
+	if file.Version != config.CurrentConfigVersion {
+		t.Errorf("Expected version %d, got %d", config.CurrentConfigVersion, file.Version)
+	}
+	if file.AI.AIProvider != "openai" || file.AI.APIKey != "sk-test" || file.AI.Mode != "monarch" {
+		t.Errorf("AI settings should move under the ai section, got %+v", file.AI)
+	}
+	if file.UI.Verbosity != "minimal" {
+		t.Errorf("Other sections should be preserved, got %+v", file.UI)
+	}
+}
+
+func TestConfig_MigrateCurrentAndNewer(t *testing.T) {
+	current := []byte(fmt.Sprintf("config_version: %d\nai:\n  provider: gemini\n", config.CurrentConfigVersion))
+	migrated, _, err := config.Migrate(current)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(migrated) != string(current) {
+		t.Error("Current configs should be left untouched")
+	}
+
+	newer := []byte(fmt.Sprintf("config_version: %d\n", config.CurrentConfigVersion+1))
+	if _, _, err := config.Migrate(newer); err == nil {
+		t.Error("Expected error for a config written by a newer version")
+	}
+}
+
+func TestConfig_LoadMigratesWithBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configDir := filepath.Join(home, ".config", "execute-my-will")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte("provider: anthropic\napi_key: test-key\nmode: royal-heir\n")
+	configPath := filepath.Join(configDir, "config.yaml")
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.AIProvider != "anthropic" || cfg.Mode != "royal-heir" {
+		t.Errorf("Unexpected migrated config: %+v", cfg)
+	}
+	if cfg.Migration == nil || cfg.Migration.FromVersion != 0 {
+		t.Fatalf("Expected a migration result, got %+v", cfg.Migration)
+	}
+
+	backup, err := os.ReadFile(cfg.Migration.BackupPath)
+	if err != nil || string(backup) != string(legacy) {
+		t.Errorf("Backup should hold the original config, got %q (%v)", backup, err)
+	}
+
+	// The upgraded file is loaded again without migrating
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Migration != nil {
+		t.Errorf("Upgraded config should not migrate again, got %+v", cfg.Migration)
+	}
+}
+
+func TestPrivacyConfig_SSHHosts(t *testing.T) {
+	var privacy config.PrivacyConfig
+	if !privacy.AllowSSHHosts() {
+		t.Error("SSH host aliases should be shared by default")
+	}
+
+	if err := privacy.SetField("ssh-hosts", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if privacy.AllowSSHHosts() {
+		t.Error("SSH host aliases should be withheld")
+	}
+	if withheld := strings.Join(privacy.Withheld(), ","); withheld != "ssh-hosts" {
+		t.Errorf("Unexpected withheld list: %s", withheld)
+	}
+}
+
+func TestConfig_APIVersions(t *testing.T) {
+	cfg := &config.Config{AIProvider: "gemini", APIKey: "key", Mode: "monarch"}
+	if cfg.APIVersion("gemini") != "v1beta" || cfg.APIVersion("anthropic") != "2023-06-01" || cfg.APIVersion("openai") != "v1" {
+		t.Errorf("Unexpected default versions: %s, %s, %s", cfg.APIVersion("gemini"), cfg.APIVersion("anthropic"), cfg.APIVersion("openai"))
+	}
+	if cfg.APIVersionWarning() != "" {
+		t.Errorf("Default versions should not warn, got %q", cfg.APIVersionWarning())
+	}
+
+	cfg.APIVersions = map[string]string{"gemini": "v1", "anthropic": "2023-01-01"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.APIVersion("gemini") != "v1" {
+		t.Errorf("Expected the pinned gemini version, got %s", cfg.APIVersion("gemini"))
+	}
+	if cfg.APIVersionWarning() != "" {
+		t.Errorf("Only the configured provider's version should be checked, got %q", cfg.APIVersionWarning())
+	}
+
+	cfg.AIProvider = "anthropic"
+	if warning := cfg.APIVersionWarning(); !strings.Contains(warning, "2023-01-01") || !strings.Contains(warning, "2023-06-01") {
+		t.Errorf("Expected a sunset warning naming both versions, got %q", warning)
+	}
+
+	for _, versions := range []map[string]string{{"gemini": "beta"}, {"anthropic": "v1"}, {"openai": "2024-01-01"}} {
+		cfg.APIVersions = versions
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected %v to be rejected", versions)
+		}
+	}
+}
+
+func TestConfig_ValidateExecutionEnv(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Mode: "monarch", Execution: config.ExecutionConfig{Env: map[string]string{"DEBIAN_FRONTEND": "noninteractive"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid environment, got %v", err)
+	}
+
+	cfg.Execution.Env["BAD NAME"] = "x"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for a variable name with a space")
+	}
+}
+
+func TestConfig_ValidateMaxLineSize(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Mode: "monarch", Execution: config.ExecutionConfig{MaxLineSize: 4096}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid line size, got %v", err)
+	}
+
+	cfg.Execution.MaxLineSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for a negative line size")
+	}
+}
+
+func TestAnalysisConfig_ScanSystem(t *testing.T) {
+	var analysis config.AnalysisConfig
+	if !analysis.ScanSystem() {
+		t.Error("The system scan should be on when the setting is omitted")
+	}
+
+	scan := false
+	analysis.SystemScan = &scan
+	if analysis.ScanSystem() {
+		t.Error("The system scan should be off when system_scan is false")
+	}
+}
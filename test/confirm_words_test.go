@@ -0,0 +1,80 @@
+// File: test/confirm_words_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+func TestIsAffirmative(t *testing.T) {
+	defer ui.SetLocale(ui.GetLocale())
+	iso, _ := ui.ParseLocale("C")
+	ui.SetLocale(iso)
+
+	for _, answer := range []string{"y", "Yes", " oui ", "Sí", "si", "sì", "ja", "JA!", "sim", "tak", "да", "Да.", "так", "ναι", "כן", "نعم", "हाँ", "हां", "はい", "是", "네", "evet"} {
+		if !ui.IsAffirmative(answer) {
+			t.Errorf("Expected %q to mean yes", answer)
+		}
+	}
+	// A single letter is only a yes in its own language; "н" starts the Russian "нет"
+	for _, answer := range []string{"", "n", "no", "non", "nein", "нет", "н", "いいえ", "s", "j", "maybe"} {
+		if ui.IsAffirmative(answer) {
+			t.Errorf("Expected %q not to mean yes", answer)
+		}
+	}
+}
+
+func TestIsAffirmative_LocaleLetters(t *testing.T) {
+	defer ui.SetLocale(ui.GetLocale())
+	testCases := map[string]struct{ yes, other string }{
+		"es_ES": {yes: "s", other: "j"},
+		"de_DE": {yes: "J", other: "s"},
+		"fr_FR": {yes: "o", other: "t"},
+		"ru_RU": {yes: "д", other: "н"},
+	}
+	for name, tc := range testCases {
+		locale, err := ui.ParseLocale(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ui.SetLocale(locale)
+		if !ui.IsAffirmative(tc.yes) {
+			t.Errorf("%s: expected %q to mean yes", name, tc.yes)
+		}
+		if ui.IsAffirmative(tc.other) {
+			t.Errorf("%s: expected %q not to mean yes", name, tc.other)
+		}
+	}
+}
+
+func TestStdinConfirmer_AcceptsWordsForYes(t *testing.T) {
+	for input, expected := range map[string]bool{"oui\n": true, "はい\n": true, "нет\n": false} {
+		approved, err := cli.NewStdinConfirmer(strings.NewReader(input)).Confirm(newQuest("x", "monarch"))
+		if err != nil || approved != expected {
+			t.Errorf("Confirm(%q) = %v and %v, expected %v", input, approved, err, expected)
+		}
+	}
+
+	// The select style falls back to typing when the answers do not come from a terminal
+	quest := newQuest("x", "monarch")
+	quest.Config.UI.Confirm = "select"
+	if approved, err := cli.NewStdinConfirmer(strings.NewReader("ja\n")).Confirm(quest); err != nil || !approved {
+		t.Errorf("Expected a typed answer without a terminal, got %v and %v", approved, err)
+	}
+}
+
+func TestWithoutAnswerHint(t *testing.T) {
+	testCases := map[string]string{
+		"Do you wish me to proceed with this quest? (y/N):":     "Do you wish me to proceed with this quest?",
+		"Read-only. Proceed? (Y/n):":                            "Read-only. Proceed?",
+		"Shall we set sail on this voyage (and fast), captain?": "Shall we set sail on this voyage (and fast), captain?",
+	}
+	for message, expected := range testCases {
+		if got := ui.WithoutAnswerHint(message); got != expected {
+			t.Errorf("WithoutAnswerHint(%q) = %q, expected %q", message, got, expected)
+		}
+	}
+}
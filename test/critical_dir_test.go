@@ -0,0 +1,124 @@
+// File: test/critical_dir_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestIsCriticalDirectory(t *testing.T) {
+	testCases := []struct {
+		dir      string
+		goos     string
+		critical bool
+	}{
+		{dir: "/", goos: "linux", critical: true},
+		{dir: "/etc/", goos: "linux", critical: true},
+		{dir: "/etc/nginx/sites-enabled", goos: "linux", critical: true},
+		{dir: "/usr", goos: "linux", critical: true},
+		{dir: "/usr/local/src/project", goos: "linux", critical: false},
+		{dir: "/home/user", goos: "linux", critical: false},
+		{dir: "/System/Library", goos: "darwin", critical: true},
+		{dir: "/Library", goos: "linux", critical: false},
+		{dir: `C:\`, goos: "windows", critical: true},
+		{dir: `c:\WINDOWS\System32`, goos: "windows", critical: true},
+		{dir: `C:\Program Files`, goos: "windows", critical: true},
+		{dir: `C:\Users\arthur\Projects`, goos: "windows", critical: false},
+		{dir: "", goos: "linux", critical: false},
+	}
+
+	for _, tc := range testCases {
+		if got := system.IsCriticalDirectory(tc.dir, tc.goos); got != tc.critical {
+			t.Errorf("%s on %s: expected critical=%v, got %v", tc.dir, tc.goos, tc.critical, got)
+		}
+	}
+}
+
+func TestDetectCriticalDirectoryUse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		dir     string
+		goos    string
+		path    string // the relative path or glob expected; "" for none
+	}{
+		{name: "glob in /", content: "rm -rf *", dir: "/", goos: "linux", path: "*"},
+		{name: "bare name in /etc", content: "rm hosts.bak", dir: "/etc", goos: "linux", path: "hosts.bak"},
+		{name: "relative path in /etc", content: "cat ./passwd", dir: "/etc", goos: "linux", path: "./passwd"},
+		{name: "chmod mode is not a path", content: "chmod 644 /etc/hosts", dir: "/etc", goos: "linux"},
+		{name: "absolute paths", content: "rm -rf /home/user/build/*.o", dir: "/", goos: "linux"},
+		{name: "quoted pattern", content: "find /var/log -name '*.gz' -delete", dir: "/", goos: "linux"},
+		{name: "home directory", content: "rm -rf *", dir: "/home/user/build", goos: "linux"},
+		{name: "cd into a critical directory", content: "cd / && rm -rf *", dir: "/home/user", goos: "linux", path: "*"},
+		{name: "cd out of a critical directory", content: "cd ~/build && rm -rf *", dir: "/", goos: "linux"},
+		{name: "relative cd in a script", content: "#!/bin/bash\ncd ..\nrm -f *.conf", dir: "/etc/nginx", goos: "linux", path: "*.conf"},
+		{name: "exit status", content: "echo $?", dir: "/", goos: "linux"},
+		{name: "windows del", content: `del /q *.*`, dir: `C:\Windows`, goos: "windows", path: "*.*"},
+		{name: "windows rd", content: `rd /s /q Temp`, dir: `C:\Windows`, goos: "windows", path: "Temp"},
+		{name: "windows absolute", content: `rd /s /q C:\Users\arthur\build`, dir: `C:\`, goos: "windows"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			use := system.DetectCriticalDirectoryUse(tc.content, tc.dir, tc.goos)
+			if tc.path == "" {
+				if use != nil {
+					t.Errorf("Expected no warning, got %+v", use)
+				}
+				return
+			}
+			if use == nil || use.Path != tc.path {
+				t.Errorf("Expected %q to be flagged, got %+v", tc.path, use)
+			}
+		})
+	}
+}
+
+func TestPipeline_CriticalDirectoryNeedsTheDirectoryTyped(t *testing.T) {
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: "/", HomeDir: "/home/user"}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf *"}
+	f.confirmer.Approve = false
+
+	q := newQuest("clear out the build folder", "monarch")
+	if err := f.pipeline().Run(q); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q.ConfirmationToken() != "/" || q.CriticalDir == nil || q.CriticalDir.Line != "rm -rf *" {
+		t.Errorf("Expected the quest to ask for '/' to be typed, got token %q and %+v", q.ConfirmationToken(), q.CriticalDir)
+	}
+	if len(f.executor.ExecutedCommands) != 0 {
+		t.Errorf("Expected nothing to run, got %v", f.executor.ExecutedCommands)
+	}
+
+	f.analyzer.SystemInfo.CurrentDir = "/home/user/build"
+	q = newQuest("clear out the build folder", "monarch")
+	f.pipeline().Run(q)
+	if q.CriticalDir != nil || q.ConfirmationToken() != "" {
+		t.Errorf("Expected a plain confirmation outside critical directories, got %+v", q.CriticalDir)
+	}
+}
+
+func TestWorkspaceRun_CriticalDirectoryIsConfirmedPerProject(t *testing.T) {
+	f := newWorkspaceFixture(t)
+	f.aiClient.NextResponses = []*ai.AIResponse{{Type: ai.ResponseTypeCommand, Content: "rm -rf ./build"}}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "cd / && rm -rf *"}
+	f.confirmer.DeclineAfter = 1
+
+	results := f.run(t, false)
+	if f.confirmer.CallCount != 2 {
+		t.Errorf("Expected the workspace and then the web project to be confirmed, got %d confirmations", f.confirmer.CallCount)
+	}
+	if results[1].Quest.ConfirmationToken() != "/" {
+		t.Errorf("Expected '/' to be typed for the web project, got %q", results[1].Quest.ConfirmationToken())
+	}
+	if results[0].Status != cli.ProjectSucceeded || results[1].Status != cli.ProjectDeclined {
+		t.Errorf("Expected api to run and web to be declined, got %s and %s", results[0].Status, results[1].Status)
+	}
+	if len(f.executors) != 1 {
+		t.Errorf("Expected only the api project to run, got %d executors", len(f.executors))
+	}
+}
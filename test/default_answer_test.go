@@ -0,0 +1,80 @@
+// File: test/default_answer_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+func TestPipeline_RiskWeightedDefaultAnswer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		mode       string
+		defaultYes bool
+		response   *ai.AIResponse
+		context    *config.IntentContext
+		expected   bool
+	}{
+		{name: "read-only command", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "du -sh * | sort -h"}, expected: true},
+		{name: "read-only script", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "# Show disk usage\ndf -h\n# Show the largest directories\ndu -sh ~/* | sort -h"}, expected: true},
+		{name: "command that modifies", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "mkdir -p ~/backups"}},
+		{name: "read-only then destructive", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls build && rm -rf build"}},
+		{name: "elevated read", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "sudo cat /etc/shadow"}},
+		{name: "oracle rates it riskier", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls -la", Risk: "destructive"}},
+		{name: "oracle agrees it only reads", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls -la", Risk: "read-only"}, expected: true},
+		{name: "not configured", mode: "monarch", response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls -la"}},
+		{name: "royal-heir mode", mode: "royal-heir", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls -la"}},
+		{name: "typed confirmation", mode: "monarch", defaultYes: true, response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ls -la"}, context: &config.IntentContext{TypedConfirmation: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPipelineFixture()
+			f.aiClient.Response = tc.response
+			deps := f.deps()
+			deps.Confirmer = cli.NewStdinConfirmer(strings.NewReader("\n"))
+
+			quest := newQuest("look around", tc.mode)
+			quest.Config.Execution.ReadOnlyDefaultYes = tc.defaultYes
+			quest.ContextName, quest.Context = "prod", tc.context
+			if err := cli.NewPipeline(deps).Run(quest); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if quest.DefaultApproval != tc.expected || quest.Executed != tc.expected {
+				t.Errorf("Expected Enter to approve: %v, got default %v and executed %v", tc.expected, quest.DefaultApproval, quest.Executed)
+			}
+		})
+	}
+}
+
+func TestStdinConfirmer_DefaultApproval(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{"\n", true},
+		{"  \n", true},
+		{"y\n", true},
+		{"n\n", false},
+		{"no\n", false},
+		{"maybe\n", false},
+	}
+
+	for _, tc := range testCases {
+		quest := newQuest("list files", "monarch")
+		quest.DefaultApproval = true
+
+		approved, err := cli.NewStdinConfirmer(strings.NewReader(tc.input)).Confirm(quest)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if approved != tc.expected {
+			t.Errorf("Input %q: expected %v, got %v", tc.input, tc.expected, approved)
+		}
+	}
+}
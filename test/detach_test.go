@@ -0,0 +1,102 @@
+// File: test/detach_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestIsLongRunning(t *testing.T) {
+	for _, command := range []string{
+		"make -j4",
+		"cd build && cmake --build . --parallel 2",
+		"sudo apt-get -y dist-upgrade",
+		"rsync -avh ~/photos/ nas:/backup/photos/",
+		"tar -czf backup.tar.gz /srv/data",
+		"docker build -t app .",
+		"pg_dump mydb > mydb.sql",
+	} {
+		if !system.IsLongRunning(command) {
+			t.Errorf("Expected %q to be long-running", command)
+		}
+	}
+	for _, command := range []string{"ls -la", "tar -xzf backup.tar.gz", "git status", "echo make it so", "apt-get install -y curl"} {
+		if system.IsLongRunning(command) {
+			t.Errorf("Expected %q not to be long-running", command)
+		}
+	}
+}
+
+func TestDetachedSession(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	tmux := system.NewDetachedSession("tmux", now)
+	if tmux.Name != "emw-0314-092653" || tmux.AttachCommand() != "tmux attach -t emw-0314-092653" {
+		t.Errorf("Unexpected session %+v", tmux)
+	}
+	command := tmux.Command("make -j4", "bash")
+	if !strings.HasPrefix(command, "tmux new-session -d -s emw-0314-092653 'bash -c '\\''make -j4; status=$?;") {
+		t.Errorf("Unexpected tmux command %q", command)
+	}
+
+	screen := system.NewDetachedSession("screen", now)
+	if screen.AttachCommand() != "screen -r emw-0314-092653" {
+		t.Errorf("Unexpected attach command %q", screen.AttachCommand())
+	}
+	if command := screen.Command("make -j4", "bash"); !strings.HasPrefix(command, "screen -dmS emw-0314-092653 bash -c 'make -j4; status=$?;") {
+		t.Errorf("Unexpected screen command %q", command)
+	}
+}
+
+func TestPipeline_OffersDetachedSessions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		multiplexer string
+		choices     []int
+		detached    bool
+		questions   int
+	}{
+		{"detached", "make -j4", "tmux", []int{1}, true, 1},
+		{"kept in the terminal", "make -j4", "tmux", []int{0}, false, 1},
+		{"short command", "ls -la", "tmux", nil, false, 0},
+		{"no multiplexer", "make -j4", "", nil, false, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPipelineFixture()
+			f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: tc.content}
+			f.prompter.Choices = tc.choices
+			deps := f.deps()
+			deps.FindMultiplexer = func() string { return tc.multiplexer }
+
+			quest := newQuest("build the project", "monarch")
+			if err := cli.NewPipeline(deps).Run(quest); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(f.prompter.Questions) != tc.questions {
+				t.Errorf("Expected %d questions, got %v", tc.questions, f.prompter.Questions)
+			}
+			if got := quest.Detached != nil; got != tc.detached {
+				t.Fatalf("Expected detached %v, got %v", tc.detached, got)
+			}
+			if len(f.executor.ExecutedCommands) != 1 {
+				t.Fatalf("Expected one execution, got %v", f.executor.ExecutedCommands)
+			}
+			executed := f.executor.ExecutedCommands[0]
+			if tc.detached && !strings.HasPrefix(executed, "tmux new-session -d -s emw-") {
+				t.Errorf("Expected the command to start a tmux session, got %q", executed)
+			}
+			if !tc.detached && executed != tc.content {
+				t.Errorf("Expected %q to run here, got %q", tc.content, executed)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// File: test/direnv_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestDetectDirenv(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "service")
+	nested := filepath.Join(project, "cmd", "server")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	envrc := `# project environment
+layout python
+use flake
+export DATABASE_URL=postgres://localhost/service
+export STRIPE_SECRET_KEY=sk_test_123
+export GITHUB_TOKEN=ghp_abc
+PRIVATE_ONLY=1
+dotenv_if_exists .env.local
+`
+	files := map[string]string{
+		filepath.Join(project, ".envrc"):     envrc,
+		filepath.Join(project, ".env.local"): "REDIS_URL=redis://localhost\nDB_PASSWORD=hunter2\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		dir    string
+		env    map[string]string
+		loaded bool
+	}{
+		{name: "in the project", dir: project},
+		{name: "in a subdirectory", dir: nested},
+		{name: "loaded by the hook", dir: nested, env: map[string]string{"DIRENV_DIR": "-" + project}, loaded: true},
+		{name: "another project loaded", dir: project, env: map[string]string{"DIRENV_DIR": "-" + root}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			direnv := system.DetectDirenv(tc.dir, func(name string) string { return tc.env[name] })
+			if direnv.Envrc != filepath.Join(project, ".envrc") {
+				t.Fatalf("Expected the project's .envrc, got %q", direnv.Envrc)
+			}
+			if direnv.Loaded != tc.loaded {
+				t.Errorf("Expected loaded=%v, got %v", tc.loaded, direnv.Loaded)
+			}
+			if got := strings.Join(direnv.Uses, ","); got != "layout python,use flake" {
+				t.Errorf("Unexpected layouts and integrations: %s", got)
+			}
+			if got := strings.Join(direnv.Variables, ","); got != "DATABASE_URL,REDIS_URL" {
+				t.Errorf("Expected only the exported, non-secret names, got %s", got)
+			}
+		})
+	}
+
+	if direnv := system.DetectDirenv(root, func(string) string { return "" }); direnv.Detected() || direnv.String() != "not detected" {
+		t.Errorf("Expected no .envrc above the project, got %q", direnv.String())
+	}
+}
+
+func TestDirenvEnvironment_Describe(t *testing.T) {
+	direnv := system.DirenvEnvironment{Envrc: "/srv/app/.envrc", Installed: true, Variables: []string{"DATABASE_URL"}}
+
+	withheld := direnv.Describe(false)
+	if strings.Contains(withheld, "DATABASE_URL") || !strings.Contains(withheld, "not loaded") {
+		t.Errorf("Expected an unloaded .envrc without its variables, got %q", withheld)
+	}
+	if shared := direnv.Describe(true); !strings.Contains(shared, "exports: DATABASE_URL") {
+		t.Errorf("Expected the variable names once allowed, got %q", shared)
+	}
+
+	direnv.Installed = false
+	if got := direnv.Describe(false); !strings.Contains(got, "direnv is not installed") {
+		t.Errorf("Expected a missing direnv to be reported, got %q", got)
+	}
+}
+
+func TestPrivacyConfig_EnvrcVariablesNeedConsent(t *testing.T) {
+	var privacy config.PrivacyConfig
+	if privacy.AllowEnvrcVariables() {
+		t.Error("The names of .envrc variables should not be shared until allowed")
+	}
+
+	if err := privacy.SetField("envrc-variables", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !privacy.AllowEnvrcVariables() {
+		t.Error("Expected the names to be shared once allowed")
+	}
+	if len(privacy.Withheld()) != 0 {
+		t.Errorf("Expected nothing withheld, got %v", privacy.Withheld())
+	}
+}
@@ -0,0 +1,139 @@
+// File: test/distill_test.go
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+const backupScript = "#!/bin/bash\n# Copy the dotfiles\nset -e\nmkdir -p \"$HOME/backup\"\necho \"backing up to ${1:-nas}\""
+
+// distillHistory holds a script run three times, one run twice, and a command run often
+func distillHistory(t *testing.T) *history.Store {
+	t.Helper()
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		store.Record("back up my dotfiles to the NAS", "", backupScript, true, nil, now.Add(time.Duration(i)*time.Minute))
+	}
+	for i := 0; i < 2; i++ {
+		store.Record("rotate the logs", "", "logrotate -f ~/.logrotate.conf\necho done", true, nil, now)
+	}
+	for i := 0; i < 5; i++ {
+		store.Record("show disk usage", "", "df -h", false, nil, now)
+	}
+	return store
+}
+
+func TestHistory_Recurring(t *testing.T) {
+	store := distillHistory(t)
+	recurring := store.Recurring(3)
+	if len(recurring) != 1 || recurring[0].Intent != "back up my dotfiles to the NAS" {
+		t.Fatalf("Expected only the script run three times, got %v", recurring)
+	}
+
+	store.Record("back up my dotfiles to the NAS", "", backupScript, true, os.ErrPermission, time.Now().Add(time.Hour))
+	if recurring := store.Recurring(3); len(recurring) != 0 {
+		t.Errorf("Expected a script whose last run failed to be left out, got %v", recurring)
+	}
+}
+
+func TestSuggestFunctionName(t *testing.T) {
+	testCases := map[string]string{
+		"back up my dotfiles to the NAS":  "back_up_dotfiles_nas",
+		"Rotate the logs!":                "rotate_logs",
+		"7zip the photos":                 "quest_7zip_photos",
+		"test":                            "quest_test",
+		"":                                "quest",
+		"clean up docker images and logs": "clean_up_docker_images",
+	}
+	for intent, expected := range testCases {
+		if got := system.SuggestFunctionName(intent); got != expected {
+			t.Errorf("%q: expected %q, got %q", intent, expected, got)
+		}
+		if err := system.ValidateFunctionName(system.SuggestFunctionName(intent)); err != nil {
+			t.Errorf("%q: expected a valid suggestion, got %v", intent, err)
+		}
+	}
+
+	for _, name := range []string{"2fast", "back-up", "cd", "with space"} {
+		if system.ValidateFunctionName(name) == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestShellFunction_RunsInASubshell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the function library is for sh-compatible shells")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is needed to load the function library")
+	}
+
+	library := filepath.Join(t.TempDir(), system.FunctionsFile)
+	function := system.ShellFunction("backup_dotfiles", "back up my dotfiles", "cd /\necho \"to $1\"\nexit 3", 4, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err := system.AppendFunction(library, function); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(function, "# Distilled on 2025-03-01 from a script run 4 times") {
+		t.Errorf("Expected the function to say where it came from, got:\n%s", function)
+	}
+
+	dir := t.TempDir()
+	output, _ := exec.Command(sh, "-c", ". "+library+"; cd "+dir+"; backup_dotfiles nas; echo \"status $? in $(pwd)\"").CombinedOutput()
+	expected := "to nas\nstatus 3 in " + dir
+	resolved, _ := filepath.EvalSymlinks(dir)
+	if got := strings.TrimSpace(string(output)); got != expected && got != "to nas\nstatus 3 in "+resolved {
+		t.Errorf("Expected the function's cd and exit to stay in its subshell, got %q", got)
+	}
+}
+
+func TestDistiller_AppendsApprovedFunction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), system.FunctionsFile)
+	prompter := &MockPrompter{Choices: []int{0, 0}}
+	distiller := &cli.Distiller{History: distillHistory(t), Prompter: prompter, Path: path, MinRuns: 3, Now: time.Now}
+
+	function, err := distiller.Run("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(function, "back_up_dotfiles_nas() (") || strings.Contains(function, "#!/bin/bash") {
+		t.Errorf("Expected the suggested name without the shebang, got:\n%s", function)
+	}
+
+	library, _ := os.ReadFile(path)
+	if !strings.Contains(string(library), ". "+path) || !strings.Contains(string(library), function) {
+		t.Errorf("Expected a header on how to load the library, then the function, got:\n%s", library)
+	}
+
+	// The same name cannot be defined twice
+	prompter = &MockPrompter{Choices: []int{0, 0}}
+	distiller.Prompter = prompter
+	if _, err := distiller.Run("back_up_dotfiles_nas"); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("Expected a duplicate name to be refused, got %v", err)
+	}
+}
+
+func TestDistiller_LeavesLibraryWhenDeclined(t *testing.T) {
+	path := filepath.Join(t.TempDir(), system.FunctionsFile)
+	distiller := &cli.Distiller{History: distillHistory(t), Prompter: &MockPrompter{Choices: []int{0, 1}}, Path: path, MinRuns: 3, Now: time.Now}
+
+	function, err := distiller.Run("dotfiles")
+	if err != nil || function != "" {
+		t.Fatalf("Expected nothing to be added, got %q and %v", function, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no library to be written, got %v", err)
+	}
+}
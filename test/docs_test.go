@@ -0,0 +1,121 @@
+// File: test/docs_test.go
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func newTestDocsIndex() *system.DocsIndex {
+	var chunks []system.DocChunk
+	chunks = append(chunks, system.ChunkToolDoc("ffmpeg", "man", "NAME\n       ffmpeg - video converter\n\nOPTIONS\n       -crf quality for the x264 video encoder\n\n       -preset slow or fast encoding speed")...)
+	chunks = append(chunks, system.ChunkToolDoc("rsync", "man", "NAME\n       rsync - a fast, versatile, remote file-copying tool\n\nOPTIONS\n       --partial keep partially transferred files\n\n       --delete delete extraneous files from destination directories")...)
+	chunks = append(chunks, system.ChunkToolDoc("jq", "--help", "Usage: jq [OPTIONS] FILTER [FILES...]\n\n  -r, --raw-output output raw strings, not JSON texts")...)
+	return system.NewDocsIndex(chunks, time.Now())
+}
+
+func TestCleanManText(t *testing.T) {
+	raw := "N\bNA\bAM\bME\bE\n                 _\bl_\bs - list\n\n\n\n    -a   all\n"
+	expected := "NAME\n    ls - list\n\n    -a   all"
+	if got := system.CleanManText(raw); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestChunkToolDoc(t *testing.T) {
+	long := strings.Repeat("--flag   does something useful\n", 200)
+	chunks := system.ChunkToolDoc("tool", "--help", "Usage: tool\n\n"+long)
+	if len(chunks) < 3 {
+		t.Fatalf("Expected a long option list to be split, got %d chunks", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if chunk.Tool != "tool" || chunk.Source != "--help" || len(chunk.Text) > 1300 {
+			t.Errorf("Unexpected chunk %s/%s of %d characters", chunk.Tool, chunk.Source, len(chunk.Text))
+		}
+	}
+}
+
+func TestDocsIndex_Search(t *testing.T) {
+	index := newTestDocsIndex()
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"convert a video with a slow preset", "ffmpeg"},
+		{"copy files to the backup server and keep partial transfers", "rsync"},
+		{"use jq to print raw strings", "jq"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			results := index.Search(tc.query, 1)
+			if len(results) != 1 || results[0].Tool != tc.expected {
+				t.Errorf("Expected %s first, got %v", tc.expected, results)
+			}
+		})
+	}
+
+	if got := index.Grounding("make me a sandwich"); got != "" {
+		t.Errorf("Expected no documentation for an unrelated intent, got %q", got)
+	}
+	grounding := index.Grounding("convert a video with a slow preset")
+	if !strings.HasPrefix(grounding, "TOOL DOCUMENTATION") || !strings.Contains(grounding, "--- ffmpeg (man) ---") {
+		t.Errorf("Unexpected grounding %q", grounding)
+	}
+}
+
+func TestDocsIndex_SaveAndReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs-index.json")
+	if index, err := system.LoadDocsIndex(path); index != nil || err != nil {
+		t.Fatalf("Expected no index and no error before building, got %v, %v", index, err)
+	}
+
+	if err := newTestDocsIndex().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := system.LoadDocsIndex(path)
+	if err != nil || strings.Join(loaded.Tools(), ",") != "ffmpeg,jq,rsync" {
+		t.Fatalf("Expected the saved tools, got %v (%v)", loaded, err)
+	}
+	if results := loaded.Search("raw strings", 1); len(results) != 1 || results[0].Tool != "jq" {
+		t.Errorf("Expected a loaded index to be searchable, got %v", results)
+	}
+
+	replaced := loaded.Replace([]string{"jq"}, system.ChunkToolDoc("jq", "man", "jq - commandline JSON processor"), time.Now())
+	if len(replaced.Search("raw strings", 5)) != 0 {
+		t.Error("Expected the old jq documentation to be replaced")
+	}
+	if strings.Join(replaced.Tools(), ",") != "ffmpeg,jq,rsync" {
+		t.Errorf("Expected the other tools to be kept, got %v", replaced.Tools())
+	}
+}
+
+func TestPipeline_SendsToolDocumentation(t *testing.T) {
+	f := newPipelineFixture()
+	deps := f.deps()
+	deps.LoadDocsIndex = func() (*system.DocsIndex, error) { return newTestDocsIndex(), nil }
+
+	q := newQuest("convert the video with a slow preset", "monarch")
+	if err := cli.NewPipeline(deps).Run(q); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(f.aiClient.LastIntent, "--- ffmpeg (man) ---") {
+		t.Errorf("Expected the ffmpeg documentation to be sent, got %q", f.aiClient.LastIntent)
+	}
+
+	// Without an index the intent goes alone
+	f = newPipelineFixture()
+	deps = f.deps()
+	deps.LoadDocsIndex = func() (*system.DocsIndex, error) { return nil, nil }
+	if err := cli.NewPipeline(deps).Run(newQuest("convert the video", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(f.aiClient.LastIntent, "TOOL DOCUMENTATION") {
+		t.Errorf("Expected no documentation without an index, got %q", f.aiClient.LastIntent)
+	}
+}
@@ -0,0 +1,151 @@
+// File: test/duplicates_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// writeDuplicateTree creates two photos with the same content, one with the same size but other
+// content, a copy of a photo in a hidden directory, and a text file with a photo's content
+func writeDuplicateTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"a.jpg":            "same photo",
+		"trip/b.jpg":       "same photo",
+		"c.jpg":            "diff photo",
+		".cache/d.jpg":     "same photo",
+		"notes/photo.txt":  "same photo",
+		"empty-one.jpg":    "",
+		"other/empty2.jpg": "",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestIsDuplicateIntent(t *testing.T) {
+	testCases := []struct {
+		intent          string
+		duplicate       bool
+		action          bool
+		deletion        bool
+		extensionsEmpty bool
+	}{
+		{"find duplicate photos in ~/Pictures", true, false, false, false},
+		{"remove the dupes in Downloads", true, true, true, true},
+		{"move duplicated videos to ./dupes", true, true, false, false},
+		{"list identical files here", true, false, false, true},
+		{"delete old logs", false, true, false, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			if got := system.IsDuplicateIntent(tc.intent); got != tc.duplicate {
+				t.Errorf("Expected duplicate intent %v, got %v", tc.duplicate, got)
+			}
+			if got := system.IsDuplicateActionIntent(tc.intent); got != tc.action {
+				t.Errorf("Expected action %v, got %v", tc.action, got)
+			}
+			if got := system.IsDuplicateDeletionIntent(tc.intent); got != tc.deletion {
+				t.Errorf("Expected deletion %v, got %v", tc.deletion, got)
+			}
+			if got := len(system.DuplicateExtensions(tc.intent)) == 0; got != tc.extensionsEmpty {
+				t.Errorf("Expected no extension filter: %v, got %v", tc.extensionsEmpty, system.DuplicateExtensions(tc.intent))
+			}
+		})
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	root := writeDuplicateTree(t)
+
+	var calls, lastDone, lastTotal int
+	report, err := system.FindDuplicates(root, system.DuplicateExtensions("duplicate photos"), func(done, total int) {
+		calls, lastDone, lastTotal = calls+1, done, total
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The hidden copy, the text file, and the empty files are never compared
+	if report.Scanned != 3 || report.Hashed != 3 {
+		t.Errorf("Expected 3 files scanned and hashed, got %d and %d", report.Scanned, report.Hashed)
+	}
+	if calls != 3 || lastDone != 3 || lastTotal != 3 {
+		t.Errorf("Expected progress for each hashed file, got %d calls ending at %d/%d", calls, lastDone, lastTotal)
+	}
+	if len(report.Groups) != 1 {
+		t.Fatalf("Expected one group, got %v", report.Groups)
+	}
+	expected := []string{filepath.Join(root, "a.jpg"), filepath.Join(root, "trip", "b.jpg")}
+	if strings.Join(report.Groups[0].Paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, report.Groups[0].Paths)
+	}
+	if report.Wasted() != int64(len("same photo")) || len(report.Extras()) != 1 || report.Extras()[0] != expected[1] {
+		t.Errorf("Expected the second copy to be the extra, got %v wasting %d", report.Extras(), report.Wasted())
+	}
+
+	// Without a filter the text file joins the group
+	all, err := system.FindDuplicates(root, nil, nil)
+	if err != nil || len(all.Groups) != 1 || len(all.Groups[0].Paths) != 3 {
+		t.Errorf("Expected three identical files without a filter, got %v (%v)", all, err)
+	}
+
+	if _, err := system.FindDuplicates(filepath.Join(root, "missing"), nil, nil); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestPipeline_FindsDuplicatesNatively(t *testing.T) {
+	testCases := []struct {
+		name       string
+		intent     string
+		selections [][]int
+		oracle     bool // the oracle is asked, with the groups in the intent
+		deletes    bool // the chosen copy is deleted
+	}{
+		{"finding answers the quest", "find duplicate photos", nil, false, false},
+		{"deleting ticks copies", "delete duplicate photos", [][]int{{0}}, false, true},
+		{"nothing ticked", "delete duplicate photos", [][]int{{}}, false, false},
+		{"other actions ask the oracle", "move the duplicate photos to a dupes folder", nil, true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := writeDuplicateTree(t)
+			f := newPipelineFixture()
+			f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: root, HomeDir: t.TempDir()}
+			f.prompter.Selections = tc.selections
+
+			if err := f.pipeline().Run(newQuest(tc.intent, "monarch")); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if (f.aiClient.GenerateCallCount == 1) != tc.oracle || f.aiClient.ListingCallCount != 0 {
+				t.Errorf("Expected the oracle asked: %v, got %d generate and %d listing calls", tc.oracle, f.aiClient.GenerateCallCount, f.aiClient.ListingCallCount)
+			}
+			if tc.oracle && !strings.Contains(f.aiClient.LastIntent, "DUPLICATE FILES") {
+				t.Errorf("Expected the groups to be sent with the intent, got %q", f.aiClient.LastIntent)
+			}
+			if tc.deletes {
+				if len(f.executor.ExecutedCommands) != 1 || !strings.Contains(f.executor.ExecutedCommands[0], filepath.Join(root, "trip", "b.jpg")) || strings.Contains(f.executor.ExecutedCommands[0], filepath.Join(root, "a.jpg")) {
+					t.Errorf("Expected only the extra copy to be deleted, got %v", f.executor.ExecutedCommands)
+				}
+			} else if !tc.oracle && len(f.executor.ExecutedCommands) != 0 {
+				t.Errorf("Expected nothing to run, got %v", f.executor.ExecutedCommands)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// File: test/elevation_test.go
+package test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestRequiresAdministrator(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected bool
+	}{
+		{"sc stop spooler", true},
+		{"net start wuauserv", true},
+		{"Restart-Service -Name Spooler", true},
+		{`reg add "HKLM\Software\Contoso" /v Enabled /t REG_DWORD /d 1`, true},
+		{"Set-ItemProperty -Path HKLM:\\Software\\Contoso -Name Enabled -Value 1", true},
+		{"choco install git -y", true},
+		{"msiexec /i tool.msi /qn", true},
+		{"Set-ExecutionPolicy RemoteSigned", true},
+		{"Set-ExecutionPolicy RemoteSigned -Scope CurrentUser", false},
+		{`reg add "HKCU\Software\Contoso" /v Enabled /d 1`, false},
+		{"winget install Git.Git", false},
+		{"Get-Service -Name Spooler", false},
+		{"dir C:\\Users", false},
+	}
+
+	for _, tc := range testCases {
+		if result := system.RequiresAdministrator(tc.command); result != tc.expected {
+			t.Errorf("RequiresAdministrator(%q) = %v, want %v", tc.command, result, tc.expected)
+		}
+	}
+}
+
+func TestEncodePowerShellCommand(t *testing.T) {
+	// powershell -EncodedCommand expects base64 of UTF-16LE
+	if encoded := system.EncodePowerShellCommand("dir"); encoded != "ZABpAHIA" {
+		t.Errorf("Expected ZABpAHIA, got %s", encoded)
+	}
+
+	launcher := system.ElevationLauncherScript("sc stop spooler", "cmd")
+	if !strings.Contains(launcher, "-Verb RunAs") || !strings.Contains(launcher, "exit $p.ExitCode") {
+		t.Errorf("Launcher should request elevation and pass on the exit code: %s", launcher)
+	}
+
+	inner := system.ElevatedPowerShellScript("sc stop spooler", "cmd")
+	if !strings.Contains(inner, "cmd.exe /C") || !strings.Contains(inner, "sc stop spooler") {
+		t.Errorf("cmd commands should run through cmd.exe: %s", inner)
+	}
+	if _, err := base64.StdEncoding.DecodeString(system.EncodePowerShellCommand(inner)); err != nil {
+		t.Errorf("Encoded script should be valid base64: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// File: test/encoding_test.go
+package test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestCodePageEncoding(t *testing.T) {
+	if system.CodePageEncoding(system.CodePageUTF8) != nil {
+		t.Error("Expected UTF-8 output to need no transcoding")
+	}
+	for _, codePage := range []uint32{437, 850, 932, 1252} {
+		if system.CodePageEncoding(codePage) == nil {
+			t.Errorf("Expected an encoding for code page %d", codePage)
+		}
+	}
+}
+
+func TestConsoleDecoder(t *testing.T) {
+	// "Répertoire de C:\" in code page 850, a UTF-8 line from a program that ignores the code
+	// page, and a last line without a newline
+	output := "R\x82pertoire de C:\\\r\n" + "✅ déjà fait\r\n" + "caf\xe9"
+
+	decoded, err := io.ReadAll(system.NewConsoleDecoder(iotest.OneByteReader(strings.NewReader(output)), system.CodePageEncoding(850)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Répertoire de C:\\\r\n✅ déjà fait\r\ncafÚ"
+	if string(decoded) != expected {
+		t.Errorf("Expected %q, got %q", expected, decoded)
+	}
+
+	passthrough := strings.NewReader(output)
+	if system.NewConsoleDecoder(passthrough, nil) != io.Reader(passthrough) {
+		t.Error("Expected no decoder without an encoding")
+	}
+}
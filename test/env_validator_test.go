@@ -362,6 +362,91 @@ func TestCommandChaining(t *testing.T) {
 	}
 }
 
+func TestEnvironmentValidator_ShellAware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		shell          string
+		command        string
+		shouldError    bool
+		expectedReason string
+	}{
+		// fish
+		{name: "fish set -gx", shell: "fish", command: "set -gx EDITOR vim", shouldError: true, expectedReason: "export"},
+		{name: "fish set -x after install", shell: "fish", command: "brew install go; set -x GOPATH ~/go", shouldError: true, expectedReason: "export"},
+		{name: "fish funcsave", shell: "fish", command: "funcsave ll", shouldError: true, expectedReason: "shell_function"},
+		{name: "fish abbr", shell: "fish", command: "abbr -a gs git status", shouldError: true, expectedReason: "alias"},
+		{name: "fish universal variable persists", shell: "fish", command: "set -U fish_greeting ''", shouldError: false},
+		{name: "fish set query", shell: "fish", command: "set -q EDITOR", shouldError: false},
+		{name: "fish cd", shell: "/usr/bin/fish", command: "cd ~/projects", shouldError: true, expectedReason: "cd"},
+
+		// PowerShell
+		{name: "powershell env assignment", shell: "powershell", command: "$env:PATH += ';C:\\tools'", shouldError: true, expectedReason: "export"},
+		{name: "pwsh set-location", shell: "pwsh", command: "Set-Location C:\\Users", shouldError: true, expectedReason: "cd"},
+		{name: "powershell dot source", shell: "powershell", command: ". .\\setup.ps1", shouldError: true, expectedReason: "source"},
+		{name: "powershell process env var", shell: "powershell", command: "[Environment]::SetEnvironmentVariable('FOO', 'bar')", shouldError: true, expectedReason: "export"},
+		{name: "powershell user env var persists", shell: "powershell", command: "[Environment]::SetEnvironmentVariable('FOO', 'bar', 'User')", shouldError: false},
+		{name: "powershell reading PATH", shell: "powershell", command: "Write-Output $env:PATH", shouldError: false},
+		{name: "powershell nvm-windows is global", shell: "powershell", command: "nvm use 20", shouldError: false},
+
+		// nushell
+		{name: "nushell let-env", shell: "nu", command: "let-env FOO = 'bar'", shouldError: true, expectedReason: "export"},
+		{name: "nushell env assignment", shell: "nu", command: "$env.FOO = 'bar'", shouldError: true, expectedReason: "export"},
+		{name: "nushell def", shell: "nu", command: "def greet [] { 'hi' }", shouldError: true, expectedReason: "shell_function"},
+		{name: "nushell listing", shell: "nu", command: "ls | where size > 1mb", shouldError: false},
+
+		// cmd
+		{name: "cmd set", shell: "cmd", command: "set FOO=bar", shouldError: true, expectedReason: "export"},
+		{name: "cmd setx persists", shell: "cmd", command: "setx FOO bar", shouldError: false},
+		{name: "cmd drive switch", shell: "cmd", command: "D:", shouldError: true, expectedReason: "cd"},
+
+		// bash-isms that are harmless elsewhere, and commands that only look like cd
+		{name: "bash cdk is not cd", shell: "bash", command: "cdk deploy", shouldError: false},
+		{name: "fish cdk is not cd", shell: "fish", command: "cdk deploy", shouldError: false},
+		{name: "export is not a cmd builtin", shell: "cmd", command: "echo export FOO=bar", shouldError: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := system.NewEnvironmentValidator(&system.Info{Shell: tc.shell})
+			err := validator.ValidateEnvironmentCommand(tc.command)
+
+			if !tc.shouldError {
+				if err != nil {
+					t.Errorf("Expected no error for %q in %s, got: %v", tc.command, tc.shell, err)
+				}
+				return
+			}
+
+			envErr, ok := err.(*system.EnvironmentCommandError)
+			if !ok {
+				t.Fatalf("Expected EnvironmentCommandError for %q in %s, got: %v", tc.command, tc.shell, err)
+			}
+			if envErr.Reason != tc.expectedReason {
+				t.Errorf("Expected reason %q, got %q", tc.expectedReason, envErr.Reason)
+			}
+		})
+	}
+}
+
+func TestShellFamily(t *testing.T) {
+	testCases := map[string]string{
+		"bash":                system.ShellFamilyPOSIX,
+		"zsh":                 system.ShellFamilyPOSIX,
+		"/usr/local/bin/fish": system.ShellFamilyFish,
+		"pwsh":                system.ShellFamilyPowerShell,
+		"powershell.exe":      system.ShellFamilyPowerShell,
+		"nu":                  system.ShellFamilyNushell,
+		"cmd":                 system.ShellFamilyCmd,
+		"":                    system.ShellFamilyPOSIX,
+	}
+
+	for shell, expected := range testCases {
+		if family := system.ShellFamily(shell); family != expected {
+			t.Errorf("ShellFamily(%q) = %q, want %q", shell, family, expected)
+		}
+	}
+}
+
 // Benchmark test for performance
 func BenchmarkValidateEnvironmentCommand(b *testing.B) {
 	validator := system.NewEnvironmentValidator(&system.Info{OS: "linux", Shell: "bash"})
@@ -0,0 +1,156 @@
+// File: test/errors_test.go
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestProviderErrors_HaveKinds(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		body        string
+		unavailable bool
+		parse       bool
+	}{
+		{name: "overloaded", status: http.StatusServiceUnavailable, body: `{"error":{"message":"overloaded"}}`, unavailable: true},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{"error":{"message":"slow down"}}`, unavailable: true},
+		{name: "gateway page", status: http.StatusBadGateway, body: `<html>Bad Gateway</html>`, unavailable: true},
+		{name: "wrong key", status: http.StatusUnauthorized, body: `{"error":{"message":"invalid api key"}}`},
+		{name: "malformed answer", status: http.StatusOK, body: `not json`, parse: true},
+		{name: "empty answer", status: http.StatusOK, body: `{"choices":[]}`, parse: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			provider, _ := ai.NewOpenAIProvider(compatibleConfig(server.URL+"/v1", ""))
+			_, err := provider.GenerateResponse("list files")
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if errors.Is(err, ai.ErrProviderUnavailable) != tc.unavailable {
+				t.Errorf("Expected unavailable to be %v for %v", tc.unavailable, err)
+			}
+			if errors.Is(err, ai.ErrParseFailure) != tc.parse {
+				t.Errorf("Expected a parse failure to be %v for %v", tc.parse, err)
+			}
+			var providerErr *ai.ProviderError
+			if !tc.parse && (!errors.As(err, &providerErr) || providerErr.StatusCode != tc.status) {
+				t.Errorf("Expected a provider error with status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+func TestProviderErrors_UnreachableServer(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	provider, _ := ai.NewOpenAIProvider(compatibleConfig(url+"/v1", ""))
+	if _, err := provider.GenerateResponse("list files"); !errors.Is(err, ai.ErrProviderUnavailable) {
+		t.Errorf("Expected a closed server to be unavailable, got %v", err)
+	}
+}
+
+func TestParsePlan_FailureKind(t *testing.T) {
+	if _, err := ai.ParsePlan("Sure, my lord, here is what I would do."); !errors.Is(err, ai.ErrParseFailure) {
+		t.Errorf("Expected an answer without a plan to be a parse failure, got %v", err)
+	}
+	if _, err := ai.ParsePlan("FAILURE: I cannot plan that"); err == nil || errors.Is(err, ai.ErrParseFailure) {
+		t.Errorf("Expected a refusal not to be a parse failure, got %v", err)
+	}
+}
+
+func TestConfigErrors_HaveKinds(t *testing.T) {
+	err := (&config.Config{AIProvider: "openai", Mode: "monarch"}).Validate()
+	if !errors.Is(err, config.ErrInvalidConfig) || !strings.Contains(err.Error(), "API key is required") {
+		t.Errorf("Expected an invalid configuration with its message, got %v", err)
+	}
+
+	if _, _, err := config.Migrate([]byte("ai: [unclosed")); !errors.Is(err, config.ErrParseFailure) {
+		t.Errorf("Expected broken YAML to be a parse failure, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "workspace.yaml")
+	os.WriteFile(path, []byte("projects: {"), 0644)
+	if _, err := config.LoadWorkspace(path); !errors.Is(err, config.ErrParseFailure) || errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("Expected a broken workspace file to be a parse failure, got %v", err)
+	}
+}
+
+func TestExecutionErrors_HaveKinds(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      *system.ExecutionError
+		expected error
+	}{
+		{name: "exit 126", err: &system.ExecutionError{Err: errors.New("exit status 126"), ExitCode: 126}, expected: system.ErrPermissionDenied},
+		{name: "denied output", err: &system.ExecutionError{Err: errors.New("exit status 1"), ExitCode: 1, Output: "rm: cannot remove '/etc/hosts': Permission denied"}, expected: system.ErrPermissionDenied},
+		{name: "exit 127", err: &system.ExecutionError{Err: errors.New("exit status 127"), ExitCode: 127}, expected: system.ErrCommandNotFound},
+		{name: "windows unknown program", err: &system.ExecutionError{Err: errors.New("exit status 1"), ExitCode: 1, Output: "'rg' is not recognized as an internal or external command"}, expected: system.ErrCommandNotFound},
+		{name: "missing file", err: &system.ExecutionError{Err: errors.New("exit status 1"), ExitCode: 1, Output: "cat: notes.txt: No such file or directory"}, expected: system.ErrPathNotFound},
+	}
+	kinds := []error{system.ErrPermissionDenied, system.ErrCommandNotFound, system.ErrPathNotFound}
+
+	for _, tc := range testCases {
+		wrapped := fmt.Errorf("quest failed: %w", tc.err)
+		for _, kind := range kinds {
+			if errors.Is(wrapped, kind) != (kind == tc.expected) {
+				t.Errorf("%s: expected errors.Is(%v) to be %v", tc.name, kind, kind == tc.expected)
+			}
+		}
+	}
+
+	plain := &system.ExecutionError{Err: errors.New("exit status 2"), ExitCode: 2, Output: "make: *** [all] Error 2"}
+	for _, kind := range kinds {
+		if errors.Is(plain, kind) {
+			t.Errorf("Expected an ordinary failure not to be %v", kind)
+		}
+	}
+}
+
+func TestSystemErrors_SafetyKinds(t *testing.T) {
+	validator := system.NewEnvironmentValidator(&system.Info{OS: "linux", Shell: "/bin/bash"})
+	err := validator.ValidateEnvironmentCommand("cd /tmp")
+	var envErr *system.EnvironmentCommandError
+	if !errors.Is(err, system.ErrUnsafeCommand) || !errors.As(err, &envErr) {
+		t.Errorf("Expected an environment command to be unsafe, got %v", err)
+	}
+
+	var missing error = &system.MissingPathError{Path: "/no/such/realm"}
+	if !errors.Is(missing, system.ErrPathNotFound) || errors.Is(missing, system.ErrUnsafeCommand) {
+		t.Errorf("Expected a missing path to be ErrPathNotFound only, got %v", missing)
+	}
+}
+
+func TestPipeline_UnavailableOracleIsExplained(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.ShouldError = true
+	f.aiClient.Err = fmt.Errorf("failed to get response after 3 attempts: %w", &ai.ProviderError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("overloaded")})
+
+	err := f.pipeline().Run(newQuest("list files", "monarch"))
+	if !errors.Is(err, ai.ErrProviderUnavailable) || !strings.Contains(err.Error(), "cannot be reached") {
+		t.Errorf("Expected the pipeline to report an unreachable oracle, got %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 {
+		t.Errorf("Expected nothing to run, got %v", f.executor.ExecutedCommands)
+	}
+}
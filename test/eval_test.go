@@ -0,0 +1,84 @@
+// File: test/eval_test.go
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestPipeline_EvalPrintsTheApprovedCommand(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "cd ~/code/api && export NODE_ENV=test\n"}
+	f.envValidator.InvalidCommands = map[string]string{"cd ~/code/api && export NODE_ENV=test": "directory change"}
+
+	var out bytes.Buffer
+	quest := newQuest("go to the api project in test mode", "monarch")
+	quest.Eval = &out
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if out.String() != "cd ~/code/api && export NODE_ENV=test\n" {
+		t.Errorf("Expected only the command on stdout, got %q", out.String())
+	}
+	if f.confirmer.CallCount != 1 || !quest.Approved {
+		t.Error("Expected the command to be confirmed before the shell evaluates it")
+	}
+	if len(f.executor.ExecutedCommands) != 0 || quest.Executed {
+		t.Error("A command handed to the shell must never be run here")
+	}
+}
+
+func TestPipeline_EvalPrintsNothingUnapproved(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "export PATH=$HOME/bin:$PATH"}
+	f.confirmer.Approve = false
+
+	var out bytes.Buffer
+	quest := newQuest("add my bin directory to the path", "monarch")
+	quest.Eval = &out
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing for the shell to evaluate, got %q", out.String())
+	}
+}
+
+func TestPipeline_EvalRefusesScripts(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "# step\ncd /tmp\necho hi"}
+
+	var out bytes.Buffer
+	quest := newQuest("set up a scratch area", "monarch")
+	quest.Eval = &out
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.Len() != 0 || f.confirmer.CallCount != 0 || len(f.executor.ExecutedCommands) != 0 {
+		t.Errorf("Expected a script to be refused before confirmation, got %q", out.String())
+	}
+}
+
+func TestEvalLine(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected string
+	}{
+		{shell: "/bin/bash", expected: `eval "$(execute-my-will --eval 'go to my project')"`},
+		{shell: "/usr/bin/fish", expected: "eval (execute-my-will --eval 'go to my project' | string collect)"},
+		{shell: "pwsh.exe", expected: "Invoke-Expression (execute-my-will --eval 'go to my project' | Out-String)"},
+		{shell: "cmd.exe", expected: ""},
+	}
+	for _, tc := range testCases {
+		if got := system.EvalLine(tc.shell, "go to my project"); got != tc.expected {
+			t.Errorf("EvalLine(%q) = %q, expected %q", tc.shell, got, tc.expected)
+		}
+	}
+	if got := system.EvalLine("zsh", "cd to bob's home"); got != `eval "$(execute-my-will --eval 'cd to bob'\''s home')"` {
+		t.Errorf("Expected the intent to be quoted, got %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+// File: test/executor_harness_test.go
+package test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// fakeShell builds the fake shell in testdata/fakeshell and returns its path
+func fakeShell(t *testing.T) string {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is needed to build the fake shell")
+	}
+	path := filepath.Join(t.TempDir(), "fakeshell")
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	if output, err := exec.Command(goTool, "build", "-o", path, "./testdata/fakeshell").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build the fake shell: %v\n%s", err, output)
+	}
+	return path
+}
+
+// fakeShellRun is a real executor whose shell is the fake one, with its invocations recorded
+type fakeShellRun struct {
+	executor system.CommandExecutor
+	dir      string // the executor's working directory
+	argsLog  string // the fake shell's record of its arguments
+}
+
+func newFakeShellRun(t *testing.T) *fakeShellRun {
+	t.Helper()
+	run := &fakeShellRun{dir: t.TempDir(), argsLog: filepath.Join(t.TempDir(), "args.log")}
+	t.Setenv("FAKESHELL_LOG", run.argsLog)
+	executor := system.NewProjectExecutor(run.dir, "fake", true)
+	executor = system.WithOutputLog(executor, t.TempDir())
+	run.executor = system.WithShellBinary(executor, fakeShell(t))
+	return run
+}
+
+// shell is the shell name the executor is given, which decides the arguments the fake shell gets
+func (r *fakeShellRun) shell() string {
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	return "bash"
+}
+
+func (r *fakeShellRun) output(t *testing.T) string {
+	t.Helper()
+	log := r.executor.(system.OutputLogger).LastOutput()
+	if log == nil {
+		t.Fatal("Expected the output to be saved")
+	}
+	data, err := os.ReadFile(log.Path)
+	if err != nil {
+		t.Fatalf("Failed to read the output log: %v", err)
+	}
+	return string(data)
+}
+
+func (r *fakeShellRun) invocations(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(r.argsLog)
+	if err != nil {
+		t.Fatalf("Failed to read the fake shell's arguments: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n\n")
+}
+
+func TestFakeShell_ExecuteStreamsStdoutAndStderr(t *testing.T) {
+	run := newFakeShellRun(t)
+	if err := run.executor.Execute("echo to stdout; echo to stderr >&2", run.shell()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := run.output(t)
+	if !strings.Contains(output, "to stdout\n") || !strings.Contains(output, "to stderr\n") {
+		t.Errorf("Expected both streams in the output, got %q", output)
+	}
+	flag := "-c"
+	if runtime.GOOS == "windows" {
+		flag = "/C"
+	}
+	if invocations := run.invocations(t); len(invocations) != 1 || !strings.HasPrefix(invocations[0], flag+"\n") {
+		t.Errorf("Expected the shell to be given %s and the command, got %q", flag, invocations)
+	}
+}
+
+func TestFakeShell_ExecuteReportsExitCodes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		exitCode int
+		output   string
+	}{
+		{name: "explicit exit", command: "echo about to fail && exit 3", exitCode: 3, output: "about to fail"},
+		{name: "unknown command", command: "frobnicate --now", exitCode: 127, output: "command not found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run := newFakeShellRun(t)
+			err := run.executor.Execute(tc.command, run.shell())
+
+			var execErr *system.ExecutionError
+			if !errors.As(err, &execErr) {
+				t.Fatalf("Expected an execution error, got %v", err)
+			}
+			if execErr.ExitCode != tc.exitCode || !strings.Contains(execErr.Output, tc.output) {
+				t.Errorf("Expected exit code %d with %q in the output, got %d and %q", tc.exitCode, tc.output, execErr.ExitCode, execErr.Output)
+			}
+		})
+	}
+}
+
+func TestFakeShell_ExecuteUsesDirectoryAndEnvironment(t *testing.T) {
+	run := newFakeShellRun(t)
+	executor := system.WithEnvironment(run.executor, map[string]string{"EMW_FAKE_REALM": "avalon"})
+	if err := executor.Execute("pwd; printenv EMW_FAKE_REALM", run.shell()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := run.output(t)
+	dir, _ := filepath.EvalSymlinks(run.dir)
+	if !strings.Contains(output, run.dir) && !strings.Contains(output, dir) {
+		t.Errorf("Expected the command to run in %s, got %q", run.dir, output)
+	}
+	if !strings.Contains(output, "avalon") {
+		t.Errorf("Expected the configured variable in the environment, got %q", output)
+	}
+}
+
+func TestFakeShell_ScriptStopsAtFailureAndIsRemoved(t *testing.T) {
+	scripts := map[string]string{
+		"bash":       "# greet the realm\necho one\nexit 4\necho two",
+		"cmd":        "REM greet the realm\necho one\nexit 4\necho two",
+		"powershell": "# greet the realm\necho one\nexit 4\necho two",
+	}
+	shells := []string{"bash"}
+	if runtime.GOOS == "windows" {
+		shells = []string{"cmd", "powershell"}
+	}
+
+	for _, shell := range shells {
+		t.Run(shell, func(t *testing.T) {
+			run := newFakeShellRun(t)
+			// Scripts are written under the configuration directory; set it once the shell is built,
+			// since HOME also moves the build cache
+			configDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", configDir)
+			t.Setenv("HOME", configDir)
+			t.Setenv("AppData", configDir)
+
+			err := run.executor.ExecuteScript(scripts[shell], shell, true)
+
+			var execErr *system.ExecutionError
+			if !errors.As(err, &execErr) || execErr.ExitCode != 4 {
+				t.Fatalf("Expected the script to fail with exit code 4, got %v", err)
+			}
+			output := run.output(t)
+			if !strings.Contains(output, "greet the realm") || !strings.Contains(output, "one\n") {
+				t.Errorf("Expected the comment and the first step in the output, got %q", output)
+			}
+			if strings.Contains(output, "two\n") {
+				t.Errorf("Expected the script to stop at the failing step, got %q", output)
+			}
+
+			invocation := strings.Split(run.invocations(t)[0], "\n")
+			scriptPath := invocation[len(invocation)-1]
+			if !strings.HasPrefix(scriptPath, configDir) {
+				t.Errorf("Expected the script to be written under %s, got %s", configDir, scriptPath)
+			}
+			if _, err := os.Stat(scriptPath); !os.IsNotExist(err) {
+				t.Errorf("Expected the script file to be removed after the run, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFakeShell_ForwardsInterrupts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a process cannot send itself an interrupt on Windows")
+	}
+
+	run := newFakeShellRun(t)
+	ready := filepath.Join(t.TempDir(), "ready")
+	t.Setenv("FAKESHELL_READY", ready)
+
+	result := make(chan error, 1)
+	go func() { result <- run.executor.Execute("wait-for-signal", run.shell()) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("The fake shell never became ready")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Fatalf("Failed to interrupt the test: %v", err)
+	}
+
+	var execErr *system.ExecutionError
+	if err := <-result; !errors.As(err, &execErr) || execErr.ExitCode != 130 {
+		t.Fatalf("Expected the command to stop with exit code 130, got %v", err)
+	}
+	if output := run.output(t); !strings.Contains(output, "received interrupt") {
+		t.Errorf("Expected the interrupt to reach the command, got %q", output)
+	}
+}
@@ -0,0 +1,66 @@
+// File: test/explain_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+)
+
+func TestCommandExplanation_ExplainsWithoutRunning(t *testing.T) {
+	client := &MockAIClient{ExplanationText: "Extracts the gzipped archive foo.tgz, listing each file."}
+	explanation := &cli.CommandExplanation{Client: client, Analyzer: &MockSystemAnalyzer{}}
+
+	text, err := explanation.Run("  tar -xzvf foo.tgz ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != client.ExplanationText {
+		t.Errorf("Expected the oracle's explanation, got %q", text)
+	}
+	if client.LastExplained != "tar -xzvf foo.tgz" {
+		t.Errorf("Expected the command to be explained as given, got %q", client.LastExplained)
+	}
+	if client.GenerateCallCount != 0 {
+		t.Errorf("Expected no command to be generated, got %d", client.GenerateCallCount)
+	}
+}
+
+func TestCommandExplanation_RedactsCredentials(t *testing.T) {
+	client := &MockAIClient{}
+	explanation := &cli.CommandExplanation{Client: client, Analyzer: &MockSystemAnalyzer{}, APIKey: "sk-knight-secret"}
+
+	if _, err := explanation.Run(`curl -H "Authorization: Bearer sk-knight-secret" https://example.com`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(client.LastExplained, "sk-knight-secret") {
+		t.Errorf("Expected the key to be redacted before reaching the oracle, got %q", client.LastExplained)
+	}
+}
+
+func TestCommandExplanation_Failures(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		analyzer *MockSystemAnalyzer
+		client   *MockAIClient
+		asked    bool
+	}{
+		{name: "no command", command: "   ", analyzer: &MockSystemAnalyzer{}, client: &MockAIClient{}},
+		{name: "analysis fails", command: "ls -la", analyzer: &MockSystemAnalyzer{ShouldError: true}, client: &MockAIClient{}},
+		{name: "oracle fails", command: "ls -la", analyzer: &MockSystemAnalyzer{}, client: &MockAIClient{ShouldError: true}, asked: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			explanation := &cli.CommandExplanation{Client: tc.client, Analyzer: tc.analyzer}
+			if _, err := explanation.Run(tc.command); err == nil {
+				t.Error("Expected an error")
+			}
+			if asked := tc.client.ExplainCallCount > 0; asked != tc.asked {
+				t.Errorf("Expected the oracle to be asked: %v, got %d calls", tc.asked, tc.client.ExplainCallCount)
+			}
+		})
+	}
+}
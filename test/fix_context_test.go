@@ -0,0 +1,104 @@
+// File: test/fix_context_test.go
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestClient_FixPromptCarriesExitCodesAndEarlierAttempts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // usage and parse statistics are kept next to the config
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request ai.OpenAIRequest
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &request)
+		prompt = request.Messages[len(request.Messages)-1].Content
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"type\":\"command\",\"command\":\"npm ci --legacy-peer-deps\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	client, err := ai.NewClient(compatibleConfig(server.URL, ""))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var earlierOutput []string
+	for i := 1; i <= 15; i++ {
+		earlierOutput = append(earlierOutput, fmt.Sprintf("npm WARN line %d", i))
+	}
+	first, second := 1, 127
+	_, err = client.FixCommand("install the dependencies", ai.Attempt{
+		Content:  "npm ci",
+		Error:    "exit status 127",
+		ExitCode: &second,
+		Output:   "sh: npm-lockfile: command not found",
+		Earlier:  []ai.Attempt{{Content: "npm install", ExitCode: &first, Output: strings.Join(earlierOutput, "\n")}},
+	}, &system.Info{OS: "linux", Shell: "bash"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"FAILED (exit code 127)", "npm-lockfile: command not found", "EARLIER ATTEMPTS", "Attempt 1 (exit code 1)", "npm install", "npm WARN line 15"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected the fix prompt to contain %q, got %s", expected, prompt)
+		}
+	}
+	if strings.Contains(prompt, "npm WARN line 5\n") {
+		t.Errorf("Expected only the last lines of an earlier attempt's output, got %s", prompt)
+	}
+}
+
+func TestPipeline_AutoFixSendsEveryFailedAttempt(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "lss -la"}
+	f.executor.FailOn = map[string]bool{"lss -la": true, "fixed: lss -la": true}
+	f.executor.FailOutput = "lss: command not found"
+
+	quest := newQuest("list files", "monarch")
+	quest.AutoFixLimit = 2
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if f.aiClient.FixCallCount != 2 {
+		t.Fatalf("Expected 2 fix requests, got %d", f.aiClient.FixCallCount)
+	}
+	last := f.aiClient.LastAttempt
+	if last.Content != "fixed: lss -la" || last.ExitCode == nil || *last.ExitCode != 1 {
+		t.Errorf("Expected the latest attempt with its exit code, got %+v", last)
+	}
+	if len(last.Earlier) != 1 || last.Earlier[0].Content != "lss -la" || last.Earlier[0].Output != "lss: command not found" {
+		t.Errorf("Expected the first failed attempt alongside the latest, got %+v", last.Earlier)
+	}
+}
+
+func TestSessionTurn_KeepsExitCodeAndOutputOfAFailure(t *testing.T) {
+	quest := newQuest("build the project", "monarch")
+	quest.Content = "make"
+	quest.Executed = true
+	quest.ExecErr = &system.ExecutionError{Err: errors.New("exit status 2"), ExitCode: 2, Output: "make: *** No rule to make target 'all'."}
+
+	turn := cli.SessionTurn(quest)
+	if turn == nil || turn.Outcome != "it ran and failed with exit code 2 (exit status 2)" {
+		t.Fatalf("Expected the exit code in the outcome, got %+v", turn)
+	}
+
+	session := history.NewSession("")
+	session.Add(turn)
+	messages := cli.SessionMessages(session)
+	result := messages[len(messages)-1].Content
+	if !strings.Contains(result, "exit code 2") || !strings.Contains(result, "Its last output lines were:") || !strings.Contains(result, "No rule to make target") {
+		t.Errorf("Expected the follow-up to hear how the quest failed, got %q", result)
+	}
+}
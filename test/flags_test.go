@@ -0,0 +1,95 @@
+// File: test/flags_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// testHelp answers like a HelpCache holding the help of a few tools
+func testHelp(tool string, args ...string) (string, bool) {
+	key := strings.Join(append([]string{tool}, args...), " ")
+	help := map[string]string{
+		"tar":        "Usage: tar [OPTION...] [FILE]...\n  -c, --create   create a new archive\n  -x, --extract  extract files\n  -z, --gzip     filter through gzip\n  -f, --file=ARCHIVE  use archive file",
+		"grep":       "Usage: grep [OPTION]... PATTERNS [FILE]...\n  -i, --ignore-case\n  -r, --recursive\n  -n, --line-number",
+		"git":        "usage: git [--version] [--help] [-C <path>] <command> [<args>]\n   commit     Record changes\n   status     Show the working tree status",
+		"git commit": "usage: git commit [options]\n    -m, --message <message>\n    --amend    amend previous commit\n    -a, --all  commit all changed files",
+		"echo":       "Usage: echo [STRING]...",
+	}
+	text, ok := help[key]
+	return text, ok && strings.Count(text, "-") >= 3
+}
+
+func TestCheckFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"documented flags", "tar -czf backup.tar.gz --file=x docs", ""},
+		{"invented long flag", "tar --frobnicate -czf backup.tar.gz docs", "tar --frobnicate"},
+		{"invented short flag", "grep -Q foo file.txt", "grep -Q"},
+		{"value after equals", "tar --exclude=node_modules -cf a.tar .", "tar --exclude"},
+		{"quoted arguments are not flags", `grep -i "use --force here" file.txt`, ""},
+		{"pipelines and chains", "grep -rn TODO . | tar --bogus && git status --porcelain", "tar --bogus"},
+		{"wrappers and assignments", "LC_ALL=C sudo -E grep --nope x", "grep --nope"},
+		{"subcommand help", "git commit --amend --no-such-thing -m 'fix'", "git commit --no-such-thing"},
+		{"subcommand without help", "git status --porcelain", ""},
+		{"unknown tools are skipped", "mytool --whatever && echo --anything", ""},
+		{"numbers and end of options", "grep -n -- --literal file && tar -5", ""},
+		{"script comments", "#!/bin/bash\n# tar --in-a-comment\ngrep -i x", ""},
+		{"substitutions are skipped", `grep -i "$(cat --weird)" $FLAGS`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, flag := range system.CheckFlags(tt.content, testHelp) {
+				got = append(got, flag.String())
+			}
+			if strings.Join(got, ", ") != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, strings.Join(got, ", "))
+			}
+		})
+	}
+}
+
+func TestPipeline_CorrectsUnknownFlags(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.NextResponses = []*ai.AIResponse{
+		{Type: ai.ResponseTypeCommand, Content: "tar --frobnicate -czf backup.tar.gz docs"},
+		{Type: ai.ResponseTypeCommand, Content: "tar -czf backup.tar.gz docs"},
+	}
+	deps := f.deps()
+	deps.CheckFlags = func(content string) []system.UnknownFlag { return system.CheckFlags(content, testHelp) }
+
+	if err := cli.NewPipeline(deps).Run(newQuest("archive the docs folder", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(f.aiClient.LastIntent, "FLAG CHECK") || !strings.Contains(f.aiClient.LastIntent, "'tar --frobnicate'") {
+		t.Errorf("Expected the unknown flag to be sent back, got %q", f.aiClient.LastIntent)
+	}
+	if len(f.executor.ExecutedCommands) != 1 || f.executor.ExecutedCommands[0] != "tar -czf backup.tar.gz docs" {
+		t.Errorf("Expected the corrected command to run, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestPipeline_KeepsCommandWhenCorrectionFails(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "grep -Q foo file.txt"}
+	deps := f.deps()
+	deps.CheckFlags = func(content string) []system.UnknownFlag { return system.CheckFlags(content, testHelp) }
+
+	if err := cli.NewPipeline(deps).Run(newQuest("search for foo", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.GenerateCallCount != 2 {
+		t.Errorf("Expected one correction attempt, got %d calls", f.aiClient.GenerateCallCount)
+	}
+	if len(f.executor.ExecutedCommands) != 1 || f.executor.ExecutedCommands[0] != "grep -Q foo file.txt" {
+		t.Errorf("Expected the approved command to run after the warning, got %v", f.executor.ExecutedCommands)
+	}
+}
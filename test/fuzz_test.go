@@ -0,0 +1,150 @@
+// File: test/fuzz_test.go
+package test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// The fuzz targets below run their seeds with every "go test". Explore further with e.g.
+//
+//	go test ./test -run '^$' -fuzz FuzzParseAIResponse -fuzztime 30s
+//
+// Inputs that fail are saved under testdata/fuzz/<target> and replayed from then on.
+
+// environmentSeeds are commands the environment validator must flag, with and without the
+// wrappers a model may put around them
+var environmentSeeds = []string{
+	"source ~/.bashrc",
+	". /etc/environment",
+	"export PATH=$PATH:/opt/bin",
+	"sudo -E source venv/bin/activate",
+	"cd /var/log && ls -la",
+	"nohup conda activate ml",
+	"apt install -y direnv && eval \"$(direnv hook bash)\"",
+	"alias ll='ls -la'",
+	"nvm use 20",
+	"ls -la",
+}
+
+func FuzzParseAIResponse(f *testing.F) {
+	for _, fixture := range loadResponseCorpus(f) {
+		f.Add(fixture.Raw)
+	}
+
+	markers := map[string]ai.ResponseType{
+		"COMMAND:": ai.ResponseTypeCommand,
+		"SCRIPT:":  ai.ResponseTypeScript,
+		"FAILURE:": ai.ResponseTypeFailure,
+		"ANSWER:":  ai.ResponseTypeAnswer,
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		response := ai.ParseAIResponse(raw)
+		ai.IsTruncatedResponse(raw)
+		if response == nil {
+			t.Fatalf("No response parsed from %q", raw)
+		}
+
+		if response.Content != strings.TrimSpace(response.Content) || response.Error != strings.TrimSpace(response.Error) {
+			t.Errorf("Expected trimmed content and error, got %+v from %q", response, raw)
+		}
+		if response.Type == ai.ResponseTypeFailure && response.Content != "" {
+			t.Errorf("A failure must carry no content to run, got %q from %q", response.Content, raw)
+		}
+		if response.Type != ai.ResponseTypeFailure && (response.Error != "" || response.Category != "") {
+			t.Errorf("Only a failure carries a reason, got %+v from %q", response, raw)
+		}
+
+		if !ai.IsWellFormedResponse(raw) {
+			// Only a JSON response in a code fence is unwrapped; anything else malformed is never run
+			if !strings.HasPrefix(strings.TrimSpace(raw), "```") && response.Type != ai.ResponseTypeFailure {
+				t.Errorf("Expected a malformed response to become a failure, got %v from %q", response.Type, raw)
+			}
+			return
+		}
+		for marker, responseType := range markers {
+			if strings.HasPrefix(strings.TrimSpace(raw), marker) && response.Type != responseType {
+				t.Errorf("Expected %s to be parsed as %v, got %v", marker, responseType, response.Type)
+			}
+		}
+	})
+}
+
+func FuzzEnvironmentValidator(f *testing.F) {
+	for _, command := range environmentSeeds {
+		f.Add(command, "bash")
+	}
+	for _, fixture := range loadResponseCorpus(f) {
+		if fixture.Type == "command" {
+			f.Add(fixture.Content, "bash")
+		}
+	}
+	f.Add("Set-Location C:\\Users", "pwsh")
+	f.Add("set -gx PATH $PATH ~/bin", "fish")
+	f.Add("$env.PATH = ($env.PATH | append ~/bin)", "nu")
+	f.Add("set PATH=%PATH%;C:\\tools", "cmd")
+
+	f.Fuzz(func(t *testing.T, command, shell string) {
+		validator := system.NewEnvironmentValidator(&system.Info{OS: "linux", Shell: shell})
+		err := validator.ValidateEnvironmentCommand(command)
+		if err == nil {
+			return
+		}
+
+		var envErr *system.EnvironmentCommandError
+		if !errors.As(err, &envErr) {
+			t.Fatalf("Expected an environment command error, got %T for %q", err, command)
+		}
+		if envErr.Command != command || envErr.Reason == "" {
+			t.Errorf("Expected the command and a reason, got %+v for %q", envErr, command)
+		}
+		if envErr.GetKnightlyMessage() == "" {
+			t.Errorf("Expected a message for %q", command)
+		}
+
+		// Running the command through sudo or a case change must not hide it
+		for _, variant := range []string{"sudo " + command, strings.ToUpper(command)} {
+			if strings.HasPrefix(strings.ToLower(command), "sudo ") || strings.HasPrefix(strings.ToLower(command), "nohup ") {
+				break
+			}
+			if validator.ValidateEnvironmentCommand(variant) == nil {
+				t.Errorf("%q is flagged as %s, but %q is not", command, envErr.Reason, variant)
+			}
+		}
+	})
+}
+
+func FuzzIntentValidator(f *testing.F) {
+	for _, intent := range []string{
+		"list files in ~/projects",
+		"copy report.pdf to ./missing/dir",
+		"move the folder C:\\Users\\me\\Desktop to ~",
+		"navigate to ../../",
+		"what directory am I in",
+		"",
+	} {
+		f.Add(intent)
+	}
+
+	home := f.TempDir()
+	validator := system.NewValidator(&system.Info{OS: "linux", Shell: "bash", CurrentDir: home, HomeDir: home})
+	f.Fuzz(func(t *testing.T, intent string) {
+		err := validator.ValidateIntent(intent)
+		if err == nil {
+			return
+		}
+
+		var missing *system.MissingPathError
+		if !errors.As(err, &missing) {
+			t.Fatalf("Expected a missing path error, got %T for %q", err, intent)
+		}
+		if !slices.Contains(strings.Fields(intent), missing.Path) {
+			t.Errorf("Expected the missing path %q to be a word of %q", missing.Path, intent)
+		}
+	})
+}
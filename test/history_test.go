@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Minand Nellipunath Manomohanan
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// File: test/history_test.go
+package test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/history"
+)
+
+func TestHistory_RecordFoldsRepeatedRuns(t *testing.T) {
+	store, err := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	store.Record("list files in Downloads", "", "ls ~/Downloads", false, nil, now)
+	store.Record("List  files in downloads", "", "ls ~/Downloads", false, nil, now.Add(time.Minute))
+	store.Record("list files in downloads", "", "ls -la ~/Downloads", false, nil, now.Add(2*time.Minute))
+
+	if len(store.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(store.Entries))
+	}
+	if store.Entries[0].Runs != 2 {
+		t.Errorf("Expected the repeated run to be folded in, got %d runs", store.Entries[0].Runs)
+	}
+}
+
+func TestHistory_FindSimilar(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	now := time.Now()
+	store.Record("delete logs older than 7 days", "", "find . -name '*.log' -mtime +7 -delete", false, nil, now)
+	store.Record("show disk usage", "", "df -h", false, nil, now)
+	store.Record("show disk usage", "", "du -sh .", false, nil, now)
+	store.Record("show disk usage", "", "du -sh .", false, nil, now)
+	store.Record("restart nginx", "prod", "systemctl restart nginx", false, nil, now)
+	store.Record("install htop", "", "apt install htop", false, errors.New("exit status 100"), now)
+
+	testCases := []struct {
+		intent   string
+		context  string
+		expected string
+	}{
+		{"show the disk usage please", "", "du -sh ."},
+		{"delete logs older than 7 days", "", "find . -name '*.log' -mtime +7 -delete"},
+		{"delete logs older than 30 days", "", ""},
+		{"restart nginx", "", ""},
+		{"restart nginx", "prod", "systemctl restart nginx"},
+		{"install htop", "", ""},
+		{"the", "", ""},
+	}
+
+	for _, tc := range testCases {
+		entry := store.FindSimilar(tc.intent, tc.context)
+		got := ""
+		if entry != nil {
+			got = entry.Content
+		}
+		if got != tc.expected {
+			t.Errorf("FindSimilar(%q, %q): expected %q, got %q", tc.intent, tc.context, tc.expected, got)
+		}
+	}
+}
+
+func TestHistory_Latest(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	now := time.Now()
+	store.Record("rotate the logs", "", "logrotate -f /etc/logrotate.conf", false, nil, now)
+	store.Record("Rotate the logs", "", "logrotate /etc/logrotate.conf", false, errors.New("exit status 1"), now.Add(time.Hour))
+	store.Record("rotate the logs", "prod", "logrotate -v /etc/logrotate.conf", false, nil, now.Add(2*time.Hour))
+
+	if latest := store.Latest("rotate the logs", ""); latest == nil || latest.Content != "logrotate /etc/logrotate.conf" {
+		t.Errorf("Expected the most recent run in the same context, got %+v", latest)
+	}
+	if latest := store.Latest("rotate logs", ""); latest != nil {
+		t.Errorf("Expected only the exact intent to count, got %+v", latest)
+	}
+}
+
+func TestHistory_RecentRun(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	now := time.Now()
+	store.Record("clear the staging data", "", "rm -rf /srv/staging", false, nil, now.Add(-40*time.Minute))
+	store.Record("wipe staging", "prod", "rm -rf /srv/staging", false, nil, now.Add(-3*time.Minute))
+
+	recent := store.RecentRun("rm -rf /srv/staging  \n", now.Add(-15*time.Minute))
+	if recent == nil || recent.Intent != "wipe staging" {
+		t.Errorf("Expected the latest run of the same command under any intent, got %+v", recent)
+	}
+	if recent := store.RecentRun("rm -rf /srv/staging", now.Add(-time.Minute)); recent != nil {
+		t.Errorf("Expected runs before the window to be ignored, got %+v", recent)
+	}
+	if recent := store.RecentRun("rm -rf /srv/staging/cache", now.Add(-time.Hour)); recent != nil {
+		t.Errorf("Expected only the same command to count, got %+v", recent)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	script := "#!/bin/bash\necho hello\n"
+	if history.Fingerprint(script) != history.Fingerprint("#!/bin/bash  \r\necho hello\r\n\n") {
+		t.Error("Expected line endings and trailing whitespace not to change the fingerprint")
+	}
+	if history.Fingerprint(script) == history.Fingerprint("#!/bin/bash\necho  hello\n") {
+		t.Error("Expected a changed line to change the fingerprint")
+	}
+}
+
+func TestHistory_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", history.HistoryFile)
+	store, _ := history.Load(path)
+	store.Record("backup the database", "", "pg_dump app > app.sql", false, nil, time.Now())
+	if err := store.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Content != "pg_dump app > app.sql" || loaded.Entries[0].Runs != 1 {
+		t.Errorf("Unexpected entries after reload: %+v", loaded.Entries)
+	}
+}
@@ -0,0 +1,121 @@
+// File: test/immutable_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// fakeRoot builds a filesystem root holding the given files
+func fakeRoot(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestDetectImmutableSystem(t *testing.T) {
+	const writableMounts = "/dev/sda1 / ext4 rw,relatime 0 0\nproc /proc proc rw 0 0\n"
+
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "ordinary distro",
+			files:    map[string]string{"etc/os-release": "ID=fedora\nVARIANT_ID=workstation\n", "proc/self/mounts": writableMounts},
+			expected: "not detected",
+		},
+		{
+			name: "fedora silverblue",
+			files: map[string]string{
+				"etc/os-release":    "NAME=\"Fedora Linux\"\nID=fedora\nVARIANT_ID=silverblue\nPRETTY_NAME=\"Fedora Linux 40 (Silverblue)\"\n",
+				"run/ostree-booted": "",
+				"proc/self/mounts":  writableMounts,
+			},
+			expected: "Fedora Linux 40 (Silverblue); ostree; install with rpm-ostree",
+		},
+		{
+			name:     "steamos from usr/lib",
+			files:    map[string]string{"usr/lib/os-release": "NAME=\"SteamOS\"\nID=steamos\n", "proc/self/mounts": "/dev/sda3 / btrfs ro,noatime 0 0\n"},
+			expected: "SteamOS; read-only root; install with flatpak",
+		},
+		{
+			name:     "unknown ostree distro",
+			files:    map[string]string{"etc/os-release": "ID=mydistro\n", "run/ostree-booted": ""},
+			expected: "ostree; install with rpm-ostree",
+		},
+		{
+			name:     "read-only container",
+			files:    map[string]string{"etc/os-release": "ID=debian\n", ".dockerenv": "", "proc/self/mounts": "overlay / overlay ro,relatime,lowerdir=/a 0 0\n"},
+			expected: "read-only root; inside docker",
+		},
+		{
+			name:     "root remounted writable",
+			files:    map[string]string{"proc/self/mounts": "/dev/sda1 / ext4 ro 0 0\n/dev/sda1 / ext4 rw 0 0\n"},
+			expected: "not detected",
+		},
+		{
+			name:     "toolbox on an immutable host",
+			files:    map[string]string{"etc/os-release": "ID=fedora\nVARIANT_ID=container\n", "run/.toolboxenv": "", "run/.containerenv": ""},
+			expected: "inside toolbox (writable)",
+		},
+		{
+			name:     "distrobox",
+			files:    map[string]string{"etc/os-release": "ID=ubuntu\n"},
+			env:      map[string]string{"DISTROBOX_ENTER_PATH": "/usr/bin/distrobox-enter"},
+			expected: "inside distrobox (writable)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := fakeRoot(t, tc.files)
+			immutable := system.DetectImmutableSystem(root, func(name string) string { return tc.env[name] })
+			if immutable.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, immutable.String())
+			}
+		})
+	}
+}
+
+func TestImmutableSystem_ConflictingInstall(t *testing.T) {
+	silverblue := system.ImmutableSystem{Distro: "Fedora Linux 40 (Silverblue)", Installer: "rpm-ostree", OSTree: true}
+
+	testCases := []struct {
+		name     string
+		system   system.ImmutableSystem
+		content  string
+		expected string
+	}{
+		{name: "dnf install", system: silverblue, content: "sudo dnf install -y htop", expected: "dnf install"},
+		{name: "dnf with flags first", system: silverblue, content: "dnf -y install htop", expected: "dnf -y install"},
+		{name: "pacman in a script", system: silverblue, content: "# Install htop\nsudo pacman -Syu htop", expected: "pacman -Syu"},
+		{name: "rpm-ostree is fine", system: silverblue, content: "rpm-ostree install htop", expected: ""},
+		{name: "toolbox is fine", system: silverblue, content: "toolbox run sudo dnf install -y htop", expected: ""},
+		{name: "flatpak is fine", system: silverblue, content: "flatpak install flathub org.gimp.GIMP", expected: ""},
+		{name: "mutable system", system: system.ImmutableSystem{}, content: "sudo apt install htop", expected: ""},
+		{name: "dnf search is fine", system: silverblue, content: "dnf search htop", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.system.ConflictingInstall(tc.content); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// File: test/integrate_test.go
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestShellWidget(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected []string
+	}{
+		{shell: "zsh", expected: []string{"bindkey '^X^W' _execute_my_will_widget", `BUFFER=$(<"$file")`, `'/opt/my tools/execute-my-will' --to-prompt "$file" -- "$BUFFER"`}},
+		{shell: "bash", expected: []string{`bind -x '"\C-x\C-w": _execute_my_will_widget'`, `READLINE_LINE=$(<"$file")`}},
+		{shell: "fish", expected: []string{`bind \cx\cw _execute_my_will_widget`, "commandline -r", `'/opt/my tools/execute-my-will' --to-prompt $file`}},
+		{shell: "powershell", expected: []string{"Set-PSReadLineKeyHandler -Chord 'Ctrl+x,Ctrl+w'", "PSConsoleReadLine]::Replace", `& '/opt/my tools/execute-my-will' --to-prompt`}},
+	}
+	for _, tc := range testCases {
+		widget, err := system.ShellWidget(tc.shell, "/opt/my tools/execute-my-will")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.shell, err)
+			continue
+		}
+		for _, expected := range tc.expected {
+			if !strings.Contains(widget, expected) {
+				t.Errorf("%s: expected the widget to contain %q, got:\n%s", tc.shell, expected, widget)
+			}
+		}
+	}
+
+	if _, err := system.ShellWidget("tcsh", "execute-my-will"); err == nil {
+		t.Error("Expected an error for a shell without a widget")
+	}
+	for name, expected := range map[string]string{"/usr/bin/zsh": "zsh", "pwsh.exe": "powershell", "fish": "fish", "/bin/sh": "", "cmd.exe": ""} {
+		if got := system.WidgetShell(name); got != expected {
+			t.Errorf("WidgetShell(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestShellWidget_ParsesInItsShell(t *testing.T) {
+	for _, shell := range []string{"zsh", "bash", "fish"} {
+		path, err := exec.LookPath(shell)
+		if err != nil {
+			continue
+		}
+		widget, _ := system.ShellWidget(shell, "/usr/bin/execute-my-will")
+		file := filepath.Join(t.TempDir(), system.WidgetFileName(shell))
+		if err := os.WriteFile(file, []byte(widget), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if output, err := exec.Command(path, "-n", file).CombinedOutput(); err != nil {
+			t.Errorf("%s rejects the widget: %v\n%s", shell, err, output)
+		}
+	}
+}
+
+func TestIntegration_LoadsTheWidgetFromTheStartupFile(t *testing.T) {
+	home := t.TempDir()
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("alias ll='ls -l'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	integration := &cli.Integration{
+		Prompter: &MockPrompter{Choices: []int{0}},
+		Binary:   "/usr/local/bin/execute-my-will",
+		Dir:      filepath.Join(home, ".config", "execute-my-will"),
+		HomeDir:  home,
+		GOOS:     "linux",
+		Now:      time.Now,
+	}
+
+	profile, err := integration.Run("bash")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if profile != bashrc {
+		t.Errorf("Expected %s to be changed, got %q", bashrc, profile)
+	}
+	widget := filepath.Join(integration.Dir, "widget.bash")
+	if data, err := os.ReadFile(widget); err != nil || !strings.Contains(string(data), "_execute_my_will_widget") {
+		t.Fatalf("Expected the widget to be written, got %v", err)
+	}
+	data, _ := os.ReadFile(bashrc)
+	if !strings.HasPrefix(string(data), "alias ll='ls -l'\n") || !strings.Contains(string(data), system.WidgetSourceLine("bash", widget)) {
+		t.Errorf("Expected the startup file to keep its lines and load the widget, got:\n%s", data)
+	}
+
+	// Integrating again only refreshes the widget
+	again := *integration
+	again.Prompter = &MockPrompter{}
+	if profile, err := again.Run("bash"); err != nil || profile != "" {
+		t.Errorf("Expected nothing more to add, got %q and %v", profile, err)
+	}
+	if updated, _ := os.ReadFile(bashrc); string(updated) != string(data) {
+		t.Errorf("Expected the startup file to be unchanged, got:\n%s", updated)
+	}
+}
+
+func TestIntegration_LeftToTheUser(t *testing.T) {
+	home := t.TempDir()
+	integration := &cli.Integration{
+		Prompter: &MockPrompter{Choices: []int{1}},
+		Binary:   "execute-my-will",
+		Dir:      filepath.Join(home, ".config", "execute-my-will"),
+		HomeDir:  home,
+		GOOS:     "linux",
+		Now:      time.Now,
+	}
+	if profile, err := integration.Run("bash"); err != nil || profile != "" {
+		t.Errorf("Expected the startup file to be left alone, got %q and %v", profile, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".bashrc")); !os.IsNotExist(err) {
+		t.Error("Expected no startup file to be created")
+	}
+	if _, err := integration.Run("tcsh"); err == nil {
+		t.Error("Expected an error for a shell without a widget")
+	}
+}
+
+func TestPipeline_ToPromptWritesTheCommandUnrun(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "cd ~/code/api && export NODE_ENV=test"}
+	f.envValidator.InvalidCommands = map[string]string{"cd ~/code/api && export NODE_ENV=test": "directory change"}
+
+	quest := newQuest("go to the api project in test mode", "monarch")
+	quest.ToPrompt = filepath.Join(t.TempDir(), "prompt")
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(quest.ToPrompt)
+	if err != nil || string(data) != "cd ~/code/api && export NODE_ENV=test" {
+		t.Errorf("Expected the environment command to reach the prompt line, got %q and %v", data, err)
+	}
+	if f.confirmer.CallCount != 0 || len(f.executor.ExecutedCommands) != 0 || quest.Executed {
+		t.Error("A command for the prompt line must never be confirmed or run here")
+	}
+}
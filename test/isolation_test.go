@@ -0,0 +1,198 @@
+// File: test/isolation_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func describeChanges(changes []system.FileChange) string {
+	var described []string
+	for _, change := range changes {
+		described = append(described, string(change.Kind)+" "+filepath.ToSlash(change.Path))
+	}
+	return strings.Join(described, ", ")
+}
+
+func TestScratchCopy_ChangesAndApply(t *testing.T) {
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"README.md":     "# App\nline 2\nline 3\n",
+		"src/main.go":   "package main\n",
+		"old/notes.txt": "notes\n",
+		"old/todo.txt":  "todo\n",
+	})
+
+	scratch, err := system.NewScratchCopy(source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer scratch.Remove()
+
+	if changes, _ := scratch.Changes(); len(changes) != 0 {
+		t.Fatalf("Expected a fresh copy to match, got %s", describeChanges(changes))
+	}
+
+	writeTree(t, scratch.Dir, map[string]string{
+		"README.md":      "# App\nline two\nline 3\n",
+		"src/util/id.go": "package util\n",
+	})
+	if err := os.RemoveAll(filepath.Join(scratch.Dir, "old")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := scratch.Changes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "modified README.md, deleted old, added src/util, added src/util/id.go"
+	if got := describeChanges(changes); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	diff := scratch.Diff(changes[0])
+	for _, line := range []string{"--- a/README.md", "+++ b/README.md", "@@ -1,3 +1,3 @@", "-line 2", "+line two"} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Errorf("Expected the diff to contain %q, got:\n%s", line, diff)
+		}
+	}
+
+	// The original is untouched until the changes are applied
+	if _, err := os.Stat(filepath.Join(source, "old", "notes.txt")); err != nil {
+		t.Fatalf("The original changed before Apply: %v", err)
+	}
+	if err := scratch.Apply(changes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(source, "README.md")); string(content) != "# App\nline two\nline 3\n" {
+		t.Errorf("Expected the modified README, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(source, "src", "util", "id.go")); err != nil {
+		t.Errorf("Expected the added file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(source, "old")); !os.IsNotExist(err) {
+		t.Errorf("Expected the deleted directory to be gone, got %v", err)
+	}
+	if changes, _ := scratch.Changes(); len(changes) != 0 {
+		t.Errorf("Expected the directories to match after Apply, got %s", describeChanges(changes))
+	}
+
+	dir := scratch.Dir
+	scratch.Remove()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the scratch copy to be removed, got %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	if diff := system.UnifiedDiff("a", "b", "same\n", "same\n"); diff != "" {
+		t.Errorf("Expected no diff for equal texts, got %q", diff)
+	}
+
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	new := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"
+	if diff := system.UnifiedDiff("a", "b", old, new); diff != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, diff)
+	}
+
+	if diff := system.UnifiedDiff("/dev/null", "b", "", "new\n"); !strings.Contains(diff, "@@ -0,0 +1,1 @@\n+new\n") {
+		t.Errorf("Unexpected diff for a new file:\n%s", diff)
+	}
+}
+
+// isolatedQuest writes to the directory the analyzer reports, as a generated command would
+func isolatedQuest(t *testing.T, files map[string]string) (func(cli.PipelineDeps) error, *string) {
+	var ranIn string
+	return func(deps cli.PipelineDeps) error {
+		info, err := deps.Analyzer.AnalyzeSystem()
+		if err != nil {
+			return err
+		}
+		ranIn = info.CurrentDir
+		writeTree(t, info.CurrentDir, files)
+		return nil
+	}, &ranIn
+}
+
+func TestIsolatedRun_AppliesApprovedChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"config.yaml": "debug: false\n"})
+
+	f := newPipelineFixture()
+	f.prompter.Choices = []int{0}
+	quest, ranIn := isolatedQuest(t, map[string]string{"config.yaml": "debug: true\n"})
+
+	outcome, err := (&cli.IsolatedRun{Deps: f.deps(), Dir: dir}).Run(quest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if outcome != cli.IsolationApplied {
+		t.Errorf("Expected the changes to be applied, got %s", outcome)
+	}
+	if *ranIn == dir || *ranIn == "" {
+		t.Errorf("Expected the quest to run in a scratch copy, got %q", *ranIn)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "config.yaml")); string(content) != "debug: true\n" {
+		t.Errorf("Expected the approved change, got %q", content)
+	}
+	if _, err := os.Stat(*ranIn); !os.IsNotExist(err) {
+		t.Errorf("Expected the scratch copy to be removed, got %v", err)
+	}
+}
+
+func TestIsolatedRun_DiscardsDeclinedChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"config.yaml": "debug: false\n"})
+
+	f := newPipelineFixture()
+	f.prompter.Choices = []int{1}
+	quest, _ := isolatedQuest(t, map[string]string{"config.yaml": "debug: true\n", "extra.txt": "x\n"})
+
+	outcome, err := (&cli.IsolatedRun{Deps: f.deps(), Dir: dir}).Run(quest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if outcome != cli.IsolationDiscarded {
+		t.Errorf("Expected the changes to be discarded, got %s", outcome)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "config.yaml")); string(content) != "debug: false\n" {
+		t.Errorf("Expected the original to be untouched, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "extra.txt")); !os.IsNotExist(err) {
+		t.Error("Expected no new file in the original")
+	}
+}
+
+func TestIsolatedRun_NothingChanged(t *testing.T) {
+	f := newPipelineFixture()
+	quest, _ := isolatedQuest(t, nil)
+
+	outcome, err := (&cli.IsolatedRun{Deps: f.deps(), Dir: t.TempDir()}).Run(quest)
+	if err != nil || outcome != cli.IsolationUnchanged {
+		t.Errorf("Expected an unchanged outcome, got %s, %v", outcome, err)
+	}
+	if len(f.prompter.Questions) != 0 {
+		t.Error("Nothing should be offered when the quest changed nothing")
+	}
+}
@@ -0,0 +1,82 @@
+// File: test/killswitch_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func skipIfMachineKillSwitch(t *testing.T) {
+	t.Helper()
+	if _, err := os.Stat(system.DefaultKillSwitchPath()); err == nil {
+		t.Skip("the machine-wide kill switch is engaged on this machine")
+	}
+}
+
+func TestCheckKillSwitch(t *testing.T) {
+	skipIfMachineKillSwitch(t)
+	path := filepath.Join(t.TempDir(), "disabled")
+
+	if killSwitch := system.CheckKillSwitch(path); killSwitch != nil {
+		t.Fatalf("Expected no kill switch, got %+v", killSwitch)
+	}
+	if killSwitch := system.CheckKillSwitch(""); killSwitch != nil {
+		t.Fatalf("Expected no kill switch without a configured path, got %+v", killSwitch)
+	}
+
+	if err := os.WriteFile(path, []byte("  Paused during the incident; ask #ops.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	killSwitch := system.CheckKillSwitch(path)
+	if killSwitch == nil || killSwitch.Path != path || killSwitch.Message != "Paused during the incident; ask #ops." {
+		t.Errorf("Expected the engaged kill switch with its message, got %+v", killSwitch)
+	}
+}
+
+// killSwitchConfirmer engages the kill switch while the quest awaits approval, then approves it
+type killSwitchConfirmer struct {
+	path string
+}
+
+func (c *killSwitchConfirmer) Confirm(q *cli.Quest) (bool, error) {
+	return true, os.WriteFile(c.path, nil, 0o644)
+}
+
+func TestPipeline_HonorsKillSwitch(t *testing.T) {
+	skipIfMachineKillSwitch(t)
+
+	t.Run("before generation", func(t *testing.T) {
+		f := newPipelineFixture()
+		quest := newQuest("install docker", "monarch")
+		quest.Config.Execution.KillSwitch = filepath.Join(t.TempDir(), "disabled")
+		if err := os.WriteFile(quest.Config.Execution.KillSwitch, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := f.pipeline().Run(quest); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if f.aiClient.GenerateCallCount != 0 || quest.Executed {
+			t.Errorf("Expected nothing generated or executed, got %d calls and executed=%v", f.aiClient.GenerateCallCount, quest.Executed)
+		}
+	})
+
+	t.Run("before execution", func(t *testing.T) {
+		f := newPipelineFixture()
+		quest := newQuest("install docker", "monarch")
+		quest.Config.Execution.KillSwitch = filepath.Join(t.TempDir(), "disabled")
+		deps := f.deps()
+		deps.Confirmer = &killSwitchConfirmer{path: quest.Config.Execution.KillSwitch}
+
+		if err := cli.NewPipeline(deps).Run(quest); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if quest.Executed || len(f.executor.ExecutedCommands) != 0 {
+			t.Errorf("Expected the approved quest not to run, got %v", f.executor.ExecutedCommands)
+		}
+	})
+}
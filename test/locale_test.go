@@ -0,0 +1,105 @@
+// File: test/locale_test.go
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+func TestParseLocale(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "de_DE.UTF-8", expected: "de"},
+		{value: "en_US.UTF-8", expected: "en_US"},
+		{value: "en-us", expected: "en_US"},
+		{value: "en_GB", expected: "en"},
+		{value: "fr_CA.utf8@euro", expected: "fr"},
+		{value: "C.UTF-8", expected: "iso"},
+		{value: "POSIX", expected: "iso"},
+		{value: "", expected: "iso"},
+	}
+	for _, tc := range testCases {
+		locale, err := ui.ParseLocale(tc.value)
+		if err != nil || locale.Name != tc.expected {
+			t.Errorf("%q: expected %q, got %q and %v", tc.value, tc.expected, locale.Name, err)
+		}
+	}
+
+	if _, err := ui.ParseLocale("klingon"); err == nil {
+		t.Error("Expected an unknown locale to be rejected")
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	if got := ui.DetectLocale(env(map[string]string{"LANG": "de_DE.UTF-8"})).Name; got != "de" {
+		t.Errorf("Expected LANG to be followed, got %q", got)
+	}
+	if got := ui.DetectLocale(env(map[string]string{"LC_ALL": "ja_JP.UTF-8", "LANG": "de_DE.UTF-8"})).Name; got != "ja" {
+		t.Errorf("Expected LC_ALL to win over LANG, got %q", got)
+	}
+	if got := ui.DetectLocale(env(map[string]string{"LC_ALL": "tlh_QS", "LANG": "de_DE.UTF-8"})).Name; got != ui.DefaultLocale {
+		t.Errorf("Expected an unknown LC_ALL to fall back to %q, got %q", ui.DefaultLocale, got)
+	}
+	if got := ui.DetectLocale(env(nil)).Name; got != ui.DefaultLocale {
+		t.Errorf("Expected %q without any locale variable, got %q", ui.DefaultLocale, got)
+	}
+}
+
+func TestLocaleFormatting(t *testing.T) {
+	defer ui.SetLocale(ui.GetLocale())
+	at := time.Date(2025, 3, 1, 21, 5, 0, 0, time.Local)
+
+	testCases := []struct {
+		locale    string
+		timestamp string
+		duration  string
+		size      string
+		number    string
+	}{
+		{locale: "iso", timestamp: "2025-03-01 21:05", duration: "4.2 s", size: "1.5 MiB", number: "1,234,567"},
+		{locale: "en_US", timestamp: "03/01/2025 9:05 PM", duration: "4.2 s", size: "1.5 MiB", number: "1,234,567"},
+		{locale: "de_DE", timestamp: "01.03.2025 21:05", duration: "4,2 s", size: "1,5 MiB", number: "1.234.567"},
+		{locale: "fr_FR", timestamp: "01/03/2025 21:05", duration: "4,2 s", size: "1,5 MiB", number: "1 234 567"},
+	}
+	for _, tc := range testCases {
+		locale, err := ui.ParseLocale(tc.locale)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ui.SetLocale(locale)
+
+		if got := ui.FormatTimestamp(at); got != tc.timestamp {
+			t.Errorf("%s: expected timestamp %q, got %q", tc.locale, tc.timestamp, got)
+		}
+		if got := ui.FormatDuration(4200 * time.Millisecond); got != tc.duration {
+			t.Errorf("%s: expected duration %q, got %q", tc.locale, tc.duration, got)
+		}
+		if got := ui.FormatBytes(1536 * 1024); got != tc.size {
+			t.Errorf("%s: expected size %q, got %q", tc.locale, tc.size, got)
+		}
+		if got := ui.FormatNumber(1234567); got != tc.number {
+			t.Errorf("%s: expected number %q, got %q", tc.locale, tc.number, got)
+		}
+	}
+
+	for duration, expected := range map[time.Duration]string{
+		850 * time.Millisecond:        "850 ms",
+		3*time.Minute + 5*time.Second: "3 min 05 s",
+		2*time.Hour + 7*time.Minute:   "2 h 07 min",
+	} {
+		if got := ui.FormatDuration(duration); got != expected {
+			t.Errorf("%v: expected %q, got %q", duration, expected, got)
+		}
+	}
+	if got := ui.FormatBytes(512); got != "512 B" {
+		t.Errorf("Expected bytes below a KiB as they are, got %q", got)
+	}
+}
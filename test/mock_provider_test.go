@@ -0,0 +1,97 @@
+// File: test/mock_provider_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func mockConfig(responses string) *config.Config {
+	return &config.Config{AIProvider: "mock", Model: "mock", MaxTokens: 1000, Mode: "monarch", Mock: config.MockConfig{Responses: responses}}
+}
+
+func TestMockProvider_ExampleResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // usage and parse statistics are kept next to the config
+	client, err := ai.NewClient(mockConfig(filepath.Join("..", "docs", "mock-responses.yaml")))
+	if err != nil {
+		t.Fatalf("Failed to create the mock client: %v", err)
+	}
+	sysInfo := &system.Info{OS: "linux", Shell: "/bin/bash", CurrentDir: "/tmp"}
+
+	testCases := []struct {
+		intent       string
+		expectedType ai.ResponseType
+		expected     string
+	}{
+		{intent: "list all files here", expectedType: ai.ResponseTypeCommand, expected: "ls -la"},
+		{intent: "How much DISK SPACE is left", expectedType: ai.ResponseTypeCommand, expected: "df -h"},
+		{intent: "set up a demo project", expectedType: ai.ResponseTypeScript, expected: "mkdir -p demo/src demo/docs"},
+		{intent: "delete everything", expectedType: ai.ResponseTypeFailure, expected: "refuses"},
+		{intent: "reticulate splines", expectedType: ai.ResponseTypeFailure, expected: "no response"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			response, err := client.GenerateResponse(tc.intent, sysInfo)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.Type != tc.expectedType {
+				t.Errorf("Expected type %v, got %v (%+v)", tc.expectedType, response.Type, response)
+			}
+			if !strings.Contains(response.Content+response.Error, tc.expected) {
+				t.Errorf("Expected %q in %+v", tc.expected, response)
+			}
+		})
+	}
+
+	summary, err := client.SummarizeOutput("list all files here", "ls -la", "total 0", 1)
+	if err != nil || summary != "The command finished without errors." {
+		t.Errorf("Expected the canned summary, got %q, %v", summary, err)
+	}
+}
+
+func TestMockProvider_Errors(t *testing.T) {
+	dir := t.TempDir()
+	badPattern := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(badPattern, []byte("responses:\n  - intent: \"(unclosed\"\n    response: \"COMMAND: ls\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		responses string
+		expected  string
+	}{
+		{name: "no responses file", responses: "", expected: "mock.responses"},
+		{name: "missing file", responses: filepath.Join(dir, "missing.yaml"), expected: "failed to read"},
+		{name: "invalid pattern", responses: badPattern, expected: "mock response 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ai.NewMockProvider(mockConfig(tc.responses))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected an error mentioning %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateMockProvider(t *testing.T) {
+	cfg := mockConfig("~/mock.yaml")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("The mock provider should need no API key: %v", err)
+	}
+
+	cfg.Mock.Responses = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for the mock provider without a responses file")
+	}
+}
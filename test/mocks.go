@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
 	"github.com/minand-mohan/execute-my-will/internal/config"
 	"github.com/minand-mohan/execute-my-will/internal/system"
 )
@@ -40,31 +41,65 @@ func (m *MockSystemAnalyzer) AnalyzeSystem() (*system.Info, error) {
 // MockCommandExecutor
 type MockCommandExecutor struct {
 	ShouldError      bool
+	FailOn           map[string]bool // commands that fail even when ShouldError is false
+	FailOutput       string          // output captured with every failure
 	ExecutedCommands []string
 	ExecutedScripts  []string
+	ElevatedCommands []string
 	LastShell        string
 	LastShowComments bool
+	Output           *system.OutputLog // returned by LastOutput after every run
+	OnRun            func()            // called by every run, e.g. to create the files a command would
 }
 
 func (m *MockCommandExecutor) Execute(command string, shell string) error {
 	m.ExecutedCommands = append(m.ExecutedCommands, command)
+	m.run()
 	m.LastShell = shell
-	if m.ShouldError {
-		return errors.New("mock execution error")
+	if m.ShouldError || m.FailOn[command] {
+		return m.failure("mock execution error")
 	}
 	return nil
 }
 
 func (m *MockCommandExecutor) ExecuteScript(scriptContent string, shell string, showComments bool) error {
 	m.ExecutedScripts = append(m.ExecutedScripts, scriptContent)
+	m.run()
 	m.LastShell = shell
 	m.LastShowComments = showComments
 	if m.ShouldError {
-		return errors.New("mock script execution error")
+		return m.failure("mock script execution error")
 	}
 	return nil
 }
 
+func (m *MockCommandExecutor) ExecuteElevated(command string, shell string) error {
+	m.ElevatedCommands = append(m.ElevatedCommands, command)
+	m.run()
+	m.LastShell = shell
+	if m.ShouldError || m.FailOn[command] {
+		return m.failure("mock elevated execution error")
+	}
+	return nil
+}
+
+func (m *MockCommandExecutor) run() {
+	if m.OnRun != nil {
+		m.OnRun()
+	}
+}
+
+func (m *MockCommandExecutor) LastOutput() *system.OutputLog {
+	return m.Output
+}
+
+func (m *MockCommandExecutor) failure(message string) error {
+	if m.FailOutput == "" {
+		return errors.New(message)
+	}
+	return &system.ExecutionError{Err: errors.New(message), ExitCode: 1, Output: m.FailOutput}
+}
+
 // MockEnvironmentValidator
 type MockEnvironmentValidator struct {
 	ShouldError     bool
@@ -108,18 +143,59 @@ func (m *MockIntentValidator) ValidateIntent(intent string) error {
 // MockAIClient
 type MockAIClient struct {
 	ShouldError       bool
+	Err               error // returned by GenerateResponse in place of a plain error when ShouldError is set
 	Response          *ai.AIResponse
+	NextResponses     []*ai.AIResponse // returned in turn before Response
 	ExplanationText   string
+	LastExplained     string
+	AnswerResponse    *ai.AIResponse
+	LastQuestion      string
 	Models            []string
+	ChecksumResponse  *ai.AIResponse
+	FixResponse       *ai.AIResponse
+	RollbackResponse  *ai.AIResponse
+	CommitMessages    []string // returned in turn, the last one repeated
+	LastRejected      []string
+	LastChanges       *system.StagedChanges
+	LastRollback      ai.Attempt
+	ListingResponse   *ai.AIResponse
+	SummaryText       string
+	LastSummaryOutput string
+	DiagnosisText     string
+	PlanSteps         []string
+	LastPlanIntent    string
+	LastIncident      ai.Incident
+	LastAttempt       ai.Attempt
+	LastIntent        string
 	GenerateCallCount int
 	ExplainCallCount  int
+	AnswerCallCount   int
+	ChecksumCallCount int
+	FixCallCount      int
+	RollbackCallCount int
+	CommitCallCount   int
+	ListingCallCount  int
+	SummaryCallCount  int
+	DiagnoseCallCount int
+	PlanCallCount     int
+	RecordedExchanges []ai.Exchange
+	Conversation      []ai.Message
 }
 
 func (m *MockAIClient) GenerateResponse(intent string, sysInfo *system.Info) (*ai.AIResponse, error) {
 	m.GenerateCallCount++
+	m.LastIntent = intent
+	if m.ShouldError && m.Err != nil {
+		return nil, m.Err
+	}
 	if m.ShouldError {
 		return nil, errors.New("mock AI error")
 	}
+	if len(m.NextResponses) > 0 {
+		response := m.NextResponses[0]
+		m.NextResponses = m.NextResponses[1:]
+		return response, nil
+	}
 	if m.Response != nil {
 		return m.Response, nil
 	}
@@ -129,8 +205,19 @@ func (m *MockAIClient) GenerateResponse(intent string, sysInfo *system.Info) (*a
 	}, nil
 }
 
+func (m *MockAIClient) StreamResponse(intent string, sysInfo *system.Info, handler ai.StreamHandler) (*ai.AIResponse, error) {
+	response, err := m.GenerateResponse(intent, sysInfo)
+	if err == nil {
+		handler.Begin()
+		handler.Chunk(response.Content)
+		handler.End()
+	}
+	return response, err
+}
+
 func (m *MockAIClient) ExplainCommand(command string, sysInfo *system.Info) (string, error) {
 	m.ExplainCallCount++
+	m.LastExplained = command
 	if m.ShouldError {
 		return "", errors.New("mock explanation error")
 	}
@@ -140,6 +227,132 @@ func (m *MockAIClient) ExplainCommand(command string, sysInfo *system.Info) (str
 	return fmt.Sprintf("This command does: %s", command), nil
 }
 
+func (m *MockAIClient) AddChecksumVerification(content string, sysInfo *system.Info) (*ai.AIResponse, error) {
+	m.ChecksumCallCount++
+	if m.ShouldError {
+		return nil, errors.New("mock checksum error")
+	}
+	if m.ChecksumResponse != nil {
+		return m.ChecksumResponse, nil
+	}
+	// A real rewrite saves the download and checks it before running it, never piping it to a shell
+	return &ai.AIResponse{
+		Type:    ai.ResponseTypeScript,
+		Content: "curl -fsSLo install.sh https://example.com/install.sh\necho \"$EXPECTED_SHA256  install.sh\" | sha256sum -c -\nsh install.sh",
+	}, nil
+}
+
+func (m *MockAIClient) FixCommand(intent string, attempt ai.Attempt, sysInfo *system.Info) (*ai.AIResponse, error) {
+	m.FixCallCount++
+	m.LastAttempt = attempt
+	if m.ShouldError {
+		return nil, errors.New("mock fix error")
+	}
+	if m.FixResponse != nil {
+		return m.FixResponse, nil
+	}
+	return &ai.AIResponse{
+		Type:    ai.ResponseTypeCommand,
+		Content: fmt.Sprintf("fixed: %s", attempt.Content),
+	}, nil
+}
+
+func (m *MockAIClient) ListCleanupCandidates(intent string, sysInfo *system.Info) (*ai.AIResponse, error) {
+	m.ListingCallCount++
+	if m.ShouldError {
+		return nil, errors.New("mock listing error")
+	}
+	if m.ListingResponse != nil {
+		return m.ListingResponse, nil
+	}
+	return &ai.AIResponse{Type: ai.ResponseTypeFailure, Error: "no listing configured"}, nil
+}
+
+func (m *MockAIClient) SummarizeOutput(intent, content, output string, lines int) (string, error) {
+	m.SummaryCallCount++
+	m.LastSummaryOutput = output
+	if m.ShouldError {
+		return "", errors.New("mock summary error")
+	}
+	if m.SummaryText != "" {
+		return m.SummaryText, nil
+	}
+	return fmt.Sprintf("%d lines of output from: %s", lines, content), nil
+}
+
+func (m *MockAIClient) DiagnoseIncident(incident ai.Incident) (string, error) {
+	m.DiagnoseCallCount++
+	m.LastIncident = incident
+	if m.ShouldError {
+		return "", errors.New("mock diagnosis error")
+	}
+	if m.DiagnosisText != "" {
+		return m.DiagnosisText, nil
+	}
+	return fmt.Sprintf("### What happened\n%s %s", incident.Content, incident.Outcome), nil
+}
+
+func (m *MockAIClient) AnswerQuestion(question string, sysInfo *system.Info) (*ai.AIResponse, error) {
+	m.AnswerCallCount++
+	m.LastQuestion = question
+	if m.ShouldError {
+		return nil, errors.New("mock answer error")
+	}
+	if m.AnswerResponse != nil {
+		return m.AnswerResponse, nil
+	}
+	return &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "COMMAND NEEDED"}, nil
+}
+
+func (m *MockAIClient) GenerateRollback(intent string, attempt ai.Attempt, sysInfo *system.Info) (*ai.AIResponse, error) {
+	m.RollbackCallCount++
+	m.LastRollback = attempt
+	if m.ShouldError {
+		return nil, errors.New("mock rollback error")
+	}
+	if m.RollbackResponse != nil {
+		return m.RollbackResponse, nil
+	}
+	return &ai.AIResponse{Type: ai.ResponseTypeFailure, Category: ai.FailureImpossible, Error: "mock: nothing to undo"}, nil
+}
+
+func (m *MockAIClient) GenerateCommitMessage(changes *system.StagedChanges, rejected []string) (string, error) {
+	m.CommitCallCount++
+	m.LastRejected = rejected
+	m.LastChanges = changes
+	if m.ShouldError {
+		return "", errors.New("mock commit message error")
+	}
+	if len(m.CommitMessages) == 0 {
+		return fmt.Sprintf("Update %d files", len(changes.Files)), nil
+	}
+	message := m.CommitMessages[0]
+	if len(m.CommitMessages) > 1 {
+		m.CommitMessages = m.CommitMessages[1:]
+	}
+	return message, nil
+}
+
+func (m *MockAIClient) PlanQuest(intent string, sysInfo *system.Info) ([]string, error) {
+	m.PlanCallCount++
+	m.LastPlanIntent = intent
+	if m.ShouldError {
+		return nil, errors.New("mock plan error")
+	}
+	if m.PlanSteps != nil {
+		return m.PlanSteps, nil
+	}
+	return []string{"first step of: " + intent, "second step of: " + intent}, nil
+}
+
+func (m *MockAIClient) Exchanges() []ai.Exchange {
+	return m.RecordedExchanges
+}
+
+func (m *MockAIClient) Continue(history []ai.Message) {
+	m.Conversation = history
+}
+
 func (m *MockAIClient) ListModels() ([]string, error) {
 	if m.ShouldError {
 		return nil, errors.New("mock list models error")
@@ -184,3 +397,107 @@ func (m *MockConfig) ToConfig() *config.Config {
 		Mode:        m.Mode,
 	}
 }
+
+// MockConfirmer
+type MockConfirmer struct {
+	Approve      bool
+	ShouldError  bool
+	DeclineAfter int // when > 0, decline every confirmation after this many
+	CallCount    int
+}
+
+func (m *MockConfirmer) Confirm(q *cli.Quest) (bool, error) {
+	m.CallCount++
+	if m.ShouldError {
+		return false, errors.New("mock confirmation error")
+	}
+	if m.DeclineAfter > 0 && m.CallCount > m.DeclineAfter {
+		return false, nil
+	}
+	return m.Approve, nil
+}
+
+// MockPrompter answers wizard questions from scripted choices and answers, in order
+type MockPrompter struct {
+	Choices    []int
+	Selections [][]int
+	Answers    []string
+	Questions  []string
+	Options    [][]string // the options offered with each multi-select question
+}
+
+func (m *MockPrompter) Choose(question string, options []string) (int, error) {
+	m.Questions = append(m.Questions, question)
+	if len(m.Choices) == 0 {
+		return 0, errors.New("mock prompter has no more choices")
+	}
+	choice := m.Choices[0]
+	m.Choices = m.Choices[1:]
+	return choice, nil
+}
+
+func (m *MockPrompter) ChooseMany(question string, options []string) ([]int, error) {
+	m.Questions = append(m.Questions, question)
+	m.Options = append(m.Options, options)
+	if len(m.Selections) == 0 {
+		return nil, errors.New("mock prompter has no more selections")
+	}
+	selection := m.Selections[0]
+	m.Selections = m.Selections[1:]
+	return selection, nil
+}
+
+func (m *MockPrompter) Ask(question, defaultValue string) (string, error) {
+	m.Questions = append(m.Questions, question)
+	if len(m.Answers) == 0 {
+		return defaultValue, nil
+	}
+	answer := m.Answers[0]
+	m.Answers = m.Answers[1:]
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// MockGitRepository records commits instead of making them
+type MockGitRepository struct {
+	Changes  *system.StagedChanges
+	Commits  []string
+	Edited   []bool
+	StageErr error
+}
+
+func (m *MockGitRepository) StagedChanges() (*system.StagedChanges, error) {
+	if m.StageErr != nil {
+		return nil, m.StageErr
+	}
+	return m.Changes, nil
+}
+
+func (m *MockGitRepository) Commit(message string, edit bool) error {
+	m.Commits = append(m.Commits, message)
+	m.Edited = append(m.Edited, edit)
+	return nil
+}
+
+// MockScheduler records the spells installed and removed instead of touching a system scheduler
+type MockScheduler struct {
+	Installed map[string]*system.Schedule
+	Commands  map[string][]string
+	Removed   []string
+}
+
+func (m *MockScheduler) Install(name string, schedule *system.Schedule, command []string) error {
+	if m.Installed == nil {
+		m.Installed, m.Commands = map[string]*system.Schedule{}, map[string][]string{}
+	}
+	m.Installed[name], m.Commands[name] = schedule, command
+	return nil
+}
+
+func (m *MockScheduler) Remove(name string) error {
+	m.Removed = append(m.Removed, name)
+	delete(m.Installed, name)
+	return nil
+}
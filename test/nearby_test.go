@@ -0,0 +1,113 @@
+// File: test/nearby_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestNearbyDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"build", "docs", "photos-2024", ".git"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "photos.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := system.NearbyDirectories("compress the photos folder", dir, 8)
+	if !slices.Equal(got, []string{"photos-2024", "build", "docs"}) {
+		t.Errorf("Expected the matching directory first and no hidden ones or files, got %v", got)
+	}
+	if got := system.NearbyDirectories("clean that folder", dir, 2); len(got) != 2 {
+		t.Errorf("Expected the limit to apply, got %v", got)
+	}
+	if got := system.NearbyDirectories("anything", filepath.Join(dir, "missing"), 8); got != nil {
+		t.Errorf("Expected nothing for a missing directory, got %v", got)
+	}
+}
+
+func TestSimilarPaths(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	for _, path := range []string{"docs/design", "docs/guides", "src", "node_modules/design"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(home, "Pictures", "Holidays"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sep := string(filepath.Separator)
+
+	testCases := []struct {
+		path     string
+		expected []string
+	}{
+		{"./doc/desgin", []string{"." + sep + filepath.Join("docs", "design")}},
+		{"docs/Guides", []string{"." + sep + filepath.Join("docs", "guides")}},
+		// Found by name under the current directory, but never inside node_modules
+		{"./design", []string{"." + sep + filepath.Join("docs", "design")}},
+		{"~/pictures/holiday", []string{"~" + sep + filepath.Join("Pictures", "Holidays")}},
+		{"./completely/unrelated", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := system.SimilarPaths(tc.path, dir, home, 3); !slices.Equal(got, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPipeline_SuggestsSimilarPaths(t *testing.T) {
+	testCases := []struct {
+		name     string
+		choices  []int
+		expected string // the intent sent to the oracle; empty when the quest stops
+	}{
+		{"accepted", []int{0}, "list the files in ." + string(filepath.Separator) + filepath.Join("docs", "design")},
+		{"declined", []int{1}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "docs", "design"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(dir)
+
+			f := newPipelineFixture()
+			f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: dir, HomeDir: t.TempDir()}
+			f.prompter.Choices = tc.choices
+			deps := f.deps()
+			deps.NewIntentValidator = system.NewValidator
+
+			q := newQuest("list the files in ./doc/desgin", "monarch")
+			if err := cli.NewPipeline(deps).Run(q); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(f.prompter.Questions) != 1 {
+				t.Errorf("Expected one question, got %v", f.prompter.Questions)
+			}
+			if tc.expected == "" {
+				if f.aiClient.GenerateCallCount != 0 {
+					t.Error("Expected the quest to stop")
+				}
+				return
+			}
+			if q.Intent != tc.expected || f.aiClient.GenerateCallCount != 1 {
+				t.Errorf("Expected %q to be sent to the oracle, got %q", tc.expected, q.Intent)
+			}
+		})
+	}
+}
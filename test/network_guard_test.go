@@ -0,0 +1,76 @@
+// File: test/network_guard_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestDetectNetworkOperations(t *testing.T) {
+	testCases := []struct {
+		name       string
+		content    string
+		operations int
+	}{
+		{name: "curl", content: "curl -fsSL https://example.com/data.json -o data.json", operations: 1},
+		{name: "wget without a scheme", content: "wget example.com/file", operations: 1},
+		{name: "git clone", content: "git clone git@github.com:org/repo.git", operations: 1},
+		{name: "git with a directory", content: "git -C ~/code/repo pull --rebase", operations: 1},
+		{name: "apt update behind sudo", content: "sudo apt update && sudo apt upgrade -y", operations: 1},
+		{name: "pacman sync", content: "sudo pacman -Syu", operations: 1},
+		{name: "pip install", content: "pip install requests", operations: 1},
+		{name: "pip from a local wheelhouse", content: "pip install --no-index --find-links ./wheels requests", operations: 0},
+		{name: "docker pull in a pipeline", content: "docker images | grep web && docker pull nginx:latest", operations: 1},
+		{name: "rsync to a host", content: "rsync -avz ./site/ deploy@web01:/var/www/", operations: 1},
+		{name: "rsync between local directories", content: "rsync -a ./src/ ./backup/", operations: 0},
+		{name: "substitution", content: `echo "$(curl -s ifconfig.me)"`, operations: 1},
+		{name: "remote url in another tool", content: "ffmpeg -i https://cdn.example.com/stream.m3u8 out.mp4", operations: 1},
+		{name: "local health check", content: "curl -fsS http://localhost:8080/health", operations: 0},
+		{name: "loopback address", content: "wget -qO- 127.0.0.1:9090/metrics", operations: 0},
+		{name: "local git commands", content: "git status && git log --oneline -5", operations: 0},
+		{name: "local files", content: "tar -czf backup.tar.gz ~/docs\nfind . -name '*.log' -delete", operations: 0},
+		{name: "powershell download", content: "Invoke-WebRequest -Uri https://example.com/a.zip -OutFile a.zip", operations: 1},
+		{name: "script lines counted separately", content: "#!/bin/bash\n# curl in a comment\ncurl https://a.example\nwget https://b.example", operations: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			operations := system.DetectNetworkOperations(tc.content)
+			if len(operations) != tc.operations {
+				t.Errorf("Expected %d network operations, got %d: %+v", tc.operations, len(operations), operations)
+			}
+		})
+	}
+}
+
+func TestPipeline_AirGappedRefusesNetwork(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "sudo apt update"}
+
+	quest := newQuest("refresh the package lists", "monarch")
+	quest.AirGapped = true
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 || f.confirmer.CallCount != 0 {
+		t.Errorf("Expected a networked command to be refused before confirmation, got %v", f.executor.ExecutedCommands)
+	}
+	if !strings.Contains(f.aiClient.LastIntent, "AIR-GAPPED") {
+		t.Errorf("Expected the oracle to be told the machine is offline, got %q", f.aiClient.LastIntent)
+	}
+
+	// Local commands still run
+	f = newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "du -sh ~/docs"}
+	quest = newQuest("how big is my docs folder", "monarch")
+	quest.AirGapped = true
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected a local command to run, got %v", f.executor.ExecutedCommands)
+	}
+}
@@ -0,0 +1,56 @@
+// File: test/nix_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestDetectNixEnvironment(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "service")
+	nested := filepath.Join(project, "cmd", "server")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{filepath.Join(project, "flake.nix"), filepath.Join(nested, "shell.nix")} {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		dir      string
+		env      map[string]string
+		expected string
+	}{
+		{name: "plain directory", dir: root, expected: "not detected"},
+		{name: "flake in project", dir: project, expected: "flake: " + filepath.Join(project, "flake.nix")},
+		{name: "flake in parent with shell.nix", dir: nested, expected: "flake: " + filepath.Join(project, "flake.nix") + "; dev shell: shell.nix"},
+		{name: "nix develop", dir: root, env: map[string]string{"IN_NIX_SHELL": "impure"}, expected: "running inside nix shell (impure)"},
+		{name: "devenv", dir: root, env: map[string]string{"IN_NIX_SHELL": "impure", "DEVENV_ROOT": root}, expected: "running inside devenv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nix := system.DetectNixEnvironment(tc.dir, func(name string) string { return tc.env[name] })
+			if nix.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, nix.String())
+			}
+			if nix.Detected() != (tc.expected != "not detected") {
+				t.Errorf("Detected() = %v for %q", nix.Detected(), nix.String())
+			}
+		})
+	}
+}
+
+func TestNixEnvironment_NixOS(t *testing.T) {
+	nix := system.NixEnvironment{NixOS: true}
+	if !nix.Detected() || nix.String() != "NixOS" {
+		t.Errorf("Expected NixOS to be reported, got %q", nix.String())
+	}
+}
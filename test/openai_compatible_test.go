@@ -0,0 +1,103 @@
+// File: test/openai_compatible_test.go
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+func compatibleConfig(baseURL, apiKey string) *config.Config {
+	return &config.Config{
+		AIProvider: "openai",
+		APIKey:     apiKey,
+		BaseURL:    baseURL,
+		Model:      "qwen2.5-coder-7b-instruct",
+		Mode:       "monarch",
+		MaxTokens:  500,
+	}
+}
+
+func TestOpenAIProvider_CustomBaseURL(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"COMMAND: uptime"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := ai.NewOpenAIProvider(compatibleConfig(server.URL+"/v1/", ""))
+	if err != nil {
+		t.Fatalf("A self-hosted server should need no API key: %v", err)
+	}
+	response, err := provider.GenerateResponse("how long has this machine been up")
+	if err != nil || response != "COMMAND: uptime" {
+		t.Fatalf("Expected the server's answer, got %q and %v", response, err)
+	}
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("Expected the chat completions of the base URL, got %s", gotPath)
+	}
+	if gotAuth != "" {
+		t.Errorf("Expected no bearer token without a key, got %q", gotAuth)
+	}
+	if gotBody["model"] != "qwen2.5-coder-7b-instruct" {
+		t.Errorf("Expected the server's model name, got %v", gotBody["model"])
+	}
+}
+
+func TestOpenAIProvider_CustomBaseURLListsModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/v1/models" || r.Header.Get("Authorization") != "Bearer gsk-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"llama-3.3-70b-versatile"}]}`))
+	}))
+	defer server.Close()
+
+	provider, _ := ai.NewOpenAIProvider(compatibleConfig(server.URL+"/openai/v1", "gsk-key"))
+	models, err := provider.ListModels()
+	if err != nil || strings.Join(models, ",") != "llama-3.3-70b-versatile" {
+		t.Errorf("Expected the server's models, got %v and %v", models, err)
+	}
+}
+
+func TestConfig_BaseURL(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     *config.Config
+		valid   bool
+		offline bool
+	}{
+		{name: "local server without a key", cfg: compatibleConfig("http://localhost:1234/v1", ""), valid: true, offline: true},
+		{name: "loopback address", cfg: compatibleConfig("http://127.0.0.1:8000/v1", ""), valid: true, offline: true},
+		{name: "IPv6 loopback", cfg: compatibleConfig("http://[::1]:11434/v1", ""), valid: true, offline: true},
+		{name: "name under localhost", cfg: compatibleConfig("http://Ollama.localhost:11434/v1", ""), valid: true, offline: true},
+		{name: "server on the local network", cfg: compatibleConfig("http://192.168.1.20:8080/v1", ""), valid: true},
+		{name: "name that only starts with localhost", cfg: compatibleConfig("http://localhost.example.com/v1", ""), valid: true},
+		{name: "hosted server", cfg: compatibleConfig("https://api.groq.com/openai/v1", "gsk-key"), valid: true},
+		{name: "base URL without scheme", cfg: compatibleConfig("localhost:1234/v1", "")},
+		{name: "openai without a base URL needs a key", cfg: compatibleConfig("", "")},
+		{name: "other providers still need a key", cfg: &config.Config{AIProvider: "gemini", BaseURL: "http://localhost:1234/v1", Mode: "monarch"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.cfg.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid=%v, got %v", tc.valid, err)
+			}
+			if got := tc.cfg.OfflineProvider(); got != tc.offline {
+				t.Errorf("Expected offline=%v, got %v", tc.offline, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// File: test/openrouter_test.go
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+func openRouterConfig(baseURL string) *config.Config {
+	return &config.Config{
+		AIProvider: "openrouter",
+		APIKey:     "sk-or-key",
+		Model:      "anthropic/claude-3.5-sonnet",
+		Mode:       "monarch",
+		MaxTokens:  500,
+		OpenRouter: config.OpenRouterConfig{BaseURL: baseURL},
+	}
+}
+
+func TestOpenRouterProvider_GenerateResponse(t *testing.T) {
+	var gotPath string
+	var gotHeader http.Header
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHeader = r.URL.Path, r.Header
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"COMMAND: df -h"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := openRouterConfig(server.URL + "/api/v1/")
+	cfg.OpenRouter.Title = "castle-ops"
+	provider, err := ai.NewOpenRouterProvider(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	response, err := provider.GenerateResponse("how much disk is left")
+	if err != nil || response != "COMMAND: df -h" {
+		t.Fatalf("Expected the model's answer, got %q and %v", response, err)
+	}
+
+	if gotPath != "/api/v1/chat/completions" {
+		t.Errorf("Expected the chat completions of the base URL, got %s", gotPath)
+	}
+	if gotHeader.Get("Authorization") != "Bearer sk-or-key" {
+		t.Errorf("Expected the key as a bearer token, got %q", gotHeader.Get("Authorization"))
+	}
+	if gotHeader.Get("HTTP-Referer") == "" || gotHeader.Get("X-Title") != "castle-ops" {
+		t.Errorf("Expected the attribution headers, got referer %q and title %q", gotHeader.Get("HTTP-Referer"), gotHeader.Get("X-Title"))
+	}
+	if gotBody["model"] != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("Expected the vendor-qualified model, got %v", gotBody["model"])
+	}
+}
+
+func TestOpenRouterProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"openai/gpt-4o-mini","name":"OpenAI: GPT-4o-mini"},{"id":"anthropic/claude-3.5-sonnet"}]}`))
+	}))
+	defer server.Close()
+
+	provider, _ := ai.NewOpenRouterProvider(openRouterConfig(server.URL))
+	models, err := provider.ListModels()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(models, ",") != "anthropic/claude-3.5-sonnet,openai/gpt-4o-mini" {
+		t.Errorf("Expected the catalog in alphabetical order, got %v", models)
+	}
+}
+
+func TestOpenRouterProvider_ReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`{"error":{"code":402,"message":"Insufficient credits"}}`))
+	}))
+	defer server.Close()
+
+	provider, _ := ai.NewOpenRouterProvider(openRouterConfig(server.URL))
+	if _, err := provider.GenerateResponse("list my files"); err == nil || !strings.Contains(err.Error(), "Insufficient credits") {
+		t.Errorf("Expected the OpenRouter error message, got %v", err)
+	}
+}
+
+func TestConfig_ValidateOpenRouter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		baseURL string
+		valid   bool
+	}{
+		{name: "default base URL", baseURL: "", valid: true},
+		{name: "self-hosted proxy", baseURL: "http://localhost:8080/v1", valid: true},
+		{name: "base URL without scheme", baseURL: "openrouter.ai/api/v1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := openRouterConfig(tc.baseURL).Validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid=%v, got %v", tc.valid, err)
+			}
+		})
+	}
+	if got := (config.OpenRouterConfig{}).URL(); got != config.DefaultOpenRouterBaseURL {
+		t.Errorf("Expected the default base URL, got %s", got)
+	}
+}
@@ -0,0 +1,101 @@
+// File: test/parse_stats_test.go
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+)
+
+func recordResponses(stats *ai.ParseStats, key string, total, failures int) {
+	for i := 0; i < total; i++ {
+		stats.RecordResponse(key, i >= failures)
+	}
+}
+
+func TestParseStats_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", ai.ParseStatsFile)
+
+	stats, err := ai.LoadParseStats(path)
+	if err != nil {
+		t.Fatalf("Missing stats file should load as empty: %v", err)
+	}
+	if len(stats.Models) != 0 {
+		t.Errorf("Expected empty stats, got %v", stats.Models)
+	}
+
+	key := ai.StatsKey("gemini", "gemini-pro")
+	recordResponses(stats, key, 10, 3)
+	stats.RecordRegeneration(key)
+	if err := stats.Save(path); err != nil {
+		t.Fatalf("Failed to save stats: %v", err)
+	}
+
+	loaded, err := ai.LoadParseStats(path)
+	if err != nil {
+		t.Fatalf("Failed to load stats: %v", err)
+	}
+	m := loaded.Models[key]
+	if m == nil || m.Responses != 10 || m.ParseFailures != 3 || m.Regenerations != 1 {
+		t.Errorf("Unexpected stats after reload: %+v", m)
+	}
+	if rate := m.FailureRate(); rate < 0.29 || rate > 0.31 {
+		t.Errorf("Expected failure rate 0.3, got %f", rate)
+	}
+}
+
+func TestParseStats_Hint(t *testing.T) {
+	flaky := ai.StatsKey("gemini", "gemini-flash")
+	steady := ai.StatsKey("openai", "gpt-4o-mini")
+
+	testCases := []struct {
+		name     string
+		setup    func(*ai.ParseStats)
+		contains []string
+	}{
+		{
+			name:  "no data",
+			setup: func(*ai.ParseStats) {},
+		},
+		{
+			name:  "too few samples",
+			setup: func(s *ai.ParseStats) { recordResponses(s, flaky, 5, 5) },
+		},
+		{
+			name:  "reliable model",
+			setup: func(s *ai.ParseStats) { recordResponses(s, flaky, 100, 1) },
+		},
+		{
+			name:     "unreliable without alternative",
+			setup:    func(s *ai.ParseStats) { recordResponses(s, flaky, 50, 6) },
+			contains: []string{"gemini/gemini-flash fails parsing 12% of the time"},
+		},
+		{
+			name: "unreliable with alternative",
+			setup: func(s *ai.ParseStats) {
+				recordResponses(s, flaky, 50, 6)
+				recordResponses(s, steady, 50, 0)
+			},
+			contains: []string{"12%", "consider openai/gpt-4o-mini"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stats, _ := ai.LoadParseStats(filepath.Join(t.TempDir(), ai.ParseStatsFile))
+			tc.setup(stats)
+
+			hint := stats.Hint(flaky)
+			if len(tc.contains) == 0 && hint != "" {
+				t.Errorf("Expected no hint, got %q", hint)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(hint, want) {
+					t.Errorf("Expected hint to contain %q, got %q", want, hint)
+				}
+			}
+		})
+	}
+}
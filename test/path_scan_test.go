@@ -0,0 +1,90 @@
+// File: test/path_scan_test.go
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// commandFiles offers every file that is not a directory as a command, as on Unix
+func commandFiles(entry os.DirEntry) []string {
+	if entry.IsDir() {
+		return nil
+	}
+	return []string{entry.Name()}
+}
+
+func makeCommands(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUniquePathDirectories(t *testing.T) {
+	root := t.TempDir()
+	bin, tools := filepath.Join(root, "bin"), filepath.Join(root, "tools")
+
+	unique := system.UniquePathDirectories([]string{bin, "", tools, bin + string(filepath.Separator), filepath.Join(root, "x", "..", "bin"), "  "})
+	if strings.Join(unique, ",") != bin+","+tools {
+		t.Errorf("Expected each directory once, in PATH order, got %v", unique)
+	}
+}
+
+func TestPrioritizePathDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	localBin := filepath.Join(home, ".local", "bin")
+
+	ordered := system.PrioritizePathDirectories([]string{"/mnt/c/Windows/System32", "/opt/tools/bin", "/usr/bin", "/media/usb/bin", localBin, "/srv/app/bin"})
+	expected := []string{"/usr/bin", localBin, "/opt/tools/bin", "/srv/app/bin", "/mnt/c/Windows/System32", "/media/usb/bin"}
+	if strings.Join(ordered, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, ordered)
+	}
+}
+
+func TestScanPathCommands(t *testing.T) {
+	root := t.TempDir()
+	first, second := filepath.Join(root, "first"), filepath.Join(root, "second")
+	makeCommands(t, first, "git", "make")
+	makeCommands(t, second, "make", "rg")
+	os.Mkdir(filepath.Join(second, "lib"), 0755)
+
+	commands, truncated := system.ScanPathCommands([]string{first, filepath.Join(root, "missing"), second, first}, commandFiles)
+	slices.Sort(commands)
+	if strings.Join(commands, ",") != "git,make,rg" || truncated {
+		t.Errorf("Expected each command once and a complete scan, got %v (truncated: %v)", commands, truncated)
+	}
+}
+
+func TestScanPathCommands_CutsOffRelevantFirst(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	huge := filepath.Join(t.TempDir(), "System32")
+	names := make([]string, system.MaxPathCommands+50)
+	for i := range names {
+		names[i] = fmt.Sprintf("tool%05d.exe", i)
+	}
+	makeCommands(t, huge, names...)
+	localBin := filepath.Join(home, ".local", "bin")
+	makeCommands(t, localBin, "mytool")
+
+	commands, truncated := system.ScanPathCommands([]string{huge, localBin}, commandFiles)
+	if !truncated || len(commands) != system.MaxPathCommands {
+		t.Fatalf("Expected the scan to stop at %d commands, got %d (truncated: %v)", system.MaxPathCommands, len(commands), truncated)
+	}
+	if commands[0] != "mytool" {
+		t.Errorf("Expected the commands of ~/.local/bin first, got %q", commands[0])
+	}
+}
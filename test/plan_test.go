@@ -0,0 +1,122 @@
+// File: test/plan_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+)
+
+var lampPlan = []string{
+	"install Apache, MariaDB, and PHP",
+	"enable and start the apache2 and mariadb services",
+	"copy ./app to /var/www/html",
+}
+
+func planStatuses(results []*cli.StepResult) string {
+	var statuses []string
+	for _, result := range results {
+		statuses = append(statuses, string(result.Status))
+	}
+	return strings.Join(statuses, ",")
+}
+
+func TestPlanRun_RunsEveryStep(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.PlanSteps = lampPlan
+	f.prompter.Choices = []int{0}
+
+	results, err := (&cli.PlanRun{Deps: f.deps()}).Run(newQuest("set up a LAMP stack and deploy my app", "monarch"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := planStatuses(results); got != "succeeded,succeeded,succeeded" {
+		t.Errorf("Expected every step to succeed, got %s", got)
+	}
+	if f.confirmer.CallCount != 3 || len(f.executor.ExecutedCommands) != 3 {
+		t.Errorf("Expected each step to be confirmed and run, got %d confirmations and %d runs", f.confirmer.CallCount, len(f.executor.ExecutedCommands))
+	}
+	if !strings.Contains(f.aiClient.LastPlanIntent, "set up a LAMP stack") {
+		t.Errorf("Expected the whole intent to be planned, got %q", f.aiClient.LastPlanIntent)
+	}
+	for _, expected := range []string{"copy ./app to /var/www/html", "step 3 of 3", "1. install Apache, MariaDB, and PHP (done before this step)", "(this step)"} {
+		if !strings.Contains(f.aiClient.LastIntent, expected) {
+			t.Errorf("Expected the last step's prompt to contain %q, got:\n%s", expected, f.aiClient.LastIntent)
+		}
+	}
+}
+
+func TestPlanRun_Abandoned(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.PlanSteps = lampPlan
+	f.prompter.Choices = []int{1}
+
+	results, err := (&cli.PlanRun{Deps: f.deps()}).Run(newQuest("set up a LAMP stack", "monarch"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.GenerateCallCount != 0 || len(f.executor.ExecutedCommands) != 0 {
+		t.Error("Nothing should be generated or run when the plan is abandoned")
+	}
+	if got := planStatuses(results); got != "not started,not started,not started" {
+		t.Errorf("Expected no step to start, got %s", got)
+	}
+}
+
+func TestPlanRun_StopsAfterDeclinedStep(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.PlanSteps = lampPlan
+	f.confirmer.DeclineAfter = 1
+	f.prompter.Choices = []int{0, 1} // follow the plan, then stop after the declined step
+
+	results, err := (&cli.PlanRun{Deps: f.deps()}).Run(newQuest("set up a LAMP stack", "monarch"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := planStatuses(results); got != "succeeded,declined,not started" {
+		t.Errorf("Unexpected statuses %s", got)
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected only the first step to run, got %d", len(f.executor.ExecutedCommands))
+	}
+}
+
+func TestPlanRun_ContinuesAfterDeclinedStep(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.PlanSteps = lampPlan[:2]
+	f.confirmer.Approve = false
+	f.prompter.Choices = []int{0, 0}
+
+	results, _ := (&cli.PlanRun{Deps: f.deps()}).Run(newQuest("set up a LAMP stack", "monarch"))
+	if got := planStatuses(results); got != "declined,declined" {
+		t.Errorf("Expected both steps to be offered, got %s", got)
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	steps, err := ai.ParsePlan("PLAN:\n1. Install Apache\n2) Start Apache\n**3. Open port 80**\n\nGood luck!")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(steps, "|") != "Install Apache|Start Apache|Open port 80" {
+		t.Errorf("Unexpected steps %q", steps)
+	}
+
+	if _, err := ai.ParsePlan("FAILURE: Deploying to production is unsafe."); err == nil || !strings.Contains(err.Error(), "unsafe") {
+		t.Errorf("Expected the failure reason, got %v", err)
+	}
+	if _, err := ai.ParsePlan("Sure, here is what I would do."); err == nil {
+		t.Error("Expected an error for an answer without numbered steps")
+	}
+
+	var long []string
+	for i := 0; i <= ai.MaxPlanSteps; i++ {
+		long = append(long, "1. step")
+	}
+	if _, err := ai.ParsePlan(strings.Join(long, "\n")); err == nil {
+		t.Error("Expected an error for a plan with too many steps")
+	}
+}
@@ -0,0 +1,97 @@
+// File: test/postmortem_test.go
+package test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestNeedsPostmortem(t *testing.T) {
+	failed := cli.NewTranscript(newFailedQuest(), nil, nil, "dev")
+	succeeded := &cli.Transcript{Intent: "clear the cache", Content: "rm -rf ~/.cache/app", Approved: true, Executed: true}
+	declined := &cli.Transcript{Intent: "clear the cache", Content: "rm -rf ~/.cache/app"}
+
+	testCases := []struct {
+		name       string
+		transcript *cli.Transcript
+		risk       system.RiskLevel
+		expected   bool
+	}{
+		{name: "failed quest", transcript: failed, risk: system.RiskModifies, expected: true},
+		{name: "destructive quest that succeeded", transcript: succeeded, risk: system.RiskDestructive, expected: true},
+		{name: "harmless quest that succeeded", transcript: succeeded, risk: system.RiskModifies, expected: false},
+		{name: "destructive quest that never ran", transcript: declined, risk: system.RiskDestructive, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cli.NeedsPostmortem(tc.transcript, tc.risk); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIncidentNote_Markdown(t *testing.T) {
+	transcript := cli.NewTranscript(newFailedQuest(), nil, nil, "1.2.3")
+	transcript.Time = time.Date(2025, 10, 17, 9, 30, 0, 0, time.UTC)
+	entry := &history.Entry{Runs: 3, Failures: 2, FirstRun: time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)}
+
+	note := cli.NewIncidentNote(transcript, entry, system.RiskModifies, "login failed", "### What happened\nThe deploy script could not log in.")
+	markdown := note.Markdown()
+
+	for _, expected := range []string{
+		"# Incident note: deploy with token=",
+		"| Outcome | failed |",
+		"| Exit code | 2 |",
+		"| Runs of this command | 3 (2 failed) |",
+		"```\n./deploy.sh\n```",
+		"## Diagnosis",
+		"The deploy script could not log in.",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Note should contain %q:\n%s", expected, markdown)
+		}
+	}
+	for _, leaked := range []string{"abc123", "configured-key-1234", "/home/arthur"} {
+		if strings.Contains(markdown, leaked) {
+			t.Errorf("Note leaks %q:\n%s", leaked, markdown)
+		}
+	}
+
+	withoutDiagnosis := cli.NewIncidentNote(transcript, nil, system.RiskModifies, "", "").Markdown()
+	if strings.Contains(withoutDiagnosis, "## Diagnosis") || strings.Contains(withoutDiagnosis, "Runs of this command") {
+		t.Errorf("Note without a diagnosis or history should leave those out:\n%s", withoutDiagnosis)
+	}
+}
+
+func TestIncidentNote_Save(t *testing.T) {
+	quest := newFailedQuest()
+	quest.Intent = "Clean up old Docker images!"
+	transcript := cli.NewTranscript(quest, nil, errors.New("pipeline broke"), "dev")
+	transcript.Time = time.Date(2025, 10, 17, 9, 30, 5, 0, time.UTC)
+
+	note := cli.NewIncidentNote(transcript, nil, system.RiskDestructive, "", "")
+	if name := note.FileName(); name != "2025-10-17-093005-clean-up-old-docker-images.md" {
+		t.Errorf("Unexpected file name %q", name)
+	}
+
+	path, err := note.Save(t.TempDir() + "/notes")
+	if err != nil {
+		t.Fatalf("Failed to save the note: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read the note: %v", err)
+	}
+	if string(data) != note.Markdown() {
+		t.Errorf("Saved note differs from its markdown")
+	}
+}
@@ -0,0 +1,113 @@
+// File: test/privileges_test.go
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// lookPathOf finds only the given tools
+func lookPathOf(tools ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, tool := range tools {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestDetectPrivileges(t *testing.T) {
+	testCases := []struct {
+		name       string
+		euid       int
+		groups     []string
+		tools      []string
+		noPassword bool
+		expected   string
+	}{
+		{name: "root", euid: 0, expected: "running as root"},
+		{name: "sudo group", euid: 1000, groups: []string{"users", "sudo"}, tools: []string{"sudo"}, expected: "available through sudo"},
+		{name: "wheel with doas", euid: 1000, groups: []string{"wheel"}, tools: []string{"doas"}, expected: "available through doas"},
+		{name: "passwordless sudo rule", euid: 1000, groups: []string{"users"}, tools: []string{"sudo"}, noPassword: true, expected: "available through sudo"},
+		{name: "not in an admin group", euid: 1000, groups: []string{"users"}, tools: []string{"sudo"}, expected: "none (no sudo); install into the home directory only"},
+		{name: "admin group without sudo", euid: 1000, groups: []string{"wheel"}, expected: "none (no sudo); install into the home directory only"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			privileges := system.DetectPrivileges(tc.euid, tc.groups, lookPathOf(tc.tools...), func() bool { return tc.noPassword })
+			if got := privileges.String(); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if got := (system.Privileges{}).String(); got != "not checked" {
+		t.Errorf("Expected an unchecked scan to say so, got %q", got)
+	}
+}
+
+func TestPrivileges_RootCommand(t *testing.T) {
+	rootless := system.Privileges{Checked: true}
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "sudo install", content: "sudo apt install htop", expected: "sudo apt install htop"},
+		{name: "plain system install", content: "apt-get install -y htop", expected: "apt-get install -y htop"},
+		{name: "snap install", content: "snap install code --classic", expected: "snap install code --classic"},
+		{name: "copy into /usr/local/bin", content: "curl -LO https://example.com/tool && install -m 755 tool /usr/local/bin/tool", expected: "install -m 755 tool /usr/local/bin/tool"},
+		{name: "redirect into /etc", content: "echo 'alias ll=ls' >> /etc/bash.bashrc", expected: "echo 'alias ll=ls' >> /etc/bash.bashrc"},
+		{name: "script with su", content: "# become root\nsu -c 'dnf install htop'", expected: "su -c 'dnf install htop'"},
+		{name: "pip user install", content: "pip install --user httpie"},
+		{name: "cargo install", content: "cargo install ripgrep"},
+		{name: "download into the home directory", content: "mkdir -p ~/.local/bin && curl -Lo ~/.local/bin/jq https://example.com/jq && chmod +x ~/.local/bin/jq"},
+		{name: "reading from a system directory", content: "cp /etc/hosts ~/hosts.bak"},
+		{name: "npm with a user prefix", content: "npm config set prefix ~/.local && npm install -g typescript"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rootless.RootCommand(tc.content); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	for _, privileges := range []system.Privileges{{}, {Checked: true, Root: true}, {Checked: true, Elevator: "sudo"}} {
+		if got := privileges.RootCommand("sudo apt install htop"); got != "" {
+			t.Errorf("Expected %q to allow sudo, got %q", privileges.String(), got)
+		}
+	}
+}
+
+func TestPipeline_RefusesRootCommandWithoutSudo(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "sudo apt install htop"}
+
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", Privileges: system.Privileges{Checked: true}}
+	if err := f.pipeline().Run(newQuest("install htop", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 || f.confirmer.CallCount != 0 {
+		t.Errorf("Expected a command needing root to be refused before confirmation, got %v", f.executor.ExecutedCommands)
+	}
+
+	// A user-space install goes ahead
+	f = newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "pip install --user httpie"}
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", Privileges: system.Privileges{Checked: true}}
+	if err := f.pipeline().Run(newQuest("install httpie", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected the user-space install to run, got %v", f.executor.ExecutedCommands)
+	}
+}
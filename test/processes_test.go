@@ -0,0 +1,132 @@
+// File: test/processes_test.go
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+var runningProcesses = []system.ProcessInfo{
+	{PID: 101, Name: "systemd", CPU: 0.1, MemoryKB: 12_000},
+	{PID: 4242, Name: "node", CPU: 99.5, MemoryKB: 300_000},
+	{PID: 5150, Name: "chrome", CPU: 12.0, MemoryKB: 900_000},
+	{PID: 5151, Name: "chrome", CPU: 3.0, MemoryKB: 1_500_000},
+	{PID: 6000, Name: "postgres", CPU: 1.0, MemoryKB: 200_000},
+}
+
+func TestParseProcessList(t *testing.T) {
+	output := "    1  0.0 10376 systemd\n" +
+		"   23  0.0     0 kworker/0:1\n" +
+		" 4242 99.5 300000 node\n" +
+		" 5150 12.0 901120 /Applications/Google Chrome.app/Contents/MacOS/Google Chrome\n" +
+		"garbage line\n"
+
+	processes := system.ParseProcessList(output)
+	if len(processes) != 4 {
+		t.Fatalf("Expected 4 processes, got %+v", processes)
+	}
+	if processes[1].Name != "kworker/0:1" {
+		t.Errorf("Expected kernel thread names to keep their slash, got %q", processes[1].Name)
+	}
+	chrome := processes[3]
+	if chrome.Name != "Google Chrome" || chrome.PID != 5150 || chrome.CPU != 12.0 || chrome.MemoryKB != 901120 {
+		t.Errorf("Unexpected process %+v", chrome)
+	}
+	if got := chrome.String(); got != "Google Chrome (pid 5150, 12.0% CPU, 880 MB)" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
+
+func TestParseTasklist(t *testing.T) {
+	output := "\"chrome.exe\",\"5150\",\"Console\",\"1\",\"150,236 K\"\r\n" +
+		"\"Code.exe\",\"7000\",\"Console\",\"1\",\"98.304 K\"\r\n"
+
+	processes := system.ParseTasklist(output)
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %+v", processes)
+	}
+	if processes[0].MemoryKB != 150236 || processes[1].MemoryKB != 98304 {
+		t.Errorf("Expected memory with any thousands separator, got %+v", processes)
+	}
+	if got := processes[0].String(); got != "chrome.exe (pid 5150, 147 MB)" {
+		t.Errorf("Expected no CPU figure from tasklist, got %q", got)
+	}
+}
+
+func TestIsProcessIntent(t *testing.T) {
+	for _, intent := range []string{"why is chrome eating my RAM", "kill the stuck node process", "what is using all the CPU"} {
+		if !system.IsProcessIntent(intent) {
+			t.Errorf("Expected %q to be about running processes", intent)
+		}
+	}
+	for _, intent := range []string{"install docker", "list my files", "compress the logs folder"} {
+		if system.IsProcessIntent(intent) {
+			t.Errorf("Expected %q not to be about running processes", intent)
+		}
+	}
+}
+
+func TestNewProcessReport(t *testing.T) {
+	report := system.NewProcessReport(runningProcesses, "why is Chrome eating my RAM")
+	if !report.Matched || len(report.Processes) != 2 || report.Processes[0].PID != 5151 {
+		t.Errorf("Expected both chrome processes, heaviest first, got %+v", report)
+	}
+
+	report = system.NewProcessReport(runningProcesses, "kill the stuck node process")
+	if !report.Matched || len(report.Processes) != 1 || report.Processes[0].PID != 4242 {
+		t.Errorf("Expected only the node process, got %+v", report)
+	}
+	if !strings.HasPrefix(report.String(), "matching the intent, by memory: node (pid 4242") {
+		t.Errorf("Unexpected description %q", report.String())
+	}
+
+	report = system.NewProcessReport(runningProcesses, "what is using all the CPU")
+	if report.Matched || report.By != "CPU" || report.Processes[0].PID != 4242 {
+		t.Errorf("Expected the heaviest processes by CPU, got %+v", report)
+	}
+	if !strings.HasPrefix(report.String(), "none match the intent by name; the top 5 by CPU") {
+		t.Errorf("Unexpected description %q", report.String())
+	}
+}
+
+func TestPipeline_ListsProcessesForProcessIntents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		intent   string
+		listErr  error
+		expected bool
+	}{
+		{"process intent", "kill the stuck node process", nil, true},
+		{"other intent", "install docker", nil, false},
+		{"listing fails", "kill the stuck node process", errors.New("ps not found"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPipelineFixture()
+			deps := f.deps()
+			listed := false
+			deps.ListProcesses = func() ([]system.ProcessInfo, error) {
+				listed = true
+				return runningProcesses, tc.listErr
+			}
+
+			quest := newQuest(tc.intent, "monarch")
+			f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash"}
+			if err := cli.NewPipeline(deps).Run(quest); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := quest.SysInfo.Processes != nil; got != tc.expected {
+				t.Errorf("Expected processes to be gathered: %v, got %v", tc.expected, got)
+			}
+			if tc.name == "other intent" && listed {
+				t.Error("Processes should not be listed for an unrelated intent")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// File: test/project_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestDetectProjectTypes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{name: "cargo", files: []string{"Cargo.toml"}, expected: []string{"cargo (Cargo.toml; tests: cargo test)"}},
+		{name: "gradle wrapper", files: []string{"build.gradle.kts", "gradlew"}, expected: []string{"gradle (build.gradle.kts; tests: ./gradlew test)"}},
+		{name: "bazel with go", files: []string{"go.mod", "MODULE.bazel", "BUILD.bazel"}, expected: []string{"bazel (MODULE.bazel; tests: bazel test //...)", "go (go.mod; tests: go test ./...)"}},
+		{name: "pnpm", files: []string{"package.json", "pnpm-lock.yaml"}, expected: []string{"node (package.json; tests: pnpm test)"}},
+		{name: "dotnet solution", files: []string{"App.sln"}, expected: []string{"dotnet (App.sln; tests: dotnet test)"}},
+		{name: "plain directory", files: []string{"notes.txt"}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var found []string
+			for _, projectType := range system.DetectProjectTypes(dir) {
+				found = append(found, projectType.String())
+			}
+			if strings.Join(found, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("Expected %v, got %v", tc.expected, found)
+			}
+		})
+	}
+}
+
+func TestDetectProjectTypes_GlobCharactersInDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "[weird]dir")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if types := system.DetectProjectTypes(dir); len(types) != 1 || types[0].Tool != "cargo" {
+		t.Errorf("Expected cargo in a directory with glob characters, got %v", types)
+	}
+}
@@ -0,0 +1,86 @@
+// File: test/question_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestIsQuestionIntent(t *testing.T) {
+	testCases := []struct {
+		intent   string
+		expected bool
+	}{
+		{"what does chmod 755 mean?", true},
+		{"What's the difference between apt and snap", true},
+		{"why does git say detached HEAD", true},
+		{"explain what umask 022 does", true},
+		{"can you explain the sticky bit?", true},
+		{"is it safe to delete ~/.cache", true},
+		{"how does ssh-agent work?", true},
+		{"how do I find large files", false},
+		{"how to extract a tar.xz", false},
+		{"can you clean up my downloads", false},
+		{"list the files in my home directory", false},
+		{"show disk usage", false},
+		{"whatever is in /tmp, archive it", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			if got := system.IsQuestionIntent(tc.intent); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPipeline_AnswersQuestions(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.AnswerResponse = &ai.AIResponse{Type: ai.ResponseTypeAnswer, Content: "It lets the owner write, and everyone read and run the file."}
+
+	if err := f.pipeline().Run(newQuest("what does chmod 755 mean?", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.AnswerCallCount != 1 || f.aiClient.LastQuestion != "what does chmod 755 mean?" {
+		t.Errorf("Expected the question to be asked once, got %d calls with %q", f.aiClient.AnswerCallCount, f.aiClient.LastQuestion)
+	}
+	if f.aiClient.GenerateCallCount != 0 || f.confirmer.CallCount != 0 || len(f.executor.ExecutedCommands) != 0 {
+		t.Errorf("Expected an answer instead of a command, got %d generations and %v", f.aiClient.GenerateCallCount, f.executor.ExecutedCommands)
+	}
+}
+
+func TestPipeline_QuestionAboutTheMachineGeneratesCommand(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "ss -ltnp 'sport = :8080'"}
+
+	if err := f.pipeline().Run(newQuest("what is using port 8080?", "monarch")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.AnswerCallCount != 1 {
+		t.Errorf("Expected the oracle to be asked for an answer first, got %d calls", f.aiClient.AnswerCallCount)
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected a command to find out, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestPipeline_CommandsOnlyNeverAnswers(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.AnswerResponse = &ai.AIResponse{Type: ai.ResponseTypeAnswer, Content: "an answer"}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "man chmod"}
+
+	quest := newQuest("what does chmod 755 mean?", "monarch")
+	quest.Config.Execution.CommandsOnly = true
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.AnswerCallCount != 0 {
+		t.Errorf("Expected no answer in commands-only mode, got %d calls", f.aiClient.AnswerCallCount)
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected the command to run, got %v", f.executor.ExecutedCommands)
+	}
+}
@@ -0,0 +1,92 @@
+// File: test/rate_limiter_test.go
+package test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+// fakeClock advances only when the limiter sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeLimiter(limit config.RateLimit, path string, clock *fakeClock) *ai.RateLimiter {
+	limiter := ai.NewRateLimiter(limit, path)
+	limiter.Clock = func() time.Time { return clock.now }
+	limiter.Sleep = func(d time.Duration) {
+		clock.sleeps = append(clock.sleeps, d)
+		clock.now = clock.now.Add(d)
+	}
+	return limiter
+}
+
+func TestRateLimiter_RequestsPerMinute(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := newFakeLimiter(config.RateLimit{RequestsPerMinute: 2}, "", clock)
+
+	limiter.Wait(10)
+	clock.now = clock.now.Add(10 * time.Second)
+	limiter.Wait(10)
+	if len(clock.sleeps) != 0 {
+		t.Fatalf("Requests within the limit should not wait, got %v", clock.sleeps)
+	}
+
+	var told []time.Duration
+	limiter.OnWait = func(wait time.Duration) { told = append(told, wait) }
+	limiter.Wait(10)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 50*time.Second {
+		t.Errorf("Expected a single 50s wait for the oldest request to expire, got %v", clock.sleeps)
+	}
+	if len(told) != 1 || told[0] != 50*time.Second {
+		t.Errorf("Expected the wait to be reported before sleeping, got %v", told)
+	}
+}
+
+func TestRateLimiter_TokensPerMinute(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := newFakeLimiter(config.RateLimit{TokensPerMinute: 1000}, "", clock)
+
+	limiter.Wait(600)
+	limiter.Wait(600)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != time.Minute {
+		t.Errorf("Expected to wait a full minute for tokens, got %v", clock.sleeps)
+	}
+
+	// A request larger than the whole limit still goes through once the window is clear
+	clock.now = clock.now.Add(2 * time.Minute)
+	clock.sleeps = nil
+	limiter.Wait(5000)
+	if len(clock.sleeps) != 0 {
+		t.Errorf("Oversized request should not wait on an empty window, got %v", clock.sleeps)
+	}
+}
+
+func TestRateLimiter_SharedAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit-gemini.yaml")
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limit := config.RateLimit{RequestsPerMinute: 1}
+
+	newFakeLimiter(limit, path, clock).Wait(10)
+	newFakeLimiter(limit, path, clock).Wait(10)
+
+	if len(clock.sleeps) != 1 {
+		t.Errorf("Second run should wait for the first run's request, got %v", clock.sleeps)
+	}
+}
+
+func TestConfig_ValidateRateLimits(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:     "test-key",
+		Mode:       "monarch",
+		RateLimits: map[string]config.RateLimit{"openai": {RequestsPerMinute: -1}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a negative rate limit")
+	}
+}
@@ -0,0 +1,64 @@
+// File: test/realm_test.go
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestCountSummary(t *testing.T) {
+	if got := cli.CountSummary(100); got != "100" {
+		t.Errorf("Expected a count within the limit on its own, got %q", got)
+	}
+	if got := cli.CountSummary(250); got != "250 (first 100 shared)" {
+		t.Errorf("Expected the shared part of a longer list, got %q", got)
+	}
+}
+
+func TestListLines_KeepsAnalyzerOrder(t *testing.T) {
+	items := make([]string, 0, 102)
+	for i := 102; i > 0; i-- {
+		items = append(items, fmt.Sprintf("pkg-%03d", i))
+	}
+
+	lines := cli.ListLines(items)
+	if len(lines) != len(items)+2 || lines[0] != "" || lines[len(lines)-1] != "" {
+		t.Fatalf("Expected one line per item between blank lines, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], "• pkg-102") || !strings.HasPrefix(lines[102], "• pkg-001") {
+		t.Errorf("Expected the analyzer's order, got %q first and %q last", lines[1], lines[102])
+	}
+	for i, line := range lines[1 : len(lines)-1] {
+		if notShared := strings.Contains(line, "(not shared)"); notShared != (i >= 100) {
+			t.Errorf("Line %d: expected only the items past the first 100 to be marked, got %q", i+1, line)
+		}
+	}
+}
+
+func TestListLines_Empty(t *testing.T) {
+	lines := cli.ListLines(nil)
+	if len(lines) != 3 || !strings.Contains(lines[1], "none") {
+		t.Errorf("Expected a single 'none' line, got %q", lines)
+	}
+}
+
+func TestRealmAnalyzer_FollowsSystemScan(t *testing.T) {
+	scan := false
+	cfg := &config.Config{Analysis: config.AnalysisConfig{SystemScan: &scan}}
+	info, err := cli.RealmAnalyzer(cfg).AnalyzeSystem()
+	if err != nil || !info.Quick {
+		t.Errorf("Expected the quick analysis quests use with the scan off, got %+v (%v)", info, err)
+	}
+
+	if _, quick := cli.RealmAnalyzer(nil).(*system.QuickAnalyzer); quick {
+		t.Error("Expected the full analysis without a configuration")
+	}
+	if _, quick := cli.RealmAnalyzer(&config.Config{}).(*system.QuickAnalyzer); quick {
+		t.Error("Expected the full analysis when the scan is left on")
+	}
+}
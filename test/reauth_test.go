@@ -0,0 +1,136 @@
+// File: test/reauth_test.go
+package test
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// reauthFixture runs quests with re-authentication configured, recording each time it is asked
+type reauthFixture struct {
+	*pipelineFixture
+	asked   []string
+	failing bool
+}
+
+func newReauthFixture(content, risk string) *reauthFixture {
+	f := &reauthFixture{pipelineFixture: newPipelineFixture()}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: content, Risk: risk}
+	return f
+}
+
+func (f *reauthFixture) run(t *testing.T, reauthenticate bool) *cli.Quest {
+	t.Helper()
+	deps := f.deps()
+	deps.Reauthenticate = func(reason string) error {
+		f.asked = append(f.asked, reason)
+		if f.failing {
+			return errors.New("sudo could not confirm who is at the terminal")
+		}
+		return nil
+	}
+	quest := newQuest("clear the build folder", "monarch")
+	quest.Config.Execution.Reauthenticate = reauthenticate
+	if err := cli.NewPipeline(deps).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return quest
+}
+
+func TestReauth_DestructiveQuestAsksFirst(t *testing.T) {
+	f := newReauthFixture("rm -rf ./build", "")
+	f.run(t, true)
+
+	if len(f.asked) != 1 {
+		t.Fatalf("Expected the system to be asked once, got %d", len(f.asked))
+	}
+	if len(f.executor.ExecutedCommands) != 1 {
+		t.Errorf("Expected the quest to run once confirmed, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestReauth_FailureStopsTheQuest(t *testing.T) {
+	f := newReauthFixture("rm -rf ./build", "")
+	f.failing = true
+	quest := f.run(t, true)
+
+	if len(f.executor.ExecutedCommands) != 0 || quest.Executed {
+		t.Errorf("Expected nothing to run without confirmation, got %v", f.executor.ExecutedCommands)
+	}
+	if f.confirmer.CallCount != 1 {
+		t.Errorf("Expected re-authentication to follow the approval, got %d confirmations", f.confirmer.CallCount)
+	}
+}
+
+func TestReauth_OnlyWhenNeeded(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		risk           string
+		reauthenticate bool
+		declined       bool
+		asked          bool
+	}{
+		{name: "read-only quest", content: "ls -la", reauthenticate: true},
+		{name: "modifying quest", content: "mkdir build", reauthenticate: true},
+		{name: "not configured", content: "rm -rf ./build"},
+		{name: "declined", content: "rm -rf ./build", reauthenticate: true, declined: true},
+		{name: "oracle rates it destructive", content: "./cleanup.sh", risk: "destructive", reauthenticate: true, asked: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newReauthFixture(tc.content, tc.risk)
+			f.confirmer.Approve = !tc.declined
+			f.run(t, tc.reauthenticate)
+
+			if asked := len(f.asked) > 0; asked != tc.asked {
+				t.Errorf("Expected asking to be %v, got %d requests", tc.asked, len(f.asked))
+			}
+		})
+	}
+}
+
+func TestReauthenticate_RefusesRoot(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("only root can show that sudo cannot confirm who is at the terminal")
+	}
+	if err := system.Reauthenticate("confirm the quest"); !errors.Is(err, system.ErrNotAuthenticated) {
+		t.Errorf("Expected root to be refused as unconfirmable, got %v", err)
+	}
+}
+
+func TestReauth_WorkspaceQuestAsksOnceForTheRiskiestProject(t *testing.T) {
+	for _, failing := range []bool{false, true} {
+		f := newWorkspaceFixture(t)
+		f.aiClient.NextResponses = []*ai.AIResponse{{Type: ai.ResponseTypeCommand, Content: "ls ./build"}}
+		f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf ./build"}
+		var asked []string
+		f.reauthenticate = func(reason string) error {
+			asked = append(asked, reason)
+			if failing {
+				return errors.New("sudo could not confirm who is at the terminal")
+			}
+			return nil
+		}
+		quest := newQuest("clear the build folder", "monarch")
+		quest.Config.Execution.Reauthenticate = true
+
+		results := f.runQuest(t, quest, false)
+		if len(asked) != 1 {
+			t.Errorf("failing=%v: expected the system to be asked once for the workspace, got %d", failing, len(asked))
+		}
+		if failing && (len(f.executors) != 0 || results[0].Status != cli.ProjectDeclined || results[1].Status != cli.ProjectDeclined) {
+			t.Errorf("Expected nothing to run without re-authentication, got %d executors and %s, %s", len(f.executors), results[0].Status, results[1].Status)
+		}
+		if !failing && len(f.executors) != 2 {
+			t.Errorf("Expected both projects to run once confirmed, got %d executors", len(f.executors))
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// File: test/recipes_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestMatchRecipe(t *testing.T) {
+	bash := &system.Info{Shell: "bash", AvailableCommands: []string{"tar", "gzip", "ss"}}
+	testCases := []struct {
+		intent   string
+		sysInfo  *system.Info
+		expected string
+	}{
+		{"find the largest directories here", bash, "largest-dirs"},
+		{"show me the 20 biggest files.", bash, "largest-files"},
+		{"tar the photos folder with progress", bash, "tar-folder"},
+		{"rotate the logs older than 14 days", bash, "rotate-logs"},
+		{"which ports are open", bash, "listening-ports"},
+		// Asking for more than the recipe does
+		{"find the largest directories and delete them", bash, ""},
+		{"open the largest file", bash, ""},
+		// Fitting two recipes equally well
+		{"compress the logs folder", bash, ""},
+		// No variant for the shell or the available commands
+		{"find the largest directories here", &system.Info{Shell: "fish"}, ""},
+		{"which ports are open", &system.Info{Shell: "bash"}, ""},
+		{"install docker", bash, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.intent, func(t *testing.T) {
+			got := ""
+			if match := system.MatchRecipe(tc.intent, tc.sysInfo); match != nil {
+				got = match.Recipe.Name
+			}
+			if got != tc.expected {
+				t.Errorf("Expected recipe %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindRecipeParam(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "photos"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	folder := system.RecipeParam{Name: "folder", Kind: system.RecipeParamDir}
+
+	if got := system.FindRecipeParam(folder, "tar the photos folder", dir, home); got != "photos" {
+		t.Errorf("Expected the relative folder, got %q", got)
+	}
+	if got := system.FindRecipeParam(folder, "largest dirs in my home", dir, home); got != home {
+		t.Errorf("Expected the home directory, got %q", got)
+	}
+	if got := system.FindRecipeParam(folder, "tar the videos folder", dir, home); got != "" {
+		t.Errorf("Expected no folder, got %q", got)
+	}
+
+	count := system.Recipes[0].Params[1]
+	if got := system.FindRecipeParam(count, "top 25 largest dirs", dir, home); got != "25" {
+		t.Errorf("Expected 25, got %q", got)
+	}
+	if got := system.FindRecipeParam(count, "the 5 biggest dirs", dir, home); got != "5" {
+		t.Errorf("Expected 5, got %q", got)
+	}
+}
+
+func TestBuildRecipeCommand(t *testing.T) {
+	values := map[string]string{"folder": "my photos"}
+	values["archive"] = system.ExpandRecipeDefault("{{folder}}.tar.gz", values)
+
+	posix := &system.RecipeVariant{Family: system.ShellFamilyPOSIX, Command: "tar -czvf {{archive}} {{folder}}"}
+	if got := system.BuildRecipeCommand(posix, values); got != "tar -czvf 'my photos.tar.gz' 'my photos'" {
+		t.Errorf("Unexpected command %q", got)
+	}
+
+	powershell := &system.RecipeVariant{Family: system.ShellFamilyPowerShell, Command: "Get-ChildItem -LiteralPath {{dir}} | Select-Object -First {{count}}"}
+	if got := system.BuildRecipeCommand(powershell, map[string]string{"dir": "C:\\it's", "count": "10"}); got != "Get-ChildItem -LiteralPath 'C:\\it''s' | Select-Object -First 10" {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
+
+func TestPipeline_PrefersRecipes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		intent    string
+		answers   []string
+		expected  string // the executed command; empty when the oracle answers
+		questions int
+		dryRun    bool
+	}{
+		{"recipe with defaults", "find the largest directories here", nil, "du -xhd 1 . 2>/dev/null | sort -rh | head -n 10", 0, false},
+		{"asks for a missing folder", "tar a folder", []string{"src"}, "tar -czvf src.tar.gz src", 1, false},
+		{"oracle when the folder is not given", "tar a folder", nil, "", 1, false},
+		{"oracle for other intents", "install docker", nil, "", 0, false},
+		{"oracle without asking in a dry run", "tar a folder", []string{"src"}, "", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPipelineFixture()
+			f.prompter.Answers = tc.answers
+			f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: t.TempDir(), AvailableCommands: []string{"tar"}}
+
+			quest := newQuest(tc.intent, "monarch")
+			quest.DryRun = tc.dryRun
+			if err := f.pipeline().Run(quest); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(f.prompter.Questions) != tc.questions {
+				t.Errorf("Expected %d questions, got %v", tc.questions, f.prompter.Questions)
+			}
+			if tc.expected == "" {
+				if f.aiClient.GenerateCallCount != 1 {
+					t.Errorf("Expected the oracle to be asked once, got %d", f.aiClient.GenerateCallCount)
+				}
+				return
+			}
+			if f.aiClient.GenerateCallCount != 0 {
+				t.Errorf("Expected the recipe instead of the oracle, got %d calls", f.aiClient.GenerateCallCount)
+			}
+			if len(f.executor.ExecutedCommands) != 1 || f.executor.ExecutedCommands[0] != tc.expected {
+				t.Errorf("Expected %q to run, got %v", tc.expected, f.executor.ExecutedCommands)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// File: test/redirect_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+)
+
+func TestMatchSubcommand(t *testing.T) {
+	testCases := []struct {
+		intent   string
+		expected string
+	}{
+		{intent: "show my configuration", expected: "configure show"},
+		{intent: "Please show me your settings?", expected: "configure show"},
+		{intent: "what is my current config", expected: "configure show"},
+		{intent: "show my history", expected: "history"},
+		{intent: "can you list my recent quests", expected: "history"},
+		{intent: "what commands did you run recently?", expected: "history"},
+		{intent: "what do you remember about me", expected: "remember list"},
+		{intent: "what do you know about this machine", expected: "realm"},
+		{intent: "are you ready", expected: "doctor"},
+		{intent: "list your recipes", expected: "recipes"},
+		{intent: "show my scheduled spells", expected: "schedule list"},
+		{intent: "undo that", expected: "undo"},
+		{intent: "run that again", expected: "redo last"},
+
+		// Intents about anything else are left to the oracle
+		{intent: "show my nginx configuration", expected: ""},
+		{intent: "show my git config", expected: ""},
+		{intent: "show my bash history", expected: ""},
+		{intent: "show the history of this file", expected: ""},
+		{intent: "undo the last git commit", expected: ""},
+		{intent: "list my docker containers", expected: ""},
+	}
+	for _, tc := range testCases {
+		if got := strings.Join(cli.MatchSubcommand(tc.intent), " "); got != tc.expected {
+			t.Errorf("MatchSubcommand(%q) = %q, expected %q", tc.intent, got, tc.expected)
+		}
+	}
+}
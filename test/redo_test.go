@@ -0,0 +1,118 @@
+// File: test/redo_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+)
+
+func redoJournal() *history.Journal {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	journal := history.NewJournal("")
+	journal.Add(&history.Record{At: at, Intent: "list docker containers", Content: "docker ps -a", Decision: history.DecisionApproved, Executed: true})
+	journal.Add(&history.Record{At: at, Intent: "set up the build", Content: "#!/bin/bash\nmkdir -p build\ncd build && cmake ..", IsScript: true, Decision: history.DecisionDeclined})
+	journal.Add(&history.Record{At: at, Intent: "log in to the registry", Content: "docker login -p [REDACTED]", Redacted: true, Decision: history.DecisionApproved, Executed: true})
+	journal.Add(&history.Record{At: at, Intent: "delete everything", Refusal: "Too dangerous", Decision: history.DecisionNotAsked})
+	return journal
+}
+
+func TestJournal_NumbersQuests(t *testing.T) {
+	journal := redoJournal()
+	for i, record := range journal.Records {
+		if record.ID != i+1 {
+			t.Errorf("Expected quest %d to be numbered %d, got %d", i, i+1, record.ID)
+		}
+	}
+	if found := journal.Find(2); found == nil || found.Intent != "set up the build" {
+		t.Errorf("Expected quest #2 to be found, got %+v", found)
+	}
+	if found := journal.Find(9); found != nil {
+		t.Errorf("Expected no quest #9, got %+v", found)
+	}
+	if last := journal.LastProposal(); last == nil || last.ID != 3 {
+		t.Errorf("Expected the refused quest to be skipped for the last proposal, got %+v", last)
+	}
+}
+
+func TestRedo_RunsTheRecordedCommandWithoutTheOracle(t *testing.T) {
+	f := newPipelineFixture()
+	redo := &cli.Redo{Journal: redoJournal(), Deps: f.deps()}
+
+	quest, err := redo.Run("1", &config.Config{Mode: "monarch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 1 || f.executor.ExecutedCommands[0] != "docker ps -a" {
+		t.Errorf("Expected the recorded command to run, got %v", f.executor.ExecutedCommands)
+	}
+	if f.aiClient.GenerateCallCount != 0 || f.aiClient.ExplainCallCount != 0 {
+		t.Errorf("Expected the oracle not to be consulted, got %d generations and %d explanations", f.aiClient.GenerateCallCount, f.aiClient.ExplainCallCount)
+	}
+	if !quest.Executed || quest.Intent != "list docker containers" || quest.Replay.ID != 1 {
+		t.Errorf("Expected an executed quest for the recorded intent, got %+v", quest)
+	}
+}
+
+func TestRedo_RunsARecordedScript(t *testing.T) {
+	f := newPipelineFixture()
+	redo := &cli.Redo{Journal: redoJournal(), Deps: f.deps()}
+
+	if _, err := redo.Run("#2", &config.Config{Mode: "royal-heir"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedScripts) != 1 || !strings.Contains(f.executor.ExecutedScripts[0], "cmake ..") {
+		t.Errorf("Expected the recorded script to run, got %v", f.executor.ExecutedScripts)
+	}
+	if len(f.executor.ExecutedCommands) != 0 {
+		t.Errorf("Expected no command to run, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestRedo_DeclinedRunsNothing(t *testing.T) {
+	f := newPipelineFixture()
+	f.confirmer.Approve = false
+	redo := &cli.Redo{Journal: redoJournal(), Deps: f.deps()}
+
+	quest, err := redo.Run("1", &config.Config{Mode: "monarch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 || quest.Executed || !quest.Declined {
+		t.Errorf("Expected nothing to run once declined, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestRedo_RejectsWhatCannotRunAgain(t *testing.T) {
+	testCases := []struct {
+		ref      string
+		expected string
+	}{
+		{ref: "last", expected: "redacted"},
+		{ref: "3", expected: "redacted"},
+		{ref: "4", expected: "proposed nothing"},
+		{ref: "9", expected: "not in the journal"},
+		{ref: "yesterday", expected: "neither a quest number"},
+	}
+	for _, tc := range testCases {
+		f := newPipelineFixture()
+		redo := &cli.Redo{Journal: redoJournal(), Deps: f.deps()}
+
+		quest, err := redo.Run(tc.ref, &config.Config{Mode: "monarch"})
+		if err == nil || !strings.Contains(err.Error(), tc.expected) || quest != nil {
+			t.Errorf("%q: expected an error mentioning %q, got %v", tc.ref, tc.expected, err)
+		}
+		if len(f.executor.ExecutedCommands) != 0 {
+			t.Errorf("%q: expected nothing to run, got %v", tc.ref, f.executor.ExecutedCommands)
+		}
+	}
+
+	empty := &cli.Redo{Journal: history.NewJournal(""), Deps: newPipelineFixture().deps()}
+	if _, err := empty.Run("last", &config.Config{Mode: "monarch"}); err == nil {
+		t.Error("Expected 'last' to fail on an empty journal")
+	}
+}
@@ -0,0 +1,124 @@
+// File: test/repeat_test.go
+package test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+)
+
+// runAfterEarlierRun runs a quest proposing content after the same content was run ago, with the
+// repeat warning configured to minutes
+func runAfterEarlierRun(t *testing.T, response *ai.AIResponse, ago time.Duration, minutes int, runErr error) (*cli.Quest, *pipelineFixture) {
+	t.Helper()
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	store.Record("drop the staging table", "", response.Content, response.Type == ai.ResponseTypeScript, runErr, time.Now().Add(-ago))
+
+	f := newPipelineFixture()
+	f.aiClient.Response = response
+	f.prompter.Choices = []int{1} // ask the oracle anew instead of reusing the earlier run
+	deps := f.deps()
+	deps.History = store
+
+	quest := newQuest("get rid of the staging table", "monarch")
+	quest.Config.Execution.RepeatWarningMinutes = minutes
+	if err := cli.NewPipeline(deps).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return quest, f
+}
+
+func TestPipeline_WarnsOfRecentDestructiveRun(t *testing.T) {
+	command := &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf /var/lib/staging"}
+	testCases := []struct {
+		name     string
+		response *ai.AIResponse
+		ago      time.Duration
+		minutes  int
+		warned   bool
+	}{
+		{name: "moments ago", response: command, ago: 3 * time.Minute, warned: true},
+		{name: "before the default window", response: command, ago: time.Hour},
+		{name: "within a configured window", response: command, ago: time.Hour, minutes: 90, warned: true},
+		{name: "warning turned off", response: command, ago: time.Minute, minutes: -1},
+		{name: "not destructive", response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "mkdir -p /var/lib/staging"}, ago: time.Minute},
+		{name: "rated destructive by the oracle", response: &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "./drop-staging.sh", Risk: "destructive"}, ago: time.Minute, warned: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quest, f := runAfterEarlierRun(t, tc.response, tc.ago, tc.minutes, nil)
+			if warned := quest.RecentRun != nil; warned != tc.warned {
+				t.Errorf("Expected a warning to be %v, got %+v", tc.warned, quest.RecentRun)
+			}
+			// The warning comes before an ordinary confirmation, which decides
+			if f.confirmer.CallCount != 1 || len(f.executor.ExecutedCommands) != 1 {
+				t.Errorf("Expected the quest to be confirmed and run, got %d confirmations", f.confirmer.CallCount)
+			}
+		})
+	}
+}
+
+func TestPipeline_RecentRunNeedsApprovalAgain(t *testing.T) {
+	// An identical script is normally trusted without asking, but not right after it ran
+	script := &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "#!/bin/bash\nrm -rf /var/lib/staging\n"}
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	store.Record("get rid of the staging table", "", script.Content, true, errors.New("exit status 1"), time.Now().Add(-2*time.Minute))
+
+	f := newPipelineFixture()
+	f.aiClient.Response = script
+	f.confirmer.Approve = false
+	f.prompter.Choices = []int{1}
+	deps := f.deps()
+	deps.History = store
+
+	quest := newQuest("get rid of the staging table", "monarch")
+	if err := cli.NewPipeline(deps).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quest.Trust == nil || !quest.Trust.Identical || quest.RecentRun == nil {
+		t.Fatalf("Expected a trusted script that ran moments ago, got %+v and %+v", quest.Trust, quest.RecentRun)
+	}
+	if f.confirmer.CallCount != 1 || len(f.executor.ExecutedScripts) != 0 {
+		t.Errorf("Expected the script to wait for a fresh approval, got %d confirmations", f.confirmer.CallCount)
+	}
+}
+
+func TestWorkspaceRun_WarnsOfRecentDestructiveRun(t *testing.T) {
+	f := newWorkspaceFixture(t)
+	f.history, _ = history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	f.history.Record("clear the build folder", "", "rm -rf ./build", false, nil, time.Now().Add(-2*time.Minute))
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf ./build"}
+
+	results := f.run(t, false)
+	if results[0].Quest.RecentRun == nil || results[1].Quest.RecentRun == nil {
+		t.Errorf("Expected every project to be warned of the recent run, got %+v and %+v", results[0].Quest.RecentRun, results[1].Quest.RecentRun)
+	}
+}
+
+func TestPipeline_AutoFixWarnsOfRecentDestructiveRun(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	store.Record("drop the staging table", "", "rm -rf /var/lib/staging", false, nil, time.Now().Add(-2*time.Minute))
+
+	f := newPipelineFixture()
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf /var/lib/stagin"}
+	f.aiClient.FixResponse = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "rm -rf /var/lib/staging"}
+	f.executor.FailOn = map[string]bool{"rm -rf /var/lib/stagin": true}
+	f.prompter.Choices = []int{1} // ask the oracle anew instead of reusing the earlier run
+	deps := f.deps()
+	deps.History = store
+
+	quest := newQuest("get rid of the staging table", "monarch")
+	quest.AutoFixLimit = 1
+	if err := cli.NewPipeline(deps).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quest.RecentRun == nil || quest.Content != "rm -rf /var/lib/staging" {
+		t.Errorf("Expected the corrected command to be warned of its recent run, got %q and %+v", quest.Content, quest.RecentRun)
+	}
+}
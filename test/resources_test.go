@@ -0,0 +1,95 @@
+// File: test/resources_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func writeCgroupFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	file := filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseCgroupLimits(t *testing.T) {
+	if got := system.ParseCgroupCPUMax("150000 100000"); got != 1.5 {
+		t.Errorf("Expected 1.5 CPUs, got %v", got)
+	}
+	if got := system.ParseCgroupCPUMax("max 100000"); got != 0 {
+		t.Errorf("Expected no quota, got %v", got)
+	}
+	if got := system.ParseCgroupV1CPU("-1", "100000"); got != 0 {
+		t.Errorf("Expected no quota, got %v", got)
+	}
+	if got := system.ParseCgroupMemory("536870912"); got != 512<<20 {
+		t.Errorf("Expected 512 MiB, got %d", got)
+	}
+	for _, unlimited := range []string{"max", "9223372036854771712"} {
+		if got := system.ParseCgroupMemory(unlimited); got != 0 {
+			t.Errorf("Expected %q to mean unlimited, got %d", unlimited, got)
+		}
+	}
+	if got := system.ParseMemTotal("MemTotal:       16318412 kB\nMemFree:         1024 kB\n"); got != 16318412*1024 {
+		t.Errorf("Unexpected total memory %d", got)
+	}
+}
+
+func TestCgroupLimits_V2(t *testing.T) {
+	root := t.TempDir()
+	// The container's own limits at the root, and a tighter memory limit on its cgroup
+	writeCgroupFile(t, root, "cpu.max", "200000 100000")
+	writeCgroupFile(t, root, "memory.max", "max")
+	writeCgroupFile(t, root, "app/cpu.max", "max 100000")
+	writeCgroupFile(t, root, "app/memory.max", "1073741824")
+
+	cpu, memory := system.CgroupLimits(root, "0::/app\n")
+	if cpu != 2 || memory != 1<<30 {
+		t.Errorf("Expected 2 CPUs and 1 GiB, got %v and %d", cpu, memory)
+	}
+}
+
+func TestCgroupLimits_V1(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_quota_us", "50000")
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_period_us", "100000")
+	writeCgroupFile(t, root, "memory/memory.limit_in_bytes", "268435456")
+
+	// Inside a container the cgroup's own path is not mounted
+	cpu, memory := system.CgroupLimits(root, "12:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n")
+	if cpu != 0.5 || memory != 256<<20 {
+		t.Errorf("Expected half a CPU and 256 MiB, got %v and %d", cpu, memory)
+	}
+
+	if cpu, memory := system.CgroupLimits(t.TempDir(), "0::/\n"); cpu != 0 || memory != 0 {
+		t.Errorf("Expected no limits without cgroup files, got %v and %d", cpu, memory)
+	}
+}
+
+func TestResourceLimits_String(t *testing.T) {
+	host := system.ResourceLimits{HostCPUs: 16, HostMemoryBytes: 32 << 30}
+	if host.Limited() || host.EffectiveCPUs() != 16 || host.String() != "16 CPUs, 32.0 GiB memory" {
+		t.Errorf("Unexpected host description %q", host.String())
+	}
+
+	container := system.ResourceLimits{HostCPUs: 16, HostMemoryBytes: 32 << 30, CPUQuota: 1.5, MemoryBytes: 512 << 20}
+	if !container.Limited() || container.EffectiveCPUs() != 2 {
+		t.Errorf("Expected the quota to round up to 2 CPUs, got %d", container.EffectiveCPUs())
+	}
+	expected := "2 CPUs (cgroup quota of 1.5 CPUs on a 16-CPU host; nproc reports the host's count), 512 MiB memory (cgroup limit; the host has 32.0 GiB)"
+	if got := container.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := (system.ResourceLimits{}).String(); got != "unknown" {
+		t.Errorf("Expected unknown limits, got %q", got)
+	}
+}
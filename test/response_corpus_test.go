@@ -0,0 +1,109 @@
+// File: test/response_corpus_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"gopkg.in/yaml.v3"
+)
+
+// responseFixture is one raw provider response from testdata/responses.yaml
+type responseFixture struct {
+	Name       string `yaml:"name"`
+	Provider   string `yaml:"provider"`
+	Raw        string `yaml:"raw"`
+	WellFormed bool   `yaml:"well_formed"`
+	Type       string `yaml:"type"`
+	Content    string `yaml:"content"`
+	Error      string `yaml:"error"`
+	Category   string `yaml:"category"`
+	Truncated  bool   `yaml:"truncated"`
+	Note       string `yaml:"note"`
+}
+
+// minCorpusSize guards against the corpus being emptied by accident
+const minCorpusSize = 30
+
+var fixtureTypes = map[string]ai.ResponseType{
+	"command": ai.ResponseTypeCommand,
+	"script":  ai.ResponseTypeScript,
+	"failure": ai.ResponseTypeFailure,
+	"answer":  ai.ResponseTypeAnswer,
+}
+
+func loadResponseCorpus(t testing.TB) []responseFixture {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "responses.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read the response corpus: %v", err)
+	}
+	var fixtures []responseFixture
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("Failed to parse the response corpus: %v", err)
+	}
+	return fixtures
+}
+
+func TestResponseCorpus_IsValid(t *testing.T) {
+	fixtures := loadResponseCorpus(t)
+	if len(fixtures) < minCorpusSize {
+		t.Fatalf("Expected at least %d fixtures, found %d", minCorpusSize, len(fixtures))
+	}
+
+	names := make(map[string]bool)
+	for _, f := range fixtures {
+		if f.Name == "" || names[f.Name] {
+			t.Errorf("Fixture names must be present and unique, got %q", f.Name)
+		}
+		names[f.Name] = true
+		if _, ok := fixtureTypes[f.Type]; !ok {
+			t.Errorf("%s: unknown type %q", f.Name, f.Type)
+		}
+		if f.Provider != "gemini" && f.Provider != "openai" && f.Provider != "anthropic" {
+			t.Errorf("%s: unknown provider %q", f.Name, f.Provider)
+		}
+	}
+}
+
+func TestResponseCorpus_ParseAIResponse(t *testing.T) {
+	for _, f := range loadResponseCorpus(t) {
+		t.Run(f.Name, func(t *testing.T) {
+			response := ai.ParseAIResponse(f.Raw)
+
+			if response.Type != fixtureTypes[f.Type] {
+				t.Errorf("Expected type %s, got %v", f.Type, response.Type)
+			}
+			if response.Content != f.Content {
+				t.Errorf("Expected content %q, got %q", f.Content, response.Content)
+			}
+			if response.Error != f.Error {
+				t.Errorf("Expected error %q, got %q", f.Error, response.Error)
+			}
+			if string(response.Category) != f.Category {
+				t.Errorf("Expected category %q, got %q", f.Category, response.Category)
+			}
+			if t.Failed() && f.Note != "" {
+				t.Logf("Note: %s", f.Note)
+			}
+		})
+	}
+}
+
+func TestResponseCorpus_WellFormed(t *testing.T) {
+	for _, f := range loadResponseCorpus(t) {
+		if got := ai.IsWellFormedResponse(f.Raw); got != f.WellFormed {
+			t.Errorf("%s: expected well-formed %v, got %v", f.Name, f.WellFormed, got)
+		}
+	}
+}
+
+func TestResponseCorpus_Truncated(t *testing.T) {
+	for _, f := range loadResponseCorpus(t) {
+		if got := ai.IsTruncatedResponse(f.Raw); got != f.Truncated {
+			t.Errorf("%s: expected truncated %v, got %v", f.Name, f.Truncated, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// File: test/response_protocol_test.go
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// jsonPrompt ends like every prompt that asks for a JSON response
+const jsonPrompt = "list the files here\n\nJSON RESPONSE:"
+
+func TestClient_AsksForJSONResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	responses := filepath.Join(t.TempDir(), "responses.yaml")
+	os.WriteFile(responses, []byte(`responses:
+  - intent: "disk"
+    prompt: "JSON RESPONSE:$"
+    response: '{"type": "command", "command": "df -h", "explanation": "Shows the free space of each disk.", "risk": "Read_Only"}'
+  - intent: "old logs"
+    prompt: "JSON RESPONSE:$"
+    response: '{"type": "command", "command": "find . -name \"*.log\" -mtime +30"}'
+default: "FAILURE: The prompt did not ask for JSON."
+`), 0644)
+
+	client, err := ai.NewClient(mockConfig(responses))
+	if err != nil {
+		t.Fatalf("Failed to create the mock client: %v", err)
+	}
+	sysInfo := &system.Info{OS: "linux", Shell: "/bin/bash", CurrentDir: "/tmp"}
+
+	response, err := client.GenerateResponse("how much disk is free", sysInfo)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Type != ai.ResponseTypeCommand || response.Content != "df -h" {
+		t.Fatalf("Expected the JSON command, got %+v", response)
+	}
+	if response.Explanation != "Shows the free space of each disk." || response.Risk != "read-only" {
+		t.Errorf("Expected the explanation and the normalized risk, got %q and %q", response.Explanation, response.Risk)
+	}
+
+	listing, err := client.ListCleanupCandidates("delete the old logs", sysInfo)
+	if err != nil || listing.Content != `find . -name "*.log" -mtime +30` {
+		t.Errorf("Expected the cleanup listing as JSON, got %+v and %v", listing, err)
+	}
+}
+
+// chatCompletionServer answers every chat completion with content and records the request bodies
+func chatCompletionServer(t *testing.T, content string) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &request)
+		requests = append(requests, request)
+		answer, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}}})
+		w.Write(answer)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestOpenRouterProvider_JSONMode(t *testing.T) {
+	server, requests := chatCompletionServer(t, `{"type": "command", "command": "ls"}`)
+	provider, _ := ai.NewOpenRouterProvider(openRouterConfig(server.URL))
+
+	provider.GenerateResponse(jsonPrompt)
+	provider.GenerateResponse("Explain what ls does.\n\nEXPLANATION:")
+
+	format, ok := (*requests)[0]["response_format"].(map[string]any)
+	if !ok || format["type"] != "json_object" {
+		t.Errorf("Expected a JSON object to be asked for, got %v", (*requests)[0]["response_format"])
+	}
+	if _, ok := (*requests)[1]["response_format"]; ok {
+		t.Errorf("Expected plain text for a prompt that expects no JSON, got %v", (*requests)[1]["response_format"])
+	}
+}
+
+func TestOpenAIProvider_CustomBaseURLWithoutJSONMode(t *testing.T) {
+	server, requests := chatCompletionServer(t, `{"type": "command", "command": "ls"}`)
+	provider, _ := ai.NewOpenAIProvider(compatibleConfig(server.URL, ""))
+
+	if _, err := provider.GenerateResponse(jsonPrompt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := (*requests)[0]["response_format"]; ok {
+		t.Errorf("Expected no response_format for an OpenAI-compatible server, which may reject it")
+	}
+}
+
+func TestPipeline_MalformedResponseIsNeverRun(t *testing.T) {
+	f := newPipelineFixture()
+	f.aiClient.Response = ai.ParseAIResponse("Sure! To clean up, run rm -rf ~/Downloads/old")
+
+	quest := newQuest("clean up my old downloads", "monarch")
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quest.Executed || len(f.executor.ExecutedCommands) > 0 {
+		t.Errorf("Expected prose never to be run, got %v", f.executor.ExecutedCommands)
+	}
+	if quest.Response.Category != ai.FailureMalformed {
+		t.Errorf("Expected a malformed failure, got %+v", quest.Response)
+	}
+}
@@ -0,0 +1,259 @@
+// File: test/schedule_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestParseSchedule(t *testing.T) {
+	testCases := []struct {
+		text       string
+		cron       string
+		onCalendar string
+	}{
+		{text: "every day at 2am", cron: "0 2 * * *", onCalendar: "*-*-* 2:0:00"},
+		{text: "Daily at 2:30 PM", cron: "30 14 * * *", onCalendar: "*-*-* 14:30:00"},
+		{text: "at noon every day", cron: "0 12 * * *", onCalendar: "*-*-* 12:0:00"},
+		{text: "every 15 minutes", cron: "*/15 * * * *", onCalendar: "*-*-* *:0/15:00"},
+		{text: "every hour", cron: "0 * * * *", onCalendar: "*-*-* *:0:00"},
+		{text: "every 6 hours", cron: "0 */6 * * *", onCalendar: "*-*-* 0/6:0:00"},
+		{text: "weekdays at 18:00", cron: "0 18 * * 1-5", onCalendar: "Mon..Fri *-*-* 18:0:00"},
+		{text: "every monday and friday at 9:30", cron: "30 9 * * 1,5", onCalendar: "Mon,Fri *-*-* 9:30:00"},
+		{text: "sundays at midnight", cron: "0 0 * * 0", onCalendar: "Sun *-*-* 0:0:00"},
+		{text: "every month", cron: "0 0 1 * *", onCalendar: "*-*-1 0:0:00"},
+		{text: "5 4 * * 0", cron: "5 4 * * 0", onCalendar: "Sun *-*-* 4:5:00"},
+	}
+	for _, tc := range testCases {
+		schedule, err := system.ParseSchedule(tc.text)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): unexpected error: %v", tc.text, err)
+			continue
+		}
+		if schedule.Cron() != tc.cron || schedule.OnCalendar() != tc.onCalendar {
+			t.Errorf("ParseSchedule(%q) = %q / %q, expected %q / %q", tc.text, schedule.Cron(), schedule.OnCalendar(), tc.cron, tc.onCalendar)
+		}
+	}
+
+	for _, text := range []string{"", "sometimes", "every day at 25:00", "every 90 minutes", "at 13pm", "every blursday at 2am"} {
+		if _, err := system.ParseSchedule(text); err == nil {
+			t.Errorf("ParseSchedule(%q): expected an error", text)
+		}
+	}
+}
+
+func TestSchedule_TaskSchedulerArgs(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "every day at 2am", expected: "/SC DAILY /ST 02:00"},
+		{text: "every 15 minutes", expected: "/SC MINUTE /MO 15"},
+		{text: "every 6 hours", expected: "/SC HOURLY /MO 6 /ST 00:00"},
+		{text: "weekdays at 18:00", expected: "/SC WEEKLY /D MON,TUE,WED,THU,FRI /ST 18:00"},
+		{text: "every month", expected: "/SC MONTHLY /D 1 /ST 00:00"},
+	}
+	for _, tc := range testCases {
+		schedule, _ := system.ParseSchedule(tc.text)
+		args, err := schedule.TaskSchedulerArgs()
+		if err != nil || strings.Join(args, " ") != tc.expected {
+			t.Errorf("%q: got %v and %v, expected %q", tc.text, args, err, tc.expected)
+		}
+	}
+
+	schedule, _ := system.ParseSchedule("0 2 1-7 */2 *")
+	if _, err := schedule.TaskSchedulerArgs(); err == nil {
+		t.Error("Expected a cron expression beyond the Task Scheduler to be refused")
+	}
+}
+
+func TestCronEntries(t *testing.T) {
+	daily, _ := system.ParseSchedule("every day at 2am")
+	entry := system.CronEntry("back_up", daily, []string{"/opt/my tools/execute-my-will", "schedule", "run", "back_up"})
+	if entry != "# execute-my-will: back_up\n0 2 * * * '/opt/my tools/execute-my-will' schedule run back_up\n" {
+		t.Errorf("Unexpected cron entry %q", entry)
+	}
+
+	crontab := "MAILTO=me@example.com\n30 1 * * * /usr/bin/certbot renew"
+	crontab = system.AddCronEntry(crontab, "back_up", entry)
+	hourly, _ := system.ParseSchedule("every hour")
+	crontab = system.AddCronEntry(crontab, "back_up", system.CronEntry("back_up", hourly, []string{"/usr/bin/execute-my-will", "schedule", "run", "back_up"}))
+	if strings.Count(crontab, "back_up\n") != 2 || !strings.Contains(crontab, "0 * * * *") || strings.Contains(crontab, "0 2 * * *") {
+		t.Errorf("Expected the entry to be replaced, got:\n%s", crontab)
+	}
+
+	crontab, found := system.RemoveCronEntry(crontab, "back_up")
+	if !found || crontab != "MAILTO=me@example.com\n30 1 * * * /usr/bin/certbot renew\n" {
+		t.Errorf("Expected only the user's own entries to remain, got %v:\n%s", found, crontab)
+	}
+	if _, found := system.RemoveCronEntry(crontab, "back_up"); found {
+		t.Error("Expected nothing to remove the second time")
+	}
+
+	percent := system.CronEntry("p", daily, []string{"/tmp/100%/execute-my-will"})
+	if !strings.Contains(percent, `100\%`) {
+		t.Errorf("Expected %% to be escaped for cron, got %q", percent)
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	schedule, _ := system.ParseSchedule("weekdays at 18:00")
+	service, timer := system.SystemdUnits("tidy", schedule, []string{"/usr/bin/execute-my-will", "schedule", "run", "tidy"})
+	if !strings.Contains(service, `ExecStart="/usr/bin/execute-my-will" "schedule" "run" "tidy"`) || !strings.Contains(service, "Type=oneshot") {
+		t.Errorf("Unexpected service unit:\n%s", service)
+	}
+	if !strings.Contains(timer, "OnCalendar=Mon..Fri *-*-* 18:0:00") || !strings.Contains(timer, "WantedBy=timers.target") {
+		t.Errorf("Unexpected timer unit:\n%s", timer)
+	}
+}
+
+func TestFunctionDefinition(t *testing.T) {
+	library := "# Shell functions distilled from recurring quests by execute-my-will.\n#   . ~/.config/execute-my-will/functions.sh\n\n" +
+		system.ShellFunction("back_up", "back up my dotfiles", "#!/bin/sh\nrsync -a ~/.dotfiles /mnt/nas", 3, time.Now()) + "\n" +
+		system.ShellFunction("tidy", "tidy downloads", "rm -f ~/Downloads/*.tmp", 4, time.Now())
+
+	definition := system.FunctionDefinition(library, "back_up")
+	if !strings.HasPrefix(definition, "# back up my dotfiles\n") || !strings.HasSuffix(definition, "\n)\n") || strings.Contains(definition, "tidy") || strings.Contains(definition, "distilled from recurring") {
+		t.Errorf("Unexpected definition:\n%s", definition)
+	}
+	if system.FunctionDefinition(library, "missing") != "" {
+		t.Error("Expected no definition for a missing function")
+	}
+}
+
+func newScheduling(t *testing.T, choices ...int) (*cli.Scheduling, *MockScheduler) {
+	t.Helper()
+	dir := t.TempDir()
+	library := filepath.Join(dir, system.FunctionsFile)
+	if err := system.AppendFunction(library, system.ShellFunction("back_up", "back up my dotfiles", "rsync -a ~/.dotfiles /mnt/nas", 3, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	journal := history.NewJournal("")
+	journal.Add(&history.Record{Intent: "clear the apt cache", Content: "sudo apt-get clean", Decision: history.DecisionApproved, Executed: true})
+	journal.Add(&history.Record{Intent: "delete old logs", Content: "rm -rf /var/log/old", Decision: history.DecisionDeclined})
+	journal.Add(&history.Record{Intent: "push the backups", Content: "curl -H 'Authorization: [REDACTED]' https://example.com", Decision: history.DecisionApproved, Executed: true, Redacted: true})
+
+	schedules, err := history.LoadSchedules(filepath.Join(dir, history.SchedulesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := &MockScheduler{}
+	return &cli.Scheduling{
+		Journal:       journal,
+		Library:       library,
+		Schedules:     schedules,
+		NewScheduler:  func(string) (system.Scheduler, error) { return scheduler, nil },
+		SchedulerKind: system.SchedulerCron,
+		Prompter:      &MockPrompter{Choices: choices},
+		Dir:           filepath.Join(dir, "scheduled"),
+		Shell:         "/bin/bash",
+		Executable:    "/usr/local/bin/execute-my-will",
+		Now:           time.Now,
+	}, scheduler
+}
+
+func TestScheduling_SchedulesAFunction(t *testing.T) {
+	scheduling, scheduler := newScheduling(t, 0)
+
+	spell, err := scheduling.Run("every day at 2am", "back_up", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spell == nil || scheduler.Installed["back_up"].Cron() != "0 2 * * *" {
+		t.Fatalf("Expected the function to be scheduled, got %+v", scheduler.Installed)
+	}
+	if command := strings.Join(scheduler.Commands["back_up"], " "); command != "/usr/local/bin/execute-my-will schedule run back_up" {
+		t.Errorf("Expected the scheduler to run the spell through execute-my-will, got %q", command)
+	}
+
+	script, err := os.ReadFile(spell.Script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(script), "#!/bin/bash\n") || !strings.Contains(string(script), "rsync -a ~/.dotfiles /mnt/nas") || !strings.HasSuffix(string(script), ")\n\nback_up\n") {
+		t.Errorf("Expected the script to define and call the function, got:\n%s", script)
+	}
+
+	saved, err := history.LoadSchedules(filepath.Join(filepath.Dir(scheduling.Dir), history.SchedulesFile))
+	if err != nil || saved.Find("back_up") == nil || saved.Find("back_up").Scheduler != system.SchedulerCron {
+		t.Errorf("Expected the scheduled spell to be saved, got %+v and %v", saved, err)
+	}
+}
+
+func TestScheduling_SchedulesAnApprovedQuest(t *testing.T) {
+	scheduling, scheduler := newScheduling(t, 0)
+
+	spell, err := scheduling.Run("sundays at 3am", "#1", "clean_apt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spell.Name != "clean_apt" || spell.Source != "quest #1" || scheduler.Installed["clean_apt"] == nil {
+		t.Errorf("Expected the quest to be scheduled as clean_apt, got %+v", spell)
+	}
+}
+
+func TestScheduling_RefusesWhatCannotRunUnattended(t *testing.T) {
+	testCases := []struct {
+		when     string
+		ref      string
+		name     string
+		expected string
+	}{
+		{when: "every day at 2am", ref: "2", expected: "did not run successfully"},
+		{when: "every day at 2am", ref: "3", expected: "redacted"},
+		{when: "every day at 2am", ref: "9", expected: "not in the journal"},
+		{when: "every day at 2am", ref: "missing", expected: "no spell called 'missing'"},
+		{when: "now and then", ref: "back_up", expected: "cannot understand the schedule"},
+		{when: "every day at 2am", ref: "back_up", name: "back-up", expected: "not a valid function name"},
+	}
+	for _, tc := range testCases {
+		scheduling, scheduler := newScheduling(t)
+		spell, err := scheduling.Run(tc.when, tc.ref, tc.name)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) || spell != nil {
+			t.Errorf("%q: expected an error mentioning %q, got %v", tc.ref, tc.expected, err)
+		}
+		if len(scheduler.Installed) != 0 {
+			t.Errorf("%q: expected nothing to be scheduled", tc.ref)
+		}
+	}
+}
+
+func TestScheduling_LeftUnscheduled(t *testing.T) {
+	scheduling, scheduler := newScheduling(t, 1)
+
+	spell, err := scheduling.Run("every day at 2am", "back_up", "")
+	if err != nil || spell != nil || len(scheduler.Installed) != 0 {
+		t.Errorf("Expected nothing to be scheduled, got %+v and %v", spell, err)
+	}
+	if _, err := os.Stat(scheduling.Dir); !os.IsNotExist(err) {
+		t.Error("Expected no script to be written")
+	}
+}
+
+func TestScheduling_Remove(t *testing.T) {
+	scheduling, scheduler := newScheduling(t, 0)
+	spell, err := scheduling.Run("every hour", "back_up", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := scheduling.Remove("back_up"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(scheduler.Removed) != 1 || scheduling.Schedules.Find("back_up") != nil {
+		t.Errorf("Expected the spell to be removed, got %v", scheduler.Removed)
+	}
+	if _, err := os.Stat(spell.Script); !os.IsNotExist(err) {
+		t.Error("Expected the spell's script to be deleted")
+	}
+	if err := scheduling.Remove("back_up"); err == nil {
+		t.Error("Expected an error removing a spell that is not scheduled")
+	}
+}
@@ -0,0 +1,154 @@
+// File: test/script_command_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func newScriptQuest(intent string, request *cli.ScriptRequest) *cli.Quest {
+	quest := newQuest(intent, "monarch")
+	quest.Script = request
+	return quest
+}
+
+func TestScriptPipeline_StageOrder(t *testing.T) {
+	stages := cli.NewScriptPipeline(newPipelineFixture().deps()).Stages()
+	expected := []string{"analyze", "validate", "docs", "generate", "airgap", "flags", "verify", "script", "review", "save", "trust", "repeat", "confirm", "reauth", "eval", "elevate", "detach", "execute", "report", "summarize", "autofix"}
+
+	if strings.Join(stages, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected stages %v, got %v", expected, stages)
+	}
+}
+
+func TestScriptPipeline_SavesWithoutRunning(t *testing.T) {
+	dir := t.TempDir()
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "/bin/bash", CurrentDir: dir, HomeDir: dir}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "cp -r ~/.bashrc ~/backups/$(date +%F)/"}
+
+	quest := newScriptQuest("back up my dotfiles", &cli.ScriptRequest{Shebang: "path"})
+	if err := cli.NewScriptPipeline(f.deps()).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(f.aiClient.LastIntent, "SCRIPT REQUESTED") {
+		t.Errorf("Expected a script to be asked for, got %q", f.aiClient.LastIntent)
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, "back_up_dotfiles.sh"))
+	if err != nil {
+		t.Fatalf("Expected the script to be saved under a name taken from the intent: %v", err)
+	}
+	if string(saved) != "#!/bin/bash\ncp -r ~/.bashrc ~/backups/$(date +%F)/\n" {
+		t.Errorf("Expected the command as a one-line script, got %q", saved)
+	}
+	if !quest.IsScript || quest.Executed || len(f.executor.ExecutedScripts) != 0 {
+		t.Error("Expected the script to be saved but not run")
+	}
+}
+
+func TestScriptPipeline_KeepsShebangOfCorrectedCommand(t *testing.T) {
+	dir := t.TempDir()
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "/bin/bash", CurrentDir: dir, HomeDir: dir}
+	f.aiClient.NextResponses = []*ai.AIResponse{
+		{Type: ai.ResponseTypeCommand, Content: "tar --frobnicate -czf docs.tar.gz docs"},
+		{Type: ai.ResponseTypeCommand, Content: "tar -czf docs.tar.gz docs"},
+	}
+	deps := f.deps()
+	deps.CheckFlags = func(content string) []system.UnknownFlag { return system.CheckFlags(content, testHelp) }
+
+	quest := newScriptQuest("archive the docs", &cli.ScriptRequest{Shebang: "env"})
+	if err := cli.NewScriptPipeline(deps).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "archive_docs.sh"))
+	if err != nil {
+		t.Fatalf("Expected the script to be saved: %v", err)
+	}
+	if string(saved) != "#!/usr/bin/env bash\ntar -czf docs.tar.gz docs\n" {
+		t.Errorf("Expected the corrected command as a script with its interpreter line, got %q", saved)
+	}
+}
+
+func TestScriptPipeline_RunsAfterSaving(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "backup.sh")
+	os.WriteFile(output, []byte("old"), 0644)
+
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "zsh", CurrentDir: dir, HomeDir: dir}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "#!/bin/sh\nmkdir -p ~/backups\ncp ~/.zshrc ~/backups/"}
+	f.prompter.Choices = []int{0} // replace the existing file
+
+	quest := newScriptQuest("back up my dotfiles", &cli.ScriptRequest{Output: output, Shebang: "env", Run: true})
+	if err := cli.NewScriptPipeline(f.deps()).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	saved, _ := os.ReadFile(output)
+	if !strings.HasPrefix(string(saved), "#!/usr/bin/env zsh\nmkdir -p ~/backups\n") {
+		t.Errorf("Expected the shebang to be replaced, got %q", saved)
+	}
+	if !quest.Executed || len(f.executor.ExecutedScripts) != 1 {
+		t.Error("Expected the saved script to be run once approved")
+	}
+}
+
+func TestScriptPipeline_KeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "backup.sh")
+	os.WriteFile(output, []byte("old"), 0644)
+
+	f := newPipelineFixture()
+	f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "bash", CurrentDir: dir, HomeDir: dir}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeScript, Content: "echo new"}
+	f.prompter.Choices = []int{1}
+
+	quest := newScriptQuest("back up my dotfiles", &cli.ScriptRequest{Output: output, Shebang: "none", Run: true})
+	if err := cli.NewScriptPipeline(f.deps()).Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if saved, _ := os.ReadFile(output); string(saved) != "old" || quest.Executed {
+		t.Errorf("Expected the existing file to be kept and nothing run, got %q", saved)
+	}
+}
+
+func TestApplyShebang(t *testing.T) {
+	testCases := []struct {
+		name, content, shell, style, expected string
+	}{
+		{name: "env", content: "echo hi", shell: "/usr/bin/bash", style: "env", expected: "#!/usr/bin/env bash\necho hi"},
+		{name: "path of a bare shell name", content: "echo hi", shell: "zsh", style: "path", expected: "#!/bin/zsh\necho hi"},
+		{name: "replaces an existing line", content: "#!/bin/sh\necho hi", shell: "/bin/bash", style: "path", expected: "#!/bin/bash\necho hi"},
+		{name: "none removes the line", content: "#!/bin/sh\necho hi", shell: "bash", style: "none", expected: "echo hi"},
+		{name: "fish", content: "echo hi", shell: "/usr/bin/fish", style: "env", expected: "#!/usr/bin/env fish\necho hi"},
+		{name: "powershell is left alone", content: "Write-Host hi", shell: "pwsh", style: "env", expected: "Write-Host hi"},
+		{name: "cmd is left alone", content: "echo hi", shell: "cmd", style: "path", expected: "echo hi"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.ApplyShebang(tc.content, tc.shell, tc.style); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestScriptPath(t *testing.T) {
+	info := &system.Info{Shell: "powershell", CurrentDir: "/work", HomeDir: "/home/me"}
+	if got := cli.ScriptPath("", "Clean the build cache", info); got != filepath.Join("/work", "clean_build_cache.ps1") {
+		t.Errorf("Expected a PowerShell file named after the intent, got %s", got)
+	}
+	if got := cli.ScriptPath("~/bin/clean.ps1", "Clean the build cache", info); got != filepath.Join("/home/me", "bin", "clean.ps1") {
+		t.Errorf("Expected ~ to be expanded, got %s", got)
+	}
+}
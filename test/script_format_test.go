@@ -0,0 +1,44 @@
+// File: test/script_format_test.go
+package test
+
+import (
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestNormalizeScript(t *testing.T) {
+	testCases := []struct {
+		name     string
+		shell    string
+		script   string
+		expected string
+	}{
+		{"crlf for bash", "bash", "set -e\r\ncd /tmp\r\n", "set -e\ncd /tmp\n"},
+		{"lone carriage return", "zsh", "echo one\recho two", "echo one\necho two"},
+		{"relative backslash path", "bash", `cp .\build\app.tar.gz ~\releases\`, `cp ./build/app.tar.gz ~/releases/`},
+		{"mixed separators", "sh", `ls "$HOME\Documents/notes"`, `ls "$HOME/Documents/notes"`},
+		{"escapes kept", "bash", `printf "$fmt\n" && grep -E '.\d+' log.txt && sed 's/\./_/g' f`, `printf "$fmt\n" && grep -E '.\d+' log.txt && sed 's/\./_/g' f`},
+		{"line continuation kept", "bash", "tar -czf out.tgz \\\n  ./src", "tar -czf out.tgz \\\n  ./src"},
+		{"cmd relative path", "cmd", `call ./scripts/build.bat && dir /s %USERPROFILE%/Documents`, `call .\scripts\build.bat && dir /s %USERPROFILE%\Documents`},
+		{"cmd home path", "cmd.exe", `copy notes.txt ~/Desktop`, `copy notes.txt %USERPROFILE%\Desktop`},
+		{"cmd switches and urls kept", "cmd", `curl -o page.html https://example.com/a/b && dir /b /s`, `curl -o page.html https://example.com/a/b && dir /b /s`},
+		{"powershell paths kept", "pwsh", "Copy-Item ./a.txt .\\b\\\r\n", "Copy-Item ./a.txt .\\b\\\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.NormalizeScript(tc.script, tc.shell); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestScriptLineEnding(t *testing.T) {
+	for shell, expected := range map[string]string{"bash": "\n", "fish": "\n", "cmd": "\r\n", "powershell": "\r\n"} {
+		if got := system.ScriptLineEnding(shell); got != expected {
+			t.Errorf("%s: expected %q, got %q", shell, expected, got)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// File: test/shell_session_test.go
+package test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// startTestSession starts a session in bash in a new directory, which it returns
+func startTestSession(t *testing.T) (*system.ShellSession, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("uses bash, like the scripts the knight writes")
+	}
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	session, err := system.StartShellSession("bash", map[string]string{"EMW_TEST_CONFIGURED": "yes"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session, dir
+}
+
+func TestShellSession_KeepsDirectoryAndVariables(t *testing.T) {
+	session, dir := startTestSession(t)
+	if err := os.Mkdir(filepath.Join(dir, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code, err := session.Run("cd api && export EMW_TEST_STAGE=two", &stdout, &stderr); err != nil || code != 0 {
+		t.Fatalf("Expected the first command to succeed, got %d and %v", code, err)
+	}
+	if session.Dir() != filepath.Join(dir, "api") {
+		t.Errorf("Expected the session in %s, got %s", filepath.Join(dir, "api"), session.Dir())
+	}
+
+	code, err := session.Run(`pwd; echo "$EMW_TEST_STAGE $EMW_TEST_CONFIGURED"; printf 'no newline'; echo oops >&2`, &stdout, &stderr)
+	if err != nil || code != 0 {
+		t.Fatalf("Expected the second command to succeed, got %d and %v", code, err)
+	}
+	expected := filepath.Join(dir, "api") + "\ntwo yes\nno newline\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected %q on stdout, got %q", expected, stdout.String())
+	}
+	if stderr.String() != "oops\n" {
+		t.Errorf("Expected the error output apart, got %q", stderr.String())
+	}
+}
+
+func TestShellSession_ReportsExitStatusAndEnd(t *testing.T) {
+	session, _ := startTestSession(t)
+
+	var out, errOut bytes.Buffer
+	if code, err := session.Run("false", &out, &errOut); err != nil || code != 1 {
+		t.Errorf("Expected status 1, got %d and %v", code, err)
+	}
+	if code, err := session.Run("(exit 3)", &out, &errOut); err != nil || code != 3 {
+		t.Errorf("Expected status 3, got %d and %v", code, err)
+	}
+	if !session.Alive() {
+		t.Fatal("Expected a failed command to leave the session running")
+	}
+
+	if _, err := session.Run("exit 4", &out, &errOut); !errors.Is(err, system.ErrSessionEnded) {
+		t.Errorf("Expected the session to end, got %v", err)
+	}
+	if session.Alive() {
+		t.Error("Expected the session shell to have ended")
+	}
+	if _, err := session.Run("true", &out, &errOut); !errors.Is(err, system.ErrSessionEnded) {
+		t.Errorf("Expected an ended session to refuse commands, got %v", err)
+	}
+}
+
+func TestSessionExecutor_RunsCommandsAndScripts(t *testing.T) {
+	session, dir := startTestSession(t)
+	executor := system.NewSessionExecutor(session)
+
+	if err := executor.Execute("export EMW_TEST_GREETING=hail && cd /", "sh"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.Dir() != "/" {
+		t.Errorf("Expected the cd to last, got %s", session.Dir())
+	}
+
+	// A script sees the session's directory and exports, but its own cd ends with it
+	script := filepath.Join(dir, "greeting.txt")
+	if err := executor.ExecuteScript("test \"$(pwd)\" = /\necho \"$EMW_TEST_GREETING\" > "+script+"\ncd /tmp", "sh", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(script); err != nil || string(data) != "hail\n" {
+		t.Errorf("Expected the script to see the exported variable, got %q and %v", data, err)
+	}
+	if session.Dir() != "/" {
+		t.Errorf("Expected the script's cd to end with it, got %s", session.Dir())
+	}
+
+	var execErr *system.ExecutionError
+	if err := executor.Execute("echo failing; exit_code() { return 7; }; exit_code", "sh"); !errors.As(err, &execErr) || execErr.ExitCode != 7 || !strings.Contains(execErr.Output, "failing") {
+		t.Errorf("Expected an execution error with status 7 and the output, got %v", err)
+	}
+}
+
+func TestShellRun_QuestsShareTheShell(t *testing.T) {
+	_, dir := startTestSession(t)
+	if err := os.Mkdir(filepath.Join(dir, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newPipelineFixture()
+	f.aiClient.NextResponses = []*ai.AIResponse{
+		{Type: ai.ResponseTypeCommand, Content: "cd api && export EMW_TEST_STAGE=test"},
+	}
+	f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: `echo "$EMW_TEST_STAGE" > stage.txt`}
+	// Outside a session the environment validator would block the cd and the export
+	f.envValidator.InvalidCommands = map[string]string{"cd api && export EMW_TEST_STAGE=test": "directory change"}
+	f.prompter.Answers = []string{"go to the api project in test mode", "", "note the stage", "exit", "never asked"}
+
+	run := &cli.ShellRun{
+		Deps:   f.deps(),
+		Config: newQuest("", "monarch").Config,
+		Start: func() (*system.ShellSession, error) {
+			return system.StartShellSession("bash", nil)
+		},
+	}
+	executed, err := run.Run()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if executed != 2 {
+		t.Errorf("Expected both quests to be carried out, got %d", executed)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "api", "stage.txt")); err != nil || string(data) != "test\n" {
+		t.Errorf("Expected the second quest to run where the first left off, got %q and %v", data, err)
+	}
+	if cwd, _ := os.Getwd(); cwd != filepath.Join(dir, "api") {
+		t.Errorf("Expected the knight to follow the session into api, got %s", cwd)
+	}
+	if len(f.prompter.Answers) != 1 {
+		t.Errorf("Expected the session to end at 'exit', %d answers left", len(f.prompter.Answers))
+	}
+}
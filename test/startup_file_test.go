@@ -0,0 +1,145 @@
+// File: test/startup_file_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestStartupFile(t *testing.T) {
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home := filepath.Join("/home", "arthur")
+
+	testCases := []struct {
+		shell    string
+		goos     string
+		expected string
+	}{
+		{shell: "/bin/bash", goos: "linux", expected: filepath.Join(home, ".bashrc")},
+		{shell: "/bin/bash", goos: "darwin", expected: filepath.Join(home, ".bash_profile")},
+		{shell: "/bin/zsh", goos: "darwin", expected: filepath.Join(home, ".zshrc")},
+		{shell: "/usr/bin/fish", goos: "linux", expected: filepath.Join(home, ".config", "fish", "config.fish")},
+		{shell: "/bin/dash", goos: "linux", expected: filepath.Join(home, ".profile")},
+		{shell: "pwsh", goos: "linux", expected: ""},
+		{shell: "cmd.exe", goos: "windows", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := system.StartupFile(tc.shell, home, tc.goos); got != tc.expected {
+			t.Errorf("%s on %s: expected %q, got %q", tc.shell, tc.goos, tc.expected, got)
+		}
+	}
+
+	t.Setenv("ZDOTDIR", filepath.Join(home, ".zsh"))
+	if got := system.StartupFile("zsh", home, "linux"); got != filepath.Join(home, ".zsh", ".zshrc") {
+		t.Errorf("Expected $ZDOTDIR to be honoured, got %q", got)
+	}
+}
+
+func TestPersistentEnvironmentLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		shell    string
+		expected []string
+		target   string
+	}{
+		{name: "export", command: `export PATH="$HOME/.local/bin:$PATH"`, shell: "bash", expected: []string{`export PATH="$HOME/.local/bin:$PATH"`}},
+		{name: "assignment and reload", command: `EDITOR=vim && source ~/.bashrc`, shell: "bash", expected: []string{"export EDITOR=vim"}},
+		{name: "alias", command: `alias ll='ls -la'`, shell: "zsh", expected: []string{`alias ll='ls -la'`}},
+		{name: "fish", command: `set -gx GOPATH ~/go; fish_add_path ~/go/bin`, shell: "fish", expected: []string{"set -gx GOPATH ~/go", "fish_add_path ~/go/bin"}},
+		{name: "echo into a startup file", command: `echo 'export PATH=$PATH:/opt/bin' >> ~/.zshrc && source ~/.zshrc`, shell: "zsh", expected: []string{"export PATH=$PATH:/opt/bin"}, target: "~/.zshrc"},
+		{name: "echo that expands", command: `echo "export PATH=$PATH:/opt/bin" >> ~/.bashrc`, shell: "bash"},
+		{name: "other steps", command: `mkdir -p ~/bin && export PATH=~/bin:$PATH`, shell: "bash"},
+		{name: "cd", command: `cd /tmp`, shell: "bash"},
+		{name: "PowerShell", command: `$env:PATH += ";C:\tools"`, shell: "pwsh"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, target := system.PersistentEnvironmentLines(tc.command, tc.shell)
+			if strings.Join(lines, "\n") != strings.Join(tc.expected, "\n") || target != tc.target {
+				t.Errorf("Expected %q into %q, got %q into %q", tc.expected, tc.target, lines, target)
+			}
+		})
+	}
+}
+
+func TestStartupFileEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	os.WriteFile(path, []byte("alias ll='ls -la'"), 0600)
+	at := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	edit, err := system.PlanStartupFileEdit(path, []string{"alias ll='ls -la'", "export EDITOR=vim"}, at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(edit.Lines) != 1 || edit.Lines[0] != "export EDITOR=vim" {
+		t.Fatalf("Expected only the missing line, got %q", edit.Lines)
+	}
+	if !strings.Contains(edit.Diff, "+export EDITOR=vim") || !strings.Contains(edit.Diff, "+# Added by execute-my-will on 2025-03-01") {
+		t.Errorf("Expected the diff to show the appended lines, got:\n%s", edit.Diff)
+	}
+
+	backup, err := edit.Apply(at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if original, _ := os.ReadFile(backup); string(original) != "alias ll='ls -la'" || !strings.HasSuffix(backup, ".emw-backup-20250301-093000") {
+		t.Errorf("Expected the original kept at a timestamped backup, got %q at %s", original, backup)
+	}
+	updated, _ := os.ReadFile(path)
+	if string(updated) != "alias ll='ls -la'\n\n# Added by execute-my-will on 2025-03-01\nexport EDITOR=vim\n" {
+		t.Errorf("Unexpected startup file:\n%s", updated)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode kept, got %v", info.Mode().Perm())
+	}
+
+	again, _ := system.PlanStartupFileEdit(path, []string{"export EDITOR=vim"}, at)
+	if len(again.Lines) != 0 || again.Diff != "" {
+		t.Errorf("Expected nothing to add the second time, got %q", again.Lines)
+	}
+}
+
+func TestPipeline_OffersToKeepABlockedExportInTheStartupFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, tc := range []struct {
+		name   string
+		choice int
+		added  bool
+	}{
+		{name: "added", choice: 0, added: true},
+		{name: "left to the user", choice: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := filepath.Join(home, ".config", "fish", "config.fish")
+			os.Remove(rc)
+			f := newPipelineFixture()
+			f.analyzer.SystemInfo = &system.Info{OS: "linux", Shell: "fish", HomeDir: home, CurrentDir: home}
+			f.aiClient.Response = &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "set -gx PATH ~/.cargo/bin $PATH"}
+			f.envValidator.InvalidCommands = map[string]string{"set -gx PATH ~/.cargo/bin $PATH": "export"}
+			f.prompter.Choices = []int{tc.choice}
+
+			if err := f.pipeline().Run(newQuest("add cargo to my PATH", "monarch")); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(f.executor.ExecutedCommands) != 0 {
+				t.Errorf("Expected nothing to run, got %v", f.executor.ExecutedCommands)
+			}
+			data, err := os.ReadFile(rc)
+			if tc.added != (err == nil && strings.Contains(string(data), "set -gx PATH ~/.cargo/bin $PATH")) {
+				t.Errorf("Expected added=%v, got %q (%v)", tc.added, data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// File: test/stdin_test.go
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestStdinIntent(t *testing.T) {
+	intent, err := cli.StdinIntent("  list the ten largest\nfiles in   my downloads\n\n")
+	if err != nil || intent != "list the ten largest files in my downloads" {
+		t.Errorf("Expected the lines joined into one intent, got %q and %v", intent, err)
+	}
+	if _, err := cli.StdinIntent("\n \t\n"); err == nil {
+		t.Error("Expected nothing on stdin to be refused")
+	}
+}
+
+func TestPipedContext(t *testing.T) {
+	cfg := &config.Config{APIKey: "configured-key-1234", Mode: "monarch"}
+	log := "Step 1/4 : FROM node:20\r\nStep 2/4 : RUN npm ci\r\nnpm ERR! 401 Unauthorized https://registry.example.com/?token=abc123def456\r\n"
+
+	text := cli.PipedContext(log, cfg)
+	if !strings.HasPrefix(text, "Step 1/4 : FROM node:20\nStep 2/4") || strings.Contains(text, "\r") {
+		t.Errorf("Expected the piped lines with plain line endings, got %q", text)
+	}
+	if strings.Contains(text, "abc123def456") || !strings.Contains(text, "[REDACTED]") {
+		t.Errorf("Expected the token to be redacted, got %q", text)
+	}
+	if text := cli.PipedContext("\n\n", cfg); text != "" {
+		t.Errorf("Expected empty input to be no context, got %q", text)
+	}
+}
+
+func TestTrimPiped_KeepsTheEnd(t *testing.T) {
+	long := strings.Repeat("building...\n", system.MaxClipboardChars) + "error: linker failed"
+	text, cut := system.TrimPiped(long)
+	if !cut || len([]rune(text)) != system.MaxClipboardChars || !strings.HasSuffix(text, "error: linker failed") {
+		t.Errorf("Expected the last %d characters with the failure, got %d (cut=%v)", system.MaxClipboardChars, len([]rune(text)), cut)
+	}
+	if text, cut := system.TrimPiped("exit status 1\n"); cut || text != "exit status 1" {
+		t.Errorf("Expected short input to be kept whole, got %q (cut=%v)", text, cut)
+	}
+}
+
+func TestReadStdin_ReattachesStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("panic: runtime error: index out of range\n")
+	w.Close()
+	original := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = original })
+
+	if !system.StdinPiped() {
+		t.Fatal("Expected a pipe on stdin to be seen")
+	}
+	text, err := system.ReadStdin()
+	if err != nil || text != "panic: runtime error: index out of range\n" {
+		t.Errorf("Expected the piped text, got %q and %v", text, err)
+	}
+	if os.Stdin == r || system.StdinPiped() {
+		t.Error("Expected stdin to be reattached to the terminal, or left empty without one")
+	}
+}
+
+func TestPipeline_SendsPipedInputWithIntent(t *testing.T) {
+	f := newPipelineFixture()
+	quest := newQuest("figure out why this failed", "monarch")
+	quest.Piped = "npm ERR! code E401\nnpm ERR! Unable to authenticate"
+
+	if err := f.pipeline().Run(quest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(f.aiClient.LastIntent, "figure out why this failed") || !strings.Contains(f.aiClient.LastIntent, "PIPED INPUT") || !strings.Contains(f.aiClient.LastIntent, "npm ERR! code E401\nnpm ERR! Unable to authenticate") {
+		t.Errorf("Expected the piped text after the intent, got %q", f.aiClient.LastIntent)
+	}
+}
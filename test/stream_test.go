@@ -0,0 +1,141 @@
+// File: test/stream_test.go
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// recordingStreamHandler keeps every answer it is shown, one entry per Begin
+type recordingStreamHandler struct {
+	answers []string
+	ended   int
+}
+
+func (h *recordingStreamHandler) Begin()            { h.answers = append(h.answers, "") }
+func (h *recordingStreamHandler) Chunk(text string) { h.answers[len(h.answers)-1] += text }
+func (h *recordingStreamHandler) End()              { h.ended++ }
+
+// streamServer replies to streamed chat completions with each answer in turn, split into the
+// given pieces, and records whether streaming was asked for
+func streamServer(t *testing.T, answers ...[]string) (*httptest.Server, *[]bool) {
+	t.Helper()
+	var streamed []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &request)
+		streamed = append(streamed, request["stream"] == true)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		for _, piece := range answers[min(len(streamed)-1, len(answers)-1)] {
+			chunk, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]string{"content": piece}}}})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server, &streamed
+}
+
+func TestOpenAIProvider_StreamResponse(t *testing.T) {
+	server, streamed := streamServer(t, []string{"SCRIPT:\n```bash\n", "mkdir -p backups\n", "tar -czf backups/docs.tgz ~/Documents\n```"})
+	provider, _ := ai.NewOpenAIProvider(compatibleConfig(server.URL, ""))
+
+	var pieces []string
+	response, err := provider.StreamResponse("back up my documents", func(chunk string) { pieces = append(pieces, chunk) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pieces) != 3 || response != strings.Join(pieces, "") {
+		t.Errorf("Expected the answer in three pieces adding up to the whole, got %q and %q", pieces, response)
+	}
+	if len(*streamed) != 1 || !(*streamed)[0] {
+		t.Errorf("Expected a streamed request, got %v", *streamed)
+	}
+}
+
+func TestOpenAIProvider_StreamResponseErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{name: "rejected before the stream", status: http.StatusUnauthorized, body: `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`, expected: "OpenAI API error: Incorrect API key provided"},
+		{name: "error event in the stream", status: http.StatusOK, body: "data: {\"choices\":[{\"delta\":{\"content\":\"COMMAND: ls\"}}]}\n\ndata: {\"error\":{\"message\":\"The server had an error\"}}\n\n", expected: "OpenAI API error: The server had an error"},
+		{name: "empty stream", status: http.StatusOK, body: "data: [DONE]\n\n", expected: "no response generated"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			provider, _ := ai.NewOpenAIProvider(compatibleConfig(server.URL, "sk-key"))
+			if _, err := provider.StreamResponse("list files", func(string) {}); err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestClient_StreamResponse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // usage and parse statistics are kept next to the config
+	server, streamed := streamServer(t,
+		[]string{"COMMAND: find / -name ", `"*.log`},
+		[]string{"COMMAND: find / -name ", `"*.log"`},
+	)
+	client, err := ai.NewClient(compatibleConfig(server.URL, ""))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := &recordingStreamHandler{}
+	response, err := client.StreamResponse("find log files", &system.Info{OS: "linux", Shell: "bash"}, handler)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Type != ai.ResponseTypeCommand || response.Content != `find / -name "*.log"` {
+		t.Errorf("Expected the complete second answer, got %+v", response)
+	}
+	if len(handler.answers) != 2 || handler.ended != 2 || handler.answers[1] != `COMMAND: find / -name "*.log"` {
+		t.Errorf("Expected the cut-off answer and its retry to be shown separately, got %q (%d ended)", handler.answers, handler.ended)
+	}
+	if len(*streamed) != 2 || !(*streamed)[1] {
+		t.Errorf("Expected both requests to be streamed, got %v", *streamed)
+	}
+	if exchanges := client.Exchanges(); len(exchanges) != 2 || exchanges[1].Response != handler.answers[1] {
+		t.Errorf("Expected streamed answers in the transcript, got %+v", exchanges)
+	}
+}
+
+func TestClient_StreamResponseFallsBackWithoutStreaming(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	client, err := ai.NewClient(mockConfig(filepath.Join("..", "docs", "mock-responses.yaml")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := &recordingStreamHandler{}
+	response, err := client.StreamResponse("list all files here", &system.Info{OS: "linux", Shell: "bash"}, handler)
+	if err != nil || response.Content != "ls -la" {
+		t.Fatalf("Expected the mock's answer, got %+v and %v", response, err)
+	}
+	if len(handler.answers) != 1 || handler.answers[0] != "COMMAND: ls -la" {
+		t.Errorf("Expected the whole answer shown at once, got %q", handler.answers)
+	}
+}
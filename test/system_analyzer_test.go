@@ -2,6 +2,7 @@
 package test
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/minand-mohan/execute-my-will/internal/system"
@@ -152,3 +153,23 @@ func TestSystemInfo_Structure(t *testing.T) {
 		t.Errorf("Expected AvailableCommands ['test-command'], got %v", info.AvailableCommands)
 	}
 }
+
+func TestQuickAnalyzer_SkipsEnumeration(t *testing.T) {
+	info, err := system.NewQuickAnalyzer().AnalyzeSystem()
+	if err != nil {
+		t.Fatalf("Quick analysis failed: %v", err)
+	}
+
+	if !info.Quick {
+		t.Error("Quick analysis should mark the info as quick")
+	}
+	if info.OS != runtime.GOOS {
+		t.Errorf("Expected OS %s, got %s", runtime.GOOS, info.OS)
+	}
+	if info.Shell == "" || info.CurrentDir == "" {
+		t.Errorf("Quick analysis should still detect the shell and current directory, got %+v", info)
+	}
+	if len(info.InstalledPackages) != 0 || len(info.AvailableCommands) != 0 || len(info.PackageManagers) != 0 {
+		t.Errorf("Quick analysis should not enumerate packages or commands, got %+v", info)
+	}
+}
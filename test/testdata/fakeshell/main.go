@@ -0,0 +1,211 @@
+// File: test/testdata/fakeshell/main.go
+
+// Command fakeshell stands in for sh, bash, cmd, and PowerShell in executor tests. It accepts the
+// arguments the executor passes to each of them ("-c CMD", "/C CMD", "/C script.bat",
+// "-File script.ps1", or a script path) and runs a tiny language, one statement per line or
+// between ";" and "&&":
+//
+//	echo TEXT          print TEXT without its quotes; "echo TEXT >&2" prints it to stderr
+//	Write-Host TEXT    the same, for PowerShell scripts
+//	exit N             stop with exit status N
+//	pwd                print the working directory
+//	printenv NAME      print an environment variable
+//	cat                copy stdin to stdout
+//	wait-for-signal    create the file named by FAKESHELL_READY, wait for an interrupt or a
+//	                   termination request, print which one arrived, and exit with status 130
+//
+// The bookkeeping of the executor's script wrappers (set, setlocal, @echo off, $Variable = ...,
+// try) is accepted and ignored, and the blocks that only run on failure ("} catch {",
+// "if ... (") are skipped. "set -e" stops at the first failing statement. Any other command
+// fails with status 127, like a real shell. When FAKESHELL_LOG is set, the arguments of every
+// invocation are appended to it, one per line, followed by a blank line.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	args := os.Args[1:]
+	if path := os.Getenv("FAKESHELL_LOG"); path != "" {
+		if file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600); err == nil {
+			fmt.Fprintf(file, "%s\n\n", strings.Join(args, "\n"))
+			file.Close()
+		}
+	}
+	os.Exit(run(program(args)))
+}
+
+// program returns the text to run from the shell's arguments
+func program(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "-noprofile":
+			continue
+		case "-c", "/c", "-command", "-file":
+			if i+1 < len(args) {
+				return source(args[i+1])
+			}
+			return ""
+		default:
+			return source(args[i])
+		}
+	}
+	return ""
+}
+
+// source reads a script file, or returns the argument itself when it is a command
+func source(arg string) string {
+	ext := strings.ToLower(filepath.Ext(arg))
+	if ext == ".sh" || ext == ".bat" || ext == ".ps1" {
+		if data, err := os.ReadFile(arg); err == nil {
+			return string(data)
+		}
+	}
+	return arg
+}
+
+func run(text string) int {
+	status := 0
+	errexit := false
+	skipping := 0
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if skipping > 0 {
+			if line == "}" || line == ")" {
+				skipping--
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "} catch") || (strings.HasPrefix(strings.ToLower(line), "if ") && strings.HasSuffix(line, "(")) {
+			skipping++
+			continue
+		}
+
+		previous := "" // the separator before the statement
+		for _, statement := range splitStatements(line) {
+			if previous == "&&" && status != 0 {
+				break
+			}
+			previous = statement.separator
+			status = execute(statement.text, &errexit)
+			if status != 0 && errexit {
+				return status
+			}
+		}
+	}
+	return status
+}
+
+type statement struct {
+	text      string
+	separator string // ";" or "&&" after the statement
+}
+
+// splitStatements splits a line at the ";" and "&&" outside quotes
+func splitStatements(line string) []statement {
+	var statements []statement
+	var current strings.Builder
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';':
+			statements = append(statements, statement{strings.TrimSpace(current.String()), ";"})
+			current.Reset()
+			continue
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			statements = append(statements, statement{strings.TrimSpace(current.String()), "&&"})
+			current.Reset()
+			i++
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(statements, statement{strings.TrimSpace(current.String()), ""})
+}
+
+func execute(text string, errexit *bool) int {
+	name, rest, _ := strings.Cut(text, " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(name) {
+	case "", "try", "{", "}", ")", "@echo", "setlocal", "rem":
+		return 0
+	case "set":
+		if rest == "-e" {
+			*errexit = true
+		}
+		return 0
+	case "echo", "write-host":
+		if strings.HasSuffix(rest, ">&2") {
+			fmt.Fprintln(os.Stderr, unquote(strings.TrimSpace(strings.TrimSuffix(rest, ">&2"))))
+			return 0
+		}
+		fmt.Println(unquote(rest))
+		return 0
+	case "exit":
+		code, err := strconv.Atoi(rest)
+		if err != nil {
+			code = 0
+		}
+		os.Exit(code)
+	case "pwd":
+		dir, _ := os.Getwd()
+		fmt.Println(dir)
+		return 0
+	case "printenv":
+		fmt.Println(os.Getenv(rest))
+		return 0
+	case "cat":
+		_, _ = io.Copy(os.Stdout, os.Stdin)
+		return 0
+	case "wait-for-signal":
+		return waitForSignal()
+	}
+	if strings.HasPrefix(name, "$") {
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "fakeshell: %s: command not found\n", name)
+	return 127
+}
+
+// unquote removes the quotes around the text and, for Write-Host, the options after it
+func unquote(text string) string {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') {
+		if end := strings.IndexByte(text[1:], text[0]); end >= 0 {
+			return text[1 : end+1]
+		}
+	}
+	return text
+}
+
+func waitForSignal() int {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	if path := os.Getenv("FAKESHELL_READY"); path != "" {
+		_ = os.WriteFile(path, nil, 0o600)
+	}
+	select {
+	case sig := <-signals:
+		fmt.Printf("received %s\n", sig)
+		return 130
+	case <-time.After(10 * time.Second):
+		fmt.Println("no signal arrived")
+		return 1
+	}
+}
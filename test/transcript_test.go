@@ -0,0 +1,84 @@
+// File: test/transcript_test.go
+package test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func newFailedQuest() *cli.Quest {
+	return &cli.Quest{
+		Intent:   "deploy with token=abc123 from /home/arthur/app",
+		Config:   &config.Config{AIProvider: "openai", Model: "gpt-4", Mode: "monarch", APIKey: "configured-key-1234"},
+		SysInfo:  &system.Info{OS: "linux", Shell: "/bin/bash", HomeDir: "/home/arthur", PackageManagers: []string{"apt"}, InstalledPackages: []string{"git", "curl"}},
+		Content:  "./deploy.sh",
+		Approved: true,
+		Executed: true,
+		ExecErr: &system.ExecutionError{
+			Err:      errors.New("exit status 2"),
+			ExitCode: 2,
+			Output:   "login failed for key configured-key-1234",
+		},
+	}
+}
+
+func TestNewTranscript_Redacts(t *testing.T) {
+	exchanges := []ai.Exchange{{Prompt: "Home Directory: /home/arthur", Response: "COMMAND: ./deploy.sh"}}
+	transcript := cli.NewTranscript(newFailedQuest(), exchanges, nil, "1.2.3")
+
+	markdown := transcript.Markdown()
+	for _, leaked := range []string{"abc123", "configured-key-1234", "/home/arthur"} {
+		if strings.Contains(markdown, leaked) {
+			t.Errorf("Report leaks %q:\n%s", leaked, markdown)
+		}
+	}
+	for _, expected := range []string{"| Version | 1.2.3 |", "| Outcome | failed |", "| Exit code | 2 |", "| Installed packages | 2 detected |", "### Exchange 1", "COMMAND: ./deploy.sh"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Report should contain %q:\n%s", expected, markdown)
+		}
+	}
+	if exchanges[0].Prompt != "Home Directory: /home/arthur" {
+		t.Error("NewTranscript should not modify the recorded exchanges")
+	}
+}
+
+func TestTranscript_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), cli.TranscriptFile)
+
+	missing, err := cli.LoadTranscript(path)
+	if err != nil || missing != nil {
+		t.Fatalf("Expected no transcript and no error, got %v, %v", missing, err)
+	}
+
+	original := cli.NewTranscript(newFailedQuest(), nil, errors.New("pipeline broke"), "1.2.3")
+	if err := original.Save(path); err != nil {
+		t.Fatalf("Failed to save transcript: %v", err)
+	}
+
+	loaded, err := cli.LoadTranscript(path)
+	if err != nil {
+		t.Fatalf("Failed to load transcript: %v", err)
+	}
+	if loaded.Markdown() != original.Markdown() {
+		t.Errorf("Loaded transcript differs:\n%s\n---\n%s", loaded.Markdown(), original.Markdown())
+	}
+	if loaded.Outcome() != "stopped by an error" {
+		t.Errorf("Expected pipeline error outcome, got %q", loaded.Outcome())
+	}
+}
+
+func TestTranscript_MarkdownKeepsEmbeddedFences(t *testing.T) {
+	quest := &cli.Quest{Intent: "set up", Content: "```bash\necho hi\n```", IsScript: true}
+	markdown := cli.NewTranscript(quest, nil, nil, "dev").Markdown()
+
+	if !strings.Contains(markdown, "## Final script\n\n````\n```bash") {
+		t.Errorf("Script fences should be wrapped in a longer fence:\n%s", markdown)
+	}
+}
@@ -0,0 +1,113 @@
+// File: test/transfer_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestParseSSHConfigHosts(t *testing.T) {
+	sshConfig := `# personal machines
+Host nas
+    HostName 192.168.1.20
+    User admin
+    IdentityFile ~/.ssh/id_ed25519
+
+Host staging staging-db
+  HostName staging.example.com
+
+host=builder
+Host *
+  ServerAliveInterval 60
+Host *.internal !bastion
+Host nas
+`
+	hosts := system.ParseSSHConfigHosts(strings.NewReader(sshConfig))
+	expected := "nas,staging,staging-db,builder"
+	if strings.Join(hosts, ",") != expected {
+		t.Errorf("Expected hosts %s, got %v", expected, hosts)
+	}
+}
+
+func TestIsTransferIntent(t *testing.T) {
+	hosts := []string{"atlas"}
+	testCases := []struct {
+		intent   string
+		expected bool
+	}{
+		{"copy my photos to the NAS", true},
+		{"sync the project folder to atlas", true},
+		{"download the logs from the server", true},
+		{"copy file.txt to backup directory", false},
+		{"list my files", false},
+	}
+
+	for _, tc := range testCases {
+		if result := system.IsTransferIntent(tc.intent, hosts); result != tc.expected {
+			t.Errorf("IsTransferIntent(%q) = %v, want %v", tc.intent, result, tc.expected)
+		}
+	}
+}
+
+func TestTransferDirectionAndHost(t *testing.T) {
+	if system.GuessTransferDirection("copy my photos to the nas") != system.TransferUpload {
+		t.Error("Expected upload for 'to the nas'")
+	}
+	if system.GuessTransferDirection("fetch the logs from the server") != system.TransferDownload {
+		t.Error("Expected download for 'from the server'")
+	}
+
+	hosts := []string{"nas", "staging"}
+	if host := system.MentionedHost("copy photos to the NAS", hosts); host != "nas" {
+		t.Errorf("Expected 'nas', got %q", host)
+	}
+	if host := system.MentionedHost("copy from staging to nas", hosts); host != "" {
+		t.Errorf("Ambiguous intents should not pick a host, got %q", host)
+	}
+}
+
+func TestFindTransferCandidates(t *testing.T) {
+	home := t.TempDir()
+	work := t.TempDir()
+	for _, dir := range []string{filepath.Join(home, "Pictures"), filepath.Join(work, "holiday-photos"), filepath.Join(work, "notes")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	candidates := system.FindTransferCandidates("copy my holiday photos to the nas", work, home)
+	expected := []string{filepath.Join(home, "Pictures"), filepath.Join(work, "holiday-photos")}
+	if strings.Join(candidates, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+}
+
+func TestBuildTransferCommand(t *testing.T) {
+	testCases := []struct {
+		name      string
+		useRsync  bool
+		direction system.TransferDirection
+		local     string
+		remote    string
+		isDir     bool
+		expected  string
+	}{
+		{"rsync upload", true, system.TransferUpload, "/home/me/Pictures", "/volume1/photos/", true, "rsync -avh --progress /home/me/Pictures nas:/volume1/photos/"},
+		{"rsync download", true, system.TransferDownload, ".", "~/logs", true, "rsync -avh --progress nas:~/logs ."},
+		{"scp file", false, system.TransferUpload, "report.pdf", "~/", false, "scp report.pdf nas:~/"},
+		{"scp directory with spaces", false, system.TransferUpload, "/home/me/My Photos", "~/My Photos", true, "scp -r '/home/me/My Photos' nas:~/'My Photos'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command := system.BuildTransferCommand(tc.useRsync, tc.direction, tc.local, "nas", tc.remote, tc.isDir)
+			if command != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, command)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// File: test/truncation_test.go
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+// answerServer replies to chat completions with the given answers in turn, repeating the last
+func answerServer(t *testing.T, answers ...string) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		answer := answers[min(requests, len(answers)-1)]
+		requests++
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_RetriesTruncatedResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // usage and parse statistics are kept next to the config
+	server, requests := answerServer(t, "SCRIPT:\n```bash\nmkdir -p backups\ntar -czf backups/home.tgz ~/Doc", "COMMAND: tar -czf backups.tgz ~/Documents")
+	retries := 0
+	original := ai.OnTruncatedRetry
+	ai.OnTruncatedRetry = func() { retries++ }
+	t.Cleanup(func() { ai.OnTruncatedRetry = original })
+	client, err := ai.NewClient(azureConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	response, err := client.GenerateResponse("back up my documents", &system.Info{OS: "linux", Shell: "bash"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Type != ai.ResponseTypeCommand || response.Content != "tar -czf backups.tgz ~/Documents" {
+		t.Errorf("Expected the complete answer of the second request, got %+v", response)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected the same prompt to be sent twice, got %d requests", *requests)
+	}
+	if retries != 1 {
+		t.Errorf("Expected the retry to be reported once through the hook, got %d", retries)
+	}
+
+	stats, _ := ai.LoadParseStats(config.StatePath(ai.ParseStatsFile))
+	if m := stats.Models[ai.StatsKey("azure-openai", "gpt-4o")]; m == nil || m.Truncations != 1 {
+		t.Errorf("Expected the cut-off answer to be counted, got %+v", m)
+	}
+}
+
+func TestClient_DiscardsRepeatedlyTruncatedResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, requests := answerServer(t, `COMMAND: find / -name "*.log`)
+	client, err := ai.NewClient(azureConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	response, err := client.GenerateResponse("find log files", &system.Info{OS: "linux", Shell: "bash"})
+	if err == nil || !strings.Contains(err.Error(), "cut off") {
+		t.Fatalf("Expected the half command to be discarded, got %+v and %v", response, err)
+	}
+	if *requests != 3 {
+		t.Errorf("Expected the first request and two retries, got %d requests", *requests)
+	}
+}
+
+func TestIsTruncatedResponse(t *testing.T) {
+	testCases := []struct {
+		response  string
+		truncated bool
+	}{
+		{response: "COMMAND: echo 'it''s done'", truncated: false},
+		{response: "COMMAND: awk '{print $1}' access.log", truncated: false},
+		{response: "COMMAND: echo \"escaped \\\" quote\"", truncated: false},
+		{response: "COMMAND: `ls -la`", truncated: false},
+		{response: "COMMAND: awk '{print $1", truncated: true},
+		{response: "COMMAND: make build &&", truncated: true},
+		{response: "COMMAND: Get-ChildItem -Recurse `", truncated: true},
+		{response: "FAILURE:", truncated: false},
+		{response: "ANSWER:  ", truncated: true},
+		{response: "SCRIPT:\n```powershell\nNew-Item -ItemType Directory logs\n```", truncated: false},
+		{response: "SCR", truncated: true},
+	}
+
+	for _, tc := range testCases {
+		if got := ai.IsTruncatedResponse(tc.response); got != tc.truncated {
+			t.Errorf("%q: expected truncated %v, got %v", tc.response, tc.truncated, got)
+		}
+	}
+}
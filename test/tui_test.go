@@ -0,0 +1,176 @@
+// File: test/tui_test.go
+package test
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+	"github.com/minand-mohan/execute-my-will/internal/tui"
+)
+
+// reviewInTUI types an intent into the TUI and hands it the oracle's command for review
+func reviewInTUI(t *testing.T, cfg *config.Config, sysInfo *system.Info, command string, hooks tui.Hooks) *tui.Model {
+	t.Helper()
+	response := &ai.AIResponse{Type: ai.ResponseTypeCommand, Content: command}
+	model := tui.NewModel(cfg, sysInfo, &MockAIClient{Response: response}, hooks)
+	model.Update(tea.WindowSizeMsg{Width: 240, Height: 40})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("clean up")})
+	_, generate := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if generate == nil {
+		t.Fatal("Expected the intent to be sent to the oracle")
+	}
+	model.Update(generate())
+	if !strings.Contains(model.View(), command) {
+		t.Fatalf("Expected the proposal under review, got:\n%s", model.View())
+	}
+	return model
+}
+
+func tuiConfig() *config.Config {
+	return &config.Config{APIKey: "test-key", Mode: "monarch", Execution: config.ExecutionConfig{KillSwitch: "/nonexistent/emw-kill-switch"}}
+}
+
+func TestTUI_RefusesDestructiveQuestWithoutReauthentication(t *testing.T) {
+	cfg := tuiConfig()
+	cfg.Execution.Reauthenticate = true
+	hooks := tui.Hooks{Risk: func(tui.Proposal) system.RiskLevel { return system.RiskDestructive }}
+	model := reviewInTUI(t, cfg, &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, "rm -rf build", hooks)
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}); cmd != nil {
+		t.Error("Expected nothing to run without a way to re-authenticate")
+	}
+	if view := model.View(); !strings.Contains(view, "Run this one from the command line") {
+		t.Errorf("Expected the refusal in the status line, got:\n%s", view)
+	}
+}
+
+func TestTUI_ReauthenticatesOnlyDestructiveQuests(t *testing.T) {
+	cfg := tuiConfig()
+	cfg.Execution.Reauthenticate = true
+	hooks := tui.Hooks{Risk: func(tui.Proposal) system.RiskLevel { return system.RiskReadOnly }}
+	model := reviewInTUI(t, cfg, &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, "ls", hooks)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if view := model.View(); !strings.Contains(view, "Executing your quest") {
+		t.Errorf("Expected a read-only quest to run without re-authentication, got:\n%s", view)
+	}
+}
+
+func TestTUI_CriticalDirectoryNeedsTypedDirectory(t *testing.T) {
+	sysInfo := &system.Info{Shell: "sh", OS: "linux", CurrentDir: "/"}
+	model := reviewInTUI(t, tuiConfig(), sysInfo, "rm -rf *", tui.Hooks{})
+	if view := model.View(); !strings.Contains(view, "a directory your system depends on") {
+		t.Errorf("Expected a critical directory warning, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/tmp")})
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("Expected nothing to run when another directory is typed")
+	}
+	if view := model.View(); !strings.Contains(view, "was not typed") {
+		t.Errorf("Expected the quest to be declined, got:\n%s", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || !strings.Contains(model.View(), "Executing your quest") {
+		t.Errorf("Expected the quest to run once the directory is typed, got:\n%s", model.View())
+	}
+}
+
+// runInTUI carries out the command returned by an approval, feeding its output to the model
+func runInTUI(t *testing.T, model *tui.Model, approve tea.Cmd) {
+	t.Helper()
+	batch, ok := approve().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatal("Expected the approval to start the command and read its output")
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- batch[1]() }()
+	for next := batch[0]; next != nil; {
+		msg := next()
+		if msg == nil {
+			break
+		}
+		_, next = model.Update(msg)
+	}
+	select {
+	case msg := <-done:
+		model.Update(msg)
+	case <-time.After(10 * time.Second):
+		t.Fatal("The command never finished")
+	}
+}
+
+func TestTUI_LongOutputLines(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	cfg := tuiConfig()
+	cfg.Execution.MaxLineSize = 1000
+	command := "head -c 200000 /dev/zero | tr '\\0' x; echo; echo finished"
+	model := reviewInTUI(t, cfg, &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, command, tui.Hooks{})
+
+	_, approve := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	runInTUI(t, model, approve)
+	if view := model.View(); !strings.Contains(view, "finished") || !strings.Contains(view, "Quest completed") {
+		t.Errorf("Expected the output after the long line and the quest to complete, got:\n%s", view)
+	}
+}
+
+func TestTUI_RecordsFinishedQuests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	var recorded []tui.Proposal
+	var runErr error
+	hooks := tui.Hooks{Record: func(p tui.Proposal, err error) {
+		recorded, runErr = append(recorded, p), err
+	}}
+	model := reviewInTUI(t, tuiConfig(), &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, "exit 3", hooks)
+
+	_, approve := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	runInTUI(t, model, approve)
+	if len(recorded) != 1 || recorded[0].Intent != "clean up" || recorded[0].Content != "exit 3" {
+		t.Fatalf("Expected the finished quest to be recorded once, got %+v", recorded)
+	}
+	var execErr *system.ExecutionError
+	if !errors.As(runErr, &execErr) || execErr.ExitCode != 3 {
+		t.Errorf("Expected the exit code with the failure, got %v", runErr)
+	}
+}
+
+func TestTUI_WarnsOfRecentDestructiveRun(t *testing.T) {
+	store, _ := history.Load(filepath.Join(t.TempDir(), history.HistoryFile))
+	ranAt := time.Now().Add(-time.Hour)
+	store.Record("drop the staging table", "", "psql -c 'DROP TABLE staging'", false, nil, ranAt)
+	hooks := tui.Hooks{
+		Risk:    func(tui.Proposal) system.RiskLevel { return system.RiskDestructive },
+		History: store,
+		Now:     func() time.Time { return ranAt.Add(3 * time.Minute) },
+	}
+
+	model := reviewInTUI(t, tuiConfig(), &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, "psql -c 'DROP TABLE staging'", hooks)
+	if view := model.View(); !strings.Contains(view, "You ran this exact command 3 min 00 s ago") || !strings.Contains(view, "drop the staging table") {
+		t.Errorf("Expected a warning about the run moments ago, got:\n%s", view)
+	}
+
+	hooks.Risk = func(tui.Proposal) system.RiskLevel { return system.RiskModifies }
+	model = reviewInTUI(t, tuiConfig(), &system.Info{Shell: "sh", CurrentDir: t.TempDir()}, "psql -c 'DROP TABLE staging'", hooks)
+	if strings.Contains(model.View(), "You ran this exact") {
+		t.Error("Expected no warning for a quest that is not destructive")
+	}
+}
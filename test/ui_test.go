@@ -0,0 +1,254 @@
+// File: test/ui_test.go
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/minand-mohan/execute-my-will/internal/ui"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    ui.Verbosity
+		shouldError bool
+	}{
+		{input: "minimal", expected: ui.VerbosityMinimal},
+		{input: "normal", expected: ui.VerbosityNormal},
+		{input: "", expected: ui.VerbosityNormal},
+		{input: "FESTIVE", expected: ui.VerbosityFestive},
+		{input: "chatty", expected: ui.VerbosityNormal, shouldError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := ui.ParseVerbosity(tc.input)
+			if tc.shouldError != (err != nil) {
+				t.Errorf("ParseVerbosity(%q) error = %v, shouldError %v", tc.input, err, tc.shouldError)
+			}
+			if v != tc.expected {
+				t.Errorf("ParseVerbosity(%q) = %v, expected %v", tc.input, v, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSetVerbosity(t *testing.T) {
+	defer ui.SetVerbosity(ui.VerbosityNormal)
+
+	ui.SetVerbosity(ui.VerbosityMinimal)
+	if !ui.IsMinimal() || ui.IsFestive() {
+		t.Error("Expected minimal verbosity to be active")
+	}
+
+	ui.SetVerbosity(ui.VerbosityFestive)
+	if !ui.IsFestive() || ui.IsMinimal() {
+		t.Error("Expected festive verbosity to be active")
+	}
+
+	if ui.GetVerbosity().String() != "festive" {
+		t.Errorf("Expected 'festive', got '%s'", ui.GetVerbosity().String())
+	}
+}
+
+func streamForTest(t *testing.T, input string, maxLineSize int) (string, *ui.OutputHighlighter) {
+	t.Helper()
+	var out bytes.Buffer
+	highlighter := ui.NewOutputHighlighter(false, 0)
+	highlighter.SetOutput(&out)
+	highlighter.SetMaxLineSize(maxLineSize)
+
+	if err := highlighter.StreamOutput(strings.NewReader(input), ""); err != nil {
+		t.Fatalf("StreamOutput failed: %v", err)
+	}
+	return out.String(), highlighter
+}
+
+func TestStreamOutput_LongLines(t *testing.T) {
+	// Longer than bufio.Scanner's 64KB limit
+	long := strings.Repeat("{\"k\":1}", 30000)
+	out, highlighter := streamForTest(t, long+"\nshort line\n", ui.DefaultMaxLineSize)
+
+	if !strings.Contains(out, long) || !strings.Contains(out, "short line") {
+		t.Error("Expected both lines to be printed in full")
+	}
+	if highlighter.LineCount() != 2 {
+		t.Errorf("Expected 2 lines, got %d", highlighter.LineCount())
+	}
+	if tail := highlighter.Tail(); len(tail[0]) > 600 || !strings.HasSuffix(tail[0], "…") {
+		t.Errorf("Expected the tail to keep a shortened copy of the long line, got %d bytes", len(tail[0]))
+	}
+}
+
+func TestStreamOutput_MaxLineSize(t *testing.T) {
+	out, _ := streamForTest(t, strings.Repeat("a", 50000)+"\n", 1000)
+
+	if strings.Count(out, "a") != 1000 {
+		t.Errorf("Expected 1000 bytes to be printed, got %d", strings.Count(out, "a"))
+	}
+	if !strings.Contains(out, "47.9 KB more not shown") {
+		t.Errorf("Expected a note about the skipped bytes, got %q", out[len(out)-60:])
+	}
+}
+
+func TestStreamOutput_MultiByteAcrossChunks(t *testing.T) {
+	out, _ := streamForTest(t, "x"+strings.Repeat("é", 20000)+"\n", ui.DefaultMaxLineSize)
+
+	if !utf8.ValidString(out) || strings.Count(out, "é") != 20000 {
+		t.Error("Expected every character to survive chunking intact")
+	}
+	if strings.Contains(out, "binary") {
+		t.Error("Text split across chunks should not be taken for binary output")
+	}
+}
+
+func TestStreamOutput_Binary(t *testing.T) {
+	input := "header line\n" + string([]byte{0x7f, 'E', 'L', 'F', 0x02, 0x01, 0x00, 0x00, 0xff, 0xfe, 0x1b, '[', '2', 'J'}) + "\nmore\x00data\n"
+	out, highlighter := streamForTest(t, input, ui.DefaultMaxLineSize)
+
+	if strings.ContainsAny(out, "\x00\x7f") || strings.Contains(out, "ELF") {
+		t.Errorf("Binary data should not be printed: %q", out)
+	}
+	if !strings.Contains(out, "header line") || !strings.Contains(out, "[binary output hidden: ") {
+		t.Errorf("Expected the text line and a binary note, got %q", out)
+	}
+	if tail := highlighter.Tail(); !strings.HasPrefix(tail[len(tail)-1], "[binary output hidden") {
+		t.Errorf("Expected the binary note in the tail, got %v", tail)
+	}
+}
+
+func TestStreamOutput_ANSIPassthrough(t *testing.T) {
+	colored := "\x1b[32m✓ 12 tests passed\x1b[0m \x1b[2m(1.2s)\x1b[22m"
+	out, highlighter := streamForTest(t, colored+"\nplain error line\n", ui.DefaultMaxLineSize)
+
+	if !strings.Contains(out, colored+"\n") {
+		t.Errorf("Colored line should pass through untouched, got %q", out)
+	}
+	if tail := highlighter.Tail(); tail[0] != "✓ 12 tests passed (1.2s)" {
+		t.Errorf("Tail should keep the visible text only, got %q", tail[0])
+	}
+}
+
+func TestANSIHelpers(t *testing.T) {
+	testCases := []struct {
+		input    string
+		hasANSI  bool
+		stripped string
+	}{
+		{input: "plain text", stripped: "plain text"},
+		{input: "\x1b[1;31mred\x1b[0m", hasANSI: true, stripped: "red"},
+		{input: "\x1b[2K\x1b[1Gprogress 50%", hasANSI: true, stripped: "progress 50%"},
+		{input: "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", hasANSI: true, stripped: "link"},
+	}
+
+	for _, tc := range testCases {
+		if ui.HasANSI(tc.input) != tc.hasANSI {
+			t.Errorf("HasANSI(%q) = %v, want %v", tc.input, !tc.hasANSI, tc.hasANSI)
+		}
+		if stripped := ui.StripANSISequences(tc.input); stripped != tc.stripped {
+			t.Errorf("StripANSISequences(%q) = %q, want %q", tc.input, stripped, tc.stripped)
+		}
+	}
+}
+
+// usePersona activates a built-in persona for the rest of the test
+func usePersona(t *testing.T, name string) {
+	t.Helper()
+	persona, err := ui.LoadPersona(name)
+	if err != nil {
+		t.Fatalf("LoadPersona(%q) failed: %v", name, err)
+	}
+	ui.SetPersona(persona)
+	t.Cleanup(func() {
+		knight, _ := ui.LoadPersona("")
+		ui.SetPersona(knight)
+	})
+}
+
+func TestVoice_Personas(t *testing.T) {
+	testCases := []struct {
+		persona  string
+		text     string
+		expected string
+	}{
+		{"knight", "The quest is done, my lord!", "The quest is done, my lord!"},
+		{"pirate", "The quest is done, my lord!", "The voyage is done, captain!"},
+		{"pirate", "🏆 QUEST COMPLETED", "🏆 VOYAGE COMPLETED"},
+		{"pirate", "Quests await thy knight", "Voyages await yer first mate"},
+		{"starship", "Consulting the oracles of the realm", "Consulting the science officers of the ship"},
+		{"plain", "Your command has been executed successfully, sire!", "Your command has been executed successfully!"},
+		{"plain", "Hear ye, hear ye! A new quest has been decreed!", "A new task has been decreed!"},
+		{"plain", "I understand, sire. Please try again.", "I understand. Please try again."},
+		{"pirate", "Your knight's oracle is ready", "Your first mate's sea witch is ready"},
+		{"pirate", "Run 'grep quest notes.txt' for the quest", "Run 'grep quest notes.txt' for the voyage"},
+		{"pirate", "Full log: /home/knight/quests/run.log", "Full log: /home/knight/quests/run.log"},
+		{"pirate", "A questionable request", "A questionable request"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.persona+"/"+tc.text, func(t *testing.T) {
+			usePersona(t, tc.persona)
+			if got := ui.Voice(tc.text); got != tc.expected {
+				t.Errorf("Voice(%q) = %q, expected %q", tc.text, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMessage_CatalogAndOverrides(t *testing.T) {
+	usePersona(t, "knight")
+	if got := ui.Message("quest.received", "intent", "list files"); got != `Your faithful knight has received your command: "list files"` {
+		t.Errorf("Unexpected catalog message %q", got)
+	}
+
+	usePersona(t, "plain")
+	if got := ui.Message("quest.difficulties", "error", "exit status 1"); got != "The task failed: exit status 1" {
+		t.Errorf("Expected the persona's own message, got %q", got)
+	}
+}
+
+func TestLoadPersona_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "butler.yaml")
+	content := "terms:\n  my lord: sir\n  quest: errand\nmessages:\n  confirm.monarch: \"Shall I see to it, sir? (y/N):\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	persona, err := ui.LoadPersona(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if persona.Name != "butler" {
+		t.Errorf("Expected the file name as the persona name, got %q", persona.Name)
+	}
+	ui.SetPersona(persona)
+	t.Cleanup(func() {
+		knight, _ := ui.LoadPersona("")
+		ui.SetPersona(knight)
+	})
+	if got := ui.Voice("Your quest awaits, my lord"); got != "Your errand awaits, sir" {
+		t.Errorf("Unexpected voice %q", got)
+	}
+	if got := ui.Message("confirm.monarch"); got != "Shall I see to it, sir? (y/N):" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+func TestLoadPersona_Errors(t *testing.T) {
+	if _, err := ui.LoadPersona("no-such-persona"); err == nil || !strings.Contains(err.Error(), "pirate") {
+		t.Errorf("Expected an error listing the built-in personas, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "typo.yaml")
+	if err := os.WriteFile(path, []byte("messages:\n  confirm.monarchy: Go?\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ui.LoadPersona(path); err == nil || !strings.Contains(err.Error(), "confirm.monarchy") {
+		t.Errorf("Expected an error naming the unknown message, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// File: test/undo_test.go
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+)
+
+func undoJournal() *history.Journal {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	journal := history.NewJournal("")
+	journal.Add(&history.Record{At: at, Intent: "install htop", Content: "sudo apt install -y htop", Decision: history.DecisionApproved, Executed: true})
+	journal.Add(&history.Record{At: at, Intent: "create the build folder", Content: "mkdir build", Decision: history.DecisionDeclined})
+	journal.Add(&history.Record{At: at, Intent: "rename the notes", Content: "mv notes.txt notes.md", Decision: history.DecisionApproved, Executed: true})
+	journal.Add(&history.Record{At: at, Intent: "list docker containers", Content: "docker ps -a", Decision: history.DecisionApproved, Executed: true})
+	return journal
+}
+
+func newUndoFixture(reversal *ai.AIResponse) *pipelineFixture {
+	f := newPipelineFixture()
+	f.aiClient.RollbackResponse = reversal
+	return f
+}
+
+func TestUndo_ReversesTheLastChange(t *testing.T) {
+	f := newUndoFixture(&ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "mv notes.md notes.txt"})
+	undo := &cli.Undo{Journal: undoJournal(), Deps: f.deps()}
+
+	quest, err := undo.Run("last", &config.Config{Mode: "monarch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.aiClient.LastRollback.Content != "mv notes.txt notes.md" {
+		t.Errorf("Expected the read-only quest to be skipped and the rename undone, got %q", f.aiClient.LastRollback.Content)
+	}
+	if len(f.executor.ExecutedCommands) != 1 || f.executor.ExecutedCommands[0] != "mv notes.md notes.txt" {
+		t.Errorf("Expected the reversal to run, got %v", f.executor.ExecutedCommands)
+	}
+	if record := cli.JournalRecord(quest, time.Now()); record.Undoes != 3 {
+		t.Errorf("Expected the journal to record the quest undone, got %d", record.Undoes)
+	}
+}
+
+func TestUndo_IrreversibleRunsNothing(t *testing.T) {
+	f := newUndoFixture(&ai.AIResponse{Type: ai.ResponseTypeFailure, Category: ai.FailureImpossible, Error: "the deleted files had no backup"})
+	undo := &cli.Undo{Journal: undoJournal(), Deps: f.deps()}
+
+	quest, err := undo.Run("1", &config.Config{Mode: "monarch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 || quest.Executed || f.confirmer.CallCount != 0 {
+		t.Errorf("Expected nothing to be offered or run, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestUndo_ReversalIsConfirmed(t *testing.T) {
+	f := newUndoFixture(&ai.AIResponse{Type: ai.ResponseTypeCommand, Content: "sudo apt remove -y htop"})
+	f.confirmer.Approve = false
+	undo := &cli.Undo{Journal: undoJournal(), Deps: f.deps()}
+
+	quest, err := undo.Run("1", &config.Config{Mode: "monarch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(f.executor.ExecutedCommands) != 0 || !quest.Declined {
+		t.Errorf("Expected nothing to run once declined, got %v", f.executor.ExecutedCommands)
+	}
+}
+
+func TestUndo_RejectsWhatCannotBeUndone(t *testing.T) {
+	journal := undoJournal()
+	journal.Add(&history.Record{Intent: "undo quest #3: rename the notes", Content: "mv notes.md notes.txt", Undoes: 3, Executed: true})
+
+	testCases := []struct {
+		ref      string
+		expected string
+	}{
+		{ref: "2", expected: "never ran"},
+		{ref: "3", expected: "already undone by quest #5"},
+		{ref: "4", expected: "changed nothing"},
+		{ref: "5", expected: "redo 3"},
+		{ref: "9", expected: "not in the journal"},
+		{ref: "yesterday", expected: "neither a quest number"},
+	}
+	for _, tc := range testCases {
+		f := newUndoFixture(nil)
+		undo := &cli.Undo{Journal: journal, Deps: f.deps()}
+
+		quest, err := undo.Run(tc.ref, &config.Config{Mode: "monarch"})
+		if err == nil || !strings.Contains(err.Error(), tc.expected) || quest != nil {
+			t.Errorf("%q: expected an error mentioning %q, got %v", tc.ref, tc.expected, err)
+		}
+		if f.aiClient.RollbackCallCount != 0 {
+			t.Errorf("%q: expected the oracle not to be asked", tc.ref)
+		}
+	}
+
+	// Quest #1 is the only change left to undo
+	f := newUndoFixture(nil)
+	if _, err := (&cli.Undo{Journal: journal, Deps: f.deps()}).Run("last", &config.Config{Mode: "monarch"}); err != nil || f.aiClient.LastRollback.Content != "sudo apt install -y htop" {
+		t.Errorf("Expected 'last' to skip the undone quest, got %q and %v", f.aiClient.LastRollback.Content, err)
+	}
+
+	empty := &cli.Undo{Journal: history.NewJournal(""), Deps: newPipelineFixture().deps()}
+	if _, err := empty.Run("last", &config.Config{Mode: "monarch"}); err == nil {
+		t.Error("Expected 'last' to fail on an empty journal")
+	}
+}
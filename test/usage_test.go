@@ -0,0 +1,97 @@
+// File: test/usage_test.go
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+)
+
+func budgetConfig(budget config.Budget) *config.Config {
+	return &config.Config{APIKey: "test-key", Mode: "monarch", AIProvider: "openai", Model: "gpt-4o", Budget: budget}
+}
+
+func TestUsageLedger_SaveAndLoadByMonth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", ai.UsageFile)
+	october := time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)
+	november := october.AddDate(0, 0, 5)
+
+	ledger, err := ai.LoadUsageLedger(path)
+	if err != nil {
+		t.Fatalf("Loading a missing ledger should succeed, got %v", err)
+	}
+	ledger.Record("openai/gpt-4o", 1000, october)
+	ledger.Record("openai/gpt-4o-mini", 500, october)
+	ledger.Record("openai/gpt-4o", 200, november)
+	if err := ledger.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := ai.LoadUsageLedger(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := loaded.MonthTokens(october); got != 1500 {
+		t.Errorf("Expected 1500 tokens in October, got %d", got)
+	}
+	if got := loaded.MonthTokens(november); got != 200 {
+		t.Errorf("Expected a new month to start from its own usage, got %d", got)
+	}
+	if keys := loaded.MonthKeys(october); len(keys) != 2 || keys[0] != "openai/gpt-4o" {
+		t.Errorf("Expected both October models sorted, got %v", keys)
+	}
+}
+
+func TestDegradeForBudget(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	ledger, _ := ai.LoadUsageLedger(filepath.Join(t.TempDir(), ai.UsageFile))
+	ledger.Record("openai/gpt-4o", 850, now)
+
+	t.Run("below the threshold keeps the model", func(t *testing.T) {
+		cfg := budgetConfig(config.Budget{MonthlyTokens: 1000, FallbackModel: "gpt-4o-mini"})
+		if notice := ai.DegradeForBudget(cfg, ledger, now); notice != "" || cfg.Model != "gpt-4o" {
+			t.Errorf("Expected no switch below 90%%, got model %s and notice %q", cfg.Model, notice)
+		}
+	})
+
+	t.Run("near the budget switches to the fallback", func(t *testing.T) {
+		cfg := budgetConfig(config.Budget{MonthlyTokens: 1000, FallbackModel: "gpt-4o-mini", SwitchAt: 0.8})
+		notice := ai.DegradeForBudget(cfg, ledger, now)
+		if cfg.Model != "gpt-4o-mini" {
+			t.Errorf("Expected the fallback model, got %s", cfg.Model)
+		}
+		if !strings.Contains(notice, "gpt-4o-mini") || !strings.Contains(notice, "850 of your 1000") {
+			t.Errorf("Expected a notice naming the usage and the fallback, got %q", notice)
+		}
+	})
+
+	t.Run("without a fallback only warns", func(t *testing.T) {
+		cfg := budgetConfig(config.Budget{MonthlyTokens: 900})
+		notice := ai.DegradeForBudget(cfg, ledger, now)
+		if cfg.Model != "gpt-4o" || !strings.Contains(notice, "No fallback_model") {
+			t.Errorf("Expected a warning and the same model, got model %s and notice %q", cfg.Model, notice)
+		}
+	})
+
+	t.Run("no budget does nothing", func(t *testing.T) {
+		cfg := budgetConfig(config.Budget{FallbackModel: "gpt-4o-mini"})
+		if notice := ai.DegradeForBudget(cfg, ledger, now); notice != "" || cfg.Model != "gpt-4o" {
+			t.Errorf("Expected no switch without a budget, got model %s and notice %q", cfg.Model, notice)
+		}
+	})
+}
+
+func TestConfig_ValidateBudget(t *testing.T) {
+	for _, budget := range []config.Budget{{MonthlyTokens: -1}, {MonthlyTokens: 1000, SwitchAt: 1.5}} {
+		if err := budgetConfig(budget).Validate(); err == nil {
+			t.Errorf("Expected an error for budget %+v", budget)
+		}
+	}
+	if err := budgetConfig(config.Budget{MonthlyTokens: 1000, SwitchAt: 0.75}).Validate(); err != nil {
+		t.Errorf("Expected a valid budget, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// File: test/wait_test.go
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestExpandWaitHelper(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		shell    string
+		binary   string
+		expected string
+	}{
+		{
+			name:     "command chain",
+			content:  "sudo systemctl restart nginx && emw-wait --url http://localhost/",
+			shell:    "/bin/bash",
+			binary:   "/usr/local/bin/execute-my-will",
+			expected: "sudo systemctl restart nginx && /usr/local/bin/execute-my-will wait --url http://localhost/",
+		},
+		{
+			name:     "indented script line",
+			content:  "# Wait for the database\n  emw-wait --port 5432\necho \"emw-wait is done\"",
+			shell:    "bash",
+			binary:   "/opt/my tools/execute-my-will",
+			expected: "# Wait for the database\n  '/opt/my tools/execute-my-will' wait --port 5432\necho \"emw-wait is done\"",
+		},
+		{
+			name:     "powershell",
+			content:  "Restart-Service W3SVC; emw-wait --port 80",
+			shell:    "powershell",
+			binary:   `C:\Tools\execute-my-will.exe`,
+			expected: `Restart-Service W3SVC; & 'C:\Tools\execute-my-will.exe' wait --port 80`,
+		},
+		{
+			name:     "cmd",
+			content:  "net start w3svc && emw-wait --port 80",
+			shell:    "cmd",
+			binary:   `C:\Program Files\emw\execute-my-will.exe`,
+			expected: `net start w3svc && "C:\Program Files\emw\execute-my-will.exe" wait --port 80`,
+		},
+		{
+			name:     "no binary",
+			content:  "emw-wait --port 80",
+			shell:    "bash",
+			expected: "emw-wait --port 80",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.ExpandWaitHelper(tc.content, tc.shell, tc.binary); got != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWaitCondition_Validate(t *testing.T) {
+	valid := system.WaitCondition{Port: "80", Timeout: time.Second, Interval: time.Second}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	for _, invalid := range []system.WaitCondition{
+		{Timeout: time.Second, Interval: time.Second},
+		{Port: "80", Process: "nginx", Timeout: time.Second, Interval: time.Second},
+		{URL: "localhost:8080", Timeout: time.Second, Interval: time.Second},
+		{Port: "80", Interval: time.Second},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestWaitFor_Port(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+
+	open := system.WaitCondition{Port: address, Timeout: time.Second, Interval: 10 * time.Millisecond}
+	if err := system.WaitFor(open); err != nil {
+		t.Errorf("Expected the open port to be ready: %v", err)
+	}
+
+	listener.Close()
+	if err := system.WaitFor(open); err == nil || !strings.Contains(err.Error(), "gave up waiting for port "+address) {
+		t.Errorf("Expected a timeout naming the port, got %v", err)
+	}
+
+	gone := open
+	gone.Gone = true
+	if err := system.WaitFor(gone); err != nil {
+		t.Errorf("Expected the closed port to count as gone: %v", err)
+	}
+}
+
+func TestWaitFor_URL(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	condition := system.WaitCondition{URL: server.URL, Timeout: time.Second, Interval: 10 * time.Millisecond}
+	if err := system.WaitFor(condition); err != nil {
+		t.Errorf("Expected the URL to be ready: %v", err)
+	}
+
+	healthy.Store(false)
+	condition.Timeout = 50 * time.Millisecond
+	if err := system.WaitFor(condition); err == nil {
+		t.Error("Expected a timeout while the URL answers 503")
+	}
+}
+
+func TestWaitHelperIsReadOnly(t *testing.T) {
+	if risk := system.AssessCommandRisk("emw-wait --port 5432 --timeout 30s"); risk != system.RiskReadOnly {
+		t.Errorf("Expected the wait helper to be read-only, got %s", risk)
+	}
+}
@@ -0,0 +1,69 @@
+// File: test/warmup_test.go
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+)
+
+func TestWarmUp_MeasuresLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"READY"}}]}`))
+	}))
+	defer server.Close()
+
+	latency, err := ai.WarmUp(compatibleConfig(server.URL, ""), time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if latency < 20*time.Millisecond {
+		t.Errorf("Expected the latency to cover the answer, got %s", latency)
+	}
+}
+
+func TestWarmUp_ReportsProblems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Authorization"), "slow") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer server.Close()
+
+	if _, err := ai.WarmUp(compatibleConfig(server.URL, "sk-wrong"), time.Second); err == nil || !strings.Contains(err.Error(), "Incorrect API key") {
+		t.Errorf("Expected the rejected key to be reported, got %v", err)
+	}
+	if _, err := ai.WarmUp(compatibleConfig(server.URL, "sk-slow"), 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "no answer within") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestLatencyBaselines_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ai.LatencyFile)
+	baselines, err := ai.LoadLatencyBaselines(path)
+	if err != nil || len(baselines.Models) != 0 {
+		t.Fatalf("Expected no baselines before the first warm-up, got %v and %v", baselines, err)
+	}
+
+	measured := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	baselines.Models[ai.StatsKey("openai", "gpt-4o")] = &ai.LatencyBaseline{Latency: 840 * time.Millisecond, MeasuredAt: measured}
+	if err := baselines.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := ai.LoadLatencyBaselines(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := loaded.Models["openai/gpt-4o"]; got == nil || got.Latency != 840*time.Millisecond || !got.MeasuredAt.Equal(measured) {
+		t.Errorf("Expected the baseline to survive a round trip, got %+v", got)
+	}
+}
@@ -0,0 +1,239 @@
+// File: test/workspace_test.go
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minand-mohan/execute-my-will/internal/ai"
+	"github.com/minand-mohan/execute-my-will/internal/cli"
+	"github.com/minand-mohan/execute-my-will/internal/config"
+	"github.com/minand-mohan/execute-my-will/internal/history"
+	"github.com/minand-mohan/execute-my-will/internal/system"
+)
+
+func TestMatchWorkspaceIntent(t *testing.T) {
+	testCases := []struct {
+		intent       string
+		expectedRest string
+		expectedAll  bool
+	}{
+		{intent: "all: pull latest and run tests", expectedRest: "pull latest and run tests", expectedAll: true},
+		{intent: "ALL:git status", expectedRest: "git status", expectedAll: true},
+		{intent: "prod: restart nginx", expectedRest: "prod: restart nginx"},
+		{intent: "list all files", expectedRest: "list all files"},
+	}
+
+	for _, tc := range testCases {
+		rest, all := config.MatchWorkspaceIntent(tc.intent)
+		if rest != tc.expectedRest || all != tc.expectedAll {
+			t.Errorf("MatchWorkspaceIntent(%q) = (%q, %v), want (%q, %v)", tc.intent, rest, all, tc.expectedRest, tc.expectedAll)
+		}
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "api", "internal")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "projects:\n  - api\n  - /srv/web\n  - ~/docs\nparallel: true\n"
+	if err := os.WriteFile(filepath.Join(root, config.WorkspaceFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := config.FindWorkspace(nested)
+	if err != nil || ws == nil {
+		t.Fatalf("Expected to find the workspace in a parent directory, got %v, %v", ws, err)
+	}
+
+	home, _ := os.UserHomeDir()
+	expected := []string{filepath.Join(root, "api"), filepath.Clean("/srv/web"), filepath.Join(home, "docs")}
+	if strings.Join(ws.Projects, ",") != strings.Join(expected, ",") || !ws.Parallel {
+		t.Errorf("Expected projects %v in parallel, got %v (parallel %v)", expected, ws.Projects, ws.Parallel)
+	}
+}
+
+func TestLoadWorkspace_NoProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.WorkspaceFile)
+	if err := os.WriteFile(path, []byte("projects: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.LoadWorkspace(path); err == nil {
+		t.Error("Expected an error for a workspace without projects")
+	}
+}
+
+// workspaceFixture runs workspace quests against mocks, with one executor per project
+type workspaceFixture struct {
+	*pipelineFixture
+	mu        sync.Mutex
+	executors map[string]*MockCommandExecutor
+	dirs      []string
+	onRun     func()        // called by every project's executor as it runs
+	approver  cli.Confirmer // replaces the mock confirmer when set
+
+	reauthenticate func(reason string) error
+	history        *history.Store
+}
+
+func newWorkspaceFixture(t *testing.T) *workspaceFixture {
+	root := t.TempDir()
+	f := &workspaceFixture{pipelineFixture: newPipelineFixture(), executors: map[string]*MockCommandExecutor{}}
+	for _, name := range []string{"api", "web"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		f.dirs = append(f.dirs, dir)
+	}
+	f.dirs = append(f.dirs, filepath.Join(root, "missing"))
+	return f
+}
+
+func (f *workspaceFixture) run(t *testing.T, parallel bool) []*cli.ProjectResult {
+	t.Helper()
+	return f.runQuest(t, newQuest("run the tests", "monarch"), parallel)
+}
+
+func (f *workspaceFixture) runQuest(t *testing.T, quest *cli.Quest, parallel bool) []*cli.ProjectResult {
+	t.Helper()
+	deps := f.deps()
+	if f.approver != nil {
+		deps.Confirmer = f.approver
+	}
+	deps.Reauthenticate = f.reauthenticate
+	deps.History = f.history
+	deps.NewProjectExecutor = func(dir, label string, background bool) system.CommandExecutor {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		executor := &MockCommandExecutor{FailOn: map[string]bool{}}
+		if label == "web" {
+			executor.ShouldError = true
+		}
+		executor.OnRun = f.onRun
+		f.executors[dir] = executor
+		return executor
+	}
+
+	run := &cli.WorkspaceRun{Workspace: &config.Workspace{Projects: f.dirs}, Parallel: parallel, Deps: deps}
+	results, err := run.Run(quest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return results
+}
+
+func TestWorkspaceRun(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		f := newWorkspaceFixture(t)
+		results := f.run(t, parallel)
+
+		var statuses []string
+		for _, result := range results {
+			statuses = append(statuses, result.Label+"="+string(result.Status))
+		}
+		expected := "api=succeeded,web=failed,missing=skipped"
+		if strings.Join(statuses, ",") != expected {
+			t.Errorf("parallel=%v: expected %s, got %v", parallel, expected, statuses)
+		}
+
+		if f.confirmer.CallCount != 1 {
+			t.Errorf("parallel=%v: expected one confirmation for the whole workspace, got %d", parallel, f.confirmer.CallCount)
+		}
+		if f.aiClient.GenerateCallCount != 2 {
+			t.Errorf("parallel=%v: expected a proposal per existing project, got %d", parallel, f.aiClient.GenerateCallCount)
+		}
+
+		var ran []string
+		for dir, executor := range f.executors {
+			if len(executor.ExecutedCommands) == 1 {
+				ran = append(ran, filepath.Base(dir))
+			}
+		}
+		sort.Strings(ran)
+		if strings.Join(ran, ",") != "api,web" {
+			t.Errorf("parallel=%v: expected the quest to run in api and web, got %v", parallel, ran)
+		}
+	}
+}
+
+func TestWorkspaceRun_Declined(t *testing.T) {
+	f := newWorkspaceFixture(t)
+	f.confirmer.Approve = false
+
+	results := f.run(t, false)
+	if len(f.executors) != 0 {
+		t.Errorf("Nothing should run when the workspace quest is declined, got %d executors", len(f.executors))
+	}
+	if results[0].Status != cli.ProjectDeclined {
+		t.Errorf("Expected the ready projects to be declined, got %s", results[0].Status)
+	}
+}
+
+func TestWorkspaceRun_DefaultAnswerFollowsRiskiestProject(t *testing.T) {
+	testCases := []struct {
+		name     string
+		web      string
+		expected bool
+	}{
+		{name: "every project only reads", web: "git status", expected: true},
+		{name: "one project is destructive", web: "rm -rf build"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newWorkspaceFixture(t)
+			f.aiClient.NextResponses = []*ai.AIResponse{
+				{Type: ai.ResponseTypeCommand, Content: "git status"},
+				{Type: ai.ResponseTypeCommand, Content: tc.web},
+			}
+			f.approver = cli.NewStdinConfirmer(strings.NewReader("\n"))
+			quest := newQuest("check the projects", "monarch")
+			quest.Config.Execution.ReadOnlyDefaultYes = true
+
+			results := f.runQuest(t, quest, false)
+			if quest.DefaultApproval != tc.expected || (results[0].Status != cli.ProjectDeclined) != tc.expected {
+				t.Errorf("Expected Enter to approve: %v, got default %v and api %s", tc.expected, quest.DefaultApproval, results[0].Status)
+			}
+		})
+	}
+}
+
+func TestWorkspaceRun_HonorsKillSwitch(t *testing.T) {
+	skipIfMachineKillSwitch(t)
+
+	t.Run("engaged while awaiting approval", func(t *testing.T) {
+		for _, parallel := range []bool{false, true} {
+			f := newWorkspaceFixture(t)
+			quest := newQuest("run the tests", "monarch")
+			quest.Config.Execution.KillSwitch = filepath.Join(t.TempDir(), "disabled")
+			f.approver = &killSwitchConfirmer{path: quest.Config.Execution.KillSwitch}
+
+			results := f.runQuest(t, quest, parallel)
+			if len(f.executors) != 0 {
+				t.Errorf("parallel=%v: expected no project to run, got %d executors", parallel, len(f.executors))
+			}
+			if results[0].Status != cli.ProjectSkipped || results[1].Status != cli.ProjectSkipped {
+				t.Errorf("parallel=%v: expected the ready projects to be skipped, got %s and %s", parallel, results[0].Status, results[1].Status)
+			}
+		}
+	})
+
+	t.Run("engaged between projects", func(t *testing.T) {
+		f := newWorkspaceFixture(t)
+		quest := newQuest("run the tests", "monarch")
+		quest.Config.Execution.KillSwitch = filepath.Join(t.TempDir(), "disabled")
+		f.onRun = func() { os.WriteFile(quest.Config.Execution.KillSwitch, nil, 0o644) }
+
+		results := f.runQuest(t, quest, false)
+		if results[0].Status != cli.ProjectSucceeded || results[1].Status != cli.ProjectSkipped || results[1].Note != "halted by the kill switch" {
+			t.Errorf("Expected the kill switch to stop the projects after api, got %s and %s (%s)", results[0].Status, results[1].Status, results[1].Note)
+		}
+	})
+}